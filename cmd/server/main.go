@@ -2,9 +2,14 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
@@ -21,6 +26,9 @@ import (
 	controllerconfig "github.com/lissto-dev/controller/pkg/config"
 )
 
+// shutdownTimeout bounds graceful shutdown, within Kubernetes' default 30s termination grace period
+const shutdownTimeout = 25 * time.Second
+
 // CustomValidator wraps the validator
 type CustomValidator struct {
 	validator *validator.Validate
@@ -49,6 +57,12 @@ func main() {
 	}
 	log.Printf("Configuration loaded from %s", configPath)
 
+	// Load API-only settings from the same file
+	settings, err := config.LoadSettings(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load API settings: %v", err)
+	}
+
 	// Get API namespace from environment (defaults to lissto-system)
 	apiNamespace := os.Getenv("POD_NAMESPACE")
 	if apiNamespace == "" {
@@ -76,8 +90,17 @@ func main() {
 	// Initialize authorization components
 	nsManager := authz.NewNamespaceManager(cfg)
 	authorizer := authz.NewAuthorizer(nsManager)
+	for name, role := range settings.Roles {
+		authorizer.SetRoleVisibility(authz.ParseRole(name), authz.RoleVisibility{
+			GlobalRead:     role.GlobalReadEnabled(),
+			ReadNamespaces: role.ReadNamespaces,
+		})
+	}
 	logging.Logger.Info("Authorization initialized")
 
+	// Label namespaces created by the API (owner, scope and configured defaults)
+	k8sClient.SetNamespaceMetadata(nsManager.NamespaceMetadata(settings.Namespaces.Labels, settings.Namespaces.Annotations))
+
 	// Ensure global namespace exists on startup
 	ctx := context.Background()
 	if err := k8sClient.EnsureNamespace(ctx, cfg.Namespaces.Global); err != nil {
@@ -154,10 +177,28 @@ func main() {
 	e.Use(internalMiddleware.APIIDMiddleware(instanceID))
 
 	// Initialize and start server
-	srv := server.New(e, apiKeys, cfg, k8sClient, authorizer, nsManager, apiNamespace, instanceID, publicURL)
+	srv := server.New(e, apiKeys, cfg, settings, k8sClient, authorizer, nsManager, apiNamespace, instanceID, publicURL)
 	logging.Logger.Info("Server initialized")
 
-	if err := srv.Start(); err != nil {
-		logging.Logger.Fatal("Server error", zap.Error(err))
+	// Serve until SIGTERM or SIGINT, then drain requests and webhook deliveries
+	stopCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- srv.Start()
+	}()
+
+	select {
+	case err := <-serverErr:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logging.Logger.Fatal("Server error", zap.Error(err))
+		}
+	case <-stopCtx.Done():
+		logging.Logger.Info("Shutting down")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logging.Logger.Error("Graceful shutdown failed", zap.Error(err))
+		}
 	}
 }
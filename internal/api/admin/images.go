@@ -0,0 +1,141 @@
+package admin
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/image"
+	"github.com/lissto-dev/api/pkg/logging"
+	"github.com/lissto-dev/api/pkg/response"
+)
+
+// Image inventory page sizes
+const (
+	DefaultImagesLimit = 100
+	MaxImagesLimit     = 1000
+)
+
+// ImageUsage is one distinct image deployed by stacks
+type ImageUsage struct {
+	Image      string   `json:"image,omitempty"`  // Tag reference, empty for stacks that only recorded a digest
+	Digest     string   `json:"digest,omitempty"` // Digest reference actually deployed
+	Registry   string   `json:"registry,omitempty"`
+	StackCount int      `json:"stack_count"`
+	Stacks     []string `json:"stacks"` // Scoped stack IDs, sorted
+}
+
+// ImageInventoryResponse is a page of the image inventory
+type ImageInventoryResponse struct {
+	Images []ImageUsage `json:"images"`
+	Total  int          `json:"total"` // Distinct images matching the filters
+	Offset int          `json:"offset"`
+	Limit  int          `json:"limit"`
+}
+
+// ListImages handles GET /admin/images
+// Aggregates the images of all stacks in all namespaces into distinct (image, digest) pairs with
+// the stacks using each, most used first. ?registry= filters by registry host (docker.io matches
+// index.docker.io), ?limit= and ?offset= page through the result.
+func (h *Handler) ListImages(c echo.Context) error {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+	if user.Role != authz.Admin {
+		logging.LogDeniedWithIP("admin_required", user.Name, "GET /admin/images", c.RealIP())
+		return response.Forbidden(c, "Admin role required")
+	}
+
+	limit, err := queryInt(c, "limit", DefaultImagesLimit)
+	if err != nil || limit < 1 || limit > MaxImagesLimit {
+		return response.BadRequest(c, fmt.Sprintf("limit must be between 1 and %d", MaxImagesLimit))
+	}
+	offset, err := queryInt(c, "offset", 0)
+	if err != nil || offset < 0 {
+		return response.BadRequest(c, "offset must be a non-negative integer")
+	}
+	registry := c.QueryParam("registry")
+	if registry != "" {
+		// Normalize the same way image references are (docker.io → index.docker.io)
+		if registry = image.RegistryHost(registry + "/image"); registry == "" {
+			return response.BadRequest(c, "Invalid registry")
+		}
+	}
+
+	stacks, err := h.k8sClient.ListStacks(c.Request().Context(), "")
+	if err != nil {
+		logging.Logger.Error("Failed to list stacks for image inventory", zap.Error(err))
+		return response.InternalServerError(c, "Failed to list stacks")
+	}
+
+	type imageKey struct{ image, digest string }
+	usages := map[imageKey]*ImageUsage{}
+	for _, stack := range stacks.Items {
+		stackID := h.nsManager.MustGenerateScopedID(stack.Namespace, stack.Name)
+		for _, info := range stack.Spec.Images {
+			if info.Image == "" && info.Digest == "" {
+				continue
+			}
+			host := image.RegistryHost(info.Digest)
+			if host == "" {
+				host = image.RegistryHost(info.Image)
+			}
+			if registry != "" && host != registry {
+				continue
+			}
+
+			key := imageKey{image: info.Image, digest: info.Digest}
+			usage, exists := usages[key]
+			if !exists {
+				usage = &ImageUsage{Image: info.Image, Digest: info.Digest, Registry: host}
+				usages[key] = usage
+			}
+			// A stack running the image in several services counts once
+			if n := len(usage.Stacks); n == 0 || usage.Stacks[n-1] != stackID {
+				usage.Stacks = append(usage.Stacks, stackID)
+			}
+		}
+	}
+
+	images := make([]ImageUsage, 0, len(usages))
+	for _, usage := range usages {
+		sort.Strings(usage.Stacks)
+		usage.StackCount = len(usage.Stacks)
+		images = append(images, *usage)
+	}
+	sort.Slice(images, func(i, j int) bool {
+		if images[i].StackCount != images[j].StackCount {
+			return images[i].StackCount > images[j].StackCount
+		}
+		if images[i].Image != images[j].Image {
+			return images[i].Image < images[j].Image
+		}
+		return images[i].Digest < images[j].Digest
+	})
+
+	total := len(images)
+	start := min(offset, total)
+	end := min(start+limit, total)
+
+	return c.JSON(200, ImageInventoryResponse{
+		Images: images[start:end],
+		Total:  total,
+		Offset: offset,
+		Limit:  limit,
+	})
+}
+
+// queryInt parses an integer query parameter, returning fallback when it is absent
+func queryInt(c echo.Context, name string, fallback int) (int, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
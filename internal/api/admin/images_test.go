@@ -0,0 +1,127 @@
+package admin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/admin"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/k8s"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+var _ = Describe("Image inventory", func() {
+	var (
+		apiV1    = "ghcr.io/acme/api@sha256:" + strings.Repeat("1", 64)
+		apiV2    = "ghcr.io/acme/api@sha256:" + strings.Repeat("2", 64)
+		postgres = "postgres@sha256:" + strings.Repeat("3", 64)
+	)
+
+	var handler *admin.Handler
+
+	stack := func(namespace, name string, images map[string]envv1alpha1.ImageInfo) *envv1alpha1.Stack {
+		return &envv1alpha1.Stack{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       envv1alpha1.StackSpec{Images: images},
+		}
+	}
+
+	BeforeEach(func() {
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		objects := []client.Object{
+			stack("dev-alice", "a1", map[string]envv1alpha1.ImageInfo{
+				"api": {Image: "ghcr.io/acme/api:main", Digest: apiV1},
+				"db":  {Image: "postgres:16", Digest: postgres},
+			}),
+			stack("dev-bob", "b1", map[string]envv1alpha1.ImageInfo{
+				"api":    {Image: "ghcr.io/acme/api:main", Digest: apiV1},
+				"worker": {Image: "ghcr.io/acme/api:main", Digest: apiV1},
+				"db":     {Image: "postgres:16", Digest: postgres},
+			}),
+			stack("lissto-global", "main", map[string]envv1alpha1.ImageInfo{
+				"api": {Image: "ghcr.io/acme/api:main", Digest: apiV2},
+				"db":  {Image: "postgres:16", Digest: postgres},
+			}),
+		}
+		k8sClient := k8s.NewClientFromClient(fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(), scheme)
+		handler = admin.NewHandler(k8sClient, authz.NewNamespaceManager(cfg), cfg, config.DefaultSettings(), admin.RuntimeInfo{})
+	})
+
+	list := func(query string, user *middleware.User) (int, admin.ImageInventoryResponse) {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/images"+query, nil), rec)
+		c.Set("user", user)
+		Expect(handler.ListImages(c)).To(Succeed())
+
+		var resp admin.ImageInventoryResponse
+		if rec.Code == 200 {
+			Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		}
+		return rec.Code, resp
+	}
+
+	root := &middleware.User{Name: "root", Role: authz.Admin}
+
+	It("should aggregate distinct images with the stacks using them", func() {
+		code, resp := list("", root)
+		Expect(code).To(Equal(200))
+		Expect(resp.Total).To(Equal(3))
+		Expect(resp.Images).To(Equal([]admin.ImageUsage{
+			{Image: "postgres:16", Digest: postgres, Registry: "index.docker.io", StackCount: 3, Stacks: []string{"alice/a1", "bob/b1", "global/main"}},
+			{Image: "ghcr.io/acme/api:main", Digest: apiV1, Registry: "ghcr.io", StackCount: 2, Stacks: []string{"alice/a1", "bob/b1"}},
+			{Image: "ghcr.io/acme/api:main", Digest: apiV2, Registry: "ghcr.io", StackCount: 1, Stacks: []string{"global/main"}},
+		}))
+	})
+
+	It("should filter by registry", func() {
+		_, resp := list("?registry=docker.io", root)
+		Expect(resp.Total).To(Equal(1))
+		Expect(resp.Images[0].Digest).To(Equal(postgres))
+
+		_, resp = list("?registry=ghcr.io", root)
+		Expect(resp.Total).To(Equal(2))
+	})
+
+	It("should paginate", func() {
+		_, resp := list("?limit=2", root)
+		Expect(resp.Images).To(HaveLen(2))
+		Expect(resp.Total).To(Equal(3))
+
+		_, resp = list("?limit=2&offset=2", root)
+		Expect(resp.Images).To(HaveLen(1))
+		Expect(resp.Images[0].Digest).To(Equal(apiV2))
+
+		_, resp = list("?offset=10", root)
+		Expect(resp.Images).To(BeEmpty())
+	})
+
+	It("should reject invalid pagination", func() {
+		code, _ := list("?limit=0", root)
+		Expect(code).To(Equal(400))
+		code, _ = list("?offset=-1", root)
+		Expect(code).To(Equal(400))
+	})
+
+	It("should require the admin role", func() {
+		code, _ := list("", &middleware.User{Name: "alice", Role: authz.User})
+		Expect(code).To(Equal(403))
+	})
+})
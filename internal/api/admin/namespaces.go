@@ -0,0 +1,147 @@
+package admin
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/logging"
+	"github.com/lissto-dev/api/pkg/response"
+)
+
+// FreezeNamespaceRequest is the optional body of POST /admin/namespaces/:scope/freeze
+type FreezeNamespaceRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// NamespaceFreezeResponse describes the freeze state of a developer namespace
+type NamespaceFreezeResponse struct {
+	Scope     string `json:"scope"` // Developer name
+	Namespace string `json:"namespace"`
+	Frozen    bool   `json:"frozen"`
+	FrozenBy  string `json:"frozen_by,omitempty"`
+	FrozenAt  string `json:"frozen_at,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// FreezeNamespace handles POST /admin/namespaces/:scope/freeze
+// Mutations in a frozen namespace are rejected with 423 Locked; reads keep working
+func (h *Handler) FreezeNamespace(c echo.Context) error {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+	if user.Role != authz.Admin {
+		logging.LogDeniedWithIP("admin_required", user.Name, "POST /admin/namespaces/:scope/freeze", c.RealIP())
+		return response.Forbidden(c, "Admin role required")
+	}
+
+	var req FreezeNamespaceRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request")
+	}
+
+	scope := c.Param("scope")
+	namespace, err := h.developerNamespace(scope)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	// Freezing a developer without a namespace yet still blocks their first stack
+	ctx := c.Request().Context()
+	if err := h.k8sClient.EnsureNamespace(ctx, namespace); err != nil {
+		logging.Logger.Error("Failed to ensure namespace",
+			zap.String("namespace", namespace),
+			zap.Error(err))
+		return response.InternalServerError(c, "Failed to create namespace")
+	}
+
+	annotations := map[string]string{
+		authz.FrozenAnnotation:   time.Now().UTC().Format(time.RFC3339),
+		authz.FrozenByAnnotation: user.Name,
+	}
+	var remove []string
+	if req.Reason != "" {
+		annotations[authz.FrozenReasonAnnotation] = req.Reason
+	} else {
+		remove = append(remove, authz.FrozenReasonAnnotation)
+	}
+	if err := h.k8sClient.UpdateNamespaceAnnotations(ctx, namespace, annotations, remove); err != nil {
+		logging.Logger.Error("Failed to freeze namespace",
+			zap.String("namespace", namespace),
+			zap.Error(err))
+		return response.InternalServerError(c, "Failed to freeze namespace")
+	}
+
+	logging.Logger.Warn("Namespace frozen",
+		zap.String("user", user.Name),
+		zap.String("namespace", namespace),
+		zap.String("reason", req.Reason))
+
+	return c.JSON(200, NamespaceFreezeResponse{
+		Scope:     scope,
+		Namespace: namespace,
+		Frozen:    true,
+		FrozenBy:  user.Name,
+		FrozenAt:  annotations[authz.FrozenAnnotation],
+		Reason:    req.Reason,
+	})
+}
+
+// UnfreezeNamespace handles POST /admin/namespaces/:scope/unfreeze
+func (h *Handler) UnfreezeNamespace(c echo.Context) error {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+	if user.Role != authz.Admin {
+		logging.LogDeniedWithIP("admin_required", user.Name, "POST /admin/namespaces/:scope/unfreeze", c.RealIP())
+		return response.Forbidden(c, "Admin role required")
+	}
+
+	scope := c.Param("scope")
+	namespace, err := h.developerNamespace(scope)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	remove := []string{authz.FrozenAnnotation, authz.FrozenByAnnotation, authz.FrozenReasonAnnotation}
+	if err := h.k8sClient.UpdateNamespaceAnnotations(c.Request().Context(), namespace, nil, remove); err != nil {
+		if apierrors.IsNotFound(err) {
+			return response.NotFound(c, fmt.Sprintf("Namespace for '%s' not found", scope))
+		}
+		logging.Logger.Error("Failed to unfreeze namespace",
+			zap.String("namespace", namespace),
+			zap.Error(err))
+		return response.InternalServerError(c, "Failed to unfreeze namespace")
+	}
+
+	logging.Logger.Warn("Namespace unfrozen",
+		zap.String("user", user.Name),
+		zap.String("namespace", namespace))
+
+	return c.JSON(200, NamespaceFreezeResponse{
+		Scope:     scope,
+		Namespace: namespace,
+		Frozen:    false,
+	})
+}
+
+// developerNamespace maps a scope (developer name) to its namespace; only developer namespaces can be frozen
+func (h *Handler) developerNamespace(scope string) (string, error) {
+	if scope == "" || scope == "global" {
+		return "", fmt.Errorf("scope must be a developer name")
+	}
+	namespace := h.nsManager.GetDeveloperNamespace(scope)
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return "", fmt.Errorf("invalid scope %q: %s", scope, strings.Join(errs, "; "))
+	}
+	return namespace, nil
+}
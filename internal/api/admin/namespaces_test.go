@@ -0,0 +1,88 @@
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/admin"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/k8s"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+var _ = Describe("Namespace freeze", func() {
+	var (
+		handler   *admin.Handler
+		k8sClient *k8s.Client
+		nsManager *authz.NamespaceManager
+		root      *middleware.User
+	)
+
+	BeforeEach(func() {
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		nsManager = authz.NewNamespaceManager(cfg)
+
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		k8sClient = k8s.NewClientFromClient(fake.NewClientBuilder().WithScheme(scheme).Build(), scheme)
+
+		handler = admin.NewHandler(k8sClient, nsManager, cfg, config.DefaultSettings(), admin.RuntimeInfo{})
+		root = &middleware.User{Name: "root", Role: authz.Admin}
+	})
+
+	call := func(fn func(echo.Context) error, action, scope, body string, user *middleware.User) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/admin/namespaces/"+scope+"/"+action, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("scope")
+		c.SetParamValues(scope)
+		c.Set("user", user)
+		Expect(fn(c)).To(Succeed())
+		return rec
+	}
+
+	It("should freeze and unfreeze a developer namespace", func() {
+		rec := call(handler.FreezeNamespace, "freeze", "alice", `{"reason":"incident 42"}`, root)
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+
+		var resp admin.NamespaceFreezeResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.Namespace).To(Equal("dev-alice"))
+		Expect(resp.Frozen).To(BeTrue())
+		Expect(resp.FrozenBy).To(Equal("root"))
+		Expect(resp.Reason).To(Equal("incident 42"))
+
+		err := nsManager.CheckNotFrozen(context.Background(), k8sClient, "dev-alice")
+		Expect(err).To(MatchError(authz.ErrNamespaceFrozen))
+		Expect(err.Error()).To(ContainSubstring("incident 42"))
+
+		rec = call(handler.UnfreezeNamespace, "unfreeze", "alice", "", root)
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+		Expect(nsManager.CheckNotFrozen(context.Background(), k8sClient, "dev-alice")).To(Succeed())
+	})
+
+	It("should reject the global namespace", func() {
+		rec := call(handler.FreezeNamespace, "freeze", "global", "", root)
+		Expect(rec.Code).To(Equal(400))
+	})
+
+	It("should reject non-admin users", func() {
+		rec := call(handler.FreezeNamespace, "freeze", "bob", "", &middleware.User{Name: "alice", Role: authz.User})
+		Expect(rec.Code).To(Equal(403))
+		Expect(nsManager.CheckNotFrozen(context.Background(), k8sClient, "dev-bob")).To(Succeed())
+	})
+})
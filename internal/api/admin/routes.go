@@ -0,0 +1,15 @@
+package admin
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterRoutes registers admin routes
+func RegisterRoutes(g *echo.Group, handler *Handler) {
+	// Authentication is applied via the group middleware; the handler checks for the admin role
+	g.GET("/config", handler.GetConfig)
+	g.GET("/images", handler.ListImages)
+	g.POST("/namespaces/:scope/freeze", handler.FreezeNamespace)
+	g.POST("/namespaces/:scope/unfreeze", handler.UnfreezeNamespace)
+	g.POST("/secrets/gc", handler.CollectOrphanedSecrets)
+}
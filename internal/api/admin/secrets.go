@@ -0,0 +1,101 @@
+package admin
+
+import (
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/logging"
+	"github.com/lissto-dev/api/pkg/response"
+)
+
+// Labels the secret handlers set on the Kubernetes Secrets holding LisstoSecret values
+const (
+	SecretManagedByLabel = "lissto.dev/managed-by"
+	SecretManagedByValue = "lissto-api"
+	SecretOwnerLabel     = "lissto.dev/owner" // Name of the owning LisstoSecret in the same namespace
+)
+
+// SecretGCRequest is the optional body of POST /admin/secrets/gc
+type SecretGCRequest struct {
+	DryRun bool `json:"dry_run,omitempty"` // Report orphaned secrets without deleting them
+}
+
+// SecretGCResponse lists the managed secrets collected (or, for a dry run, that would be)
+type SecretGCResponse struct {
+	DryRun  bool             `json:"dry_run"`
+	Scanned int              `json:"scanned"`
+	Removed []OrphanedSecret `json:"removed"`
+	Failed  []OrphanedSecret `json:"failed,omitempty"`
+}
+
+// OrphanedSecret is a managed Secret whose owner LisstoSecret no longer exists
+type OrphanedSecret struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Owner     string `json:"owner"`
+	Error     string `json:"error,omitempty"`
+}
+
+// CollectOrphanedSecrets handles POST /admin/secrets/gc
+// Owner references normally remove these secrets with their LisstoSecret; this catches the ones left behind
+func (h *Handler) CollectOrphanedSecrets(c echo.Context) error {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		return response.Unauthorized(c, "User not authenticated")
+	}
+	if user.Role != authz.Admin {
+		logging.LogDeniedWithIP("admin_required", user.Name, "POST /admin/secrets/gc", c.RealIP())
+		return response.Forbidden(c, "Admin role required")
+	}
+
+	var req SecretGCRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request")
+	}
+
+	ctx := c.Request().Context()
+	secrets, err := h.k8sClient.ListSecretsWithLabels(ctx, "", map[string]string{SecretManagedByLabel: SecretManagedByValue})
+	if err != nil {
+		logging.Logger.Error("Failed to list managed secrets", zap.Error(err))
+		return response.InternalServerError(c, "Failed to list managed secrets")
+	}
+
+	resp := SecretGCResponse{DryRun: req.DryRun, Scanned: len(secrets.Items), Removed: []OrphanedSecret{}}
+	for _, secret := range secrets.Items {
+		owner := secret.Labels[SecretOwnerLabel]
+		if owner == "" {
+			continue // Without an owner label there is nothing to check against
+		}
+		_, err := h.k8sClient.GetLisstoSecret(ctx, secret.Namespace, owner)
+		if err == nil {
+			continue
+		}
+		orphan := OrphanedSecret{Namespace: secret.Namespace, Name: secret.Name, Owner: owner}
+		if !apierrors.IsNotFound(err) {
+			orphan.Error = err.Error()
+			resp.Failed = append(resp.Failed, orphan)
+			continue
+		}
+
+		if !req.DryRun {
+			if err := h.k8sClient.DeleteSecret(ctx, secret.Namespace, secret.Name); err != nil && !apierrors.IsNotFound(err) {
+				orphan.Error = err.Error()
+				resp.Failed = append(resp.Failed, orphan)
+				continue
+			}
+		}
+		resp.Removed = append(resp.Removed, orphan)
+	}
+
+	logging.Logger.Warn("Collected orphaned secrets",
+		zap.String("user", user.Name),
+		zap.Bool("dry_run", req.DryRun),
+		zap.Int("scanned", resp.Scanned),
+		zap.Int("removed", len(resp.Removed)),
+		zap.Int("failed", len(resp.Failed)))
+
+	return c.JSON(200, resp)
+}
@@ -0,0 +1,108 @@
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/admin"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/k8s"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+var _ = Describe("Orphaned secret collection", func() {
+	var (
+		handler   *admin.Handler
+		k8sClient *k8s.Client
+	)
+
+	managedSecret := func(namespace, name, owner string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				admin.SecretManagedByLabel: admin.SecretManagedByValue,
+				admin.SecretOwnerLabel:     owner,
+			},
+		}}
+	}
+
+	BeforeEach(func() {
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		k8sClient = k8s.NewClientFromClient(fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&envv1alpha1.LisstoSecret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "dev-alice"}},
+			managedSecret("dev-alice", "db-secret", "db"),
+			managedSecret("dev-bob", "api-secret", "api"),
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unmanaged", Namespace: "dev-bob"}},
+		).Build(), scheme)
+
+		handler = admin.NewHandler(k8sClient, authz.NewNamespaceManager(cfg), cfg, config.DefaultSettings(), admin.RuntimeInfo{})
+	})
+
+	collect := func(body string, user *middleware.User) (*httptest.ResponseRecorder, admin.SecretGCResponse) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/admin/secrets/gc", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user", user)
+		Expect(handler.CollectOrphanedSecrets(c)).To(Succeed())
+
+		var resp admin.SecretGCResponse
+		if rec.Code == 200 {
+			Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		}
+		return rec, resp
+	}
+
+	root := &middleware.User{Name: "root", Role: authz.Admin}
+	orphan := admin.OrphanedSecret{Namespace: "dev-bob", Name: "api-secret", Owner: "api"}
+
+	It("should delete managed secrets whose LisstoSecret is gone and keep owned ones", func() {
+		rec, resp := collect("", root)
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+		Expect(resp.Scanned).To(Equal(2))
+		Expect(resp.Removed).To(Equal([]admin.OrphanedSecret{orphan}))
+
+		_, err := k8sClient.GetSecret(context.Background(), "dev-bob", "api-secret")
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		_, err = k8sClient.GetSecret(context.Background(), "dev-alice", "db-secret")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = k8sClient.GetSecret(context.Background(), "dev-bob", "unmanaged")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should only report orphans on a dry run", func() {
+		rec, resp := collect(`{"dry_run":true}`, root)
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+		Expect(resp.DryRun).To(BeTrue())
+		Expect(resp.Removed).To(Equal([]admin.OrphanedSecret{orphan}))
+
+		_, err := k8sClient.GetSecret(context.Background(), "dev-bob", "api-secret")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should require the admin role", func() {
+		rec, _ := collect("", &middleware.User{Name: "alice", Role: authz.User})
+		Expect(rec.Code).To(Equal(403))
+	})
+})
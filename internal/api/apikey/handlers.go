@@ -1,6 +1,9 @@
 package apikey
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/labstack/echo/v4"
 	"github.com/lissto-dev/api/internal/middleware"
 	"github.com/lissto-dev/api/pkg/authz"
@@ -37,16 +40,20 @@ func NewHandler(
 
 // CreateAPIKeyRequest represents the request to create a new API key
 type CreateAPIKeyRequest struct {
-	Name        string `json:"name" validate:"required"`
-	Role        string `json:"role" validate:"required"`
-	SlackUserID string `json:"slack_user_id,omitempty"`
+	Name        string     `json:"name" validate:"required"`
+	Role        string     `json:"role" validate:"required"`
+	SlackUserID string     `json:"slack_user_id,omitempty"`
+	Scopes      []string   `json:"scopes,omitempty"`     // Resource types the key is limited to
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // When the key stops being accepted
 }
 
 // CreateAPIKeyResponse represents the response after creating an API key
 type CreateAPIKeyResponse struct {
-	APIKey string `json:"api_key"`
-	Name   string `json:"name"`
-	Role   string `json:"role"`
+	APIKey    string     `json:"api_key"`
+	Name      string     `json:"name"`
+	Role      string     `json:"role"`
+	Scopes    []string   `json:"scopes,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
 
 // CreateAPIKey handles POST /_internal/api-keys
@@ -88,6 +95,15 @@ func (h *Handler) CreateAPIKey(c echo.Context) error {
 		return response.BadRequest(c, "Invalid role. Must be one of: deploy, user")
 	}
 
+	for _, scope := range req.Scopes {
+		if !authz.IsValidResourceType(scope) {
+			return response.BadRequest(c, fmt.Sprintf("Invalid scope %q. Must be one of: stack, blueprint, env, variable, secret", scope))
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return response.BadRequest(c, "expires_at must be in the future")
+	}
+
 	// Generate API key with role-based prefix
 	apiKeyValue, err := config.GenerateAPIKey(req.Role)
 	if err != nil {
@@ -101,6 +117,8 @@ func (h *Handler) CreateAPIKey(c echo.Context) error {
 		APIKey:      apiKeyValue,
 		Name:        req.Name,
 		SlackUserID: req.SlackUserID,
+		Scopes:      req.Scopes,
+		ExpiresAt:   req.ExpiresAt,
 	}
 
 	// Load current keys from secret (from API's own namespace)
@@ -147,9 +165,11 @@ func (h *Handler) CreateAPIKey(c echo.Context) error {
 
 	// Return the new API key (only on creation)
 	return response.Created(c, "API key created", CreateAPIKeyResponse{
-		APIKey: apiKeyValue,
-		Name:   req.Name,
-		Role:   req.Role,
+		APIKey:    apiKeyValue,
+		Name:      req.Name,
+		Role:      req.Role,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
 	})
 }
 
@@ -7,6 +7,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/prepare"
 	"github.com/lissto-dev/api/internal/middleware"
 	"github.com/lissto-dev/api/pkg/authz"
 	"github.com/lissto-dev/api/pkg/compose"
@@ -24,6 +25,8 @@ type Handler struct {
 	authorizer *authz.Authorizer
 	nsManager  *authz.NamespaceManager
 	config     *controllerconfig.Config
+
+	imageResolver prepare.ImageResolver
 }
 
 // NewHandler creates a new blueprint handler
@@ -32,12 +35,14 @@ func NewHandler(
 	authorizer *authz.Authorizer,
 	nsManager *authz.NamespaceManager,
 	config *controllerconfig.Config,
+	imageResolver prepare.ImageResolver,
 ) *Handler {
 	return &Handler{
-		k8sClient:  k8sClient,
-		authorizer: authorizer,
-		nsManager:  nsManager,
-		config:     config,
+		k8sClient:     k8sClient,
+		authorizer:    authorizer,
+		nsManager:     nsManager,
+		config:        config,
+		imageResolver: imageResolver,
 	}
 }
 
@@ -170,7 +175,9 @@ func (h *Handler) CreateBlueprint(c echo.Context) error {
 			zap.String("namespace", namespace),
 			zap.String("blueprint", targetNamespaceMatch.Name),
 			zap.String("identifier", identifier))
-		return c.String(200, identifier)
+		return common.HandleCreatedResponse(c, 200, &FormattableBlueprint{K8sObj: targetNamespaceMatch, NsManager: h.nsManager}, func() error {
+			return c.String(200, identifier)
+		})
 	}
 
 	if globalNamespaceMatch != nil && user.Role == authz.Deploy {
@@ -182,7 +189,9 @@ func (h *Handler) CreateBlueprint(c echo.Context) error {
 			zap.String("global_namespace", globalNamespace),
 			zap.String("blueprint", globalNamespaceMatch.Name),
 			zap.String("identifier", identifier))
-		return c.String(200, identifier)
+		return common.HandleCreatedResponse(c, 200, &FormattableBlueprint{K8sObj: globalNamespaceMatch, NsManager: h.nsManager}, func() error {
+			return c.String(200, identifier)
+		})
 	}
 
 	// Blueprint doesn't exist - create new one
@@ -209,6 +218,10 @@ func (h *Handler) CreateBlueprint(c echo.Context) error {
 		return c.String(500, "Failed to process blueprint metadata")
 	}
 
+	if rejected, err := common.RejectIfFrozen(c, h.nsManager, h.k8sClient, namespace, user.Name, "POST /blueprints"); rejected {
+		return err
+	}
+
 	// Ensure namespace exists
 	if err := h.k8sClient.EnsureNamespace(c.Request().Context(), namespace); err != nil {
 		logging.Logger.Error("Failed to create namespace",
@@ -257,9 +270,11 @@ func (h *Handler) CreateBlueprint(c echo.Context) error {
 		return c.String(500, "Failed to create blueprint")
 	}
 
-	// Return 201 with scoped identifier
+	// Return 201 with scoped identifier, or the blueprint when asked for a representation
 	identifier := h.nsManager.MustGenerateScopedID(namespace, blueprintName)
-	return c.String(201, identifier)
+	return common.HandleCreatedResponse(c, 201, &FormattableBlueprint{K8sObj: blueprint, NsManager: h.nsManager}, func() error {
+		return c.String(201, identifier)
+	})
 }
 
 // BlueprintResponse represents enriched blueprint data
@@ -415,29 +430,25 @@ func (h *Handler) DeleteBlueprint(c echo.Context) error {
 	// Resolve namespace from ID
 	targetNamespace, name, searchAll := h.nsManager.ResolveNamespaceFromID(idParam, allowedNS)
 
-	// Try to delete the blueprint
+	// Try to find the blueprint
 	userNS := h.nsManager.GetDeveloperNamespace(user.Name)
 	globalNS := h.nsManager.GetGlobalNamespace()
-	if h.deleteBlueprint(c, targetNamespace, name, searchAll, userNS, globalNS, allowedNS) {
-		return c.NoContent(204)
+	blueprint, found := h.findBlueprint(c, targetNamespace, name, searchAll, userNS, globalNS, allowedNS)
+	if !found {
+		return c.String(404, fmt.Sprintf("Blueprint '%s' not found", idParam))
 	}
 
-	return c.String(404, fmt.Sprintf("Blueprint '%s' not found", idParam))
-}
-
-// deleteBlueprint searches for and deletes a blueprint in the appropriate namespace(s)
-func (h *Handler) deleteBlueprint(c echo.Context, targetNS, name string, searchAll bool, userNS, globalNS string, allowedNS []string) bool {
-	ctx := c.Request().Context()
-
-	// Get ordered list of namespaces to search
-	namespaces := namespace.ResolveNamespacesToSearch(targetNS, userNS, globalNS, searchAll, allowedNS)
+	if rejected, err := common.RejectIfFrozen(c, h.nsManager, h.k8sClient, blueprint.Namespace, user.Name, "DELETE /blueprints/"+idParam); rejected {
+		return err
+	}
 
-	// Try to delete from each namespace in order
-	for _, ns := range namespaces {
-		if h.k8sClient.DeleteBlueprint(ctx, ns, name) == nil {
-			return true
-		}
+	if err := h.k8sClient.DeleteBlueprint(c.Request().Context(), blueprint.Namespace, blueprint.Name); err != nil {
+		logging.Logger.Error("Failed to delete blueprint",
+			zap.String("namespace", blueprint.Namespace),
+			zap.String("name", blueprint.Name),
+			zap.Error(err))
+		return c.String(500, "Failed to delete blueprint")
 	}
 
-	return false
+	return c.NoContent(204)
 }
@@ -228,6 +228,48 @@ var _ = Describe("FormattableBlueprint", func() {
 			})
 		})
 
+		Context("with noisy internal keys", func() {
+			It("should strip denied prefixes and keep user and lissto keys", func() {
+				config := &operatorConfig.Config{}
+				config.Namespaces.Global = "lissto-global"
+				config.Namespaces.DeveloperPrefix = "lissto-"
+
+				nsManager := authz.NewNamespaceManager(config)
+
+				bp := &envv1alpha1.Blueprint{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "noisy",
+						Namespace: "lissto-global",
+						Labels: map[string]string{
+							"kompose.service": "web",
+							"branch":          "main",
+						},
+						Annotations: map[string]string{
+							"kubectl.kubernetes.io/last-applied-configuration": "{}",
+							"kompose.version":  "1.37.0",
+							"lissto.dev/title": "Noisy",
+							"team":             "platform",
+						},
+						CreationTimestamp: metav1.Time{Time: time.Now()},
+					},
+				}
+
+				formattable := &blueprint.FormattableBlueprint{
+					K8sObj:    bp,
+					NsManager: nsManager,
+				}
+
+				detailed, err := formattable.ToDetailed()
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(detailed.Metadata.Labels).To(Equal(map[string]string{"branch": "main"}))
+				Expect(detailed.Metadata.Annotations).To(Equal(map[string]string{
+					"lissto.dev/title": "Noisy",
+					"team":             "platform",
+				}))
+			})
+		})
+
 		Context("with empty annotations and labels", func() {
 			It("should handle nil metadata gracefully", func() {
 				config := &operatorConfig.Config{}
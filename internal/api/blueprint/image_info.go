@@ -0,0 +1,103 @@
+package blueprint
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/image"
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// GetServiceImageInfo handles GET /blueprints/:id/services/:service/image-info
+// Resolves the service image like prepare does and reports the OS, entrypoint, user and exposed
+// ports recorded in its config, for onboarding and security review
+func (h *Handler) GetServiceImageInfo(c echo.Context) error {
+	idParam := c.Param("id")
+	serviceName := c.Param("service")
+	user, _ := middleware.GetUserFromContext(c)
+
+	inspector, ok := h.imageResolver.(prepare.ImageInspector)
+	if h.imageResolver == nil || !ok {
+		return c.String(503, "Image inspection is not available")
+	}
+
+	// Get allowed namespaces for authorization
+	allowedNS := h.authorizer.GetAllowedNamespaces(user.Role, authz.ActionRead, authz.ResourceBlueprint, user.Name)
+	if len(allowedNS) == 0 {
+		return c.String(403, "Permission denied: no accessible namespaces")
+	}
+
+	// Resolve namespace from ID
+	targetNamespace, bpName, searchAll := h.nsManager.ResolveNamespaceFromID(idParam, allowedNS)
+
+	// Try to find the blueprint
+	userNS := h.nsManager.GetDeveloperNamespace(user.Name)
+	globalNS := h.nsManager.GetGlobalNamespace()
+	blueprint, found := h.findBlueprint(c, targetNamespace, bpName, searchAll, userNS, globalNS, allowedNS)
+	if !found {
+		return c.String(404, fmt.Sprintf("Blueprint '%s' not found", idParam))
+	}
+
+	project, err := prepare.ParseDockerCompose(blueprint.Spec.DockerCompose)
+	if err != nil {
+		logging.Logger.Error("Failed to parse Docker Compose",
+			zap.String("blueprint", idParam),
+			zap.Error(err))
+		return c.String(400, "Invalid Docker Compose content")
+	}
+
+	service, ok := project.Services[serviceName]
+	if !ok {
+		return c.String(404, fmt.Sprintf("Service '%s' not found in blueprint '%s'", serviceName, idParam))
+	}
+
+	info, err := prepare.ResolveServiceImage(h.imageResolver, serviceName, service, compose.ExtractLisstoConfig(project), prepare.ResolveOptions{
+		Commit: c.QueryParam("commit"),
+		Branch: c.QueryParam("branch"),
+	})
+	if err != nil {
+		return c.String(404, err.Error())
+	}
+	imageRef := info.Digest
+	if imageRef == "" {
+		imageRef = info.Image
+	}
+
+	config, err := inspector.InspectImage(imageRef, service)
+	if err != nil {
+		logging.Logger.Warn("Failed to inspect service image",
+			zap.String("blueprint", idParam),
+			zap.String("service", serviceName),
+			zap.String("image", imageRef),
+			zap.Error(err))
+		if errors.Is(err, image.ErrRegistryUnavailable) {
+			return c.String(503, fmt.Sprintf("Registry unavailable for image '%s'", imageRef))
+		}
+		return c.String(502, fmt.Sprintf("Failed to read config of image '%s': %v", imageRef, err))
+	}
+
+	logging.Logger.Info("Service image info",
+		zap.String("user", user.Name),
+		zap.String("blueprint", idParam),
+		zap.String("service", serviceName),
+		zap.String("image", imageRef),
+		zap.String("os", config.OS),
+		zap.String("image_user", config.User))
+
+	return c.JSON(200, common.ServiceImageInfoResponse{
+		Blueprint: h.nsManager.MustGenerateScopedID(blueprint.Namespace, blueprint.Name),
+		Service:   serviceName,
+		Image:     imageRef,
+		Method:    info.Method,
+		Platform:  info.Platform,
+		Config:    config,
+	})
+}
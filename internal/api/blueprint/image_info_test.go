@@ -0,0 +1,92 @@
+package blueprint_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/blueprint"
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/k8s"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+	operatorConfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+// inspectingResolver returns a config for every image it resolved
+type inspectingResolver struct {
+	fakeResolver
+	inspected []string
+}
+
+func (r *inspectingResolver) InspectImage(imageRef string, _ types.ServiceConfig) (*common.ImageConfigInfo, error) {
+	r.inspected = append(r.inspected, imageRef)
+	for _, digest := range r.digests {
+		if digest == imageRef {
+			return &common.ImageConfigInfo{OS: "linux", Architecture: "amd64", User: "nginx", ExposedPorts: []string{"80/tcp"}}, nil
+		}
+	}
+	return nil, fmt.Errorf("image not found: %s", imageRef)
+}
+
+var _ = Describe("Blueprint service image info", func() {
+	var resolver *inspectingResolver
+
+	BeforeEach(func() {
+		Expect(logging.InitLogger("info", "console")).To(Succeed())
+		resolver = &inspectingResolver{fakeResolver: fakeResolver{digests: map[string]string{
+			"nginx:alpine": "index.docker.io/library/nginx@sha256:aaa",
+		}}}
+	})
+
+	imageInfo := func(service string) *httptest.ResponseRecorder {
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		bp := &envv1alpha1.Blueprint{
+			ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "dev-alice"},
+			Spec:       envv1alpha1.BlueprintSpec{DockerCompose: resolveCompose},
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(bp).Build()
+		cfg := &operatorConfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		nsManager := authz.NewNamespaceManager(cfg)
+		h := blueprint.NewHandler(k8s.NewClientFromClient(fakeClient, scheme), authz.NewAuthorizer(nsManager), nsManager, cfg, resolver)
+
+		req := httptest.NewRequest(http.MethodGet, "/blueprints/shop/services/"+service+"/image-info", nil)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.Set("user", &middleware.User{Name: "alice", Role: authz.User})
+		c.SetParamNames("id", "service")
+		c.SetParamValues("shop", service)
+		Expect(h.GetServiceImageInfo(c)).To(Succeed())
+		return rec
+	}
+
+	It("should report the config of the resolved image", func() {
+		rec := imageInfo("web")
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+
+		var resp common.ServiceImageInfoResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.Blueprint).To(Equal("alice/shop"))
+		Expect(resp.Image).To(Equal("index.docker.io/library/nginx@sha256:aaa"))
+		Expect(resp.Config.User).To(Equal("nginx"))
+		Expect(resp.Config.ExposedPorts).To(Equal([]string{"80/tcp"}))
+		Expect(resolver.inspected).To(Equal([]string{"index.docker.io/library/nginx@sha256:aaa"}))
+	})
+
+	It("should return 404 for unknown services", func() {
+		Expect(imageInfo("missing").Code).To(Equal(404))
+	})
+})
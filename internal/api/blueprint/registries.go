@@ -0,0 +1,138 @@
+package blueprint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// GetBlueprintRegistries handles GET /blueprints/:id/registries
+// Resolves the blueprint's images like prepare does and reports the registry hosts they come from
+func (h *Handler) GetBlueprintRegistries(c echo.Context) error {
+	idParam := c.Param("id")
+	user, _ := middleware.GetUserFromContext(c)
+
+	if h.imageResolver == nil {
+		return c.String(503, "Image resolution is not available")
+	}
+
+	// Get allowed namespaces for authorization
+	allowedNS := h.authorizer.GetAllowedNamespaces(user.Role, authz.ActionRead, authz.ResourceBlueprint, user.Name)
+	if len(allowedNS) == 0 {
+		return c.String(403, "Permission denied: no accessible namespaces")
+	}
+
+	// Resolve namespace from ID
+	targetNamespace, bpName, searchAll := h.nsManager.ResolveNamespaceFromID(idParam, allowedNS)
+
+	// Try to find the blueprint
+	userNS := h.nsManager.GetDeveloperNamespace(user.Name)
+	globalNS := h.nsManager.GetGlobalNamespace()
+	blueprint, found := h.findBlueprint(c, targetNamespace, bpName, searchAll, userNS, globalNS, allowedNS)
+	if !found {
+		return c.String(404, fmt.Sprintf("Blueprint '%s' not found", idParam))
+	}
+
+	project, err := prepare.ParseDockerCompose(blueprint.Spec.DockerCompose)
+	if err != nil {
+		logging.Logger.Error("Failed to parse Docker Compose",
+			zap.String("blueprint", idParam),
+			zap.Error(err))
+		return c.String(400, "Invalid Docker Compose content")
+	}
+
+	logging.Logger.Info("Blueprint registries request",
+		zap.String("user", user.Name),
+		zap.String("blueprint", idParam),
+		zap.String("env", c.QueryParam("env")),
+		zap.String("commit", c.QueryParam("commit")),
+		zap.String("branch", c.QueryParam("branch")))
+
+	// Resolve in detailed mode so one failing service does not hide the others
+	lisstoConfig := compose.ExtractLisstoConfig(project)
+	opts := prepare.ResolveOptions{
+		Commit:       c.QueryParam("commit"),
+		Branch:       c.QueryParam("branch"),
+		Detailed:     true,
+		AllowPending: true,
+	}
+
+	var results []common.DetailedImageResolutionInfo
+	for serviceName, service := range project.Services {
+		info, err := prepare.ResolveServiceImage(h.imageResolver, serviceName, service, lisstoConfig, opts)
+		if err != nil {
+			return c.String(400, err.Error())
+		}
+		results = append(results, info)
+	}
+
+	response := GroupImagesByRegistry(results)
+	response.Blueprint = h.nsManager.MustGenerateScopedID(blueprint.Namespace, blueprint.Name)
+	return c.JSON(200, response)
+}
+
+// GroupImagesByRegistry groups resolved service images by registry host
+// Pending services are listed under the registry their first build will be pushed to;
+// services without any resolvable reference are reported as unresolved
+func GroupImagesByRegistry(results []common.DetailedImageResolutionInfo) common.BlueprintRegistriesResponse {
+	byHost := make(map[string][]common.RegistryImage)
+	response := common.BlueprintRegistriesResponse{
+		Hosts:      []string{},
+		Registries: []common.RegistryImages{},
+	}
+
+	for _, info := range results {
+		ref := info.Digest
+		if ref == "" && info.Pending {
+			ref = info.Image
+		}
+		if ref == "" {
+			response.Unresolved = append(response.Unresolved, info.Service)
+			continue
+		}
+
+		// Only the repository part determines the host
+		repository, _, _ := strings.Cut(ref, "@")
+		parsed, err := name.ParseReference(repository)
+		if err != nil {
+			logging.Logger.Warn("Failed to parse image reference",
+				zap.String("service", info.Service),
+				zap.String("image", ref),
+				zap.Error(err))
+			response.Unresolved = append(response.Unresolved, info.Service)
+			continue
+		}
+
+		host := parsed.Context().RegistryStr()
+		byHost[host] = append(byHost[host], common.RegistryImage{
+			Service: info.Service,
+			Image:   info.Image,
+			Digest:  info.Digest,
+		})
+	}
+
+	for host := range byHost {
+		response.Hosts = append(response.Hosts, host)
+	}
+	sort.Strings(response.Hosts)
+	sort.Strings(response.Unresolved)
+
+	for _, host := range response.Hosts {
+		images := byHost[host]
+		sort.Slice(images, func(i, j int) bool { return images[i].Service < images[j].Service })
+		response.Registries = append(response.Registries, common.RegistryImages{Host: host, Images: images})
+	}
+
+	return response
+}
@@ -0,0 +1,140 @@
+package blueprint_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/blueprint"
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/image"
+	"github.com/lissto-dev/api/pkg/k8s"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+	operatorConfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+// fakeResolver resolves explicit images to canned digests and fails build resolution
+type fakeResolver struct {
+	digests map[string]string
+}
+
+func (f *fakeResolver) GetImageDigestWithServicePlatform(imageURL string, _ types.ServiceConfig) (string, error) {
+	if digest, ok := f.digests[imageURL]; ok {
+		return digest, nil
+	}
+	return "", fmt.Errorf("image %s not found", imageURL)
+}
+
+func (f *fakeResolver) ResolveImageDetailed(_ types.ServiceConfig, _ image.ResolutionConfig) (*image.DetailedImageResolutionResult, error) {
+	return &image.DetailedImageResolutionResult{}, fmt.Errorf("no candidates found")
+}
+
+const multiRegistryCompose = `
+services:
+  web:
+    image: nginx:alpine
+  api:
+    image: ghcr.io/acme/api:1.2.0
+  worker:
+    image: ghcr.io/acme/worker:1.2.0
+  db:
+    image: postgres:15
+    labels:
+      lissto.dev/image: 123456789012.dkr.ecr.eu-central-1.amazonaws.com/docker-hub/library/postgres:15
+  cache:
+    image: quay.io/acme/cache:missing
+`
+
+var _ = Describe("Blueprint registries", func() {
+	var resolver *fakeResolver
+
+	BeforeEach(func() {
+		Expect(logging.InitLogger("info", "console")).To(Succeed())
+		resolver = &fakeResolver{digests: map[string]string{
+			"nginx:alpine":              "index.docker.io/library/nginx@sha256:aaa",
+			"ghcr.io/acme/api:1.2.0":    "ghcr.io/acme/api@sha256:bbb",
+			"ghcr.io/acme/worker:1.2.0": "ghcr.io/acme/worker@sha256:ccc",
+			"123456789012.dkr.ecr.eu-central-1.amazonaws.com/docker-hub/library/postgres:15": "123456789012.dkr.ecr.eu-central-1.amazonaws.com/docker-hub/library/postgres@sha256:ddd",
+		}}
+	})
+
+	newHandler := func() *blueprint.Handler {
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+
+		bp := &envv1alpha1.Blueprint{
+			ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "dev-alice"},
+			Spec:       envv1alpha1.BlueprintSpec{DockerCompose: multiRegistryCompose},
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(bp).Build()
+
+		cfg := &operatorConfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		nsManager := authz.NewNamespaceManager(cfg)
+		return blueprint.NewHandler(k8s.NewClientFromClient(fakeClient, scheme), authz.NewAuthorizer(nsManager), nsManager, cfg, resolver)
+	}
+
+	get := func(id string, user *middleware.User) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/blueprints/"+id+"/registries?env=dev", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user", user)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		Expect(newHandler().GetBlueprintRegistries(c)).To(Succeed())
+		return rec
+	}
+
+	It("should report each distinct registry host of a multi-registry blueprint", func() {
+		rec := get("shop", &middleware.User{Name: "alice", Role: authz.User})
+		Expect(rec.Code).To(Equal(200))
+
+		var response common.BlueprintRegistriesResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+
+		Expect(response.Blueprint).To(Equal("alice/shop"))
+		Expect(response.Hosts).To(Equal([]string{
+			"123456789012.dkr.ecr.eu-central-1.amazonaws.com",
+			"ghcr.io",
+			"index.docker.io",
+		}))
+		Expect(response.Unresolved).To(ConsistOf("cache"))
+
+		Expect(response.Registries).To(HaveLen(3))
+		ghcr := response.Registries[1]
+		Expect(ghcr.Host).To(Equal("ghcr.io"))
+		Expect(ghcr.Images).To(Equal([]common.RegistryImage{
+			{Service: "api", Image: "ghcr.io/acme/api:1.2.0", Digest: "ghcr.io/acme/api@sha256:bbb"},
+			{Service: "worker", Image: "ghcr.io/acme/worker:1.2.0", Digest: "ghcr.io/acme/worker@sha256:ccc"},
+		}))
+	})
+
+	It("should not expose blueprints outside the user's namespaces", func() {
+		rec := get("shop", &middleware.User{Name: "bob", Role: authz.User})
+		Expect(rec.Code).To(Equal(404))
+	})
+
+	Describe("GroupImagesByRegistry", func() {
+		It("should list pending services under their target registry", func() {
+			response := blueprint.GroupImagesByRegistry([]common.DetailedImageResolutionInfo{
+				{Service: "app", Image: "registry.example.com/acme/app:main", Pending: true},
+			})
+
+			Expect(response.Hosts).To(Equal([]string{"registry.example.com"}))
+			Expect(response.Unresolved).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,89 @@
+package blueprint_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/blueprint"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/k8s"
+	"github.com/lissto-dev/api/pkg/logging"
+	operatorConfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+type testValidator struct {
+	validator *validator.Validate
+}
+
+func (v *testValidator) Validate(i interface{}) error {
+	return v.validator.Struct(i)
+}
+
+var _ = Describe("CreateBlueprint representation", func() {
+	var handler *blueprint.Handler
+
+	BeforeEach(func() {
+		Expect(logging.InitLogger("info", "console")).To(Succeed())
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+
+		cfg := &operatorConfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		cfg.Repos = map[string]operatorConfig.RepoConfig{"shop": {URL: "https://github.com/acme/shop"}}
+		nsManager := authz.NewNamespaceManager(cfg)
+		k8sClient := k8s.NewClientFromClient(fake.NewClientBuilder().WithScheme(scheme).Build(), scheme)
+		handler = blueprint.NewHandler(k8sClient, authz.NewAuthorizer(nsManager), nsManager, cfg, nil)
+	})
+
+	create := func(target string) *httptest.ResponseRecorder {
+		e := echo.New()
+		e.Validator = &testValidator{validator: validator.New()}
+		body := `{"compose":"services:\n  web:\n    image: nginx\n","repository":"https://github.com/acme/shop"}`
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user", &middleware.User{Name: "alice", Role: authz.User})
+		Expect(handler.CreateBlueprint(c)).To(Succeed())
+		return rec
+	}
+
+	It("should return the scoped identifier by default", func() {
+		rec := create("/blueprints")
+
+		Expect(rec.Code).To(Equal(201), rec.Body.String())
+		Expect(rec.Body.String()).To(HavePrefix("alice/"))
+	})
+
+	It("should return the created blueprint with ?return=representation", func() {
+		rec := create("/blueprints?return=representation")
+
+		Expect(rec.Code).To(Equal(201), rec.Body.String())
+		var response blueprint.BlueprintResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+		Expect(response.ID).To(HavePrefix("alice/"))
+		Expect(response.Content.Infra).To(ConsistOf("web"))
+	})
+
+	It("should return the existing blueprint for duplicate content", func() {
+		first := create("/blueprints")
+		Expect(first.Code).To(Equal(201))
+
+		rec := create("/blueprints?return=representation")
+
+		Expect(rec.Code).To(Equal(200))
+		var response blueprint.BlueprintResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+		Expect(response.ID).To(Equal(first.Body.String()))
+	})
+})
@@ -0,0 +1,106 @@
+package blueprint
+
+import (
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// ResolveService handles GET /blueprints/:id/services/:service/resolve
+// Debug aid: resolves a single service exactly like prepare does and returns the full decision,
+// including every candidate tried
+func (h *Handler) ResolveService(c echo.Context) error {
+	idParam := c.Param("id")
+	serviceName := c.Param("service")
+	envName := c.QueryParam("env")
+	user, _ := middleware.GetUserFromContext(c)
+
+	if h.imageResolver == nil {
+		return c.String(503, "Image resolution is not available")
+	}
+
+	// Get allowed namespaces for authorization
+	allowedNS := h.authorizer.GetAllowedNamespaces(user.Role, authz.ActionRead, authz.ResourceBlueprint, user.Name)
+	if len(allowedNS) == 0 {
+		return c.String(403, "Permission denied: no accessible namespaces")
+	}
+
+	// Resolve namespace from ID
+	targetNamespace, bpName, searchAll := h.nsManager.ResolveNamespaceFromID(idParam, allowedNS)
+
+	// Try to find the blueprint
+	userNS := h.nsManager.GetDeveloperNamespace(user.Name)
+	globalNS := h.nsManager.GetGlobalNamespace()
+	blueprint, found := h.findBlueprint(c, targetNamespace, bpName, searchAll, userNS, globalNS, allowedNS)
+	if !found {
+		return c.String(404, fmt.Sprintf("Blueprint '%s' not found", idParam))
+	}
+
+	// Exposed URLs depend on the env, which must exist like in prepare
+	if envName != "" {
+		if _, err := h.k8sClient.GetEnv(c.Request().Context(), userNS, envName); err != nil {
+			return c.String(404, fmt.Sprintf("Env '%s' not found", envName))
+		}
+	}
+
+	project, err := prepare.ParseDockerCompose(blueprint.Spec.DockerCompose)
+	if err != nil {
+		logging.Logger.Error("Failed to parse Docker Compose",
+			zap.String("blueprint", idParam),
+			zap.Error(err))
+		return c.String(400, "Invalid Docker Compose content")
+	}
+
+	service, ok := project.Services[serviceName]
+	if !ok {
+		return c.String(404, fmt.Sprintf("Service '%s' not found in blueprint '%s'", serviceName, idParam))
+	}
+
+	lisstoConfig := compose.ExtractLisstoConfig(project)
+	exposePreprocessor, err := prepare.NewExposePreprocessor(h.config, config.IngressSettings{}).WithDefaultVisibility(lisstoConfig.ExposeDefault)
+	if err != nil {
+		return c.String(400, err.Error())
+	}
+
+	// Detailed mode records failures in the result, so a failed resolution is still a 200
+	info, err := prepare.ResolveExposedServiceImage(
+		h.imageResolver,
+		exposePreprocessor,
+		serviceName,
+		service,
+		lisstoConfig,
+		envName,
+		prepare.ResolveOptions{
+			Commit:   c.QueryParam("commit"),
+			Branch:   c.QueryParam("branch"),
+			Detailed: true,
+		},
+	)
+	if err != nil {
+		return c.String(400, err.Error())
+	}
+
+	logging.Logger.Info("Service image resolution decision",
+		zap.String("user", user.Name),
+		zap.String("blueprint", idParam),
+		zap.String("service", serviceName),
+		zap.String("env", envName),
+		zap.String("commit", c.QueryParam("commit")),
+		zap.String("branch", c.QueryParam("branch")),
+		zap.String("method", info.Method),
+		zap.String("registry", info.Registry),
+		zap.String("image_name", info.ImageName),
+		zap.String("selected", info.Image),
+		zap.String("digest", info.Digest),
+		zap.Any("candidates", info.Candidates))
+
+	return c.JSON(200, info)
+}
@@ -0,0 +1,152 @@
+package blueprint_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/blueprint"
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/image"
+	"github.com/lissto-dev/api/pkg/k8s"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+	operatorConfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+// candidateResolver resolves build services from the main branch tag and explicit images from a map
+type candidateResolver struct {
+	fakeResolver
+}
+
+func (r *candidateResolver) ResolveImageDetailed(service types.ServiceConfig, config image.ResolutionConfig) (*image.DetailedImageResolutionResult, error) {
+	repo := fmt.Sprintf("%s/%s", config.ComposeRegistry, service.Name)
+	return &image.DetailedImageResolutionResult{
+		FinalImage: repo + "@sha256:eee",
+		Method:     "branch",
+		Selected:   repo + ":" + config.Branch,
+		Registry:   config.ComposeRegistry,
+		ImageName:  service.Name,
+		Candidates: []common.ImageCandidate{
+			{ImageURL: repo + ":" + config.Commit, Tag: config.Commit, Source: "commit", Error: "not found"},
+			{ImageURL: repo + ":" + config.Branch, Tag: config.Branch, Source: "branch", Success: true, Digest: "sha256:eee"},
+		},
+	}, nil
+}
+
+const resolveCompose = `
+x-lissto:
+  registry: registry.acme.io
+services:
+  api:
+    build: .
+    labels:
+      lissto.dev/expose: internal
+  web:
+    image: nginx:alpine
+`
+
+var _ = Describe("Blueprint service resolution", func() {
+	var (
+		resolver *candidateResolver
+		cfg      *operatorConfig.Config
+	)
+
+	BeforeEach(func() {
+		Expect(logging.InitLogger("info", "console")).To(Succeed())
+		resolver = &candidateResolver{fakeResolver{digests: map[string]string{
+			"nginx:alpine": "index.docker.io/library/nginx@sha256:aaa",
+		}}}
+		cfg = &operatorConfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		cfg.Stacks.Ingress.Internal = &operatorConfig.VisibilityConfig{IngressClass: "nginx", HostSuffix: ".dev.acme.io"}
+	})
+
+	resolve := func(service, query string) *httptest.ResponseRecorder {
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		bp := &envv1alpha1.Blueprint{
+			ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "dev-alice"},
+			Spec:       envv1alpha1.BlueprintSpec{DockerCompose: resolveCompose},
+		}
+		env := &envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(bp, env).Build()
+		nsManager := authz.NewNamespaceManager(cfg)
+		h := blueprint.NewHandler(k8s.NewClientFromClient(fakeClient, scheme), authz.NewAuthorizer(nsManager), nsManager, cfg, resolver)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/blueprints/shop/services/"+service+"/resolve?"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user", &middleware.User{Name: "alice", Role: authz.User})
+		c.SetParamNames("id", "service")
+		c.SetParamValues("shop", service)
+		Expect(h.ResolveService(c)).To(Succeed())
+		return rec
+	}
+
+	// prepareEntries resolves every service the way a detailed prepare does
+	prepareEntries := func(env, commit, branch string) map[string]common.DetailedImageResolutionInfo {
+		project, err := prepare.ParseDockerCompose(resolveCompose)
+		Expect(err).NotTo(HaveOccurred())
+		entries := make(map[string]common.DetailedImageResolutionInfo)
+		for name, service := range project.Services {
+			info, err := prepare.ResolveExposedServiceImage(resolver, prepare.NewExposePreprocessor(cfg, config.IngressSettings{}), name, service,
+				compose.ExtractLisstoConfig(project), env, prepare.ResolveOptions{Commit: commit, Branch: branch, Detailed: true})
+			Expect(err).NotTo(HaveOccurred())
+			entries[name] = info
+		}
+		return entries
+	}
+
+	It("should return the same detailed result as the full prepare entry", func() {
+		rec := resolve("api", "env=dev&commit=abc123&branch=main")
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+
+		var info common.DetailedImageResolutionInfo
+		Expect(json.Unmarshal(rec.Body.Bytes(), &info)).To(Succeed())
+		Expect(info).To(Equal(prepareEntries("dev", "abc123", "main")["api"]))
+
+		Expect(info.Method).To(Equal("branch"))
+		Expect(info.Registry).To(Equal("registry.acme.io"))
+		Expect(info.Candidates).To(HaveLen(2))
+		Expect(info.Candidates[0].Success).To(BeFalse())
+		Expect(info.Candidates[1].Digest).To(Equal("sha256:eee"))
+		Expect(info.Exposed).To(BeTrue())
+		Expect(info.URL).NotTo(BeEmpty())
+	})
+
+	It("should resolve explicit images like prepare", func() {
+		rec := resolve("web", "")
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+
+		var info common.DetailedImageResolutionInfo
+		Expect(json.Unmarshal(rec.Body.Bytes(), &info)).To(Succeed())
+		Expect(info).To(Equal(prepareEntries("", "", "")["web"]))
+		Expect(info.Digest).To(Equal("index.docker.io/library/nginx@sha256:aaa"))
+	})
+
+	It("should return 404 for an unknown service", func() {
+		rec := resolve("missing", "")
+		Expect(rec.Code).To(Equal(404))
+	})
+
+	It("should return 404 for an unknown env", func() {
+		rec := resolve("api", "env=nope")
+		Expect(rec.Code).To(Equal(404))
+	})
+})
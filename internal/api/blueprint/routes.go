@@ -9,6 +9,9 @@ func RegisterRoutes(g *echo.Group, handler *Handler) {
 	// All authorization is handled in the handler methods
 	g.GET("", handler.GetBlueprints)
 	g.GET("/:id", handler.GetBlueprint)
+	g.GET("/:id/registries", handler.GetBlueprintRegistries)
+	g.GET("/:id/services/:service/resolve", handler.ResolveService)
+	g.GET("/:id/services/:service/image-info", handler.GetServiceImageInfo)
 	g.POST("", handler.CreateBlueprint)
 	g.DELETE("/:id", handler.DeleteBlueprint)
 }
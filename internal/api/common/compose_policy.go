@@ -0,0 +1,47 @@
+package common
+
+import (
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// ComposePolicyResponse is the 400 body listing every denied compose feature in use
+type ComposePolicyResponse struct {
+	Error      string                    `json:"error"`
+	Violations []compose.PolicyViolation `json:"violations"`
+}
+
+// RejectPolicyViolations responds 400 with the violations when the project uses denied compose features
+// It reports whether a response was written; the caller then returns the error as is
+func RejectPolicyViolations(c echo.Context, project *types.Project, denied []string) (bool, error) {
+	if len(denied) == 0 {
+		return false, nil
+	}
+	violations := compose.CheckPolicy(project, denied)
+	if len(violations) == 0 {
+		return false, nil
+	}
+
+	features := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		features = append(features, violation.Service+":"+violation.Feature)
+	}
+	logging.Logger.Info("Rejected compose using denied features",
+		zap.String("path", c.Path()),
+		zap.Strings("violations", features))
+
+	return true, c.JSON(400, ComposePolicyResponse{
+		Error:      "Compose uses denied features",
+		Violations: violations,
+	})
+}
+
+// LabelPolicyResponse is the 400 body listing every lissto label the user's role may not use
+type LabelPolicyResponse struct {
+	Error  string                   `json:"error"`
+	Labels []compose.LabelViolation `json:"labels"`
+}
@@ -2,6 +2,7 @@ package common
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -22,6 +23,7 @@ type DetailedMetadata struct {
 
 // ExtractDetailedMetadata extracts and normalizes metadata from k8s objects
 // Uses the controller's NormalizeToScope for namespace normalization
+// Labels and annotations matching the configured deny-list are stripped
 // Returns error if namespace type is unknown
 func ExtractDetailedMetadata(obj metav1.ObjectMeta, nsManager *authz.NamespaceManager) (DetailedMetadata, error) {
 	normalizedNS, err := nsManager.NormalizeToScope(obj.Namespace)
@@ -32,8 +34,8 @@ func ExtractDetailedMetadata(obj metav1.ObjectMeta, nsManager *authz.NamespaceMa
 	return DetailedMetadata{
 		Name:        obj.Name,
 		Namespace:   normalizedNS,
-		Labels:      obj.Labels,
-		Annotations: obj.Annotations,
+		Labels:      FilterMetadataKeys(obj.Labels),
+		Annotations: FilterMetadataKeys(obj.Annotations),
 		CreatedAt:   obj.CreationTimestamp.Format(time.RFC3339),
 	}, nil
 }
@@ -69,6 +71,50 @@ type Formattable interface {
 // HandleFormatResponse handles ?format=detailed query parameter for any Formattable resource
 // This is the single place where format logic lives - called by ALL resource handlers
 func HandleFormatResponse(c echo.Context, resource Formattable) error {
+	return respondFormatted(c, 200, resource)
+}
+
+// ReturnRepresentation asks a create endpoint to respond with the created resource
+const ReturnRepresentation = "representation"
+
+// WantsRepresentation reports whether the client asked for the created resource rather than the
+// endpoint's legacy response, with ?return=representation or Prefer: return=representation (RFC 7240)
+func WantsRepresentation(c echo.Context) bool {
+	if c.QueryParam("return") == ReturnRepresentation {
+		return true
+	}
+	for _, preference := range strings.Split(c.Request().Header.Get("Prefer"), ",") {
+		if strings.TrimSpace(preference) == "return="+ReturnRepresentation {
+			return true
+		}
+	}
+	return false
+}
+
+// AcceptsJSON reports whether the client listed application/json in its Accept header
+// Endpoints answering 204 by default use it to return a body only to clients that want one
+func AcceptsJSON(c echo.Context) bool {
+	for _, mediaType := range strings.Split(c.Request().Header.Get(echo.HeaderAccept), ",") {
+		mediaType, _, _ = strings.Cut(mediaType, ";")
+		if strings.TrimSpace(mediaType) == echo.MIMEApplicationJSON {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleCreatedResponse responds to a create request: with the resource (honoring ?format=detailed)
+// when the client asked for a representation, otherwise with the endpoint's legacy response
+func HandleCreatedResponse(c echo.Context, code int, resource Formattable, legacy func() error) error {
+	if !WantsRepresentation(c) {
+		return legacy()
+	}
+	c.Response().Header().Set("Preference-Applied", "return="+ReturnRepresentation)
+	return respondFormatted(c, code, resource)
+}
+
+// respondFormatted writes the standard or, with ?format=detailed, the detailed format of a resource
+func respondFormatted(c echo.Context, code int, resource Formattable) error {
 	format := c.QueryParam("format")
 
 	if format == "detailed" {
@@ -77,9 +123,9 @@ func HandleFormatResponse(c echo.Context, resource Formattable) error {
 			logging.Logger.Error("Failed to format detailed response", zap.Error(err))
 			return c.String(500, "Failed to extract resource details")
 		}
-		return c.JSON(200, detailed)
+		return c.JSON(code, detailed)
 	}
 
 	// Default: return standard format
-	return c.JSON(200, resource.ToStandard())
+	return c.JSON(code, resource.ToStandard())
 }
@@ -0,0 +1,91 @@
+package common_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/internal/api/common"
+)
+
+// namedResource is a Formattable returning its name in both formats
+type namedResource struct{ name string }
+
+func (r namedResource) ToDetailed() (common.DetailedResponse, error) {
+	return common.DetailedResponse{Metadata: common.DetailedMetadata{Name: r.name}}, nil
+}
+
+func (r namedResource) ToStandard() interface{} {
+	return map[string]string{"name": r.name}
+}
+
+var _ = Describe("HandleCreatedResponse", func() {
+	respond := func(target string, prefer string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, nil)
+		if prefer != "" {
+			req.Header.Set("Prefer", prefer)
+		}
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		Expect(common.HandleCreatedResponse(c, 201, namedResource{name: "web"}, func() error {
+			return c.String(201, "alice/web")
+		})).To(Succeed())
+		return rec
+	}
+
+	It("should keep the legacy response by default", func() {
+		rec := respond("/envs", "")
+
+		Expect(rec.Code).To(Equal(201))
+		Expect(rec.Body.String()).To(Equal("alice/web"))
+		Expect(rec.Header().Get("Preference-Applied")).To(BeEmpty())
+	})
+
+	It("should return the resource for ?return=representation", func() {
+		rec := respond("/envs?return=representation", "")
+
+		Expect(rec.Code).To(Equal(201))
+		Expect(rec.Body.String()).To(MatchJSON(`{"name":"web"}`))
+		Expect(rec.Header().Get("Preference-Applied")).To(Equal("return=representation"))
+	})
+
+	It("should return the resource for the Prefer header", func() {
+		rec := respond("/envs", "respond-async, return=representation")
+
+		Expect(rec.Body.String()).To(MatchJSON(`{"name":"web"}`))
+	})
+
+	It("should honor ?format=detailed in the representation", func() {
+		rec := respond("/envs?return=representation&format=detailed", "")
+
+		Expect(rec.Code).To(Equal(201))
+		Expect(rec.Body.String()).To(MatchJSON(`{"metadata":{"name":"web","namespace":""},"spec":null}`))
+	})
+
+	It("should ignore other return preferences", func() {
+		rec := respond("/envs?return=minimal", "return=minimal")
+
+		Expect(rec.Body.String()).To(Equal("alice/web"))
+	})
+})
+
+var _ = Describe("AcceptsJSON", func() {
+	accepts := func(accept string) bool {
+		req := httptest.NewRequest(http.MethodDelete, "/stacks/web", nil)
+		req.Header.Set(echo.HeaderAccept, accept)
+		return common.AcceptsJSON(echo.New().NewContext(req, httptest.NewRecorder()))
+	}
+
+	It("should match application/json among other media types", func() {
+		Expect(accepts("text/plain, application/json;q=0.9")).To(BeTrue())
+	})
+
+	It("should not match wildcards or other types", func() {
+		Expect(accepts("*/*")).To(BeFalse())
+		Expect(accepts("text/plain")).To(BeFalse())
+		Expect(accepts("")).To(BeFalse())
+	})
+})
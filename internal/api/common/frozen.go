@@ -0,0 +1,32 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// RejectIfFrozen responds 423 Locked when an admin froze the namespace
+// It reports whether a response was written; the caller then returns the error as is
+func RejectIfFrozen(c echo.Context, nsManager *authz.NamespaceManager, getter authz.NamespaceGetter, namespace, userName, endpoint string) (bool, error) {
+	err := nsManager.CheckNotFrozen(c.Request().Context(), getter, namespace)
+	if err == nil {
+		return false, nil
+	}
+
+	if errors.Is(err, authz.ErrNamespaceFrozen) {
+		logging.LogDeniedWithIP("namespace_frozen", userName, endpoint, c.RealIP())
+		return true, c.String(http.StatusLocked, fmt.Sprintf("Changes in namespace '%s' are blocked: %v", namespace, err))
+	}
+
+	logging.Logger.Error("Failed to check namespace freeze",
+		zap.String("namespace", namespace),
+		zap.Error(err))
+	return true, c.String(500, "Failed to check namespace status")
+}
@@ -0,0 +1,71 @@
+package common
+
+import (
+	"strings"
+	"sync"
+)
+
+// lisstoKeyPrefix marks lissto-managed keys, which are never stripped
+const lisstoKeyPrefix = "lissto.dev/"
+
+// DefaultStrippedMetadataPrefixes are label/annotation key prefixes hidden from detailed responses
+// unless overridden in configuration
+var DefaultStrippedMetadataPrefixes = []string{
+	"kubectl.kubernetes.io/",
+	"kompose.",
+}
+
+var (
+	strippedPrefixesMu sync.RWMutex
+	strippedPrefixes   = DefaultStrippedMetadataPrefixes
+)
+
+// SetStrippedMetadataPrefixes configures the deny-list used by all detailed responses
+// A nil slice restores the defaults, an empty slice disables stripping
+func SetStrippedMetadataPrefixes(prefixes []string) {
+	strippedPrefixesMu.Lock()
+	defer strippedPrefixesMu.Unlock()
+	if prefixes == nil {
+		strippedPrefixes = DefaultStrippedMetadataPrefixes
+		return
+	}
+	strippedPrefixes = prefixes
+}
+
+// StrippedMetadataPrefixes returns the deny-list currently in effect
+func StrippedMetadataPrefixes() []string {
+	strippedPrefixesMu.RLock()
+	defer strippedPrefixesMu.RUnlock()
+	return append([]string(nil), strippedPrefixes...)
+}
+
+// FilterMetadataKeys returns a copy of labels/annotations without denied keys
+// Lissto-managed keys (lissto.dev/*) are always kept
+func FilterMetadataKeys(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+
+	strippedPrefixesMu.RLock()
+	prefixes := strippedPrefixes
+	strippedPrefixesMu.RUnlock()
+
+	filtered := make(map[string]string, len(values))
+	for key, value := range values {
+		if !strings.HasPrefix(key, lisstoKeyPrefix) && hasAnyPrefix(key, prefixes) {
+			continue
+		}
+		filtered[key] = value
+	}
+	return filtered
+}
+
+// hasAnyPrefix reports whether key starts with any of the prefixes
+func hasAnyPrefix(key string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if prefix != "" && strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package common_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/internal/api/common"
+)
+
+var _ = Describe("FilterMetadataKeys", func() {
+	AfterEach(func() {
+		common.SetStrippedMetadataPrefixes(nil)
+	})
+
+	It("should strip default prefixes and keep user keys", func() {
+		filtered := common.FilterMetadataKeys(map[string]string{
+			"kubectl.kubernetes.io/last-applied-configuration": "{}",
+			"kompose.cmd":          "kompose convert",
+			"app.example.com/team": "platform",
+			"lissto.dev/stack":     "my-stack",
+		})
+
+		Expect(filtered).To(Equal(map[string]string{
+			"app.example.com/team": "platform",
+			"lissto.dev/stack":     "my-stack",
+		}))
+	})
+
+	It("should use configured prefixes instead of the defaults", func() {
+		common.SetStrippedMetadataPrefixes([]string{"internal.example.com/"})
+
+		filtered := common.FilterMetadataKeys(map[string]string{
+			"internal.example.com/build": "42",
+			"kompose.cmd":                "kompose convert",
+		})
+
+		Expect(filtered).To(Equal(map[string]string{"kompose.cmd": "kompose convert"}))
+	})
+
+	It("should never strip lissto-managed keys", func() {
+		common.SetStrippedMetadataPrefixes([]string{"lissto"})
+
+		filtered := common.FilterMetadataKeys(map[string]string{
+			"lissto.dev/created-by": "alice",
+			"lissto-legacy":         "x",
+		})
+
+		Expect(filtered).To(Equal(map[string]string{"lissto.dev/created-by": "alice"}))
+	})
+
+	It("should disable stripping with an empty list", func() {
+		common.SetStrippedMetadataPrefixes([]string{})
+
+		filtered := common.FilterMetadataKeys(map[string]string{"kompose.cmd": "kompose convert"})
+
+		Expect(filtered).To(HaveKey("kompose.cmd"))
+	})
+
+	It("should keep nil maps nil", func() {
+		Expect(common.FilterMetadataKeys(nil)).To(BeNil())
+	})
+})
@@ -106,13 +106,58 @@ func generateRandomSuffix() string {
 }
 
 // PrepareStackRequest for preparing stack images
+// Exactly one of Blueprint, Blueprints or Compose must be set
 type PrepareStackRequest struct {
+	Blueprint string `json:"blueprint,omitempty"`
+	// Blueprints are merged into one stack (e.g. an infra and an app blueprint); service names must not collide
+	Blueprints []string `json:"blueprints,omitempty" validate:"omitempty,dive,required"`
+	Compose    string   `json:"compose,omitempty"`       // Inline docker-compose content, used instead of a blueprint
+	Env        string   `json:"env" validate:"required"` // Required: Env name for calculating exposed service URLs
+	Commit     string   `json:"commit,omitempty"`        // Optional: Git commit hash
+	Branch     string   `json:"branch,omitempty"`
+	Tag        string   `json:"tag,omitempty"`
+	Detailed   bool     `json:"detailed,omitempty"` // Whether to return detailed response with all candidates
+	// Optional: resolve build-only services without a published image to a pending placeholder
+	// (same as labelling every such service with lissto.dev/build-pending: allow)
+	AllowPending bool `json:"allow_pending,omitempty"`
+	// Optional: fail instead of warning when validation finds problems (e.g. missing TLS secrets)
+	Strict bool `json:"strict,omitempty"`
+	// Optional: pick the newest candidate tag pushed before this time (RFC 3339), e.g. the commit time
+	ResolvedBefore *time.Time `json:"resolved_before,omitempty"`
+	// Optional: registry credential for this request's image lookups only (TLS connections only)
+	RegistryAuth *RegistryAuth `json:"registry_auth,omitempty"`
+	// Optional: host suffix replacing the configured one for exposed services, e.g. pr-123.preview.example.com
+	// (admins and roles with hostOverride, within api.ingress.previewDomains)
+	HostOverrideSuffix string `json:"host_override_suffix,omitempty"`
+}
+
+// RegistryAuth is a short-lived registry credential sent with a prepare request
+// It is used for that request's lookups and never stored or logged
+type RegistryAuth struct {
+	Registry string `json:"registry" validate:"required"` // Registry host, e.g. ghcr.io
+	Token    string `json:"token,omitempty"`              // Bearer token, or Username and Password
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// PreparePlanRequest for planning image resolution without contacting registries
+type PreparePlanRequest struct {
 	Blueprint string `json:"blueprint" validate:"required"`
-	Env       string `json:"env" validate:"required"` // Required: Env name for calculating exposed service URLs
-	Commit    string `json:"commit,omitempty"`        // Optional: Git commit hash
-	Branch    string `json:"branch,omitempty"`
-	Tag       string `json:"tag,omitempty"`
-	Detailed  bool   `json:"detailed,omitempty"` // Whether to return detailed response with all candidates
+	Commit    string `json:"commit,omitempty"` // Optional: Git commit hash
+	Branch    string `json:"branch,omitempty"` // Optional: Git branch name
+}
+
+// BatchPrepareStackRequest for preparing one blueprint for several envs at once
+// Images are resolved once and shared; exposed URLs are computed per env
+type BatchPrepareStackRequest struct {
+	Blueprint    string   `json:"blueprint" validate:"required"`
+	Envs         []string `json:"envs" validate:"required,min=1,dive,required"` // Env names (scoped to logged-in user)
+	Commit       string   `json:"commit,omitempty"`                             // Optional: Git commit hash
+	Branch       string   `json:"branch,omitempty"`                             // Optional: Git branch name
+	Detailed     bool     `json:"detailed,omitempty"`                           // Record resolution failures instead of failing
+	AllowPending bool     `json:"allow_pending,omitempty"`
+	// Optional: pick the newest candidate tag pushed before this time (RFC 3339)
+	ResolvedBefore *time.Time `json:"resolved_before,omitempty"`
 }
 
 func (r *PrepareStackRequest) GetBranch() string { return r.Branch }
@@ -127,12 +172,50 @@ type CreateEnvRequest struct {
 
 // CreateStackRequest for creating a stack (simplified)
 type CreateStackRequest struct {
-	Blueprint string `json:"blueprint" validate:"required"`
+	Blueprint string `json:"blueprint,omitempty"`            // Required unless the request ID was prepared from inline compose
 	Env       string `json:"env" validate:"required"`        // Env name (scoped to logged-in user)
 	RequestID string `json:"request_id" validate:"required"` // Request ID from prepare API
+	// Optional: create the stack even if some services are still pending their first build
+	AllowPending bool `json:"allow_pending,omitempty"`
+	// Optional: human-friendly description and tags for finding the stack later
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	// Optional: host suffix for exposed services, defaults to the one given at prepare
+	HostOverrideSuffix string `json:"host_override_suffix,omitempty"`
+}
+
+// CloneStackRequest for copying a stack into another env without preparing again
+type CloneStackRequest struct {
+	Env string `json:"env" validate:"required"` // Target env name (scoped to logged-in user)
+	// Optional: description and tags of the clone, the source's are kept when unset
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// ExecStackRequest for running a one-off command in a stack service
+type ExecStackRequest struct {
+	Service string   `json:"service" validate:"required"`
+	Command []string `json:"command" validate:"required,min=1"`
+}
+
+// RecreateServiceRequest for force-recreating the pods of one stack service
+type RecreateServiceRequest struct {
+	// Strategy is "delete" (default: delete the pods, their controller recreates them)
+	// or "rollout" (rolling restart through the workload's restart annotation)
+	Strategy string `json:"strategy,omitempty" validate:"omitempty,oneof=delete rollout"`
 }
 
 // UpdateStackRequest for updating a stack
 type UpdateStackRequest struct {
 	Blueprint string `json:"blueprint,omitempty"`
 }
+
+// RefreshEnvImagesRequest for re-resolving the images of all stacks in an env
+type RefreshEnvImagesRequest struct {
+	DryRun bool `json:"dry_run,omitempty"` // Report changes without updating stacks
+}
+
+// SetStackProtectionRequest for toggling delete protection on a stack
+type SetStackProtectionRequest struct {
+	Protected *bool `json:"protected" validate:"required"`
+}
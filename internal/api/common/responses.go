@@ -1,6 +1,10 @@
 package common
 
 import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
 	"github.com/lissto-dev/controller/pkg/namespace"
 )
@@ -24,10 +28,12 @@ type ImageCandidate struct {
 
 // ImageResolutionInfo contains minimal info about resolved image
 type ImageResolutionInfo struct {
-	Service string `json:"service"`
-	Image   string `json:"image"`         // Final image with digest
-	Method  string `json:"method"`        // "original", "label", "commit", "branch", "latest"
-	Tag     string `json:"tag,omitempty"` // User-friendly tag (if resolved)
+	Service  string `json:"service"`
+	Image    string `json:"image"`              // Final image with digest
+	Method   string `json:"method"`             // "original", "label", "commit", "branch", "latest"
+	Tag      string `json:"tag,omitempty"`      // User-friendly tag (if resolved)
+	Pending  bool   `json:"pending,omitempty"`  // Awaiting first build (no published image yet)
+	Unpinned bool   `json:"unpinned,omitempty"` // Deployed by tag without a digest (unpinned fallback)
 }
 
 // DetailedImageResolutionInfo contains detailed info about image resolution process
@@ -41,20 +47,138 @@ type DetailedImageResolutionInfo struct {
 	Candidates []ImageCandidate `json:"candidates,omitempty"` // All candidates that were tried
 	Exposed    bool             `json:"exposed,omitempty"`    // Whether this service is exposed
 	URL        string           `json:"url,omitempty"`        // Expected URL if exposed and env provided
+	Pending    bool             `json:"pending,omitempty"`    // Awaiting first build; Image holds the expected placeholder
+	Unpinned   bool             `json:"unpinned,omitempty"`   // Digest could not be resolved; Digest holds the unpinned tag
+	Platform   string           `json:"platform,omitempty"`   // Platform the image was resolved for (os/arch)
+	MultiArch  bool             `json:"multi_arch,omitempty"` // Digest was selected from a multi-arch manifest list
+	Platforms  []string         `json:"platforms,omitempty"`  // Platforms verified for lissto.dev/require-platforms
+	Signature  *SignatureInfo   `json:"signature,omitempty"`  // Cosign signature status, when verification is configured
+	// Base image of build services (lissto.dev/base-image label), informational only
+	BaseImage       string `json:"base_image,omitempty"`
+	BaseImageDigest string `json:"base_image_digest,omitempty"`
+	BaseImageError  string `json:"base_image_error,omitempty"`
+}
+
+// SignatureInfo is the cosign signature status of a resolved image
+type SignatureInfo struct {
+	Signed   bool   `json:"signed"`
+	Verified bool   `json:"verified"`
+	Signer   string `json:"signer,omitempty"` // Trusted key name or keyless certificate identity
+	Error    string `json:"error,omitempty"`  // Why the signature did not verify
+}
+
+// ImageConfigInfo holds the runtime defaults recorded in an image config
+type ImageConfigInfo struct {
+	OS           string   `json:"os"`
+	OSVersion    string   `json:"os_version,omitempty"`
+	Architecture string   `json:"architecture"`
+	Entrypoint   []string `json:"entrypoint,omitempty"`
+	Cmd          []string `json:"cmd,omitempty"`
+	User         string   `json:"user,omitempty"` // Empty runs as root
+	WorkingDir   string   `json:"working_dir,omitempty"`
+	ExposedPorts []string `json:"exposed_ports"`        // e.g. "8080/tcp", sorted
+	BaseImage    string   `json:"base_image,omitempty"` // org.opencontainers.image.base.name label
+}
+
+// ServiceImageInfoResponse describes the image a blueprint service resolves to
+type ServiceImageInfoResponse struct {
+	Blueprint string           `json:"blueprint"`
+	Service   string           `json:"service"`
+	Image     string           `json:"image"`  // Resolved image, pinned to a digest when possible
+	Method    string           `json:"method"` // How the image was resolved, see DetailedImageResolutionInfo
+	Platform  string           `json:"platform,omitempty"`
+	Config    *ImageConfigInfo `json:"config"`
 }
 
 // PrepareStackResponse contains the result of stack preparation
 type PrepareStackResponse struct {
-	Blueprint string                `json:"blueprint"`
-	Images    []ImageResolutionInfo `json:"images"`
+	Blueprint  string                `json:"blueprint"`
+	Blueprints []string              `json:"blueprints,omitempty"` // Merged blueprints, when several were prepared together
+	Images     []ImageResolutionInfo `json:"images"`
+	Warnings   []PrepareWarning      `json:"warnings,omitempty"` // Validation problems found during prepare
+	// Compose YAML handed to Kompose, returned with ?showNormalized=true
+	NormalizedCompose string `json:"normalized_compose,omitempty"`
 }
 
 // DetailedPrepareStackResponse contains detailed result of stack preparation
 type DetailedPrepareStackResponse struct {
-	RequestID string                        `json:"request_id"` // UUID for caching and stack creation
-	Blueprint string                        `json:"blueprint"`
-	Images    []DetailedImageResolutionInfo `json:"images"`
-	Exposed   []ExposedServiceInfo          `json:"exposed,omitempty"` // List of exposed services with URLs
+	RequestID  string                        `json:"request_id"` // UUID for caching and stack creation
+	Blueprint  string                        `json:"blueprint"`
+	Blueprints []string                      `json:"blueprints,omitempty"` // Merged blueprints, when several were prepared together
+	Images     []DetailedImageResolutionInfo `json:"images"`
+	Exposed    []ExposedServiceInfo          `json:"exposed,omitempty"`  // List of exposed services with URLs
+	Warnings   []PrepareWarning              `json:"warnings,omitempty"` // Validation problems found during prepare
+	// Compose YAML handed to Kompose, returned with ?showNormalized=true
+	NormalizedCompose string `json:"normalized_compose,omitempty"`
+}
+
+// BatchPrepareStackResponse contains one prepare result per env
+// Every result has its own request ID for creating the stack in that env
+type BatchPrepareStackResponse struct {
+	Blueprint string                                  `json:"blueprint"`
+	Results   map[string]DetailedPrepareStackResponse `json:"results"` // Keyed by env name
+}
+
+// Service changes reported by PrepareDiffResponse
+const (
+	DiffSame    = "same"
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// PrepareDiffResponse compares what two prepare results would deploy
+type PrepareDiffResponse struct {
+	From     string        `json:"from"`     // Request ID of the baseline
+	To       string        `json:"to"`       // Request ID compared against it
+	Services []ServiceDiff `json:"services"` // Sorted by service name
+}
+
+// ServiceDiff describes how one service differs between two prepare results
+type ServiceDiff struct {
+	Service string             `json:"service"`
+	Change  string             `json:"change"`           // same, added, removed or changed
+	Fields  []string           `json:"fields,omitempty"` // Changed fields: digest, tag, url
+	From    *PreparedImageInfo `json:"from,omitempty"`   // Absent for added services
+	To      *PreparedImageInfo `json:"to,omitempty"`     // Absent for removed services
+}
+
+// PreparedImageInfo is what a prepare result would deploy for a service
+type PreparedImageInfo struct {
+	Digest   string `json:"digest,omitempty"`
+	Tag      string `json:"tag,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Pending  bool   `json:"pending,omitempty"`
+	Unpinned bool   `json:"unpinned,omitempty"`
+}
+
+// PrepareWarning describes a problem that does not block stack creation
+type PrepareWarning struct {
+	Service string `json:"service"`
+	Code    string `json:"code"`    // Machine-readable code (e.g., "tls_secret_missing")
+	Message string `json:"message"` // Human-readable description
+}
+
+// PreparePlanResponse lists the image candidates prepare would try for each service
+type PreparePlanResponse struct {
+	Blueprint string             `json:"blueprint"`
+	Services  []ServiceImagePlan `json:"services"` // Sorted by service name
+}
+
+// ServiceImagePlan describes how a service image would be resolved
+type ServiceImagePlan struct {
+	Service    string             `json:"service"`
+	Method     string             `json:"method"`               // "override", "original" or "candidates"
+	Registry   string             `json:"registry,omitempty"`   // Registry used for candidates
+	ImageName  string             `json:"image_name,omitempty"` // Image name used for candidates
+	Candidates []PlannedCandidate `json:"candidates"`           // In the order they would be tried
+}
+
+// PlannedCandidate is an image URL that would be tried
+type PlannedCandidate struct {
+	ImageURL string `json:"image_url"`
+	Tag      string `json:"tag,omitempty"`
+	Source   string `json:"source"` // "override", "original", "label", "commit", "branch", "latest"
 }
 
 // ExposedServiceInfo contains information about an exposed service
@@ -63,18 +187,150 @@ type ExposedServiceInfo struct {
 	URL     string `json:"url"`     // Expected endpoint URL (e.g., "operator-daniel.dev.lissto.dev")
 }
 
+// StackDeletedResponse is returned by DELETE /stacks/:id to clients accepting JSON
+type StackDeletedResponse struct {
+	ID      string               `json:"id"`      // Scoped ID of the deleted stack
+	Exposed []ExposedServiceInfo `json:"exposed"` // URLs that went away with the stack, sorted by service
+}
+
+// BlueprintRegistriesResponse lists the registries a blueprint's images would be pulled from
+type BlueprintRegistriesResponse struct {
+	Blueprint  string           `json:"blueprint"`
+	Hosts      []string         `json:"hosts"`                // Distinct registry hosts, sorted
+	Registries []RegistryImages `json:"registries"`           // Images grouped by registry host
+	Unresolved []string         `json:"unresolved,omitempty"` // Services whose image could not be resolved
+}
+
+// RegistryImages contains the images pulled from a single registry host
+type RegistryImages struct {
+	Host   string          `json:"host"` // Registry host (e.g., "docker.io", "123.dkr.ecr.eu-central-1.amazonaws.com")
+	Images []RegistryImage `json:"images"`
+}
+
+// RegistryImage is a fully resolved image reference for a service
+type RegistryImage struct {
+	Service string `json:"service"`
+	Image   string `json:"image"`  // User-friendly tag
+	Digest  string `json:"digest"` // Full image reference with digest
+}
+
+// RefreshEnvImagesResponse reports the outcome of re-resolving an env's stack images
+type RefreshEnvImagesResponse struct {
+	Env    string               `json:"env"`
+	DryRun bool                 `json:"dry_run"`
+	Stacks []StackRefreshReport `json:"stacks"`
+}
+
+// StackRefreshReport describes the digest changes found for one stack
+type StackRefreshReport struct {
+	Stack   string              `json:"stack"`
+	Updated bool                `json:"updated"`           // Whether the stack was updated (never in dry run)
+	Changes []ImageDigestChange `json:"changes,omitempty"` // Services whose digest changed
+	Errors  []string            `json:"errors,omitempty"`  // Services that could not be re-resolved, or the update failure
+}
+
+// ImageDigestChange is a service image whose tag now points at a different digest
+type ImageDigestChange struct {
+	Service   string `json:"service"`
+	Image     string `json:"image"`
+	OldDigest string `json:"old_digest"`
+	NewDigest string `json:"new_digest"`
+}
+
+// RecreateServiceResponse reports the pods and workloads affected by a service recreate
+type RecreateServiceResponse struct {
+	Stack     string `json:"stack"`
+	Service   string `json:"service"`
+	Strategy  string `json:"strategy"`
+	Pods      int    `json:"pods"`              // Pods deleted (delete strategy)
+	Workloads int    `json:"workloads"`         // Deployments/StatefulSets restarted (rollout strategy)
+	Skipped   int    `json:"skipped,omitempty"` // Standalone pods left alone, nothing would recreate them
+}
+
+// RepairStackResponse reports what a stack repair changed
+type RepairStackResponse struct {
+	Stack    string   `json:"stack"`
+	Repaired []string `json:"repaired"` // Repair actions taken, empty when the stack was consistent
+}
+
+// PrepullResponse reports the image pre-pulling Job of a stack
+type PrepullResponse struct {
+	Stack       string               `json:"stack"`
+	Job         string               `json:"job"`
+	Status      string               `json:"status"` // "running", "succeeded" or "failed"
+	Images      []PrepullImageStatus `json:"images"` // Sorted by image
+	PullSecrets []string             `json:"pull_secrets,omitempty"`
+}
+
+// PrepullImageStatus is the pull state of one image of a prepull Job
+type PrepullImageStatus struct {
+	Image   string `json:"image"`
+	Status  string `json:"status"`            // "pending", "pulled" or "failed"
+	Message string `json:"message,omitempty"` // Pull error reported by the kubelet
+}
+
 // EnvResponse represents an env resource
 type EnvResponse struct {
 	ID   string `json:"id"`   // Scoped identifier: namespace/envname
 	Name string `json:"name"` // Env name (metadata.name)
 }
 
+// LifecycleResponse represents a stack lifecycle resource
+type LifecycleResponse struct {
+	ID     string `json:"id"`     // Scoped identifier: namespace/name
+	Name   string `json:"name"`   // Lifecycle name (metadata.name)
+	Paused bool   `json:"paused"` // Whether its tasks are stopped by the lissto.dev/paused annotation
+}
+
+// EnvDependentsResponse lists the resources tied to an env, returned by GET /envs/:id/dependents
+// and with the 409 of DELETE /envs/:id
+type EnvDependentsResponse struct {
+	Env       string             `json:"env"`
+	Total     int                `json:"total"`
+	Stacks    DependentResources `json:"stacks"`    // Stacks deployed to the env
+	Variables DependentResources `json:"variables"` // Env-scoped variables bound to the env
+	Secrets   DependentResources `json:"secrets"`   // Env-scoped secrets bound to the env
+}
+
+// ConflictResponse is the 409 body of a create whose resource already exists
+// Clients can update the existing resource instead of retrying the create
+type ConflictResponse struct {
+	Error     string    `json:"error"`
+	ID        string    `json:"id"`         // Identifier of the existing resource
+	CreatedAt time.Time `json:"created_at"` // Creation time of the existing resource
+}
+
+// NewConflictResponse describes the existing resource a create conflicted with
+func NewConflictResponse(message, id string, existing metav1.Object) ConflictResponse {
+	return ConflictResponse{
+		Error:     message,
+		ID:        id,
+		CreatedAt: existing.GetCreationTimestamp().UTC(),
+	}
+}
+
+// DependentResources counts and identifies dependents of one kind
+type DependentResources struct {
+	Count int      `json:"count"`
+	IDs   []string `json:"ids"`
+}
+
 // UserInfoResponse represents the authenticated user's information
 type UserInfoResponse struct {
 	Name string `json:"name"` // Lissto username (from API key)
 	Role string `json:"role"` // User role
 }
 
+// WhoAmIResponse describes the authenticated API key and what it can do
+type WhoAmIResponse struct {
+	Name        string                         `json:"name"`
+	Role        string                         `json:"role"`
+	Scopes      []string                       `json:"scopes,omitempty"`     // Resource types the key is limited to, empty means all
+	ExpiresAt   *time.Time                     `json:"expires_at,omitempty"` // Omitted for keys that never expire
+	ExpiresSoon bool                           `json:"expires_soon"`         // The key expires within the warning window
+	Permissions map[string]map[string][]string `json:"permissions"`          // resource → action → namespaces ("*" means all)
+}
+
 // ExtractBlueprintTitle extracts the title from blueprint annotations
 // Falls back to the provided fallback value if annotation is not present or empty
 func ExtractBlueprintTitle(bp *envv1alpha1.Blueprint, fallback string) string {
@@ -0,0 +1,120 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ScopedConfig describes a variable or secret for visibility computation
+type ScopedConfig struct {
+	ID         string
+	Name       string
+	Scope      string // "env", "repo" or "global"
+	Env        string
+	Repository string
+	Keys       []string
+}
+
+// ScopeOverlap is a same-named config in another scope that applies to some of the same stacks
+type ScopeOverlap struct {
+	ID         string   `json:"id"`
+	Scope      string   `json:"scope"`
+	Env        string   `json:"env,omitempty"`
+	Repository string   `json:"repository,omitempty"`
+	Keys       []string `json:"keys"` // Keys defined by both; the more specific scope wins
+}
+
+// ScopeInfoResponse describes where a variable or secret applies and how it interacts
+// with same-named configs in other scopes
+type ScopeInfoResponse struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Scope      string         `json:"scope"`
+	Env        string         `json:"env,omitempty"`
+	Repository string         `json:"repository,omitempty"`
+	AppliesTo  string         `json:"applies_to"`
+	Shadowed   bool           `json:"shadowed"`              // A more specific same-named config exists
+	ShadowedBy []ScopeOverlap `json:"shadowed_by,omitempty"` // More specific configs overriding this one
+	Shadows    []ScopeOverlap `json:"shadows,omitempty"`     // Less specific configs this one overrides
+}
+
+// scopePriority orders scopes like the controller does when merging (lower is more specific)
+func scopePriority(scope string) int {
+	switch scope {
+	case "env":
+		return 0
+	case "repo":
+		return 1
+	case "global":
+		return 2
+	default:
+		return 3
+	}
+}
+
+// ComputeScopeInfo computes the visibility of target given the same-named configs in other scopes
+// Configs in the same scope target different envs or repositories and never override each other
+func ComputeScopeInfo(target ScopedConfig, sameNamed []ScopedConfig) ScopeInfoResponse {
+	info := ScopeInfoResponse{
+		ID:         target.ID,
+		Name:       target.Name,
+		Scope:      target.Scope,
+		Env:        target.Env,
+		Repository: target.Repository,
+		AppliesTo:  appliesTo(target),
+	}
+
+	targetPriority := scopePriority(target.Scope)
+	for _, other := range sameNamed {
+		if other.ID == target.ID {
+			continue
+		}
+		overlap := ScopeOverlap{
+			ID:         other.ID,
+			Scope:      other.Scope,
+			Env:        other.Env,
+			Repository: other.Repository,
+			Keys:       commonKeys(target.Keys, other.Keys),
+		}
+
+		switch priority := scopePriority(other.Scope); {
+		case priority < targetPriority:
+			info.ShadowedBy = append(info.ShadowedBy, overlap)
+		case priority > targetPriority:
+			info.Shadows = append(info.Shadows, overlap)
+		}
+	}
+	info.Shadowed = len(info.ShadowedBy) > 0
+
+	return info
+}
+
+// appliesTo describes which stacks a config is injected into
+func appliesTo(config ScopedConfig) string {
+	switch config.Scope {
+	case "env":
+		return fmt.Sprintf("stacks in env %s", config.Env)
+	case "repo":
+		return fmt.Sprintf("stacks of repository %s", config.Repository)
+	case "global":
+		return "all stacks"
+	default:
+		return "no stacks (unknown scope)"
+	}
+}
+
+// commonKeys returns the sorted keys present in both lists
+func commonKeys(a, b []string) []string {
+	inA := make(map[string]bool, len(a))
+	for _, key := range a {
+		inA[key] = true
+	}
+	keys := []string{}
+	for _, key := range b {
+		if inA[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
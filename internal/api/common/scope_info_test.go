@@ -0,0 +1,45 @@
+package common_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/internal/api/common"
+)
+
+var _ = Describe("ComputeScopeInfo", func() {
+	global := common.ScopedConfig{ID: "lissto-global/db", Name: "db", Scope: "global", Keys: []string{"DB_HOST", "DB_PORT"}}
+	repo := common.ScopedConfig{ID: "lissto-global/db-repo", Name: "db", Scope: "repo", Repository: "acme/api", Keys: []string{"DB_PORT"}}
+	env := common.ScopedConfig{ID: "dev-alice/db", Name: "db", Scope: "env", Env: "dev", Keys: []string{"DB_HOST"}}
+
+	It("should report a global config shadowed by more specific ones", func() {
+		info := common.ComputeScopeInfo(global, []common.ScopedConfig{global, repo, env})
+
+		Expect(info.AppliesTo).To(Equal("all stacks"))
+		Expect(info.Shadowed).To(BeTrue())
+		Expect(info.ShadowedBy).To(ConsistOf(
+			common.ScopeOverlap{ID: "lissto-global/db-repo", Scope: "repo", Repository: "acme/api", Keys: []string{"DB_PORT"}},
+			common.ScopeOverlap{ID: "dev-alice/db", Scope: "env", Env: "dev", Keys: []string{"DB_HOST"}},
+		))
+		Expect(info.Shadows).To(BeEmpty())
+	})
+
+	It("should report the configs an env config overrides", func() {
+		info := common.ComputeScopeInfo(env, []common.ScopedConfig{global, env})
+
+		Expect(info.AppliesTo).To(Equal("stacks in env dev"))
+		Expect(info.Shadowed).To(BeFalse())
+		Expect(info.Shadows).To(ConsistOf(
+			common.ScopeOverlap{ID: "lissto-global/db", Scope: "global", Keys: []string{"DB_HOST"}},
+		))
+	})
+
+	It("should not treat configs in the same scope as shadowing", func() {
+		otherEnv := common.ScopedConfig{ID: "dev-alice/db-staging", Name: "db", Scope: "env", Env: "staging"}
+
+		info := common.ComputeScopeInfo(env, []common.ScopedConfig{otherEnv})
+
+		Expect(info.Shadowed).To(BeFalse())
+		Expect(info.Shadows).To(BeEmpty())
+	})
+})
@@ -0,0 +1,65 @@
+package common
+
+import (
+	"context"
+	"sort"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/pkg/logging"
+	"github.com/lissto-dev/api/pkg/secretref"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+// SecretLister lists the LisstoSecrets of a namespace
+type SecretLister interface {
+	ListLisstoSecrets(ctx context.Context, namespace string) (*envv1alpha1.LisstoSecretList, error)
+}
+
+// RejectDanglingSecretRefs responds 400 when a secretref:// value is malformed or names a secret key
+// that doesn't exist in the namespace (env-scoped secrets must belong to env)
+// This is save-time validation only: the controller resolves references when it injects variables
+// It reports whether a response was written; the caller then returns the error as is
+func RejectDanglingSecretRefs(c echo.Context, lister SecretLister, namespace, env string, values map[string]string) (bool, error) {
+	var violations []ValueViolation
+	var secrets []envv1alpha1.LisstoSecret
+	loaded := false
+
+	for key, value := range values {
+		ref, ok, err := secretref.Parse(value)
+		if !ok {
+			continue
+		}
+		if err == nil {
+			if !loaded {
+				list, listErr := lister.ListLisstoSecrets(c.Request().Context(), namespace)
+				if listErr != nil {
+					logging.Logger.Error("Failed to list secrets for secret references",
+						zap.String("namespace", namespace),
+						zap.Error(listErr))
+					return true, c.String(500, "Failed to check secret references")
+				}
+				secrets, loaded = list.Items, true
+			}
+			_, err = ref.Resolve(secrets, env)
+		}
+		if err != nil {
+			violations = append(violations, ValueViolation{Key: key, Rule: "secret_ref", Message: err.Error()})
+		}
+	}
+	if len(violations) == 0 {
+		return false, nil
+	}
+	sort.Slice(violations, func(i, j int) bool { return violations[i].Key < violations[j].Key })
+
+	logging.Logger.Info("Rejected dangling secret references",
+		zap.String("path", c.Path()),
+		zap.String("namespace", namespace),
+		zap.Int("count", len(violations)))
+
+	return true, c.JSON(400, ValueValidationResponse{
+		Error:      "Invalid secret references",
+		Violations: violations,
+	})
+}
@@ -0,0 +1,112 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// Value formats supported by ValueRule.Format
+const (
+	ValueFormatJSON = "json"
+)
+
+// ValueRule declares content checks for the value of one variable/secret key
+type ValueRule struct {
+	MaxLength  int    `json:"max_length,omitempty"`  // Overrides the configured maximum for this key
+	SingleLine bool   `json:"single_line,omitempty"` // Reject values containing line breaks
+	Format     string `json:"format,omitempty"`      // "json": value must be valid JSON
+	Pattern    string `json:"pattern,omitempty"`     // Regular expression the whole value must match
+}
+
+// ValueViolation describes a value that failed validation
+type ValueViolation struct {
+	Key     string `json:"key"`
+	Rule    string `json:"rule"` // max_length, single_line, format or pattern
+	Message string `json:"message"`
+}
+
+// ValueValidationResponse is the 400 body listing every offending key
+type ValueValidationResponse struct {
+	Error      string           `json:"error"`
+	Violations []ValueViolation `json:"violations"`
+}
+
+// ValidateValues checks values against a maximum length (0 disables) and per-key rules
+// Rules for keys that are not in values are ignored; violations are sorted by key
+func ValidateValues(values map[string]string, maxLength int, rules map[string]ValueRule) []ValueViolation {
+	var violations []ValueViolation
+	for key, value := range values {
+		violations = append(violations, validateValue(key, value, maxLength, rules[key])...)
+	}
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].Key < violations[j].Key })
+	return violations
+}
+
+// validateValue applies the length limit and a key's rule to its value
+func validateValue(key, value string, maxLength int, rule ValueRule) []ValueViolation {
+	var violations []ValueViolation
+	violate := func(name, format string, args ...interface{}) {
+		violations = append(violations, ValueViolation{Key: key, Rule: name, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if rule.MaxLength > 0 {
+		maxLength = rule.MaxLength
+	}
+	if maxLength > 0 && len(value) > maxLength {
+		violate("max_length", "value is %d bytes, maximum is %d", len(value), maxLength)
+	}
+	if rule.SingleLine && strings.ContainsAny(value, "\r\n") {
+		violate("single_line", "value must be a single line")
+	}
+
+	switch rule.Format {
+	case "":
+	case ValueFormatJSON:
+		if !json.Valid([]byte(value)) {
+			violate("format", "value is not valid JSON")
+		}
+	default:
+		violate("format", "unknown format %q (supported: %s)", rule.Format, ValueFormatJSON)
+	}
+
+	if rule.Pattern != "" {
+		pattern, err := regexp.Compile(`^(?:` + rule.Pattern + `)$`)
+		switch {
+		case err != nil:
+			violate("pattern", "invalid pattern %q: %v", rule.Pattern, err)
+		case !pattern.MatchString(value):
+			violate("pattern", "value does not match pattern %q", rule.Pattern)
+		}
+	}
+	return violations
+}
+
+// RejectInvalidValues responds 400 with the violations when values fail validation
+// It reports whether a response was written; the caller then returns the error as is
+func RejectInvalidValues(c echo.Context, values map[string]string, maxLength int, rules map[string]ValueRule) (bool, error) {
+	violations := ValidateValues(values, maxLength, rules)
+	if len(violations) == 0 {
+		return false, nil
+	}
+
+	keys := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		keys = append(keys, violation.Key)
+	}
+	logging.Logger.Info("Rejected invalid values",
+		zap.String("path", c.Path()),
+		zap.Strings("keys", keys))
+
+	return true, c.JSON(400, ValueValidationResponse{
+		Error:      "Invalid values",
+		Violations: violations,
+	})
+}
@@ -0,0 +1,66 @@
+package common_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/internal/api/common"
+)
+
+var _ = Describe("ValidateValues", func() {
+	It("should accept values that pass every rule", func() {
+		violations := common.ValidateValues(
+			map[string]string{"PORT": "8080", "CONFIG": `{"debug":true}`, "NAME": "api"},
+			16,
+			map[string]common.ValueRule{
+				"PORT":   {Pattern: "[0-9]+", SingleLine: true},
+				"CONFIG": {Format: common.ValueFormatJSON},
+				"OTHER":  {Pattern: "never"}, // Not in values, ignored
+			},
+		)
+		Expect(violations).To(BeEmpty())
+	})
+
+	It("should reject values over the maximum length", func() {
+		violations := common.ValidateValues(map[string]string{"TOKEN": "0123456789", "SHORT": "ok"}, 8, nil)
+		Expect(violations).To(ConsistOf(
+			HaveField("Key", "TOKEN"),
+		))
+		Expect(violations[0].Rule).To(Equal("max_length"))
+		Expect(violations[0].Message).To(ContainSubstring("maximum is 8"))
+	})
+
+	It("should let a key rule override the maximum length", func() {
+		values := map[string]string{"CERT": "0123456789"}
+		Expect(common.ValidateValues(values, 8, map[string]common.ValueRule{"CERT": {MaxLength: 64}})).To(BeEmpty())
+		Expect(common.ValidateValues(values, 0, map[string]common.ValueRule{"CERT": {MaxLength: 4}})).To(HaveLen(1))
+	})
+
+	It("should reject values that do not match the whole pattern", func() {
+		violations := common.ValidateValues(
+			map[string]string{"PORT": "8080x", "HOST": "db"},
+			0,
+			map[string]common.ValueRule{"PORT": {Pattern: "[0-9]+"}, "HOST": {Pattern: "[a-z]+"}},
+		)
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].Key).To(Equal("PORT"))
+		Expect(violations[0].Rule).To(Equal("pattern"))
+	})
+
+	It("should report invalid patterns, JSON and multi-line values per key", func() {
+		violations := common.ValidateValues(
+			map[string]string{"A": "x", "B": "{not json", "C": "line1\nline2"},
+			0,
+			map[string]common.ValueRule{
+				"A": {Pattern: "("},
+				"B": {Format: common.ValueFormatJSON},
+				"C": {SingleLine: true},
+			},
+		)
+		Expect(violations).To(HaveLen(3))
+		Expect(violations[0]).To(HaveField("Key", "A"))
+		Expect(violations[0].Message).To(ContainSubstring("invalid pattern"))
+		Expect(violations[1]).To(HaveField("Rule", "format"))
+		Expect(violations[2]).To(HaveField("Rule", "single_line"))
+	})
+})
@@ -0,0 +1,257 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/stack"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/logging"
+	"github.com/lissto-dev/api/pkg/notify"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+// envDependents holds the resources in a namespace that are tied to one env
+type envDependents struct {
+	stacks    []envv1alpha1.Stack
+	variables []envv1alpha1.LisstoVariable
+	secrets   []envv1alpha1.LisstoSecret
+}
+
+// empty reports whether nothing depends on the env
+func (d *envDependents) empty() bool {
+	return len(d.stacks) == 0 && len(d.variables) == 0 && len(d.secrets) == 0
+}
+
+// findDependents lists stacks deployed to the env and env-scoped variables and secrets bound to it
+func (h *Handler) findDependents(ctx context.Context, namespace, envName string) (*envDependents, error) {
+	dependents := &envDependents{}
+
+	stackList, err := h.k8sClient.ListStacks(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stacks: %w", err)
+	}
+	for _, s := range stackList.Items {
+		if s.Spec.Env == envName {
+			dependents.stacks = append(dependents.stacks, s)
+		}
+	}
+
+	variableList, err := h.k8sClient.ListLisstoVariables(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list variables: %w", err)
+	}
+	for _, v := range variableList.Items {
+		if v.GetScope() == "env" && v.Spec.Env == envName {
+			dependents.variables = append(dependents.variables, v)
+		}
+	}
+
+	secretList, err := h.k8sClient.ListLisstoSecrets(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	for _, s := range secretList.Items {
+		if s.GetScope() == "env" && s.Spec.Env == envName {
+			dependents.secrets = append(dependents.secrets, s)
+		}
+	}
+
+	return dependents, nil
+}
+
+// response converts dependents to the API response, IDs sorted
+func (d *envDependents) response(envName string, nsManager *authz.NamespaceManager) common.EnvDependentsResponse {
+	resources := func(count int, id func(i int) string) common.DependentResources {
+		ids := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			ids = append(ids, id(i))
+		}
+		sort.Strings(ids)
+		return common.DependentResources{Count: count, IDs: ids}
+	}
+
+	return common.EnvDependentsResponse{
+		Env:   envName,
+		Total: len(d.stacks) + len(d.variables) + len(d.secrets),
+		Stacks: resources(len(d.stacks), func(i int) string {
+			return nsManager.MustGenerateScopedID(d.stacks[i].Namespace, d.stacks[i].Name)
+		}),
+		Variables: resources(len(d.variables), func(i int) string {
+			return fmt.Sprintf("%s/%s", d.variables[i].Namespace, d.variables[i].Name)
+		}),
+		Secrets: resources(len(d.secrets), func(i int) string {
+			return fmt.Sprintf("%s/%s", d.secrets[i].Namespace, d.secrets[i].Name)
+		}),
+	}
+}
+
+// GetEnvDependents handles GET /envs/:id/dependents
+// Preflight for DELETE /envs/:id: lists what still depends on the env
+func (h *Handler) GetEnvDependents(c echo.Context) error {
+	user, _ := middleware.GetUserFromContext(c)
+	envName := c.Param("id")
+	namespace := h.nsManager.GetDeveloperNamespace(user.Name)
+
+	perm := h.authorizer.CanAccess(user.Role, authz.ActionRead, authz.ResourceEnv, namespace, user.Name)
+	if !perm.Allowed {
+		logging.LogDeniedWithIP(perm.Reason, user.Name, fmt.Sprintf("GET /envs/%s/dependents", envName), c.RealIP())
+		return c.String(403, fmt.Sprintf("Permission denied: %s", perm.Reason))
+	}
+
+	if _, err := h.k8sClient.GetEnv(c.Request().Context(), namespace, envName); err != nil {
+		return c.String(404, fmt.Sprintf("Environment '%s' not found", envName))
+	}
+
+	dependents, err := h.findDependents(c.Request().Context(), namespace, envName)
+	if err != nil {
+		logging.Logger.Error("Failed to list env dependents",
+			zap.String("env", envName),
+			zap.String("namespace", namespace),
+			zap.Error(err))
+		return c.String(500, "Failed to list env dependents")
+	}
+
+	return c.JSON(200, dependents.response(envName, h.nsManager))
+}
+
+// DeleteEnv handles DELETE /envs/:id
+// Refuses with 409 while stacks, variables or secrets depend on the env;
+// ?cascade=true deletes them first: stacks, then variables and secrets, then the env
+func (h *Handler) DeleteEnv(c echo.Context) error {
+	user, _ := middleware.GetUserFromContext(c)
+	envName := c.Param("id")
+	namespace := h.nsManager.GetDeveloperNamespace(user.Name)
+	endpoint := fmt.Sprintf("DELETE /envs/%s", envName)
+	cascade := c.QueryParam("cascade") == "true"
+
+	logging.Logger.Info("Env delete request",
+		zap.String("user", user.Name),
+		zap.String("env", envName),
+		zap.String("namespace", namespace),
+		zap.Bool("cascade", cascade))
+
+	perm := h.authorizer.CanAccess(user.Role, authz.ActionDelete, authz.ResourceEnv, namespace, user.Name)
+	if !perm.Allowed {
+		logging.LogDeniedWithIP(perm.Reason, user.Name, endpoint, c.RealIP())
+		return c.String(403, fmt.Sprintf("Permission denied: %s", perm.Reason))
+	}
+
+	if rejected, err := common.RejectIfFrozen(c, h.nsManager, h.k8sClient, namespace, user.Name, endpoint); rejected {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	if _, err := h.k8sClient.GetEnv(ctx, namespace, envName); err != nil {
+		return c.String(404, fmt.Sprintf("Environment '%s' not found", envName))
+	}
+
+	dependents, err := h.findDependents(ctx, namespace, envName)
+	if err != nil {
+		logging.Logger.Error("Failed to list env dependents",
+			zap.String("env", envName),
+			zap.String("namespace", namespace),
+			zap.Error(err))
+		return c.String(500, "Failed to list env dependents")
+	}
+
+	if !dependents.empty() {
+		if !cascade {
+			logging.LogDeniedWithIP("env_has_dependents", user.Name, endpoint, c.RealIP())
+			return c.JSON(409, dependents.response(envName, h.nsManager))
+		}
+
+		// Cascading must not bypass stack delete protection
+		for i := range dependents.stacks {
+			if dependents.stacks[i].Labels[stack.ProtectedLabel] == "true" {
+				stackID := h.nsManager.MustGenerateScopedID(dependents.stacks[i].Namespace, dependents.stacks[i].Name)
+				logging.LogDeniedWithIP("stack_protected", user.Name, endpoint, c.RealIP())
+				return c.String(412, fmt.Sprintf("Stack '%s' is protected; delete it with DELETE /stacks/%s?confirm=%s first",
+					stackID, stackID, stackID))
+			}
+		}
+
+		if err := h.deleteDependents(ctx, dependents, user.Name); err != nil {
+			if errors.Is(err, errNamespaceLock) {
+				logging.Logger.Warn("Gave up waiting for the namespace lock",
+					zap.String("namespace", namespace),
+					zap.Error(err))
+				return c.String(503, "Another stack operation in this namespace is in progress. Please retry.")
+			}
+			logging.Logger.Error("Failed to delete env dependents",
+				zap.String("env", envName),
+				zap.String("namespace", namespace),
+				zap.Error(err))
+			return c.String(500, "Failed to delete env dependents")
+		}
+	}
+
+	if err := h.k8sClient.DeleteEnv(ctx, namespace, envName); err != nil {
+		logging.Logger.Error("Failed to delete env",
+			zap.String("env", envName),
+			zap.String("namespace", namespace),
+			zap.Error(err))
+		return c.String(500, "Failed to delete env")
+	}
+
+	logging.Logger.Info("Env deleted successfully",
+		zap.String("env", envName),
+		zap.String("namespace", namespace),
+		zap.String("user", user.Name),
+		zap.Int("stacks", len(dependents.stacks)),
+		zap.Int("variables", len(dependents.variables)),
+		zap.Int("secrets", len(dependents.secrets)))
+
+	return c.NoContent(204)
+}
+
+// errNamespaceLock is returned when the request ended while waiting for a namespace's stack lock
+var errNamespaceLock = errors.New("namespace lock not acquired")
+
+// deleteDependents deletes stacks before the variables and secrets they consume
+// Each stack is deleted under the namespace lock, so it never races a create, update or delete through /stacks.
+func (h *Handler) deleteDependents(ctx context.Context, dependents *envDependents, username string) error {
+	for i := range dependents.stacks {
+		s := &dependents.stacks[i]
+		unlock, err := h.locker.Lock(ctx, s.Namespace)
+		if err != nil {
+			return fmt.Errorf("%w for stack %s: %w", errNamespaceLock, s.Name, err)
+		}
+		err = h.k8sClient.DeleteStack(ctx, s.Namespace, s.Name)
+		unlock()
+		if err != nil {
+			return fmt.Errorf("failed to delete stack %s: %w", s.Name, err)
+		}
+		identifier := h.nsManager.MustGenerateScopedID(s.Namespace, s.Name)
+		h.notifier.Notify(notify.NewStackEvent(notify.EventStackDeleted, identifier, s, username))
+	}
+
+	for _, v := range dependents.variables {
+		if err := h.k8sClient.DeleteLisstoVariable(ctx, v.Namespace, v.Name); err != nil {
+			return fmt.Errorf("failed to delete variable %s: %w", v.Name, err)
+		}
+	}
+
+	for _, s := range dependents.secrets {
+		// Delete the K8s Secret first, same as DELETE /secrets/:id
+		secretRefName := s.GetSecretRef()
+		if err := h.k8sClient.DeleteSecret(ctx, s.Namespace, secretRefName); err != nil {
+			logging.Logger.Warn("Failed to delete k8s secret",
+				zap.String("name", secretRefName),
+				zap.String("namespace", s.Namespace),
+				zap.Error(err))
+		}
+		if err := h.k8sClient.DeleteLisstoSecret(ctx, s.Namespace, s.Name); err != nil {
+			return fmt.Errorf("failed to delete secret %s: %w", s.Name, err)
+		}
+	}
+
+	return nil
+}
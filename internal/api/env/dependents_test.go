@@ -0,0 +1,177 @@
+package env_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/env"
+	"github.com/lissto-dev/api/internal/api/stack"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/k8s"
+	"github.com/lissto-dev/api/pkg/notify"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+var _ = Describe("Env dependents", func() {
+	var (
+		k8sClient *k8s.Client
+		handler   *env.Handler
+		locker    stack.NamespaceLocker
+		deleted   []string
+	)
+
+	newHandler := func(objects ...client.Object) {
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		nsManager := authz.NewNamespaceManager(cfg)
+
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		deleted = nil
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+					deleted = append(deleted, fmt.Sprintf("%T/%s", obj, obj.GetName()))
+					return c.Delete(ctx, obj, opts...)
+				},
+			}).Build()
+		k8sClient = k8s.NewClientFromClient(fakeClient, scheme)
+		locker = stack.NewNamespaceLocker()
+		handler = env.NewHandler(k8sClient, authz.NewAuthorizer(nsManager), nsManager, cfg, notify.NopNotifier{}, locker)
+	}
+
+	objects := func() []client.Object {
+		return []client.Object{
+			&envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}},
+			&envv1alpha1.Stack{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "dev-alice"}, Spec: envv1alpha1.StackSpec{Env: "dev"}},
+			&envv1alpha1.Stack{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "dev-alice"}, Spec: envv1alpha1.StackSpec{Env: "staging"}},
+			&envv1alpha1.LisstoVariable{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "dev-alice"}, Spec: envv1alpha1.LisstoVariableSpec{Scope: "env", Env: "dev"}},
+			&envv1alpha1.LisstoVariable{ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "dev-alice"}, Spec: envv1alpha1.LisstoVariableSpec{Scope: "repository", Repository: "acme/api"}},
+			&envv1alpha1.LisstoSecret{ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "dev-alice"}, Spec: envv1alpha1.LisstoSecretSpec{Scope: "env", Env: "dev"}},
+		}
+	}
+
+	callWithContext := func(ctx context.Context, method, target string, fn func(echo.Context) error) *httptest.ResponseRecorder {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(method, target, nil).WithContext(ctx), rec)
+		c.SetParamNames("id")
+		c.SetParamValues("dev")
+		c.Set("user", &middleware.User{Name: "alice", Role: authz.User})
+		Expect(fn(c)).To(Succeed())
+		return rec
+	}
+
+	call := func(method, target string, fn func(echo.Context) error) *httptest.ResponseRecorder {
+		return callWithContext(context.Background(), method, target, fn)
+	}
+
+	It("should list the stacks, variables and secrets tied to the env", func() {
+		newHandler(objects()...)
+		rec := call(http.MethodGet, "/envs/dev/dependents", handler.GetEnvDependents)
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+
+		var resp common.EnvDependentsResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.Total).To(Equal(3))
+		Expect(resp.Stacks).To(Equal(common.DependentResources{Count: 1, IDs: []string{"alice/api"}}))
+		Expect(resp.Variables).To(Equal(common.DependentResources{Count: 1, IDs: []string{"dev-alice/db"}}))
+		Expect(resp.Secrets).To(Equal(common.DependentResources{Count: 1, IDs: []string{"dev-alice/creds"}}))
+	})
+
+	It("should refuse to delete an env with dependents", func() {
+		newHandler(objects()...)
+		rec := call(http.MethodDelete, "/envs/dev", handler.DeleteEnv)
+		Expect(rec.Code).To(Equal(409), rec.Body.String())
+		Expect(deleted).To(BeEmpty())
+
+		_, err := k8sClient.GetEnv(context.Background(), "dev-alice", "dev")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should delete an env without dependents", func() {
+		newHandler(&envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}})
+		rec := call(http.MethodDelete, "/envs/dev", handler.DeleteEnv)
+		Expect(rec.Code).To(Equal(204), rec.Body.String())
+		Expect(deleted).To(Equal([]string{"*v1alpha1.Env/dev"}))
+	})
+
+	It("should cascade delete stacks, then variables and secrets, then the env", func() {
+		newHandler(objects()...)
+		rec := call(http.MethodDelete, "/envs/dev?cascade=true", handler.DeleteEnv)
+		Expect(rec.Code).To(Equal(204), rec.Body.String())
+
+		Expect(deleted).To(Equal([]string{
+			"*v1alpha1.Stack/api",
+			"*v1alpha1.LisstoVariable/db",
+			"*v1.Secret/creds-data",
+			"*v1alpha1.LisstoSecret/creds",
+			"*v1alpha1.Env/dev",
+		}))
+
+		// Resources of other envs and scopes are kept
+		_, err := k8sClient.GetStack(context.Background(), "dev-alice", "other")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = k8sClient.GetLisstoVariable(context.Background(), "dev-alice", "repo")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should not cascade over a protected stack", func() {
+		objs := objects()
+		objs[1].SetLabels(map[string]string{stack.ProtectedLabel: "true"})
+		newHandler(objs...)
+		rec := call(http.MethodDelete, "/envs/dev?cascade=true", handler.DeleteEnv)
+		Expect(rec.Code).To(Equal(412), rec.Body.String())
+		Expect(deleted).To(BeEmpty())
+	})
+
+	It("should wait for the namespace stack lock before deleting a stack", func() {
+		newHandler(objects()...)
+		unlock, err := locker.Lock(context.Background(), "dev-alice")
+		Expect(err).NotTo(HaveOccurred())
+
+		done := make(chan *httptest.ResponseRecorder)
+		go func() {
+			defer GinkgoRecover()
+			done <- call(http.MethodDelete, "/envs/dev?cascade=true", handler.DeleteEnv)
+		}()
+		Consistently(done, "100ms").ShouldNot(Receive())
+		_, err = k8sClient.GetStack(context.Background(), "dev-alice", "api")
+		Expect(err).NotTo(HaveOccurred())
+
+		unlock()
+		var rec *httptest.ResponseRecorder
+		Eventually(done).Should(Receive(&rec))
+		Expect(rec.Code).To(Equal(204), rec.Body.String())
+		Expect(deleted).To(ContainElement("*v1alpha1.Stack/api"))
+	})
+
+	It("should answer 503 when the request ends while waiting for the namespace lock", func() {
+		newHandler(objects()...)
+		unlock, err := locker.Lock(context.Background(), "dev-alice")
+		Expect(err).NotTo(HaveOccurred())
+		defer unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		rec := callWithContext(ctx, http.MethodDelete, "/envs/dev?cascade=true", handler.DeleteEnv)
+		Expect(rec.Code).To(Equal(503), rec.Body.String())
+		Expect(deleted).To(BeEmpty())
+	})
+})
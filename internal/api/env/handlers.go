@@ -7,10 +7,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/stack"
 	"github.com/lissto-dev/api/internal/middleware"
 	"github.com/lissto-dev/api/pkg/authz"
 	"github.com/lissto-dev/api/pkg/k8s"
 	"github.com/lissto-dev/api/pkg/logging"
+	"github.com/lissto-dev/api/pkg/notify"
 	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
 	controllerconfig "github.com/lissto-dev/controller/pkg/config"
 	"go.uber.org/zap"
@@ -22,6 +24,8 @@ type Handler struct {
 	authorizer *authz.Authorizer
 	nsManager  *authz.NamespaceManager
 	config     *controllerconfig.Config
+	notifier   notify.Notifier       // Stack deleted events for stacks removed by a cascading env delete
+	locker     stack.NamespaceLocker // The stack handler's locker, held while a cascading env delete removes a stack
 }
 
 // FormattableEnv wraps a k8s Env to implement common.Formattable
@@ -53,12 +57,16 @@ func NewHandler(
 	authorizer *authz.Authorizer,
 	nsManager *authz.NamespaceManager,
 	config *controllerconfig.Config,
+	notifier notify.Notifier,
+	locker stack.NamespaceLocker,
 ) *Handler {
 	return &Handler{
 		k8sClient:  k8sClient,
 		authorizer: authorizer,
 		nsManager:  nsManager,
 		config:     config,
+		notifier:   notifier,
+		locker:     locker,
 	}
 }
 
@@ -94,13 +102,18 @@ func (h *Handler) CreateEnv(c echo.Context) error {
 		return c.String(403, fmt.Sprintf("Permission denied: %s", perm.Reason))
 	}
 
+	if rejected, err := common.RejectIfFrozen(c, h.nsManager, h.k8sClient, namespace, user.Name, "POST /envs"); rejected {
+		return err
+	}
+
 	// Check if env already exists
 	existing, err := h.k8sClient.GetEnv(c.Request().Context(), namespace, req.Name)
 	if err == nil && existing != nil {
 		logging.Logger.Error("Env already exists",
 			zap.String("name", req.Name),
 			zap.String("namespace", namespace))
-		return c.String(409, fmt.Sprintf("Env '%s' already exists", req.Name))
+		return c.JSON(409, common.NewConflictResponse(fmt.Sprintf("Env '%s' already exists", req.Name),
+			h.nsManager.MustGenerateScopedID(namespace, req.Name), existing))
 	}
 
 	// Ensure namespace exists
@@ -133,9 +146,11 @@ func (h *Handler) CreateEnv(c echo.Context) error {
 		zap.String("namespace", namespace),
 		zap.String("user", user.Name))
 
-	// Return scoped identifier
+	// Return scoped identifier, or the env when asked for a representation
 	identifier := h.nsManager.MustGenerateScopedID(namespace, req.Name)
-	return c.String(201, identifier)
+	return common.HandleCreatedResponse(c, 201, &FormattableEnv{k8sObj: env, nsManager: h.nsManager}, func() error {
+		return c.String(201, identifier)
+	})
 }
 
 // GetEnvs handles GET /envs
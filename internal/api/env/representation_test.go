@@ -0,0 +1,80 @@
+package env_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/env"
+	"github.com/lissto-dev/api/internal/api/stack"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/k8s"
+	"github.com/lissto-dev/api/pkg/notify"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+type testValidator struct {
+	validator *validator.Validate
+}
+
+func (v *testValidator) Validate(i interface{}) error {
+	return v.validator.Struct(i)
+}
+
+var _ = Describe("CreateEnv representation", func() {
+	create := func(target string) *httptest.ResponseRecorder {
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		nsManager := authz.NewNamespaceManager(cfg)
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		k8sClient := k8s.NewClientFromClient(fake.NewClientBuilder().WithScheme(scheme).Build(), scheme)
+		handler := env.NewHandler(k8sClient, authz.NewAuthorizer(nsManager), nsManager, cfg, notify.NopNotifier{}, stack.NewNamespaceLocker())
+
+		e := echo.New()
+		e.Validator = &testValidator{validator: validator.New()}
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(`{"name":"dev"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user", &middleware.User{Name: "alice", Role: authz.User})
+		Expect(handler.CreateEnv(c)).To(Succeed())
+		return rec
+	}
+
+	It("should return the scoped identifier by default", func() {
+		rec := create("/envs")
+
+		Expect(rec.Code).To(Equal(201))
+		Expect(rec.Body.String()).To(Equal("alice/dev"))
+	})
+
+	It("should return the created env with ?return=representation", func() {
+		rec := create("/envs?return=representation")
+
+		Expect(rec.Code).To(Equal(201))
+		var response common.EnvResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+		Expect(response).To(Equal(common.EnvResponse{ID: "alice/dev", Name: "dev"}))
+	})
+
+	It("should return the detailed env with ?format=detailed", func() {
+		rec := create("/envs?return=representation&format=detailed")
+
+		Expect(rec.Code).To(Equal(201))
+		var response common.DetailedResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+		Expect(response.Metadata.Name).To(Equal("dev"))
+		Expect(response.Metadata.Namespace).To(Equal("alice"))
+	})
+})
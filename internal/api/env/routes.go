@@ -10,4 +10,6 @@ func RegisterRoutes(g *echo.Group, handler *Handler) {
 	g.POST("", handler.CreateEnv)
 	g.GET("", handler.GetEnvs)
 	g.GET("/:id", handler.GetEnv)
+	g.GET("/:id/dependents", handler.GetEnvDependents)
+	g.DELETE("/:id", handler.DeleteEnv)
 }
@@ -0,0 +1,169 @@
+package lifecycle
+
+import (
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/k8s"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+	"github.com/lissto-dev/controller/pkg/namespace"
+)
+
+// PausedAnnotation records that a lifecycle's tasks should stop without deleting it
+// The API only records the intent; honoring it is left to the controller, which does not read it yet
+const PausedAnnotation = "lissto.dev/paused"
+
+// Handler handles stack lifecycle requests
+type Handler struct {
+	k8sClient  *k8s.Client
+	authorizer *authz.Authorizer
+	nsManager  *authz.NamespaceManager
+}
+
+// FormattableLifecycle wraps a k8s StackLifecycle to implement common.Formattable
+type FormattableLifecycle struct {
+	k8sObj    *envv1alpha1.StackLifecycle
+	nsManager *authz.NamespaceManager
+}
+
+func (f *FormattableLifecycle) ToDetailed() (common.DetailedResponse, error) {
+	return common.NewDetailedResponse(f.k8sObj.ObjectMeta, f.k8sObj.Spec, f.nsManager)
+}
+
+func (f *FormattableLifecycle) ToStandard() interface{} {
+	return extractLifecycleResponse(f.k8sObj, f.nsManager)
+}
+
+// extractLifecycleResponse extracts standard data from a lifecycle
+func extractLifecycleResponse(lifecycle *envv1alpha1.StackLifecycle, nsManager *authz.NamespaceManager) common.LifecycleResponse {
+	return common.LifecycleResponse{
+		ID:     nsManager.MustGenerateScopedID(lifecycle.Namespace, lifecycle.Name),
+		Name:   lifecycle.Name,
+		Paused: isPaused(lifecycle),
+	}
+}
+
+// isPaused reports whether a lifecycle carries the paused annotation
+func isPaused(lifecycle *envv1alpha1.StackLifecycle) bool {
+	return lifecycle.Annotations[PausedAnnotation] == "true"
+}
+
+// NewHandler creates a new lifecycle handler
+func NewHandler(k8sClient *k8s.Client, authorizer *authz.Authorizer, nsManager *authz.NamespaceManager) *Handler {
+	return &Handler{
+		k8sClient:  k8sClient,
+		authorizer: authorizer,
+		nsManager:  nsManager,
+	}
+}
+
+// GetLifecycle handles GET /lifecycles/:id
+func (h *Handler) GetLifecycle(c echo.Context) error {
+	idParam := c.Param("id")
+	user, _ := middleware.GetUserFromContext(c)
+
+	// Lifecycles belong to stacks, reading one needs stack read access
+	allowedNS := h.authorizer.GetAllowedNamespaces(user.Role, authz.ActionRead, authz.ResourceStack, user.Name)
+	if len(allowedNS) == 0 {
+		return c.String(403, "Permission denied: no accessible namespaces")
+	}
+
+	lifecycle, found := h.findLifecycle(c, idParam, user, allowedNS)
+	if !found {
+		return c.String(404, fmt.Sprintf("Lifecycle '%s' not found", idParam))
+	}
+
+	return common.HandleFormatResponse(c, &FormattableLifecycle{k8sObj: lifecycle, nsManager: h.nsManager})
+}
+
+// PauseLifecycle handles POST /lifecycles/:id/pause
+func (h *Handler) PauseLifecycle(c echo.Context) error {
+	return h.setPaused(c, true)
+}
+
+// ResumeLifecycle handles POST /lifecycles/:id/resume
+func (h *Handler) ResumeLifecycle(c echo.Context) error {
+	return h.setPaused(c, false)
+}
+
+// setPaused sets or clears the paused annotation, a no-op when the lifecycle is already in that state
+// Admins may pause lifecycles in any namespace, other users those in namespaces they own. Pausing is allowed
+// in frozen namespaces, that is what it is for; resuming is not, except for admins.
+func (h *Handler) setPaused(c echo.Context, paused bool) error {
+	idParam := c.Param("id")
+	user, _ := middleware.GetUserFromContext(c)
+
+	action := "resume"
+	if paused {
+		action = "pause"
+	}
+
+	allowedNS := []string{"*"}
+	if user.Role != authz.Admin {
+		allowedNS = h.authorizer.GetAllowedNamespaces(user.Role, authz.ActionUpdate, authz.ResourceStack, user.Name)
+	}
+	if len(allowedNS) == 0 {
+		logging.LogDeniedWithIP("no accessible namespaces", user.Name, fmt.Sprintf("POST /lifecycles/%s/%s", idParam, action), c.RealIP())
+		return c.String(403, "Permission denied: no accessible namespaces")
+	}
+
+	lifecycle, found := h.findLifecycle(c, idParam, user, allowedNS)
+	if !found {
+		return c.String(404, fmt.Sprintf("Lifecycle '%s' not found", idParam))
+	}
+
+	if !paused && user.Role != authz.Admin {
+		if rejected, err := common.RejectIfFrozen(c, h.nsManager, h.k8sClient, lifecycle.Namespace, user.Name, fmt.Sprintf("POST /lifecycles/%s/resume", idParam)); rejected {
+			return err
+		}
+	}
+
+	identifier := h.nsManager.MustGenerateScopedID(lifecycle.Namespace, lifecycle.Name)
+	if paused != isPaused(lifecycle) {
+		if paused {
+			if lifecycle.Annotations == nil {
+				lifecycle.Annotations = map[string]string{}
+			}
+			lifecycle.Annotations[PausedAnnotation] = "true"
+		} else {
+			delete(lifecycle.Annotations, PausedAnnotation)
+		}
+
+		if err := h.k8sClient.UpdateStackLifecycle(c.Request().Context(), lifecycle); err != nil {
+			logging.Logger.Error("Failed to update lifecycle",
+				zap.String("lifecycle", identifier),
+				zap.Error(err))
+			return c.String(500, "Failed to update lifecycle")
+		}
+
+		logging.Logger.Info("Lifecycle pause changed",
+			zap.String("user", user.Name),
+			zap.String("lifecycle", identifier),
+			zap.Bool("paused", paused))
+	}
+
+	return c.JSON(200, extractLifecycleResponse(lifecycle, h.nsManager))
+}
+
+// findLifecycle resolves a lifecycle ID within the allowed namespaces, the user's namespace first
+func (h *Handler) findLifecycle(c echo.Context, idParam string, user *middleware.User, allowedNS []string) (*envv1alpha1.StackLifecycle, bool) {
+	ctx := c.Request().Context()
+
+	targetNamespace, name, searchAll := h.nsManager.ResolveNamespaceFromID(idParam, allowedNS)
+	userNS := h.nsManager.GetDeveloperNamespace(user.Name)
+	globalNS := h.nsManager.GetGlobalNamespace()
+
+	for _, ns := range namespace.ResolveNamespacesToSearch(targetNamespace, userNS, globalNS, searchAll, allowedNS) {
+		if lifecycle, err := h.k8sClient.GetStackLifecycle(ctx, ns, name); err == nil {
+			return lifecycle, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,138 @@
+package lifecycle_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/lifecycle"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/k8s"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+var _ = Describe("Lifecycle pause", func() {
+	var (
+		k8sClient *k8s.Client
+		handler   *lifecycle.Handler
+		alice     *middleware.User
+	)
+
+	BeforeEach(func() {
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		nsManager := authz.NewNamespaceManager(cfg)
+
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&envv1alpha1.StackLifecycle{ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "dev-alice"}},
+			&envv1alpha1.StackLifecycle{ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "dev-bob"}},
+		).Build()
+		k8sClient = k8s.NewClientFromClient(fakeClient, scheme)
+		handler = lifecycle.NewHandler(k8sClient, authz.NewAuthorizer(nsManager), nsManager)
+		alice = &middleware.User{Name: "alice", Role: authz.User}
+	})
+
+	call := func(method, target, id string, user *middleware.User, fn func(echo.Context) error) *httptest.ResponseRecorder {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(method, target, nil), rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		c.Set("user", user)
+		Expect(fn(c)).To(Succeed())
+		return rec
+	}
+
+	annotations := func(namespace string) map[string]string {
+		lc, err := k8sClient.GetStackLifecycle(context.Background(), namespace, "nightly")
+		Expect(err).NotTo(HaveOccurred())
+		return lc.Annotations
+	}
+
+	decode := func(rec *httptest.ResponseRecorder) common.LifecycleResponse {
+		var resp common.LifecycleResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		return resp
+	}
+
+	It("should set the paused annotation on pause", func() {
+		rec := call(http.MethodPost, "/lifecycles/nightly/pause", "nightly", alice, handler.PauseLifecycle)
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+		Expect(decode(rec)).To(Equal(common.LifecycleResponse{ID: "alice/nightly", Name: "nightly", Paused: true}))
+		Expect(annotations("dev-alice")).To(HaveKeyWithValue(lifecycle.PausedAnnotation, "true"))
+	})
+
+	It("should clear the paused annotation on resume", func() {
+		call(http.MethodPost, "/lifecycles/nightly/pause", "nightly", alice, handler.PauseLifecycle)
+
+		rec := call(http.MethodPost, "/lifecycles/nightly/resume", "nightly", alice, handler.ResumeLifecycle)
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+		Expect(decode(rec).Paused).To(BeFalse())
+		Expect(annotations("dev-alice")).NotTo(HaveKey(lifecycle.PausedAnnotation))
+	})
+
+	It("should report the paused state in GetLifecycle", func() {
+		rec := call(http.MethodGet, "/lifecycles/nightly", "nightly", alice, handler.GetLifecycle)
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+		Expect(decode(rec).Paused).To(BeFalse())
+
+		call(http.MethodPost, "/lifecycles/nightly/pause", "nightly", alice, handler.PauseLifecycle)
+
+		rec = call(http.MethodGet, "/lifecycles/nightly", "nightly", alice, handler.GetLifecycle)
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+		Expect(decode(rec).Paused).To(BeTrue())
+	})
+
+	It("should let admins pause lifecycles in any namespace", func() {
+		admin := &middleware.User{Name: "root", Role: authz.Admin}
+		rec := call(http.MethodPost, "/lifecycles/bob/nightly/pause", "bob/nightly", admin, handler.PauseLifecycle)
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+		Expect(annotations("dev-bob")).To(HaveKeyWithValue(lifecycle.PausedAnnotation, "true"))
+	})
+
+	It("should not let users pause lifecycles of other namespaces", func() {
+		rec := call(http.MethodPost, "/lifecycles/bob/nightly/pause", "bob/nightly", alice, handler.PauseLifecycle)
+		Expect(rec.Code).To(Equal(404), rec.Body.String())
+		Expect(annotations("dev-bob")).NotTo(HaveKey(lifecycle.PausedAnnotation))
+	})
+
+	It("should not let the deploy role pause lifecycles", func() {
+		deploy := &middleware.User{Name: "ci", Role: authz.Deploy}
+		rec := call(http.MethodPost, "/lifecycles/alice/nightly/pause", "alice/nightly", deploy, handler.PauseLifecycle)
+		Expect(rec.Code).To(Equal(403), rec.Body.String())
+		Expect(annotations("dev-alice")).NotTo(HaveKey(lifecycle.PausedAnnotation))
+	})
+
+	It("should allow pausing but not resuming in a frozen namespace, except for admins", func() {
+		Expect(k8sClient.Create(context.Background(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        "dev-alice",
+			Annotations: map[string]string{authz.FrozenAnnotation: "2026-10-01T12:00:00Z"},
+		}})).To(Succeed())
+
+		rec := call(http.MethodPost, "/lifecycles/nightly/pause", "nightly", alice, handler.PauseLifecycle)
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+
+		rec = call(http.MethodPost, "/lifecycles/nightly/resume", "nightly", alice, handler.ResumeLifecycle)
+		Expect(rec.Code).To(Equal(http.StatusLocked), rec.Body.String())
+		Expect(annotations("dev-alice")).To(HaveKeyWithValue(lifecycle.PausedAnnotation, "true"))
+
+		admin := &middleware.User{Name: "root", Role: authz.Admin}
+		rec = call(http.MethodPost, "/lifecycles/alice/nightly/resume", "alice/nightly", admin, handler.ResumeLifecycle)
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+		Expect(annotations("dev-alice")).NotTo(HaveKey(lifecycle.PausedAnnotation))
+	})
+})
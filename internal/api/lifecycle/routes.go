@@ -0,0 +1,13 @@
+package lifecycle
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterRoutes registers lifecycle routes
+func RegisterRoutes(g *echo.Group, handler *Handler) {
+	// All authorization is handled in the handler methods
+	g.GET("/:id", handler.GetLifecycle)
+	g.POST("/:id/pause", handler.PauseLifecycle)
+	g.POST("/:id/resume", handler.ResumeLifecycle)
+}
@@ -0,0 +1,64 @@
+package prepare_test
+
+import (
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+var _ = Describe("Digest allowlists", func() {
+	const (
+		approved = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		repushed = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+
+	var (
+		resolver     *mockImageResolver
+		service      types.ServiceConfig
+		lisstoConfig *compose.LisstoConfig
+	)
+
+	BeforeEach(func() {
+		project, err := prepare.ParseDockerCompose(`
+x-lissto:
+  allowedDigests:
+    registry.acme.io/web:
+      - ` + approved + `
+services:
+  web:
+    image: registry.acme.io/web:1.0
+`)
+		Expect(err).NotTo(HaveOccurred())
+		service = project.Services["web"]
+		lisstoConfig = compose.ExtractLisstoConfig(project)
+		resolver = new(mockImageResolver)
+	})
+
+	It("should deploy an approved digest", func() {
+		resolver.On("GetImageDigestWithServicePlatform", "registry.acme.io/web:1.0", mock.AnythingOfType("types.ServiceConfig")).
+			Return("registry.acme.io/web@"+approved, nil)
+
+		info, err := prepare.ResolveServiceImage(resolver, "web", service, lisstoConfig, prepare.ResolveOptions{})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Digest).To(Equal("registry.acme.io/web@" + approved))
+	})
+
+	It("should reject a re-pushed tag, also in detailed mode", func() {
+		resolver.On("GetImageDigestWithServicePlatform", "registry.acme.io/web:1.0", mock.AnythingOfType("types.ServiceConfig")).
+			Return("registry.acme.io/web@"+repushed, nil)
+
+		for _, detailed := range []bool{false, true} {
+			_, err := prepare.ResolveServiceImage(resolver, "web", service, lisstoConfig, prepare.ResolveOptions{Detailed: detailed})
+
+			Expect(err).To(MatchError(image.ErrDigestNotAllowed))
+			Expect(err).To(MatchError(ContainSubstring("service web")))
+			Expect(err).To(MatchError(ContainSubstring(repushed)))
+		}
+	})
+})
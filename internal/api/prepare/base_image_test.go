@@ -0,0 +1,80 @@
+package prepare_test
+
+import (
+	"errors"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+var _ = Describe("Base image resolution", func() {
+	var (
+		resolver *mockImageResolver
+		service  types.ServiceConfig
+	)
+
+	BeforeEach(func() {
+		service = types.ServiceConfig{
+			Name:   "api",
+			Build:  &types.BuildConfig{Context: "."},
+			Labels: map[string]string{image.BaseImageLabel: "node:20-alpine"},
+		}
+		resolver = new(mockImageResolver)
+		resolver.On("ResolveImageDetailed", mock.AnythingOfType("types.ServiceConfig"), mock.AnythingOfType("image.ResolutionConfig")).
+			Return(&image.DetailedImageResolutionResult{
+				FinalImage: "registry.io/api@sha256:own",
+				Method:     "commit",
+				Selected:   "registry.io/api:abc123",
+				Candidates: []common.ImageCandidate{{ImageURL: "registry.io/api:abc123", Source: "commit", Success: true}},
+			}, nil)
+	})
+
+	resolve := func(detailed bool) common.DetailedImageResolutionInfo {
+		info, err := prepare.ResolveServiceImage(resolver, "api", service, &compose.LisstoConfig{}, prepare.ResolveOptions{
+			Commit:   "abc123",
+			Detailed: detailed,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return info
+	}
+
+	It("should report the base image digest without affecting the service image", func() {
+		resolver.On("GetImageDigestWithServicePlatform", "node:20-alpine", mock.AnythingOfType("types.ServiceConfig")).
+			Return("node@sha256:base", nil)
+
+		info := resolve(true)
+
+		Expect(info.Digest).To(Equal("registry.io/api@sha256:own"))
+		Expect(info.Method).To(Equal("commit"))
+		Expect(info.Candidates).To(HaveLen(1))
+		Expect(info.BaseImage).To(Equal("node:20-alpine"))
+		Expect(info.BaseImageDigest).To(Equal("node@sha256:base"))
+		Expect(info.BaseImageError).To(BeEmpty())
+	})
+
+	It("should record a base image failure without failing resolution", func() {
+		resolver.On("GetImageDigestWithServicePlatform", "node:20-alpine", mock.AnythingOfType("types.ServiceConfig")).
+			Return("", errors.New("image not found: node:20-alpine"))
+
+		info := resolve(true)
+
+		Expect(info.Digest).To(Equal("registry.io/api@sha256:own"))
+		Expect(info.BaseImageDigest).To(BeEmpty())
+		Expect(info.BaseImageError).To(ContainSubstring("image not found"))
+	})
+
+	It("should skip the base image outside detailed mode", func() {
+		info := resolve(false)
+
+		Expect(info.Digest).To(Equal("registry.io/api@sha256:own"))
+		Expect(info.BaseImage).To(BeEmpty())
+		resolver.AssertNotCalled(GinkgoT(), "GetImageDigestWithServicePlatform", mock.Anything, mock.Anything)
+	})
+})
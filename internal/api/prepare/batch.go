@@ -0,0 +1,251 @@
+package prepare
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/logging"
+	"github.com/lissto-dev/api/pkg/preprocessor"
+)
+
+// PrepareStackBatch handles POST /prepare/batch
+// Resolves the blueprint's images once and returns a cached prepare result per env
+func (h *Handler) PrepareStackBatch(c echo.Context) error {
+	var req common.BatchPrepareStackRequest
+	user, _ := middleware.GetUserFromContext(c)
+
+	// Bind and validate
+	if err := c.Bind(&req); err != nil {
+		return c.String(400, "Invalid request")
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.String(400, err.Error())
+	}
+	envs := uniqueEnvs(req.Envs)
+
+	// Parse blueprint reference
+	blueprintNamespace, blueprintName, err := h.nsManager.ParseScopedID(req.Blueprint)
+	if err != nil {
+		return c.String(400, fmt.Sprintf("Invalid blueprint reference: %v", err))
+	}
+
+	perm := h.authorizer.CanAccess(user.Role, authz.ActionRead, authz.ResourceBlueprint, blueprintNamespace, user.Name)
+	if !perm.Allowed {
+		logging.LogDeniedWithIP("insufficient_permissions", user.Name, "POST /prepare/batch", c.RealIP())
+		return c.String(403, fmt.Sprintf("Permission denied: %s", perm.Reason))
+	}
+
+	logging.Logger.Info("Stack batch prepare request",
+		zap.String("user", user.Name),
+		zap.String("blueprint", req.Blueprint),
+		zap.String("commit", req.Commit),
+		zap.String("branch", req.Branch),
+		zap.Strings("envs", envs))
+
+	// Validate every env exists before doing any registry work
+	namespace := h.nsManager.GetDeveloperNamespace(user.Name)
+	for _, envName := range envs {
+		if _, err := h.k8sClient.GetEnv(c.Request().Context(), namespace, envName); err != nil {
+			logging.Logger.Error("Failed to get env",
+				zap.String("env", envName),
+				zap.String("namespace", namespace),
+				zap.Error(err))
+			return c.String(404, fmt.Sprintf("Env '%s' not found", envName))
+		}
+	}
+
+	blueprint, err := h.k8sClient.GetBlueprint(c.Request().Context(), blueprintNamespace, blueprintName)
+	if err != nil {
+		logging.Logger.Error("Failed to get blueprint",
+			zap.String("blueprint", req.Blueprint),
+			zap.Error(err))
+		return c.String(404, "Blueprint not found")
+	}
+
+	project, err := ParseDockerCompose(blueprint.Spec.DockerCompose)
+	if err != nil {
+		logging.Logger.Error("Failed to parse Docker Compose",
+			zap.String("blueprint", req.Blueprint),
+			zap.Error(err))
+		return c.String(400, "Invalid Docker Compose content")
+	}
+	if rejected, err := common.RejectPolicyViolations(c, project, h.deniedFeatures); rejected {
+		return err
+	}
+	labelWarnings, rejected, err := EnforceLabelPolicy(c, user.Role, h.roles, project)
+	if rejected {
+		return err
+	}
+
+	// Optional point-in-time resolution (zero keeps the normal candidate order)
+	var resolvedBefore time.Time
+	if req.ResolvedBefore != nil {
+		resolvedBefore = *req.ResolvedBefore
+	}
+
+	lisstoConfig := compose.ExtractLisstoConfig(project)
+	exposePreprocessor, err := NewExposePreprocessor(h.config, h.ingress).WithDefaultVisibility(lisstoConfig.ExposeDefault)
+	if err != nil {
+		return c.String(400, err.Error())
+	}
+	resultsByEnv, err := ResolveBatchImages(h.imageResolver, exposePreprocessor, project, lisstoConfig, envs, ResolveOptions{
+		Commit:         req.Commit,
+		Branch:         req.Branch,
+		Detailed:       req.Detailed,
+		AllowPending:   req.AllowPending,
+		ResolvedBefore: resolvedBefore,
+
+		UnpinnedFallback: h.unpinnedFallback,
+		Verifier:         h.signatureVerifier,
+	})
+	if err != nil {
+		return c.String(400, err.Error())
+	}
+
+	// TLS secrets live in the user's namespace, so the warnings are the same for every env
+	warnings := append(labelWarnings, CheckTLSSecrets(c.Request().Context(), h.k8sClient, namespace, project.Services, exposePreprocessor)...)
+	// Env-scoped variables and secrets differ per env and are checked for each
+	envReferences := compose.ExtractEnvReferences(project)
+
+	// One prepare result is cached per env; the quota admits them all or rejects the batch
+	requestIDs := make(map[string]string, len(envs))
+	ids := make([]string, 0, len(envs))
+	for _, envName := range envs {
+		requestIDs[envName] = uuid.New().String()
+		ids = append(ids, requestIDs[envName])
+	}
+	evicted, err := h.quota.Admit(user.Name, user.Role.String(), ids...)
+	if err != nil {
+		logging.LogDeniedWithIP("prepare_quota_exceeded", user.Name, "POST /prepare/batch", c.RealIP())
+		return c.String(429, err.Error())
+	}
+	h.evictPrepareResults(c.Request().Context(), user.Name, evicted)
+
+	response := common.BatchPrepareStackResponse{
+		Blueprint: req.Blueprint,
+		Results:   make(map[string]common.DetailedPrepareStackResponse, len(envs)),
+	}
+	for _, envName := range envs {
+		results := resultsByEnv[envName]
+		requestID := requestIDs[envName]
+
+		cacheEntry := &cache.PrepareResultCache{
+			Namespace: namespace,
+			Images:    make(map[string]cache.ImageInfoCache),
+		}
+		var exposedServices []common.ExposedServiceInfo
+		for _, result := range results {
+			verified, signer := verifiedSignature(result)
+			cacheEntry.Images[result.Service] = cache.ImageInfoCache{
+				Digest:   result.Digest,
+				Image:    result.Image,
+				URL:      result.URL,
+				Pending:  result.Pending,
+				Unpinned: result.Unpinned,
+				Verified: verified,
+				Signer:   signer,
+			}
+			if result.Exposed {
+				exposedServices = append(exposedServices, common.ExposedServiceInfo{
+					Service: result.Service,
+					URL:     result.URL,
+				})
+			}
+		}
+
+		// Cache with 15 min TTL, same as a single prepare
+		envWarnings := append(append([]common.PrepareWarning{}, warnings...),
+			CheckEnvReferences(c.Request().Context(), h.k8sClient, namespace, envName, envReferences)...)
+		// Each env has its own floor, so it is applied to a copy of the project
+		envProject := *project
+		envProject.Services = maps.Clone(project.Services)
+		envWarnings = append(envWarnings, ApplyResourceFloor(&envProject, envName, h.resources)...)
+		if err := h.cache.Set(c.Request().Context(), requestID, cacheEntry, PrepareResultTTL); errors.Is(err, cache.ErrEntryTooLarge) {
+			logging.Logger.Warn("Prepare result too large to cache",
+				zap.String("env", envName),
+				zap.Error(err))
+			h.quota.Release(user.Name, requestID)
+			requestID = ""
+			envWarnings = append(envWarnings, CacheEntryTooLargeWarning(err))
+		} else if err != nil {
+			logging.Logger.Warn("Failed to cache prepare result",
+				zap.String("env", envName),
+				zap.Error(err))
+		}
+
+		response.Results[envName] = common.DetailedPrepareStackResponse{
+			RequestID: requestID,
+			Blueprint: req.Blueprint,
+			Images:    results,
+			Exposed:   exposedServices,
+			Warnings:  envWarnings,
+		}
+	}
+
+	logging.Logger.Info("Batch prepare completed",
+		zap.String("blueprint", req.Blueprint),
+		zap.Int("envs", len(envs)),
+		zap.Int("services", len(project.Services)))
+
+	return c.JSON(200, response)
+}
+
+// ResolveBatchImages resolves every service image once and returns a copy per env
+// with that env's exposed URLs filled in
+func ResolveBatchImages(
+	resolver ImageResolver,
+	exposePreprocessor *preprocessor.ExposePreprocessor,
+	project *types.Project,
+	lisstoConfig *compose.LisstoConfig,
+	envs []string,
+	opts ResolveOptions,
+) (map[string][]common.DetailedImageResolutionInfo, error) {
+	shared := make([]common.DetailedImageResolutionInfo, 0, len(project.Services))
+	for serviceName, service := range project.Services {
+		info, err := ResolveServiceImage(resolver, serviceName, service, lisstoConfig, opts)
+		if err != nil {
+			return nil, err
+		}
+		shared = append(shared, info)
+	}
+
+	resultsByEnv := make(map[string][]common.DetailedImageResolutionInfo, len(envs))
+	for _, envName := range envs {
+		results := make([]common.DetailedImageResolutionInfo, len(shared))
+		copy(results, shared)
+		for i := range results {
+			service := project.Services[results[i].Service]
+			if exposedURL := exposePreprocessor.GetExposedServiceURL(service, results[i].Service, envName); exposedURL != "" {
+				results[i].Exposed = true
+				results[i].URL = exposedURL
+			}
+		}
+		resultsByEnv[envName] = results
+	}
+	return resultsByEnv, nil
+}
+
+// uniqueEnvs drops repeated env names, keeping the request order
+func uniqueEnvs(envs []string) []string {
+	seen := make(map[string]bool, len(envs))
+	unique := make([]string, 0, len(envs))
+	for _, envName := range envs {
+		if !seen[envName] {
+			seen[envName] = true
+			unique = append(unique, envName)
+		}
+	}
+	return unique
+}
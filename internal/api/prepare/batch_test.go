@@ -0,0 +1,92 @@
+package prepare_test
+
+import (
+	"errors"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/image"
+	"github.com/lissto-dev/api/pkg/preprocessor"
+)
+
+var _ = Describe("Batch prepare", func() {
+	var (
+		resolver           *mockImageResolver
+		exposePreprocessor *preprocessor.ExposePreprocessor
+		project            *types.Project
+	)
+
+	BeforeEach(func() {
+		resolver = new(mockImageResolver)
+		resolver.On("ResolveImageDetailed", mock.AnythingOfType("types.ServiceConfig"), mock.AnythingOfType("image.ResolutionConfig")).
+			Return(&image.DetailedImageResolutionResult{
+				FinalImage: "registry.io/web@sha256:web",
+				Method:     "commit",
+				Selected:   "registry.io/web:abc123",
+			}, nil)
+		resolver.On("GetImageDigestWithServicePlatform", "postgres:16", mock.AnythingOfType("types.ServiceConfig")).
+			Return("postgres@sha256:db", nil)
+
+		exposePreprocessor = preprocessor.NewExposePreprocessor(
+			nil,
+			&preprocessor.IngressConfig{IngressClass: "nginx", HostSuffix: ".example.com"},
+		)
+		project = &types.Project{Services: types.Services{
+			"web": {Name: "web", Build: &types.BuildConfig{Context: "."}, Labels: map[string]string{"lissto.dev/expose": "internet"}},
+			"db":  {Name: "db", Image: "postgres:16"},
+		}}
+	})
+
+	findService := func(results []common.DetailedImageResolutionInfo, name string) common.DetailedImageResolutionInfo {
+		for _, result := range results {
+			if result.Service == name {
+				return result
+			}
+		}
+		Fail("service not found: " + name)
+		return common.DetailedImageResolutionInfo{}
+	}
+
+	It("should resolve images once and compute URLs per env", func() {
+		resultsByEnv, err := prepare.ResolveBatchImages(resolver, exposePreprocessor, project, &compose.LisstoConfig{},
+			[]string{"dev", "staging", "prod"}, prepare.ResolveOptions{Commit: "abc123"})
+		Expect(err).NotTo(HaveOccurred())
+
+		resolver.AssertNumberOfCalls(GinkgoT(), "ResolveImageDetailed", 1)
+		resolver.AssertNumberOfCalls(GinkgoT(), "GetImageDigestWithServicePlatform", 1)
+
+		Expect(resultsByEnv).To(HaveLen(3))
+		urls := map[string]bool{}
+		for envName, results := range resultsByEnv {
+			Expect(results).To(HaveLen(2))
+
+			web := findService(results, "web")
+			Expect(web.Digest).To(Equal("registry.io/web@sha256:web"))
+			Expect(web.Exposed).To(BeTrue())
+			Expect(web.URL).To(ContainSubstring(envName))
+			urls[web.URL] = true
+
+			db := findService(results, "db")
+			Expect(db.Digest).To(Equal("postgres@sha256:db"))
+			Expect(db.Exposed).To(BeFalse())
+			Expect(db.URL).To(BeEmpty())
+		}
+		Expect(urls).To(HaveLen(3))
+	})
+
+	It("should fail the whole batch when an image cannot be resolved", func() {
+		project.Services["cache"] = types.ServiceConfig{Name: "cache", Image: "redis:7"}
+		resolver.On("GetImageDigestWithServicePlatform", "redis:7", mock.AnythingOfType("types.ServiceConfig")).
+			Return("", errors.New("image not found: redis:7"))
+
+		_, err := prepare.ResolveBatchImages(resolver, exposePreprocessor, project, &compose.LisstoConfig{},
+			[]string{"dev", "prod"}, prepare.ResolveOptions{})
+		Expect(err).To(MatchError(ContainSubstring("cache")))
+	})
+})
@@ -0,0 +1,50 @@
+package prepare
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+// WarningCacheEntryTooLarge is reported when a prepare result exceeds the cache entry limit
+// The result is returned without a request ID, so the stack cannot be created from it
+const WarningCacheEntryTooLarge = "cache_entry_too_large"
+
+// CacheEntryTooLargeWarning builds the warning for a prepare result rejected by the cache
+func CacheEntryTooLargeWarning(err error) common.PrepareWarning {
+	return common.PrepareWarning{
+		Code:    WarningCacheEntryTooLarge,
+		Message: "Prepare result was not cached (" + err.Error() + "); reduce the blueprint size to create a stack from it",
+	}
+}
+
+// ParseCacheOverride reads the admin-only ?noCache=true and ?cacheTtl=<duration> of a prepare request
+// noCache always asks the registry (fresh digests are still cached), cacheTtl ignores cached digests
+// older than the duration and caches fresh ones no longer than it. Other roles keep the normal cache behavior.
+func ParseCacheOverride(c echo.Context, role authz.Role) (image.CacheOverride, error) {
+	var override image.CacheOverride
+	if role != authz.Admin {
+		return override, nil
+	}
+	if value := c.QueryParam("noCache"); value != "" {
+		skip, err := strconv.ParseBool(value)
+		if err != nil {
+			return override, fmt.Errorf("invalid noCache %q", value)
+		}
+		override.SkipRead = skip
+	}
+	if value := c.QueryParam("cacheTtl"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl <= 0 {
+			return override, fmt.Errorf("invalid cacheTtl %q: must be a positive duration (e.g. 5m), use noCache=true to bypass the cache", value)
+		}
+		override.TTL = ttl
+	}
+	return override, nil
+}
@@ -0,0 +1,109 @@
+package prepare_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/k8s"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+var _ = Describe("Prepare cache overrides", func() {
+	const body = `{"compose":"services:\n  web:\n    image: registry.acme.io/web:1.4.0\n","env":"dev"}`
+
+	var (
+		h       *prepare.Handler
+		checker *countingChecker
+		admin   *middleware.User
+		alice   *middleware.User
+	)
+
+	BeforeEach(func() {
+		Expect(logging.InitLogger("info", "console")).To(Succeed())
+
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}},
+			&envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-root"}},
+		).Build()
+
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		nsManager := authz.NewNamespaceManager(cfg)
+		h = prepare.NewHandler(k8s.NewClientFromClient(fakeClient, scheme), authz.NewAuthorizer(nsManager), nsManager,
+			cfg, config.DefaultSettings(), cache.NewMemoryCache())
+		checker = &countingChecker{}
+		h.SetCachedImageChecker(checker)
+
+		admin = &middleware.User{Name: "root", Role: authz.Admin}
+		alice = &middleware.User{Name: "alice", Role: authz.User}
+	})
+
+	prepareAs := func(user *middleware.User, query string) *httptest.ResponseRecorder {
+		e := echo.New()
+		e.Validator = &testValidator{validator: validator.New()}
+		req := httptest.NewRequest(http.MethodPost, "/stacks/prepare"+query, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user", user)
+		Expect(h.PrepareStack(c)).To(Succeed())
+		return rec
+	}
+
+	// warmCache resolves the image once and checks the next prepare is served from the digest cache
+	warmCache := func() int {
+		Expect(prepareAs(admin, "").Code).To(Equal(200))
+		calls := checker.calls
+		Expect(calls).To(BeNumerically(">", 0))
+		Expect(prepareAs(admin, "").Code).To(Equal(200))
+		Expect(checker.calls).To(Equal(calls))
+		return calls
+	}
+
+	It("should ask the registry despite a warm cache entry with noCache", func() {
+		calls := warmCache()
+
+		Expect(prepareAs(admin, "?noCache=true").Code).To(Equal(200))
+		Expect(checker.calls).To(Equal(2 * calls))
+	})
+
+	It("should refetch digests cached longer ago than cacheTtl", func() {
+		calls := warmCache()
+
+		Expect(prepareAs(admin, "?cacheTtl=1h").Code).To(Equal(200))
+		Expect(checker.calls).To(Equal(calls))
+		Expect(prepareAs(admin, "?cacheTtl=1ns").Code).To(Equal(200))
+		Expect(checker.calls).To(Equal(2 * calls))
+	})
+
+	It("should keep the cache for other roles", func() {
+		calls := warmCache()
+
+		Expect(prepareAs(alice, "?noCache=true").Code).To(Equal(200))
+		Expect(checker.calls).To(Equal(calls))
+	})
+
+	It("should reject invalid overrides", func() {
+		rec := prepareAs(admin, "?cacheTtl=-5m")
+		Expect(rec.Code).To(Equal(400))
+		Expect(rec.Body.String()).To(ContainSubstring("cacheTtl"))
+	})
+})
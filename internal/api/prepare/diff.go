@@ -0,0 +1,122 @@
+package prepare
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// DiffPrepared handles GET /prepare/diff?from=<request_id>&to=<request_id>
+// Compares the images two prepare results would deploy, e.g. before promoting dev to prod
+func (h *Handler) DiffPrepared(c echo.Context) error {
+	user, _ := middleware.GetUserFromContext(c)
+	fromID := c.QueryParam("from")
+	toID := c.QueryParam("to")
+	if fromID == "" || toID == "" {
+		return c.String(400, "Both from and to request IDs are required")
+	}
+
+	namespace := h.nsManager.GetDeveloperNamespace(user.Name)
+	results := make(map[string]*cache.PrepareResultCache, 2)
+	for _, requestID := range []string{fromID, toID} {
+		var result cache.PrepareResultCache
+		if err := h.cache.Get(c.Request().Context(), requestID, &result); err != nil {
+			if !cache.IsMiss(err) {
+				logging.Logger.Error("Cache backend unavailable while retrieving prepare result",
+					zap.String("request_id", requestID),
+					zap.Error(err))
+				return c.String(503, "Cache temporarily unavailable. Please retry.")
+			}
+			return c.String(404, fmt.Sprintf("Request ID '%s' not found or expired", requestID))
+		}
+
+		// Only diff results prepared in the caller's namespace
+		if result.Namespace != namespace {
+			logging.Logger.Warn("Request ID namespace mismatch",
+				zap.String("request_id", requestID),
+				zap.String("cached_namespace", result.Namespace),
+				zap.String("user_namespace", namespace))
+			return c.String(404, fmt.Sprintf("Request ID '%s' not found or expired", requestID))
+		}
+		results[requestID] = &result
+	}
+
+	return c.JSON(200, common.PrepareDiffResponse{
+		From:     fromID,
+		To:       toID,
+		Services: DiffPrepareResults(results[fromID], results[toID]),
+	})
+}
+
+// DiffPrepareResults compares two cached prepare results per service, sorted by service name
+func DiffPrepareResults(from, to *cache.PrepareResultCache) []common.ServiceDiff {
+	services := make(map[string]bool, len(from.Images)+len(to.Images))
+	for service := range from.Images {
+		services[service] = true
+	}
+	for service := range to.Images {
+		services[service] = true
+	}
+
+	diffs := make([]common.ServiceDiff, 0, len(services))
+	for service := range services {
+		diff := common.ServiceDiff{Service: service}
+		fromInfo, inFrom := from.Images[service]
+		toInfo, inTo := to.Images[service]
+		if inFrom {
+			diff.From = preparedImageInfo(fromInfo)
+		}
+		if inTo {
+			diff.To = preparedImageInfo(toInfo)
+		}
+
+		switch {
+		case !inFrom:
+			diff.Change = common.DiffAdded
+		case !inTo:
+			diff.Change = common.DiffRemoved
+		default:
+			diff.Fields = changedFields(fromInfo, toInfo)
+			diff.Change = common.DiffSame
+			if len(diff.Fields) > 0 {
+				diff.Change = common.DiffChanged
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Service < diffs[j].Service })
+	return diffs
+}
+
+// changedFields lists the deployed fields that differ between two cached images
+func changedFields(from, to cache.ImageInfoCache) []string {
+	var fields []string
+	if from.Digest != to.Digest {
+		fields = append(fields, "digest")
+	}
+	if from.Image != to.Image {
+		fields = append(fields, "tag")
+	}
+	if from.URL != to.URL {
+		fields = append(fields, "url")
+	}
+	return fields
+}
+
+// preparedImageInfo converts a cached image for the diff response
+func preparedImageInfo(info cache.ImageInfoCache) *common.PreparedImageInfo {
+	return &common.PreparedImageInfo{
+		Digest:   info.Digest,
+		Tag:      info.Image,
+		URL:      info.URL,
+		Pending:  info.Pending,
+		Unpinned: info.Unpinned,
+	}
+}
@@ -0,0 +1,110 @@
+package prepare_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/k8s"
+	"github.com/lissto-dev/api/pkg/logging"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+var _ = Describe("Prepare diff", func() {
+	var (
+		h        *prepare.Handler
+		memCache *cache.MemoryCache
+	)
+
+	BeforeEach(func() {
+		Expect(logging.InitLogger("info", "console")).To(Succeed())
+
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		nsManager := authz.NewNamespaceManager(cfg)
+		memCache = cache.NewMemoryCache()
+		h = prepare.NewHandler(k8s.NewClientFromClient(fake.NewClientBuilder().WithScheme(scheme).Build(), scheme),
+			authz.NewAuthorizer(nsManager), nsManager, cfg, config.DefaultSettings(), memCache)
+
+		ctx := context.Background()
+		Expect(memCache.Set(ctx, "dev-req", &cache.PrepareResultCache{
+			Namespace: "dev-alice",
+			Images: map[string]cache.ImageInfoCache{
+				"api":    {Digest: "registry.io/api@sha256:new", Image: "registry.io/api:abc123", URL: "https://api-dev.example.com"},
+				"db":     {Digest: "postgres@sha256:db", Image: "postgres:16"},
+				"worker": {Digest: "registry.io/worker@sha256:w", Image: "registry.io/worker:abc123"},
+			},
+		}, time.Minute)).To(Succeed())
+		Expect(memCache.Set(ctx, "prod-req", &cache.PrepareResultCache{
+			Namespace: "dev-alice",
+			Images: map[string]cache.ImageInfoCache{
+				"api":   {Digest: "registry.io/api@sha256:old", Image: "registry.io/api:abc123", URL: "https://api-dev.example.com"},
+				"db":    {Digest: "postgres@sha256:db", Image: "postgres:16"},
+				"cache": {Digest: "redis@sha256:r", Image: "redis:7"},
+			},
+		}, time.Minute)).To(Succeed())
+		Expect(memCache.Set(ctx, "bob-req", &cache.PrepareResultCache{Namespace: "dev-bob"}, time.Minute)).To(Succeed())
+	})
+
+	diff := func(query string) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/prepare/diff?"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user", &middleware.User{Name: "alice", Role: authz.User})
+		Expect(h.DiffPrepared(c)).To(Succeed())
+		return rec
+	}
+
+	It("should report the changed digest per service", func() {
+		rec := diff("from=prod-req&to=dev-req")
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+
+		var resp common.PrepareDiffResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.From).To(Equal("prod-req"))
+		Expect(resp.To).To(Equal("dev-req"))
+		Expect(resp.Services).To(HaveLen(4))
+
+		api, cacheSvc, db, worker := resp.Services[0], resp.Services[1], resp.Services[2], resp.Services[3]
+		Expect(api.Service).To(Equal("api"))
+		Expect(api.Change).To(Equal(common.DiffChanged))
+		Expect(api.Fields).To(Equal([]string{"digest"}))
+		Expect(api.From.Digest).To(Equal("registry.io/api@sha256:old"))
+		Expect(api.To.Digest).To(Equal("registry.io/api@sha256:new"))
+
+		Expect(cacheSvc.Change).To(Equal(common.DiffRemoved))
+		Expect(cacheSvc.To).To(BeNil())
+		Expect(db.Change).To(Equal(common.DiffSame))
+		Expect(db.Fields).To(BeEmpty())
+		Expect(worker.Change).To(Equal(common.DiffAdded))
+		Expect(worker.From).To(BeNil())
+	})
+
+	It("should not diff request IDs from another namespace", func() {
+		rec := diff("from=dev-req&to=bob-req")
+		Expect(rec.Code).To(Equal(404))
+		Expect(rec.Body.String()).To(ContainSubstring("bob-req"))
+	})
+
+	It("should reject unknown or missing request IDs", func() {
+		Expect(diff("from=dev-req&to=expired").Code).To(Equal(404))
+		Expect(diff("from=dev-req").Code).To(Equal(400))
+	})
+})
@@ -0,0 +1,85 @@
+package prepare
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+// Warning codes reported by the env reference check
+const (
+	WarningVariableMissing        = "variable_missing"
+	WarningSecretMissing          = "secret_missing"
+	WarningEnvReferenceUnverified = "env_reference_unverified"
+)
+
+// EnvResourceGetter reads LisstoVariables and LisstoSecrets
+type EnvResourceGetter interface {
+	GetLisstoVariable(ctx context.Context, namespace, name string) (*envv1alpha1.LisstoVariable, error)
+	GetLisstoSecret(ctx context.Context, namespace, name string) (*envv1alpha1.LisstoSecret, error)
+}
+
+// CheckEnvReferences verifies that the variables and secrets a blueprint declares in x-lissto.env exist
+// for env in the stack namespace; env-scoped ones must be bound to env. Problems are reported as warnings
+// in declaration order, variables first.
+func CheckEnvReferences(
+	ctx context.Context,
+	getter EnvResourceGetter,
+	namespace, env string,
+	refs compose.EnvReferences,
+) []common.PrepareWarning {
+	var warnings []common.PrepareWarning
+
+	check := func(kind, name, missingCode string, get func() (scope, boundEnv string, err error)) {
+		scope, boundEnv, err := get()
+		switch {
+		case errors.IsNotFound(err):
+			warnings = append(warnings, common.PrepareWarning{
+				Code:    missingCode,
+				Message: fmt.Sprintf("%s '%s' referenced by x-lissto.env not found in namespace '%s'", kind, name, namespace),
+			})
+		case err != nil:
+			logging.Logger.Warn("Failed to check env reference",
+				zap.String("kind", kind),
+				zap.String("name", name),
+				zap.String("namespace", namespace),
+				zap.Error(err))
+			warnings = append(warnings, common.PrepareWarning{
+				Code:    WarningEnvReferenceUnverified,
+				Message: fmt.Sprintf("could not verify %s '%s' referenced by x-lissto.env: %v", kind, name, err),
+			})
+		case scope == "env" && boundEnv != env:
+			warnings = append(warnings, common.PrepareWarning{
+				Code:    missingCode,
+				Message: fmt.Sprintf("%s '%s' referenced by x-lissto.env belongs to env '%s', not '%s'", kind, name, boundEnv, env),
+			})
+		}
+	}
+
+	for _, name := range refs.Variables {
+		check("variable", name, WarningVariableMissing, func() (string, string, error) {
+			variable, err := getter.GetLisstoVariable(ctx, namespace, name)
+			if err != nil {
+				return "", "", err
+			}
+			return variable.GetScope(), variable.Spec.Env, nil
+		})
+	}
+	for _, name := range refs.Secrets {
+		check("secret", name, WarningSecretMissing, func() (string, string, error) {
+			secret, err := getter.GetLisstoSecret(ctx, namespace, name)
+			if err != nil {
+				return "", "", err
+			}
+			return secret.GetScope(), secret.Spec.Env, nil
+		})
+	}
+	return warnings
+}
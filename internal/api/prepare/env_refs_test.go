@@ -0,0 +1,83 @@
+package prepare_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/k8s"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+var _ = Describe("CheckEnvReferences", func() {
+	const blueprint = `
+x-lissto:
+  env:
+    variables: [app-config]
+    secrets: [db-creds, stripe]
+services:
+  api:
+    image: api
+`
+
+	var refs compose.EnvReferences
+
+	BeforeEach(func() {
+		Expect(logging.InitLogger("info", "console")).To(Succeed())
+		project, err := prepare.ParseDockerCompose(blueprint)
+		Expect(err).NotTo(HaveOccurred())
+		refs = compose.ExtractEnvReferences(project)
+		Expect(refs).To(Equal(compose.EnvReferences{Variables: []string{"app-config"}, Secrets: []string{"db-creds", "stripe"}}))
+	})
+
+	newClient := func(objects ...client.Object) *k8s.Client {
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		return k8s.NewClientFromClient(fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(), scheme)
+	}
+	variable := &envv1alpha1.LisstoVariable{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "dev-alice"},
+		Spec:       envv1alpha1.LisstoVariableSpec{Env: "dev"},
+	}
+	secret := func(name, env string) *envv1alpha1.LisstoSecret {
+		return &envv1alpha1.LisstoSecret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "dev-alice"},
+			Spec:       envv1alpha1.LisstoSecretSpec{Env: env},
+		}
+	}
+
+	It("should not warn when every reference exists in the env", func() {
+		c := newClient(variable, secret("db-creds", "dev"), secret("stripe", "dev"))
+
+		Expect(prepare.CheckEnvReferences(context.Background(), c, "dev-alice", "dev", refs)).To(BeEmpty())
+	})
+
+	It("should warn about a missing secret", func() {
+		c := newClient(variable, secret("db-creds", "dev"))
+
+		warnings := prepare.CheckEnvReferences(context.Background(), c, "dev-alice", "dev", refs)
+
+		Expect(warnings).To(Equal([]common.PrepareWarning{{
+			Code:    prepare.WarningSecretMissing,
+			Message: "secret 'stripe' referenced by x-lissto.env not found in namespace 'dev-alice'",
+		}}))
+	})
+
+	It("should warn about a secret bound to another env", func() {
+		c := newClient(variable, secret("db-creds", "staging"), secret("stripe", "dev"))
+
+		warnings := prepare.CheckEnvReferences(context.Background(), c, "dev-alice", "dev", refs)
+
+		Expect(warnings).To(HaveLen(1))
+		Expect(warnings[0].Code).To(Equal(prepare.WarningSecretMissing))
+		Expect(warnings[0].Message).To(ContainSubstring("belongs to env 'staging'"))
+	})
+})
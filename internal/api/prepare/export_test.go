@@ -0,0 +1,13 @@
+package prepare
+
+import "github.com/lissto-dev/api/pkg/image"
+
+// SetImageChecker replaces the registry lookups of the handler's image resolver
+func (h *Handler) SetImageChecker(checker image.ImageChecker) {
+	h.imageResolver = image.NewImageResolver(h.config.Stacks.Images.Registry, h.config.Stacks.Images.RepositoryPrefix, checker)
+}
+
+// SetCachedImageChecker replaces the registry lookups of the handler's image resolver, caching digests in the handler's cache
+func (h *Handler) SetCachedImageChecker(checker image.ImageChecker) {
+	h.imageResolver = image.NewImageResolverWithCache(h.config.Stacks.Images.Registry, h.config.Stacks.Images.RepositoryPrefix, checker, h.cache)
+}
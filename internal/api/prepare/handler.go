@@ -2,7 +2,9 @@ package prepare
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/compose-spec/compose-go/v2/loader"
@@ -16,6 +18,7 @@ import (
 	"github.com/lissto-dev/api/pkg/authz"
 	"github.com/lissto-dev/api/pkg/cache"
 	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/config"
 	"github.com/lissto-dev/api/pkg/image"
 	"github.com/lissto-dev/api/pkg/k8s"
 	"github.com/lissto-dev/api/pkg/logging"
@@ -31,6 +34,17 @@ type Handler struct {
 	config        *controllerconfig.Config
 	imageResolver *image.ImageResolver
 	cache         cache.Cache
+	// signatureVerifier checks cosign signatures of resolved images, nil when not configured
+	signatureVerifier image.SignatureVerifier
+	quota             *PrepareQuota // Per-user index of cached prepare results
+	sharedResultTTL   time.Duration // How long resolved images are reused by identical prepares, 0 disables
+
+	ingress        config.IngressSettings
+	roles          map[string]config.RoleSettings // Per-role host_override_suffix permission
+	deniedFeatures []string                       // Compose features rejected by the policy
+	resources      config.ResourceSettings        // Default resources and the floors of envs
+
+	unpinnedFallback bool // Deploy compose image tags unpinned when they cannot be resolved
 }
 
 // NewHandler creates a new stack preparation handler
@@ -39,40 +53,68 @@ func NewHandler(
 	authorizer *authz.Authorizer,
 	nsManager *authz.NamespaceManager,
 	cfg *controllerconfig.Config,
+	settings *config.Settings,
 	cache cache.Cache,
 ) *Handler {
-	// Create image existence checker with K8s authentication
-	// This will automatically use:
-	// - Image pull secrets from the pod's service account
-	// - Node IAM credentials (ECR on AWS, Workload Identity on GCP, etc.)
-	// - Docker config files and credential helpers
-	// Falls back to anonymous access if authentication is not available
-	ctx := context.Background()
-	imageChecker := image.NewImageExistenceCheckerWithK8sAuth(ctx)
-
-	// Create image resolver with global config and cache support
-	imageResolver := image.NewImageResolverWithCache(
-		cfg.Stacks.Images.Registry,
-		cfg.Stacks.Images.RepositoryPrefix,
-		imageChecker,
-		cache,
-	)
+	imageResolver := NewImageResolver(cfg, settings, cache)
+	signatureVerifier, err := NewSignatureVerifier(settings.Signatures, cache)
+	if err != nil {
+		// Without a verifier no image is verified, so required signatures fail closed at stack creation
+		logging.Logger.Error("Image signature verification disabled", zap.Error(err))
+	}
 
 	logging.Logger.Info("Image resolver created with global config and cache",
 		zap.String("global_registry", cfg.Stacks.Images.Registry),
 		zap.String("global_repository_prefix", cfg.Stacks.Images.RepositoryPrefix),
+		zap.Strings("tag_sources", imageResolver.TagSources()),
+		zap.String("default_tag", imageResolver.DefaultTag()),
 		zap.Bool("cache_enabled", cache != nil))
 
 	return &Handler{
-		k8sClient:     k8sClient,
-		authorizer:    authorizer,
-		nsManager:     nsManager,
-		config:        cfg,
-		imageResolver: imageResolver,
-		cache:         cache,
+		k8sClient:         k8sClient,
+		authorizer:        authorizer,
+		nsManager:         nsManager,
+		config:            cfg,
+		imageResolver:     imageResolver,
+		cache:             cache,
+		signatureVerifier: signatureVerifier,
+		quota:             NewPrepareQuota(settings.Prepare),
+		sharedResultTTL:   settings.Prepare.SharedResultTTL(),
+		ingress:           settings.Ingress,
+		roles:             settings.Roles,
+		deniedFeatures:    settings.Compose.DeniedFeatures,
+		resources:         settings.Resources,
+
+		unpinnedFallback: settings.Images.UnpinnedFallback,
 	}
 }
 
+// NewExposePreprocessor creates the expose preprocessor for the configured ingress classes
+// ingress selects the TLS mode and cert-manager issuers, the zero value references the static TLS secrets
+func NewExposePreprocessor(cfg *controllerconfig.Config, ingress config.IngressSettings) *preprocessor.ExposePreprocessor {
+	var internalConfig *preprocessor.IngressConfig
+	if cfg.Stacks.Ingress.Internal != nil {
+		internalConfig = &preprocessor.IngressConfig{
+			IngressClass:  cfg.Stacks.Ingress.Internal.IngressClass,
+			HostSuffix:    cfg.Stacks.Ingress.Internal.HostSuffix,
+			TLSSecret:     cfg.Stacks.Ingress.Internal.TLSSecret,
+			TLSMode:       ingress.TLSMode,
+			ClusterIssuer: ingress.ClusterIssuer(string(preprocessor.VisibilityInternal)),
+		}
+	}
+	var internetConfig *preprocessor.IngressConfig
+	if cfg.Stacks.Ingress.Internet != nil {
+		internetConfig = &preprocessor.IngressConfig{
+			IngressClass:  cfg.Stacks.Ingress.Internet.IngressClass,
+			HostSuffix:    cfg.Stacks.Ingress.Internet.HostSuffix,
+			TLSSecret:     cfg.Stacks.Ingress.Internet.TLSSecret,
+			TLSMode:       ingress.TLSMode,
+			ClusterIssuer: ingress.ClusterIssuer(string(preprocessor.VisibilityInternet)),
+		}
+	}
+	return preprocessor.NewExposePreprocessor(internalConfig, internetConfig)
+}
+
 // PrepareStack handles POST /stacks/prepare
 func (h *Handler) PrepareStack(c echo.Context) error {
 	var req common.PrepareStackRequest
@@ -85,14 +127,59 @@ func (h *Handler) PrepareStack(c echo.Context) error {
 	if err := c.Validate(&req); err != nil {
 		return c.String(400, err.Error())
 	}
+	sources := 0
+	for _, set := range []bool{req.Blueprint != "", len(req.Blueprints) > 0, req.Compose != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return c.String(400, "Exactly one of blueprint, blueprints or compose is required")
+	}
+
+	// Admins may bypass or shorten the image digest cache, e.g. after a registry served a bad digest
+	cacheOverride, err := ParseCacheOverride(c, user.Role)
+	if err != nil {
+		return c.String(400, err.Error())
+	}
+	baseResolver := h.imageResolver
+	if cacheOverride != (image.CacheOverride{}) {
+		logging.Logger.Info("Overriding image digest cache for prepare",
+			zap.String("user", user.Name),
+			zap.Bool("no_cache", cacheOverride.SkipRead),
+			zap.Duration("cache_ttl", cacheOverride.TTL))
+		baseResolver = baseResolver.WithCacheOverride(cacheOverride)
+	}
+
+	// A request credential replaces the shared resolver for this request only
+	var resolver ImageResolver = baseResolver
+	if req.RegistryAuth != nil {
+		if !c.IsTLS() {
+			logging.LogDeniedWithIP("registry_auth_without_tls", user.Name, "POST /prepare", c.RealIP())
+			return c.String(400, "registry_auth requires a TLS connection")
+		}
+		credentialResolver, err := baseResolver.WithCredential(image.RegistryCredential{
+			Registry: req.RegistryAuth.Registry,
+			Token:    req.RegistryAuth.Token,
+			Username: req.RegistryAuth.Username,
+			Password: req.RegistryAuth.Password,
+		})
+		if err != nil {
+			return c.String(400, fmt.Sprintf("Invalid registry_auth: %v", err))
+		}
+		resolver = credentialResolver
+	}
 
 	logging.Logger.Info("Stack prepare request",
 		zap.String("user", user.Name),
 		zap.String("blueprint", req.Blueprint),
+		zap.Strings("blueprints", req.Blueprints),
+		zap.Bool("inline_compose", req.Compose != ""),
 		zap.String("commit", req.Commit),
 		zap.String("branch", req.Branch),
 		zap.String("tag", req.Tag),
-		zap.String("env", req.Env))
+		zap.String("env", req.Env),
+		zap.Bool("registry_auth", req.RegistryAuth != nil))
 
 	// Validate env exists
 	namespace := h.nsManager.GetDeveloperNamespace(user.Name)
@@ -108,32 +195,73 @@ func (h *Handler) PrepareStack(c echo.Context) error {
 		return c.String(404, fmt.Sprintf("Env '%s' not found", req.Env))
 	}
 
-	// Parse blueprint reference
-	blueprintNamespace, blueprintName, err := h.nsManager.ParseScopedID(req.Blueprint)
-	if err != nil {
-		logging.Logger.Error("Failed to parse blueprint reference",
-			zap.String("blueprint", req.Blueprint),
-			zap.Error(err))
-		return c.String(400, fmt.Sprintf("Invalid blueprint reference: %v", err))
-	}
+	// Load compose content from the blueprint or the inline request body
+	composeContent := req.Compose
+	if req.Blueprint != "" {
+		blueprintNamespace, blueprintName, err := h.nsManager.ParseScopedID(req.Blueprint)
+		if err != nil {
+			logging.Logger.Error("Failed to parse blueprint reference",
+				zap.String("blueprint", req.Blueprint),
+				zap.Error(err))
+			return c.String(400, fmt.Sprintf("Invalid blueprint reference: %v", err))
+		}
 
-	// Get blueprint from Kubernetes
-	blueprint, err := h.k8sClient.GetBlueprint(c.Request().Context(), blueprintNamespace, blueprintName)
-	if err != nil {
-		logging.Logger.Error("Failed to get blueprint",
-			zap.String("blueprint", req.Blueprint),
-			zap.Error(err))
-		return c.String(404, "Blueprint not found")
+		blueprint, err := h.k8sClient.GetBlueprint(c.Request().Context(), blueprintNamespace, blueprintName)
+		if err != nil {
+			logging.Logger.Error("Failed to get blueprint",
+				zap.String("blueprint", req.Blueprint),
+				zap.Error(err))
+			return c.String(404, "Blueprint not found")
+		}
+		composeContent = blueprint.Spec.DockerCompose
+	}
+	// Several blueprints are merged into one compose, resolved and cached like inline compose
+	mergedCompose := ""
+	if len(req.Blueprints) > 0 {
+		files := make([]compose.ComposeFile, 0, len(req.Blueprints))
+		for _, reference := range req.Blueprints {
+			blueprintNamespace, blueprintName, err := h.nsManager.ParseScopedID(reference)
+			if err != nil {
+				return c.String(400, fmt.Sprintf("Invalid blueprint reference %s: %v", reference, err))
+			}
+			blueprint, err := h.k8sClient.GetBlueprint(c.Request().Context(), blueprintNamespace, blueprintName)
+			if err != nil {
+				logging.Logger.Error("Failed to get blueprint",
+					zap.String("blueprint", reference),
+					zap.Error(err))
+				return c.String(404, fmt.Sprintf("Blueprint '%s' not found", reference))
+			}
+			files = append(files, compose.ComposeFile{Name: reference, Content: blueprint.Spec.DockerCompose})
+		}
+		mergedCompose, err = compose.MergeComposeFiles(files)
+		if err != nil {
+			return c.String(400, fmt.Sprintf("Cannot merge blueprints: %v", err))
+		}
+		composeContent = mergedCompose
 	}
 
 	// Parse Docker Compose content
-	project, err := h.parseDockerCompose(blueprint.Spec.DockerCompose)
+	project, err := ParseDockerCompose(composeContent)
 	if err != nil {
 		logging.Logger.Error("Failed to parse Docker Compose",
 			zap.String("blueprint", req.Blueprint),
+			zap.Bool("inline", req.Compose != ""),
 			zap.Error(err))
+		if req.Compose != "" {
+			return c.String(400, fmt.Sprintf("Invalid docker-compose content: %v", err))
+		}
 		return c.String(400, "Invalid Docker Compose content")
 	}
+	if req.Compose != "" && len(project.Services) == 0 {
+		return c.String(400, "Invalid docker-compose content: no services defined")
+	}
+	if rejected, err := common.RejectPolicyViolations(c, project, h.deniedFeatures); rejected {
+		return err
+	}
+	labelWarnings, rejected, err := EnforceLabelPolicy(c, user.Role, h.roles, project)
+	if rejected {
+		return err
+	}
 
 	// Extract x-lissto configuration from compose file
 	lisstoConfig := compose.ExtractLisstoConfig(project)
@@ -143,35 +271,62 @@ func (h *Handler) PrepareStack(c echo.Context) error {
 		zap.String("repositoryPrefix", lisstoConfig.RepositoryPrefix))
 
 	// Create expose preprocessor for checking exposed services and calculating URLs
-	var internalConfig *preprocessor.IngressConfig
-	if h.config.Stacks.Ingress.Internal != nil {
-		internalConfig = &preprocessor.IngressConfig{
-			IngressClass: h.config.Stacks.Ingress.Internal.IngressClass,
-			HostSuffix:   h.config.Stacks.Ingress.Internal.HostSuffix,
-			TLSSecret:    h.config.Stacks.Ingress.Internal.TLSSecret,
-		}
+	exposePreprocessor, err := NewExposePreprocessor(h.config, h.ingress).WithDefaultVisibility(lisstoConfig.ExposeDefault)
+	if err != nil {
+		return c.String(400, err.Error())
 	}
-	var internetConfig *preprocessor.IngressConfig
-	if h.config.Stacks.Ingress.Internet != nil {
-		internetConfig = &preprocessor.IngressConfig{
-			IngressClass: h.config.Stacks.Ingress.Internet.IngressClass,
-			HostSuffix:   h.config.Stacks.Ingress.Internet.HostSuffix,
-			TLSSecret:    h.config.Stacks.Ingress.Internet.TLSSecret,
-		}
+	hostSuffix, err := HostOverrideSuffix(user.Role, req.HostOverrideSuffix, h.ingress, h.roles)
+	if errors.Is(err, ErrHostSuffixForbidden) {
+		logging.LogDeniedWithIP("host_suffix_override", user.Name, "POST /prepare", c.RealIP())
+		return c.String(403, err.Error())
+	} else if err != nil {
+		return c.String(400, err.Error())
+	}
+	exposePreprocessor = exposePreprocessor.WithHostSuffix(hostSuffix)
+
+	// Optional point-in-time resolution (zero keeps the normal candidate order)
+	var resolvedBefore time.Time
+	if req.ResolvedBefore != nil {
+		resolvedBefore = *req.ResolvedBefore
 	}
-	exposePreprocessor := preprocessor.NewExposePreprocessor(internalConfig, internetConfig)
 
 	// Resolve images for each service
 	var results []common.DetailedImageResolutionInfo
 	var exposedServices []common.ExposedServiceInfo
 
+	// An identical recent prepare (also by another user) already resolved the images; request
+	// credentials may see other images and stripped labels change resolution, so those prepares
+	// neither reuse nor share results
+	servicesToResolve := project.Services
+	sharedKey := ""
+	if req.RegistryAuth == nil && len(labelWarnings) == 0 {
+		sharedKey = sharedResultKey(composeContent, &req, resolvedBefore, hostSuffix)
+	}
+	// A cache override asks for fresh resolution, so it skips shared results too (and shares its own)
+	var shared []common.DetailedImageResolutionInfo
+	reused := false
+	if cacheOverride == (image.CacheOverride{}) {
+		shared, reused = h.sharedResults(c.Request().Context(), sharedKey)
+	}
+	if reused {
+		logging.Logger.Info("Reusing shared prepare result",
+			zap.String("env", req.Env),
+			zap.Int("services", len(shared)))
+		results, servicesToResolve = shared, nil
+		for _, info := range results {
+			if info.Exposed {
+				exposedServices = append(exposedServices, common.ExposedServiceInfo{Service: info.Service, URL: info.URL})
+			}
+		}
+	}
+
 	logging.Logger.Info("Starting image resolution for services",
 		zap.Int("total_services", len(project.Services)),
 		zap.Strings("service_names", getServiceNames(project.Services)),
 		zap.Bool("detailed", req.Detailed),
 		zap.String("env", req.Env))
 
-	for serviceName, service := range project.Services {
+	for serviceName, service := range servicesToResolve {
 		logging.Logger.Info("Processing service for image resolution",
 			zap.String("service", serviceName),
 			zap.String("has_image", fmt.Sprintf("%t", service.Image != "")),
@@ -179,153 +334,27 @@ func (h *Handler) PrepareStack(c echo.Context) error {
 			zap.String("image", service.Image),
 			zap.Any("labels", service.Labels))
 
-		// Always collect detailed information
-		var info common.DetailedImageResolutionInfo
-		info.Service = serviceName
-
-		// PRIORITY: Check for lissto.dev/image override label first
-		imageOverride := ""
-		if service.Labels != nil {
-			if override, ok := service.Labels["lissto.dev/image"]; ok && override != "" {
-				imageOverride = override
-			}
+		info, err := ResolveExposedServiceImage(resolver, exposePreprocessor, serviceName, service, lisstoConfig, req.Env, ResolveOptions{
+			Commit:         req.Commit,
+			Branch:         req.Branch,
+			Detailed:       req.Detailed,
+			AllowPending:   req.AllowPending,
+			ResolvedBefore: resolvedBefore,
+
+			UnpinnedFallback: h.unpinnedFallback,
+			Verifier:         h.signatureVerifier,
+		})
+		if errors.Is(err, image.ErrRegistryUnavailable) {
+			return c.String(503, err.Error())
 		}
-
-		// If service has image override label, use it with highest priority
-		if imageOverride != "" {
-			logging.Logger.Info("Using image override from label",
-				zap.String("service", serviceName),
-				zap.String("override_image", imageOverride))
-
-			// Use service context for platform-specific resolution and caching
-			imageWithDigest, err := h.imageResolver.GetImageDigestWithServicePlatform(imageOverride, service)
-			if err != nil {
-				logging.Logger.Error("Failed to get image digest for override",
-					zap.String("service", serviceName),
-					zap.String("override_image", imageOverride),
-					zap.Error(err))
-
-				// In detailed mode, continue processing and show the error
-				if req.Detailed {
-					info.Image = imageOverride // Keep override image even on error
-					info.Method = "override"
-					info.Candidates = []common.ImageCandidate{{
-						ImageURL: imageOverride,
-						Tag:      "override",
-						Source:   "override",
-						Success:  false,
-						Error:    err.Error(),
-					}}
-				} else {
-					return c.String(400, fmt.Sprintf("Failed to resolve override image for service %s: %v", serviceName, err))
-				}
-			} else {
-				info.Digest = imageWithDigest // Full digest (e.g., nginx@sha256:...)
-				info.Image = imageOverride    // User-friendly tag (e.g., nginx:alpine)
-				info.Method = "override"
-				info.Candidates = []common.ImageCandidate{{
-					ImageURL: imageOverride,
-					Tag:      "override",
-					Source:   "override",
-					Success:  true,
-					Digest:   imageWithDigest,
-				}}
-			}
-		} else if service.Image != "" {
-			// If service has image, resolve to digest
-			logging.Logger.Info("Service has explicit image, resolving to digest",
-				zap.String("service", serviceName),
-				zap.String("image", service.Image))
-
-			// Use service context for platform-specific resolution and caching
-			imageWithDigest, err := h.imageResolver.GetImageDigestWithServicePlatform(service.Image, service)
-			if err != nil {
-				logging.Logger.Error("Failed to get image digest",
-					zap.String("service", serviceName),
-					zap.String("image", service.Image),
-					zap.Error(err))
-
-				// In detailed mode, continue processing and show the error
-				if req.Detailed {
-					info.Image = service.Image // Keep original image even on error
-					info.Method = "original"
-					info.Candidates = []common.ImageCandidate{{
-						ImageURL: service.Image,
-						Tag:      "original",
-						Source:   "original",
-						Success:  false,
-						Error:    err.Error(),
-					}}
-				} else {
-					return c.String(400, fmt.Sprintf("Failed to resolve image for service %s: %v", serviceName, err))
-				}
-			} else {
-				info.Digest = imageWithDigest // Full digest (e.g., nginx@sha256:...)
-				info.Image = service.Image    // User-friendly tag (e.g., nginx:alpine)
-				info.Method = "original"
-				info.Candidates = []common.ImageCandidate{{
-					ImageURL: service.Image,
-					Tag:      "original",
-					Source:   "original",
-					Success:  true,
-					Digest:   imageWithDigest,
-				}}
-			}
-		} else {
-			// Service has build or needs resolution - try candidates
-			logging.Logger.Info("Service needs image resolution, trying candidates",
-				zap.String("service", serviceName),
-				zap.String("commit", req.Commit),
-				zap.String("branch", req.Branch))
-
-			result, err := h.imageResolver.ResolveImageDetailed(
-				service,
-				image.ResolutionConfig{
-					Commit:            req.Commit,
-					Branch:            req.Branch,
-					ComposeRegistry:   lisstoConfig.Registry,
-					ComposeRepository: lisstoConfig.Repository,
-					ComposePrefix:     lisstoConfig.RepositoryPrefix,
-				},
-			)
-			if err != nil {
-				logging.Logger.Error("Failed to resolve image for service",
-					zap.String("service", serviceName),
-					zap.Error(err))
-
-				// Always use result data, even on error
-				info.Digest = result.FinalImage
-				info.Image = result.Selected
-				info.Method = result.Method
-				info.Registry = result.Registry
-				info.ImageName = result.ImageName
-				info.Candidates = result.Candidates
-
-				// In standard mode, return error immediately
-				if !req.Detailed {
-					return c.String(400, fmt.Sprintf("Failed to resolve image for service %s: %v", serviceName, err))
-				}
-			} else {
-				info.Digest = result.FinalImage
-				info.Image = result.Selected
-				info.Method = result.Method
-				info.Registry = result.Registry
-				info.ImageName = result.ImageName
-				info.Candidates = result.Candidates
-			}
+		if err != nil {
+			return c.String(400, err.Error())
 		}
-
-		// Check if service is exposed and calculate URL (env is now mandatory)
-		exposedURL := exposePreprocessor.GetExposedServiceURL(service, serviceName, req.Env)
-		if exposedURL != "" {
-			info.Exposed = true
-			info.URL = exposedURL
+		if info.Exposed {
 			exposedServices = append(exposedServices, common.ExposedServiceInfo{
 				Service: serviceName,
-				URL:     exposedURL,
+				URL:     info.URL,
 			})
-		} else {
-			info.Exposed = false
 		}
 
 		results = append(results, info)
@@ -337,28 +366,88 @@ func (h *Handler) PrepareStack(c echo.Context) error {
 			zap.String("method", info.Method),
 			zap.Bool("exposed", info.Exposed),
 			zap.String("url", info.URL),
+			zap.Bool("pending", info.Pending),
+			zap.Bool("unpinned", info.Unpinned),
 			zap.Int("candidates_tried", len(info.Candidates)))
 	}
 
+	if !reused {
+		h.shareResults(c.Request().Context(), sharedKey, results)
+	}
+
+	// Check that exposed services will get their TLS secret and the env has the referenced variables and secrets
+	warnings := append(labelWarnings, CheckTLSSecrets(c.Request().Context(), h.k8sClient, namespace, project.Services, exposePreprocessor)...)
+	warnings = append(warnings, CheckEnvReferences(c.Request().Context(), h.k8sClient, namespace, env.Name, compose.ExtractEnvReferences(project))...)
+	for _, warning := range warnings {
+		logging.Logger.Warn("Prepare validation warning",
+			zap.String("service", warning.Service),
+			zap.String("code", warning.Code),
+			zap.String("message", warning.Message))
+	}
+	if req.Strict && len(warnings) > 0 {
+		messages := make([]string, len(warnings))
+		for i, warning := range warnings {
+			messages[i] = warning.Message
+		}
+		return c.String(400, fmt.Sprintf("Validation failed: %s", strings.Join(messages, "; ")))
+	}
+
+	// Raise services below the env's resource floor, as stack creation will, and report it
+	warnings = append(warnings, ApplyResourceFloor(project, env.Name, h.resources)...)
+
+	// Optionally show the compose Kompose will receive, to debug surprising conversions
+	var normalizedCompose string
+	if c.QueryParam("showNormalized") == "true" {
+		normalizedCompose, err = NormalizeCompose(project, results, exposePreprocessor, req.Env)
+		if err != nil {
+			return c.String(400, err.Error())
+		}
+	}
+
 	// Generate request ID
 	requestID := uuid.New().String()
 
 	// Build cache entry with namespace for ownership verification
 	cacheEntry := &cache.PrepareResultCache{
-		Namespace: namespace,
-		Images:    make(map[string]cache.ImageInfoCache),
+		Namespace:  namespace,
+		Images:     make(map[string]cache.ImageInfoCache),
+		Compose:    req.Compose, // Create reads inline compose from here instead of a blueprint
+		HostSuffix: hostSuffix,
+	}
+	if mergedCompose != "" {
+		cacheEntry.Compose = mergedCompose
+		cacheEntry.Blueprints = req.Blueprints
 	}
 
 	for _, result := range results {
+		verified, signer := verifiedSignature(result)
 		cacheEntry.Images[result.Service] = cache.ImageInfoCache{
-			Digest: result.Digest, // Full digest
-			Image:  result.Image,  // User-friendly tag
-			URL:    result.URL,    // Exposed URL (if applicable)
+			Digest:   result.Digest, // Full digest
+			Image:    result.Image,  // User-friendly tag
+			URL:      result.URL,    // Exposed URL (if applicable)
+			Pending:  result.Pending,
+			Unpinned: result.Unpinned,
+			Verified: verified,
+			Signer:   signer,
 		}
 	}
 
+	// Cap the user's unexpired prepare results, evicting their oldest or rejecting per config
+	evicted, err := h.quota.Admit(user.Name, user.Role.String(), requestID)
+	if err != nil {
+		logging.LogDeniedWithIP("prepare_quota_exceeded", user.Name, "POST /stacks/prepare", c.RealIP())
+		return c.String(429, err.Error())
+	}
+	h.evictPrepareResults(c.Request().Context(), user.Name, evicted)
+
 	// Cache with 15 min TTL
-	if err := h.cache.Set(c.Request().Context(), requestID, cacheEntry, 15*time.Minute); err != nil {
+	if err := h.cache.Set(c.Request().Context(), requestID, cacheEntry, PrepareResultTTL); errors.Is(err, cache.ErrEntryTooLarge) {
+		// Return the result uncached; there is no request ID to create the stack from
+		logging.Logger.Warn("Prepare result too large to cache", zap.Error(err))
+		h.quota.Release(user.Name, requestID)
+		requestID = ""
+		warnings = append(warnings, CacheEntryTooLargeWarning(err))
+	} else if err != nil {
 		logging.Logger.Warn("Failed to cache prepare result", zap.Error(err))
 		// Continue anyway - cache is optional
 	} else {
@@ -371,10 +460,14 @@ func (h *Handler) PrepareStack(c echo.Context) error {
 	// Return appropriate response based on mode
 	if req.Detailed {
 		response := common.DetailedPrepareStackResponse{
-			RequestID: requestID,
-			Blueprint: req.Blueprint,
-			Images:    results,
-			Exposed:   exposedServices,
+			RequestID:  requestID,
+			Blueprint:  req.Blueprint,
+			Blueprints: req.Blueprints,
+			Images:     results,
+			Exposed:    exposedServices,
+			Warnings:   warnings,
+
+			NormalizedCompose: normalizedCompose,
 		}
 
 		return c.JSON(200, response)
@@ -383,24 +476,30 @@ func (h *Handler) PrepareStack(c echo.Context) error {
 		images := make([]common.ImageResolutionInfo, len(results))
 		for i, result := range results {
 			images[i] = common.ImageResolutionInfo{
-				Service: result.Service,
-				Image:   result.Digest,
-				Method:  result.Method,
-				Tag:     result.Image,
+				Service:  result.Service,
+				Image:    result.Digest,
+				Method:   result.Method,
+				Tag:      result.Image,
+				Pending:  result.Pending,
+				Unpinned: result.Unpinned,
 			}
 		}
 
 		response := common.PrepareStackResponse{
-			Blueprint: req.Blueprint,
-			Images:    images,
+			Blueprint:  req.Blueprint,
+			Blueprints: req.Blueprints,
+			Images:     images,
+			Warnings:   warnings,
+
+			NormalizedCompose: normalizedCompose,
 		}
 
 		return c.JSON(200, response)
 	}
 }
 
-// parseDockerCompose parses Docker Compose content into a project
-func (h *Handler) parseDockerCompose(composeContent string) (*types.Project, error) {
+// ParseDockerCompose parses Docker Compose content into a project
+func ParseDockerCompose(composeContent string) (*types.Project, error) {
 	project, err := loader.LoadWithContext(
 		context.Background(),
 		types.ConfigDetails{
@@ -413,11 +512,22 @@ func (h *Handler) parseDockerCompose(composeContent string) (*types.Project, err
 			WorkingDir: "/tmp",
 		},
 		loader.WithSkipValidation,
+		compose.WithDeferredEnvFiles,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse Docker Compose content: %w", err)
 	}
 
+	// Merge env_file values bundled in x-lissto.envFiles
+	if err := compose.ResolveEnvFiles(project); err != nil {
+		return nil, fmt.Errorf("failed to parse Docker Compose content: %w", err)
+	}
+
+	// Apply x-lissto.defaults to services that don't set their own labels/settings
+	if err := compose.ApplyServiceDefaults(project); err != nil {
+		return nil, fmt.Errorf("failed to parse Docker Compose content: %w", err)
+	}
+
 	if project.Name == "" {
 		project.Name = "stack"
 	}
@@ -6,12 +6,14 @@ import (
 
 	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/lissto-dev/api/pkg/image"
+	"github.com/lissto-dev/api/pkg/logging"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/stretchr/testify/mock"
 )
 
 func TestPrepare(t *testing.T) {
+	_ = logging.InitLogger("info", "console")
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Prepare Handler Suite")
 }
@@ -0,0 +1,34 @@
+package prepare
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/preprocessor"
+)
+
+// ErrHostSuffixForbidden is returned when the caller's role may not override the host suffix
+var ErrHostSuffixForbidden = errors.New("host_override_suffix is not allowed for this role")
+
+// HostOverrideSuffix validates a requested host_override_suffix for the caller's role and returns it
+// normalized; empty keeps the configured host suffixes
+// Admins may always override, other roles need roles.<role>.hostOverride
+func HostOverrideSuffix(
+	role authz.Role,
+	suffix string,
+	ingress config.IngressSettings,
+	roles map[string]config.RoleSettings,
+) (string, error) {
+	if suffix == "" {
+		return "", nil
+	}
+	if role != authz.Admin && !roles[role.String()].HostOverride {
+		return "", ErrHostSuffixForbidden
+	}
+	if len(ingress.PreviewDomains) == 0 {
+		return "", fmt.Errorf("host_override_suffix is not enabled on this server")
+	}
+	return preprocessor.NormalizeHostSuffix(suffix, ingress.PreviewDomains)
+}
@@ -0,0 +1,141 @@
+package prepare_test
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/k8s"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+type testValidator struct {
+	validator *validator.Validate
+}
+
+func (v *testValidator) Validate(i interface{}) error {
+	return v.validator.Struct(i)
+}
+
+var _ = Describe("Prepare with inline compose", func() {
+	var h *prepare.Handler
+
+	BeforeEach(func() {
+		Expect(logging.InitLogger("info", "console")).To(Succeed())
+
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		env := &envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(env).Build()
+
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		nsManager := authz.NewNamespaceManager(cfg)
+		h = prepare.NewHandler(k8s.NewClientFromClient(fakeClient, scheme), authz.NewAuthorizer(nsManager), nsManager,
+			cfg, config.DefaultSettings(), cache.NewMemoryCache())
+	})
+
+	prepareStack := func(body string) *httptest.ResponseRecorder {
+		e := echo.New()
+		e.Validator = &testValidator{validator: validator.New()}
+		req := httptest.NewRequest(http.MethodPost, "/stacks/prepare", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user", &middleware.User{Name: "alice", Role: authz.User})
+		Expect(h.PrepareStack(c)).To(Succeed())
+		return rec
+	}
+
+	It("should reject requests with both blueprint and compose", func() {
+		rec := prepareStack(`{"blueprint":"alice/web","compose":"services: {}","env":"dev"}`)
+
+		Expect(rec.Code).To(Equal(400))
+		Expect(rec.Body.String()).To(Equal("Exactly one of blueprint, blueprints or compose is required"))
+	})
+
+	It("should reject requests with both blueprints and compose", func() {
+		rec := prepareStack(`{"blueprints":["alice/postgres","alice/web"],"compose":"services: {}","env":"dev"}`)
+
+		Expect(rec.Code).To(Equal(400))
+		Expect(rec.Body.String()).To(Equal("Exactly one of blueprint, blueprints or compose is required"))
+	})
+
+	It("should reject requests with neither blueprint nor compose", func() {
+		rec := prepareStack(`{"env":"dev"}`)
+
+		Expect(rec.Code).To(Equal(400))
+		Expect(rec.Body.String()).To(Equal("Exactly one of blueprint, blueprints or compose is required"))
+	})
+
+	It("should reject malformed inline compose", func() {
+		rec := prepareStack(`{"compose":"services: [unclosed","env":"dev"}`)
+
+		Expect(rec.Code).To(Equal(400))
+		Expect(rec.Body.String()).To(HavePrefix("Invalid docker-compose content:"))
+	})
+
+	It("should reject inline compose without services", func() {
+		rec := prepareStack(`{"compose":"x-lissto:\n  title: empty\n","env":"dev"}`)
+
+		Expect(rec.Code).To(Equal(400))
+		Expect(rec.Body.String()).To(Equal("Invalid docker-compose content: no services defined"))
+	})
+
+	Describe("registry_auth", func() {
+		const compose = `{"compose":"services:\n  web:\n    image: ghcr.io/acme/web:1.0\n","env":"dev",` +
+			`"registry_auth":%s}`
+
+		prepareStackTLS := func(body string) *httptest.ResponseRecorder {
+			e := echo.New()
+			e.Validator = &testValidator{validator: validator.New()}
+			req := httptest.NewRequest(http.MethodPost, "/stacks/prepare", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.TLS = &tls.ConnectionState{}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", &middleware.User{Name: "alice", Role: authz.User})
+			Expect(h.PrepareStack(c)).To(Succeed())
+			return rec
+		}
+
+		It("should reject a credential over plain HTTP", func() {
+			rec := prepareStack(fmt.Sprintf(compose, `{"registry":"ghcr.io","token":"short-lived"}`))
+
+			Expect(rec.Code).To(Equal(400))
+			Expect(rec.Body.String()).To(Equal("registry_auth requires a TLS connection"))
+			Expect(rec.Body.String()).NotTo(ContainSubstring("short-lived"))
+		})
+
+		It("should reject an incomplete credential", func() {
+			rec := prepareStackTLS(fmt.Sprintf(compose, `{"registry":"ghcr.io","username":"ci"}`))
+
+			Expect(rec.Code).To(Equal(400))
+			Expect(rec.Body.String()).To(HavePrefix("Invalid registry_auth:"))
+		})
+
+		It("should require the registry", func() {
+			rec := prepareStackTLS(fmt.Sprintf(compose, `{"token":"short-lived"}`))
+
+			Expect(rec.Code).To(Equal(400))
+			Expect(rec.Body.String()).To(ContainSubstring("Registry"))
+		})
+	})
+})
@@ -0,0 +1,69 @@
+package prepare
+
+import (
+	"fmt"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// WarningLabelStripped reports a lissto label removed from a service by the role's label policy
+const WarningLabelStripped = "label_stripped"
+
+// EnforceLabelPolicy checks the project's lissto labels against roles.<role>, admins bypass it
+// Disallowed labels are rejected with a 400 listing them, or removed from the project and returned as
+// warnings when the role strips them. It reports whether a response was written; the caller then returns the error as is
+func EnforceLabelPolicy(
+	c echo.Context,
+	role authz.Role,
+	roles map[string]config.RoleSettings,
+	project *types.Project,
+) ([]common.PrepareWarning, bool, error) {
+	if role == authz.Admin {
+		return nil, false, nil
+	}
+	policy := roles[role.String()]
+	violations := compose.CheckLabelPolicy(project, policy.AllowedLabels, policy.DeniedLabels)
+	if len(violations) == 0 {
+		return nil, false, nil
+	}
+
+	labels := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		labels = append(labels, violation.Service+":"+violation.Label)
+	}
+
+	if policy.StripsDisallowedLabels() {
+		logging.Logger.Info("Stripped labels disallowed for role",
+			zap.String("path", c.Path()),
+			zap.String("role", role.String()),
+			zap.Strings("labels", labels))
+		compose.StripLabels(project, violations)
+		warnings := make([]common.PrepareWarning, 0, len(violations))
+		for _, violation := range violations {
+			warnings = append(warnings, common.PrepareWarning{
+				Service: violation.Service,
+				Code:    WarningLabelStripped,
+				Message: fmt.Sprintf("service %s: label %s is not allowed for role %s and was removed",
+					violation.Service, violation.Label, role.String()),
+			})
+		}
+		return warnings, false, nil
+	}
+
+	logging.Logger.Info("Rejected compose using disallowed labels",
+		zap.String("path", c.Path()),
+		zap.String("role", role.String()),
+		zap.Strings("labels", labels))
+	return nil, true, c.JSON(400, common.LabelPolicyResponse{
+		Error:  "Compose uses labels not allowed for your role",
+		Labels: violations,
+	})
+}
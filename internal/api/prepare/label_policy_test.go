@@ -0,0 +1,109 @@
+package prepare_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/k8s"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+var _ = Describe("Prepare with a label policy", func() {
+	const body = `{"compose":"services:\n  web:\n    image: nginx:1.27\n    labels:\n` +
+		`      lissto.dev/image: registry.acme.io/web:1.0\n      lissto.dev/expose: internal\n","env":"dev","detailed":true}`
+
+	var (
+		settings *config.Settings
+		handler  func() *prepare.Handler
+	)
+
+	BeforeEach(func() {
+		Expect(logging.InitLogger("info", "console")).To(Succeed())
+
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}},
+			&envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-root"}},
+		).Build()
+
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		nsManager := authz.NewNamespaceManager(cfg)
+		settings = config.DefaultSettings()
+		settings.Roles = map[string]config.RoleSettings{
+			"user": {DeniedLabels: []string{"lissto.dev/image"}},
+		}
+		handler = func() *prepare.Handler {
+			h := prepare.NewHandler(k8s.NewClientFromClient(fakeClient, scheme), authz.NewAuthorizer(nsManager), nsManager,
+				cfg, settings, cache.NewMemoryCache())
+			h.SetImageChecker(&countingChecker{})
+			return h
+		}
+	})
+
+	prepareAs := func(user *middleware.User) *httptest.ResponseRecorder {
+		e := echo.New()
+		e.Validator = &testValidator{validator: validator.New()}
+		req := httptest.NewRequest(http.MethodPost, "/stacks/prepare", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user", user)
+		Expect(handler().PrepareStack(c)).To(Succeed())
+		return rec
+	}
+
+	It("should reject a developer using a restricted label", func() {
+		rec := prepareAs(&middleware.User{Name: "alice", Role: authz.User})
+
+		Expect(rec.Code).To(Equal(400))
+		var resp common.LabelPolicyResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.Labels).To(Equal([]compose.LabelViolation{{Service: "web", Label: "lissto.dev/image"}}))
+	})
+
+	It("should let admins use any label", func() {
+		rec := prepareAs(&middleware.User{Name: "root", Role: authz.Admin})
+
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+		var resp common.DetailedPrepareStackResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.Images).To(HaveLen(1))
+		Expect(resp.Images[0].Image).To(HavePrefix("registry.acme.io/web"))
+	})
+
+	It("should strip the label with a warning when the role strips", func() {
+		settings.Roles["user"] = config.RoleSettings{
+			DeniedLabels:      []string{"lissto.dev/image"},
+			OnDisallowedLabel: config.LabelPolicyStrip,
+		}
+		rec := prepareAs(&middleware.User{Name: "alice", Role: authz.User})
+
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+		var resp common.DetailedPrepareStackResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.Images).To(HaveLen(1))
+		Expect(resp.Images[0].Image).NotTo(ContainSubstring("registry.acme.io"))
+		Expect(resp.Warnings).To(ContainElement(HaveField("Code", prepare.WarningLabelStripped)))
+	})
+})
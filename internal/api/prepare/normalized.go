@@ -0,0 +1,49 @@
+package prepare
+
+import (
+	"fmt"
+
+	"github.com/compose-spec/compose-go/v2/types"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/pkg/preprocessor"
+	"github.com/lissto-dev/api/pkg/serializer"
+)
+
+// NormalizedStackName stands in for the stack name, generated at stack creation, in normalized compose
+const NormalizedStackName = "<stack>"
+
+// NormalizeCompose returns the compose YAML that Kompose receives for a prepared project
+// Parsing already applied profiles, variables, env files and x-lissto defaults; services get their
+// resolved images (the expected tag while pending) and exposure is preprocessed for the env.
+func NormalizeCompose(
+	project *types.Project,
+	results []common.DetailedImageResolutionInfo,
+	exposePreprocessor *preprocessor.ExposePreprocessor,
+	env string,
+) (string, error) {
+	services := make(types.Services, len(project.Services))
+	for name, service := range project.Services {
+		services[name] = service
+	}
+	for _, result := range results {
+		service, ok := services[result.Service]
+		if !ok {
+			continue
+		}
+		if result.Pending {
+			service.Image = result.Image
+		} else if result.Digest != "" {
+			service.Image = result.Digest
+		}
+		services[result.Service] = service
+	}
+
+	processed, err := exposePreprocessor.ProcessServices(services, env, NormalizedStackName)
+	if err != nil {
+		return "", fmt.Errorf("service exposure configuration error: %w", err)
+	}
+	normalized := *project
+	normalized.Services = processed
+	return serializer.NewComposeSerializer().Serialize(&normalized)
+}
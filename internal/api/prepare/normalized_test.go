@@ -0,0 +1,100 @@
+package prepare_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/k8s"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+var _ = Describe("Prepare with the normalized compose", func() {
+	const composeContent = `
+services:
+  web:
+    image: registry.acme.io/web:${WEB_TAG:-1.4}
+    environment:
+      GREETING: ${GREETING:-hello}
+  debug:
+    image: busybox
+    profiles: [debug]
+`
+
+	var h *prepare.Handler
+
+	BeforeEach(func() {
+		Expect(logging.InitLogger("info", "console")).To(Succeed())
+
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		env := &envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(env).Build()
+
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		nsManager := authz.NewNamespaceManager(cfg)
+		h = prepare.NewHandler(k8s.NewClientFromClient(fakeClient, scheme), authz.NewAuthorizer(nsManager), nsManager,
+			cfg, config.DefaultSettings(), cache.NewMemoryCache())
+		h.SetImageChecker(&countingChecker{})
+	})
+
+	prepareStack := func(target string) common.PrepareStackResponse {
+		body, err := json.Marshal(map[string]string{"compose": composeContent, "env": "dev"})
+		Expect(err).NotTo(HaveOccurred())
+
+		e := echo.New()
+		e.Validator = &testValidator{validator: validator.New()}
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(string(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user", &middleware.User{Name: "alice", Role: authz.User})
+		Expect(h.PrepareStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+
+		var response common.PrepareStackResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+		return response
+	}
+
+	It("should return the compose with profiles applied, variables resolved and images pinned", func() {
+		response := prepareStack("/stacks/prepare?showNormalized=true")
+
+		var normalized struct {
+			Services map[string]struct {
+				Image       string             `yaml:"image"`
+				Environment map[string]*string `yaml:"environment"`
+			} `yaml:"services"`
+		}
+		Expect(yaml.Unmarshal([]byte(response.NormalizedCompose), &normalized)).To(Succeed())
+		Expect(normalized.Services).To(HaveLen(1))
+		Expect(normalized.Services).NotTo(HaveKey("debug"))
+
+		web := normalized.Services["web"]
+		Expect(web.Image).To(HavePrefix("registry.acme.io/web@sha256:"))
+		Expect(web.Environment).To(HaveKeyWithValue("GREETING", HaveValue(Equal("hello"))))
+	})
+
+	It("should leave the normalized compose out unless asked", func() {
+		Expect(prepareStack("/stacks/prepare").NormalizedCompose).To(BeEmpty())
+	})
+})
@@ -0,0 +1,81 @@
+package prepare_test
+
+import (
+	"errors"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+var _ = Describe("Digest-pinned compose images", func() {
+	const pinned = "nginx@sha256:1111111111111111111111111111111111111111111111111111111111111111"
+
+	var (
+		resolver *mockImageResolver
+		service  types.ServiceConfig
+	)
+
+	BeforeEach(func() {
+		service = types.ServiceConfig{Name: "web", Image: pinned}
+		resolver = new(mockImageResolver)
+	})
+
+	It("should verify the image and deploy it unchanged", func() {
+		// The registry answers with the platform digest of the pinned manifest list
+		resolver.On("GetImageDigestWithServicePlatform", pinned, mock.AnythingOfType("types.ServiceConfig")).
+			Return("nginx@sha256:2222222222222222222222222222222222222222222222222222222222222222", nil)
+
+		info, err := prepare.ResolveServiceImage(resolver, "web", service, &compose.LisstoConfig{}, prepare.ResolveOptions{
+			Commit: "abc123",
+			Branch: "main",
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Digest).To(Equal(pinned))
+		Expect(info.Image).To(Equal(pinned))
+		Expect(info.Method).To(Equal(image.MethodPinned))
+		Expect(info.Candidates).To(HaveLen(1))
+		Expect(info.Candidates[0].Success).To(BeTrue())
+		resolver.AssertNotCalled(GinkgoT(), "ResolveImageDetailed", mock.Anything, mock.Anything)
+	})
+
+	It("should fail when the pinned image does not exist", func() {
+		resolver.On("GetImageDigestWithServicePlatform", pinned, mock.AnythingOfType("types.ServiceConfig")).
+			Return("", errors.New("image not found"))
+
+		_, err := prepare.ResolveServiceImage(resolver, "web", service, &compose.LisstoConfig{}, prepare.ResolveOptions{
+			UnpinnedFallback: true,
+		})
+
+		Expect(err).To(MatchError(ContainSubstring("pinned image")))
+	})
+
+	It("should record the failure in detailed mode", func() {
+		resolver.On("GetImageDigestWithServicePlatform", pinned, mock.AnythingOfType("types.ServiceConfig")).
+			Return("", errors.New("image not found"))
+
+		info, err := prepare.ResolveServiceImage(resolver, "web", service, &compose.LisstoConfig{}, prepare.ResolveOptions{Detailed: true})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Digest).To(BeEmpty())
+		Expect(info.Candidates).To(HaveLen(1))
+		Expect(info.Candidates[0].Error).To(ContainSubstring("image not found"))
+	})
+
+	It("should plan the pinned image as written", func() {
+		rewriting := image.NewImageResolver("", "", &recordingChecker{})
+		Expect(rewriting.SetRewriteRules([]image.RewriteRule{{From: "docker.io/library/*", To: "mirror.acme.io/docker-hub/*"}}, true)).To(Succeed())
+
+		plan := prepare.PlanServiceImage(rewriting, "web", service, &compose.LisstoConfig{}, "", "main")
+
+		Expect(plan.Method).To(Equal(prepare.PlanMethodPinned))
+		Expect(plan.Candidates).To(HaveLen(1))
+		Expect(plan.Candidates[0].ImageURL).To(Equal(pinned))
+	})
+})
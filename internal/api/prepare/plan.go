@@ -0,0 +1,147 @@
+package prepare
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/image"
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// Plan methods reported for services that are not resolved from candidates
+const (
+	PlanMethodOverride   = "override"
+	PlanMethodOriginal   = "original"
+	PlanMethodPinned     = image.MethodPinned
+	PlanMethodCandidates = "candidates"
+)
+
+// ImagePlanner builds resolution plans without contacting registries
+type ImagePlanner interface {
+	PlanCandidates(service types.ServiceConfig, config image.ResolutionConfig) *image.ResolutionPlan
+}
+
+// PlanStack handles POST /prepare/plan
+// Returns the image candidates prepare would try for each service, without checking any of them
+func (h *Handler) PlanStack(c echo.Context) error {
+	var req common.PreparePlanRequest
+	user, _ := middleware.GetUserFromContext(c)
+
+	// Bind and validate
+	if err := c.Bind(&req); err != nil {
+		return c.String(400, "Invalid request")
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.String(400, err.Error())
+	}
+
+	// Parse blueprint reference
+	blueprintNamespace, blueprintName, err := h.nsManager.ParseScopedID(req.Blueprint)
+	if err != nil {
+		return c.String(400, fmt.Sprintf("Invalid blueprint reference: %v", err))
+	}
+
+	perm := h.authorizer.CanAccess(user.Role, authz.ActionRead, authz.ResourceBlueprint, blueprintNamespace, user.Name)
+	if !perm.Allowed {
+		logging.LogDeniedWithIP("insufficient_permissions", user.Name, "POST /prepare/plan", c.RealIP())
+		return c.String(403, fmt.Sprintf("Permission denied: %s", perm.Reason))
+	}
+
+	blueprint, err := h.k8sClient.GetBlueprint(c.Request().Context(), blueprintNamespace, blueprintName)
+	if err != nil {
+		logging.Logger.Error("Failed to get blueprint",
+			zap.String("blueprint", req.Blueprint),
+			zap.Error(err))
+		return c.String(404, "Blueprint not found")
+	}
+
+	project, err := ParseDockerCompose(blueprint.Spec.DockerCompose)
+	if err != nil {
+		logging.Logger.Error("Failed to parse Docker Compose",
+			zap.String("blueprint", req.Blueprint),
+			zap.Error(err))
+		return c.String(400, "Invalid Docker Compose content")
+	}
+
+	logging.Logger.Info("Stack prepare plan request",
+		zap.String("user", user.Name),
+		zap.String("blueprint", req.Blueprint),
+		zap.String("commit", req.Commit),
+		zap.String("branch", req.Branch))
+
+	return c.JSON(200, common.PreparePlanResponse{
+		Blueprint: req.Blueprint,
+		Services:  PlanStackImages(h.imageResolver, project, req.Commit, req.Branch),
+	})
+}
+
+// PlanStackImages plans the image resolution of every service in a project, sorted by service name
+func PlanStackImages(planner ImagePlanner, project *types.Project, commit, branch string) []common.ServiceImagePlan {
+	lisstoConfig := compose.ExtractLisstoConfig(project)
+
+	plans := make([]common.ServiceImagePlan, 0, len(project.Services))
+	for serviceName, service := range project.Services {
+		plans = append(plans, PlanServiceImage(planner, serviceName, service, lisstoConfig, commit, branch))
+	}
+	sort.Slice(plans, func(i, j int) bool { return plans[i].Service < plans[j].Service })
+	return plans
+}
+
+// PlanServiceImage describes how ResolveServiceImage would resolve a service
+// Priority: lissto.dev/image override label → digest-pinned image → explicit image → build candidates
+func PlanServiceImage(
+	planner ImagePlanner,
+	serviceName string,
+	service types.ServiceConfig,
+	lisstoConfig *compose.LisstoConfig,
+	commit, branch string,
+) common.ServiceImagePlan {
+	plan := common.ServiceImagePlan{Service: serviceName}
+
+	explicit, method := service.Labels["lissto.dev/image"], PlanMethodOverride
+	if explicit == "" {
+		explicit, method = service.Image, PlanMethodOriginal
+		if image.IsDigestPinned(explicit) {
+			method = PlanMethodPinned
+		}
+	}
+	if explicit != "" {
+		if rewriter, ok := planner.(ExplicitImageRewriter); ok && method != PlanMethodPinned {
+			explicit = rewriter.RewriteExplicitImage(explicit)
+		}
+		plan.Method = method
+		plan.Candidates = []common.PlannedCandidate{{ImageURL: explicit, Source: method}}
+		return plan
+	}
+
+	resolution := planner.PlanCandidates(service, image.ResolutionConfig{
+		Commit:              commit,
+		Branch:              branch,
+		ComposeRegistry:     lisstoConfig.Registry,
+		ComposeRepository:   lisstoConfig.Repository,
+		ComposePrefix:       lisstoConfig.RepositoryPrefix,
+		DefaultTag:          lisstoConfig.DefaultTag,
+		TagPrefixPerService: lisstoConfig.TagPrefixPerService,
+	})
+
+	plan.Method = PlanMethodCandidates
+	plan.Registry = resolution.Registry
+	plan.ImageName = resolution.ImageName
+	plan.Candidates = make([]common.PlannedCandidate, 0, len(resolution.Candidates))
+	for _, candidate := range resolution.Candidates {
+		plan.Candidates = append(plan.Candidates, common.PlannedCandidate{
+			ImageURL: candidate.ImageURL,
+			Tag:      candidate.Tag,
+			Source:   candidate.Source,
+		})
+	}
+	return plan
+}
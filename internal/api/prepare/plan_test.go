@@ -0,0 +1,122 @@
+package prepare_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+// recordingChecker records every image lookup and reports all images as missing
+type recordingChecker struct {
+	checked []string
+}
+
+func (r *recordingChecker) CheckImageExists(imageURL string) (*image.ImageMetadata, error) {
+	r.checked = append(r.checked, imageURL)
+	return &image.ImageMetadata{Exists: false}, errors.New("not found")
+}
+
+func (r *recordingChecker) CheckImageExistsForPlatform(imageURL, _, _ string) (*image.ImageMetadata, error) {
+	return r.CheckImageExists(imageURL)
+}
+
+var _ = Describe("Prepare plan", func() {
+	const composeContent = `
+x-lissto:
+  registry: registry.acme.io
+  repositoryPrefix: team/
+services:
+  api:
+    build: .
+    labels:
+      lissto.dev/tag: stable
+  db:
+    image: postgres:15
+  cache:
+    image: redis:7
+    labels:
+      lissto.dev/image: mirror.acme.io/redis:7
+`
+
+	var (
+		checker  *recordingChecker
+		resolver *image.ImageResolver
+	)
+
+	BeforeEach(func() {
+		checker = &recordingChecker{}
+		resolver = image.NewImageResolver("", "", checker)
+	})
+
+	It("should plan every service without contacting a registry", func() {
+		project, err := prepare.ParseDockerCompose(composeContent)
+		Expect(err).NotTo(HaveOccurred())
+
+		plans := prepare.PlanStackImages(resolver, project, "abc123", "main")
+
+		Expect(checker.checked).To(BeEmpty())
+		Expect(plans).To(HaveLen(3))
+
+		Expect(plans[0].Service).To(Equal("api"))
+		Expect(plans[0].Method).To(Equal(prepare.PlanMethodCandidates))
+		Expect(plans[0].Registry).To(Equal("registry.acme.io"))
+		Expect(plans[0].ImageName).To(Equal("team/api"))
+
+		Expect(plans[1].Service).To(Equal("cache"))
+		Expect(plans[1].Method).To(Equal(prepare.PlanMethodOverride))
+		Expect(plans[1].Candidates[0].ImageURL).To(Equal("mirror.acme.io/redis:7"))
+
+		Expect(plans[2].Service).To(Equal("db"))
+		Expect(plans[2].Method).To(Equal(prepare.PlanMethodOriginal))
+		Expect(plans[2].Candidates[0].ImageURL).To(Equal("postgres:15"))
+	})
+
+	It("should plan candidates in the order the resolver tries them", func() {
+		Expect(resolver.SetTagSources([]string{"branch", "label", "commit", "latest"})).To(Succeed())
+		project, err := prepare.ParseDockerCompose(composeContent)
+		Expect(err).NotTo(HaveOccurred())
+		lisstoConfig := compose.ExtractLisstoConfig(project)
+		service := project.Services["api"]
+
+		plan := prepare.PlanServiceImage(resolver, "api", service, lisstoConfig, "abc123", "main")
+
+		planned := make([]string, 0, len(plan.Candidates))
+		for _, candidate := range plan.Candidates {
+			planned = append(planned, candidate.ImageURL)
+		}
+		Expect(planned).To(Equal([]string{
+			"registry.acme.io/team/api:main",
+			"registry.acme.io/team/api:stable",
+			"registry.acme.io/team/api:abc123",
+			"registry.acme.io/team/api:latest",
+		}))
+
+		// Every candidate is missing, so resolution tries them all in its own order
+		_, err = resolver.ResolveImageDetailed(service, image.ResolutionConfig{
+			Commit:            "abc123",
+			Branch:            "main",
+			ComposeRegistry:   lisstoConfig.Registry,
+			ComposeRepository: lisstoConfig.Repository,
+			ComposePrefix:     lisstoConfig.RepositoryPrefix,
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(checker.checked).To(Equal(planned))
+	})
+
+	It("should apply registry rewrites to planned candidates", func() {
+		Expect(resolver.SetRewriteRules([]image.RewriteRule{
+			{From: "registry.acme.io/", To: "mirror.acme.io/acme/"},
+		}, false)).To(Succeed())
+		project, err := prepare.ParseDockerCompose(composeContent)
+		Expect(err).NotTo(HaveOccurred())
+
+		plan := prepare.PlanServiceImage(resolver, "api", project.Services["api"], compose.ExtractLisstoConfig(project), "", "main")
+
+		Expect(plan.Candidates[0].ImageURL).To(Equal("mirror.acme.io/acme/team/api:stable"))
+	})
+})
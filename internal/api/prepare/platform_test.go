@@ -0,0 +1,69 @@
+package prepare_test
+
+import (
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+// platformChecker serves one digest per platform; arm64 comes from a manifest list
+type platformChecker struct{}
+
+func (platformChecker) CheckImageExists(imageURL string) (*image.ImageMetadata, error) {
+	return platformChecker{}.CheckImageExistsForPlatform(imageURL, "linux", "amd64")
+}
+
+func (platformChecker) CheckImageExistsForPlatform(imageURL, os, arch string) (*image.ImageMetadata, error) {
+	return &image.ImageMetadata{
+		Exists:      true,
+		Digest:      "sha256:" + arch,
+		IsMultiArch: arch == "arm64",
+	}, nil
+}
+
+var _ = Describe("Resolved image platform", func() {
+	var resolver *image.ImageResolver
+
+	BeforeEach(func() {
+		resolver = image.NewImageResolver("registry.io", "", platformChecker{})
+	})
+
+	resolve := func(service types.ServiceConfig) (string, bool) {
+		info, err := prepare.ResolveServiceImage(resolver, service.Name, service, &compose.LisstoConfig{}, prepare.ResolveOptions{
+			Commit:   "abc123",
+			Detailed: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return info.Platform, info.MultiArch
+	}
+
+	It("should report the default platform for explicit images", func() {
+		platform, multiArch := resolve(types.ServiceConfig{Name: "db", Image: "postgres:16"})
+		Expect(platform).To(Equal("linux/amd64"))
+		Expect(multiArch).To(BeFalse())
+	})
+
+	It("should reflect a per-service lissto.dev/platform-arch override", func() {
+		platform, multiArch := resolve(types.ServiceConfig{
+			Name:   "db",
+			Image:  "postgres:16",
+			Labels: types.Labels{"lissto.dev/platform-arch": "arm64"},
+		})
+		Expect(platform).To(Equal("linux/arm64"))
+		Expect(multiArch).To(BeTrue())
+	})
+
+	It("should reflect the override for services resolved from candidates", func() {
+		platform, multiArch := resolve(types.ServiceConfig{
+			Name:   "api",
+			Build:  &types.BuildConfig{Context: "."},
+			Labels: types.Labels{"lissto.dev/platform-arch": "arm64"},
+		})
+		Expect(platform).To(Equal("linux/arm64"))
+		Expect(multiArch).To(BeTrue())
+	})
+})
@@ -0,0 +1,75 @@
+package prepare_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/k8s"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+var _ = Describe("Prepare with a compose feature policy", func() {
+	var h *prepare.Handler
+
+	BeforeEach(func() {
+		Expect(logging.InitLogger("info", "console")).To(Succeed())
+
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		env := &envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(env).Build()
+
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		nsManager := authz.NewNamespaceManager(cfg)
+		settings := config.DefaultSettings()
+		settings.Compose.DeniedFeatures = []string{compose.FeaturePrivileged, compose.FeatureHostNetwork}
+		h = prepare.NewHandler(k8s.NewClientFromClient(fakeClient, scheme), authz.NewAuthorizer(nsManager), nsManager,
+			cfg, settings, cache.NewMemoryCache())
+	})
+
+	It("should reject privileged and host network services with each violation", func() {
+		body, err := json.Marshal(map[string]string{
+			"env":     "dev",
+			"compose": "services:\n  agent:\n    image: datadog/agent\n    privileged: true\n    network_mode: host\n",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		e := echo.New()
+		e.Validator = &testValidator{validator: validator.New()}
+		req := httptest.NewRequest(http.MethodPost, "/stacks/prepare", strings.NewReader(string(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user", &middleware.User{Name: "alice", Role: authz.User})
+		Expect(h.PrepareStack(c)).To(Succeed())
+
+		Expect(rec.Code).To(Equal(400))
+		var resp common.ComposePolicyResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.Error).To(Equal("Compose uses denied features"))
+		Expect(resp.Violations).To(HaveLen(2))
+		Expect(resp.Violations[0].Service).To(Equal("agent"))
+		Expect(resp.Violations[0].Feature).To(Equal(compose.FeatureHostNetwork))
+		Expect(resp.Violations[1].Feature).To(Equal(compose.FeaturePrivileged))
+	})
+})
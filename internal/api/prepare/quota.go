@@ -0,0 +1,137 @@
+package prepare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// PrepareResultTTL is how long a prepare result stays cached for stack creation
+const PrepareResultTTL = 15 * time.Minute
+
+// ErrPrepareQuotaExceeded is returned when a user holds as many prepare results as allowed
+// and the quota rejects instead of evicting
+var ErrPrepareQuotaExceeded = errors.New("prepare result quota exceeded")
+
+// preparedResult is a cached prepare result in the per-user index
+type preparedResult struct {
+	requestID string
+	expiresAt time.Time
+}
+
+// PrepareQuota is the per-user index of unexpired prepare results, capping how many each user holds
+// The index lives in the API process; results are cached with PrepareResultTTL, so entries
+// are pruned by their expiry rather than by watching the cache.
+type PrepareQuota struct {
+	settings config.PrepareSettings
+
+	mu      sync.Mutex
+	results map[string][]preparedResult // User name to results, oldest first
+	now     func() time.Time
+}
+
+// NewPrepareQuota creates an empty per-user prepare index
+func NewPrepareQuota(settings config.PrepareSettings) *PrepareQuota {
+	return &PrepareQuota{
+		settings: settings,
+		results:  make(map[string][]preparedResult),
+		now:      time.Now,
+	}
+}
+
+// Admit records new prepare results of a user, returning the request IDs evicted to make room
+// The evicted IDs are no longer indexed and must be deleted from the cache by the caller.
+// In reject mode, or when the new results alone exceed the cap, ErrPrepareQuotaExceeded is
+// returned and nothing is recorded.
+func (q *PrepareQuota) Admit(user, role string, requestIDs ...string) ([]string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	results := q.unexpired(user, now)
+	limit := q.settings.ResultLimit(role)
+
+	var evicted []string
+	if excess := len(results) + len(requestIDs) - limit; limit > 0 && excess > 0 {
+		if q.settings.RejectsOnLimit() || len(requestIDs) > limit {
+			return nil, fmt.Errorf("%w: %d of %d prepare results in use, create or let them expire (%s)",
+				ErrPrepareQuotaExceeded, len(results), limit, PrepareResultTTL)
+		}
+		for _, result := range results[:excess] {
+			evicted = append(evicted, result.requestID)
+		}
+		results = append([]preparedResult(nil), results[excess:]...)
+	}
+
+	for _, requestID := range requestIDs {
+		results = append(results, preparedResult{requestID: requestID, expiresAt: now.Add(PrepareResultTTL)})
+	}
+	q.results[user] = results
+	return evicted, nil
+}
+
+// Release removes a result that was admitted but not cached
+func (q *PrepareQuota) Release(user, requestID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	results := q.results[user]
+	for i, result := range results {
+		if result.requestID == requestID {
+			q.results[user] = append(results[:i:i], results[i+1:]...)
+			break
+		}
+	}
+	if len(q.results[user]) == 0 {
+		delete(q.results, user)
+	}
+}
+
+// Count returns how many unexpired prepare results a user holds
+func (q *PrepareQuota) Count(user string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.unexpired(user, q.now()))
+}
+
+// unexpired prunes the user's expired results and returns the rest; the caller holds the lock
+func (q *PrepareQuota) unexpired(user string, now time.Time) []preparedResult {
+	results := q.results[user]
+	kept := results[:0]
+	for _, result := range results {
+		if now.Before(result.expiresAt) {
+			kept = append(kept, result)
+		}
+	}
+	if len(kept) == 0 {
+		delete(q.results, user)
+		return nil
+	}
+	q.results[user] = kept
+	return kept
+}
+
+// evictPrepareResults deletes prepare results the quota evicted from the cache
+// Their request IDs can no longer create a stack
+func (h *Handler) evictPrepareResults(ctx context.Context, user string, requestIDs []string) {
+	for _, requestID := range requestIDs {
+		if err := h.cache.Delete(ctx, requestID); err != nil {
+			logging.Logger.Warn("Failed to evict prepare result",
+				zap.String("user", user),
+				zap.String("request_id", requestID),
+				zap.Error(err))
+			continue
+		}
+		logging.Logger.Info("Evicted oldest prepare result over the per-user quota",
+			zap.String("user", user),
+			zap.String("request_id", requestID))
+	}
+}
@@ -0,0 +1,68 @@
+package prepare_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/pkg/config"
+)
+
+var _ = Describe("PrepareQuota", func() {
+	admit := func(quota *prepare.PrepareQuota, user, role string, requestIDs ...string) []string {
+		evicted, err := quota.Admit(user, role, requestIDs...)
+		Expect(err).NotTo(HaveOccurred())
+		return evicted
+	}
+
+	It("should evict the user's oldest results once the cap is exceeded", func() {
+		quota := prepare.NewPrepareQuota(config.PrepareSettings{MaxResultsPerUser: 2})
+
+		Expect(admit(quota, "alice", "user", "a1")).To(BeEmpty())
+		Expect(admit(quota, "alice", "user", "a2")).To(BeEmpty())
+		Expect(admit(quota, "bob", "user", "b1")).To(BeEmpty())
+
+		Expect(admit(quota, "alice", "user", "a3")).To(Equal([]string{"a1"}))
+		Expect(admit(quota, "alice", "user", "a4", "a5")).To(Equal([]string{"a2", "a3"}))
+		Expect(quota.Count("alice")).To(Equal(2))
+		Expect(quota.Count("bob")).To(Equal(1))
+	})
+
+	It("should reject once the cap is reached in reject mode", func() {
+		quota := prepare.NewPrepareQuota(config.PrepareSettings{MaxResultsPerUser: 2, OnLimit: config.PrepareQuotaReject})
+
+		admit(quota, "alice", "user", "a1", "a2")
+		_, err := quota.Admit("alice", "user", "a3")
+		Expect(err).To(MatchError(prepare.ErrPrepareQuotaExceeded))
+		Expect(quota.Count("alice")).To(Equal(2))
+
+		quota.Release("alice", "a1")
+		Expect(admit(quota, "alice", "user", "a3")).To(BeEmpty())
+	})
+
+	It("should reject batches larger than the cap even when evicting", func() {
+		quota := prepare.NewPrepareQuota(config.PrepareSettings{MaxResultsPerUser: 2})
+		admit(quota, "alice", "user", "a1")
+
+		_, err := quota.Admit("alice", "user", "a2", "a3", "a4")
+		Expect(err).To(MatchError(prepare.ErrPrepareQuotaExceeded))
+		Expect(quota.Count("alice")).To(Equal(1))
+	})
+
+	It("should apply the default cap to users and exempt admins unless capped", func() {
+		quota := prepare.NewPrepareQuota(config.PrepareSettings{OnLimit: config.PrepareQuotaReject})
+		for i := 0; i < config.DefaultPrepareMaxResultsPerUser; i++ {
+			admit(quota, "alice", "user", fmt.Sprintf("a%d", i))
+			admit(quota, "root", "admin", fmt.Sprintf("r%d", i))
+		}
+		_, err := quota.Admit("alice", "user", "over")
+		Expect(err).To(MatchError(prepare.ErrPrepareQuotaExceeded))
+		Expect(admit(quota, "root", "admin", "over")).To(BeEmpty())
+
+		capped := prepare.NewPrepareQuota(config.PrepareSettings{AdminMaxResults: 1})
+		admit(capped, "root", "admin", "r1")
+		Expect(admit(capped, "root", "admin", "r2")).To(Equal([]string{"r1"}))
+	})
+})
@@ -0,0 +1,427 @@
+package prepare
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/image"
+	"github.com/lissto-dev/api/pkg/logging"
+	"github.com/lissto-dev/api/pkg/preprocessor"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+// ImageResolver is the subset of image.ImageResolver used to resolve service images
+type ImageResolver interface {
+	GetImageDigestWithServicePlatform(imageURL string, service types.ServiceConfig) (string, error)
+	ResolveImageDetailed(service types.ServiceConfig, config image.ResolutionConfig) (*image.DetailedImageResolutionResult, error)
+}
+
+// ExplicitImageRewriter is implemented by resolvers that rewrite user-given images (e.g. to a mirror)
+type ExplicitImageRewriter interface {
+	RewriteExplicitImage(imageRef string) string
+}
+
+// PlatformDigestResolver is implemented by resolvers that report the platform an image was resolved for
+type PlatformDigestResolver interface {
+	ResolvePlatformDigest(imageURL string, service types.ServiceConfig) (*image.PlatformDigest, error)
+}
+
+// ImageInspector is implemented by resolvers that can read an image's config
+type ImageInspector interface {
+	InspectImage(imageRef string, service types.ServiceConfig) (*common.ImageConfigInfo, error)
+}
+
+// ResolveOptions controls how service images are resolved
+type ResolveOptions struct {
+	Commit       string
+	Branch       string
+	Detailed     bool // Record failures in the result instead of returning an error
+	AllowPending bool // Resolve build-only services without a published image as pending
+	// ResolvedBefore picks the newest candidate tag pushed before this time (zero disables)
+	ResolvedBefore time.Time
+	// UnpinnedFallback deploys the compose image tag without a digest when it cannot be pinned
+	UnpinnedFallback bool
+	// Verifier checks the signature of resolved images (nil skips verification)
+	Verifier image.SignatureVerifier
+}
+
+// NewImageResolver creates the image resolver used for stack preparation
+// It authenticates with K8s credentials and uses the global registry config and tag source order
+func NewImageResolver(cfg *controllerconfig.Config, settings *config.Settings, cache cache.Cache) *image.ImageResolver {
+	// Create image existence checker with K8s authentication
+	// This will automatically use:
+	// - Image pull secrets from the pod's service account
+	// - Node IAM credentials (ECR on AWS, Workload Identity on GCP, etc.)
+	// - Docker config files and credential helpers
+	// Falls back to anonymous access if authentication is not available
+	// Registry calls are retried and fail fast while a registry's circuit breaker is open
+	imageChecker := image.NewBreakerChecker(
+		image.NewImageExistenceCheckerWithK8sAuth(context.Background()),
+		image.DefaultRegistryBreaker,
+	)
+
+	// Create image resolver with global config and cache support
+	resolver := image.NewImageResolverWithCache(
+		cfg.Stacks.Images.Registry,
+		cfg.Stacks.Images.RepositoryPrefix,
+		imageChecker,
+		cache,
+	)
+
+	// Tag sources and rewrite rules are validated when settings are loaded
+	if err := resolver.SetTagSources(settings.Images.TagSources); err != nil {
+		logging.Logger.Warn("Ignoring invalid tag sources, using defaults",
+			zap.Strings("tag_sources", settings.Images.TagSources),
+			zap.Error(err))
+	}
+	if err := resolver.SetRewriteRules(settings.Images.Rewrites, settings.Images.RewriteExplicit); err != nil {
+		logging.Logger.Warn("Ignoring invalid image rewrite rules", zap.Error(err))
+	}
+	if err := resolver.SetDefaultTag(settings.Images.DefaultTag); err != nil {
+		logging.Logger.Warn("Ignoring invalid default tag, using latest",
+			zap.String("default_tag", settings.Images.DefaultTag),
+			zap.Error(err))
+	}
+	return resolver
+}
+
+// ResolutionConfigHash is a short, stable hash of the settings NewImageResolver applies
+// (global registry and repository prefix, tag sources, rewrites and default tag)
+func ResolutionConfigHash(cfg *controllerconfig.Config, settings *config.Settings) string {
+	data, _ := json.Marshal(struct {
+		Registry         string               `json:"registry"`
+		RepositoryPrefix string               `json:"repositoryPrefix"`
+		Images           config.ImageSettings `json:"images"`
+	}{
+		Registry:         cfg.Stacks.Images.Registry,
+		RepositoryPrefix: cfg.Stacks.Images.RepositoryPrefix,
+		Images:           settings.Images,
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// ResolveServiceImage resolves the image of a single compose service
+// Priority: lissto.dev/image override label → digest-pinned image → explicit image → build candidates
+// In detailed mode failures are recorded in the returned info and no error is returned,
+// and the digest of a declared base image (lissto.dev/base-image) is reported
+// Images outside a blueprint's x-lissto.allowedDigests are always rejected
+// With opts.Verifier set, the signature of the resolved image is recorded as well
+func ResolveServiceImage(
+	resolver ImageResolver,
+	serviceName string,
+	service types.ServiceConfig,
+	lisstoConfig *compose.LisstoConfig,
+	opts ResolveOptions,
+) (common.DetailedImageResolutionInfo, error) {
+	info, err := resolveServiceImage(resolver, serviceName, service, lisstoConfig, opts)
+	if err != nil {
+		return info, err
+	}
+	// Reviewed blueprints pin their approved digests; a re-pushed tag must not slip through, also in detailed mode
+	if len(lisstoConfig.AllowedDigests) > 0 && (info.Digest != "" || info.Pending) {
+		if err := image.CheckAllowedDigest(info.Digest, lisstoConfig.AllowedDigests); err != nil {
+			return info, fmt.Errorf("image of service %s rejected by x-lissto.allowedDigests: %w", serviceName, err)
+		}
+	}
+	info = verifyImageSignature(opts.Verifier, info)
+	if !opts.Detailed {
+		return info, nil
+	}
+	return resolveBaseImage(resolver, info, service), nil
+}
+
+// resolveServiceImage resolves the service's own image, see ResolveServiceImage
+func resolveServiceImage(
+	resolver ImageResolver,
+	serviceName string,
+	service types.ServiceConfig,
+	lisstoConfig *compose.LisstoConfig,
+	opts ResolveOptions,
+) (common.DetailedImageResolutionInfo, error) {
+	info := common.DetailedImageResolutionInfo{Service: serviceName}
+
+	// PRIORITY: Check for lissto.dev/image override label first
+	imageOverride := ""
+	if service.Labels != nil {
+		if override, ok := service.Labels["lissto.dev/image"]; ok && override != "" {
+			imageOverride = override
+		}
+	}
+
+	// If service has image override label, use it with highest priority
+	if imageOverride != "" {
+		logging.Logger.Info("Using image override from label",
+			zap.String("service", serviceName),
+			zap.String("override_image", imageOverride))
+		return resolveExplicitImage(resolver, info, imageOverride, "override", service, opts)
+	}
+
+	// A compose image pinned to a digest is only verified and deployed as written
+	if image.IsDigestPinned(service.Image) {
+		return resolvePinnedImage(resolver, info, service, opts)
+	}
+
+	// If service has image, resolve to digest
+	if service.Image != "" {
+		logging.Logger.Info("Service has explicit image, resolving to digest",
+			zap.String("service", serviceName),
+			zap.String("image", service.Image))
+		return resolveExplicitImage(resolver, info, service.Image, "original", service, opts)
+	}
+
+	// Service has build or needs resolution - try candidates
+	logging.Logger.Info("Service needs image resolution, trying candidates",
+		zap.String("service", serviceName),
+		zap.String("commit", opts.Commit),
+		zap.String("branch", opts.Branch))
+
+	result, err := resolver.ResolveImageDetailed(
+		service,
+		image.ResolutionConfig{
+			Commit:              opts.Commit,
+			Branch:              opts.Branch,
+			ComposeRegistry:     lisstoConfig.Registry,
+			ComposeRepository:   lisstoConfig.Repository,
+			ComposePrefix:       lisstoConfig.RepositoryPrefix,
+			ResolvedBefore:      opts.ResolvedBefore,
+			UnpinnedFallback:    opts.UnpinnedFallback,
+			DefaultTag:          lisstoConfig.DefaultTag,
+			TagPrefixPerService: lisstoConfig.TagPrefixPerService,
+		},
+	)
+	if err != nil && !errors.Is(err, image.ErrMissingPlatforms) && image.AllowsBuildPending(service, opts.AllowPending) {
+		// Build-only service without a published image yet: mark as pending
+		// instead of failing, so clients can show it as awaiting its first build
+		placeholder := image.PendingPlaceholder(result)
+		logging.Logger.Info("No published image yet, marking service as pending build",
+			zap.String("service", serviceName),
+			zap.String("placeholder", placeholder))
+
+		info.Image = placeholder
+		info.Method = image.MethodPending
+		info.Registry = result.Registry
+		info.ImageName = result.ImageName
+		info.Candidates = result.Candidates
+		info.Pending = true
+		return info, nil
+	}
+
+	if err != nil {
+		logging.Logger.Error("Failed to resolve image for service",
+			zap.String("service", serviceName),
+			zap.Error(err))
+	}
+
+	// Always use result data, even on error
+	if result != nil {
+		info.Digest = result.FinalImage
+		info.Image = result.Selected
+		info.Method = result.Method
+		info.Registry = result.Registry
+		info.ImageName = result.ImageName
+		info.Candidates = result.Candidates
+		info.Unpinned = result.Method == image.MethodUnpinnedFallback
+		info.Platform = result.Platform
+		info.MultiArch = result.MultiArch
+		info.Platforms = result.Platforms
+	}
+
+	// In standard mode, return error immediately
+	if err != nil && !opts.Detailed {
+		return info, fmt.Errorf("failed to resolve image for service %s: %w", serviceName, err)
+	}
+	return info, nil
+}
+
+// ResolveExposedServiceImage resolves a service image like ResolveServiceImage and,
+// when env is set, records whether the service is exposed and its URL in that env
+func ResolveExposedServiceImage(
+	resolver ImageResolver,
+	exposePreprocessor *preprocessor.ExposePreprocessor,
+	serviceName string,
+	service types.ServiceConfig,
+	lisstoConfig *compose.LisstoConfig,
+	env string,
+	opts ResolveOptions,
+) (common.DetailedImageResolutionInfo, error) {
+	info, err := ResolveServiceImage(resolver, serviceName, service, lisstoConfig, opts)
+	if err != nil {
+		return info, err
+	}
+
+	if env != "" {
+		if exposedURL := exposePreprocessor.GetExposedServiceURL(service, serviceName, env); exposedURL != "" {
+			info.Exposed = true
+			info.URL = exposedURL
+		}
+	}
+	return info, nil
+}
+
+// resolveExplicitImage resolves an image given by label or image field to its digest
+func resolveExplicitImage(
+	resolver ImageResolver,
+	info common.DetailedImageResolutionInfo,
+	imageRef, method string,
+	service types.ServiceConfig,
+	opts ResolveOptions,
+) (common.DetailedImageResolutionInfo, error) {
+	// Apply registry rewrite rules when enabled for explicit images
+	if rewriter, ok := resolver.(ExplicitImageRewriter); ok {
+		imageRef = rewriter.RewriteExplicitImage(imageRef)
+	}
+
+	info.Image = imageRef // User-friendly tag (e.g., nginx:alpine), kept even on error
+	info.Method = method
+
+	// Use service context for platform-specific resolution and caching
+	resolved, err := resolveDigest(resolver, imageRef, service)
+	if err != nil {
+		logging.Logger.Error("Failed to get image digest",
+			zap.String("service", info.Service),
+			zap.String("image", imageRef),
+			zap.String("method", method),
+			zap.Error(err))
+
+		failed := common.ImageCandidate{
+			ImageURL: imageRef,
+			Tag:      method,
+			Source:   method,
+			Success:  false,
+			Error:    err.Error(),
+		}
+
+		// Only the compose image field falls back; an explicit override or a missing required platform must resolve
+		if opts.UnpinnedFallback && method == "original" && !errors.Is(err, image.ErrMissingPlatforms) {
+			logging.Logger.Warn("Image digest unavailable, deploying compose image tag UNPINNED",
+				zap.String("service", info.Service),
+				zap.String("image", imageRef))
+
+			info.Digest = imageRef
+			info.Method = image.MethodUnpinnedFallback
+			info.Unpinned = true
+			info.Candidates = []common.ImageCandidate{failed, image.UnpinnedFallbackCandidate(imageRef)}
+			return info, nil
+		}
+
+		// In detailed mode, continue processing and show the error
+		if !opts.Detailed {
+			if method == "override" {
+				return info, fmt.Errorf("failed to resolve override image for service %s: %w", info.Service, err)
+			}
+			return info, fmt.Errorf("failed to resolve image for service %s: %w", info.Service, err)
+		}
+		info.Candidates = []common.ImageCandidate{failed}
+		return info, nil
+	}
+
+	info.Digest = resolved.Image // Full digest (e.g., nginx@sha256:...)
+	info.Platform = resolved.Platform
+	info.MultiArch = resolved.MultiArch
+	info.Platforms = resolved.Platforms
+	info.Candidates = []common.ImageCandidate{{
+		ImageURL: imageRef,
+		Tag:      method,
+		Source:   method,
+		Success:  true,
+		Digest:   resolved.Image,
+	}}
+	return info, nil
+}
+
+// resolvePinnedImage verifies that a digest-pinned compose image exists and keeps the reference unchanged
+// No rewrite rules are applied and no tag candidates are generated
+func resolvePinnedImage(
+	resolver ImageResolver,
+	info common.DetailedImageResolutionInfo,
+	service types.ServiceConfig,
+	opts ResolveOptions,
+) (common.DetailedImageResolutionInfo, error) {
+	info.Image = service.Image
+	info.Method = image.MethodPinned
+
+	resolved, err := resolveDigest(resolver, service.Image, service)
+	if err != nil {
+		logging.Logger.Error("Pinned image not found",
+			zap.String("service", info.Service),
+			zap.String("image", service.Image),
+			zap.Error(err))
+		if !opts.Detailed {
+			return info, fmt.Errorf("failed to verify pinned image for service %s: %w", info.Service, err)
+		}
+		info.Candidates = []common.ImageCandidate{{
+			ImageURL: service.Image,
+			Tag:      image.MethodPinned,
+			Source:   image.MethodPinned,
+			Error:    err.Error(),
+		}}
+		return info, nil
+	}
+
+	logging.Logger.Info("Using digest-pinned compose image",
+		zap.String("service", info.Service),
+		zap.String("image", service.Image))
+
+	info.Digest = service.Image
+	info.Platform = resolved.Platform
+	info.MultiArch = resolved.MultiArch
+	info.Platforms = resolved.Platforms
+	info.Candidates = []common.ImageCandidate{image.PinnedCandidate(service.Image)}
+	return info, nil
+}
+
+// resolveDigest resolves imageRef for the service's platform, with the platform
+// details when the resolver reports them
+func resolveDigest(resolver ImageResolver, imageRef string, service types.ServiceConfig) (*image.PlatformDigest, error) {
+	if platformResolver, ok := resolver.(PlatformDigestResolver); ok {
+		return platformResolver.ResolvePlatformDigest(imageRef, service)
+	}
+	imageWithDigest, err := resolver.GetImageDigestWithServicePlatform(imageRef, service)
+	if err != nil {
+		return nil, err
+	}
+	return &image.PlatformDigest{Image: imageWithDigest}, nil
+}
+
+// resolveBaseImage records the digest of a build service's lissto.dev/base-image
+// Failures are reported in the info only; the base image is informational
+func resolveBaseImage(
+	resolver ImageResolver,
+	info common.DetailedImageResolutionInfo,
+	service types.ServiceConfig,
+) common.DetailedImageResolutionInfo {
+	baseImage := image.BaseImage(service)
+	if baseImage == "" {
+		return info
+	}
+	if rewriter, ok := resolver.(ExplicitImageRewriter); ok {
+		baseImage = rewriter.RewriteExplicitImage(baseImage)
+	}
+	info.BaseImage = baseImage
+
+	// Same lookup as explicit images, so the digest cache is shared
+	digest, err := resolver.GetImageDigestWithServicePlatform(baseImage, service)
+	if err != nil {
+		logging.Logger.Warn("Failed to resolve base image digest",
+			zap.String("service", info.Service),
+			zap.String("base_image", baseImage),
+			zap.Error(err))
+		info.BaseImageError = err.Error()
+		return info
+	}
+	info.BaseImageDigest = digest
+	return info
+}
@@ -0,0 +1,41 @@
+package prepare
+
+import (
+	"fmt"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// WarningResourceFloorRaised reports a service value raised to the env's resource floor
+const WarningResourceFloorRaised = "resource_floor_raised"
+
+// ApplyResourceFloor raises the project's services below the env's resource floor (resources.floors) to it
+// Every adjustment is logged and returned as a warning
+func ApplyResourceFloor(project *types.Project, env string, resources config.ResourceSettings) []common.PrepareWarning {
+	adjustments := resources.ApplyFloor(project, env)
+	warnings := make([]common.PrepareWarning, 0, len(adjustments))
+	for _, adjustment := range adjustments {
+		from := adjustment.From
+		if from == "" {
+			from = "unset"
+		}
+		logging.Logger.Info("Raised service to the env's resource floor",
+			zap.String("env", env),
+			zap.String("service", adjustment.Service),
+			zap.String("field", adjustment.Field),
+			zap.String("from", from),
+			zap.String("to", adjustment.To))
+		warnings = append(warnings, common.PrepareWarning{
+			Service: adjustment.Service,
+			Code:    WarningResourceFloorRaised,
+			Message: fmt.Sprintf("service %s: %s raised from %s to %s, the floor of env %s",
+				adjustment.Service, adjustment.Field, from, adjustment.To, env),
+		})
+	}
+	return warnings
+}
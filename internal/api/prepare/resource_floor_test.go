@@ -0,0 +1,87 @@
+package prepare_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/k8s"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+var _ = Describe("Prepare with resource floors", func() {
+	var h *prepare.Handler
+
+	BeforeEach(func() {
+		Expect(logging.InitLogger("info", "console")).To(Succeed())
+
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}},
+			&envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "production", Namespace: "dev-alice"}},
+		).Build()
+
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		nsManager := authz.NewNamespaceManager(cfg)
+		settings := config.DefaultSettings()
+		settings.Resources.Floors.Envs = map[string]compose.ResourceFloor{"production": {MinReplicas: 3}}
+		h = prepare.NewHandler(k8s.NewClientFromClient(fakeClient, scheme), authz.NewAuthorizer(nsManager), nsManager,
+			cfg, settings, cache.NewMemoryCache())
+		h.SetImageChecker(&countingChecker{})
+	})
+
+	prepareIn := func(env string) common.DetailedPrepareStackResponse {
+		e := echo.New()
+		e.Validator = &testValidator{validator: validator.New()}
+		body := `{"compose":"services:\n  web:\n    image: nginx:1.27\n    deploy:\n      replicas: 1\n","env":"` + env + `","detailed":true}`
+		req := httptest.NewRequest(http.MethodPost, "/stacks/prepare?showNormalized=true", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user", &middleware.User{Name: "alice", Role: authz.User})
+		Expect(h.PrepareStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+
+		var resp common.DetailedPrepareStackResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		return resp
+	}
+
+	It("should raise a service requesting 1 replica to the env's floor and report it", func() {
+		resp := prepareIn("production")
+
+		Expect(resp.Warnings).To(ConsistOf(common.PrepareWarning{
+			Service: "web",
+			Code:    prepare.WarningResourceFloorRaised,
+			Message: "service web: replicas raised from 1 to 3, the floor of env production",
+		}))
+		Expect(resp.NormalizedCompose).To(ContainSubstring("replicas: 3"))
+	})
+
+	It("should leave services alone in envs without a floor", func() {
+		resp := prepareIn("dev")
+
+		Expect(resp.Warnings).To(BeEmpty())
+		Expect(resp.NormalizedCompose).To(ContainSubstring("replicas: 1"))
+	})
+})
@@ -8,4 +8,7 @@ import (
 func RegisterRoutes(g *echo.Group, handler *Handler) {
 	// All authorization is handled in the handler methods
 	g.POST("/prepare", handler.PrepareStack)
+	g.POST("/prepare/plan", handler.PlanStack)
+	g.POST("/prepare/batch", handler.PrepareStackBatch)
+	g.GET("/prepare/diff", handler.DiffPrepared)
 }
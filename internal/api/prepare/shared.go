@@ -0,0 +1,77 @@
+package prepare
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// sharedResultKeyPrefix prefixes the cache keys of shared prepare results
+const sharedResultKeyPrefix = "prepare-shared:"
+
+// sharedResultInput is everything besides the registries that decides a prepare's resolved images
+type sharedResultInput struct {
+	ComposeHash    string    `json:"compose_hash"`
+	Commit         string    `json:"commit,omitempty"`
+	Branch         string    `json:"branch,omitempty"`
+	Tag            string    `json:"tag,omitempty"`
+	Env            string    `json:"env"`
+	Detailed       bool      `json:"detailed,omitempty"`
+	AllowPending   bool      `json:"allow_pending,omitempty"`
+	ResolvedBefore time.Time `json:"resolved_before,omitempty"`
+	HostSuffix     string    `json:"host_suffix,omitempty"`
+}
+
+// sharedPrepareResult is the resolution of a prepare shared between users; it holds no request ID
+// or namespace, each prepare caches its own entry for stack creation
+type sharedPrepareResult struct {
+	Results []common.DetailedImageResolutionInfo `json:"results"`
+}
+
+// sharedResultKey returns the cache key of the shared result for a prepare, keyed by the compose hash,
+// commit, branch and env plus the options that change resolution
+func sharedResultKey(composeContent string, req *common.PrepareStackRequest, resolvedBefore time.Time, hostSuffix string) string {
+	composeHash := sha256.Sum256([]byte(composeContent))
+	input, _ := json.Marshal(sharedResultInput{
+		ComposeHash:    hex.EncodeToString(composeHash[:]),
+		Commit:         req.Commit,
+		Branch:         req.Branch,
+		Tag:            req.Tag,
+		Env:            req.Env,
+		Detailed:       req.Detailed,
+		AllowPending:   req.AllowPending,
+		ResolvedBefore: resolvedBefore,
+		HostSuffix:     hostSuffix,
+	})
+	key := sha256.Sum256(input)
+	return sharedResultKeyPrefix + hex.EncodeToString(key[:])
+}
+
+// sharedResults returns the resolved images of a recent identical prepare, if sharing is enabled
+func (h *Handler) sharedResults(ctx context.Context, key string) ([]common.DetailedImageResolutionInfo, bool) {
+	if h.sharedResultTTL <= 0 || key == "" {
+		return nil, false
+	}
+	var shared sharedPrepareResult
+	if err := h.cache.Get(ctx, key, &shared); err != nil {
+		return nil, false
+	}
+	return shared.Results, true
+}
+
+// shareResults stores resolved images for identical prepares within the sharing TTL
+func (h *Handler) shareResults(ctx context.Context, key string, results []common.DetailedImageResolutionInfo) {
+	if h.sharedResultTTL <= 0 || key == "" {
+		return
+	}
+	if err := h.cache.Set(ctx, key, &sharedPrepareResult{Results: results}, h.sharedResultTTL); err != nil {
+		logging.Logger.Warn("Failed to share prepare result", zap.Error(err))
+	}
+}
@@ -0,0 +1,143 @@
+package prepare_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/image"
+	"github.com/lissto-dev/api/pkg/k8s"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+// countingChecker finds every image with a fixed digest and counts lookups
+type countingChecker struct {
+	calls int
+}
+
+func (c *countingChecker) CheckImageExists(string) (*image.ImageMetadata, error) {
+	c.calls++
+	return &image.ImageMetadata{
+		Exists: true,
+		Digest: "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+	}, nil
+}
+
+func (c *countingChecker) CheckImageExistsForPlatform(imageURL, _, _ string) (*image.ImageMetadata, error) {
+	return c.CheckImageExists(imageURL)
+}
+
+var _ = Describe("Shared prepare results", func() {
+	const body = `{"compose":"services:\n  web:\n    image: registry.acme.io/web:1.0\n","env":"dev","commit":"abc123"}`
+
+	var (
+		checker    *countingChecker
+		memCache   cache.Cache
+		settings   *config.Settings
+		newHandler func() *prepare.Handler
+	)
+
+	BeforeEach(func() {
+		Expect(logging.InitLogger("info", "console")).To(Succeed())
+
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}},
+			&envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-bob"}},
+		).Build()
+
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		nsManager := authz.NewNamespaceManager(cfg)
+
+		checker = &countingChecker{}
+		memCache = cache.NewMemoryCache()
+		settings = config.DefaultSettings()
+		settings.Prepare.SharedResultTTLSeconds = 60
+		newHandler = func() *prepare.Handler {
+			h := prepare.NewHandler(k8s.NewClientFromClient(fakeClient, scheme), authz.NewAuthorizer(nsManager), nsManager,
+				cfg, settings, memCache)
+			h.SetImageChecker(checker)
+			return h
+		}
+	})
+
+	prepareAs := func(h *prepare.Handler, userName, body string) common.DetailedPrepareStackResponse {
+		e := echo.New()
+		e.Validator = &testValidator{validator: validator.New()}
+		req := httptest.NewRequest(http.MethodPost, "/stacks/prepare", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user", &middleware.User{Name: userName, Role: authz.User})
+		Expect(h.PrepareStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+
+		var response common.DetailedPrepareStackResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+		return response
+	}
+
+	detailed := func(body string) string {
+		return strings.Replace(body, `"env":"dev"`, `"env":"dev","detailed":true`, 1)
+	}
+
+	It("should reuse the resolution of an identical prepare by another user", func() {
+		h := newHandler()
+		first := prepareAs(h, "alice", detailed(body))
+		calls := checker.calls
+		Expect(calls).To(BeNumerically(">", 0))
+
+		second := prepareAs(h, "bob", detailed(body))
+
+		Expect(checker.calls).To(Equal(calls))
+		Expect(second.Images).To(Equal(first.Images))
+		Expect(second.RequestID).NotTo(BeEmpty())
+		Expect(second.RequestID).NotTo(Equal(first.RequestID))
+
+		var entry cache.PrepareResultCache
+		Expect(memCache.Get(context.Background(), second.RequestID, &entry)).To(Succeed())
+		Expect(entry.Namespace).To(Equal("dev-bob"))
+		Expect(entry.Images).To(HaveKey("web"))
+	})
+
+	It("should resolve again for a different commit", func() {
+		h := newHandler()
+		prepareAs(h, "alice", detailed(body))
+		calls := checker.calls
+
+		prepareAs(h, "alice", detailed(strings.Replace(body, "abc123", "def456", 1)))
+
+		Expect(checker.calls).To(BeNumerically(">", calls))
+	})
+
+	It("should resolve every prepare when sharing is disabled", func() {
+		settings.Prepare.SharedResultTTLSeconds = 0
+		h := newHandler()
+		prepareAs(h, "alice", detailed(body))
+		calls := checker.calls
+
+		prepareAs(h, "alice", detailed(body))
+
+		Expect(checker.calls).To(Equal(2 * calls))
+	})
+})
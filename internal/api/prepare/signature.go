@@ -0,0 +1,110 @@
+package prepare
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/image"
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// NewSignatureVerifier creates the cached cosign verifier for the configured keys and keyless roots
+// It returns nil when signature verification is not configured
+func NewSignatureVerifier(settings config.SignatureSettings, c cache.Cache) (image.SignatureVerifier, error) {
+	if !settings.Enabled() {
+		return nil, nil
+	}
+
+	keys := make([]image.TrustedKey, 0, len(settings.Keys))
+	for _, path := range settings.Keys {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signature key: %w", err)
+		}
+		key, err := image.ParsePublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signature key %s: %w", path, err)
+		}
+		keys = append(keys, image.TrustedKey{Name: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), Key: key})
+	}
+
+	var keyless *image.KeylessPolicy
+	if settings.Roots != "" {
+		data, err := os.ReadFile(settings.Roots)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signature roots: %w", err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in %s", settings.Roots)
+		}
+		keyless = &image.KeylessPolicy{Roots: roots, Identities: settings.KeylessIdentities()}
+		for _, path := range settings.RekorKeys {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read rekor key: %w", err)
+			}
+			key, err := image.ParsePublicKey(data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rekor key %s: %w", path, err)
+			}
+			keyless.RekorKeys = append(keyless.RekorKeys, key)
+		}
+	}
+
+	// Signatures live next to the images, so they are read with the same registry credentials
+	keychain, err := image.GetK8sKeychain(context.Background())
+	if err != nil {
+		logging.Logger.Warn("K8s authentication not available for signatures, using default keychain", zap.Error(err))
+		keychain = nil
+	}
+
+	logging.Logger.Info("Image signature verification enabled",
+		zap.Int("keys", len(keys)),
+		zap.Int("issuers", len(settings.Issuers)),
+		zap.Int("rekor_keys", len(settings.RekorKeys)),
+		zap.Bool("required", settings.Required))
+	return image.NewCachedSignatureVerifier(image.NewCosignVerifier(keys, keyless, keychain), c), nil
+}
+
+// verifyImageSignature records the signature status of a resolved image
+// Pending and unpinned images have no digest to verify; registry failures are reported as unverified
+func verifyImageSignature(verifier image.SignatureVerifier, info common.DetailedImageResolutionInfo) common.DetailedImageResolutionInfo {
+	if verifier == nil || info.Pending || info.Unpinned || !strings.Contains(info.Digest, "@sha256:") {
+		return info
+	}
+
+	result, err := verifier.VerifySignature(context.Background(), info.Digest)
+	if err != nil {
+		logging.Logger.Warn("Failed to verify image signature",
+			zap.String("service", info.Service),
+			zap.String("image", info.Digest),
+			zap.Error(err))
+		info.Signature = &common.SignatureInfo{Error: err.Error()}
+		return info
+	}
+	info.Signature = &common.SignatureInfo{
+		Signed:   result.Signed,
+		Verified: result.Verified,
+		Signer:   result.Signer,
+		Error:    result.Error,
+	}
+	return info
+}
+
+// verifiedSignature returns whether the resolved image's signature verified and who signed it
+func verifiedSignature(info common.DetailedImageResolutionInfo) (bool, string) {
+	if info.Signature == nil || !info.Signature.Verified {
+		return false, ""
+	}
+	return true, info.Signature.Signer
+}
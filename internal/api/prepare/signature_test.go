@@ -0,0 +1,78 @@
+package prepare_test
+
+import (
+	"context"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+// mockSignatureVerifier answers with a fixed result per image
+type mockSignatureVerifier struct {
+	results map[string]*image.SignatureResult
+}
+
+func (m *mockSignatureVerifier) VerifySignature(_ context.Context, imageRef string) (*image.SignatureResult, error) {
+	if result, ok := m.results[imageRef]; ok {
+		return result, nil
+	}
+	return &image.SignatureResult{Error: "no cosign signature found"}, nil
+}
+
+var _ = Describe("Image signature verification", func() {
+	const signed = "ghcr.io/acme/web@sha256:1111111111111111111111111111111111111111111111111111111111111111"
+
+	var (
+		resolver *mockImageResolver
+		verifier *mockSignatureVerifier
+	)
+
+	BeforeEach(func() {
+		resolver = new(mockImageResolver)
+		verifier = &mockSignatureVerifier{results: map[string]*image.SignatureResult{
+			signed: {Signed: true, Verified: true, Signer: "release"},
+		}}
+	})
+
+	It("should report the signer of a verified image", func() {
+		resolver.On("GetImageDigestWithServicePlatform", "ghcr.io/acme/web:1.0", mock.AnythingOfType("types.ServiceConfig")).
+			Return(signed, nil)
+
+		info, err := prepare.ResolveServiceImage(resolver, "web", types.ServiceConfig{Name: "web", Image: "ghcr.io/acme/web:1.0"},
+			&compose.LisstoConfig{}, prepare.ResolveOptions{Detailed: true, Verifier: verifier})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Signature).To(Equal(&common.SignatureInfo{Signed: true, Verified: true, Signer: "release"}))
+	})
+
+	It("should report an unsigned image as unverified", func() {
+		unsigned := "ghcr.io/acme/worker@sha256:2222222222222222222222222222222222222222222222222222222222222222"
+		resolver.On("GetImageDigestWithServicePlatform", "ghcr.io/acme/worker:1.0", mock.AnythingOfType("types.ServiceConfig")).
+			Return(unsigned, nil)
+
+		info, err := prepare.ResolveServiceImage(resolver, "worker", types.ServiceConfig{Name: "worker", Image: "ghcr.io/acme/worker:1.0"},
+			&compose.LisstoConfig{}, prepare.ResolveOptions{Verifier: verifier})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Signature.Verified).To(BeFalse())
+		Expect(info.Signature.Error).To(Equal("no cosign signature found"))
+	})
+
+	It("should skip verification without a verifier", func() {
+		resolver.On("GetImageDigestWithServicePlatform", "ghcr.io/acme/web:1.0", mock.AnythingOfType("types.ServiceConfig")).
+			Return(signed, nil)
+
+		info, err := prepare.ResolveServiceImage(resolver, "web", types.ServiceConfig{Name: "web", Image: "ghcr.io/acme/web:1.0"},
+			&compose.LisstoConfig{}, prepare.ResolveOptions{})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Signature).To(BeNil())
+	})
+})
@@ -0,0 +1,80 @@
+package prepare
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/pkg/logging"
+	"github.com/lissto-dev/api/pkg/preprocessor"
+)
+
+// Warning codes reported by the TLS secret check
+const (
+	WarningTLSSecretMissing    = "tls_secret_missing"
+	WarningTLSSecretUnverified = "tls_secret_unverified"
+)
+
+// SecretGetter reads Kubernetes secrets
+type SecretGetter interface {
+	GetSecret(ctx context.Context, namespace, name string) (*corev1.Secret, error)
+}
+
+// CheckTLSSecrets verifies that the TLS secret of every exposed service exists in the stack namespace
+// Without it the ingress is created without TLS, so problems are reported as warnings sorted by service
+func CheckTLSSecrets(
+	ctx context.Context,
+	secrets SecretGetter,
+	namespace string,
+	services types.Services,
+	exposePreprocessor *preprocessor.ExposePreprocessor,
+) []common.PrepareWarning {
+	var warnings []common.PrepareWarning
+	checked := make(map[string]error)
+
+	for serviceName, service := range services {
+		secretName := exposePreprocessor.GetTLSSecret(service)
+		if secretName == "" {
+			continue
+		}
+
+		err, seen := checked[secretName]
+		if !seen {
+			_, err = secrets.GetSecret(ctx, namespace, secretName)
+			checked[secretName] = err
+		}
+		if err == nil {
+			continue
+		}
+
+		if errors.IsNotFound(err) {
+			warnings = append(warnings, common.PrepareWarning{
+				Service: serviceName,
+				Code:    WarningTLSSecretMissing,
+				Message: fmt.Sprintf("TLS secret '%s' not found in namespace '%s'; service '%s' would be exposed without TLS",
+					secretName, namespace, serviceName),
+			})
+			continue
+		}
+
+		logging.Logger.Warn("Failed to check TLS secret",
+			zap.String("service", serviceName),
+			zap.String("secret", secretName),
+			zap.String("namespace", namespace),
+			zap.Error(err))
+		warnings = append(warnings, common.PrepareWarning{
+			Service: serviceName,
+			Code:    WarningTLSSecretUnverified,
+			Message: fmt.Sprintf("could not verify TLS secret '%s' for service '%s': %v", secretName, serviceName, err),
+		})
+	}
+
+	sort.Slice(warnings, func(i, j int) bool { return warnings[i].Service < warnings[j].Service })
+	return warnings
+}
@@ -0,0 +1,93 @@
+package prepare_test
+
+import (
+	"context"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/pkg/k8s"
+	"github.com/lissto-dev/api/pkg/logging"
+	"github.com/lissto-dev/api/pkg/preprocessor"
+)
+
+var _ = Describe("CheckTLSSecrets", func() {
+	var (
+		exposePreprocessor *preprocessor.ExposePreprocessor
+		services           types.Services
+	)
+
+	BeforeEach(func() {
+		Expect(logging.InitLogger("info", "console")).To(Succeed())
+		exposePreprocessor = preprocessor.NewExposePreprocessor(
+			&preprocessor.IngressConfig{IngressClass: "nginx", HostSuffix: ".dev.example.com", TLSSecret: "internal-tls"},
+			&preprocessor.IngressConfig{IngressClass: "nginx-public", HostSuffix: ".example.com", TLSSecret: "public-tls"},
+		)
+		services = types.Services{
+			"web":    {Name: "web", Labels: map[string]string{"lissto.dev/expose": "internet"}},
+			"admin":  {Name: "admin", Labels: map[string]string{"lissto.dev/expose": "internal"}},
+			"worker": {Name: "worker"},
+		}
+	})
+
+	newClient := func(objects ...client.Object) *k8s.Client {
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+		return k8s.NewClientFromClient(fakeClient, scheme)
+	}
+
+	secret := func(name string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "dev-alice"}}
+	}
+
+	It("should not warn when all TLS secrets exist", func() {
+		c := newClient(secret("internal-tls"), secret("public-tls"))
+
+		warnings := prepare.CheckTLSSecrets(context.Background(), c, "dev-alice", services, exposePreprocessor)
+
+		Expect(warnings).To(BeEmpty())
+	})
+
+	It("should name the missing secret and the exposed service", func() {
+		c := newClient(secret("internal-tls"))
+
+		warnings := prepare.CheckTLSSecrets(context.Background(), c, "dev-alice", services, exposePreprocessor)
+
+		Expect(warnings).To(HaveLen(1))
+		Expect(warnings[0].Service).To(Equal("web"))
+		Expect(warnings[0].Code).To(Equal(prepare.WarningTLSSecretMissing))
+		Expect(warnings[0].Message).To(ContainSubstring("public-tls"))
+		Expect(warnings[0].Message).To(ContainSubstring("dev-alice"))
+	})
+
+	It("should only look in the stack namespace", func() {
+		other := secret("public-tls")
+		other.Namespace = "lissto-global"
+		c := newClient(secret("internal-tls"), other)
+
+		warnings := prepare.CheckTLSSecrets(context.Background(), c, "dev-alice", services, exposePreprocessor)
+
+		Expect(warnings).To(HaveLen(1))
+		Expect(warnings[0].Service).To(Equal("web"))
+	})
+
+	It("should not check secrets cert-manager issues", func() {
+		exposePreprocessor = preprocessor.NewExposePreprocessor(
+			&preprocessor.IngressConfig{IngressClass: "nginx", HostSuffix: ".dev.example.com", TLSSecret: "internal-tls"},
+			&preprocessor.IngressConfig{IngressClass: "nginx-public", HostSuffix: ".example.com", TLSSecret: "public-tls",
+				TLSMode: preprocessor.TLSModeCertManager, ClusterIssuer: "letsencrypt"},
+		)
+		c := newClient(secret("internal-tls"))
+
+		warnings := prepare.CheckTLSSecrets(context.Background(), c, "dev-alice", services, exposePreprocessor)
+
+		Expect(warnings).To(BeEmpty())
+	})
+})
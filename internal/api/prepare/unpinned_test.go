@@ -0,0 +1,58 @@
+package prepare_test
+
+import (
+	"errors"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+var _ = Describe("Unpinned fallback", func() {
+	var (
+		resolver *mockImageResolver
+		service  types.ServiceConfig
+	)
+
+	BeforeEach(func() {
+		service = types.ServiceConfig{Name: "cache", Image: "redis:7"}
+		resolver = new(mockImageResolver)
+		resolver.On("GetImageDigestWithServicePlatform", "redis:7", mock.AnythingOfType("types.ServiceConfig")).
+			Return("", errors.New("registry unavailable"))
+	})
+
+	It("should fail by default when the image cannot be pinned", func() {
+		_, err := prepare.ResolveServiceImage(resolver, "cache", service, &compose.LisstoConfig{}, prepare.ResolveOptions{})
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should deploy the compose image tag unpinned when enabled", func() {
+		info, err := prepare.ResolveServiceImage(resolver, "cache", service, &compose.LisstoConfig{}, prepare.ResolveOptions{
+			UnpinnedFallback: true,
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Unpinned).To(BeTrue())
+		Expect(info.Digest).To(Equal("redis:7"))
+		Expect(info.Method).To(Equal(image.MethodUnpinnedFallback))
+		Expect(info.Candidates).To(HaveLen(2))
+		Expect(info.Candidates[0].Success).To(BeFalse())
+		Expect(info.Candidates[1].Source).To(Equal(image.MethodUnpinnedFallback))
+	})
+
+	It("should not fall back for lissto.dev/image overrides", func() {
+		service.Labels = map[string]string{"lissto.dev/image": "redis:7"}
+
+		_, err := prepare.ResolveServiceImage(resolver, "cache", service, &compose.LisstoConfig{}, prepare.ResolveOptions{
+			UnpinnedFallback: true,
+		})
+
+		Expect(err).To(HaveOccurred())
+	})
+})
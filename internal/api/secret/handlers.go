@@ -10,6 +10,7 @@ import (
 	"github.com/lissto-dev/api/internal/api/common"
 	"github.com/lissto-dev/api/internal/middleware"
 	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
 	"github.com/lissto-dev/api/pkg/k8s"
 	"github.com/lissto-dev/api/pkg/logging"
 	"github.com/lissto-dev/api/pkg/metadata"
@@ -25,6 +26,8 @@ type Handler struct {
 	authorizer *authz.Authorizer
 	nsManager  *authz.NamespaceManager
 	config     *controllerconfig.Config
+
+	maxValueLength int // Maximum value size in bytes (0 = unlimited)
 }
 
 // NewHandler creates a new secret handler
@@ -32,13 +35,16 @@ func NewHandler(
 	k8sClient *k8s.Client,
 	authorizer *authz.Authorizer,
 	nsManager *authz.NamespaceManager,
-	config *controllerconfig.Config,
+	cfg *controllerconfig.Config,
+	settings *config.Settings,
 ) *Handler {
 	return &Handler{
 		k8sClient:  k8sClient,
 		authorizer: authorizer,
 		nsManager:  nsManager,
-		config:     config,
+		config:     cfg,
+
+		maxValueLength: settings.Values.MaxLength,
 	}
 }
 
@@ -49,11 +55,15 @@ type CreateSecretRequest struct {
 	Env        string            `json:"env,omitempty"`        // required for scope=env
 	Repository string            `json:"repository,omitempty"` // required for scope=repo
 	Secrets    map[string]string `json:"secrets,omitempty"`    // key-value pairs to set initially
+	// Optional per-key content rules, e.g. {"PORT": {"pattern": "[0-9]+"}, "CONFIG": {"format": "json"}}
+	Validations map[string]common.ValueRule `json:"validations,omitempty"`
 }
 
 // SetSecretRequest represents a request to set/update secret values
 type SetSecretRequest struct {
 	Secrets map[string]string `json:"secrets" validate:"required"`
+	// Optional per-key content rules, see CreateSecretRequest
+	Validations map[string]common.ValueRule `json:"validations,omitempty"`
 }
 
 // SecretResponse represents a secret config response (write-only - no values)
@@ -108,6 +118,9 @@ func (h *Handler) CreateSecret(c echo.Context) error {
 	if req.Name == "" {
 		return c.String(400, "name is required")
 	}
+	if rejected, err := common.RejectInvalidValues(c, req.Secrets, h.maxValueLength, req.Validations); rejected {
+		return err
+	}
 
 	// Default scope to "env" if not specified
 	scope := req.Scope
@@ -142,6 +155,10 @@ func (h *Handler) CreateSecret(c echo.Context) error {
 		return c.String(403, fmt.Sprintf("Permission denied: %s", perm.Reason))
 	}
 
+	if rejected, err := common.RejectIfFrozen(c, h.nsManager, h.k8sClient, namespace, user.Name, "POST /secrets"); rejected {
+		return err
+	}
+
 	// Ensure namespace exists
 	if err := h.k8sClient.EnsureNamespace(c.Request().Context(), namespace); err != nil {
 		logging.Logger.Error("Failed to ensure namespace",
@@ -156,7 +173,8 @@ func (h *Handler) CreateSecret(c echo.Context) error {
 		logging.Logger.Error("Secret already exists",
 			zap.String("name", req.Name),
 			zap.String("namespace", namespace))
-		return c.String(409, fmt.Sprintf("Secret '%s' already exists", req.Name))
+		return c.JSON(409, common.NewConflictResponse(fmt.Sprintf("Secret '%s' already exists", req.Name),
+			fmt.Sprintf("%s/%s", namespace, req.Name), existing))
 	}
 
 	// Build labels for discovery
@@ -248,13 +266,15 @@ func (h *Handler) CreateSecret(c echo.Context) error {
 		zap.String("user", user.Name),
 		zap.Int("keys", len(keys)))
 
-	return c.JSON(201, SecretResponse{
-		ID:         fmt.Sprintf("%s/%s", namespace, req.Name),
-		Name:       req.Name,
-		Scope:      scope,
-		Env:        req.Env,
-		Repository: req.Repository,
-		Keys:       keys,
+	return common.HandleCreatedResponse(c, 201, &FormattableSecret{k8sObj: lisstoSecret, nsManager: h.nsManager}, func() error {
+		return c.JSON(201, SecretResponse{
+			ID:         fmt.Sprintf("%s/%s", namespace, req.Name),
+			Name:       req.Name,
+			Scope:      scope,
+			Env:        req.Env,
+			Repository: req.Repository,
+			Keys:       keys,
+		})
 	})
 }
 
@@ -276,15 +296,6 @@ func (h *Handler) GetSecrets(c echo.Context) error {
 		return c.String(500, "Failed to list secrets")
 	}
 
-	// Also list global secrets
-	globalNS := h.nsManager.GetGlobalNamespace()
-	globalList, err := h.k8sClient.ListLisstoSecrets(c.Request().Context(), globalNS)
-	if err != nil {
-		logging.Logger.Warn("Failed to list global secrets",
-			zap.String("namespace", globalNS),
-			zap.Error(err))
-	}
-
 	// Combine and convert to response format (keys only, no values)
 	var secrets []SecretResponse
 	for _, s := range secretList.Items {
@@ -299,8 +310,20 @@ func (h *Handler) GetSecrets(c echo.Context) error {
 			KeyUpdatedAt: metadata.GetKeyTimestamps(&s),
 		})
 	}
-	if globalList != nil {
-		for _, s := range globalList.Items {
+
+	// Also list shared namespaces the role reads (global unless disabled for the role)
+	for _, sharedNS := range h.authorizer.SharedReadNamespaces(user.Role) {
+		if sharedNS == namespace {
+			continue
+		}
+		sharedList, err := h.k8sClient.ListLisstoSecrets(c.Request().Context(), sharedNS)
+		if err != nil {
+			logging.Logger.Warn("Failed to list shared secrets",
+				zap.String("namespace", sharedNS),
+				zap.Error(err))
+			continue
+		}
+		for _, s := range sharedList.Items {
 			secrets = append(secrets, SecretResponse{
 				ID:           fmt.Sprintf("%s/%s", s.Namespace, s.Name),
 				Name:         s.Name,
@@ -347,9 +370,8 @@ func (h *Handler) GetSecret(c echo.Context) error {
 		zap.String("namespace", namespace))
 
 	// Check if user can access this namespace
-	globalNS := h.nsManager.GetGlobalNamespace()
 	userNS := h.nsManager.GetDeveloperNamespace(user.Name)
-	if namespace != userNS && namespace != globalNS {
+	if namespace != userNS && !h.authorizer.CanReadShared(user.Role, namespace) {
 		return c.String(403, "Cannot access secrets in other namespaces")
 	}
 
@@ -383,6 +405,9 @@ func (h *Handler) UpdateSecret(c echo.Context) error {
 		logging.Logger.Error("Request validation failed", zap.Error(err))
 		return c.String(400, err.Error())
 	}
+	if rejected, err := common.RejectInvalidValues(c, req.Secrets, h.maxValueLength, req.Validations); rejected {
+		return err
+	}
 
 	// Get scope from query params to determine namespace (like GetSecret)
 	scope := c.QueryParam("scope")
@@ -409,6 +434,10 @@ func (h *Handler) UpdateSecret(c echo.Context) error {
 		return c.String(403, fmt.Sprintf("Permission denied: %s", perm.Reason))
 	}
 
+	if rejected, err := common.RejectIfFrozen(c, h.nsManager, h.k8sClient, namespace, user.Name, "PUT /secrets/:id"); rejected {
+		return err
+	}
+
 	logging.Logger.Info("Secret update request",
 		zap.String("user", user.Name),
 		zap.String("id", id),
@@ -547,6 +576,10 @@ func (h *Handler) DeleteSecret(c echo.Context) error {
 		return c.String(403, fmt.Sprintf("Permission denied: %s", perm.Reason))
 	}
 
+	if rejected, err := common.RejectIfFrozen(c, h.nsManager, h.k8sClient, namespace, user.Name, "DELETE /secrets/:id"); rejected {
+		return err
+	}
+
 	logging.Logger.Info("Secret delete request",
 		zap.String("user", user.Name),
 		zap.String("id", id),
@@ -0,0 +1,72 @@
+package secret_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/secret"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/k8s"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+var _ = Describe("CreateSecret representation", func() {
+	create := func(target string) *httptest.ResponseRecorder {
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		nsManager := authz.NewNamespaceManager(cfg)
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		k8sClient := k8s.NewClientFromClient(fake.NewClientBuilder().WithScheme(scheme).Build(), scheme)
+		handler := secret.NewHandler(k8sClient, authz.NewAuthorizer(nsManager), nsManager, cfg, config.DefaultSettings())
+
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(`{"name":"db","env":"dev","secrets":{"PASSWORD":"hunter2"}}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.Set("user", &middleware.User{Name: "alice", Role: authz.User})
+		Expect(handler.CreateSecret(c)).To(Succeed())
+		return rec
+	}
+
+	It("should keep the legacy response by default", func() {
+		rec := create("/secrets")
+
+		Expect(rec.Code).To(Equal(201), rec.Body.String())
+		var response secret.SecretResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+		Expect(response.ID).To(Equal("dev-alice/db"))
+		Expect(response.Keys).To(ConsistOf("PASSWORD"))
+		Expect(response.KeyUpdatedAt).To(BeEmpty())
+	})
+
+	It("should return the stored secret without values with ?return=representation", func() {
+		rec := create("/secrets?return=representation")
+
+		Expect(rec.Code).To(Equal(201), rec.Body.String())
+		Expect(rec.Body.String()).NotTo(ContainSubstring("hunter2"))
+		var response secret.SecretResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+		Expect(response.ID).To(Equal("dev-alice/db"))
+		Expect(response.Keys).To(ConsistOf("PASSWORD"))
+		Expect(response.KeyUpdatedAt).To(HaveKey("PASSWORD"))
+	})
+
+	It("should return the detailed secret without values with ?format=detailed", func() {
+		rec := create("/secrets?return=representation&format=detailed")
+
+		Expect(rec.Code).To(Equal(201), rec.Body.String())
+		Expect(rec.Body.String()).NotTo(ContainSubstring("hunter2"))
+		Expect(rec.Body.String()).To(ContainSubstring(`"namespace":"alice"`))
+	})
+})
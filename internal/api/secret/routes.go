@@ -9,6 +9,7 @@ func RegisterRoutes(g *echo.Group, handler *Handler) {
 	g.POST("", handler.CreateSecret)
 	g.GET("", handler.GetSecrets)
 	g.GET("/:id", handler.GetSecret)
+	g.GET("/:id/scope-info", handler.GetSecretScopeInfo)
 	g.PUT("/:id", handler.UpdateSecret)
 	g.DELETE("/:id", handler.DeleteSecret)
 }
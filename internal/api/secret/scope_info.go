@@ -0,0 +1,84 @@
+package secret
+
+import (
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+// GetSecretScopeInfo handles GET /secrets/:id/scope-info
+// Reports where the secret applies and which same-named secrets in the user's
+// namespace or the global namespace override it or are overridden by it
+func (h *Handler) GetSecretScopeInfo(c echo.Context) error {
+	user, _ := middleware.GetUserFromContext(c)
+	id := c.Param("id")
+
+	// Resolve the secret like GetSecret does
+	scope := c.QueryParam("scope")
+	if scope == "" {
+		scope = "env" // default
+	}
+	namespace, err := h.authorizer.ResolveNamespaceForScope(user.Role, user.Name, scope)
+	if err != nil {
+		return c.String(400, err.Error())
+	}
+	_, name, err := parseSecretID(id, namespace)
+	if err != nil {
+		return c.String(400, err.Error())
+	}
+
+	globalNS := h.nsManager.GetGlobalNamespace()
+	userNS := h.nsManager.GetDeveloperNamespace(user.Name)
+	if namespace != userNS && !h.authorizer.CanReadShared(user.Role, namespace) {
+		return c.String(403, "Cannot access secrets in other namespaces")
+	}
+
+	ctx := c.Request().Context()
+	lisstoSecret, err := h.k8sClient.GetLisstoSecret(ctx, namespace, name)
+	if err != nil {
+		return c.String(404, fmt.Sprintf("Secret '%s' not found", name))
+	}
+
+	// Same-named secrets can only come from the namespaces the controller merges from
+	// Global ones are left out when the role can't read global
+	namespaces := []string{userNS}
+	if globalNS != userNS && h.authorizer.CanReadGlobal(user.Role) {
+		namespaces = append(namespaces, globalNS)
+	}
+	var sameNamed []common.ScopedConfig
+	for _, ns := range namespaces {
+		list, err := h.k8sClient.ListLisstoSecrets(ctx, ns)
+		if err != nil {
+			logging.Logger.Error("Failed to list secrets",
+				zap.String("namespace", ns),
+				zap.Error(err))
+			return c.String(500, "Failed to list secrets")
+		}
+		for i := range list.Items {
+			if list.Items[i].Name == name {
+				sameNamed = append(sameNamed, scopedSecret(&list.Items[i]))
+			}
+		}
+	}
+
+	return c.JSON(200, common.ComputeScopeInfo(scopedSecret(lisstoSecret), sameNamed))
+}
+
+// scopedSecret describes a secret for scope computation (key names only)
+func scopedSecret(lisstoSecret *envv1alpha1.LisstoSecret) common.ScopedConfig {
+	response := extractSecretResponse(lisstoSecret)
+	return common.ScopedConfig{
+		ID:         response.ID,
+		Name:       response.Name,
+		Scope:      response.Scope,
+		Env:        response.Env,
+		Repository: response.Repository,
+		Keys:       response.Keys,
+	}
+}
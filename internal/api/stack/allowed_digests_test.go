@@ -0,0 +1,84 @@
+package stack
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+var _ = Describe("Update stack with a digest allowlist", func() {
+	var (
+		approved   = "sha256:" + strings.Repeat("a", 64)
+		unapproved = "sha256:" + strings.Repeat("b", 64)
+		h          *Handler
+		alice      *middleware.User
+	)
+
+	BeforeEach(func() {
+		alice = &middleware.User{Name: "alice", Role: authz.User}
+		h = newTestHandler(config.DefaultSettings(), nil,
+			&envv1alpha1.Blueprint{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "dev-alice"},
+				Spec: envv1alpha1.BlueprintSpec{DockerCompose: "x-lissto:\n  allowedDigests:\n    registry.acme.io/web:\n      - " +
+					approved + "\nservices:\n  web:\n    image: registry.acme.io/web:1.0\n"},
+			},
+			&envv1alpha1.Stack{
+				ObjectMeta: metav1.ObjectMeta{Name: "web-stack", Namespace: "dev-alice"},
+				Spec: envv1alpha1.StackSpec{
+					BlueprintReference: "alice/web",
+					Env:                "dev",
+					Images:             map[string]envv1alpha1.ImageInfo{"web": {Image: "registry.acme.io/web:1.0", Digest: "registry.acme.io/web@" + approved}},
+				},
+			})
+	})
+
+	update := func(digest string) (int, string) {
+		c, rec := newTestContext(http.MethodPut, "/stacks/alice/web-stack",
+			`{"images":{"web":{"digest":"registry.acme.io/web@`+digest+`","image":"registry.acme.io/web:1.1"}}}`, alice)
+		c.SetParamNames("id")
+		c.SetParamValues("alice/web-stack")
+		Expect(h.UpdateStack(c)).To(Succeed())
+		return rec.Code, rec.Body.String()
+	}
+
+	It("should reject a digest missing from the blueprint's allowlist", func() {
+		code, body := update(unapproved)
+
+		Expect(code).To(Equal(422))
+		Expect(body).To(ContainSubstring("Service web"))
+		stack, err := h.k8sClient.GetStack(context.Background(), "dev-alice", "web-stack")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stack.Spec.Images["web"].Digest).To(Equal("registry.acme.io/web@" + approved))
+	})
+
+	It("should apply an approved digest", func() {
+		code, _ := update(approved)
+
+		Expect(code).To(Equal(200))
+		stack, err := h.k8sClient.GetStack(context.Background(), "dev-alice", "web-stack")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stack.Spec.Images["web"].Image).To(Equal("registry.acme.io/web:1.1"))
+	})
+
+	It("should reject updates when the blueprint holding the allowlist is missing", func() {
+		blueprint, err := h.k8sClient.GetBlueprint(context.Background(), "dev-alice", "web")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(h.k8sClient.Delete(context.Background(), blueprint)).To(Succeed())
+
+		code, _ := update(unapproved)
+
+		Expect(code).To(Equal(500))
+		stack, err := h.k8sClient.GetStack(context.Background(), "dev-alice", "web-stack")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stack.Spec.Images["web"].Digest).To(Equal("registry.acme.io/web@" + approved))
+	})
+})
@@ -0,0 +1,50 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lissto-dev/api/pkg/compose"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+// BlueprintsAnnotation lists the comma-separated blueprint references merged into a stack
+// Spec.BlueprintReference holds the first of them
+const BlueprintsAnnotation = "lissto.dev/blueprints"
+
+// stackBlueprints returns the blueprint references a stack was created from, none for inline compose
+func stackBlueprints(stack *envv1alpha1.Stack) []string {
+	if merged := stack.Annotations[BlueprintsAnnotation]; merged != "" {
+		return strings.Split(merged, ",")
+	}
+	if stack.Spec.BlueprintReference != "" {
+		return []string{stack.Spec.BlueprintReference}
+	}
+	return nil
+}
+
+// stackCompose loads the compose of a stack's blueprints, merging them when there are several
+func (h *Handler) stackCompose(ctx context.Context, stack *envv1alpha1.Stack) (string, error) {
+	references := stackBlueprints(stack)
+	if len(references) == 0 {
+		return "", errInlineStack
+	}
+
+	files := make([]compose.ComposeFile, 0, len(references))
+	for _, reference := range references {
+		blueprintNamespace, blueprintName, err := h.nsManager.ParseScopedIDWithDefault(reference, stack.Namespace)
+		if err != nil {
+			return "", fmt.Errorf("invalid blueprint reference: %w", err)
+		}
+		blueprint, err := h.k8sClient.GetBlueprint(ctx, blueprintNamespace, blueprintName)
+		if err != nil {
+			return "", fmt.Errorf("failed to get blueprint %s: %w", reference, err)
+		}
+		files = append(files, compose.ComposeFile{Name: reference, Content: blueprint.Spec.DockerCompose})
+	}
+	if len(files) == 1 {
+		return files[0].Content, nil
+	}
+	return compose.MergeComposeFiles(files)
+}
@@ -0,0 +1,85 @@
+package stack
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/config"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+var _ = Describe("Create stack from merged blueprints", func() {
+	const (
+		infraCompose = "services:\n  postgres:\n    image: postgres:16\n"
+		appCompose   = "services:\n  web:\n    image: web\n"
+	)
+
+	var (
+		h     *Handler
+		alice *middleware.User
+	)
+
+	BeforeEach(func() {
+		alice = &middleware.User{Name: "alice", Role: authz.User}
+		h = newTestHandler(config.DefaultSettings(), nil,
+			&envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}},
+			&envv1alpha1.Blueprint{
+				ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "dev-alice"},
+				Spec:       envv1alpha1.BlueprintSpec{DockerCompose: infraCompose},
+			},
+			&envv1alpha1.Blueprint{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "dev-alice"},
+				Spec:       envv1alpha1.BlueprintSpec{DockerCompose: appCompose},
+			})
+		h.cache = cache.NewMemoryCache()
+
+		merged, err := compose.MergeComposeFiles([]compose.ComposeFile{
+			{Name: "alice/postgres", Content: infraCompose},
+			{Name: "alice/app", Content: appCompose},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(h.cache.Set(context.Background(), "req-1", cache.PrepareResultCache{
+			Namespace: "dev-alice",
+			Images: map[string]cache.ImageInfoCache{
+				"postgres": {Digest: "postgres@sha256:aaa", Image: "postgres:16"},
+				"web":      {Digest: "registry.io/web@sha256:bbb", Image: "registry.io/web:main"},
+			},
+			Compose:    merged,
+			Blueprints: []string{"alice/postgres", "alice/app"},
+		}, time.Minute)).To(Succeed())
+	})
+
+	It("should deploy the merged services as one stack and render it again", func() {
+		c, rec := newTestContext(http.MethodPost, "/stacks", `{"env":"dev","request_id":"req-1"}`, alice)
+		Expect(h.CreateStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(201), rec.Body.String())
+
+		stack, err := h.k8sClient.GetStack(context.Background(), "dev-alice", strings.TrimPrefix(rec.Body.String(), "alice/"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stack.Spec.BlueprintReference).To(Equal("alice/postgres"))
+		Expect(stack.Annotations).To(HaveKeyWithValue(BlueprintsAnnotation, "alice/postgres,alice/app"))
+		Expect(stack.Spec.Images).To(HaveKey("postgres"))
+		Expect(stack.Spec.Images).To(HaveKey("web"))
+
+		rendered, err := h.renderStack(context.Background(), stack)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rendered.Project.ServiceNames()).To(ConsistOf("postgres", "web"))
+	})
+
+	It("should reject a blueprint for a merged request ID", func() {
+		c, rec := newTestContext(http.MethodPost, "/stacks", `{"blueprint":"alice/app","env":"dev","request_id":"req-1"}`, alice)
+		Expect(h.CreateStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(400))
+		Expect(rec.Body.String()).To(ContainSubstring("several blueprints"))
+	})
+})
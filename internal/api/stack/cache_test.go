@@ -0,0 +1,73 @@
+package stack
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/config"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+// failingCache simulates a shared cache backend that is unreachable
+type failingCache struct {
+	gets atomic.Int32
+}
+
+func (f *failingCache) Set(context.Context, string, interface{}, time.Duration) error {
+	return errors.New("dial tcp 10.0.0.5:6379: connection refused")
+}
+
+func (f *failingCache) Get(context.Context, string, interface{}) error {
+	f.gets.Add(1)
+	return errors.New("dial tcp 10.0.0.5:6379: connection refused")
+}
+
+func (f *failingCache) Delete(context.Context, string) error {
+	return errors.New("dial tcp 10.0.0.5:6379: connection refused")
+}
+
+var _ = Describe("Create stack cache failures", func() {
+	var (
+		h     *Handler
+		alice *middleware.User
+	)
+
+	BeforeEach(func() {
+		originalBackoff := cacheGetBackoff
+		cacheGetBackoff = time.Millisecond
+		DeferCleanup(func() { cacheGetBackoff = originalBackoff })
+
+		alice = &middleware.User{Name: "alice", Role: authz.User}
+		env := &envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}}
+		h = newTestHandler(config.DefaultSettings(), nil, env)
+	})
+
+	It("should return 503 after retrying when the cache backend errors", func() {
+		backend := &failingCache{}
+		h.cache = backend
+
+		c, rec := newTestContext(http.MethodPost, "/stacks", `{"blueprint":"alice/web","env":"dev","request_id":"req-1"}`, alice)
+		Expect(h.CreateStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(503))
+		Expect(backend.gets.Load()).To(Equal(int32(cacheGetAttempts)))
+	})
+
+	It("should return 400 without retrying for a genuine miss", func() {
+		h.cache = cache.NewMemoryCache()
+
+		c, rec := newTestContext(http.MethodPost, "/stacks", `{"blueprint":"alice/web","env":"dev","request_id":"unknown"}`, alice)
+		Expect(h.CreateStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(400))
+		Expect(rec.Body.String()).To(ContainSubstring("Invalid or expired request ID"))
+	})
+})
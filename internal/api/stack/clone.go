@@ -0,0 +1,207 @@
+package stack
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/logging"
+	"github.com/lissto-dev/api/pkg/notify"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+// ClonedFromAnnotation records the scoped ID of the stack a stack was cloned from
+const ClonedFromAnnotation = "lissto.dev/cloned-from"
+
+// CloneStack handles POST /stacks/:id/clone
+// Creates a stack in the caller's namespace for another env from the source stack's blueprint and
+// resolved images, without preparing again: only the exposed hostnames change with the env
+func (h *Handler) CloneStack(c echo.Context) error {
+	idParam := c.Param("id")
+	user, _ := middleware.GetUserFromContext(c)
+	endpoint := fmt.Sprintf("POST /stacks/%s/clone", idParam)
+
+	var req common.CloneStackRequest
+	if err := c.Bind(&req); err != nil {
+		return c.String(400, "Invalid request")
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.String(400, err.Error())
+	}
+	if len(req.Description) > maxDescriptionLength {
+		return c.String(400, fmt.Sprintf("Description must be at most %d characters", maxDescriptionLength))
+	}
+	if err := validateTags(req.Tags); err != nil {
+		return c.String(400, err.Error())
+	}
+
+	// Locate the source stack with read access
+	allowedNS := h.authorizer.GetAllowedNamespaces(user.Role, authz.ActionRead, authz.ResourceStack, user.Name)
+	if len(allowedNS) == 0 {
+		return c.String(403, "Permission denied: no accessible namespaces")
+	}
+
+	// Resolve namespace from ID
+	targetNamespace, name, searchAll := h.nsManager.ResolveNamespaceFromID(idParam, allowedNS)
+
+	userNS := h.nsManager.GetDeveloperNamespace(user.Name)
+	globalNS := h.nsManager.GetGlobalNamespace()
+	source, found := h.findStack(c, targetNamespace, name, searchAll, userNS, globalNS, allowedNS)
+	if !found {
+		return c.String(404, fmt.Sprintf("Stack '%s' not found", idParam))
+	}
+	if source.Spec.BlueprintReference == "" {
+		return c.String(409, fmt.Sprintf("Cannot clone stack '%s': %v", idParam, errInlineStack))
+	}
+
+	// The clone is always created in the caller's namespace, for one of the caller's envs
+	namespace := userNS
+	env, err := h.k8sClient.GetEnv(c.Request().Context(), namespace, req.Env)
+	if err != nil {
+		logging.Logger.Error("Failed to get env",
+			zap.String("env", req.Env),
+			zap.String("namespace", namespace),
+			zap.Error(err))
+		return c.String(404, fmt.Sprintf("Env '%s' not found", req.Env))
+	}
+
+	perm := h.authorizer.CanAccess(user.Role, authz.ActionCreate, authz.ResourceStack, namespace, user.Name)
+	if !perm.Allowed {
+		logging.LogDeniedWithIP("insufficient_permissions", user.Name, endpoint, c.RealIP())
+		return c.String(403, fmt.Sprintf("Permission denied: %s", perm.Reason))
+	}
+
+	if rejected, err := common.RejectIfFrozen(c, h.nsManager, h.k8sClient, namespace, user.Name, endpoint); rejected {
+		return err
+	}
+
+	// A reference without namespace is relative to the source stack; pin it for the new namespace
+	blueprintNamespace, blueprintName, err := h.nsManager.ParseScopedIDWithDefault(source.Spec.BlueprintReference, source.Namespace)
+	if err != nil {
+		return c.String(400, fmt.Sprintf("Invalid blueprint reference on stack '%s': %v", idParam, err))
+	}
+
+	// Merged blueprints are pinned the same way
+	var mergedBlueprints []string
+	if source.Annotations[BlueprintsAnnotation] != "" {
+		for _, reference := range stackBlueprints(source) {
+			namespace, name, err := h.nsManager.ParseScopedIDWithDefault(reference, source.Namespace)
+			if err != nil {
+				return c.String(400, fmt.Sprintf("Invalid blueprint reference on stack '%s': %v", idParam, err))
+			}
+			mergedBlueprints = append(mergedBlueprints, h.nsManager.MustGenerateScopedID(namespace, name))
+		}
+	}
+
+	stackName := common.GenerateStackName("", "")
+	images := make(map[string]envv1alpha1.ImageInfo, len(source.Spec.Images))
+	for service, info := range source.Spec.Images {
+		images[service] = envv1alpha1.ImageInfo{Digest: info.Digest, Image: info.Image}
+	}
+	stack := &envv1alpha1.Stack{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      stackName,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "lissto",
+			},
+			Annotations: map[string]string{
+				"lissto.dev/blueprint-title": source.Annotations["lissto.dev/blueprint-title"],
+				"lissto.dev/created-by":      user.Name,
+				ClonedFromAnnotation:         h.nsManager.MustGenerateScopedID(source.Namespace, source.Name),
+			},
+		},
+		Spec: envv1alpha1.StackSpec{
+			BlueprintReference:    h.nsManager.MustGenerateScopedID(blueprintNamespace, blueprintName),
+			Env:                   env.Name,
+			ManifestsConfigMapRef: manifestsConfigMapName(stackName),
+			Images:                images,
+		},
+	}
+	if len(mergedBlueprints) > 0 {
+		stack.Annotations[BlueprintsAnnotation] = strings.Join(mergedBlueprints, ",")
+	}
+	description, tags := req.Description, req.Tags
+	if description == "" {
+		description = source.Annotations[DescriptionAnnotation]
+	}
+	if tags == nil {
+		tags = stackTags(source)
+	}
+	applyDescriptionAndTags(stack, description, tags)
+	applyPropagation(stack, envPropagation(env))
+
+	// Render with the source's digests: no registry lookups, the new env only changes hostnames
+	project, err := h.loadStackProject(c.Request().Context(), stack)
+	var rendered *renderedStack
+	if err == nil {
+		// The source may use labels the caller's role may not; strip (or reject) them before rendering
+		if _, rejected, err := prepare.EnforceLabelPolicy(c, user.Role, h.settings.Roles, project); rejected {
+			return err
+		}
+		rendered, err = h.renderStackProject(c.Request().Context(), stack, project)
+	}
+	if err != nil {
+		logging.Logger.Error("Failed to render cloned stack",
+			zap.String("source", idParam),
+			zap.String("blueprint", stack.Spec.BlueprintReference),
+			zap.String("env", env.Name),
+			zap.Error(err))
+		if errors.Is(err, errInlineStack) {
+			return c.String(409, fmt.Sprintf("Cannot clone stack '%s': %v", idParam, err))
+		}
+		return c.String(500, "Failed to generate Kubernetes manifests")
+	}
+	for service, url := range rendered.URLs {
+		info := stack.Spec.Images[service]
+		info.URL = url
+		stack.Spec.Images[service] = info
+	}
+
+	if rejected, err := common.RejectPolicyViolations(c, rendered.Project, h.settings.Compose.DeniedFeatures); rejected {
+		return err
+	}
+	if rejected, err := h.checkObjectCount(c, stack.Spec.BlueprintReference, rendered.ObjectCount); rejected {
+		return err
+	}
+	if len(rendered.Manifests) > maxManifestsSize {
+		return c.String(400, "Generated manifests exceed 1MB size limit")
+	}
+
+	unlock, rejected, err := h.lockNamespace(c, namespace)
+	if rejected {
+		return err
+	}
+	defer unlock()
+
+	if err := h.k8sClient.EnsureNamespace(c.Request().Context(), namespace); err != nil {
+		logging.Logger.Error("Failed to create namespace",
+			zap.String("namespace", namespace),
+			zap.Error(err))
+		return c.String(500, "Failed to create namespace")
+	}
+	if message, err := h.createStackWithManifests(c.Request().Context(), stack, rendered.Manifests); err != nil {
+		return c.String(500, message)
+	}
+
+	logging.Logger.Info("Stack cloned successfully",
+		zap.String("source", stack.Annotations[ClonedFromAnnotation]),
+		zap.String("stack_name", stackName),
+		zap.String("namespace", namespace),
+		zap.String("env", env.Name),
+		zap.String("user", user.Name))
+
+	identifier := h.nsManager.MustGenerateScopedID(namespace, stackName)
+	h.notifier.Notify(notify.NewStackEvent(notify.EventStackCreated, identifier, stack, user.Name))
+	return common.HandleCreatedResponse(c, 201, &FormattableStack{k8sObj: stack, nsManager: h.nsManager}, func() error {
+		return c.String(201, identifier)
+	})
+}
@@ -0,0 +1,149 @@
+package stack
+
+import (
+	"context"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/preprocessor"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+var _ = Describe("CloneStack", func() {
+	const compose = `
+services:
+  web:
+    image: nginx
+    ports:
+      - "8080:80"
+    labels:
+      lissto.dev/expose: "true"
+  db:
+    image: postgres:16
+`
+
+	var (
+		h     *Handler
+		alice *middleware.User
+	)
+
+	BeforeEach(func() {
+		alice = &middleware.User{Name: "alice", Role: authz.User}
+		source := &envv1alpha1.Stack{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "source",
+				Namespace: "dev-alice",
+				Labels:    map[string]string{TagLabelPrefix + "feature-x": "true"},
+				Annotations: map[string]string{
+					"lissto.dev/created-by":      "alice",
+					"lissto.dev/blueprint-title": "Web",
+				},
+			},
+			Spec: envv1alpha1.StackSpec{
+				BlueprintReference:    "alice/web-bp",
+				Env:                   "dev",
+				ManifestsConfigMapRef: "lissto-source",
+				Images: map[string]envv1alpha1.ImageInfo{
+					"web": {Digest: "nginx@sha256:abc", Image: "nginx:latest", URL: "web-dev.dev.example.com"},
+					"db":  {Digest: "postgres@sha256:def", Image: "postgres:16"},
+				},
+			},
+		}
+		blueprint := &envv1alpha1.Blueprint{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-bp", Namespace: "dev-alice"},
+			Spec:       envv1alpha1.BlueprintSpec{DockerCompose: compose},
+		}
+		dev := &envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}}
+		staging := &envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "staging", Namespace: "dev-alice"}}
+
+		h = newTestHandler(config.DefaultSettings(), nil, source, blueprint, dev, staging)
+		h.exposePreprocessor = preprocessor.NewExposePreprocessor(
+			&preprocessor.IngressConfig{IngressClass: "nginx", HostSuffix: ".dev.example.com", TLSSecret: "dev-tls"}, nil)
+	})
+
+	clone := func(body string) (int, string) {
+		c, rec := newTestContext(http.MethodPost, "/stacks/source/clone", body, alice)
+		c.SetParamNames("id")
+		c.SetParamValues("source")
+		Expect(h.CloneStack(c)).To(Succeed())
+		return rec.Code, rec.Body.String()
+	}
+
+	It("should reuse the source images with the new env's hostnames", func() {
+		code, body := clone(`{"env":"staging"}`)
+		Expect(code).To(Equal(201), body)
+
+		stacks, err := h.k8sClient.ListStacks(context.Background(), "dev-alice")
+		Expect(err).NotTo(HaveOccurred())
+		var cloned *envv1alpha1.Stack
+		for i := range stacks.Items {
+			if "alice/"+stacks.Items[i].Name == body {
+				cloned = &stacks.Items[i]
+			}
+		}
+		Expect(cloned).NotTo(BeNil())
+
+		Expect(cloned.Spec.Env).To(Equal("staging"))
+		Expect(cloned.Spec.BlueprintReference).To(Equal("alice/web-bp"))
+		Expect(cloned.Spec.Images).To(Equal(map[string]envv1alpha1.ImageInfo{
+			"web": {Digest: "nginx@sha256:abc", Image: "nginx:latest", URL: "web-staging.dev.example.com"},
+			"db":  {Digest: "postgres@sha256:def", Image: "postgres:16"},
+		}))
+		Expect(cloned.Annotations).To(HaveKeyWithValue(ClonedFromAnnotation, "alice/source"))
+		Expect(stackTags(cloned)).To(Equal([]string{"feature-x"}))
+
+		configMap, err := h.k8sClient.GetConfigMap(context.Background(), "dev-alice", cloned.Spec.ManifestsConfigMapRef)
+		Expect(err).NotTo(HaveOccurred())
+		manifests := configMap.Data["manifests.yaml"]
+		Expect(manifests).To(ContainSubstring("nginx@sha256:abc"))
+		Expect(manifests).To(ContainSubstring("postgres@sha256:def"))
+		Expect(manifests).To(ContainSubstring("web-staging.dev.example.com"))
+		Expect(manifests).NotTo(ContainSubstring("web-dev.dev.example.com"))
+		Expect(configMap.OwnerReferences).To(HaveLen(1))
+	})
+
+	It("should require an existing env of the caller", func() {
+		code, _ := clone(`{"env":"prod"}`)
+		Expect(code).To(Equal(404))
+	})
+
+	It("should require the env", func() {
+		code, _ := clone(`{}`)
+		Expect(code).To(Equal(400))
+	})
+
+	It("should reject cloning a stack using labels denied to the caller's role", func() {
+		h.settings.Roles = map[string]config.RoleSettings{"user": {DeniedLabels: []string{"lissto.dev/expose"}}}
+
+		code, body := clone(`{"env":"staging"}`)
+		Expect(code).To(Equal(400), body)
+		Expect(body).To(ContainSubstring("lissto.dev/expose"))
+
+		stacks, err := h.k8sClient.ListStacks(context.Background(), "dev-alice")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stacks.Items).To(HaveLen(1))
+	})
+
+	It("should strip labels denied to the caller's role before rendering the clone", func() {
+		h.settings.Roles = map[string]config.RoleSettings{"user": {
+			DeniedLabels:      []string{"lissto.dev/expose"},
+			OnDisallowedLabel: config.LabelPolicyStrip,
+		}}
+
+		code, body := clone(`{"env":"staging"}`)
+		Expect(code).To(Equal(201), body)
+
+		cloned, err := h.k8sClient.GetStack(context.Background(), "dev-alice", body[len("alice/"):])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cloned.Spec.Images["web"].URL).To(BeEmpty())
+		configMap, err := h.k8sClient.GetConfigMap(context.Background(), "dev-alice", cloned.Spec.ManifestsConfigMapRef)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(configMap.Data["manifests.yaml"]).NotTo(ContainSubstring("web-staging.dev.example.com"))
+	})
+})
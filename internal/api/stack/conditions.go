@@ -0,0 +1,116 @@
+package stack
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+// Stack reconcile phases derived from the controller's status
+const (
+	PhasePending     = "pending"     // Not reconciled yet (no conditions)
+	PhaseReconciling = "reconciling" // Spec changed since the last reconcile
+	PhaseReady       = "ready"
+	PhaseError       = "error"
+)
+
+// StackCondition is a status condition written by the Stack controller
+type StackCondition struct {
+	Type               string `json:"type"`
+	Status             string `json:"status"`
+	Reason             string `json:"reason,omitempty"`
+	Message            string `json:"message,omitempty"`
+	LastTransitionTime string `json:"last_transition_time,omitempty"`
+}
+
+// StackConditionsResponse describes the reconcile status of a stack
+type StackConditionsResponse struct {
+	ID                 string           `json:"id"`
+	Phase              string           `json:"phase"`
+	Generation         int64            `json:"generation"`
+	ObservedGeneration int64            `json:"observed_generation"`
+	Conditions         []StackCondition `json:"conditions"`
+}
+
+// stackPhase summarizes the controller's conditions
+// Ready=True for the current generation is ready; a failed Ready or resource condition is an error
+func stackPhase(stack *envv1alpha1.Stack) string {
+	conditions := stack.Status.Conditions
+	if len(conditions) == 0 {
+		return PhasePending
+	}
+	if stack.Status.ObservedGeneration < stack.Generation {
+		return PhaseReconciling
+	}
+	for _, condition := range conditions {
+		if condition.Status == metav1.ConditionFalse && condition.Type != "Ready" && condition.Reason == "Failed" {
+			return PhaseError
+		}
+	}
+
+	ready := meta.FindStatusCondition(conditions, "Ready")
+	switch {
+	case ready == nil, ready.Status == metav1.ConditionUnknown:
+		return PhaseReconciling
+	case ready.Status == metav1.ConditionTrue:
+		return PhaseReady
+	default:
+		return PhaseError
+	}
+}
+
+// stackConditions converts the controller's conditions for responses
+func stackConditions(stack *envv1alpha1.Stack) []StackCondition {
+	conditions := make([]StackCondition, 0, len(stack.Status.Conditions))
+	for _, condition := range stack.Status.Conditions {
+		converted := StackCondition{
+			Type:    condition.Type,
+			Status:  string(condition.Status),
+			Reason:  condition.Reason,
+			Message: condition.Message,
+		}
+		if !condition.LastTransitionTime.IsZero() {
+			converted.LastTransitionTime = condition.LastTransitionTime.Format(time.RFC3339)
+		}
+		conditions = append(conditions, converted)
+	}
+	return conditions
+}
+
+// GetStackConditions handles GET /stacks/:id/conditions
+func (h *Handler) GetStackConditions(c echo.Context) error {
+	idParam := c.Param("id")
+	user, _ := middleware.GetUserFromContext(c)
+
+	// Get allowed namespaces for authorization
+	allowedNS := h.authorizer.GetAllowedNamespaces(user.Role, authz.ActionRead, authz.ResourceStack, user.Name)
+	if len(allowedNS) == 0 {
+		return c.String(403, "Permission denied: no accessible namespaces")
+	}
+
+	// Resolve namespace from ID
+	targetNamespace, name, searchAll := h.nsManager.ResolveNamespaceFromID(idParam, allowedNS)
+
+	// Try to find the stack
+	userNS := h.nsManager.GetDeveloperNamespace(user.Name)
+	globalNS := h.nsManager.GetGlobalNamespace()
+	stack, found := h.findStack(c, targetNamespace, name, searchAll, userNS, globalNS, allowedNS)
+	if !found {
+		return c.String(404, fmt.Sprintf("Stack '%s' not found", idParam))
+	}
+
+	return c.JSON(200, StackConditionsResponse{
+		ID:                 h.nsManager.MustGenerateScopedID(stack.Namespace, stack.Name),
+		Phase:              stackPhase(stack),
+		Generation:         stack.Generation,
+		ObservedGeneration: stack.Status.ObservedGeneration,
+		Conditions:         stackConditions(stack),
+	})
+}
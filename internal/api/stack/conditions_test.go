@@ -0,0 +1,110 @@
+package stack
+
+import (
+	"encoding/json"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+var _ = Describe("Stack conditions", func() {
+	var (
+		h     *Handler
+		alice *middleware.User
+	)
+
+	BeforeEach(func() {
+		alice = &middleware.User{Name: "alice", Role: authz.User}
+		ready := &envv1alpha1.Stack{
+			ObjectMeta: metav1.ObjectMeta{Name: "ready", Namespace: "dev-alice", Generation: 2},
+			Status: envv1alpha1.StackStatus{
+				ObservedGeneration: 2,
+				Conditions: []metav1.Condition{{
+					Type:               "Ready",
+					Status:             metav1.ConditionTrue,
+					Reason:             "Reconciled",
+					Message:            "All resources applied",
+					LastTransitionTime: metav1.Now(),
+				}},
+			},
+		}
+		failed := &envv1alpha1.Stack{
+			ObjectMeta: metav1.ObjectMeta{Name: "failed", Namespace: "dev-alice", Generation: 1},
+			Status: envv1alpha1.StackStatus{
+				ObservedGeneration: 1,
+				Conditions: []metav1.Condition{{
+					Type:    "Ready",
+					Status:  metav1.ConditionFalse,
+					Reason:  "ApplyFailed",
+					Message: "deployment web: invalid spec",
+				}},
+			},
+		}
+		pending := &envv1alpha1.Stack{ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "dev-alice"}}
+		other := &envv1alpha1.Stack{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "dev-bob"}}
+		h = newTestHandler(config.DefaultSettings(), nil, ready, failed, pending, other)
+	})
+
+	getConditions := func(id string) (int, StackConditionsResponse) {
+		c, rec := newTestContext(http.MethodGet, "/stacks/"+id+"/conditions", "", alice)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		Expect(h.GetStackConditions(c)).To(Succeed())
+		var resp StackConditionsResponse
+		if rec.Code == http.StatusOK {
+			Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		}
+		return rec.Code, resp
+	}
+
+	It("should return the conditions written by the controller", func() {
+		code, resp := getConditions("ready")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(resp.ID).To(Equal("alice/ready"))
+		Expect(resp.Phase).To(Equal(PhaseReady))
+		Expect(resp.ObservedGeneration).To(Equal(int64(2)))
+		Expect(resp.Conditions).To(HaveLen(1))
+		Expect(resp.Conditions[0].Type).To(Equal("Ready"))
+		Expect(resp.Conditions[0].Status).To(Equal("True"))
+		Expect(resp.Conditions[0].Message).To(Equal("All resources applied"))
+		Expect(resp.Conditions[0].LastTransitionTime).NotTo(BeEmpty())
+	})
+
+	It("should report a failed reconcile as an error", func() {
+		code, resp := getConditions("failed")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(resp.Phase).To(Equal(PhaseError))
+		Expect(resp.Conditions[0].Reason).To(Equal("ApplyFailed"))
+	})
+
+	It("should report a stack without status as pending", func() {
+		code, resp := getConditions("pending")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(resp.Phase).To(Equal(PhasePending))
+		Expect(resp.Conditions).To(BeEmpty())
+	})
+
+	It("should not expose stacks outside the user's namespaces", func() {
+		code, _ := getConditions("bob/other")
+		Expect(code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should include conditions in the stack detail response", func() {
+		c, rec := newTestContext(http.MethodGet, "/stacks/ready", "", alice)
+		c.SetParamNames("id")
+		c.SetParamValues("ready")
+		Expect(h.GetStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		var resp StackResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.Phase).To(Equal(PhaseReady))
+		Expect(resp.Conditions).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,69 @@
+package stack
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+var _ = Describe("Stack delete response", func() {
+	var (
+		h     *Handler
+		alice *middleware.User
+	)
+
+	BeforeEach(func() {
+		alice = &middleware.User{Name: "alice", Role: authz.User}
+		exposed := &envv1alpha1.Stack{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "dev-alice"},
+			Spec: envv1alpha1.StackSpec{Images: map[string]envv1alpha1.ImageInfo{
+				"web":    {Digest: "web@sha256:abc", URL: "web-dev.dev.example.com"},
+				"admin":  {Digest: "admin@sha256:def", URL: "admin-dev.dev.example.com"},
+				"worker": {Digest: "worker@sha256:123"},
+			}},
+		}
+		h = newTestHandler(config.DefaultSettings(), nil, exposed)
+	})
+
+	deleteStack := func(accept string) *http.Response {
+		c, rec := newTestContext(http.MethodDelete, "/stacks/web", "", alice)
+		if accept != "" {
+			c.Request().Header.Set(echo.HeaderAccept, accept)
+		}
+		c.SetParamNames("id")
+		c.SetParamValues("web")
+		Expect(h.DeleteStack(c)).To(Succeed())
+		return rec.Result()
+	}
+
+	It("should report the exposed URLs to JSON clients", func() {
+		resp := deleteStack("application/json; charset=utf-8")
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(200))
+		var body common.StackDeletedResponse
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		Expect(body.ID).To(Equal("alice/web"))
+		Expect(body.Exposed).To(Equal([]common.ExposedServiceInfo{
+			{Service: "admin", URL: "admin-dev.dev.example.com"},
+			{Service: "web", URL: "web-dev.dev.example.com"},
+		}))
+	})
+
+	It("should keep the 204 for plain clients", func() {
+		resp := deleteStack("")
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+	})
+})
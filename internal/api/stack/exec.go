@@ -0,0 +1,181 @@
+package stack
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/k8s"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+// ExecFrame is a single NDJSON line streamed back by the exec endpoint
+type ExecFrame struct {
+	Stream string `json:"stream"`          // "stdout", "stderr" or "exit"
+	Data   string `json:"data,omitempty"`  // Output chunk
+	Error  string `json:"error,omitempty"` // Set on the exit frame if the command failed
+}
+
+// ExecStack handles POST /stacks/:id/exec
+func (h *Handler) ExecStack(c echo.Context) error {
+	idParam := c.Param("id")
+	user, _ := middleware.GetUserFromContext(c)
+
+	if !h.settings.Exec.Enabled || h.executor == nil {
+		return c.String(403, "Exec is disabled on this server")
+	}
+
+	var req common.ExecStackRequest
+	if err := c.Bind(&req); err != nil {
+		return c.String(400, "Invalid request")
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.String(400, err.Error())
+	}
+
+	// Locate the stack with read access; ownership is checked separately below
+	allowedNS := h.authorizer.GetAllowedNamespaces(user.Role, authz.ActionRead, authz.ResourceStack, user.Name)
+	if len(allowedNS) == 0 {
+		return c.String(403, "Permission denied: no accessible namespaces")
+	}
+
+	// Resolve namespace from ID
+	targetNamespace, name, searchAll := h.nsManager.ResolveNamespaceFromID(idParam, allowedNS)
+
+	// Try to find the stack
+	userNS := h.nsManager.GetDeveloperNamespace(user.Name)
+	globalNS := h.nsManager.GetGlobalNamespace()
+	stack, found := h.findStack(c, targetNamespace, name, searchAll, userNS, globalNS, allowedNS)
+	if !found {
+		return c.String(404, fmt.Sprintf("Stack '%s' not found", idParam))
+	}
+
+	// Exec requires elevated access: the stack owner or an admin
+	if !canExec(user, stack) {
+		logging.LogDeniedWithIP("exec_not_owner", user.Name, "POST /stacks/:id/exec", c.RealIP())
+		return c.String(403, "Permission denied: only the stack owner or an admin can exec")
+	}
+
+	pod, container, err := h.findServicePod(c, stack, req.Service)
+	if err != nil {
+		logging.Logger.Warn("No pod available for exec",
+			zap.String("stack", stack.Name),
+			zap.String("service", req.Service),
+			zap.Error(err))
+		return c.String(404, err.Error())
+	}
+
+	logging.Logger.Info("Executing command in stack pod",
+		zap.String("user", user.Name),
+		zap.String("stack", stack.Name),
+		zap.String("namespace", stack.Namespace),
+		zap.String("service", req.Service),
+		zap.String("pod", pod),
+		zap.String("container", container),
+		zap.Strings("command", req.Command))
+
+	// Stream output as NDJSON frames
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "application/x-ndjson")
+	res.WriteHeader(http.StatusOK)
+
+	stream := &frameWriter{encoder: json.NewEncoder(res), flusher: res}
+	execErr := h.executor.Exec(c.Request().Context(), k8s.ExecRequest{
+		Namespace: stack.Namespace,
+		Pod:       pod,
+		Container: container,
+		Command:   req.Command,
+	}, stream.writer("stdout"), stream.writer("stderr"))
+
+	exit := ExecFrame{Stream: "exit"}
+	if execErr != nil {
+		logging.Logger.Warn("Exec command failed",
+			zap.String("stack", stack.Name),
+			zap.String("service", req.Service),
+			zap.Error(execErr))
+		exit.Error = execErr.Error()
+	}
+	return stream.write(exit)
+}
+
+// canExec reports whether the user may run commands in the stack's pods
+func canExec(user *middleware.User, stack *envv1alpha1.Stack) bool {
+	if user.Role == authz.Admin {
+		return true
+	}
+	return stack.Annotations["lissto.dev/created-by"] == user.Name
+}
+
+// findServicePod finds a running pod of the service and the container to exec into
+func (h *Handler) findServicePod(c echo.Context, stack *envv1alpha1.Stack, service string) (string, string, error) {
+	pods, err := h.k8sClient.ListPodsWithLabels(c.Request().Context(), stack.Namespace, map[string]string{
+		"lissto.dev/stack":   stack.Name,
+		"io.kompose.service": service,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to list pods for service %s: %w", service, err)
+	}
+
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		return pod.Name, serviceContainer(pod, stack.Spec.Images[service].ContainerName, service), nil
+	}
+
+	return "", "", fmt.Errorf("no running pod found for service %s", service)
+}
+
+// serviceContainer picks the service's container, honoring a custom container_name
+func serviceContainer(pod corev1.Pod, containerName, service string) string {
+	if len(pod.Spec.Containers) == 1 {
+		return pod.Spec.Containers[0].Name
+	}
+	if containerName != "" {
+		return containerName
+	}
+	return service
+}
+
+// frameWriter serializes concurrent stdout/stderr writes into NDJSON frames
+type frameWriter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	flusher http.Flusher
+}
+
+func (w *frameWriter) write(frame ExecFrame) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.encoder.Encode(frame); err != nil {
+		return err
+	}
+	w.flusher.Flush()
+	return nil
+}
+
+func (w *frameWriter) writer(stream string) *streamWriter {
+	return &streamWriter{frames: w, stream: stream}
+}
+
+// streamWriter is an io.Writer emitting frames for a single stream
+type streamWriter struct {
+	frames *frameWriter
+	stream string
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	if err := s.frames.write(ExecFrame{Stream: s.stream, Data: string(p)}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
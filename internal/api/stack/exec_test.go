@@ -0,0 +1,207 @@
+package stack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/k8s"
+	"github.com/lissto-dev/api/pkg/notify"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+// fakeExecutor records exec requests and writes canned output
+type fakeExecutor struct {
+	requests []k8s.ExecRequest
+	stdout   string
+	stderr   string
+	err      error
+}
+
+func (f *fakeExecutor) Exec(_ context.Context, req k8s.ExecRequest, stdout, stderr io.Writer) error {
+	f.requests = append(f.requests, req)
+	if f.stdout != "" {
+		_, _ = stdout.Write([]byte(f.stdout))
+	}
+	if f.stderr != "" {
+		_, _ = stderr.Write([]byte(f.stderr))
+	}
+	return f.err
+}
+
+type testValidator struct {
+	validator *validator.Validate
+}
+
+func (v *testValidator) Validate(i interface{}) error {
+	return v.validator.Struct(i)
+}
+
+// newTestHandler builds a stack handler backed by a fake Kubernetes client
+func newTestHandler(settings *config.Settings, executor k8s.Executor, objects ...client.Object) *Handler {
+	scheme, err := k8s.NewScheme()
+	Expect(err).NotTo(HaveOccurred())
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	cfg := &controllerconfig.Config{}
+	cfg.Namespaces.Global = "lissto-global"
+	cfg.Namespaces.DeveloperPrefix = "dev-"
+
+	nsManager := authz.NewNamespaceManager(cfg)
+	return NewHandler(k8s.NewClientFromClient(fakeClient, scheme), authz.NewAuthorizer(nsManager), nsManager, cfg, settings, nil, executor, notify.NopNotifier{}, nil, "")
+}
+
+// newTestContext creates an echo context with an authenticated user
+func newTestContext(method, target, body string, user *middleware.User) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	e.Validator = &testValidator{validator: validator.New()}
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user", user)
+	return c, rec
+}
+
+func execStackObjects() []client.Object {
+	stack := &envv1alpha1.Stack{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "my-stack",
+			Namespace:   "dev-alice",
+			Annotations: map[string]string{"lissto.dev/created-by": "alice"},
+		},
+		Spec: envv1alpha1.StackSpec{
+			Images: map[string]envv1alpha1.ImageInfo{"api": {Digest: "api@sha256:abc"}},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "api-7d9f",
+			Namespace: "dev-alice",
+			Labels: map[string]string{
+				"lissto.dev/stack":   "my-stack",
+				"io.kompose.service": "api",
+			},
+		},
+		Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: "api", Image: "api"}}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	return []client.Object{stack, pod}
+}
+
+var _ = Describe("ExecStack", func() {
+	var (
+		executor *fakeExecutor
+		enabled  *config.Settings
+	)
+
+	BeforeEach(func() {
+		executor = &fakeExecutor{stdout: "hello\n"}
+		enabled = &config.Settings{Exec: config.ExecSettings{Enabled: true}}
+	})
+
+	exec := func(h *Handler, stackID, body string, user *middleware.User) *httptest.ResponseRecorder {
+		c, rec := newTestContext(http.MethodPost, "/stacks/"+stackID+"/exec", body, user)
+		c.SetParamNames("id")
+		c.SetParamValues(stackID)
+		Expect(h.ExecStack(c)).To(Succeed())
+		return rec
+	}
+
+	It("should route the command to the service pod for the stack owner", func() {
+		h := newTestHandler(enabled, executor, execStackObjects()...)
+		rec := exec(h, "my-stack", `{"service":"api","command":["ls","-la"]}`,
+			&middleware.User{Name: "alice", Role: authz.User})
+
+		Expect(rec.Code).To(Equal(200))
+		Expect(executor.requests).To(ConsistOf(k8s.ExecRequest{
+			Namespace: "dev-alice",
+			Pod:       "api-7d9f",
+			Container: "api",
+			Command:   []string{"ls", "-la"},
+		}))
+
+		lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+		Expect(lines).To(HaveLen(2))
+		var output, exit ExecFrame
+		Expect(json.Unmarshal([]byte(lines[0]), &output)).To(Succeed())
+		Expect(output).To(Equal(ExecFrame{Stream: "stdout", Data: "hello\n"}))
+		Expect(json.Unmarshal([]byte(lines[1]), &exit)).To(Succeed())
+		Expect(exit).To(Equal(ExecFrame{Stream: "exit"}))
+	})
+
+	It("should allow admins to exec into any stack", func() {
+		h := newTestHandler(enabled, executor, execStackObjects()...)
+		rec := exec(h, "alice/my-stack", `{"service":"api","command":["env"]}`,
+			&middleware.User{Name: "root", Role: authz.Admin})
+
+		Expect(rec.Code).To(Equal(200))
+		Expect(executor.requests).To(HaveLen(1))
+	})
+
+	It("should report command failures in the exit frame", func() {
+		executor.err = fmt.Errorf("command terminated with exit code 1")
+		h := newTestHandler(enabled, executor, execStackObjects()...)
+		rec := exec(h, "my-stack", `{"service":"api","command":["false"]}`,
+			&middleware.User{Name: "alice", Role: authz.User})
+
+		Expect(rec.Body.String()).To(ContainSubstring(`"stream":"exit","error":"command terminated with exit code 1"`))
+	})
+
+	It("should reject users that do not own the stack", func() {
+		objects := execStackObjects()
+		stack := objects[0].(*envv1alpha1.Stack)
+		stack.Annotations["lissto.dev/created-by"] = "bob"
+
+		h := newTestHandler(enabled, executor, objects...)
+		rec := exec(h, "my-stack", `{"service":"api","command":["ls"]}`,
+			&middleware.User{Name: "alice", Role: authz.User})
+
+		Expect(rec.Code).To(Equal(403))
+		Expect(executor.requests).To(BeEmpty())
+	})
+
+	It("should reject non-owners that can read the stack", func() {
+		h := newTestHandler(enabled, executor, execStackObjects()...)
+		rec := exec(h, "alice/my-stack", `{"service":"api","command":["ls"]}`,
+			&middleware.User{Name: "ci", Role: authz.Deploy})
+
+		Expect(rec.Code).To(Equal(403))
+		Expect(executor.requests).To(BeEmpty())
+	})
+
+	It("should be rejected when exec is disabled", func() {
+		h := newTestHandler(config.DefaultSettings(), executor, execStackObjects()...)
+		rec := exec(h, "my-stack", `{"service":"api","command":["ls"]}`,
+			&middleware.User{Name: "alice", Role: authz.Admin})
+
+		Expect(rec.Code).To(Equal(403))
+		Expect(executor.requests).To(BeEmpty())
+	})
+
+	It("should return 404 when the service has no running pod", func() {
+		h := newTestHandler(enabled, executor, execStackObjects()...)
+		rec := exec(h, "my-stack", `{"service":"worker","command":["ls"]}`,
+			&middleware.User{Name: "alice", Role: authz.User})
+
+		Expect(rec.Code).To(Equal(404))
+		Expect(executor.requests).To(BeEmpty())
+	})
+})
@@ -0,0 +1,89 @@
+package stack
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/config"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+var _ = Describe("Frozen namespace", func() {
+	var (
+		h     *Handler
+		alice *middleware.User
+	)
+
+	BeforeEach(func() {
+		alice = &middleware.User{Name: "alice", Role: authz.User}
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name: "dev-alice",
+			Annotations: map[string]string{
+				authz.FrozenAnnotation:       "2026-10-01T12:00:00Z",
+				authz.FrozenReasonAnnotation: "incident 42",
+			},
+		}}
+		env := &envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}}
+		stack := &envv1alpha1.Stack{
+			ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "dev-alice"},
+			Spec:       envv1alpha1.StackSpec{Env: "dev"},
+		}
+		h = newTestHandler(config.DefaultSettings(), nil, namespace, env, stack)
+		h.cache = cache.NewMemoryCache()
+
+		Expect(h.cache.Set(context.Background(), "req-1", cache.PrepareResultCache{
+			Namespace: "dev-alice",
+			Images: map[string]cache.ImageInfoCache{
+				"api": {Digest: "registry.io/api@sha256:aaa", Image: "registry.io/api:main"},
+			},
+			Compose: "services:\n  api:\n    image: api\n",
+		}, time.Minute)).To(Succeed())
+	})
+
+	It("should block stack creation with 423 Locked", func() {
+		c, rec := newTestContext(http.MethodPost, "/stacks", `{"env":"dev","request_id":"req-1"}`, alice)
+		Expect(h.CreateStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(http.StatusLocked))
+		Expect(rec.Body.String()).To(ContainSubstring("incident 42"))
+
+		stacks, err := h.k8sClient.ListStacks(context.Background(), "dev-alice")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stacks.Items).To(HaveLen(1))
+	})
+
+	It("should block stack deletion", func() {
+		c, rec := newTestContext(http.MethodDelete, "/stacks/existing", "", alice)
+		c.SetParamNames("id")
+		c.SetParamValues("existing")
+		Expect(h.DeleteStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(http.StatusLocked))
+
+		_, err := h.k8sClient.GetStack(context.Background(), "dev-alice", "existing")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should still allow listing stacks", func() {
+		c, rec := newTestContext(http.MethodGet, "/stacks", "", alice)
+		Expect(h.GetStacks(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(200))
+		Expect(rec.Body.String()).To(ContainSubstring("existing"))
+	})
+
+	It("should allow creation again once unfrozen", func() {
+		Expect(h.k8sClient.UpdateNamespaceAnnotations(context.Background(), "dev-alice", nil,
+			[]string{authz.FrozenAnnotation, authz.FrozenReasonAnnotation})).To(Succeed())
+
+		c, rec := newTestContext(http.MethodPost, "/stacks", `{"env":"dev","request_id":"req-1"}`, alice)
+		Expect(h.CreateStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(201), rec.Body.String())
+	})
+})
@@ -0,0 +1,212 @@
+package stack
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"sigs.k8s.io/yaml"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+// KustomizationFile is the index of a GitOps bundle, listing every object file as a resource
+const KustomizationFile = "kustomization.yaml"
+
+// StackIDAnnotation records the stack an exported object belongs to as namespace/name
+const StackIDAnnotation = "lissto.dev/stack-id"
+
+// manifestSeparator splits the stored multi-document manifests
+var manifestSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// bundleFile is one file of a GitOps bundle
+type bundleFile struct {
+	Name    string
+	Content []byte
+}
+
+// kustomization is the subset of a kustomize Kustomization written into bundles
+type kustomization struct {
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Namespace  string   `json:"namespace,omitempty"`
+	Resources  []string `json:"resources"`
+}
+
+// GetGitOpsBundle handles GET /stacks/:id/gitops-bundle
+// It returns the stack's generated objects as multi-document YAML, or as a tar of one file per object
+// with ?format=tar; both end with a kustomization.yaml referencing every object file.
+func (h *Handler) GetGitOpsBundle(c echo.Context) error {
+	idParam := c.Param("id")
+	user, _ := middleware.GetUserFromContext(c)
+
+	format := c.QueryParam("format")
+	if format != "" && format != "yaml" && format != "tar" {
+		return c.String(400, fmt.Sprintf("Invalid format %q (valid: yaml, tar)", format))
+	}
+
+	// Get allowed namespaces for authorization
+	allowedNS := h.authorizer.GetAllowedNamespaces(user.Role, authz.ActionRead, authz.ResourceStack, user.Name)
+	if len(allowedNS) == 0 {
+		return c.String(403, "Permission denied: no accessible namespaces")
+	}
+
+	// Resolve namespace from ID
+	targetNamespace, name, searchAll := h.nsManager.ResolveNamespaceFromID(idParam, allowedNS)
+
+	// Try to find the stack
+	userNS := h.nsManager.GetDeveloperNamespace(user.Name)
+	globalNS := h.nsManager.GetGlobalNamespace()
+	stack, found := h.findStack(c, targetNamespace, name, searchAll, userNS, globalNS, allowedNS)
+	if !found {
+		return c.String(404, fmt.Sprintf("Stack '%s' not found", idParam))
+	}
+
+	configMap, err := h.k8sClient.GetConfigMap(c.Request().Context(), stack.Namespace, stack.Spec.ManifestsConfigMapRef)
+	if err != nil || configMap.Data["manifests.yaml"] == "" {
+		logging.Logger.Warn("Stack manifests not found",
+			zap.String("stack", stack.Name),
+			zap.String("namespace", stack.Namespace),
+			zap.String("configmap", stack.Spec.ManifestsConfigMapRef),
+			zap.Error(err))
+		return c.String(404, fmt.Sprintf("Manifests of stack '%s' not found", idParam))
+	}
+
+	id := h.nsManager.MustGenerateScopedID(stack.Namespace, stack.Name)
+	files, err := buildGitOpsBundle(stack, id, configMap.Data["manifests.yaml"])
+	if err != nil {
+		logging.Logger.Error("Failed to build GitOps bundle",
+			zap.String("stack", stack.Name),
+			zap.String("namespace", stack.Namespace),
+			zap.Error(err))
+		return c.String(500, "Failed to build GitOps bundle")
+	}
+
+	if format == "tar" {
+		archive, err := tarBundle(stack.Name, files)
+		if err != nil {
+			logging.Logger.Error("Failed to archive GitOps bundle",
+				zap.String("stack", stack.Name),
+				zap.Error(err))
+			return c.String(500, "Failed to build GitOps bundle")
+		}
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", stack.Name+".tar"))
+		return c.Blob(200, "application/x-tar", archive)
+	}
+	return c.Blob(200, "application/yaml", joinBundle(files))
+}
+
+// buildGitOpsBundle splits the stored manifests into one file per object, stamped with the stack ID and its
+// provenance, followed by the kustomization.yaml listing them
+func buildGitOpsBundle(stack *envv1alpha1.Stack, id, manifests string) ([]bundleFile, error) {
+	provenance := map[string]string{StackIDAnnotation: id}
+	for _, key := range []string{APIInstanceAnnotation, APIVersionAnnotation, ResolutionConfigAnnotation} {
+		if value := stack.Annotations[key]; value != "" {
+			provenance[key] = value
+		}
+	}
+
+	var files []bundleFile
+	seen := make(map[string]bool)
+	for _, document := range manifestSeparator.Split(manifests, -1) {
+		if strings.TrimSpace(document) == "" {
+			continue
+		}
+		var object map[string]interface{}
+		if err := yaml.Unmarshal([]byte(document), &object); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest: %w", err)
+		}
+		if object == nil {
+			continue
+		}
+
+		metadata, _ := object["metadata"].(map[string]interface{})
+		if metadata == nil {
+			metadata = map[string]interface{}{}
+			object["metadata"] = metadata
+		}
+		annotations, _ := metadata["annotations"].(map[string]interface{})
+		if annotations == nil {
+			annotations = map[string]interface{}{}
+			metadata["annotations"] = annotations
+		}
+		for key, value := range provenance {
+			annotations[key] = value
+		}
+
+		kind, _ := object["kind"].(string)
+		objectName, _ := metadata["name"].(string)
+		fileName := strings.ToLower(kind) + "-" + objectName + ".yaml"
+		for i := 2; seen[fileName]; i++ {
+			fileName = fmt.Sprintf("%s-%s-%d.yaml", strings.ToLower(kind), objectName, i)
+		}
+		seen[fileName] = true
+
+		content, err := yaml.Marshal(object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize %s %s: %w", kind, objectName, err)
+		}
+		files = append(files, bundleFile{Name: fileName, Content: content})
+	}
+
+	index := kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Namespace:  stack.Namespace,
+		Resources:  make([]string, 0, len(files)),
+	}
+	for _, file := range files {
+		index.Resources = append(index.Resources, file.Name)
+	}
+	content, err := yaml.Marshal(index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize kustomization: %w", err)
+	}
+	return append(files, bundleFile{Name: KustomizationFile, Content: content}), nil
+}
+
+// joinBundle writes the files as one multi-document YAML, each document headed by its file name
+func joinBundle(files []bundleFile) []byte {
+	var out bytes.Buffer
+	for i, file := range files {
+		if i > 0 {
+			out.WriteString("---\n")
+		}
+		fmt.Fprintf(&out, "# Source: %s\n", file.Name)
+		out.Write(file.Content)
+	}
+	return out.Bytes()
+}
+
+// tarBundle archives the files under a directory named after the stack
+func tarBundle(directory string, files []bundleFile) ([]byte, error) {
+	var out bytes.Buffer
+	writer := tar.NewWriter(&out)
+	modTime := time.Now().UTC()
+	for _, file := range files {
+		header := &tar.Header{
+			Name:    directory + "/" + file.Name,
+			Mode:    0o644,
+			Size:    int64(len(file.Content)),
+			ModTime: modTime,
+		}
+		if err := writer.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write(file.Content); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
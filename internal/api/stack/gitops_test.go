@@ -0,0 +1,148 @@
+package stack
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/config"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+var _ = Describe("GitOps bundle", func() {
+	const composeContent = `
+services:
+  api:
+    image: api
+    ports:
+      - "8080:8080"
+  db:
+    image: postgres
+`
+
+	var (
+		h       *Handler
+		alice   *middleware.User
+		stackID string
+	)
+
+	BeforeEach(func() {
+		alice = &middleware.User{Name: "alice", Role: authz.User}
+		env := &envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}}
+		h = newTestHandler(config.DefaultSettings(), nil, env)
+		h.cache = cache.NewMemoryCache()
+		h.instanceID = "api-0"
+
+		Expect(h.cache.Set(context.Background(), "req-1", cache.PrepareResultCache{
+			Namespace: "dev-alice",
+			Images: map[string]cache.ImageInfoCache{
+				"api": {Digest: "registry.io/api@sha256:aaa", Image: "registry.io/api:main"},
+				"db":  {Digest: "postgres@sha256:bbb", Image: "postgres:16"},
+			},
+			Compose: composeContent,
+		}, time.Minute)).To(Succeed())
+		c, rec := newTestContext(http.MethodPost, "/stacks", `{"env":"dev","request_id":"req-1"}`, alice)
+		Expect(h.CreateStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(201), rec.Body.String())
+		stackID = rec.Body.String()
+	})
+
+	getBundle := func(query string, user *middleware.User) (int, []byte) {
+		c, rec := newTestContext(http.MethodGet, "/stacks/"+stackID+"/gitops-bundle"+query, "", user)
+		c.SetParamNames("id")
+		c.SetParamValues(stackID)
+		Expect(h.GetGitOpsBundle(c)).To(Succeed())
+		return rec.Code, rec.Body.Bytes()
+	}
+
+	// storedObjectCount counts the objects in the stack's manifests ConfigMap
+	storedObjectCount := func() int {
+		stack, err := h.k8sClient.GetStack(context.Background(), "dev-alice", strings.TrimPrefix(stackID, "alice/"))
+		Expect(err).NotTo(HaveOccurred())
+		configMap, err := h.k8sClient.GetConfigMap(context.Background(), "dev-alice", stack.Spec.ManifestsConfigMapRef)
+		Expect(err).NotTo(HaveOccurred())
+		return len(strings.Split(configMap.Data["manifests.yaml"], "---\n"))
+	}
+
+	// expectValidBundle checks that the kustomization lists exactly the object files
+	expectValidBundle := func(files map[string][]byte) {
+		var index kustomization
+		Expect(yaml.Unmarshal(files[KustomizationFile], &index)).To(Succeed())
+		Expect(index.Kind).To(Equal("Kustomization"))
+		Expect(index.Namespace).To(Equal("dev-alice"))
+		Expect(index.Resources).To(HaveLen(len(files) - 1))
+		Expect(index.Resources).To(HaveLen(storedObjectCount()))
+
+		kinds := map[string]bool{}
+		for _, resource := range index.Resources {
+			Expect(files).To(HaveKey(resource))
+			var object struct {
+				Kind     string            `json:"kind"`
+				Metadata metav1.ObjectMeta `json:"metadata"`
+			}
+			Expect(yaml.Unmarshal(files[resource], &object)).To(Succeed())
+			Expect(object.Metadata.Annotations).To(HaveKeyWithValue(StackIDAnnotation, stackID))
+			Expect(object.Metadata.Annotations).To(HaveKeyWithValue(APIInstanceAnnotation, "api-0"))
+			kinds[object.Kind] = true
+		}
+		Expect(kinds).To(HaveKey("Deployment"))
+		Expect(kinds).To(HaveKey("Service"))
+	}
+
+	It("should return every object and the kustomization as multi-document YAML", func() {
+		code, body := getBundle("", alice)
+		Expect(code).To(Equal(200), string(body))
+
+		files := map[string][]byte{}
+		for _, document := range strings.Split(string(body), "---\n") {
+			header, content, found := strings.Cut(document, "\n")
+			Expect(found).To(BeTrue())
+			Expect(header).To(HavePrefix("# Source: "))
+			files[strings.TrimPrefix(header, "# Source: ")] = []byte(content)
+		}
+		expectValidBundle(files)
+	})
+
+	It("should return one file per object in a tar", func() {
+		code, body := getBundle("?format=tar", alice)
+		Expect(code).To(Equal(200), string(body))
+
+		files := map[string][]byte{}
+		reader := tar.NewReader(bytes.NewReader(body))
+		for {
+			header, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			Expect(err).NotTo(HaveOccurred())
+			content, err := io.ReadAll(reader)
+			Expect(err).NotTo(HaveOccurred())
+			name, found := strings.CutPrefix(header.Name, strings.TrimPrefix(stackID, "alice/")+"/")
+			Expect(found).To(BeTrue())
+			files[name] = content
+		}
+		expectValidBundle(files)
+	})
+
+	It("should not export stacks of other users", func() {
+		code, _ := getBundle("", &middleware.User{Name: "bob", Role: authz.User})
+		Expect(code).To(Equal(404))
+	})
+
+	It("should reject unknown formats", func() {
+		code, _ := getBundle("?format=zip", alice)
+		Expect(code).To(Equal(400))
+	})
+})
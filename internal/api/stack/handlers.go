@@ -2,8 +2,11 @@ package stack
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/compose-spec/compose-go/v2/loader"
 	"github.com/compose-spec/compose-go/v2/types"
@@ -14,12 +17,17 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/prepare"
 	"github.com/lissto-dev/api/internal/middleware"
 	"github.com/lissto-dev/api/pkg/authz"
 	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/image"
 	"github.com/lissto-dev/api/pkg/k8s"
 	"github.com/lissto-dev/api/pkg/kompose"
 	"github.com/lissto-dev/api/pkg/logging"
+	"github.com/lissto-dev/api/pkg/notify"
 	"github.com/lissto-dev/api/pkg/postprocessor"
 	"github.com/lissto-dev/api/pkg/preprocessor"
 	"github.com/lissto-dev/api/pkg/serializer"
@@ -28,22 +36,48 @@ import (
 	"github.com/lissto-dev/controller/pkg/namespace"
 )
 
+// Retry policy for reading prepare results when the cache backend errors
+// (variables so tests can shorten the backoff)
+var (
+	cacheGetAttempts = 3
+	cacheGetBackoff  = 200 * time.Millisecond
+)
+
 // Handler handles all stack-related HTTP requests
 type Handler struct {
 	k8sClient          *k8s.Client
 	authorizer         *authz.Authorizer
 	nsManager          *authz.NamespaceManager
 	config             *controllerconfig.Config
+	settings           *config.Settings
 	exposePreprocessor *preprocessor.ExposePreprocessor
 	cache              cache.Cache
+	executor           k8s.Executor // nil unless exec is enabled
+	notifier           notify.Notifier
+	imageResolver      prepare.ImageResolver // nil disables image refresh
+	podWatcher         PodWatcher
+	locker             NamespaceLocker // Serializes create, update and delete within a namespace
+	// signatureVerifier checks the signatures of images stacks are updated to, nil when not configured
+	signatureVerifier image.SignatureVerifier
+	// instanceID and resolutionConfigHash are stamped on created stacks, see applyProvenance
+	instanceID           string
+	resolutionConfigHash string
 }
 
 // StackResponse represents standard stack data
 type StackResponse struct {
-	Name               string `json:"name"`
-	Namespace          string `json:"namespace"`
-	BlueprintReference string `json:"blueprintReference"`
-	EnvReference       string `json:"envReference"`
+	Name               string   `json:"name"`
+	Namespace          string   `json:"namespace"`
+	BlueprintReference string   `json:"blueprintReference"`
+	EnvReference       string   `json:"envReference"`
+	Description        string   `json:"description,omitempty"`
+	Tags               []string `json:"tags,omitempty"`
+	Protected          bool     `json:"protected,omitempty"`
+	// Phase and Conditions reflect the controller's reconcile status
+	Phase      string           `json:"phase"`
+	Conditions []StackCondition `json:"conditions,omitempty"`
+	// CreatedBy identifies the API instance and version that created the stack
+	CreatedBy *StackProvenance `json:"created_by,omitempty"`
 }
 
 // FormattableStack wraps a k8s Stack to implement common.Formattable
@@ -67,6 +101,12 @@ func extractStackResponse(stack *envv1alpha1.Stack) StackResponse {
 		Namespace:          stack.Namespace,
 		BlueprintReference: stack.Spec.BlueprintReference,
 		EnvReference:       stack.Spec.Env,
+		Description:        stack.Annotations[DescriptionAnnotation],
+		Tags:               stackTags(stack),
+		Protected:          isProtected(stack),
+		Phase:              stackPhase(stack),
+		Conditions:         stackConditions(stack),
+		CreatedBy:          stackProvenance(stack),
 	}
 }
 
@@ -76,41 +116,43 @@ func NewHandler(
 	authorizer *authz.Authorizer,
 	nsManager *authz.NamespaceManager,
 	cfg *controllerconfig.Config,
+	settings *config.Settings,
 	cache cache.Cache,
+	executor k8s.Executor,
+	notifier notify.Notifier,
+	imageResolver prepare.ImageResolver,
+	instanceID string,
 ) *Handler {
-	// Create internal config if available
-	var internalConfig *preprocessor.IngressConfig
-	if cfg.Stacks.Ingress.Internal != nil {
-		internalConfig = &preprocessor.IngressConfig{
-			IngressClass: cfg.Stacks.Ingress.Internal.IngressClass,
-			HostSuffix:   cfg.Stacks.Ingress.Internal.HostSuffix,
-			TLSSecret:    cfg.Stacks.Ingress.Internal.TLSSecret,
-		}
-	}
-
-	// Create internet config if available
-	var internetConfig *preprocessor.IngressConfig
-	if cfg.Stacks.Ingress.Internet != nil {
-		internetConfig = &preprocessor.IngressConfig{
-			IngressClass: cfg.Stacks.Ingress.Internet.IngressClass,
-			HostSuffix:   cfg.Stacks.Ingress.Internet.HostSuffix,
-			TLSSecret:    cfg.Stacks.Ingress.Internet.TLSSecret,
-		}
-	}
-
 	// Create expose preprocessor with internal and internet configs
-	exposePreprocessor := preprocessor.NewExposePreprocessor(internalConfig, internetConfig)
+	exposePreprocessor := prepare.NewExposePreprocessor(cfg, settings.Ingress)
+	signatureVerifier, err := prepare.NewSignatureVerifier(settings.Signatures, cache)
+	if err != nil {
+		// Without a verifier no image is verified, so updates to images that require signatures fail closed
+		logging.Logger.Error("Image signature verification disabled for stack updates", zap.Error(err))
+	}
 
 	return &Handler{
-		k8sClient:          k8sClient,
-		authorizer:         authorizer,
-		nsManager:          nsManager,
-		config:             cfg,
-		exposePreprocessor: exposePreprocessor,
-		cache:              cache,
+		k8sClient:            k8sClient,
+		authorizer:           authorizer,
+		nsManager:            nsManager,
+		config:               cfg,
+		settings:             settings,
+		exposePreprocessor:   exposePreprocessor,
+		cache:                cache,
+		executor:             executor,
+		notifier:             notifier,
+		imageResolver:        imageResolver,
+		podWatcher:           k8sClient,
+		locker:               NewNamespaceLocker(),
+		signatureVerifier:    signatureVerifier,
+		instanceID:           instanceID,
+		resolutionConfigHash: prepare.ResolutionConfigHash(cfg, settings),
 	}
 }
 
+// HostSuffixAnnotation records the preview host suffix a stack was created with, so re-rendering keeps its hostnames
+const HostSuffixAnnotation = "lissto.dev/host-suffix"
+
 // CreateStack handles POST /stacks
 func (h *Handler) CreateStack(c echo.Context) error {
 	var req common.CreateStackRequest
@@ -123,6 +165,12 @@ func (h *Handler) CreateStack(c echo.Context) error {
 	if err := c.Validate(&req); err != nil {
 		return c.String(400, err.Error())
 	}
+	if len(req.Description) > maxDescriptionLength {
+		return c.String(400, fmt.Sprintf("Description must be at most %d characters", maxDescriptionLength))
+	}
+	if err := validateTags(req.Tags); err != nil {
+		return c.String(400, err.Error())
+	}
 
 	// Log request details
 	logging.Logger.Info("Stack creation request",
@@ -156,9 +204,15 @@ func (h *Handler) CreateStack(c echo.Context) error {
 	}
 	envName := env.Name
 
-	// Retrieve cached prepare result
+	// Retrieve cached prepare result, retrying briefly if the cache backend is failing
 	var cachedResult cache.PrepareResultCache
-	if err := h.cache.Get(c.Request().Context(), req.RequestID, &cachedResult); err != nil {
+	if err := cache.GetWithRetry(c.Request().Context(), h.cache, req.RequestID, &cachedResult, cacheGetAttempts, cacheGetBackoff); err != nil {
+		if !cache.IsMiss(err) {
+			logging.Logger.Error("Cache backend unavailable while retrieving prepare result",
+				zap.String("request_id", req.RequestID),
+				zap.Error(err))
+			return c.String(503, "Cache temporarily unavailable. Please retry.")
+		}
 		logging.Logger.Error("Failed to retrieve cached prepare result",
 			zap.String("request_id", req.RequestID),
 			zap.Error(err))
@@ -174,6 +228,14 @@ func (h *Handler) CreateStack(c echo.Context) error {
 		return c.String(404, "Request ID not found")
 	}
 
+	// Reject services still awaiting their first build unless explicitly allowed
+	if err := checkPendingImages(cachedResult.Images, req.AllowPending); err != nil {
+		logging.Logger.Warn("Stack creation rejected due to pending builds",
+			zap.String("request_id", req.RequestID),
+			zap.Error(err))
+		return c.String(400, err.Error())
+	}
+
 	// Build enriched images from cache
 	enrichedImages := make(map[string]envv1alpha1.ImageInfo)
 	for service, info := range cachedResult.Images {
@@ -195,6 +257,18 @@ func (h *Handler) CreateStack(c echo.Context) error {
 		return c.String(403, fmt.Sprintf("Permission denied: %s", perm.Reason))
 	}
 
+	// Admins can freeze a namespace during an incident
+	if rejected, err := common.RejectIfFrozen(c, h.nsManager, h.k8sClient, namespace, user.Name, "POST /stacks"); rejected {
+		return err
+	}
+
+	// Concurrent creates in the namespace would race on ensuring it and on stack names
+	unlock, rejected, err := h.lockNamespace(c, namespace)
+	if rejected {
+		return err
+	}
+	defer unlock()
+
 	// Ensure namespace exists
 	if err := h.k8sClient.EnsureNamespace(c.Request().Context(), namespace); err != nil {
 		logging.Logger.Error("Failed to create namespace",
@@ -203,33 +277,72 @@ func (h *Handler) CreateStack(c echo.Context) error {
 		return c.String(500, "Failed to create namespace")
 	}
 
-	// Step 1: Parse blueprint reference and get blueprint
-	blueprintNamespace, blueprintName, err := h.nsManager.ParseScopedID(req.Blueprint)
-	if err != nil {
-		logging.Logger.Error("Failed to parse blueprint reference",
-			zap.String("blueprint", req.Blueprint),
-			zap.Error(err))
-		return c.String(400, fmt.Sprintf("Invalid blueprint reference: %v", err))
+	// Step 1: Load compose content from the blueprint, or from the cache for inline prepares
+	inline := cachedResult.Compose != ""
+	merged := len(cachedResult.Blueprints) > 0
+	if merged && req.Blueprint != "" {
+		return c.String(400, "Request ID was prepared from several blueprints; omit blueprint")
+	}
+	if inline && req.Blueprint != "" {
+		return c.String(400, "Request ID was prepared from inline compose; omit blueprint")
+	}
+	if !inline && req.Blueprint == "" {
+		return c.String(400, "blueprint is required")
 	}
 
-	blueprint, err := h.k8sClient.GetBlueprint(c.Request().Context(), blueprintNamespace, blueprintName)
-	if err != nil {
-		logging.Logger.Error("Failed to get blueprint",
-			zap.String("blueprint", req.Blueprint),
-			zap.String("blueprint_namespace", blueprintNamespace),
-			zap.String("blueprint_name", blueprintName),
-			zap.Error(err))
-		return c.String(404, "Blueprint not found")
+	composeContent := cachedResult.Compose
+	blueprintTitle := "inline"
+	if !inline {
+		blueprintNamespace, blueprintName, err := h.nsManager.ParseScopedID(req.Blueprint)
+		if err != nil {
+			logging.Logger.Error("Failed to parse blueprint reference",
+				zap.String("blueprint", req.Blueprint),
+				zap.Error(err))
+			return c.String(400, fmt.Sprintf("Invalid blueprint reference: %v", err))
+		}
+
+		blueprint, err := h.k8sClient.GetBlueprint(c.Request().Context(), blueprintNamespace, blueprintName)
+		if err != nil {
+			logging.Logger.Error("Failed to get blueprint",
+				zap.String("blueprint", req.Blueprint),
+				zap.String("blueprint_namespace", blueprintNamespace),
+				zap.String("blueprint_name", blueprintName),
+				zap.Error(err))
+			return c.String(404, "Blueprint not found")
+		}
+		composeContent = blueprint.Spec.DockerCompose
+		blueprintTitle = common.ExtractBlueprintTitle(blueprint, blueprint.Name)
+	} else if metadata, err := compose.ParseBlueprintMetadata(composeContent, controllerconfig.RepoConfig{}); err == nil && metadata.Title != "" {
+		// Inline compose has no blueprint annotations; x-lissto.title is the only title source
+		blueprintTitle = metadata.Title
+	} else if merged {
+		blueprintTitle = strings.Join(cachedResult.Blueprints, " + ")
 	}
 
 	// Parse Docker Compose content
-	composeConfig, err := h.parseDockerCompose(blueprint.Spec.DockerCompose)
+	composeConfig, err := h.parseDockerCompose(composeContent)
 	if err != nil {
 		logging.Logger.Error("Failed to parse Docker Compose",
 			zap.String("blueprint", req.Blueprint),
+			zap.Bool("inline", inline),
 			zap.Error(err))
 		return c.String(400, "Invalid Docker Compose content")
 	}
+	// Prepare checks the policy too, this catches requests that skipped it or a policy changed since
+	if rejected, err := common.RejectPolicyViolations(c, composeConfig, h.settings.Compose.DeniedFeatures); rejected {
+		return err
+	}
+	// Labels stripped at prepare come back with the compose, so they are stripped (or rejected) again
+	if _, rejected, err := prepare.EnforceLabelPolicy(c, user.Role, h.settings.Roles, composeConfig); rejected {
+		return err
+	}
+	// Images must carry a verified signature when required by settings or lissto.dev/require-signature
+	if err := checkSignedImages(composeConfig, cachedResult.Images, h.settings.Signatures.Required); err != nil {
+		logging.Logger.Warn("Stack creation rejected due to unverified image signatures",
+			zap.String("request_id", req.RequestID),
+			zap.Error(err))
+		return c.String(400, err.Error())
+	}
 
 	// Step 2: Validate and apply provided service images
 	for serviceName := range composeConfig.Services {
@@ -238,8 +351,20 @@ func (h *Handler) CreateStack(c echo.Context) error {
 			return c.String(400, fmt.Sprintf("Missing image for service: %s", serviceName))
 		}
 
-		// Validate image contains digest (@sha256:...)
-		if !strings.Contains(imageInfo.Digest, "@sha256:") {
+		// Pending services are deployed with their expected tag and start once the build publishes it
+		pending := cachedResult.Images[serviceName].Pending
+		appliedImage := imageInfo.Digest
+		if pending {
+			if imageInfo.Image == "" {
+				return c.String(400, fmt.Sprintf("Pending service %s has no placeholder image", serviceName))
+			}
+			appliedImage = imageInfo.Image
+		} else if cachedResult.Images[serviceName].Unpinned {
+			logging.Logger.Warn("Deploying UNPINNED image (digest could not be resolved at prepare)",
+				zap.String("service", serviceName),
+				zap.String("image", imageInfo.Digest))
+		} else if !strings.Contains(imageInfo.Digest, "@sha256:") {
+			// Validate image contains digest (@sha256:...)
 			return c.String(400, fmt.Sprintf("Image for service %s must contain digest (@sha256:...), got: %s", serviceName, imageInfo.Digest))
 		}
 
@@ -254,13 +379,14 @@ func (h *Handler) CreateStack(c echo.Context) error {
 		}
 
 		// Apply provided image to service
-		service.Image = imageInfo.Digest
+		service.Image = appliedImage
 		composeConfig.Services[serviceName] = service
 
 		logging.Logger.Info("Applied image to service",
 			zap.String("service", serviceName),
 			zap.String("digest", imageInfo.Digest),
-			zap.String("image", imageInfo.Image))
+			zap.String("image", imageInfo.Image),
+			zap.Bool("pending", pending))
 	}
 
 	// Step 3: Generate stack name (needed for label injection)
@@ -268,7 +394,32 @@ func (h *Handler) CreateStack(c echo.Context) error {
 	stackName := common.GenerateStackName("", "")
 
 	// Step 4: Expose services preprocessing (using env name for URL generation and stack name for labels)
-	processedServices, err := h.exposePreprocessor.ProcessServices(composeConfig.Services, envName, stackName)
+	// The blueprint's x-lissto.exposeDefault picks the visibility of services exposed with "true"
+	exposePreprocessor, err := h.exposePreprocessor.WithDefaultVisibility(compose.ExtractLisstoConfig(composeConfig).ExposeDefault)
+	if err != nil {
+		return c.String(400, fmt.Sprintf("Service exposure configuration error: %s", err.Error()))
+	}
+	// A preview host suffix given at create wins over the one given at prepare
+	hostSuffix := req.HostOverrideSuffix
+	if hostSuffix == "" {
+		hostSuffix = cachedResult.HostSuffix
+	}
+	hostSuffix, err = prepare.HostOverrideSuffix(user.Role, hostSuffix, h.settings.Ingress, h.settings.Roles)
+	if errors.Is(err, prepare.ErrHostSuffixForbidden) {
+		logging.LogDeniedWithIP("host_suffix_override", user.Name, "POST /stacks", c.RealIP())
+		return c.String(403, err.Error())
+	} else if err != nil {
+		return c.String(400, err.Error())
+	}
+	if hostSuffix != "" {
+		exposePreprocessor = exposePreprocessor.WithHostSuffix(hostSuffix)
+		for serviceName, service := range composeConfig.Services {
+			info := enrichedImages[serviceName]
+			info.URL = exposePreprocessor.GetExposedServiceURL(service, serviceName, envName)
+			enrichedImages[serviceName] = info
+		}
+	}
+	processedServices, err := exposePreprocessor.ProcessServices(composeConfig.Services, envName, stackName)
 	if err != nil {
 		logging.Logger.Error("Failed to process service exposure configuration",
 			zap.String("blueprint", req.Blueprint),
@@ -277,8 +428,21 @@ func (h *Handler) CreateStack(c echo.Context) error {
 	}
 	composeConfig.Services = processedServices
 
+	// Step 4.5: Collect what the env propagates: metadata and its default variables and secrets
+	propagated := envPropagation(env)
+	if propagated.Env, err = h.envDefaults(c.Request().Context(), env); err != nil {
+		logging.Logger.Error("Failed to load env defaults",
+			zap.String("env", envName),
+			zap.String("namespace", namespace),
+			zap.Error(err))
+		return c.String(400, fmt.Sprintf("Invalid env defaults: %v", err))
+	}
+
+	// Step 4.6: Raise services below the env's resource floor to it
+	prepare.ApplyResourceFloor(composeConfig, envName, h.settings.Resources)
+
 	// Step 5: Generate Kubernetes manifests using Kompose (isolated)
-	k8sManifests, err := h.generateKubernetesManifests(composeConfig, namespace, stackName)
+	k8sManifests, objectCount, err := h.generateKubernetesManifests(composeConfig, namespace, stackName, propagated)
 	if err != nil {
 		logging.Logger.Error("Failed to generate Kubernetes manifests",
 			zap.String("blueprint", req.Blueprint),
@@ -286,45 +450,21 @@ func (h *Handler) CreateStack(c echo.Context) error {
 		return c.String(500, "Failed to generate Kubernetes manifests")
 	}
 
+	// Step 5.4: Enforce the object count limits (the count is reported in a response header)
+	if rejected, err := h.checkObjectCount(c, req.Blueprint, objectCount); rejected {
+		return err
+	}
+
 	// Step 5.5: Validate manifest size (ConfigMap 1MB limit)
-	const maxConfigMapSize = 1 * 1024 * 1024 // 1MB
-	if len(k8sManifests) > maxConfigMapSize {
+	if len(k8sManifests) > maxManifestsSize {
 		logging.Logger.Error("Kubernetes manifests exceed ConfigMap size limit",
 			zap.Int("size", len(k8sManifests)),
-			zap.Int("limit", maxConfigMapSize))
+			zap.Int("limit", maxManifestsSize))
 		return c.String(400, "Generated manifests exceed 1MB size limit")
 	}
 
-	// Step 6: Create ConfigMap with manifests
-	configMapName := fmt.Sprintf("lissto-%s", stackName)
-
-	// Step 1: Create ConfigMap with manifests (no owner reference yet)
-	configMap := &corev1.ConfigMap{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      configMapName,
-			Namespace: namespace,
-			Labels: map[string]string{
-				"app.kubernetes.io/managed-by": "lissto",
-				"lissto.dev/stack":             stackName,
-			},
-		},
-		Data: map[string]string{
-			"manifests.yaml": k8sManifests,
-		},
-	}
-
-	if err := h.k8sClient.CreateConfigMap(c.Request().Context(), configMap); err != nil {
-		logging.Logger.Error("Failed to create manifests ConfigMap",
-			zap.String("configmap_name", configMapName),
-			zap.String("namespace", namespace),
-			zap.Error(err))
-		return c.String(500, "Failed to create manifests ConfigMap")
-	}
-
-	// Step 2: Create Stack CRD
-	// Extract blueprint title
-	blueprintTitle := common.ExtractBlueprintTitle(blueprint, blueprint.Name)
-
+	// Step 6: Create ConfigMap with manifests and the Stack CRD owning it
+	configMapName := manifestsConfigMapName(stackName)
 	stack := &envv1alpha1.Stack{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      stackName,
@@ -338,25 +478,76 @@ func (h *Handler) CreateStack(c echo.Context) error {
 			},
 		},
 		Spec: envv1alpha1.StackSpec{
-			BlueprintReference:    req.Blueprint,
+			BlueprintReference:    req.Blueprint, // Empty for inline compose stacks
 			Env:                   envName,
 			ManifestsConfigMapRef: configMapName,
 			Images:                enrichedImages,
 		},
 	}
+	applyDescriptionAndTags(stack, req.Description, req.Tags)
+	applyPropagation(stack, propagated)
+	if hostSuffix != "" {
+		stack.Annotations[HostSuffixAnnotation] = hostSuffix
+	}
+	if merged {
+		stack.Spec.BlueprintReference = cachedResult.Blueprints[0]
+		stack.Annotations[BlueprintsAnnotation] = strings.Join(cachedResult.Blueprints, ",")
+	}
+
+	if message, err := h.createStackWithManifests(c.Request().Context(), stack, k8sManifests); err != nil {
+		return c.String(500, message)
+	}
+
+	logging.Logger.Info("Stack created successfully",
+		zap.String("stack_name", stackName),
+		zap.String("namespace", namespace),
+		zap.String("user", user.Name))
+
+	// Return scoped identifier, or the stack when asked for a representation
+	identifier := h.nsManager.MustGenerateScopedID(namespace, stackName)
+	h.notifier.Notify(notify.NewStackEvent(notify.EventStackCreated, identifier, stack, user.Name))
+	return common.HandleCreatedResponse(c, 201, &FormattableStack{k8sObj: stack, nsManager: h.nsManager}, func() error {
+		return c.String(201, identifier)
+	})
+}
+
+// maxManifestsSize is the ConfigMap size limit the manifests must fit in
+const maxManifestsSize = 1 * 1024 * 1024 // 1MB
+
+// createStackWithManifests creates the manifests ConfigMap, then the stack, then makes the stack own
+// the ConfigMap; what was created is deleted again if a later step fails
+// The stack is stamped with the provenance annotations first, see applyProvenance
+// On failure it returns the message for the client along with the error
+func (h *Handler) createStackWithManifests(ctx context.Context, stack *envv1alpha1.Stack, manifests string) (string, error) {
+	namespace, stackName := stack.Namespace, stack.Name
+	configMapName := stack.Spec.ManifestsConfigMapRef
+	h.applyProvenance(stack)
+
+	// Step 1: Create ConfigMap with manifests (no owner reference yet)
+	configMap := newManifestsConfigMap(namespace, stackName, manifests)
+	configMap.Name = configMapName
+
+	if err := h.k8sClient.CreateConfigMap(ctx, configMap); err != nil {
+		logging.Logger.Error("Failed to create manifests ConfigMap",
+			zap.String("configmap_name", configMapName),
+			zap.String("namespace", namespace),
+			zap.Error(err))
+		return "Failed to create manifests ConfigMap", err
+	}
 
-	if err := h.k8sClient.CreateStack(c.Request().Context(), stack); err != nil {
+	// Step 2: Create Stack CRD
+	if err := h.k8sClient.CreateStack(ctx, stack); err != nil {
 		logging.Logger.Error("Failed to create stack",
 			zap.String("stack_name", stackName),
 			zap.String("namespace", namespace),
 			zap.Error(err))
 		// Clean up ConfigMap since Stack creation failed
-		if cleanupErr := h.k8sClient.DeleteConfigMap(c.Request().Context(), namespace, configMapName); cleanupErr != nil {
+		if cleanupErr := h.k8sClient.DeleteConfigMap(ctx, namespace, configMapName); cleanupErr != nil {
 			logging.Logger.Error("Failed to cleanup ConfigMap after Stack creation failure",
 				zap.String("configmap_name", configMapName),
 				zap.Error(cleanupErr))
 		}
-		return c.String(500, "Failed to create stack")
+		return "Failed to create stack", err
 	}
 
 	// Step 3: Update ConfigMap with owner reference for automatic cleanup
@@ -366,47 +557,39 @@ func (h *Handler) CreateStack(c echo.Context) error {
 			zap.String("configmap_name", configMapName),
 			zap.Error(err))
 		// Clean up both resources since owner reference failed
-		if cleanupErr := h.k8sClient.DeleteStack(c.Request().Context(), namespace, stackName); cleanupErr != nil {
+		if cleanupErr := h.k8sClient.DeleteStack(ctx, namespace, stackName); cleanupErr != nil {
 			logging.Logger.Error("Failed to cleanup Stack after owner reference failure",
 				zap.String("stack_name", stackName),
 				zap.Error(cleanupErr))
 		}
-		if cleanupErr := h.k8sClient.DeleteConfigMap(c.Request().Context(), namespace, configMapName); cleanupErr != nil {
+		if cleanupErr := h.k8sClient.DeleteConfigMap(ctx, namespace, configMapName); cleanupErr != nil {
 			logging.Logger.Error("Failed to cleanup ConfigMap after owner reference failure",
 				zap.String("configmap_name", configMapName),
 				zap.Error(cleanupErr))
 		}
-		return c.String(500, "Failed to set owner reference")
+		return "Failed to set owner reference", err
 	}
 
 	// Update ConfigMap with owner reference
-	if err := h.k8sClient.UpdateConfigMap(c.Request().Context(), configMap); err != nil {
+	if err := h.k8sClient.UpdateConfigMap(ctx, configMap); err != nil {
 		logging.Logger.Error("Failed to update ConfigMap with owner reference",
 			zap.String("configmap_name", configMapName),
 			zap.String("namespace", namespace),
 			zap.Error(err))
 		// Clean up both resources since update failed
-		if cleanupErr := h.k8sClient.DeleteStack(c.Request().Context(), namespace, stackName); cleanupErr != nil {
+		if cleanupErr := h.k8sClient.DeleteStack(ctx, namespace, stackName); cleanupErr != nil {
 			logging.Logger.Error("Failed to cleanup Stack after ConfigMap update failure",
 				zap.String("stack_name", stackName),
 				zap.Error(cleanupErr))
 		}
-		if cleanupErr := h.k8sClient.DeleteConfigMap(c.Request().Context(), namespace, configMapName); cleanupErr != nil {
+		if cleanupErr := h.k8sClient.DeleteConfigMap(ctx, namespace, configMapName); cleanupErr != nil {
 			logging.Logger.Error("Failed to cleanup ConfigMap after update failure",
 				zap.String("configmap_name", configMapName),
 				zap.Error(cleanupErr))
 		}
-		return c.String(500, "Failed to update ConfigMap with owner reference")
+		return "Failed to update ConfigMap with owner reference", err
 	}
-
-	logging.Logger.Info("Stack created successfully",
-		zap.String("stack_name", stackName),
-		zap.String("namespace", namespace),
-		zap.String("user", user.Name))
-
-	// Return scoped identifier
-	identifier := h.nsManager.MustGenerateScopedID(namespace, stackName)
-	return c.String(201, identifier)
+	return "", nil
 }
 
 // GetStacks handles GET /stacks
@@ -446,6 +629,17 @@ func (h *Handler) GetStacks(c echo.Context) error {
 		}
 	}
 
+	// Optional ?tag= filter
+	if tag := c.QueryParam("tag"); tag != "" {
+		filtered := make([]envv1alpha1.Stack, 0, len(allStacks))
+		for i := range allStacks {
+			if hasTag(&allStacks[i], tag) {
+				filtered = append(filtered, allStacks[i])
+			}
+		}
+		allStacks = filtered
+	}
+
 	// Return list of stack objects (JSON marshaller handles serialization)
 	return c.JSON(200, allStacks)
 }
@@ -506,31 +700,62 @@ func (h *Handler) DeleteStack(c echo.Context) error {
 	// Resolve namespace from ID
 	targetNamespace, name, searchAll := h.nsManager.ResolveNamespaceFromID(idParam, allowedNS)
 
-	// Try to delete the stack
+	// Try to find the stack
 	userNS := h.nsManager.GetDeveloperNamespace(user.Name)
 	globalNS := h.nsManager.GetGlobalNamespace()
-	if h.deleteStack(c, targetNamespace, name, searchAll, userNS, globalNS, allowedNS) {
-		return c.NoContent(204)
+	stack, found := h.findStack(c, targetNamespace, name, searchAll, userNS, globalNS, allowedNS)
+	if !found {
+		return c.String(404, fmt.Sprintf("Stack '%s' not found", idParam))
 	}
 
-	return c.String(404, fmt.Sprintf("Stack '%s' not found", idParam))
-}
+	if rejected, err := common.RejectIfFrozen(c, h.nsManager, h.k8sClient, stack.Namespace, user.Name, "DELETE /stacks/"+idParam); rejected {
+		return err
+	}
+	unlock, rejected, err := h.lockNamespace(c, stack.Namespace)
+	if rejected {
+		return err
+	}
+	defer unlock()
 
-// deleteStack searches for and deletes a stack in the appropriate namespace(s)
-func (h *Handler) deleteStack(c echo.Context, targetNS, name string, searchAll bool, userNS, globalNS string, allowedNS []string) bool {
-	ctx := c.Request().Context()
+	// Protected stacks must be named explicitly to be deleted
+	if isProtected(stack) && !h.deleteConfirmed(stack, idParam, c.QueryParam("confirm")) {
+		logging.LogDeniedWithIP("stack_protected", user.Name, "DELETE /stacks/"+idParam, c.RealIP())
+		return c.String(412, fmt.Sprintf("Stack '%s' is protected; repeat the request with ?confirm=%s",
+			idParam, h.nsManager.MustGenerateScopedID(stack.Namespace, stack.Name)))
+	}
 
-	// Get ordered list of namespaces to search
-	namespaces := namespace.ResolveNamespacesToSearch(targetNS, userNS, globalNS, searchAll, allowedNS)
+	if err := h.k8sClient.DeleteStack(c.Request().Context(), stack.Namespace, stack.Name); err != nil {
+		logging.Logger.Error("Failed to delete stack",
+			zap.String("namespace", stack.Namespace),
+			zap.String("name", stack.Name),
+			zap.Error(err))
+		return c.String(500, "Failed to delete stack")
+	}
 
-	// Try to delete from each namespace in order
-	for _, ns := range namespaces {
-		if h.k8sClient.DeleteStack(ctx, ns, name) == nil {
-			return true
-		}
+	identifier := h.nsManager.MustGenerateScopedID(stack.Namespace, stack.Name)
+	h.notifier.Notify(notify.NewStackEvent(notify.EventStackDeleted, identifier, stack, user.Name))
+
+	// Plain clients keep the 204, JSON clients learn which URLs went away
+	if !common.AcceptsJSON(c) {
+		return c.NoContent(204)
 	}
+	return c.JSON(200, common.StackDeletedResponse{
+		ID:      identifier,
+		Exposed: exposedURLs(stack),
+	})
+}
 
-	return false
+// exposedURLs returns the URLs of the stack's exposed services, sorted by service
+// They are recorded in the stack images when the stack is created
+func exposedURLs(stack *envv1alpha1.Stack) []common.ExposedServiceInfo {
+	exposed := []common.ExposedServiceInfo{}
+	for service, info := range stack.Spec.Images {
+		if info.URL != "" {
+			exposed = append(exposed, common.ExposedServiceInfo{Service: service, URL: info.URL})
+		}
+	}
+	sort.Slice(exposed, func(i, j int) bool { return exposed[i].Service < exposed[j].Service })
+	return exposed
 }
 
 // UpdateStack handles PUT /stacks/:id
@@ -566,59 +791,98 @@ func (h *Handler) UpdateStack(c echo.Context) error {
 		return c.String(404, fmt.Sprintf("Stack '%s' not found", idParam))
 	}
 
+	if rejected, err := common.RejectIfFrozen(c, h.nsManager, h.k8sClient, stack.Namespace, user.Name, "PUT /stacks/"+idParam); rejected {
+		return err
+	}
+	unlock, rejected, err := h.lockNamespace(c, stack.Namespace)
+	if rejected {
+		return err
+	}
+	defer unlock()
+
+	// Re-read under the lock: an update that held it before may have changed the stack
+	stack, err = h.k8sClient.GetStack(c.Request().Context(), stack.Namespace, stack.Name)
+	if err != nil {
+		return c.String(404, fmt.Sprintf("Stack '%s' not found", idParam))
+	}
+
+	// Caller-supplied images must be approved and signed as the blueprint requires, like prepared and refreshed ones
+	if rejected, err := h.rejectUnapprovedImages(c, stack, req.Images); rejected {
+		return err
+	}
+
 	return h.updateStackImages(c, stack, req.Images, user.Name)
 }
 
+// rejectUnapprovedImages answers 422 when a changed image is missing from the blueprint's x-lissto.allowedDigests
+// or lacks a required verified signature, and 500 when the blueprint cannot be loaded.
+// It reports whether a response was written; the caller then returns the error as is
+func (h *Handler) rejectUnapprovedImages(c echo.Context, stack *envv1alpha1.Stack, images map[string]interface{}) (bool, error) {
+	ctx := c.Request().Context()
+	policy, err := h.loadImagePolicy(ctx, stack)
+	if err != nil {
+		logging.Logger.Error("Failed to load the stack's image policy",
+			zap.String("stack", stack.Name),
+			zap.String("namespace", stack.Namespace),
+			zap.Error(err))
+		return true, c.String(500, "Failed to load the stack's blueprint to check its images")
+	}
+	services := make([]string, 0, len(images))
+	for service := range images {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	for _, service := range services {
+		existing := stack.Spec.Images[service]
+		info := updatedImageInfo(existing, images[service])
+		if info.Digest == existing.Digest {
+			continue
+		}
+		if err := h.checkImage(ctx, policy, service, info.Digest); err != nil {
+			logging.Logger.Info("Rejected stack update with an unapproved image",
+				zap.String("stack", stack.Name),
+				zap.String("namespace", stack.Namespace),
+				zap.String("service", service),
+				zap.Error(err))
+			return true, c.String(422, fmt.Sprintf("Service %s: %v", service, err))
+		}
+	}
+	return false, nil
+}
+
 // updateStackImages is a helper to update stack images
 func (h *Handler) updateStackImages(c echo.Context, stack *envv1alpha1.Stack, images map[string]interface{}, userName string) error {
+	if err := h.applyStackImages(c.Request().Context(), stack, images, userName); err != nil {
+		return c.String(500, "Failed to update stack")
+	}
+
+	// Return updated stack identifier
+	identifier := h.nsManager.MustGenerateScopedID(stack.Namespace, stack.Name)
+	return c.JSON(200, map[string]interface{}{
+		"data": map[string]string{
+			"id": identifier,
+		},
+	})
+}
+
+// applyStackImages replaces the stack images, preserving URLs and container names, and notifies on success
+func (h *Handler) applyStackImages(ctx context.Context, stack *envv1alpha1.Stack, images map[string]interface{}, userName string) error {
 	// Build updated images map
 	updatedImages := make(map[string]envv1alpha1.ImageInfo)
 	for service, imageData := range images {
-		// Get existing info to preserve URL
-		existingInfo := stack.Spec.Images[service]
-
-		var newImage, newDigest string
-
-		// Handle both string (digest only) and object (digest + tag) formats
-		switch v := imageData.(type) {
-		case string:
-			// Legacy format: just digest, preserve existing tag
-			newDigest = v
-			newImage = existingInfo.Image
-		case map[string]interface{}:
-			// New format: object with digest and tag
-			if digest, ok := v["digest"].(string); ok {
-				newDigest = digest
-			}
-			if image, ok := v["image"].(string); ok && image != "" {
-				newImage = image
-			} else {
-				newImage = existingInfo.Image // Fallback to existing tag
-			}
-		default:
-			// Fallback: preserve existing
-			newDigest = existingInfo.Digest
-			newImage = existingInfo.Image
-		}
-
-		updatedImages[service] = envv1alpha1.ImageInfo{
-			Digest:        newDigest,
-			Image:         newImage,                   // Use new tag if provided
-			URL:           existingInfo.URL,           // Preserve URL
-			ContainerName: existingInfo.ContainerName, // Preserve container name
-		}
+		updatedImages[service] = updatedImageInfo(stack.Spec.Images[service], imageData)
 	}
 
 	// Update stack images
 	stack.Spec.Images = updatedImages
 
 	// Update in Kubernetes
-	if err := h.k8sClient.UpdateStack(c.Request().Context(), stack); err != nil {
+	if err := h.k8sClient.UpdateStack(ctx, stack); err != nil {
 		logging.Logger.Error("Failed to update stack",
 			zap.String("namespace", stack.Namespace),
 			zap.String("name", stack.Name),
 			zap.Error(err))
-		return c.String(500, "Failed to update stack")
+		return err
 	}
 
 	logging.Logger.Info("Stack updated successfully",
@@ -627,13 +891,105 @@ func (h *Handler) updateStackImages(c echo.Context, stack *envv1alpha1.Stack, im
 		zap.String("user", userName),
 		zap.Int("updated_services", len(updatedImages)))
 
-	// Return updated stack identifier
 	identifier := h.nsManager.MustGenerateScopedID(stack.Namespace, stack.Name)
-	return c.JSON(200, map[string]interface{}{
-		"data": map[string]string{
-			"id": identifier,
+	h.notifier.Notify(notify.NewStackEvent(notify.EventStackUpdated, identifier, stack, userName))
+	return nil
+}
+
+// updatedImageInfo returns a stack image updated with the request's image data, preserving URL and container name
+// The data is a digest (legacy, keeping the tag) or an object with digest and image
+func updatedImageInfo(existingInfo envv1alpha1.ImageInfo, imageData interface{}) envv1alpha1.ImageInfo {
+	var newImage, newDigest string
+
+	// Handle both string (digest only) and object (digest + tag) formats
+	switch v := imageData.(type) {
+	case string:
+		// Legacy format: just digest, preserve existing tag
+		newDigest = v
+		newImage = existingInfo.Image
+	case map[string]interface{}:
+		// New format: object with digest and tag
+		if digest, ok := v["digest"].(string); ok {
+			newDigest = digest
+		}
+		if image, ok := v["image"].(string); ok && image != "" {
+			newImage = image
+		} else {
+			newImage = existingInfo.Image // Fallback to existing tag
+		}
+	default:
+		// Fallback: preserve existing
+		newDigest = existingInfo.Digest
+		newImage = existingInfo.Image
+	}
+
+	return envv1alpha1.ImageInfo{
+		Digest:        newDigest,
+		Image:         newImage,                   // Use new tag if provided
+		URL:           existingInfo.URL,           // Preserve URL
+		ContainerName: existingInfo.ContainerName, // Preserve container name
+	}
+}
+
+// checkPendingImages returns an error listing pending services unless allowPending is set
+func checkPendingImages(images map[string]cache.ImageInfoCache, allowPending bool) error {
+	if allowPending {
+		return nil
+	}
+
+	var pending []string
+	for service, info := range images {
+		if info.Pending {
+			pending = append(pending, service)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	sort.Strings(pending)
+	return fmt.Errorf("services pending their first build: %s (set allow_pending to create the stack anyway)", strings.Join(pending, ", "))
+}
+
+// checkSignedImages returns an error listing services whose image signature must but did not verify at prepare
+func checkSignedImages(project *types.Project, images map[string]cache.ImageInfoCache, requireAll bool) error {
+	var unverified []string
+	for name, service := range project.Services {
+		if !requireAll && !image.RequiresSignature(service) {
+			continue
+		}
+		if !images[name].Verified {
+			unverified = append(unverified, name)
+		}
+	}
+	if len(unverified) == 0 {
+		return nil
+	}
+
+	sort.Strings(unverified)
+	return fmt.Errorf("images without a verified signature: %s", strings.Join(unverified, ", "))
+}
+
+// manifestsConfigMapName returns the name of the ConfigMap holding a stack's manifests
+func manifestsConfigMapName(stackName string) string {
+	return fmt.Sprintf("lissto-%s", stackName)
+}
+
+// newManifestsConfigMap builds the ConfigMap holding a stack's manifests, without owner reference
+func newManifestsConfigMap(namespace, stackName, manifests string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      manifestsConfigMapName(stackName),
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "lissto",
+				"lissto.dev/stack":             stackName,
+			},
 		},
-	})
+		Data: map[string]string{
+			"manifests.yaml": manifests,
+		},
+	}
 }
 
 // parseDockerCompose parses Docker Compose content into a project
@@ -650,11 +1006,22 @@ func (h *Handler) parseDockerCompose(composeContent string) (*types.Project, err
 			WorkingDir: "/tmp",
 		},
 		loader.WithSkipValidation,
+		compose.WithDeferredEnvFiles,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse Docker Compose content: %w", err)
 	}
 
+	// Merge env_file values bundled in x-lissto.envFiles
+	if err := compose.ResolveEnvFiles(project); err != nil {
+		return nil, fmt.Errorf("failed to parse Docker Compose content: %w", err)
+	}
+
+	// Apply x-lissto.defaults to services that don't set their own labels/settings
+	if err := compose.ApplyServiceDefaults(project); err != nil {
+		return nil, fmt.Errorf("failed to parse Docker Compose content: %w", err)
+	}
+
 	if project.Name == "" {
 		project.Name = "stack"
 	}
@@ -667,47 +1034,142 @@ func (h *Handler) parseDockerCompose(composeContent string) (*types.Project, err
 }
 
 // generateKubernetesManifests converts Docker Compose project to Kubernetes manifests using Kompose
-func (h *Handler) generateKubernetesManifests(project *types.Project, namespace, stackName string) (string, error) {
+// It also returns the number of generated objects
+func (h *Handler) generateKubernetesManifests(project *types.Project, namespace, stackName string, propagated propagatedMetadata) (string, int, error) {
 	// 1. Extract service labels before Kompose conversion (for command override)
 	serviceLabelMap := h.extractServiceLabels(project)
 
+	// 1.5. Extract volume size/storage class hints (Kompose drops driver options)
+	volumeStorage, err := compose.ExtractVolumeStorage(project)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to extract volume storage: %w", err)
+	}
+
+	// 1.6. Extract tmpfs mounts and read_only (Kompose drops sizes and turns tmpfs volumes into PVCs)
+	filesystems, err := compose.ExtractServiceFilesystems(project)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to extract service filesystems: %w", err)
+	}
+
+	// 1.7. Extract logging options (compose logging.options and lissto.dev/logging.* labels)
+	serviceLogging := compose.ExtractServiceLogging(project)
+
+	// 1.8. Extract extra_hosts (Kompose drops them)
+	serviceExtraHosts := compose.ExtractServiceExtraHosts(project)
+
+	// 1.8.1. Extract GPU device reservations (Kompose drops them)
+	serviceGPUs, err := compose.ExtractServiceGPUs(project)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to extract GPU reservations: %w", err)
+	}
+
+	// 1.9. Classify infra services (they get their own default resources)
+	infraServices := compose.InfraServices(project)
+
+	// 1.9.1. Extract restart policies (they pick the workload kind), then drop `on-failure:N` attempts Kompose rejects
+	restartPolicies := compose.ExtractServiceRestartPolicies(project)
+	compose.NormalizeRestartPolicies(project)
+
 	// 2. Serialize preprocessed project to compose YAML
 	ser := serializer.NewComposeSerializer()
 	composeYAML, err := ser.Serialize(project)
 	if err != nil {
-		return "", fmt.Errorf("failed to serialize Docker Compose: %w", err)
+		return "", 0, fmt.Errorf("failed to serialize Docker Compose: %w", err)
 	}
 
 	// 3. Convert with Kompose (pure conversion)
 	converter := kompose.NewConverter(namespace)
 	objects, err := converter.ConvertToObjects(composeYAML)
 	if err != nil {
-		return "", fmt.Errorf("kompose conversion failed: %w", err)
+		return "", 0, fmt.Errorf("kompose conversion failed: %w", err)
 	}
 
 	// 4. Post-process: normalize PVC accessModes to ReadWriteOnce
 	pvcNormalizer := postprocessor.NewPVCAccessModeNormalizer()
 	objects = pvcNormalizer.NormalizeAccessModes(objects)
 
+	// 4.5. Post-process: apply volume size and storage class to PVCs
+	storageInjector := postprocessor.NewPVCStorageInjector()
+	objects = storageInjector.InjectStorage(objects, volumeStorage)
+
 	// 5. Post-process: inject stack labels to pod templates
 	labelInjector := postprocessor.NewStackLabelInjector()
 	objects = labelInjector.InjectLabels(objects, stackName)
 
+	// 5.1. Post-process: stamp the labels and annotations the env propagates (lissto.dev/propagate.*)
+	objects = labelInjector.InjectMetadata(objects, propagated.Labels, propagated.Annotations)
+
 	// 6. Post-process: override commands based on lissto.dev labels
 	commandOverrider := postprocessor.NewCommandOverrider()
 	objects = commandOverrider.OverrideCommands(objects, serviceLabelMap)
 
+	// 6.1. Post-process: override working directory and run-as user based on lissto.dev labels
+	runtimeOverrider := postprocessor.NewContainerRuntimeOverrider()
+	objects = runtimeOverrider.OverrideRuntime(objects, serviceLabelMap)
+
+	// 6.1.1. Post-process: apply resource labels and the configured default requests/limits
+	resourceInjector := postprocessor.NewResourceLimitInjector(h.settings.Resources.App, h.settings.Resources.Infra)
+	objects = resourceInjector.Inject(objects, serviceLabelMap, infraServices)
+
+	// 6.1.2. Post-process: request nvidia.com/gpu for GPU device reservations
+	gpuInjector := postprocessor.NewGPUResourceInjector()
+	objects = gpuInjector.Inject(objects, serviceGPUs, serviceLabelMap)
+
+	// 6.1.3. Post-process: termination grace period and preStop hooks based on lissto.dev labels
+	lifecycleConfigurator := postprocessor.NewLifecycleConfigurator()
+	objects = lifecycleConfigurator.Configure(objects, serviceLabelMap)
+
+	// 6.1.4. Post-process: add the env's default variables and secrets (lissto.dev/default-*)
+	envDefaultsInjector := postprocessor.NewEnvDefaultsInjector()
+	objects = envDefaultsInjector.Inject(objects, propagated.Env, serviceLabelMap)
+
+	// 6.2. Post-process: mount tmpfs paths as memory emptyDirs and apply read_only
+	filesystemTranslator := postprocessor.NewFilesystemTranslator()
+	objects = filesystemTranslator.Translate(objects, filesystems)
+
+	// 6.3. Post-process: add host aliases and DNS servers from lissto.dev labels
+	networkConfigurator := postprocessor.NewPodNetworkConfigurator()
+	objects = networkConfigurator.Configure(objects, serviceLabelMap)
+
+	// 6.3.1. Post-process: merge compose extra_hosts into host aliases
+	extraHostsTranslator := postprocessor.NewExtraHostsTranslator()
+	objects = extraHostsTranslator.Translate(objects, serviceExtraHosts)
+
+	// 6.3.2. Post-process: make Services headless or sticky from lissto.dev labels
+	serviceOptions := postprocessor.NewServiceOptionsConfigurator()
+	objects = serviceOptions.Configure(objects, serviceLabelMap)
+
+	// 6.3.3. Post-process: add the cert-manager issuer annotation to ingresses in cert-manager TLS mode
+	ingressTLSAnnotator := postprocessor.NewIngressTLSAnnotator()
+	objects = ingressTLSAnnotator.Annotate(objects, serviceLabelMap)
+
+	// 6.4. Post-process: annotate pod templates with logging options for log shippers
+	loggingAnnotator := postprocessor.NewLoggingAnnotator()
+	objects = loggingAnnotator.Annotate(objects, serviceLogging)
+
+	// 6.4.1. Post-process: run services as Deployments, Jobs or Pods according to their restart policy
+	restartMapper := postprocessor.NewRestartPolicyMapper()
+	objects = restartMapper.Map(objects, restartPolicies)
+
+	// 6.5. Post-process: classify objects as state or workload (lissto.dev/class overrides the kind default)
+	classifier := postprocessor.NewResourceClassifier()
+	objects = classifier.Classify(objects, serviceLabelMap)
+
+	// 6.6. Post-process: order objects so namespaces and state are applied before workloads
+	orderer := postprocessor.NewResourceOrderer()
+	objects = orderer.Order(objects)
+
 	// 7. Serialize to YAML
 	yamlManifests, err := converter.SerializeToYAML(objects)
 	if err != nil {
-		return "", fmt.Errorf("YAML serialization failed: %w", err)
+		return "", 0, fmt.Errorf("YAML serialization failed: %w", err)
 	}
 
-	return yamlManifests, nil
+	return yamlManifests, len(objects), nil
 }
 
 // extractServiceLabels extracts labels from each service before Kompose conversion
-// This is needed for command override postprocessor which needs access to original labels
+// This is needed for label-driven postprocessors (command, working dir, user) which need the original labels
 func (h *Handler) extractServiceLabels(project *types.Project) map[string]map[string]string {
 	labelMap := make(map[string]map[string]string)
 	for name, service := range project.Services {
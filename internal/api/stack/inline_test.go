@@ -0,0 +1,81 @@
+package stack
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/config"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+var _ = Describe("Create stack from inline compose", func() {
+	const inlineCompose = `
+x-lissto:
+  title: scratch
+services:
+  api:
+    image: api
+`
+
+	var (
+		h     *Handler
+		alice *middleware.User
+	)
+
+	BeforeEach(func() {
+		alice = &middleware.User{Name: "alice", Role: authz.User}
+		env := &envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}}
+		h = newTestHandler(config.DefaultSettings(), nil, env)
+		h.cache = cache.NewMemoryCache()
+	})
+
+	cachePrepareResult := func(requestID, composeContent string) {
+		Expect(h.cache.Set(context.Background(), requestID, cache.PrepareResultCache{
+			Namespace: "dev-alice",
+			Images: map[string]cache.ImageInfoCache{
+				"api": {Digest: "registry.io/api@sha256:aaa", Image: "registry.io/api:main"},
+			},
+			Compose: composeContent,
+		}, time.Minute)).To(Succeed())
+	}
+
+	It("should create the stack from the cached compose without a blueprint", func() {
+		cachePrepareResult("req-1", inlineCompose)
+
+		c, rec := newTestContext(http.MethodPost, "/stacks", `{"env":"dev","request_id":"req-1"}`, alice)
+		Expect(h.CreateStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(201), rec.Body.String())
+
+		stack, err := h.k8sClient.GetStack(context.Background(), "dev-alice", rec.Body.String()[len("alice/"):])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stack.Spec.BlueprintReference).To(BeEmpty())
+		Expect(stack.Annotations).To(HaveKeyWithValue("lissto.dev/blueprint-title", "scratch"))
+		Expect(stack.Spec.Images).To(HaveKey("api"))
+	})
+
+	It("should reject a blueprint for an inline request ID", func() {
+		cachePrepareResult("req-1", inlineCompose)
+
+		c, rec := newTestContext(http.MethodPost, "/stacks", `{"blueprint":"alice/web","env":"dev","request_id":"req-1"}`, alice)
+		Expect(h.CreateStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(400))
+		Expect(rec.Body.String()).To(ContainSubstring("inline compose"))
+	})
+
+	It("should still require a blueprint for blueprint-based request IDs", func() {
+		cachePrepareResult("req-1", "")
+
+		c, rec := newTestContext(http.MethodPost, "/stacks", `{"env":"dev","request_id":"req-1"}`, alice)
+		Expect(h.CreateStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(400))
+		Expect(rec.Body.String()).To(Equal("blueprint is required"))
+	})
+})
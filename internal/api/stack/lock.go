@@ -0,0 +1,90 @@
+package stack
+
+import (
+	"context"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// NamespaceLocker serializes stack operations within a namespace while other namespaces proceed in parallel
+// The default is in-process; replicas sharing namespaces need a distributed implementation (e.g. a Lease).
+type NamespaceLocker interface {
+	// Lock blocks until the namespace is held or ctx is done and returns the function releasing it
+	Lock(ctx context.Context, namespace string) (unlock func(), err error)
+}
+
+// keyedMutex is the in-process NamespaceLocker, one lock per namespace dropped when nobody holds or awaits it
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*namespaceLock
+}
+
+// namespaceLock is held by whoever put the token in held; refs counts holders and waiters
+type namespaceLock struct {
+	held chan struct{}
+	refs int
+}
+
+// NewNamespaceLocker creates the in-process NamespaceLocker
+func NewNamespaceLocker() NamespaceLocker {
+	return &keyedMutex{locks: make(map[string]*namespaceLock)}
+}
+
+// Lock implements NamespaceLocker
+func (k *keyedMutex) Lock(ctx context.Context, namespace string) (func(), error) {
+	k.mu.Lock()
+	lock, ok := k.locks[namespace]
+	if !ok {
+		lock = &namespaceLock{held: make(chan struct{}, 1)}
+		k.locks[namespace] = lock
+	}
+	lock.refs++
+	k.mu.Unlock()
+
+	select {
+	case lock.held <- struct{}{}:
+	case <-ctx.Done():
+		k.release(namespace, lock)
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-lock.held
+			k.release(namespace, lock)
+		})
+	}, nil
+}
+
+// release drops a holder or waiter, removing the namespace's lock when it was the last
+func (k *keyedMutex) release(namespace string, lock *namespaceLock) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	lock.refs--
+	if lock.refs == 0 {
+		delete(k.locks, namespace)
+	}
+}
+
+// Locker returns the handler's NamespaceLocker, shared with handlers deleting stacks outside /stacks
+func (h *Handler) Locker() NamespaceLocker {
+	return h.locker
+}
+
+// lockNamespace waits for other stack operations in the namespace to finish; the caller defers the unlock
+// It reports whether a response was written (503 when the request ended while waiting); the caller then returns the error as is
+func (h *Handler) lockNamespace(c echo.Context, namespace string) (func(), bool, error) {
+	unlock, err := h.locker.Lock(c.Request().Context(), namespace)
+	if err != nil {
+		logging.Logger.Warn("Gave up waiting for the namespace lock",
+			zap.String("namespace", namespace),
+			zap.Error(err))
+		return nil, true, c.String(503, "Another stack operation in this namespace is in progress. Please retry.")
+	}
+	return unlock, false, nil
+}
@@ -0,0 +1,229 @@
+package stack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/config"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+var _ = Describe("Namespace locks", func() {
+	Describe("NewNamespaceLocker", func() {
+		var locker NamespaceLocker
+
+		BeforeEach(func() {
+			locker = NewNamespaceLocker()
+		})
+
+		// lockAsync takes the lock in the background and reports when it is held
+		lockAsync := func(namespace string) <-chan func() {
+			acquired := make(chan func(), 1)
+			go func() {
+				defer GinkgoRecover()
+				unlock, err := locker.Lock(context.Background(), namespace)
+				Expect(err).NotTo(HaveOccurred())
+				acquired <- unlock
+			}()
+			return acquired
+		}
+
+		It("should serialize holders of one namespace", func() {
+			unlock, err := locker.Lock(context.Background(), "dev-alice")
+			Expect(err).NotTo(HaveOccurred())
+
+			second := lockAsync("dev-alice")
+			Consistently(second, 100*time.Millisecond).ShouldNot(Receive())
+
+			unlock()
+			var unlockSecond func()
+			Eventually(second).Should(Receive(&unlockSecond))
+			unlockSecond()
+		})
+
+		It("should not block other namespaces", func() {
+			unlock, err := locker.Lock(context.Background(), "dev-alice")
+			Expect(err).NotTo(HaveOccurred())
+			defer unlock()
+
+			var unlockBob func()
+			Eventually(lockAsync("dev-bob")).Should(Receive(&unlockBob))
+			unlockBob()
+		})
+
+		It("should give up when the context ends and keep the lock usable", func() {
+			unlock, err := locker.Lock(context.Background(), "dev-alice")
+			Expect(err).NotTo(HaveOccurred())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			_, err = locker.Lock(ctx, "dev-alice")
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+
+			unlock()
+			unlock() // releasing twice is harmless
+			unlock, err = locker.Lock(context.Background(), "dev-alice")
+			Expect(err).NotTo(HaveOccurred())
+			unlock()
+			Expect(locker.(*keyedMutex).locks).To(BeEmpty())
+		})
+	})
+
+	Describe("CreateStack", func() {
+		const inlineCompose = `
+services:
+  api:
+    image: api
+`
+		var h *Handler
+
+		BeforeEach(func() {
+			h = newTestHandler(config.DefaultSettings(), nil,
+				&envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}},
+				&envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-bob"}},
+			)
+			h.cache = cache.NewMemoryCache()
+			for requestID, namespace := range map[string]string{"req-alice": "dev-alice", "req-bob": "dev-bob"} {
+				Expect(h.cache.Set(context.Background(), requestID, cache.PrepareResultCache{
+					Namespace: namespace,
+					Images: map[string]cache.ImageInfoCache{
+						"api": {Digest: "registry.io/api@sha256:aaa", Image: "registry.io/api:main"},
+					},
+					Compose: inlineCompose,
+				}, time.Minute)).To(Succeed())
+			}
+		})
+
+		// createAsync creates a stack in the background and reports the response code
+		createAsync := func(userName, requestID string) <-chan int {
+			done := make(chan int, 1)
+			go func() {
+				defer GinkgoRecover()
+				c, rec := newTestContext(http.MethodPost, "/stacks", `{"env":"dev","request_id":"`+requestID+`"}`,
+					&middleware.User{Name: userName, Role: authz.User})
+				Expect(h.CreateStack(c)).To(Succeed())
+				done <- rec.Code
+			}()
+			return done
+		}
+
+		It("should wait for the operation in progress in the namespace", func() {
+			unlock, err := h.locker.Lock(context.Background(), "dev-alice")
+			Expect(err).NotTo(HaveOccurred())
+
+			created := createAsync("alice", "req-alice")
+			Consistently(created, 100*time.Millisecond).ShouldNot(Receive())
+
+			unlock()
+			Eventually(created).Should(Receive(Equal(201)))
+		})
+
+		It("should create in other namespaces meanwhile", func() {
+			unlock, err := h.locker.Lock(context.Background(), "dev-alice")
+			Expect(err).NotTo(HaveOccurred())
+			defer unlock()
+
+			Eventually(createAsync("bob", "req-bob")).Should(Receive(Equal(201)))
+		})
+	})
+
+	Describe("Stack updates", func() {
+		var (
+			h     *Handler
+			alice *middleware.User
+		)
+
+		BeforeEach(func() {
+			alice = &middleware.User{Name: "alice", Role: authz.User}
+			h = newTestHandler(config.DefaultSettings(), nil,
+				&envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}},
+				&envv1alpha1.Stack{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "dev-alice"},
+					Spec: envv1alpha1.StackSpec{Env: "dev", Images: map[string]envv1alpha1.ImageInfo{
+						"api": {Image: "registry.io/api:main", Digest: "registry.io/api@sha256:aaa"},
+						"db":  {Image: "postgres:15", Digest: "postgres@sha256:old"},
+					}},
+				},
+			)
+		})
+
+		// updateAsync updates the stack's api digest in the background and reports the response code
+		updateAsync := func(digest string) <-chan int {
+			done := make(chan int, 1)
+			go func() {
+				defer GinkgoRecover()
+				c, rec := newTestContext(http.MethodPut, "/stacks/web", `{"images":{"api":"`+digest+`"}}`, alice)
+				c.SetParamNames("id")
+				c.SetParamValues("web")
+				Expect(h.UpdateStack(c)).To(Succeed())
+				done <- rec.Code
+			}()
+			return done
+		}
+
+		It("should serialize queued updates of a stack", func() {
+			unlock, err := h.locker.Lock(context.Background(), "dev-alice")
+			Expect(err).NotTo(HaveOccurred())
+
+			first := updateAsync("registry.io/api@sha256:bbb")
+			second := updateAsync("registry.io/api@sha256:ccc")
+			Consistently(first, 100*time.Millisecond).ShouldNot(Receive())
+
+			unlock()
+			Eventually(first).Should(Receive(Equal(200)))
+			Eventually(second).Should(Receive(Equal(200)))
+		})
+
+		It("should refresh images once the operation in progress in the namespace is done", func() {
+			h.imageResolver = &digestResolver{digests: map[string]string{
+				"registry.io/api:main": "registry.io/api@sha256:aaa",
+				"postgres:15":          "postgres@sha256:new",
+			}}
+			unlock, err := h.locker.Lock(context.Background(), "dev-alice")
+			Expect(err).NotTo(HaveOccurred())
+
+			done := make(chan common.RefreshEnvImagesResponse, 1)
+			go func() {
+				defer GinkgoRecover()
+				c, rec := newTestContext(http.MethodPost, "/envs/dev/refresh-images", "", alice)
+				c.SetParamNames("id")
+				c.SetParamValues("dev")
+				Expect(h.RefreshEnvImages(c)).To(Succeed())
+				var response common.RefreshEnvImagesResponse
+				Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+				done <- response
+			}()
+			Consistently(done, 100*time.Millisecond).ShouldNot(Receive())
+
+			// An update made meanwhile to another service is kept
+			stack, err := h.k8sClient.GetStack(context.Background(), "dev-alice", "web")
+			Expect(err).NotTo(HaveOccurred())
+			api := stack.Spec.Images["api"]
+			api.Digest = "registry.io/api@sha256:bbb"
+			stack.Spec.Images["api"] = api
+			Expect(h.k8sClient.UpdateStack(context.Background(), stack)).To(Succeed())
+			unlock()
+
+			var response common.RefreshEnvImagesResponse
+			Eventually(done).Should(Receive(&response))
+			Expect(response.Stacks).To(HaveLen(1))
+			Expect(response.Stacks[0].Errors).To(BeEmpty())
+			Expect(response.Stacks[0].Updated).To(BeTrue())
+
+			stack, err = h.k8sClient.GetStack(context.Background(), "dev-alice", "web")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stack.Spec.Images["db"].Digest).To(Equal("postgres@sha256:new"))
+			Expect(stack.Spec.Images["api"].Digest).To(Equal("registry.io/api@sha256:bbb"))
+		})
+	})
+})
@@ -0,0 +1,98 @@
+package stack
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/notify"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+var _ = Describe("Stack event notifications", func() {
+	var (
+		recorder *notify.RecordingNotifier
+		alice    *middleware.User
+	)
+
+	BeforeEach(func() {
+		recorder = notify.NewRecordingNotifier()
+		alice = &middleware.User{Name: "alice", Role: authz.User}
+	})
+
+	It("should dispatch a created event with the exposed URLs", func() {
+		env := &envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}}
+		blueprint := &envv1alpha1.Blueprint{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "dev-alice"},
+			Spec: envv1alpha1.BlueprintSpec{DockerCompose: `
+services:
+  api:
+    image: api
+  worker:
+    image: worker
+`},
+		}
+		h := newTestHandler(config.DefaultSettings(), nil, env, blueprint)
+		h.notifier = recorder
+		h.cache = cache.NewMemoryCache()
+		Expect(h.cache.Set(context.Background(), "req-1", cache.PrepareResultCache{
+			Namespace: "dev-alice",
+			Images: map[string]cache.ImageInfoCache{
+				"api":    {Digest: "registry.io/api@sha256:aaa", Image: "registry.io/api:main", URL: "api-dev.dev.lissto.dev"},
+				"worker": {Digest: "registry.io/worker@sha256:bbb", Image: "registry.io/worker:main"},
+			},
+		}, time.Minute)).To(Succeed())
+
+		c, rec := newTestContext(http.MethodPost, "/stacks", `{"blueprint":"alice/web","env":"dev","request_id":"req-1"}`, alice)
+		Expect(h.CreateStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(201), rec.Body.String())
+
+		events := recorder.Events()
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Type).To(Equal(notify.EventStackCreated))
+		Expect(events[0].StackID).To(Equal(rec.Body.String()))
+		Expect(events[0].Namespace).To(Equal("dev-alice"))
+		Expect(events[0].User).To(Equal("alice"))
+		Expect(events[0].URLs).To(Equal([]string{"api-dev.dev.lissto.dev"}))
+		Expect(events[0].Timestamp).NotTo(BeZero())
+	})
+
+	It("should dispatch a deleted event for the removed stack", func() {
+		h := newTestHandler(config.DefaultSettings(), nil, execStackObjects()...)
+		h.notifier = recorder
+
+		c, rec := newTestContext(http.MethodDelete, "/stacks/my-stack", "", alice)
+		c.SetParamNames("id")
+		c.SetParamValues("my-stack")
+		Expect(h.DeleteStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(204))
+
+		events := recorder.Events()
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Type).To(Equal(notify.EventStackDeleted))
+		Expect(events[0].StackID).To(Equal("alice/my-stack"))
+		Expect(events[0].Namespace).To(Equal("dev-alice"))
+		Expect(events[0].User).To(Equal("alice"))
+	})
+
+	It("should not dispatch when the stack does not exist", func() {
+		h := newTestHandler(config.DefaultSettings(), nil)
+		h.notifier = recorder
+
+		c, rec := newTestContext(http.MethodDelete, "/stacks/missing", "", alice)
+		c.SetParamNames("id")
+		c.SetParamValues("missing")
+		Expect(h.DeleteStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(404))
+
+		Expect(recorder.Events()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,45 @@
+package stack
+
+import (
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// Response headers carrying the generated object count and a warning when it is high
+const (
+	ObjectCountHeader = "X-Lissto-Object-Count"
+	WarningHeader     = "X-Lissto-Warning"
+)
+
+// checkObjectCount reports the generated object count in a response header and enforces
+// api.manifests: above warnObjects a warning header is added, above maxObjects the stack
+// is rejected with 400. It reports whether a response was written.
+func (h *Handler) checkObjectCount(c echo.Context, blueprint string, count int) (bool, error) {
+	warn, max := h.settings.Manifests.ObjectLimits()
+	c.Response().Header().Set(ObjectCountHeader, fmt.Sprint(count))
+
+	if count > max {
+		logging.Logger.Error("Generated Kubernetes objects exceed the limit",
+			zap.String("blueprint", blueprint),
+			zap.Int("objects", count),
+			zap.Int("limit", max))
+		return true, c.String(400, fmt.Sprintf(
+			"Blueprint generates %d Kubernetes objects, above the limit of %d; reduce services or ask an admin to raise api.manifests.maxObjects",
+			count, max))
+	}
+
+	if count > warn {
+		logging.Logger.Warn("Generated Kubernetes object count is high",
+			zap.String("blueprint", blueprint),
+			zap.Int("objects", count),
+			zap.Int("warn_threshold", warn),
+			zap.Int("limit", max))
+		c.Response().Header().Set(WarningHeader, fmt.Sprintf(
+			"stack generates %d Kubernetes objects (warning above %d, limit %d)", count, warn, max))
+	}
+	return false, nil
+}
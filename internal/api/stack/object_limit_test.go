@@ -0,0 +1,92 @@
+package stack
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/config"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+var _ = Describe("Generated object count limits", func() {
+	// Three services without ports generate three Deployments
+	const compose = `
+services:
+  api:
+    image: api
+  worker:
+    image: worker
+  cron:
+    image: cron
+`
+
+	alice := &middleware.User{Name: "alice", Role: authz.User}
+
+	createStack := func(manifests config.ManifestSettings) (int, http.Header, string) {
+		settings := config.DefaultSettings()
+		settings.Manifests = manifests
+		env := &envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}}
+		h := newTestHandler(settings, nil, env)
+		h.cache = cache.NewMemoryCache()
+
+		images := map[string]cache.ImageInfoCache{}
+		for _, service := range []string{"api", "worker", "cron"} {
+			images[service] = cache.ImageInfoCache{Digest: "registry.io/" + service + "@sha256:aaa"}
+		}
+		Expect(h.cache.Set(context.Background(), "req-1", cache.PrepareResultCache{
+			Namespace: "dev-alice",
+			Images:    images,
+			Compose:   compose,
+		}, time.Minute)).To(Succeed())
+
+		c, rec := newTestContext(http.MethodPost, "/stacks", `{"env":"dev","request_id":"req-1"}`, alice)
+		Expect(h.CreateStack(c)).To(Succeed())
+		return rec.Code, rec.Header(), rec.Body.String()
+	}
+
+	It("should report the object count without a warning under the threshold", func() {
+		code, header, body := createStack(config.ManifestSettings{})
+		Expect(code).To(Equal(201), body)
+		Expect(header.Get(ObjectCountHeader)).To(Equal("3"))
+		Expect(header.Get(WarningHeader)).To(BeEmpty())
+	})
+
+	It("should warn above the warning threshold", func() {
+		code, header, body := createStack(config.ManifestSettings{WarnObjects: 2, MaxObjects: 10})
+		Expect(code).To(Equal(201), body)
+		Expect(header.Get(ObjectCountHeader)).To(Equal("3"))
+		Expect(header.Get(WarningHeader)).To(ContainSubstring("3 Kubernetes objects"))
+	})
+
+	It("should reject stacks above the cap", func() {
+		code, header, body := createStack(config.ManifestSettings{MaxObjects: 2})
+		Expect(code).To(Equal(400))
+		Expect(header.Get(ObjectCountHeader)).To(Equal("3"))
+		Expect(body).To(ContainSubstring("3 Kubernetes objects, above the limit of 2"))
+	})
+})
+
+var _ = Describe("ManifestSettings", func() {
+	It("should apply defaults and keep the warning below a lowered cap", func() {
+		warn, max := config.ManifestSettings{}.ObjectLimits()
+		Expect(warn).To(Equal(config.DefaultWarnObjects))
+		Expect(max).To(Equal(config.DefaultMaxObjects))
+
+		warn, max = config.ManifestSettings{MaxObjects: 100}.ObjectLimits()
+		Expect(warn).To(Equal(100))
+		Expect(max).To(Equal(100))
+	})
+
+	It("should reject a warning threshold above the cap", func() {
+		Expect(config.ManifestSettings{WarnObjects: 50, MaxObjects: 10}.Validate()).To(HaveOccurred())
+		Expect(config.ManifestSettings{MaxObjects: 1000}.Validate()).To(Succeed())
+	})
+})
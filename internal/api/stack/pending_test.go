@@ -0,0 +1,33 @@
+package stack
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/cache"
+)
+
+var _ = Describe("checkPendingImages", func() {
+	images := map[string]cache.ImageInfoCache{
+		"web":    {Digest: "registry.io/web@sha256:abc", Image: "registry.io/web:main"},
+		"worker": {Image: "registry.io/worker:abc123", Pending: true},
+		"api":    {Image: "registry.io/api:abc123", Pending: true},
+	}
+
+	It("should reject pending services by default", func() {
+		err := checkPendingImages(images, false)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("api, worker"))
+	})
+
+	It("should accept pending services when explicitly allowed", func() {
+		Expect(checkPendingImages(images, true)).To(Succeed())
+	})
+
+	It("should accept results without pending services", func() {
+		resolved := map[string]cache.ImageInfoCache{
+			"web": {Digest: "registry.io/web@sha256:abc", Image: "registry.io/web:main"},
+		}
+		Expect(checkPendingImages(resolved, false)).To(Succeed())
+	})
+})
@@ -0,0 +1,318 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+// PrepullLabel marks the pods of a stack's prepull Job, its value is the stack name
+const PrepullLabel = "lissto.dev/prepull"
+
+// Prepull Job and image states reported by GetPrepullStatus
+const (
+	PrepullRunning   = "running"
+	PrepullSucceeded = "succeeded"
+	PrepullFailed    = "failed"
+
+	PrepullImagePending = "pending"
+	PrepullImagePulled  = "pulled"
+	PrepullImageFailed  = "failed"
+)
+
+// PrepullStack handles POST /stacks/:id/prepull
+// Starts a Job pulling every image of the stack with the stack's pull secrets, replacing a previous one.
+// The stack itself is not touched; poll GET /stacks/:id/prepull for completion.
+func (h *Handler) PrepullStack(c echo.Context) error {
+	idParam := c.Param("id")
+	user, _ := middleware.GetUserFromContext(c)
+	endpoint := fmt.Sprintf("POST /stacks/%s/prepull", idParam)
+
+	if !h.settings.Prepull.Enabled {
+		return c.String(403, "Image pre-pulling is disabled on this server")
+	}
+
+	// Locate the stack with read access; update access is checked separately below
+	allowedNS := h.authorizer.GetAllowedNamespaces(user.Role, authz.ActionRead, authz.ResourceStack, user.Name)
+	if len(allowedNS) == 0 {
+		return c.String(403, "Permission denied: no accessible namespaces")
+	}
+
+	// Resolve namespace from ID
+	targetNamespace, name, searchAll := h.nsManager.ResolveNamespaceFromID(idParam, allowedNS)
+
+	userNS := h.nsManager.GetDeveloperNamespace(user.Name)
+	globalNS := h.nsManager.GetGlobalNamespace()
+	stack, found := h.findStack(c, targetNamespace, name, searchAll, userNS, globalNS, allowedNS)
+	if !found {
+		return c.String(404, fmt.Sprintf("Stack '%s' not found", idParam))
+	}
+
+	// The Job runs in the stack's namespace: owners prepull their own stacks, admins any stack
+	if user.Role != authz.Admin {
+		perm := h.authorizer.CanAccess(user.Role, authz.ActionUpdate, authz.ResourceStack, stack.Namespace, user.Name)
+		if !perm.Allowed {
+			logging.LogDeniedWithIP("insufficient_permissions", user.Name, endpoint, c.RealIP())
+			return c.String(403, fmt.Sprintf("Permission denied: %s", perm.Reason))
+		}
+	}
+
+	if rejected, err := common.RejectIfFrozen(c, h.nsManager, h.k8sClient, stack.Namespace, user.Name, endpoint); rejected {
+		return err
+	}
+
+	images := stackImages(stack)
+	if len(images) == 0 {
+		return c.String(400, fmt.Sprintf("Stack '%s' has no images to pull", idParam))
+	}
+
+	ctx := c.Request().Context()
+	pullSecrets, err := h.stackPullSecrets(ctx, stack)
+	if err != nil {
+		logging.Logger.Error("Failed to collect stack pull secrets",
+			zap.String("stack", stack.Name),
+			zap.String("namespace", stack.Namespace),
+			zap.Error(err))
+		return c.String(500, "Failed to start image pre-pulling")
+	}
+
+	job := newPrepullJob(stack, images, pullSecrets, h.settings.Prepull.TTL())
+	if err := h.k8sClient.DeleteJob(ctx, job.Namespace, job.Name); err != nil {
+		logging.Logger.Error("Failed to delete previous prepull Job",
+			zap.String("job", job.Name),
+			zap.String("namespace", job.Namespace),
+			zap.Error(err))
+		return c.String(500, "Failed to start image pre-pulling")
+	}
+	if err := h.k8sClient.CreateJob(ctx, job); err != nil {
+		logging.Logger.Error("Failed to create prepull Job",
+			zap.String("job", job.Name),
+			zap.String("namespace", job.Namespace),
+			zap.Error(err))
+		return c.String(500, "Failed to start image pre-pulling")
+	}
+
+	logging.Logger.Info("Started image pre-pulling",
+		zap.String("user", user.Name),
+		zap.String("stack", stack.Name),
+		zap.String("namespace", stack.Namespace),
+		zap.Int("images", len(images)),
+		zap.Strings("pull_secrets", pullSecrets))
+
+	return c.JSON(202, prepullStatus(h.nsManager.MustGenerateScopedID(stack.Namespace, stack.Name), job, nil))
+}
+
+// GetPrepullStatus handles GET /stacks/:id/prepull
+func (h *Handler) GetPrepullStatus(c echo.Context) error {
+	idParam := c.Param("id")
+	user, _ := middleware.GetUserFromContext(c)
+
+	if !h.settings.Prepull.Enabled {
+		return c.String(403, "Image pre-pulling is disabled on this server")
+	}
+
+	allowedNS := h.authorizer.GetAllowedNamespaces(user.Role, authz.ActionRead, authz.ResourceStack, user.Name)
+	if len(allowedNS) == 0 {
+		return c.String(403, "Permission denied: no accessible namespaces")
+	}
+
+	// Resolve namespace from ID
+	targetNamespace, name, searchAll := h.nsManager.ResolveNamespaceFromID(idParam, allowedNS)
+
+	userNS := h.nsManager.GetDeveloperNamespace(user.Name)
+	globalNS := h.nsManager.GetGlobalNamespace()
+	stack, found := h.findStack(c, targetNamespace, name, searchAll, userNS, globalNS, allowedNS)
+	if !found {
+		return c.String(404, fmt.Sprintf("Stack '%s' not found", idParam))
+	}
+
+	ctx := c.Request().Context()
+	job, err := h.k8sClient.GetJob(ctx, stack.Namespace, prepullJobName(stack.Name))
+	if apierrors.IsNotFound(err) {
+		return c.String(404, fmt.Sprintf("No image pre-pulling for stack '%s', it finished more than %ds ago or never ran",
+			idParam, h.settings.Prepull.TTL()))
+	}
+	if err != nil {
+		logging.Logger.Error("Failed to get prepull Job",
+			zap.String("stack", stack.Name),
+			zap.String("namespace", stack.Namespace),
+			zap.Error(err))
+		return c.String(500, "Failed to get image pre-pulling status")
+	}
+
+	pods, err := h.k8sClient.ListPodsWithLabels(ctx, stack.Namespace, map[string]string{PrepullLabel: stack.Name})
+	if err != nil {
+		logging.Logger.Error("Failed to list prepull pods",
+			zap.String("stack", stack.Name),
+			zap.String("namespace", stack.Namespace),
+			zap.Error(err))
+		return c.String(500, "Failed to get image pre-pulling status")
+	}
+
+	return c.JSON(200, prepullStatus(h.nsManager.MustGenerateScopedID(stack.Namespace, stack.Name), job, pods.Items))
+}
+
+// prepullJobName returns the name of a stack's prepull Job
+func prepullJobName(stackName string) string {
+	return fmt.Sprintf("lissto-prepull-%s", stackName)
+}
+
+// stackImages returns the distinct images deployed by the stack, sorted
+func stackImages(stack *envv1alpha1.Stack) []string {
+	seen := map[string]bool{}
+	var images []string
+	for _, info := range stack.Spec.Images {
+		image := info.Digest
+		if image == "" {
+			image = info.Image
+		}
+		if image != "" && !seen[image] {
+			seen[image] = true
+			images = append(images, image)
+		}
+	}
+	sort.Strings(images)
+	return images
+}
+
+// stackPullSecrets returns the image pull secrets used by the stack's Deployments and StatefulSets, sorted
+func (h *Handler) stackPullSecrets(ctx context.Context, stack *envv1alpha1.Stack) ([]string, error) {
+	workloads, err := h.k8sClient.ListWorkloadsWithPodLabels(ctx, stack.Namespace, map[string]string{"lissto.dev/stack": stack.Name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workloads: %w", err)
+	}
+
+	seen := map[string]bool{}
+	var secrets []string
+	for _, workload := range workloads {
+		var refs []corev1.LocalObjectReference
+		switch w := workload.(type) {
+		case *appsv1.Deployment:
+			refs = w.Spec.Template.Spec.ImagePullSecrets
+		case *appsv1.StatefulSet:
+			refs = w.Spec.Template.Spec.ImagePullSecrets
+		}
+		for _, ref := range refs {
+			if ref.Name != "" && !seen[ref.Name] {
+				seen[ref.Name] = true
+				secrets = append(secrets, ref.Name)
+			}
+		}
+	}
+	sort.Strings(secrets)
+	return secrets, nil
+}
+
+// newPrepullJob builds a Job with one container per image. The kubelet pulls an image before
+// starting its container, so a container that started (whatever its exit code) has its image on
+// the node; the commands are not meant to succeed in every image and their results are ignored.
+func newPrepullJob(stack *envv1alpha1.Stack, images, pullSecrets []string, ttl int32) *batchv1.Job {
+	labels := map[string]string{
+		"app.kubernetes.io/managed-by": "lissto",
+		PrepullLabel:                   stack.Name,
+	}
+
+	containers := make([]corev1.Container, 0, len(images))
+	for i, image := range images {
+		containers = append(containers, corev1.Container{
+			Name:            fmt.Sprintf("image-%d", i),
+			Image:           image,
+			Command:         []string{"true"},
+			ImagePullPolicy: corev1.PullIfNotPresent,
+		})
+	}
+	refs := make([]corev1.LocalObjectReference, 0, len(pullSecrets))
+	for _, secret := range pullSecrets {
+		refs = append(refs, corev1.LocalObjectReference{Name: secret})
+	}
+
+	backoffLimit := int32(0)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      prepullJobName(stack.Name),
+			Namespace: stack.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            &backoffLimit,
+			TTLSecondsAfterFinished: &ttl,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy:    corev1.RestartPolicyNever,
+					Containers:       containers,
+					ImagePullSecrets: refs,
+				},
+			},
+		},
+	}
+}
+
+// prepullStatus reports the pull state of each image of the Job from its pods' container statuses
+func prepullStatus(stackID string, job *batchv1.Job, pods []corev1.Pod) common.PrepullResponse {
+	statuses := map[string]common.PrepullImageStatus{}
+	for _, pod := range pods {
+		for _, container := range pod.Status.ContainerStatuses {
+			status := imagePullStatus(container.State)
+			// Keep the most advanced state across retried pods
+			if previous, ok := statuses[container.Name]; !ok || previous.Status != PrepullImagePulled {
+				statuses[container.Name] = status
+			}
+		}
+	}
+
+	response := common.PrepullResponse{
+		Stack:  stackID,
+		Job:    job.Name,
+		Status: PrepullSucceeded,
+		Images: []common.PrepullImageStatus{},
+	}
+	for _, ref := range job.Spec.Template.Spec.ImagePullSecrets {
+		response.PullSecrets = append(response.PullSecrets, ref.Name)
+	}
+	for _, container := range job.Spec.Template.Spec.Containers {
+		status, ok := statuses[container.Name]
+		if !ok {
+			status.Status = PrepullImagePending
+		}
+		status.Image = container.Image
+		response.Images = append(response.Images, status)
+
+		switch {
+		case status.Status == PrepullImageFailed:
+			response.Status = PrepullFailed
+		case status.Status == PrepullImagePending && response.Status != PrepullFailed:
+			response.Status = PrepullRunning
+		}
+	}
+	sort.Slice(response.Images, func(i, j int) bool { return response.Images[i].Image < response.Images[j].Image })
+	return response
+}
+
+// imagePullStatus maps a container state to the pull state of its image
+func imagePullStatus(state corev1.ContainerState) common.PrepullImageStatus {
+	switch {
+	case state.Running != nil, state.Terminated != nil:
+		return common.PrepullImageStatus{Status: PrepullImagePulled}
+	case state.Waiting != nil:
+		switch state.Waiting.Reason {
+		case "ErrImagePull", "ImagePullBackOff", "InvalidImageName", "ErrImageNeverPull":
+			return common.PrepullImageStatus{Status: PrepullImageFailed, Message: state.Waiting.Message}
+		}
+	}
+	return common.PrepullImageStatus{Status: PrepullImagePending}
+}
@@ -0,0 +1,122 @@
+package stack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+var _ = Describe("Stack image pre-pulling", func() {
+	var (
+		alice    *middleware.User
+		settings *config.Settings
+		stack    *envv1alpha1.Stack
+		workload *appsv1.Deployment
+	)
+
+	BeforeEach(func() {
+		alice = &middleware.User{Name: "alice", Role: authz.User}
+		settings = config.DefaultSettings()
+		settings.Prepull.Enabled = true
+
+		stack = &envv1alpha1.Stack{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "dev-alice"},
+			Spec: envv1alpha1.StackSpec{Images: map[string]envv1alpha1.ImageInfo{
+				"web":    {Digest: "registry.example.com/web@sha256:abc"},
+				"worker": {Digest: "registry.example.com/worker@sha256:def"},
+				"cache":  {Image: "redis:7"},
+			}},
+		}
+		workload = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "dev-alice"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"lissto.dev/stack": "web", "io.kompose.service": "web"}},
+				Spec:       corev1.PodSpec{ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry-creds"}}},
+			}},
+		}
+	})
+
+	prepull := func(h *Handler) (int, string) {
+		c, rec := newTestContext(http.MethodPost, "/stacks/web/prepull", "", alice)
+		c.SetParamNames("id")
+		c.SetParamValues("web")
+		Expect(h.PrepullStack(c)).To(Succeed())
+		return rec.Code, rec.Body.String()
+	}
+
+	It("should create a Job pulling every stack image with the stack's pull secrets", func() {
+		other := workload.DeepCopy()
+		other.Name = "other"
+		other.Spec.Template.Labels = map[string]string{"lissto.dev/stack": "other"}
+		other.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "other-creds"}}
+		h := newTestHandler(settings, nil, stack, workload, other)
+
+		code, body := prepull(h)
+		Expect(code).To(Equal(202), body)
+
+		job, err := h.k8sClient.GetJob(context.Background(), "dev-alice", "lissto-prepull-web")
+		Expect(err).NotTo(HaveOccurred())
+		var images []string
+		for _, container := range job.Spec.Template.Spec.Containers {
+			images = append(images, container.Image)
+		}
+		Expect(images).To(ConsistOf("registry.example.com/web@sha256:abc", "registry.example.com/worker@sha256:def", "redis:7"))
+		Expect(job.Spec.Template.Spec.ImagePullSecrets).To(Equal([]corev1.LocalObjectReference{{Name: "registry-creds"}}))
+		Expect(*job.Spec.TTLSecondsAfterFinished).To(Equal(int32(config.DefaultPrepullTTLSeconds)))
+
+		var resp common.PrepullResponse
+		Expect(json.Unmarshal([]byte(body), &resp)).To(Succeed())
+		Expect(resp.Status).To(Equal(PrepullRunning))
+		Expect(resp.Images).To(HaveLen(3))
+		Expect(resp.PullSecrets).To(Equal([]string{"registry-creds"}))
+	})
+
+	It("should report pulled and failed images from the Job's pod", func() {
+		h := newTestHandler(settings, nil, stack, workload)
+		code, body := prepull(h)
+		Expect(code).To(Equal(202), body)
+
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "lissto-prepull-web-x", Namespace: "dev-alice", Labels: map[string]string{PrepullLabel: "web"}},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "image-0", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 127}}},
+				{Name: "image-1", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "not found"}}},
+			}},
+		}
+		Expect(h.k8sClient.Create(context.Background(), pod)).To(Succeed())
+
+		c, rec := newTestContext(http.MethodGet, "/stacks/web/prepull", "", alice)
+		c.SetParamNames("id")
+		c.SetParamValues("web")
+		Expect(h.GetPrepullStatus(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+
+		var resp common.PrepullResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.Status).To(Equal(PrepullFailed))
+		Expect(resp.Images).To(Equal([]common.PrepullImageStatus{
+			{Image: "redis:7", Status: PrepullImagePulled},
+			{Image: "registry.example.com/web@sha256:abc", Status: PrepullImageFailed, Message: "not found"},
+			{Image: "registry.example.com/worker@sha256:def", Status: PrepullImagePending},
+		}))
+	})
+
+	It("should be disabled by default", func() {
+		h := newTestHandler(config.DefaultSettings(), nil, stack, workload)
+
+		code, _ := prepull(h)
+		Expect(code).To(Equal(403))
+	})
+})
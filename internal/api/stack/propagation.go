@@ -0,0 +1,156 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/lissto-dev/api/pkg/secretref"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+// EnvPropagatePrefix marks Env labels and annotations copied onto the env's stacks and their resources
+// with the prefix removed, e.g. lissto.dev/propagate.cost-center: "4711" becomes cost-center: "4711"
+const EnvPropagatePrefix = "lissto.dev/propagate."
+
+// Env annotations naming the LisstoVariables and LisstoSecrets (comma-separated, in the env's namespace)
+// every stack of the env receives as env vars; services opt out with lissto.dev/env-defaults: "false"
+const (
+	EnvDefaultVariablesAnnotation = "lissto.dev/default-variables"
+	EnvDefaultSecretsAnnotation   = "lissto.dev/default-secrets"
+)
+
+// propagatedMetadata holds what an env propagates to its stacks
+type propagatedMetadata struct {
+	Labels      map[string]string
+	Annotations map[string]string
+	Env         []corev1.EnvVar // Default variables and secrets, see envDefaults
+}
+
+// envPropagation returns the labels and annotations of env marked for propagation
+func envPropagation(env *envv1alpha1.Env) propagatedMetadata {
+	if env == nil {
+		return propagatedMetadata{}
+	}
+	return propagatedMetadata{
+		Labels:      propagatedKeys(env.Labels),
+		Annotations: propagatedKeys(env.Annotations),
+	}
+}
+
+// propagatedKeys returns the entries under EnvPropagatePrefix with the prefix removed
+func propagatedKeys(values map[string]string) map[string]string {
+	var propagated map[string]string
+	for key, value := range values {
+		name := strings.TrimPrefix(key, EnvPropagatePrefix)
+		if name == key || name == "" {
+			continue
+		}
+		if propagated == nil {
+			propagated = make(map[string]string)
+		}
+		propagated[name] = value
+	}
+	return propagated
+}
+
+// applyPropagation stamps the propagated labels and annotations on the stack, keeping keys it already has
+func applyPropagation(stack *envv1alpha1.Stack, propagated propagatedMetadata) {
+	if len(propagated.Labels) > 0 && stack.Labels == nil {
+		stack.Labels = make(map[string]string)
+	}
+	for key, value := range propagated.Labels {
+		if _, exists := stack.Labels[key]; !exists {
+			stack.Labels[key] = value
+		}
+	}
+	if len(propagated.Annotations) > 0 && stack.Annotations == nil {
+		stack.Annotations = make(map[string]string)
+	}
+	for key, value := range propagated.Annotations {
+		if _, exists := stack.Annotations[key]; !exists {
+			stack.Annotations[key] = value
+		}
+	}
+}
+
+// envDefaults builds the env vars of the env's default variables and secrets
+// Variables come first, then secrets; a key set by an earlier one is not repeated.
+func (h *Handler) envDefaults(ctx context.Context, env *envv1alpha1.Env) ([]corev1.EnvVar, error) {
+	variableNames := annotationList(env, EnvDefaultVariablesAnnotation)
+	secretNames := annotationList(env, EnvDefaultSecretsAnnotation)
+	if len(variableNames) == 0 && len(secretNames) == 0 {
+		return nil, nil
+	}
+
+	secretList, err := h.k8sClient.ListLisstoSecrets(ctx, env.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	secrets := make(map[string]*envv1alpha1.LisstoSecret, len(secretList.Items))
+	for i := range secretList.Items {
+		secrets[secretList.Items[i].Name] = &secretList.Items[i]
+	}
+
+	var envVars []corev1.EnvVar
+	seen := make(map[string]bool)
+	add := func(envVar corev1.EnvVar) {
+		if !seen[envVar.Name] {
+			seen[envVar.Name] = true
+			envVars = append(envVars, envVar)
+		}
+	}
+
+	for _, name := range variableNames {
+		variable, err := h.k8sClient.GetLisstoVariable(ctx, env.Namespace, name)
+		if err != nil {
+			return nil, fmt.Errorf("default variable %q: %w", name, err)
+		}
+		if variable.GetScope() == "env" && variable.Spec.Env != env.Name {
+			return nil, fmt.Errorf("default variable %q belongs to env %q", name, variable.Spec.Env)
+		}
+		keys := make([]string, 0, len(variable.Spec.Data))
+		for key := range variable.Spec.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			envVar, err := secretref.EnvVar(key, variable.Spec.Data[key], secretList.Items, env.Name)
+			if err != nil {
+				return nil, fmt.Errorf("default variable %q: %w", name, err)
+			}
+			add(envVar)
+		}
+	}
+
+	for _, name := range secretNames {
+		secret, ok := secrets[name]
+		if !ok {
+			return nil, fmt.Errorf("default secret %q not found", name)
+		}
+		if secret.GetScope() == "env" && secret.Spec.Env != env.Name {
+			return nil, fmt.Errorf("default secret %q belongs to env %q", name, secret.Spec.Env)
+		}
+		for _, key := range secret.Spec.Keys {
+			add(corev1.EnvVar{Name: key, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secret.GetSecretRef()},
+				Key:                  key,
+			}}})
+		}
+	}
+	return envVars, nil
+}
+
+// annotationList splits a comma-separated annotation, skipping empty entries
+func annotationList(env *envv1alpha1.Env, annotation string) []string {
+	var values []string
+	for _, value := range strings.Split(env.Annotations[annotation], ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,123 @@
+package stack
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/config"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+var _ = Describe("Env propagation", func() {
+	// createStack creates a stack from inline compose in env and returns it with its Deployments by name
+	createStack := func(env *envv1alpha1.Env, composeContent string, objects ...client.Object) (*envv1alpha1.Stack, map[string]*appsv1.Deployment) {
+		h := newTestHandler(config.DefaultSettings(), nil, append(objects, env)...)
+		h.cache = cache.NewMemoryCache()
+		Expect(h.cache.Set(context.Background(), "req-1", cache.PrepareResultCache{
+			Namespace: "dev-alice",
+			Images: map[string]cache.ImageInfoCache{
+				"api":    {Digest: "registry.io/api@sha256:aaa", Image: "registry.io/api:main"},
+				"worker": {Digest: "registry.io/worker@sha256:bbb", Image: "registry.io/worker:main"},
+			},
+			Compose: composeContent,
+		}, time.Minute)).To(Succeed())
+
+		c, rec := newTestContext(http.MethodPost, "/stacks", `{"env":"dev","request_id":"req-1"}`, &middleware.User{Name: "alice", Role: authz.User})
+		Expect(h.CreateStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(201), rec.Body.String())
+
+		stack, err := h.k8sClient.GetStack(context.Background(), "dev-alice", strings.TrimPrefix(rec.Body.String(), "alice/"))
+		Expect(err).NotTo(HaveOccurred())
+
+		configMap := &corev1.ConfigMap{}
+		Expect(h.k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "dev-alice", Name: stack.Spec.ManifestsConfigMapRef}, configMap)).To(Succeed())
+		deployments := make(map[string]*appsv1.Deployment)
+		for _, document := range strings.Split(configMap.Data["manifests.yaml"], "\n---\n") {
+			if strings.Contains(document, "kind: Deployment") {
+				deployment := &appsv1.Deployment{}
+				Expect(yaml.Unmarshal([]byte(document), deployment)).To(Succeed())
+				deployments[deployment.Name] = deployment
+			}
+		}
+		return stack, deployments
+	}
+
+	It("should stamp the env's propagated labels and annotations on the stack and its Deployments", func() {
+		env := &envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{
+			Name:      "dev",
+			Namespace: "dev-alice",
+			Labels: map[string]string{
+				EnvPropagatePrefix + "cost-center": "cc-4711",
+				"unrelated":                        "kept-on-env",
+			},
+			Annotations: map[string]string{EnvPropagatePrefix + "team": "payments"},
+		}}
+		stack, deployments := createStack(env, "services:\n  api:\n    image: api\n")
+
+		Expect(stack.Labels).To(HaveKeyWithValue("cost-center", "cc-4711"))
+		Expect(stack.Labels).NotTo(HaveKey("unrelated"))
+		Expect(stack.Annotations).To(HaveKeyWithValue("team", "payments"))
+
+		deployment := deployments["api"]
+		Expect(deployment).NotTo(BeNil())
+		Expect(deployment.Labels).To(HaveKeyWithValue("cost-center", "cc-4711"))
+		Expect(deployment.Annotations).To(HaveKeyWithValue("team", "payments"))
+		Expect(deployment.Spec.Template.Labels).To(HaveKeyWithValue("cost-center", "cc-4711"))
+		Expect(deployment.Spec.Template.Labels).To(HaveKeyWithValue("lissto.dev/stack", stack.Name))
+	})
+
+	It("should inject the env's default secrets and variables unless a service opts out", func() {
+		env := &envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{
+			Name:      "dev",
+			Namespace: "dev-alice",
+			Annotations: map[string]string{
+				EnvDefaultVariablesAnnotation: "observability",
+				EnvDefaultSecretsAnnotation:   "db-creds",
+			},
+		}}
+		secret := &envv1alpha1.LisstoSecret{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-creds", Namespace: "dev-alice"},
+			Spec:       envv1alpha1.LisstoSecretSpec{Env: "dev", Keys: []string{"DB_PASSWORD"}, SecretRef: "db-creds-values"},
+		}
+		variable := &envv1alpha1.LisstoVariable{
+			ObjectMeta: metav1.ObjectMeta{Name: "observability", Namespace: "dev-alice"},
+			Spec:       envv1alpha1.LisstoVariableSpec{Env: "dev", Data: map[string]string{"OTEL_ENDPOINT": "http://otel:4317", "LOG_LEVEL": "debug"}},
+		}
+		_, deployments := createStack(env, `
+services:
+  api:
+    image: api
+    environment:
+      LOG_LEVEL: info
+  worker:
+    image: worker
+    labels:
+      lissto.dev/env-defaults: "false"
+`, secret, variable)
+
+		api := deployments["api"].Spec.Template.Spec.Containers[0].Env
+		Expect(api).To(ContainElement(corev1.EnvVar{Name: "OTEL_ENDPOINT", Value: "http://otel:4317"}))
+		Expect(api).To(ContainElement(corev1.EnvVar{Name: "LOG_LEVEL", Value: "info"}))
+		Expect(api).NotTo(ContainElement(corev1.EnvVar{Name: "LOG_LEVEL", Value: "debug"}))
+		Expect(api).To(ContainElement(corev1.EnvVar{Name: "DB_PASSWORD", ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db-creds-values"}, Key: "DB_PASSWORD"},
+		}}))
+
+		for _, envVar := range deployments["worker"].Spec.Template.Spec.Containers[0].Env {
+			Expect(envVar.Name).NotTo(BeElementOf("OTEL_ENDPOINT", "DB_PASSWORD"))
+		}
+	})
+})
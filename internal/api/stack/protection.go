@@ -0,0 +1,93 @@
+package stack
+
+import (
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+// ProtectedLabel marks a stack that can only be deleted with ?confirm=<stack-id>
+// Enforced by the API only; the controller does not know about it
+const ProtectedLabel = "lissto.dev/protected"
+
+// isProtected reports whether a stack has delete protection enabled
+func isProtected(stack *envv1alpha1.Stack) bool {
+	return stack.Labels[ProtectedLabel] == "true"
+}
+
+// deleteConfirmed reports whether confirm names the stack, either as its
+// scoped ID (e.g. "alice/prod") or as the ID used in the request path
+func (h *Handler) deleteConfirmed(stack *envv1alpha1.Stack, idParam, confirm string) bool {
+	if confirm == "" {
+		return false
+	}
+	return confirm == idParam || confirm == h.nsManager.MustGenerateScopedID(stack.Namespace, stack.Name)
+}
+
+// SetStackProtection handles PUT /stacks/:id/protection
+func (h *Handler) SetStackProtection(c echo.Context) error {
+	idParam := c.Param("id")
+	user, _ := middleware.GetUserFromContext(c)
+
+	var req common.SetStackProtectionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.String(400, "Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.String(400, err.Error())
+	}
+
+	// Toggling protection is a stack update
+	allowedNS := h.authorizer.GetAllowedNamespaces(user.Role, authz.ActionUpdate, authz.ResourceStack, user.Name)
+	if len(allowedNS) == 0 {
+		return c.String(403, "Permission denied: no accessible namespaces")
+	}
+
+	targetNamespace, name, searchAll := h.nsManager.ResolveNamespaceFromID(idParam, allowedNS)
+	userNS := h.nsManager.GetDeveloperNamespace(user.Name)
+	globalNS := h.nsManager.GetGlobalNamespace()
+	stack, found := h.findStack(c, targetNamespace, name, searchAll, userNS, globalNS, allowedNS)
+	if !found {
+		return c.String(404, fmt.Sprintf("Stack '%s' not found", idParam))
+	}
+
+	if rejected, err := common.RejectIfFrozen(c, h.nsManager, h.k8sClient, stack.Namespace, user.Name, "PUT /stacks/"+idParam+"/protection"); rejected {
+		return err
+	}
+
+	identifier := h.nsManager.MustGenerateScopedID(stack.Namespace, stack.Name)
+	if *req.Protected != isProtected(stack) {
+		if *req.Protected {
+			if stack.Labels == nil {
+				stack.Labels = map[string]string{}
+			}
+			stack.Labels[ProtectedLabel] = "true"
+		} else {
+			delete(stack.Labels, ProtectedLabel)
+		}
+
+		if err := h.k8sClient.UpdateStack(c.Request().Context(), stack); err != nil {
+			logging.Logger.Error("Failed to update stack protection",
+				zap.String("stack", identifier),
+				zap.Error(err))
+			return c.String(500, "Failed to update stack")
+		}
+
+		logging.Logger.Info("Stack protection changed",
+			zap.String("user", user.Name),
+			zap.String("stack", identifier),
+			zap.Bool("protected", *req.Protected))
+	}
+
+	return c.JSON(200, map[string]interface{}{
+		"id":        identifier,
+		"protected": *req.Protected,
+	})
+}
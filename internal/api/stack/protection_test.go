@@ -0,0 +1,86 @@
+package stack
+
+import (
+	"context"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+var _ = Describe("Stack delete protection", func() {
+	var (
+		h     *Handler
+		alice *middleware.User
+	)
+
+	BeforeEach(func() {
+		alice = &middleware.User{Name: "alice", Role: authz.User}
+		protected := &envv1alpha1.Stack{ObjectMeta: metav1.ObjectMeta{
+			Name:      "prod",
+			Namespace: "dev-alice",
+			Labels:    map[string]string{ProtectedLabel: "true"},
+		}}
+		unprotected := &envv1alpha1.Stack{ObjectMeta: metav1.ObjectMeta{Name: "scratch", Namespace: "dev-alice"}}
+		h = newTestHandler(config.DefaultSettings(), nil, protected, unprotected)
+	})
+
+	deleteStack := func(id, query string) int {
+		c, rec := newTestContext(http.MethodDelete, "/stacks/"+id+query, "", alice)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		Expect(h.DeleteStack(c)).To(Succeed())
+		return rec.Code
+	}
+
+	stackExists := func(name string) bool {
+		_, err := h.k8sClient.GetStack(context.Background(), "dev-alice", name)
+		return err == nil
+	}
+
+	It("should reject deleting a protected stack without confirmation", func() {
+		Expect(deleteStack("prod", "")).To(Equal(http.StatusPreconditionFailed))
+		Expect(deleteStack("prod", "?confirm=scratch")).To(Equal(http.StatusPreconditionFailed))
+		Expect(stackExists("prod")).To(BeTrue())
+	})
+
+	It("should delete a protected stack with a matching confirmation", func() {
+		Expect(deleteStack("prod", "?confirm=alice/prod")).To(Equal(http.StatusNoContent))
+		Expect(stackExists("prod")).To(BeFalse())
+	})
+
+	It("should delete an unprotected stack normally", func() {
+		Expect(deleteStack("scratch", "")).To(Equal(http.StatusNoContent))
+		Expect(stackExists("scratch")).To(BeFalse())
+	})
+
+	It("should toggle protection", func() {
+		c, rec := newTestContext(http.MethodPut, "/stacks/scratch/protection", `{"protected":true}`, alice)
+		c.SetParamNames("id")
+		c.SetParamValues("scratch")
+		Expect(h.SetStackProtection(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+		Expect(deleteStack("scratch", "")).To(Equal(http.StatusPreconditionFailed))
+
+		c, rec = newTestContext(http.MethodPut, "/stacks/scratch/protection", `{"protected":false}`, alice)
+		c.SetParamNames("id")
+		c.SetParamValues("scratch")
+		Expect(h.SetStackProtection(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+		Expect(deleteStack("scratch", "")).To(Equal(http.StatusNoContent))
+	})
+
+	It("should require the protected field", func() {
+		c, rec := newTestContext(http.MethodPut, "/stacks/scratch/protection", `{}`, alice)
+		c.SetParamNames("id")
+		c.SetParamValues("scratch")
+		Expect(h.SetStackProtection(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(400))
+	})
+})
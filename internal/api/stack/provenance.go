@@ -0,0 +1,49 @@
+package stack
+
+import (
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+
+	"github.com/lissto-dev/api/pkg/version"
+)
+
+// Annotations recording which API instance created a stack, for correlating stacks with API rollouts
+const (
+	// APIInstanceAnnotation holds the instance ID of the API that created the stack
+	APIInstanceAnnotation = "lissto.dev/api-instance"
+	// APIVersionAnnotation holds the version of the API that created the stack
+	APIVersionAnnotation = "lissto.dev/api-version"
+	// ResolutionConfigAnnotation holds the hash of the image resolution config in effect
+	ResolutionConfigAnnotation = "lissto.dev/resolution-config-hash"
+)
+
+// StackProvenance describes the API that created a stack
+type StackProvenance struct {
+	APIInstance string `json:"api_instance,omitempty"`
+	APIVersion  string `json:"api_version,omitempty"`
+	ConfigHash  string `json:"config_hash,omitempty"`
+}
+
+// applyProvenance stamps the stack with this API instance, its version and the resolution config hash
+func (h *Handler) applyProvenance(stack *envv1alpha1.Stack) {
+	if stack.Annotations == nil {
+		stack.Annotations = make(map[string]string)
+	}
+	if h.instanceID != "" {
+		stack.Annotations[APIInstanceAnnotation] = h.instanceID
+	}
+	stack.Annotations[APIVersionAnnotation] = version.Version
+	stack.Annotations[ResolutionConfigAnnotation] = h.resolutionConfigHash
+}
+
+// stackProvenance reads the provenance annotations, nil for stacks created before they were recorded
+func stackProvenance(stack *envv1alpha1.Stack) *StackProvenance {
+	provenance := StackProvenance{
+		APIInstance: stack.Annotations[APIInstanceAnnotation],
+		APIVersion:  stack.Annotations[APIVersionAnnotation],
+		ConfigHash:  stack.Annotations[ResolutionConfigAnnotation],
+	}
+	if provenance == (StackProvenance{}) {
+		return nil
+	}
+	return &provenance
+}
@@ -0,0 +1,86 @@
+package stack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/notify"
+	"github.com/lissto-dev/api/pkg/version"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+var _ = Describe("Stack provenance", func() {
+	var (
+		h     *Handler
+		alice *middleware.User
+	)
+
+	BeforeEach(func() {
+		alice = &middleware.User{Name: "alice", Role: authz.User}
+		env := &envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}}
+		base := newTestHandler(config.DefaultSettings(), nil, env)
+		h = NewHandler(base.k8sClient, base.authorizer, base.nsManager, base.config, base.settings,
+			cache.NewMemoryCache(), nil, notify.NopNotifier{}, nil, "api-instance-1")
+
+		Expect(h.cache.Set(context.Background(), "req-1", cache.PrepareResultCache{
+			Namespace: "dev-alice",
+			Images: map[string]cache.ImageInfoCache{
+				"api": {Digest: "registry.io/api@sha256:aaa", Image: "registry.io/api:main"},
+			},
+			Compose: "services:\n  api:\n    image: api\n",
+		}, time.Minute)).To(Succeed())
+	})
+
+	It("should stamp created stacks with the instance ID, version and config hash", func() {
+		c, rec := newTestContext(http.MethodPost, "/stacks", `{"env":"dev","request_id":"req-1"}`, alice)
+		Expect(h.CreateStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(201), rec.Body.String())
+		name := rec.Body.String()[len("alice/"):]
+
+		stack, err := h.k8sClient.GetStack(context.Background(), "dev-alice", name)
+		Expect(err).NotTo(HaveOccurred())
+		configHash := prepare.ResolutionConfigHash(h.config, h.settings)
+		Expect(configHash).To(HaveLen(16))
+		Expect(stack.Annotations).To(HaveKeyWithValue(APIInstanceAnnotation, "api-instance-1"))
+		Expect(stack.Annotations).To(HaveKeyWithValue(APIVersionAnnotation, version.Version))
+		Expect(stack.Annotations).To(HaveKeyWithValue(ResolutionConfigAnnotation, configHash))
+
+		c, rec = newTestContext(http.MethodGet, "/stacks/"+name, "", alice)
+		c.SetParamNames("id")
+		c.SetParamValues(name)
+		Expect(h.GetStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+
+		var resp StackResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.CreatedBy).To(Equal(&StackProvenance{
+			APIInstance: "api-instance-1",
+			APIVersion:  version.Version,
+			ConfigHash:  configHash,
+		}))
+	})
+
+	It("should change the config hash with the resolution settings", func() {
+		settings := config.DefaultSettings()
+		settings.Images.DefaultTag = "stable"
+
+		Expect(prepare.ResolutionConfigHash(h.config, settings)).NotTo(Equal(prepare.ResolutionConfigHash(h.config, h.settings)))
+	})
+
+	It("should omit the provenance of stacks created before it was recorded", func() {
+		stack := &envv1alpha1.Stack{ObjectMeta: metav1.ObjectMeta{Name: "old", Annotations: map[string]string{}}}
+
+		Expect(extractStackResponse(stack).CreatedBy).To(BeNil())
+	})
+})
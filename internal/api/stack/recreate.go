@@ -0,0 +1,161 @@
+package stack
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+// Recreate strategies
+const (
+	RecreateStrategyDelete  = "delete"  // Delete the pods, their controller recreates them
+	RecreateStrategyRollout = "rollout" // Rolling restart through the workload's restart annotation
+)
+
+// RecreateService handles POST /stacks/:id/services/:service/recreate
+// Force-recreates the pods of one service (to clear state or pick up a config change)
+// without touching the rest of the stack
+func (h *Handler) RecreateService(c echo.Context) error {
+	idParam := c.Param("id")
+	service := c.Param("service")
+	user, _ := middleware.GetUserFromContext(c)
+	endpoint := fmt.Sprintf("POST /stacks/%s/services/%s/recreate", idParam, service)
+
+	var req common.RecreateServiceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.String(400, "Invalid request")
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.String(400, err.Error())
+	}
+	if req.Strategy == "" {
+		req.Strategy = RecreateStrategyDelete
+	}
+
+	// Locate the stack with read access; update access is checked separately below
+	allowedNS := h.authorizer.GetAllowedNamespaces(user.Role, authz.ActionRead, authz.ResourceStack, user.Name)
+	if len(allowedNS) == 0 {
+		return c.String(403, "Permission denied: no accessible namespaces")
+	}
+
+	// Resolve namespace from ID
+	targetNamespace, name, searchAll := h.nsManager.ResolveNamespaceFromID(idParam, allowedNS)
+
+	// Try to find the stack
+	userNS := h.nsManager.GetDeveloperNamespace(user.Name)
+	globalNS := h.nsManager.GetGlobalNamespace()
+	stack, found := h.findStack(c, targetNamespace, name, searchAll, userNS, globalNS, allowedNS)
+	if !found {
+		return c.String(404, fmt.Sprintf("Stack '%s' not found", idParam))
+	}
+
+	// Owners update their own stacks; admins may recreate services of any stack
+	if user.Role != authz.Admin {
+		perm := h.authorizer.CanAccess(user.Role, authz.ActionUpdate, authz.ResourceStack, stack.Namespace, user.Name)
+		if !perm.Allowed {
+			logging.LogDeniedWithIP("insufficient_permissions", user.Name, endpoint, c.RealIP())
+			return c.String(403, fmt.Sprintf("Permission denied: %s", perm.Reason))
+		}
+	}
+
+	if rejected, err := common.RejectIfFrozen(c, h.nsManager, h.k8sClient, stack.Namespace, user.Name, endpoint); rejected {
+		return err
+	}
+
+	response := common.RecreateServiceResponse{
+		Stack:    h.nsManager.MustGenerateScopedID(stack.Namespace, stack.Name),
+		Service:  service,
+		Strategy: req.Strategy,
+	}
+
+	var err error
+	if req.Strategy == RecreateStrategyRollout {
+		err = h.restartServiceWorkloads(c, stack, service, &response)
+	} else {
+		err = h.deleteServicePods(c, stack, service, &response)
+	}
+	if err != nil {
+		logging.Logger.Error("Failed to recreate service",
+			zap.String("stack", stack.Name),
+			zap.String("namespace", stack.Namespace),
+			zap.String("service", service),
+			zap.String("strategy", req.Strategy),
+			zap.Error(err))
+		return c.String(500, "Failed to recreate service")
+	}
+
+	if response.Pods == 0 && response.Workloads == 0 && response.Skipped == 0 {
+		return c.String(404, fmt.Sprintf("No pods found for service '%s' in stack '%s'", service, idParam))
+	}
+
+	logging.Logger.Info("Recreated stack service",
+		zap.String("user", user.Name),
+		zap.String("stack", stack.Name),
+		zap.String("namespace", stack.Namespace),
+		zap.String("service", service),
+		zap.String("strategy", req.Strategy),
+		zap.Int("pods", response.Pods),
+		zap.Int("workloads", response.Workloads),
+		zap.Int("skipped", response.Skipped))
+
+	return c.JSON(200, response)
+}
+
+// servicePodLabels selects the pods of one service of a stack
+func servicePodLabels(stack *envv1alpha1.Stack, service string) map[string]string {
+	return map[string]string{
+		"lissto.dev/stack":   stack.Name,
+		"io.kompose.service": service,
+	}
+}
+
+// deleteServicePods deletes the service's pods that have a controller to recreate them
+func (h *Handler) deleteServicePods(c echo.Context, stack *envv1alpha1.Stack, service string, response *common.RecreateServiceResponse) error {
+	ctx := c.Request().Context()
+	pods, err := h.k8sClient.ListPodsWithLabels(ctx, stack.Namespace, servicePodLabels(stack, service))
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	for _, pod := range pods.Items {
+		// A standalone pod would be gone for good
+		if len(pod.OwnerReferences) == 0 {
+			logging.Logger.Warn("Not deleting standalone pod, nothing would recreate it",
+				zap.String("pod", pod.Name),
+				zap.String("service", service))
+			response.Skipped++
+			continue
+		}
+		if err := h.k8sClient.DeletePod(ctx, pod.Namespace, pod.Name); err != nil {
+			return fmt.Errorf("failed to delete pod %s: %w", pod.Name, err)
+		}
+		response.Pods++
+	}
+	return nil
+}
+
+// restartServiceWorkloads triggers a rolling restart of the service's Deployments and StatefulSets
+func (h *Handler) restartServiceWorkloads(c echo.Context, stack *envv1alpha1.Stack, service string, response *common.RecreateServiceResponse) error {
+	ctx := c.Request().Context()
+	workloads, err := h.k8sClient.ListWorkloadsWithPodLabels(ctx, stack.Namespace, servicePodLabels(stack, service))
+	if err != nil {
+		return fmt.Errorf("failed to list workloads: %w", err)
+	}
+
+	now := time.Now()
+	for _, workload := range workloads {
+		if err := h.k8sClient.RestartWorkload(ctx, workload, now); err != nil {
+			return fmt.Errorf("failed to restart %s: %w", workload.GetName(), err)
+		}
+		response.Workloads++
+	}
+	return nil
+}
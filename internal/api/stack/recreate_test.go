@@ -0,0 +1,178 @@
+package stack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/k8s"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+func recreateObjects() []client.Object {
+	stack := &envv1alpha1.Stack{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "my-stack",
+			Namespace:   "dev-alice",
+			Annotations: map[string]string{"lissto.dev/created-by": "alice"},
+		},
+	}
+	owned := []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs", UID: "uid"}}
+	pod := func(name, stackName, service string, owners []metav1.OwnerReference) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "dev-alice",
+			OwnerReferences: owners,
+			Labels:          map[string]string{"lissto.dev/stack": stackName, "io.kompose.service": service},
+		}}
+	}
+	deployment := func(name, stackName, service string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "dev-alice"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"lissto.dev/stack": stackName, "io.kompose.service": service},
+				},
+			}},
+		}
+	}
+
+	return []client.Object{
+		stack,
+		pod("api-1", "my-stack", "api", owned),
+		pod("api-2", "my-stack", "api", owned),
+		pod("worker-1", "my-stack", "worker", owned),
+		pod("api-other", "other-stack", "api", owned),
+		deployment("api", "my-stack", "api"),
+		deployment("worker", "my-stack", "worker"),
+		deployment("other-api", "other-stack", "api"),
+	}
+}
+
+var _ = Describe("RecreateService", func() {
+	alice := &middleware.User{Name: "alice", Role: authz.User}
+
+	recreate := func(h *Handler, stackID, service, body string, user *middleware.User) *httptest.ResponseRecorder {
+		c, rec := newTestContext(http.MethodPost, "/stacks/"+stackID+"/services/"+service+"/recreate", body, user)
+		c.SetParamNames("id", "service")
+		c.SetParamValues(stackID, service)
+		Expect(h.RecreateService(c)).To(Succeed())
+		return rec
+	}
+
+	podNames := func(h *Handler) []string {
+		pods := &corev1.PodList{}
+		Expect(h.k8sClient.List(context.Background(), pods, client.InNamespace("dev-alice"))).To(Succeed())
+		var names []string
+		for _, pod := range pods.Items {
+			names = append(names, pod.Name)
+		}
+		return names
+	}
+
+	restartedAt := func(h *Handler, name string) string {
+		deployment := &appsv1.Deployment{}
+		Expect(h.k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "dev-alice", Name: name}, deployment)).To(Succeed())
+		return deployment.Spec.Template.Annotations[k8s.RestartedAtAnnotation]
+	}
+
+	It("should delete only the targeted service's pods", func() {
+		h := newTestHandler(config.DefaultSettings(), nil, recreateObjects()...)
+		rec := recreate(h, "my-stack", "api", "", alice)
+
+		Expect(rec.Code).To(Equal(200))
+		var response common.RecreateServiceResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+		Expect(response).To(Equal(common.RecreateServiceResponse{
+			Stack:    "alice/my-stack",
+			Service:  "api",
+			Strategy: RecreateStrategyDelete,
+			Pods:     2,
+		}))
+		Expect(podNames(h)).To(ConsistOf("worker-1", "api-other"))
+	})
+
+	It("should leave standalone pods alone", func() {
+		objects := append(recreateObjects(), &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      "migrate",
+			Namespace: "dev-alice",
+			Labels:    map[string]string{"lissto.dev/stack": "my-stack", "io.kompose.service": "migrate"},
+		}})
+		h := newTestHandler(config.DefaultSettings(), nil, objects...)
+		rec := recreate(h, "my-stack", "migrate", "", alice)
+
+		Expect(rec.Code).To(Equal(200))
+		var response common.RecreateServiceResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+		Expect(response.Pods).To(Equal(0))
+		Expect(response.Skipped).To(Equal(1))
+		Expect(podNames(h)).To(ContainElement("migrate"))
+	})
+
+	It("should restart only the targeted service's workloads with the rollout strategy", func() {
+		h := newTestHandler(config.DefaultSettings(), nil, recreateObjects()...)
+		rec := recreate(h, "my-stack", "api", `{"strategy":"rollout"}`, alice)
+
+		Expect(rec.Code).To(Equal(200))
+		var response common.RecreateServiceResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+		Expect(response.Workloads).To(Equal(1))
+		Expect(response.Pods).To(Equal(0))
+
+		Expect(restartedAt(h, "api")).NotTo(BeEmpty())
+		Expect(restartedAt(h, "worker")).To(BeEmpty())
+		Expect(restartedAt(h, "other-api")).To(BeEmpty())
+		Expect(podNames(h)).To(HaveLen(4))
+	})
+
+	It("should return 404 for a service without pods", func() {
+		h := newTestHandler(config.DefaultSettings(), nil, recreateObjects()...)
+		rec := recreate(h, "my-stack", "missing", "", alice)
+
+		Expect(rec.Code).To(Equal(404))
+	})
+
+	It("should reject an unknown strategy", func() {
+		h := newTestHandler(config.DefaultSettings(), nil, recreateObjects()...)
+		rec := recreate(h, "my-stack", "api", `{"strategy":"nuke"}`, alice)
+
+		Expect(rec.Code).To(Equal(400))
+		Expect(podNames(h)).To(HaveLen(4))
+	})
+
+	It("should not let other users recreate the stack's services", func() {
+		h := newTestHandler(config.DefaultSettings(), nil, recreateObjects()...)
+		rec := recreate(h, "alice/my-stack", "api", "", &middleware.User{Name: "bob", Role: authz.User})
+
+		Expect(rec.Code).To(Equal(404))
+		Expect(podNames(h)).To(HaveLen(4))
+	})
+
+	It("should not let deploy users recreate services", func() {
+		h := newTestHandler(config.DefaultSettings(), nil, recreateObjects()...)
+		rec := recreate(h, "alice/my-stack", "api", "", &middleware.User{Name: "ci", Role: authz.Deploy})
+
+		Expect(rec.Code).To(Equal(403))
+		Expect(podNames(h)).To(HaveLen(4))
+	})
+
+	It("should let admins recreate any stack's services", func() {
+		h := newTestHandler(config.DefaultSettings(), nil, recreateObjects()...)
+		rec := recreate(h, "alice/my-stack", "api", "", &middleware.User{Name: "root", Role: authz.Admin})
+
+		Expect(rec.Code).To(Equal(200))
+		Expect(podNames(h)).To(ConsistOf("worker-1", "api-other"))
+	})
+})
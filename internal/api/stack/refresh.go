@@ -0,0 +1,252 @@
+package stack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/image"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+// RefreshEnvImages handles POST /envs/:id/refresh-images
+// Re-resolves the image tag of every service of every stack in the env and updates
+// the stacks whose digests changed (e.g. after a base image was re-published)
+func (h *Handler) RefreshEnvImages(c echo.Context) error {
+	idParam := c.Param("id")
+	user, _ := middleware.GetUserFromContext(c)
+
+	var req common.RefreshEnvImagesRequest
+	if err := c.Bind(&req); err != nil {
+		return c.String(400, "Invalid request")
+	}
+
+	if h.imageResolver == nil {
+		return c.String(503, "Image resolution is not available")
+	}
+
+	// Env IDs are scoped ("alice/dev"); a bare name refers to the user's own env
+	namespace, envName, err := h.nsManager.ParseScopedID(idParam)
+	if err != nil {
+		return c.String(400, fmt.Sprintf("Invalid env reference: %v", err))
+	}
+	if namespace == "" {
+		namespace = h.nsManager.GetDeveloperNamespace(user.Name)
+	}
+
+	// Owners update their own stacks; admins may refresh any env
+	if user.Role != authz.Admin {
+		perm := h.authorizer.CanAccess(user.Role, authz.ActionUpdate, authz.ResourceStack, namespace, user.Name)
+		if !perm.Allowed {
+			logging.LogDeniedWithIP("insufficient_permissions", user.Name, fmt.Sprintf("POST /envs/%s/refresh-images", idParam), c.RealIP())
+			return c.String(403, fmt.Sprintf("Permission denied: %s", perm.Reason))
+		}
+	}
+
+	ctx := c.Request().Context()
+	if _, err := h.k8sClient.GetEnv(ctx, namespace, envName); err != nil {
+		return c.String(404, fmt.Sprintf("Env '%s' not found", idParam))
+	}
+
+	// A dry run changes nothing, so it is allowed in frozen namespaces
+	if !req.DryRun {
+		if rejected, err := common.RejectIfFrozen(c, h.nsManager, h.k8sClient, namespace, user.Name, fmt.Sprintf("POST /envs/%s/refresh-images", idParam)); rejected {
+			return err
+		}
+	}
+
+	stackList, err := h.k8sClient.ListStacks(ctx, namespace)
+	if err != nil {
+		logging.Logger.Error("Failed to list stacks",
+			zap.String("namespace", namespace),
+			zap.Error(err))
+		return c.String(500, "Failed to list stacks")
+	}
+
+	response := common.RefreshEnvImagesResponse{
+		Env:    h.nsManager.MustGenerateScopedID(namespace, envName),
+		DryRun: req.DryRun,
+		Stacks: []common.StackRefreshReport{},
+	}
+	for i := range stackList.Items {
+		stack := &stackList.Items[i]
+		if stack.Spec.Env != envName {
+			continue
+		}
+		response.Stacks = append(response.Stacks, h.refreshStackImages(ctx, stack, req.DryRun, user.Name))
+	}
+	sort.Slice(response.Stacks, func(i, j int) bool { return response.Stacks[i].Stack < response.Stacks[j].Stack })
+
+	logging.Logger.Info("Env images refreshed",
+		zap.String("user", user.Name),
+		zap.String("env", response.Env),
+		zap.Bool("dry_run", req.DryRun),
+		zap.Int("stacks", len(response.Stacks)))
+
+	return c.JSON(200, response)
+}
+
+// refreshStackImages re-resolves the stack's image tags and applies changed digests unless dryRun
+func (h *Handler) refreshStackImages(ctx context.Context, stack *envv1alpha1.Stack, dryRun bool, userName string) common.StackRefreshReport {
+	report := common.StackRefreshReport{
+		Stack: h.nsManager.MustGenerateScopedID(stack.Namespace, stack.Name),
+	}
+
+	services := make([]string, 0, len(stack.Spec.Images))
+	for service := range stack.Spec.Images {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	// Every service is passed on, since an update replaces the whole image map
+	images := make(map[string]interface{}, len(services))
+	var policy *stackImagePolicy
+	var policyErr error
+	policyLoaded := false
+	for _, service := range services {
+		info := stack.Spec.Images[service]
+		images[service] = info.Digest
+
+		// Services without a tag (e.g. pinned by digest only) have nothing to re-resolve
+		if info.Image == "" {
+			continue
+		}
+
+		digest, err := h.imageResolver.GetImageDigestWithServicePlatform(info.Image, types.ServiceConfig{Name: service})
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", service, err))
+			continue
+		}
+		if digest == info.Digest {
+			continue
+		}
+		// A re-pushed tag must not bypass the blueprint's approved digests or required signatures
+		if !policyLoaded {
+			policy, policyErr = h.loadImagePolicy(ctx, stack)
+			policyLoaded = true
+		}
+		if policyErr != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: cannot check the blueprint's image policy: %v", service, policyErr))
+			continue
+		}
+		if err := h.checkImage(ctx, policy, service, digest); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", service, err))
+			continue
+		}
+
+		report.Changes = append(report.Changes, common.ImageDigestChange{
+			Service:   service,
+			Image:     info.Image,
+			OldDigest: info.Digest,
+			NewDigest: digest,
+		})
+		images[service] = digest
+	}
+
+	if dryRun || len(report.Changes) == 0 {
+		return report
+	}
+
+	// Apply under the namespace lock, to the stack as it is then, unless one of its changed services moved meanwhile
+	unlock, err := h.locker.Lock(ctx, stack.Namespace)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("update failed: %v", err))
+		return report
+	}
+	defer unlock()
+	stack, err = h.k8sClient.GetStack(ctx, stack.Namespace, stack.Name)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("update failed: %v", err))
+		return report
+	}
+	images = make(map[string]interface{}, len(stack.Spec.Images))
+	for service, info := range stack.Spec.Images {
+		images[service] = info.Digest
+	}
+	for _, change := range report.Changes {
+		if stack.Spec.Images[change.Service].Digest != change.OldDigest {
+			report.Errors = append(report.Errors, fmt.Sprintf("update failed: service %s was changed during the refresh", change.Service))
+			return report
+		}
+		images[change.Service] = change.NewDigest
+	}
+
+	if err := h.applyStackImages(ctx, stack, images, userName); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("update failed: %v", err))
+		return report
+	}
+	report.Updated = true
+	return report
+}
+
+// stackImagePolicy is what a stack's blueprints and the settings require of the images it is updated to
+type stackImagePolicy struct {
+	allowedDigests map[string][]string // x-lissto.allowedDigests, nil when unset
+	signed         map[string]bool     // Services labeled lissto.dev/require-signature
+	requireAll     bool                // signatures.required: every image must be verified
+}
+
+// requiresSignature reports whether the image of a service must carry a verified signature
+func (p *stackImagePolicy) requiresSignature(service string) bool {
+	return p.requireAll || p.signed[service]
+}
+
+// loadImagePolicy loads the image policy of the stack's blueprints; inline stacks only get the settings'
+// An unreadable blueprint is an error: checking images without its policy would let any image through
+func (h *Handler) loadImagePolicy(ctx context.Context, stack *envv1alpha1.Stack) (*stackImagePolicy, error) {
+	policy := &stackImagePolicy{requireAll: h.settings.Signatures.Required}
+	composeContent, err := h.stackCompose(ctx, stack)
+	if errors.Is(err, errInlineStack) {
+		return policy, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	project, err := h.parseDockerCompose(composeContent)
+	if err != nil {
+		return nil, err
+	}
+	policy.allowedDigests = compose.ExtractLisstoConfig(project).AllowedDigests
+	policy.signed = make(map[string]bool)
+	for name, service := range project.Services {
+		if image.RequiresSignature(service) {
+			policy.signed[name] = true
+		}
+	}
+	return policy, nil
+}
+
+// checkImage returns an error unless a service's new image is approved by the policy, verifying its
+// signature when one is required
+func (h *Handler) checkImage(ctx context.Context, policy *stackImagePolicy, service, digest string) error {
+	if err := image.CheckAllowedDigest(digest, policy.allowedDigests); err != nil {
+		return err
+	}
+	if !policy.requiresSignature(service) {
+		return nil
+	}
+	if h.signatureVerifier == nil {
+		return fmt.Errorf("image %s needs a verified signature, but signature verification is not configured", digest)
+	}
+	result, err := h.signatureVerifier.VerifySignature(ctx, digest)
+	if err != nil {
+		return fmt.Errorf("failed to verify the signature of image %s: %w", digest, err)
+	}
+	if !result.Verified {
+		if result.Error != "" {
+			return fmt.Errorf("image %s has no verified signature: %s", digest, result.Error)
+		}
+		return fmt.Errorf("image %s has no verified signature", digest)
+	}
+	return nil
+}
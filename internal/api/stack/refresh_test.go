@@ -0,0 +1,147 @@
+package stack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/image"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+// digestResolver resolves image tags to the digests they currently point at
+type digestResolver struct {
+	digests map[string]string
+}
+
+func (r *digestResolver) GetImageDigestWithServicePlatform(imageURL string, _ types.ServiceConfig) (string, error) {
+	if digest, ok := r.digests[imageURL]; ok {
+		return digest, nil
+	}
+	return "", fmt.Errorf("image %s not found", imageURL)
+}
+
+func (r *digestResolver) ResolveImageDetailed(types.ServiceConfig, image.ResolutionConfig) (*image.DetailedImageResolutionResult, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+var _ = Describe("Refresh env images", func() {
+	var (
+		h     *Handler
+		alice *middleware.User
+	)
+
+	newStack := func(name, env string, images map[string]envv1alpha1.ImageInfo) *envv1alpha1.Stack {
+		return &envv1alpha1.Stack{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "dev-alice"},
+			Spec:       envv1alpha1.StackSpec{Env: env, Images: images},
+		}
+	}
+
+	BeforeEach(func() {
+		alice = &middleware.User{Name: "alice", Role: authz.User}
+		objects := []client.Object{
+			&envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}},
+			newStack("patched", "dev", map[string]envv1alpha1.ImageInfo{
+				"db":  {Image: "postgres:15", Digest: "postgres@sha256:old", URL: "db.internal"},
+				"api": {Image: "registry.io/api:main", Digest: "registry.io/api@sha256:aaa"},
+			}),
+			newStack("current", "dev", map[string]envv1alpha1.ImageInfo{
+				"api": {Image: "registry.io/api:main", Digest: "registry.io/api@sha256:aaa"},
+			}),
+			newStack("elsewhere", "staging", map[string]envv1alpha1.ImageInfo{
+				"db": {Image: "postgres:15", Digest: "postgres@sha256:old"},
+			}),
+		}
+		h = newTestHandler(config.DefaultSettings(), nil, objects...)
+		h.imageResolver = &digestResolver{digests: map[string]string{
+			"postgres:15":          "postgres@sha256:new",
+			"registry.io/api:main": "registry.io/api@sha256:aaa",
+		}}
+	})
+
+	refresh := func(id, body string, user *middleware.User) (*common.RefreshEnvImagesResponse, int) {
+		c, rec := newTestContext(http.MethodPost, "/envs/"+id+"/refresh-images", body, user)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		Expect(h.RefreshEnvImages(c)).To(Succeed())
+		if rec.Code != 200 {
+			return nil, rec.Code
+		}
+		var response common.RefreshEnvImagesResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+		return &response, rec.Code
+	}
+
+	getStack := func(name string) *envv1alpha1.Stack {
+		stack, err := h.k8sClient.GetStack(context.Background(), "dev-alice", name)
+		Expect(err).NotTo(HaveOccurred())
+		return stack
+	}
+
+	It("should update only the stacks whose digests changed", func() {
+		response, code := refresh("dev", "", alice)
+		Expect(code).To(Equal(200))
+
+		Expect(response.Env).To(Equal("alice/dev"))
+		Expect(response.Stacks).To(HaveLen(2))
+		Expect(response.Stacks[0].Stack).To(Equal("alice/current"))
+		Expect(response.Stacks[0].Updated).To(BeFalse())
+		Expect(response.Stacks[0].Changes).To(BeEmpty())
+		Expect(response.Stacks[1].Stack).To(Equal("alice/patched"))
+		Expect(response.Stacks[1].Updated).To(BeTrue())
+		Expect(response.Stacks[1].Changes).To(Equal([]common.ImageDigestChange{{
+			Service: "db", Image: "postgres:15", OldDigest: "postgres@sha256:old", NewDigest: "postgres@sha256:new",
+		}}))
+
+		patched := getStack("patched")
+		Expect(patched.Spec.Images["db"]).To(Equal(envv1alpha1.ImageInfo{Image: "postgres:15", Digest: "postgres@sha256:new", URL: "db.internal"}))
+		Expect(patched.Spec.Images["api"].Digest).To(Equal("registry.io/api@sha256:aaa"))
+		Expect(getStack("elsewhere").Spec.Images["db"].Digest).To(Equal("postgres@sha256:old"))
+	})
+
+	It("should only report changes in a dry run", func() {
+		response, code := refresh("alice/dev", `{"dry_run":true}`, alice)
+		Expect(code).To(Equal(200))
+
+		Expect(response.DryRun).To(BeTrue())
+		Expect(response.Stacks[1].Changes).To(HaveLen(1))
+		Expect(response.Stacks[1].Updated).To(BeFalse())
+		Expect(getStack("patched").Spec.Images["db"].Digest).To(Equal("postgres@sha256:old"))
+	})
+
+	It("should not let other users refresh the env", func() {
+		_, code := refresh("alice/dev", "", &middleware.User{Name: "bob", Role: authz.User})
+		Expect(code).To(Equal(403))
+	})
+
+	It("should return 404 for an unknown env", func() {
+		_, code := refresh("missing", "", alice)
+		Expect(code).To(Equal(404))
+	})
+
+	It("should not apply a changed digest when the stack's blueprint cannot be loaded", func() {
+		patched := getStack("patched")
+		patched.Spec.BlueprintReference = "alice/deleted"
+		Expect(h.k8sClient.UpdateStack(context.Background(), patched)).To(Succeed())
+
+		response, code := refresh("dev", "", alice)
+		Expect(code).To(Equal(200))
+
+		Expect(response.Stacks[1].Stack).To(Equal("alice/patched"))
+		Expect(response.Stacks[1].Updated).To(BeFalse())
+		Expect(response.Stacks[1].Errors).To(ConsistOf(ContainSubstring("cannot check the blueprint's image policy")))
+		Expect(getStack("patched").Spec.Images["db"].Digest).To(Equal("postgres@sha256:old"))
+	})
+})
@@ -0,0 +1,292 @@
+package stack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/prepare"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+	"github.com/lissto-dev/controller/pkg/namespace"
+)
+
+// Repair actions reported by RepairStack
+const (
+	RepairRecreatedConfigMap       = "recreated_configmap"        // Manifests ConfigMap was missing and was rendered again
+	RepairSetOwnerReference        = "set_owner_reference"        // ConfigMap was not owned by the stack
+	RepairSetManifestsRef          = "set_manifests_ref"          // Stack did not point at its ConfigMap
+	RepairDeletedOrphanedConfigMap = "deleted_orphaned_configmap" // ConfigMap left behind by a failed create
+)
+
+// errInlineStack is returned when manifests must be rendered for a stack created from inline compose
+var errInlineStack = errors.New("stack was created from inline compose, its manifests cannot be rendered again")
+
+// RepairStack handles POST /stacks/:id/repair
+// Reconciles what a CreateStack that failed midway can leave behind: a stack without its manifests
+// ConfigMap (rendered again from the blueprint and the stack's images), a ConfigMap without owner
+// reference, or a ConfigMap whose stack was never created (deleted)
+func (h *Handler) RepairStack(c echo.Context) error {
+	idParam := c.Param("id")
+	user, _ := middleware.GetUserFromContext(c)
+	endpoint := fmt.Sprintf("POST /stacks/%s/repair", idParam)
+
+	// Locate the stack with read access; update access is checked separately below
+	allowedNS := h.authorizer.GetAllowedNamespaces(user.Role, authz.ActionRead, authz.ResourceStack, user.Name)
+	if len(allowedNS) == 0 {
+		return c.String(403, "Permission denied: no accessible namespaces")
+	}
+
+	// Resolve namespace from ID
+	targetNamespace, name, searchAll := h.nsManager.ResolveNamespaceFromID(idParam, allowedNS)
+
+	userNS := h.nsManager.GetDeveloperNamespace(user.Name)
+	globalNS := h.nsManager.GetGlobalNamespace()
+	stack, found := h.findStack(c, targetNamespace, name, searchAll, userNS, globalNS, allowedNS)
+	if !found {
+		namespaces := namespace.ResolveNamespacesToSearch(targetNamespace, userNS, globalNS, searchAll, allowedNS)
+		return h.deleteOrphanedConfigMap(c, user, namespaces, name, idParam, endpoint)
+	}
+
+	// Owners repair their own stacks; admins may repair any stack
+	if user.Role != authz.Admin {
+		perm := h.authorizer.CanAccess(user.Role, authz.ActionUpdate, authz.ResourceStack, stack.Namespace, user.Name)
+		if !perm.Allowed {
+			logging.LogDeniedWithIP("insufficient_permissions", user.Name, endpoint, c.RealIP())
+			return c.String(403, fmt.Sprintf("Permission denied: %s", perm.Reason))
+		}
+	}
+
+	if rejected, err := common.RejectIfFrozen(c, h.nsManager, h.k8sClient, stack.Namespace, user.Name, endpoint); rejected {
+		return err
+	}
+
+	repaired, err := h.repairManifestsConfigMap(c.Request().Context(), stack)
+	if err != nil {
+		logging.Logger.Error("Failed to repair stack",
+			zap.String("stack", stack.Name),
+			zap.String("namespace", stack.Namespace),
+			zap.Strings("repaired", repaired),
+			zap.Error(err))
+		if errors.Is(err, errInlineStack) {
+			return c.String(409, fmt.Sprintf("Cannot repair stack '%s': %v", idParam, err))
+		}
+		return c.String(500, "Failed to repair stack")
+	}
+
+	logging.Logger.Info("Repaired stack",
+		zap.String("user", user.Name),
+		zap.String("stack", stack.Name),
+		zap.String("namespace", stack.Namespace),
+		zap.Strings("repaired", repaired))
+
+	return c.JSON(200, common.RepairStackResponse{
+		Stack:    h.nsManager.MustGenerateScopedID(stack.Namespace, stack.Name),
+		Repaired: repaired,
+	})
+}
+
+// repairManifestsConfigMap makes sure the stack references an existing ConfigMap it owns
+// It returns the actions taken, including those done before an error
+func (h *Handler) repairManifestsConfigMap(ctx context.Context, stack *envv1alpha1.Stack) ([]string, error) {
+	repaired := []string{}
+
+	if stack.Spec.ManifestsConfigMapRef == "" {
+		stack.Spec.ManifestsConfigMapRef = manifestsConfigMapName(stack.Name)
+		if err := h.k8sClient.UpdateStack(ctx, stack); err != nil {
+			return repaired, fmt.Errorf("failed to set manifests reference: %w", err)
+		}
+		repaired = append(repaired, RepairSetManifestsRef)
+	}
+
+	configMap, err := h.k8sClient.GetConfigMap(ctx, stack.Namespace, stack.Spec.ManifestsConfigMapRef)
+	if apierrors.IsNotFound(err) {
+		manifests, err := h.renderStackManifests(ctx, stack)
+		if err != nil {
+			return repaired, err
+		}
+		configMap = newManifestsConfigMap(stack.Namespace, stack.Name, manifests)
+		configMap.Name = stack.Spec.ManifestsConfigMapRef
+		if err := controllerutil.SetOwnerReference(stack, configMap, h.k8sClient.Scheme()); err != nil {
+			return repaired, fmt.Errorf("failed to set owner reference: %w", err)
+		}
+		if err := h.k8sClient.CreateConfigMap(ctx, configMap); err != nil {
+			return repaired, fmt.Errorf("failed to create manifests ConfigMap: %w", err)
+		}
+		return append(repaired, RepairRecreatedConfigMap), nil
+	}
+	if err != nil {
+		return repaired, fmt.Errorf("failed to get manifests ConfigMap: %w", err)
+	}
+
+	if !ownedBy(configMap.OwnerReferences, stack) {
+		if err := controllerutil.SetOwnerReference(stack, configMap, h.k8sClient.Scheme()); err != nil {
+			return repaired, fmt.Errorf("failed to set owner reference: %w", err)
+		}
+		if err := h.k8sClient.UpdateConfigMap(ctx, configMap); err != nil {
+			return repaired, fmt.Errorf("failed to update manifests ConfigMap: %w", err)
+		}
+		repaired = append(repaired, RepairSetOwnerReference)
+	}
+	return repaired, nil
+}
+
+// ownedBy reports whether the owner references include the stack
+func ownedBy(owners []metav1.OwnerReference, stack *envv1alpha1.Stack) bool {
+	for _, owner := range owners {
+		if owner.UID == stack.UID && owner.Kind == "Stack" {
+			return true
+		}
+	}
+	return false
+}
+
+// renderedStack is a stack rendered again from its blueprint and images
+type renderedStack struct {
+	Project     *types.Project    // Blueprint compose with the stack's images, before expose processing
+	Manifests   string            // Kubernetes manifests as stored in the manifests ConfigMap
+	ObjectCount int               // Number of objects in Manifests
+	URLs        map[string]string // Exposed URL per service for the stack's env
+}
+
+// renderStackManifests renders the manifests of a stack again from its blueprint and images
+func (h *Handler) renderStackManifests(ctx context.Context, stack *envv1alpha1.Stack) (string, error) {
+	rendered, err := h.renderStack(ctx, stack)
+	if err != nil {
+		return "", err
+	}
+	return rendered.Manifests, nil
+}
+
+// renderStack renders a stack from its blueprint, images, env and name like CreateStack did
+func (h *Handler) renderStack(ctx context.Context, stack *envv1alpha1.Stack) (*renderedStack, error) {
+	project, err := h.loadStackProject(ctx, stack)
+	if err != nil {
+		return nil, err
+	}
+	return h.renderStackProject(ctx, stack, project)
+}
+
+// loadStackProject loads the compose of the stack's blueprints with the images recorded on the stack
+func (h *Handler) loadStackProject(ctx context.Context, stack *envv1alpha1.Stack) (*types.Project, error) {
+	composeContent, err := h.stackCompose(ctx, stack)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := h.parseDockerCompose(composeContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse blueprint compose: %w", err)
+	}
+
+	// Deploy the images recorded on the stack, as CreateStack did
+	for serviceName, service := range project.Services {
+		info, ok := stack.Spec.Images[serviceName]
+		if !ok {
+			return nil, fmt.Errorf("stack has no image for service %s", serviceName)
+		}
+		service.Image = info.Digest
+		if service.Image == "" {
+			service.Image = info.Image
+		}
+		project.Services[serviceName] = service
+	}
+	return project, nil
+}
+
+// renderStackProject renders the manifests of a project loaded with loadStackProject
+func (h *Handler) renderStackProject(ctx context.Context, stack *envv1alpha1.Stack, project *types.Project) (*renderedStack, error) {
+	exposePreprocessor, err := h.exposePreprocessor.WithDefaultVisibility(compose.ExtractLisstoConfig(project).ExposeDefault)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process service exposure: %w", err)
+	}
+	exposePreprocessor = exposePreprocessor.WithHostSuffix(stack.Annotations[HostSuffixAnnotation])
+	urls := make(map[string]string)
+	for serviceName, service := range project.Services {
+		if url := exposePreprocessor.GetExposedServiceURL(service, serviceName, stack.Spec.Env); url != "" {
+			urls[serviceName] = url
+		}
+	}
+
+	// ProcessServices returns new services, the project keeps the blueprint's for the caller
+	original := project.Services
+	processedServices, err := exposePreprocessor.ProcessServices(project.Services, stack.Spec.Env, stack.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process service exposure: %w", err)
+	}
+	project.Services = processedServices
+
+	// The env may be gone (e.g. when repairing); its stacks then render without what it propagates
+	var propagated propagatedMetadata
+	if env, err := h.k8sClient.GetEnv(ctx, stack.Namespace, stack.Spec.Env); err == nil {
+		propagated = envPropagation(env)
+		if propagated.Env, err = h.envDefaults(ctx, env); err != nil {
+			return nil, fmt.Errorf("invalid env defaults: %w", err)
+		}
+	}
+
+	prepare.ApplyResourceFloor(project, stack.Spec.Env, h.settings.Resources)
+	manifests, objectCount, err := h.generateKubernetesManifests(project, stack.Namespace, stack.Name, propagated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate manifests: %w", err)
+	}
+	project.Services = original
+	return &renderedStack{Project: project, Manifests: manifests, ObjectCount: objectCount, URLs: urls}, nil
+}
+
+// deleteOrphanedConfigMap deletes the manifests ConfigMap of a stack that doesn't exist
+func (h *Handler) deleteOrphanedConfigMap(c echo.Context, user *middleware.User, namespaces []string, name, idParam, endpoint string) error {
+	ctx := c.Request().Context()
+	configMapName := manifestsConfigMapName(name)
+
+	for _, ns := range namespaces {
+		configMap, err := h.k8sClient.GetConfigMap(ctx, ns, configMapName)
+		if err != nil {
+			continue
+		}
+		// Only touch ConfigMaps CreateStack made for this stack
+		if configMap.Labels["app.kubernetes.io/managed-by"] != "lissto" || configMap.Labels["lissto.dev/stack"] != name {
+			continue
+		}
+
+		perm := h.authorizer.CanAccess(user.Role, authz.ActionDelete, authz.ResourceStack, ns, user.Name)
+		if !perm.Allowed {
+			logging.LogDeniedWithIP("insufficient_permissions", user.Name, endpoint, c.RealIP())
+			return c.String(403, fmt.Sprintf("Permission denied: %s", perm.Reason))
+		}
+		if rejected, err := common.RejectIfFrozen(c, h.nsManager, h.k8sClient, ns, user.Name, endpoint); rejected {
+			return err
+		}
+
+		if err := h.k8sClient.DeleteConfigMap(ctx, ns, configMapName); err != nil {
+			logging.Logger.Error("Failed to delete orphaned manifests ConfigMap",
+				zap.String("configmap_name", configMapName),
+				zap.String("namespace", ns),
+				zap.Error(err))
+			return c.String(500, "Failed to delete orphaned ConfigMap")
+		}
+
+		logging.Logger.Info("Deleted orphaned manifests ConfigMap",
+			zap.String("user", user.Name),
+			zap.String("configmap_name", configMapName),
+			zap.String("namespace", ns))
+
+		return c.JSON(200, common.RepairStackResponse{
+			Stack:    h.nsManager.MustGenerateScopedID(ns, name),
+			Repaired: []string{RepairDeletedOrphanedConfigMap},
+		})
+	}
+
+	return c.String(404, fmt.Sprintf("Stack '%s' not found", idParam))
+}
@@ -0,0 +1,142 @@
+package stack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+var _ = Describe("RepairStack", func() {
+	alice := &middleware.User{Name: "alice", Role: authz.User}
+
+	newStack := func(blueprint, configMapRef string) *envv1alpha1.Stack {
+		return &envv1alpha1.Stack{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "my-stack",
+				Namespace:   "dev-alice",
+				UID:         "stack-uid",
+				Annotations: map[string]string{"lissto.dev/created-by": "alice"},
+			},
+			Spec: envv1alpha1.StackSpec{
+				BlueprintReference:    blueprint,
+				Env:                   "dev",
+				ManifestsConfigMapRef: configMapRef,
+				Images:                map[string]envv1alpha1.ImageInfo{"web": {Digest: "nginx@sha256:abc"}},
+			},
+		}
+	}
+	blueprint := &envv1alpha1.Blueprint{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-bp", Namespace: "dev-alice"},
+		Spec:       envv1alpha1.BlueprintSpec{DockerCompose: "services:\n  web:\n    image: nginx\n"},
+	}
+
+	repair := func(h *Handler, stackID string, user *middleware.User) (int, common.RepairStackResponse) {
+		c, rec := newTestContext(http.MethodPost, "/stacks/"+stackID+"/repair", "", user)
+		c.SetParamNames("id")
+		c.SetParamValues(stackID)
+		Expect(h.RepairStack(c)).To(Succeed())
+
+		var response common.RepairStackResponse
+		if rec.Code == 200 {
+			Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+		}
+		return rec.Code, response
+	}
+
+	getConfigMap := func(h *Handler) (*corev1.ConfigMap, error) {
+		configMap := &corev1.ConfigMap{}
+		err := h.k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "dev-alice", Name: "lissto-my-stack"}, configMap)
+		return configMap, err
+	}
+
+	It("should delete a ConfigMap whose stack was never created", func() {
+		orphan := newManifestsConfigMap("dev-alice", "my-stack", "kind: Deployment")
+		h := newTestHandler(config.DefaultSettings(), nil, orphan)
+
+		code, response := repair(h, "my-stack", alice)
+		Expect(code).To(Equal(200))
+		Expect(response).To(Equal(common.RepairStackResponse{
+			Stack:    "alice/my-stack",
+			Repaired: []string{RepairDeletedOrphanedConfigMap},
+		}))
+
+		_, err := getConfigMap(h)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should leave other ConfigMaps alone", func() {
+		unrelated := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "lissto-my-stack", Namespace: "dev-alice"}}
+		h := newTestHandler(config.DefaultSettings(), nil, unrelated)
+
+		code, _ := repair(h, "my-stack", alice)
+		Expect(code).To(Equal(404))
+		_, err := getConfigMap(h)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should set a missing owner reference", func() {
+		configMap := newManifestsConfigMap("dev-alice", "my-stack", "kind: Deployment")
+		h := newTestHandler(config.DefaultSettings(), nil, newStack("alice/web-bp", "lissto-my-stack"), configMap)
+
+		code, response := repair(h, "my-stack", alice)
+		Expect(code).To(Equal(200))
+		Expect(response.Repaired).To(Equal([]string{RepairSetOwnerReference}))
+
+		repaired, err := getConfigMap(h)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(repaired.OwnerReferences).To(HaveLen(1))
+		Expect(repaired.OwnerReferences[0].Name).To(Equal("my-stack"))
+		Expect(repaired.Data["manifests.yaml"]).To(Equal("kind: Deployment"))
+
+		// A second repair finds nothing to do
+		code, response = repair(h, "my-stack", alice)
+		Expect(code).To(Equal(200))
+		Expect(response.Repaired).To(BeEmpty())
+	})
+
+	It("should render a missing ConfigMap from the blueprint and the stack's images", func() {
+		h := newTestHandler(config.DefaultSettings(), nil, newStack("alice/web-bp", ""), blueprint)
+
+		code, response := repair(h, "alice/my-stack", &middleware.User{Name: "root", Role: authz.Admin})
+		Expect(code).To(Equal(200))
+		Expect(response.Repaired).To(Equal([]string{RepairSetManifestsRef, RepairRecreatedConfigMap}))
+
+		configMap, err := getConfigMap(h)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(configMap.OwnerReferences).To(HaveLen(1))
+		Expect(configMap.Data["manifests.yaml"]).To(ContainSubstring("nginx@sha256:abc"))
+		Expect(strings.Count(configMap.Data["manifests.yaml"], "kind: Deployment")).To(Equal(1))
+	})
+
+	It("should refuse to render manifests of inline stacks", func() {
+		h := newTestHandler(config.DefaultSettings(), nil, newStack("", "lissto-my-stack"))
+
+		code, _ := repair(h, "my-stack", alice)
+		Expect(code).To(Equal(409))
+	})
+
+	It("should not let other users repair the stack", func() {
+		configMap := newManifestsConfigMap("dev-alice", "my-stack", "kind: Deployment")
+		h := newTestHandler(config.DefaultSettings(), nil, newStack("alice/web-bp", "lissto-my-stack"), configMap)
+
+		code, _ := repair(h, "alice/my-stack", &middleware.User{Name: "bob", Role: authz.User})
+		Expect(code).To(Equal(404))
+		repaired, err := getConfigMap(h)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(repaired.OwnerReferences).To(BeEmpty())
+	})
+})
@@ -0,0 +1,59 @@
+package stack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/config"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+var _ = Describe("CreateStack representation", func() {
+	alice := &middleware.User{Name: "alice", Role: authz.User}
+
+	create := func(target string) (int, []byte) {
+		env := &envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}}
+		blueprint := &envv1alpha1.Blueprint{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "dev-alice"},
+			Spec:       envv1alpha1.BlueprintSpec{DockerCompose: "services:\n  api:\n    image: api\n"},
+		}
+		h := newTestHandler(config.DefaultSettings(), nil, env, blueprint)
+		h.cache = cache.NewMemoryCache()
+		Expect(h.cache.Set(context.Background(), "req-1", cache.PrepareResultCache{
+			Namespace: "dev-alice",
+			Images:    map[string]cache.ImageInfoCache{"api": {Digest: "registry.io/api@sha256:aaa", Image: "registry.io/api:main"}},
+		}, time.Minute)).To(Succeed())
+
+		c, rec := newTestContext(http.MethodPost, target, `{"blueprint":"alice/web","env":"dev","request_id":"req-1"}`, alice)
+		Expect(h.CreateStack(c)).To(Succeed())
+		return rec.Code, rec.Body.Bytes()
+	}
+
+	It("should return the scoped identifier by default", func() {
+		code, body := create("/stacks")
+
+		Expect(code).To(Equal(201), string(body))
+		Expect(string(body)).To(HavePrefix("alice/"))
+	})
+
+	It("should return the created stack with ?return=representation", func() {
+		code, body := create("/stacks?return=representation")
+
+		Expect(code).To(Equal(201), string(body))
+		var response StackResponse
+		Expect(json.Unmarshal(body, &response)).To(Succeed())
+		Expect(response.Name).NotTo(BeEmpty())
+		Expect(response.Namespace).To(Equal("dev-alice"))
+		Expect(response.BlueprintReference).To(Equal("alice/web"))
+		Expect(response.EnvReference).To(Equal("dev"))
+	})
+})
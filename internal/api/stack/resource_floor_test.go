@@ -0,0 +1,48 @@
+package stack
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/config"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+var _ = Describe("Create stack with resource floors", func() {
+	It("should deploy a service requesting 1 replica with the env's floor", func() {
+		settings := config.DefaultSettings()
+		settings.Resources.Floors.Envs = map[string]compose.ResourceFloor{
+			"production": {MinReplicas: 3, MinMemoryRequest: "256Mi"},
+		}
+		h := newTestHandler(settings, nil,
+			&envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "production", Namespace: "dev-alice"}})
+		h.cache = cache.NewMemoryCache()
+		Expect(h.cache.Set(context.Background(), "req-1", cache.PrepareResultCache{
+			Namespace: "dev-alice",
+			Images:    map[string]cache.ImageInfoCache{"web": {Digest: "registry.io/web@sha256:bbb", Image: "registry.io/web:1.0"}},
+			Compose:   "services:\n  web:\n    image: registry.io/web:1.0\n    deploy:\n      replicas: 1\n",
+		}, time.Minute)).To(Succeed())
+
+		alice := &middleware.User{Name: "alice", Role: authz.User}
+		c, rec := newTestContext(http.MethodPost, "/stacks", `{"env":"production","request_id":"req-1"}`, alice)
+		Expect(h.CreateStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(201), rec.Body.String())
+
+		stackName := strings.TrimPrefix(rec.Body.String(), "alice/")
+		configMap, err := h.k8sClient.GetConfigMap(context.Background(), "dev-alice", manifestsConfigMapName(stackName))
+		Expect(err).NotTo(HaveOccurred())
+		manifests := configMap.Data["manifests.yaml"]
+		Expect(manifests).To(ContainSubstring("replicas: 3"))
+		Expect(manifests).To(ContainSubstring("memory: 256Mi"))
+	})
+})
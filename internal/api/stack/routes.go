@@ -9,7 +9,22 @@ func RegisterRoutes(g *echo.Group, handler *Handler) {
 	// All authorization is handled in the handler methods
 	g.GET("", handler.GetStacks)
 	g.GET("/:id", handler.GetStack)
+	g.GET("/:id/conditions", handler.GetStackConditions)
+	g.GET("/:id/gitops-bundle", handler.GetGitOpsBundle)
+	g.GET("/:id/watch", handler.WatchStack)
 	g.POST("", handler.CreateStack)
 	g.PUT("/:id", handler.UpdateStack)
 	g.DELETE("/:id", handler.DeleteStack)
+	g.PUT("/:id/protection", handler.SetStackProtection)
+	g.POST("/:id/exec", handler.ExecStack)
+	g.POST("/:id/services/:service/recreate", handler.RecreateService)
+	g.POST("/:id/repair", handler.RepairStack)
+	g.POST("/:id/clone", handler.CloneStack)
+	g.POST("/:id/prepull", handler.PrepullStack)
+	g.GET("/:id/prepull", handler.GetPrepullStatus)
+}
+
+// RegisterEnvRoutes registers stack operations scoped to an env
+func RegisterEnvRoutes(g *echo.Group, handler *Handler) {
+	g.POST("/:id/refresh-images", handler.RefreshEnvImages)
 }
@@ -0,0 +1,185 @@
+package stack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/image"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+var _ = Describe("Required image signatures", func() {
+	const compose = "services:\n  api:\n    image: api\n    labels:\n      lissto.dev/require-signature: \"true\"\n  db:\n    image: postgres\n"
+
+	var (
+		h     *Handler
+		alice *middleware.User
+	)
+
+	BeforeEach(func() {
+		alice = &middleware.User{Name: "alice", Role: authz.User}
+		env := &envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}}
+		h = newTestHandler(config.DefaultSettings(), nil, env)
+		h.cache = cache.NewMemoryCache()
+	})
+
+	createStack := func(verified bool) (int, string) {
+		Expect(h.cache.Set(context.Background(), "req-1", cache.PrepareResultCache{
+			Namespace: "dev-alice",
+			Images: map[string]cache.ImageInfoCache{
+				"api": {Digest: "registry.io/api@sha256:aaa", Image: "registry.io/api:main", Verified: verified, Signer: "release"},
+				"db":  {Digest: "postgres@sha256:bbb", Image: "postgres:16"},
+			},
+			Compose: compose,
+		}, time.Minute)).To(Succeed())
+
+		c, rec := newTestContext(http.MethodPost, "/stacks", `{"env":"dev","request_id":"req-1"}`, alice)
+		Expect(h.CreateStack(c)).To(Succeed())
+		return rec.Code, rec.Body.String()
+	}
+
+	It("should create the stack when the required signature verified", func() {
+		code, body := createStack(true)
+		Expect(code).To(Equal(201), body)
+	})
+
+	It("should reject an image without a verified signature", func() {
+		code, body := createStack(false)
+		Expect(code).To(Equal(400))
+		Expect(body).To(Equal("images without a verified signature: api"))
+
+		stacks, err := h.k8sClient.ListStacks(context.Background(), "dev-alice")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stacks.Items).To(BeEmpty())
+	})
+
+	It("should require every image to be verified when configured", func() {
+		h.settings.Signatures.Required = true
+
+		code, body := createStack(true)
+		Expect(code).To(Equal(400))
+		Expect(body).To(Equal("images without a verified signature: db"))
+	})
+})
+
+// signedDigests verifies the signatures of the listed image digests only
+type signedDigests map[string]bool
+
+func (s signedDigests) VerifySignature(_ context.Context, imageRef string) (*image.SignatureResult, error) {
+	if !s[imageRef] {
+		return &image.SignatureResult{Signed: false, Error: "no signature found"}, nil
+	}
+	return &image.SignatureResult{Signed: true, Verified: true, Signer: "release"}, nil
+}
+
+var _ = Describe("Required image signatures on stack changes", func() {
+	const compose = "services:\n  api:\n    image: registry.io/api:main\n    labels:\n      lissto.dev/require-signature: \"true\"\n  db:\n    image: postgres:15\n"
+
+	var (
+		h     *Handler
+		alice *middleware.User
+	)
+
+	BeforeEach(func() {
+		alice = &middleware.User{Name: "alice", Role: authz.User}
+		h = newTestHandler(config.DefaultSettings(), nil,
+			&envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}},
+			&envv1alpha1.Blueprint{
+				ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "dev-alice"},
+				Spec:       envv1alpha1.BlueprintSpec{DockerCompose: compose},
+			},
+			&envv1alpha1.Stack{
+				ObjectMeta: metav1.ObjectMeta{Name: "api-stack", Namespace: "dev-alice"},
+				Spec: envv1alpha1.StackSpec{
+					BlueprintReference: "alice/api",
+					Env:                "dev",
+					Images: map[string]envv1alpha1.ImageInfo{
+						"api": {Image: "registry.io/api:main", Digest: "registry.io/api@sha256:aaa"},
+						"db":  {Image: "postgres:15", Digest: "postgres@sha256:old"},
+					},
+				},
+			})
+		h.signatureVerifier = signedDigests{"registry.io/api@sha256:signed": true}
+	})
+
+	update := func(service, digest string) (int, string) {
+		c, rec := newTestContext(http.MethodPut, "/stacks/alice/api-stack",
+			`{"images":{"`+service+`":{"digest":"`+digest+`"}}}`, alice)
+		c.SetParamNames("id")
+		c.SetParamValues("alice/api-stack")
+		Expect(h.UpdateStack(c)).To(Succeed())
+		return rec.Code, rec.Body.String()
+	}
+
+	digestOf := func(service string) string {
+		stack, err := h.k8sClient.GetStack(context.Background(), "dev-alice", "api-stack")
+		Expect(err).NotTo(HaveOccurred())
+		return stack.Spec.Images[service].Digest
+	}
+
+	It("should reject an update to an unsigned image when the blueprint requires a signature", func() {
+		code, body := update("api", "registry.io/api@sha256:unsigned")
+		Expect(code).To(Equal(422))
+		Expect(body).To(ContainSubstring("Service api"))
+		Expect(digestOf("api")).To(Equal("registry.io/api@sha256:aaa"))
+	})
+
+	It("should apply an update to a verified image", func() {
+		code, body := update("api", "registry.io/api@sha256:signed")
+		Expect(code).To(Equal(200), body)
+		Expect(digestOf("api")).To(Equal("registry.io/api@sha256:signed"))
+	})
+
+	It("should not verify services that need no signature", func() {
+		code, body := update("db", "postgres@sha256:new")
+		Expect(code).To(Equal(200), body)
+		Expect(digestOf("db")).To(Equal("postgres@sha256:new"))
+	})
+
+	It("should verify every service when the settings require signatures", func() {
+		h.settings.Signatures.Required = true
+
+		code, _ := update("db", "postgres@sha256:new")
+		Expect(code).To(Equal(422))
+		Expect(digestOf("db")).To(Equal("postgres@sha256:old"))
+	})
+
+	It("should reject required signatures when no verifier is configured", func() {
+		h.signatureVerifier = nil
+
+		code, _ := update("api", "registry.io/api@sha256:signed")
+		Expect(code).To(Equal(422))
+		Expect(digestOf("api")).To(Equal("registry.io/api@sha256:aaa"))
+	})
+
+	It("should not refresh to a re-pushed image without a verified signature", func() {
+		h.imageResolver = &digestResolver{digests: map[string]string{
+			"registry.io/api:main": "registry.io/api@sha256:unsigned",
+			"postgres:15":          "postgres@sha256:new",
+		}}
+
+		c, rec := newTestContext(http.MethodPost, "/envs/dev/refresh-images", "", alice)
+		c.SetParamNames("id")
+		c.SetParamValues("dev")
+		Expect(h.RefreshEnvImages(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+
+		var response common.RefreshEnvImagesResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+		Expect(response.Stacks).To(HaveLen(1))
+		Expect(response.Stacks[0].Errors).To(ConsistOf(ContainSubstring("api: image registry.io/api@sha256:unsigned has no verified signature")))
+		Expect(digestOf("api")).To(Equal("registry.io/api@sha256:aaa"))
+		Expect(digestOf("db")).To(Equal("postgres@sha256:new"))
+	})
+})
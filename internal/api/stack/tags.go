@@ -0,0 +1,62 @@
+package stack
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+const (
+	// DescriptionAnnotation holds the free-form stack description
+	DescriptionAnnotation = "lissto.dev/description"
+	// TagLabelPrefix prefixes one label per stack tag, e.g. lissto.dev/tag.feature-x
+	TagLabelPrefix = "lissto.dev/tag."
+
+	// maxDescriptionLength keeps descriptions well within the annotation size limit
+	maxDescriptionLength = 1024
+)
+
+// validateTags checks that every tag can be used as the name part of a label key
+func validateTags(tags []string) error {
+	for _, tag := range tags {
+		if tag == "" {
+			return fmt.Errorf("tag must not be empty")
+		}
+		if errs := validation.IsQualifiedName(TagLabelPrefix + tag); len(errs) > 0 {
+			return fmt.Errorf("invalid tag %q: %s", tag, strings.Join(errs, "; "))
+		}
+	}
+	return nil
+}
+
+// applyDescriptionAndTags stores the description and tags on the stack metadata
+func applyDescriptionAndTags(stack *envv1alpha1.Stack, description string, tags []string) {
+	if description != "" {
+		stack.Annotations[DescriptionAnnotation] = description
+	}
+	for _, tag := range tags {
+		stack.Labels[TagLabelPrefix+tag] = "true"
+	}
+}
+
+// stackTags returns the sorted tags of a stack
+func stackTags(stack *envv1alpha1.Stack) []string {
+	var tags []string
+	for key := range stack.Labels {
+		if tag, ok := strings.CutPrefix(key, TagLabelPrefix); ok && tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// hasTag reports whether a stack carries the given tag
+func hasTag(stack *envv1alpha1.Stack, tag string) bool {
+	_, ok := stack.Labels[TagLabelPrefix+tag]
+	return ok
+}
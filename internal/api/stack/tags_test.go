@@ -0,0 +1,105 @@
+package stack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/config"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+var _ = Describe("Stack description and tags", func() {
+	var alice *middleware.User
+
+	BeforeEach(func() {
+		alice = &middleware.User{Name: "alice", Role: authz.User}
+	})
+
+	taggedStack := func(name string, tags ...string) *envv1alpha1.Stack {
+		stack := &envv1alpha1.Stack{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "dev-alice",
+				Labels:      map[string]string{},
+				Annotations: map[string]string{},
+			},
+		}
+		applyDescriptionAndTags(stack, "", tags)
+		return stack
+	}
+
+	It("should store the description and tags and return them in the stack detail", func() {
+		env := &envv1alpha1.Env{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "dev-alice"}}
+		blueprint := &envv1alpha1.Blueprint{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "dev-alice"},
+			Spec:       envv1alpha1.BlueprintSpec{DockerCompose: "services:\n  api:\n    image: api\n"},
+		}
+		h := newTestHandler(config.DefaultSettings(), nil, env, blueprint)
+		h.cache = cache.NewMemoryCache()
+		Expect(h.cache.Set(context.Background(), "req-1", cache.PrepareResultCache{
+			Namespace: "dev-alice",
+			Images:    map[string]cache.ImageInfoCache{"api": {Digest: "registry.io/api@sha256:aaa"}},
+		}, time.Minute)).To(Succeed())
+
+		c, rec := newTestContext(http.MethodPost, "/stacks",
+			`{"blueprint":"alice/web","env":"dev","request_id":"req-1","description":"Checkout rework demo","tags":["feature-x","demo"]}`, alice)
+		Expect(h.CreateStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(201), rec.Body.String())
+		stackName := strings.TrimPrefix(rec.Body.String(), "alice/")
+
+		stack, err := h.k8sClient.GetStack(context.Background(), "dev-alice", stackName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stack.Annotations).To(HaveKeyWithValue(DescriptionAnnotation, "Checkout rework demo"))
+		Expect(stack.Labels).To(HaveKeyWithValue("lissto.dev/tag.feature-x", "true"))
+
+		c, rec = newTestContext(http.MethodGet, "/stacks/"+stackName, "", alice)
+		c.SetParamNames("id")
+		c.SetParamValues(stackName)
+		Expect(h.GetStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(200))
+
+		var resp StackResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.Description).To(Equal("Checkout rework demo"))
+		Expect(resp.Tags).To(Equal([]string{"demo", "feature-x"}))
+	})
+
+	It("should reject tags that are not valid label names", func() {
+		h := newTestHandler(config.DefaultSettings(), nil)
+
+		c, rec := newTestContext(http.MethodPost, "/stacks",
+			`{"blueprint":"alice/web","env":"dev","request_id":"req-1","tags":["not a tag"]}`, alice)
+		Expect(h.CreateStack(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(400))
+		Expect(rec.Body.String()).To(ContainSubstring(`invalid tag "not a tag"`))
+	})
+
+	It("should filter the stack list by tag", func() {
+		h := newTestHandler(config.DefaultSettings(), nil,
+			taggedStack("one", "demo"),
+			taggedStack("two", "demo", "feature-x"),
+			taggedStack("three"))
+
+		c, rec := newTestContext(http.MethodGet, "/stacks?tag=demo", "", alice)
+		Expect(h.GetStacks(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(200))
+
+		var stacks []envv1alpha1.Stack
+		Expect(json.Unmarshal(rec.Body.Bytes(), &stacks)).To(Succeed())
+		names := make([]string, len(stacks))
+		for i, stack := range stacks {
+			names[i] = stack.Name
+		}
+		Expect(names).To(ConsistOf("one", "two"))
+	})
+})
@@ -0,0 +1,284 @@
+package stack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+// Rollout phases reported by the watch stream
+const (
+	RolloutProgressing = "progressing" // Some services have no ready pod yet
+	RolloutReady       = "ready"       // Every service has a ready (or completed) pod
+	RolloutDegraded    = "degraded"    // A pod is failing, e.g. crash looping or unable to pull its image
+)
+
+// SSE event names sent by GET /stacks/:id/watch; ready, degraded and timeout end the stream
+const (
+	WatchEventStatus  = "status"
+	WatchEventTimeout = "timeout"
+)
+
+// watchTimeout ends streams whose stack neither becomes ready nor degraded
+const watchTimeout = 15 * time.Minute
+
+// degradedReasons are container waiting reasons that will not resolve by waiting
+var degradedReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
+// PodWatcher watches the pods of a stack
+type PodWatcher interface {
+	WatchPodsWithLabels(ctx context.Context, namespace string, labels map[string]string) (watch.Interface, error)
+	ListPodsWithLabels(ctx context.Context, namespace string, labels map[string]string) (*corev1.PodList, error)
+}
+
+// ServiceRollout is the rollout state of one stack service
+type ServiceRollout struct {
+	Service   string `json:"service"`
+	Ready     bool   `json:"ready"`
+	Pods      int    `json:"pods"`
+	ReadyPods int    `json:"ready_pods"`
+	Reason    string `json:"reason,omitempty"` // Why the service is not ready, e.g. ContainerCreating
+}
+
+// StackRolloutEvent is the data of every SSE event sent by the watch stream
+type StackRolloutEvent struct {
+	ID       string           `json:"id"`
+	Phase    string           `json:"phase"`
+	Services []ServiceRollout `json:"services"`
+}
+
+// WatchStack handles GET /stacks/:id/watch
+// Streams the rollout as Server-Sent Events until the stack is ready or degraded, the timeout
+// passes or the client disconnects
+func (h *Handler) WatchStack(c echo.Context) error {
+	idParam := c.Param("id")
+	user, _ := middleware.GetUserFromContext(c)
+
+	// Get allowed namespaces for authorization
+	allowedNS := h.authorizer.GetAllowedNamespaces(user.Role, authz.ActionRead, authz.ResourceStack, user.Name)
+	if len(allowedNS) == 0 {
+		return c.String(403, "Permission denied: no accessible namespaces")
+	}
+
+	// Resolve namespace from ID
+	targetNamespace, name, searchAll := h.nsManager.ResolveNamespaceFromID(idParam, allowedNS)
+
+	// Try to find the stack
+	userNS := h.nsManager.GetDeveloperNamespace(user.Name)
+	globalNS := h.nsManager.GetGlobalNamespace()
+	stack, found := h.findStack(c, targetNamespace, name, searchAll, userNS, globalNS, allowedNS)
+	if !found {
+		return c.String(404, fmt.Sprintf("Stack '%s' not found", idParam))
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), watchTimeout)
+	defer cancel()
+
+	// Watch before listing so no change between the two is missed
+	podLabels := map[string]string{"lissto.dev/stack": stack.Name}
+	watcher, err := h.podWatcher.WatchPodsWithLabels(ctx, stack.Namespace, podLabels)
+	if err != nil {
+		logging.Logger.Error("Failed to watch stack pods",
+			zap.String("stack", stack.Name),
+			zap.String("namespace", stack.Namespace),
+			zap.Error(err))
+		return c.String(500, "Failed to watch stack")
+	}
+	defer watcher.Stop()
+
+	podList, err := h.podWatcher.ListPodsWithLabels(ctx, stack.Namespace, podLabels)
+	if err != nil {
+		logging.Logger.Error("Failed to list stack pods",
+			zap.String("stack", stack.Name),
+			zap.String("namespace", stack.Namespace),
+			zap.Error(err))
+		return c.String(500, "Failed to list stack pods")
+	}
+	pods := make(map[string]*corev1.Pod, len(podList.Items))
+	for i := range podList.Items {
+		pods[podList.Items[i].Name] = &podList.Items[i]
+	}
+
+	logging.Logger.Info("Watching stack rollout",
+		zap.String("user", user.Name),
+		zap.String("stack", stack.Name),
+		zap.String("namespace", stack.Namespace))
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.WriteHeader(http.StatusOK)
+
+	id := h.nsManager.MustGenerateScopedID(stack.Namespace, stack.Name)
+	var last *StackRolloutEvent
+	for {
+		event := StackRolloutEvent{ID: id}
+		event.Phase, event.Services = stackRollout(stack, pods)
+
+		// Only changes are sent; pod updates that keep the rollout state are skipped
+		if last == nil || !reflect.DeepEqual(*last, event) {
+			name := WatchEventStatus
+			if event.Phase != RolloutProgressing {
+				name = event.Phase
+			}
+			if err := writeSSE(res, name, event); err != nil {
+				return nil
+			}
+			if event.Phase != RolloutProgressing {
+				logging.Logger.Info("Stack rollout finished",
+					zap.String("stack", stack.Name),
+					zap.String("phase", event.Phase))
+				return nil
+			}
+			last = &event
+		}
+
+		select {
+		case <-ctx.Done():
+			// A disconnected client cannot be written to; only a timeout is reported
+			if ctx.Err() == context.DeadlineExceeded && c.Request().Context().Err() == nil {
+				_ = writeSSE(res, WatchEventTimeout, last)
+			}
+			return nil
+		case change, ok := <-watcher.ResultChan():
+			if !ok {
+				// The API server closed the watch; the client reconnects for a fresh one
+				return nil
+			}
+			pod, isPod := change.Object.(*corev1.Pod)
+			if !isPod {
+				continue
+			}
+			switch change.Type {
+			case watch.Added, watch.Modified:
+				pods[pod.Name] = pod
+			case watch.Deleted:
+				delete(pods, pod.Name)
+			}
+		}
+	}
+}
+
+// stackRollout derives the rollout phase and per-service state from the stack's pods
+// Terminating pods are ignored; pods of services no longer in the stack are ignored as well
+func stackRollout(stack *envv1alpha1.Stack, pods map[string]*corev1.Pod) (string, []ServiceRollout) {
+	services := make(map[string]*ServiceRollout, len(stack.Spec.Images))
+	for service := range stack.Spec.Images {
+		services[service] = &ServiceRollout{Service: service, Reason: "NoPods"}
+	}
+
+	degraded := false
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		rollout, ok := services[pod.Labels["io.kompose.service"]]
+		if !ok {
+			continue
+		}
+		rollout.Pods++
+		if podReady(pod) {
+			rollout.ReadyPods++
+			continue
+		}
+		if reason := podFailure(pod); reason != "" {
+			rollout.Reason = reason
+			degraded = true
+		} else if rollout.Reason == "NoPods" {
+			rollout.Reason = podWaitingReason(pod)
+		}
+	}
+
+	result := make([]ServiceRollout, 0, len(services))
+	allReady := true
+	for _, rollout := range services {
+		if rollout.ReadyPods > 0 {
+			rollout.Ready = true
+			rollout.Reason = ""
+		} else {
+			allReady = false
+		}
+		result = append(result, *rollout)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Service < result[j].Service })
+
+	switch {
+	case degraded:
+		return RolloutDegraded, result
+	case allReady:
+		return RolloutReady, result
+	default:
+		return RolloutProgressing, result
+	}
+}
+
+// podReady reports whether the pod is ready, counting completed job pods as ready
+func podReady(pod *corev1.Pod) bool {
+	if pod.Status.Phase == corev1.PodSucceeded {
+		return true
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// podFailure returns why the pod cannot become ready on its own, empty if it still may
+func podFailure(pod *corev1.Pod) string {
+	if pod.Status.Phase == corev1.PodFailed {
+		return "PodFailed"
+	}
+	for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		if status.State.Waiting != nil && degradedReasons[status.State.Waiting.Reason] {
+			return status.State.Waiting.Reason
+		}
+	}
+	return ""
+}
+
+// podWaitingReason describes what a pending pod is waiting for
+func podWaitingReason(pod *corev1.Pod) string {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason != "" {
+			return status.State.Waiting.Reason
+		}
+	}
+	return string(pod.Status.Phase)
+}
+
+// writeSSE writes a single Server-Sent Event with JSON data and flushes it
+func writeSSE(res *echo.Response, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	res.Flush()
+	return nil
+}
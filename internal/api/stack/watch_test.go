@@ -0,0 +1,175 @@
+package stack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+// fakePodWatcher serves a fixed pod list and a fake watch driven by the test
+type fakePodWatcher struct {
+	pods    []corev1.Pod
+	watcher *watch.FakeWatcher
+}
+
+func (f *fakePodWatcher) WatchPodsWithLabels(context.Context, string, map[string]string) (watch.Interface, error) {
+	return f.watcher, nil
+}
+
+func (f *fakePodWatcher) ListPodsWithLabels(context.Context, string, map[string]string) (*corev1.PodList, error) {
+	return &corev1.PodList{Items: f.pods}, nil
+}
+
+// sseEvent is a parsed Server-Sent Event
+type sseEvent struct {
+	name string
+	data StackRolloutEvent
+}
+
+// parseSSE splits an SSE body into its events
+func parseSSE(body string) []sseEvent {
+	var events []sseEvent
+	for _, block := range strings.Split(strings.TrimSpace(body), "\n\n") {
+		var event sseEvent
+		for _, line := range strings.Split(block, "\n") {
+			if name, ok := strings.CutPrefix(line, "event: "); ok {
+				event.name = name
+			}
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				Expect(json.Unmarshal([]byte(data), &event.data)).To(Succeed())
+			}
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// rolloutPod is a pod of a stack service with the given readiness
+func rolloutPod(name, service string, ready bool) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "dev-alice", Labels: map[string]string{
+			"lissto.dev/stack":   "web",
+			"io.kompose.service": service,
+		}},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodPending,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+			ContainerStatuses: []corev1.ContainerStatus{{State: corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"},
+			}}},
+		},
+	}
+}
+
+var _ = Describe("Stack rollout watch", func() {
+	var (
+		h       *Handler
+		alice   *middleware.User
+		watcher *watch.FakeWatcher
+	)
+
+	BeforeEach(func() {
+		alice = &middleware.User{Name: "alice", Role: authz.User}
+		stack := &envv1alpha1.Stack{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "dev-alice"},
+			Spec: envv1alpha1.StackSpec{Images: map[string]envv1alpha1.ImageInfo{
+				"web": {Digest: "registry.example.com/web@sha256:abc"},
+				"db":  {Image: "postgres:16"},
+			}},
+		}
+		h = newTestHandler(config.DefaultSettings(), nil, stack)
+		watcher = watch.NewFake()
+		h.podWatcher = &fakePodWatcher{
+			pods:    []corev1.Pod{*rolloutPod("db-0", "db", true)},
+			watcher: watcher,
+		}
+	})
+
+	// watchStack runs the handler in the background, returning a channel closed once it finished
+	watchStack := func(ctx context.Context) (chan struct{}, func() string) {
+		c, rec := newTestContext(http.MethodGet, "/stacks/web/watch", "", alice)
+		c.SetRequest(c.Request().WithContext(ctx))
+		c.SetParamNames("id")
+		c.SetParamValues("web")
+
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			Expect(h.WatchStack(c)).To(Succeed())
+		}()
+		return done, func() string {
+			Expect(rec.Header().Get("Content-Type")).To(Equal("text/event-stream"))
+			return rec.Body.String()
+		}
+	}
+
+	It("should stream a pending to ready transition and end with a ready event", func() {
+		done, body := watchStack(context.Background())
+
+		watcher.Add(rolloutPod("web-1", "web", false))
+		watcher.Modify(rolloutPod("web-1", "web", true))
+		Eventually(done).Should(BeClosed())
+
+		events := parseSSE(body())
+		Expect(events).To(HaveLen(3))
+
+		Expect(events[0].name).To(Equal(WatchEventStatus))
+		Expect(events[0].data.ID).To(Equal("alice/web"))
+		Expect(events[0].data.Phase).To(Equal(RolloutProgressing))
+		Expect(events[0].data.Services).To(Equal([]ServiceRollout{
+			{Service: "db", Ready: true, Pods: 1, ReadyPods: 1},
+			{Service: "web", Reason: "NoPods"},
+		}))
+
+		Expect(events[1].name).To(Equal(WatchEventStatus))
+		Expect(events[1].data.Services[1]).To(Equal(ServiceRollout{Service: "web", Pods: 1, Reason: "ContainerCreating"}))
+
+		Expect(events[2].name).To(Equal(RolloutReady))
+		Expect(events[2].data.Phase).To(Equal(RolloutReady))
+		Expect(events[2].data.Services[1]).To(Equal(ServiceRollout{Service: "web", Ready: true, Pods: 1, ReadyPods: 1}))
+		Expect(watcher.IsStopped()).To(BeTrue())
+	})
+
+	It("should end with a degraded event when a pod crash loops", func() {
+		done, body := watchStack(context.Background())
+
+		crashing := rolloutPod("web-1", "web", false)
+		crashing.Status.ContainerStatuses[0].State.Waiting.Reason = "CrashLoopBackOff"
+		watcher.Add(crashing)
+		Eventually(done).Should(BeClosed())
+
+		events := parseSSE(body())
+		Expect(events).To(HaveLen(2))
+		Expect(events[1].name).To(Equal(RolloutDegraded))
+		Expect(events[1].data.Services[1].Reason).To(Equal("CrashLoopBackOff"))
+	})
+
+	It("should stop the watch when the client disconnects", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		done, body := watchStack(ctx)
+
+		watcher.Add(rolloutPod("web-1", "web", false))
+		cancel()
+		Eventually(done).Should(BeClosed())
+
+		Expect(watcher.IsStopped()).To(BeTrue())
+		Expect(parseSSE(body())).To(HaveLen(2))
+	})
+})
@@ -1,18 +1,26 @@
 package user
 
 import (
+	"time"
+
 	"github.com/labstack/echo/v4"
 
 	"github.com/lissto-dev/api/internal/api/common"
 	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
 )
 
+// ExpiryWarningWindow is how long before expiry a key is reported as expiring soon
+const ExpiryWarningWindow = 7 * 24 * time.Hour
+
 // Handler handles user-related HTTP requests
-type Handler struct{}
+type Handler struct {
+	authorizer *authz.Authorizer
+}
 
 // NewHandler creates a new user handler
-func NewHandler() *Handler {
-	return &Handler{}
+func NewHandler(authorizer *authz.Authorizer) *Handler {
+	return &Handler{authorizer: authorizer}
 }
 
 // GetCurrentUser handles GET /user or GET /me
@@ -28,3 +36,40 @@ func (h *Handler) GetCurrentUser(c echo.Context) error {
 	}
 	return c.JSON(200, response)
 }
+
+// WhoAmI handles GET /auth/whoami
+// Confirms the API key is valid and summarizes the namespaces it can reach per resource and action,
+// so tooling can show accurate capability hints and warn about keys close to expiry
+func (h *Handler) WhoAmI(c echo.Context) error {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		return c.NoContent(401)
+	}
+
+	response := common.WhoAmIResponse{
+		Name:        user.Name,
+		Role:        user.Role.String(),
+		Scopes:      user.Scopes,
+		ExpiresAt:   user.ExpiresAt,
+		ExpiresSoon: user.ExpiresAt != nil && time.Until(*user.ExpiresAt) < ExpiryWarningWindow,
+		Permissions: map[string]map[string][]string{},
+	}
+
+	for _, resourceType := range authz.ResourceTypes() {
+		// Resources outside the key's scopes are unreachable whatever the role allows
+		if !user.HasScope(resourceType) {
+			continue
+		}
+		actions := map[string][]string{}
+		for _, action := range authz.Actions() {
+			if namespaces := h.authorizer.GetAllowedNamespaces(user.Role, action, resourceType, user.Name); len(namespaces) > 0 {
+				actions[string(action)] = namespaces
+			}
+		}
+		if len(actions) > 0 {
+			response.Permissions[string(resourceType)] = actions
+		}
+	}
+
+	return c.JSON(200, response)
+}
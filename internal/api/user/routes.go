@@ -9,3 +9,8 @@ func RegisterRoutes(g *echo.Group, handler *Handler) {
 	g.GET("", handler.GetCurrentUser)
 	g.GET("/me", handler.GetCurrentUser)
 }
+
+// RegisterAuthRoutes registers API key introspection routes
+func RegisterAuthRoutes(g *echo.Group, handler *Handler) {
+	g.GET("/whoami", handler.WhoAmI)
+}
@@ -0,0 +1,18 @@
+package user_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+func TestUser(t *testing.T) {
+	// Initialize logger for tests
+	_ = logging.InitLogger("info", "console")
+
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "User Suite")
+}
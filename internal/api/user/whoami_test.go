@@ -0,0 +1,109 @@
+package user_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/user"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+var _ = Describe("WhoAmI", func() {
+	var handler *user.Handler
+
+	BeforeEach(func() {
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		handler = user.NewHandler(authz.NewAuthorizer(authz.NewNamespaceManager(cfg)))
+	})
+
+	whoami := func(u *middleware.User) (int, common.WhoAmIResponse) {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/auth/whoami", nil), rec)
+		c.Set("user", u)
+		Expect(handler.WhoAmI(c)).To(Succeed())
+
+		var response common.WhoAmIResponse
+		if rec.Code == 200 {
+			Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+		}
+		return rec.Code, response
+	}
+
+	It("should summarize an admin key", func() {
+		code, response := whoami(&middleware.User{Name: "root", Role: authz.Admin})
+
+		Expect(code).To(Equal(200))
+		Expect(response.Role).To(Equal("admin"))
+		Expect(response.Scopes).To(BeEmpty())
+		Expect(response.ExpiresAt).To(BeNil())
+		Expect(response.ExpiresSoon).To(BeFalse())
+		Expect(response.Permissions).To(HaveLen(5))
+		Expect(response.Permissions["stack"]).To(Equal(map[string][]string{
+			"list":   {"*"},
+			"read":   {"*"},
+			"delete": {"*"},
+		}))
+	})
+
+	It("should summarize a developer key", func() {
+		code, response := whoami(&middleware.User{Name: "alice", Role: authz.User})
+
+		Expect(code).To(Equal(200))
+		Expect(response.Name).To(Equal("alice"))
+		Expect(response.Role).To(Equal("user"))
+		Expect(response.Permissions["stack"]["read"]).To(ConsistOf("lissto-global", "dev-alice"))
+		Expect(response.Permissions["stack"]["create"]).To(Equal([]string{"dev-alice"}))
+		Expect(response.Permissions["secret"]["delete"]).To(Equal([]string{"dev-alice"}))
+	})
+
+	It("should limit a scoped key to its resources and report its expiry", func() {
+		expiresAt := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+		code, response := whoami(&middleware.User{
+			Name:      "ci",
+			Role:      authz.Deploy,
+			Scopes:    []string{"stack", "blueprint"},
+			ExpiresAt: &expiresAt,
+		})
+
+		Expect(code).To(Equal(200))
+		Expect(response.Scopes).To(Equal([]string{"stack", "blueprint"}))
+		Expect(response.ExpiresAt).NotTo(BeNil())
+		Expect(response.ExpiresAt.Equal(expiresAt)).To(BeTrue())
+		Expect(response.ExpiresSoon).To(BeTrue())
+		Expect(response.Permissions).To(HaveLen(2))
+		Expect(response.Permissions["stack"]).To(Equal(map[string][]string{
+			"list":   {"*"},
+			"read":   {"*"},
+			"create": {"*"},
+		}))
+		Expect(response.Permissions).NotTo(HaveKey("secret"))
+	})
+
+	It("should not report a distant expiry as soon", func() {
+		expiresAt := time.Now().Add(90 * 24 * time.Hour)
+		_, response := whoami(&middleware.User{Name: "alice", Role: authz.User, ExpiresAt: &expiresAt})
+
+		Expect(response.ExpiresSoon).To(BeFalse())
+	})
+
+	It("should reject requests without a user", func() {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/auth/whoami", nil), rec)
+		Expect(handler.WhoAmI(c)).To(Succeed())
+
+		Expect(rec.Code).To(Equal(401))
+	})
+})
@@ -0,0 +1,56 @@
+package variable_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/variable"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/k8s"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+var _ = Describe("CreateVariable conflict", func() {
+	It("should describe the existing variable", func() {
+		createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		nsManager := authz.NewNamespaceManager(cfg)
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		k8sClient := k8s.NewClientFromClient(fake.NewClientBuilder().WithScheme(scheme).WithObjects(&envv1alpha1.LisstoVariable{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "dev-alice", CreationTimestamp: metav1.NewTime(createdAt)},
+			Spec:       envv1alpha1.LisstoVariableSpec{Env: "dev", Data: map[string]string{"HOST": "db"}},
+		}).Build(), scheme)
+		handler := variable.NewHandler(k8sClient, authz.NewAuthorizer(nsManager), nsManager, cfg, config.DefaultSettings())
+
+		e := echo.New()
+		e.Validator = &testValidator{validator: validator.New()}
+		req := httptest.NewRequest(http.MethodPost, "/variables", strings.NewReader(`{"name":"app","env":"dev","data":{"HOST":"db"}}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user", &middleware.User{Name: "alice", Role: authz.User})
+		Expect(handler.CreateVariable(c)).To(Succeed())
+
+		Expect(rec.Code).To(Equal(409))
+		var response common.ConflictResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+		Expect(response).To(Equal(common.ConflictResponse{Error: "Variable 'app' already exists", ID: "dev-alice/app", CreatedAt: createdAt}))
+	})
+})
@@ -9,6 +9,7 @@ import (
 	"github.com/lissto-dev/api/internal/api/common"
 	"github.com/lissto-dev/api/internal/middleware"
 	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
 	"github.com/lissto-dev/api/pkg/k8s"
 	"github.com/lissto-dev/api/pkg/logging"
 	"github.com/lissto-dev/api/pkg/metadata"
@@ -23,6 +24,8 @@ type Handler struct {
 	authorizer *authz.Authorizer
 	nsManager  *authz.NamespaceManager
 	config     *controllerconfig.Config
+
+	maxValueLength int // Maximum value size in bytes (0 = unlimited)
 }
 
 // NewHandler creates a new variable handler
@@ -30,13 +33,16 @@ func NewHandler(
 	k8sClient *k8s.Client,
 	authorizer *authz.Authorizer,
 	nsManager *authz.NamespaceManager,
-	config *controllerconfig.Config,
+	cfg *controllerconfig.Config,
+	settings *config.Settings,
 ) *Handler {
 	return &Handler{
 		k8sClient:  k8sClient,
 		authorizer: authorizer,
 		nsManager:  nsManager,
-		config:     config,
+		config:     cfg,
+
+		maxValueLength: settings.Values.MaxLength,
 	}
 }
 
@@ -47,11 +53,15 @@ type CreateVariableRequest struct {
 	Env        string            `json:"env,omitempty"`        // required for scope=env
 	Repository string            `json:"repository,omitempty"` // required for scope=repo
 	Data       map[string]string `json:"data" validate:"required"`
+	// Optional per-key content rules, e.g. {"PORT": {"pattern": "[0-9]+"}, "CONFIG": {"format": "json"}}
+	Validations map[string]common.ValueRule `json:"validations,omitempty"`
 }
 
 // UpdateVariableRequest represents a request to update a variable config
 type UpdateVariableRequest struct {
 	Data map[string]string `json:"data" validate:"required"`
+	// Optional per-key content rules, see CreateVariableRequest
+	Validations map[string]common.ValueRule `json:"validations,omitempty"`
 }
 
 // VariableResponse represents a variable config response
@@ -107,6 +117,9 @@ func (h *Handler) CreateVariable(c echo.Context) error {
 		logging.Logger.Error("Request validation failed", zap.Error(err))
 		return c.String(400, err.Error())
 	}
+	if rejected, err := common.RejectInvalidValues(c, req.Data, h.maxValueLength, req.Validations); rejected {
+		return err
+	}
 
 	// Default scope to "env" if not specified
 	scope := req.Scope
@@ -141,6 +154,15 @@ func (h *Handler) CreateVariable(c echo.Context) error {
 		return c.String(403, fmt.Sprintf("Permission denied: %s", perm.Reason))
 	}
 
+	if rejected, err := common.RejectIfFrozen(c, h.nsManager, h.k8sClient, namespace, user.Name, "POST /variables"); rejected {
+		return err
+	}
+
+	// secretref:// values must point at an existing secret key
+	if rejected, err := common.RejectDanglingSecretRefs(c, h.k8sClient, namespace, req.Env, req.Data); rejected {
+		return err
+	}
+
 	// Ensure namespace exists
 	if err := h.k8sClient.EnsureNamespace(c.Request().Context(), namespace); err != nil {
 		logging.Logger.Error("Failed to ensure namespace",
@@ -155,7 +177,8 @@ func (h *Handler) CreateVariable(c echo.Context) error {
 		logging.Logger.Error("Variable already exists",
 			zap.String("name", req.Name),
 			zap.String("namespace", namespace))
-		return c.String(409, fmt.Sprintf("Variable '%s' already exists", req.Name))
+		return c.JSON(409, common.NewConflictResponse(fmt.Sprintf("Variable '%s' already exists", req.Name),
+			fmt.Sprintf("%s/%s", namespace, req.Name), existing))
 	}
 
 	// Build labels for discovery
@@ -205,13 +228,15 @@ func (h *Handler) CreateVariable(c echo.Context) error {
 		zap.String("namespace", namespace),
 		zap.String("user", user.Name))
 
-	return c.JSON(201, VariableResponse{
-		ID:         fmt.Sprintf("%s/%s", namespace, req.Name),
-		Name:       req.Name,
-		Scope:      scope,
-		Env:        req.Env,
-		Repository: req.Repository,
-		Data:       req.Data,
+	return common.HandleCreatedResponse(c, 201, &FormattableVariable{k8sObj: variable, nsManager: h.nsManager}, func() error {
+		return c.JSON(201, VariableResponse{
+			ID:         fmt.Sprintf("%s/%s", namespace, req.Name),
+			Name:       req.Name,
+			Scope:      scope,
+			Env:        req.Env,
+			Repository: req.Repository,
+			Data:       req.Data,
+		})
 	})
 }
 
@@ -233,16 +258,6 @@ func (h *Handler) GetVariables(c echo.Context) error {
 		return c.String(500, "Failed to list variables")
 	}
 
-	// Also list global variables if user has access
-	globalNS := h.nsManager.GetGlobalNamespace()
-	globalList, err := h.k8sClient.ListLisstoVariables(c.Request().Context(), globalNS)
-	if err != nil {
-		logging.Logger.Warn("Failed to list global variables",
-			zap.String("namespace", globalNS),
-			zap.Error(err))
-		// Continue without global variables
-	}
-
 	// Combine and convert to response format
 	var variables []VariableResponse
 	for _, v := range variableList.Items {
@@ -257,8 +272,20 @@ func (h *Handler) GetVariables(c echo.Context) error {
 			KeyUpdatedAt: metadata.GetKeyTimestamps(&v),
 		})
 	}
-	if globalList != nil {
-		for _, v := range globalList.Items {
+
+	// Also list shared namespaces the role reads (global unless disabled for the role)
+	for _, sharedNS := range h.authorizer.SharedReadNamespaces(user.Role) {
+		if sharedNS == namespace {
+			continue
+		}
+		sharedList, err := h.k8sClient.ListLisstoVariables(c.Request().Context(), sharedNS)
+		if err != nil {
+			logging.Logger.Warn("Failed to list shared variables",
+				zap.String("namespace", sharedNS),
+				zap.Error(err))
+			continue
+		}
+		for _, v := range sharedList.Items {
 			variables = append(variables, VariableResponse{
 				ID:           fmt.Sprintf("%s/%s", v.Namespace, v.Name),
 				Name:         v.Name,
@@ -305,9 +332,8 @@ func (h *Handler) GetVariable(c echo.Context) error {
 		zap.String("namespace", namespace))
 
 	// Check if user can access this namespace
-	globalNS := h.nsManager.GetGlobalNamespace()
 	userNS := h.nsManager.GetDeveloperNamespace(user.Name)
-	if namespace != userNS && namespace != globalNS {
+	if namespace != userNS && !h.authorizer.CanReadShared(user.Role, namespace) {
 		return c.String(403, "Cannot access variables in other namespaces")
 	}
 
@@ -340,6 +366,9 @@ func (h *Handler) UpdateVariable(c echo.Context) error {
 		logging.Logger.Error("Request validation failed", zap.Error(err))
 		return c.String(400, err.Error())
 	}
+	if rejected, err := common.RejectInvalidValues(c, req.Data, h.maxValueLength, req.Validations); rejected {
+		return err
+	}
 
 	// Get scope from query params to determine namespace
 	scope := c.QueryParam("scope")
@@ -366,6 +395,10 @@ func (h *Handler) UpdateVariable(c echo.Context) error {
 		return c.String(403, fmt.Sprintf("Permission denied: %s", perm.Reason))
 	}
 
+	if rejected, err := common.RejectIfFrozen(c, h.nsManager, h.k8sClient, namespace, user.Name, "PUT /variables/:id"); rejected {
+		return err
+	}
+
 	logging.Logger.Info("Variable update request",
 		zap.String("user", user.Name),
 		zap.String("id", id),
@@ -382,6 +415,11 @@ func (h *Handler) UpdateVariable(c echo.Context) error {
 		return c.String(404, fmt.Sprintf("Variable '%s' not found", name))
 	}
 
+	// secretref:// values must point at an existing secret key
+	if rejected, err := common.RejectDanglingSecretRefs(c, h.k8sClient, namespace, variable.Spec.Env, req.Data); rejected {
+		return err
+	}
+
 	// Update data
 	variable.Spec.Data = req.Data
 
@@ -447,6 +485,10 @@ func (h *Handler) DeleteVariable(c echo.Context) error {
 		return c.String(403, fmt.Sprintf("Permission denied: %s", perm.Reason))
 	}
 
+	if rejected, err := common.RejectIfFrozen(c, h.nsManager, h.k8sClient, namespace, user.Name, "DELETE /variables/:id"); rejected {
+		return err
+	}
+
 	logging.Logger.Info("Variable delete request",
 		zap.String("user", user.Name),
 		zap.String("id", id),
@@ -0,0 +1,80 @@
+package variable_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/variable"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/k8s"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+var _ = Describe("GetVariables", func() {
+	var (
+		authorizer *authz.Authorizer
+		handler    *variable.Handler
+	)
+
+	BeforeEach(func() {
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		nsManager := authz.NewNamespaceManager(cfg)
+		authorizer = authz.NewAuthorizer(nsManager)
+
+		variables := []*envv1alpha1.LisstoVariable{
+			{ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "lissto-global"}, Spec: envv1alpha1.LisstoVariableSpec{Scope: "global"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "team", Namespace: "team-shared"}, Spec: envv1alpha1.LisstoVariableSpec{Scope: "env", Env: "dev"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "own", Namespace: "dev-alice"}, Spec: envv1alpha1.LisstoVariableSpec{Scope: "env", Env: "dev"}},
+		}
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		builder := fake.NewClientBuilder().WithScheme(scheme)
+		for _, v := range variables {
+			builder = builder.WithObjects(v)
+		}
+		handler = variable.NewHandler(k8s.NewClientFromClient(builder.Build(), scheme), authorizer, nsManager, cfg, config.DefaultSettings())
+	})
+
+	listIDs := func() []string {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/variables", nil), rec)
+		c.Set("user", &middleware.User{Name: "alice", Role: authz.User})
+		Expect(handler.GetVariables(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+
+		var resp []variable.VariableResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		ids := make([]string, 0, len(resp))
+		for _, v := range resp {
+			ids = append(ids, v.ID)
+		}
+		return ids
+	}
+
+	It("should include global variables by default", func() {
+		Expect(listIDs()).To(ConsistOf("dev-alice/own", "lissto-global/shared"))
+	})
+
+	It("should leave out global variables when global read is disabled", func() {
+		authorizer.SetRoleVisibility(authz.User, authz.RoleVisibility{GlobalRead: false})
+		Expect(listIDs()).To(ConsistOf("dev-alice/own"))
+	})
+
+	It("should include the role's extra read namespaces", func() {
+		authorizer.SetRoleVisibility(authz.User, authz.RoleVisibility{GlobalRead: false, ReadNamespaces: []string{"team-shared"}})
+		Expect(listIDs()).To(ConsistOf("dev-alice/own", "team-shared/team"))
+	})
+})
@@ -0,0 +1,66 @@
+package variable_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/variable"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/k8s"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+var _ = Describe("CreateVariable representation", func() {
+	create := func(target string) *httptest.ResponseRecorder {
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		nsManager := authz.NewNamespaceManager(cfg)
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		k8sClient := k8s.NewClientFromClient(fake.NewClientBuilder().WithScheme(scheme).Build(), scheme)
+		handler := variable.NewHandler(k8sClient, authz.NewAuthorizer(nsManager), nsManager, cfg, config.DefaultSettings())
+
+		e := echo.New()
+		e.Validator = &testValidator{validator: validator.New()}
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(`{"name":"app","env":"dev","data":{"HOST":"db"}}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user", &middleware.User{Name: "alice", Role: authz.User})
+		Expect(handler.CreateVariable(c)).To(Succeed())
+		return rec
+	}
+
+	It("should keep the legacy response by default", func() {
+		rec := create("/variables")
+
+		Expect(rec.Code).To(Equal(201))
+		var response variable.VariableResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+		Expect(response.ID).To(Equal("dev-alice/app"))
+		Expect(response.KeyUpdatedAt).To(BeEmpty())
+	})
+
+	It("should return the stored variable with ?return=representation", func() {
+		rec := create("/variables?return=representation")
+
+		Expect(rec.Code).To(Equal(201))
+		Expect(rec.Header().Get("Preference-Applied")).To(Equal("return=representation"))
+		var response variable.VariableResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+		Expect(response.ID).To(Equal("dev-alice/app"))
+		Expect(response.Data).To(Equal(map[string]string{"HOST": "db"}))
+		Expect(response.KeyUpdatedAt).To(HaveKey("HOST"))
+	})
+})
@@ -9,6 +9,7 @@ func RegisterRoutes(g *echo.Group, handler *Handler) {
 	g.POST("", handler.CreateVariable)
 	g.GET("", handler.GetVariables)
 	g.GET("/:id", handler.GetVariable)
+	g.GET("/:id/scope-info", handler.GetVariableScopeInfo)
 	g.PUT("/:id", handler.UpdateVariable)
 	g.DELETE("/:id", handler.DeleteVariable)
 }
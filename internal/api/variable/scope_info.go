@@ -0,0 +1,91 @@
+package variable
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/logging"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+// GetVariableScopeInfo handles GET /variables/:id/scope-info
+// Reports where the variable applies and which same-named variables in the user's
+// namespace or the global namespace override it or are overridden by it
+func (h *Handler) GetVariableScopeInfo(c echo.Context) error {
+	user, _ := middleware.GetUserFromContext(c)
+	id := c.Param("id")
+
+	// Resolve the variable like GetVariable does
+	scope := c.QueryParam("scope")
+	if scope == "" {
+		scope = "env" // default
+	}
+	namespace, err := h.authorizer.ResolveNamespaceForScope(user.Role, user.Name, scope)
+	if err != nil {
+		return c.String(400, err.Error())
+	}
+	_, name, err := parseVariableID(id, namespace)
+	if err != nil {
+		return c.String(400, err.Error())
+	}
+
+	globalNS := h.nsManager.GetGlobalNamespace()
+	userNS := h.nsManager.GetDeveloperNamespace(user.Name)
+	if namespace != userNS && !h.authorizer.CanReadShared(user.Role, namespace) {
+		return c.String(403, "Cannot access variables in other namespaces")
+	}
+
+	ctx := c.Request().Context()
+	variable, err := h.k8sClient.GetLisstoVariable(ctx, namespace, name)
+	if err != nil {
+		return c.String(404, fmt.Sprintf("Variable '%s' not found", name))
+	}
+
+	// Same-named variables can only come from the namespaces the controller merges from
+	// Global ones are left out when the role can't read global
+	namespaces := []string{userNS}
+	if globalNS != userNS && h.authorizer.CanReadGlobal(user.Role) {
+		namespaces = append(namespaces, globalNS)
+	}
+	var sameNamed []common.ScopedConfig
+	for _, ns := range namespaces {
+		list, err := h.k8sClient.ListLisstoVariables(ctx, ns)
+		if err != nil {
+			logging.Logger.Error("Failed to list variables",
+				zap.String("namespace", ns),
+				zap.Error(err))
+			return c.String(500, "Failed to list variables")
+		}
+		for i := range list.Items {
+			if list.Items[i].Name == name {
+				sameNamed = append(sameNamed, scopedVariable(&list.Items[i]))
+			}
+		}
+	}
+
+	return c.JSON(200, common.ComputeScopeInfo(scopedVariable(variable), sameNamed))
+}
+
+// scopedVariable describes a variable for scope computation
+func scopedVariable(variable *envv1alpha1.LisstoVariable) common.ScopedConfig {
+	keys := make([]string, 0, len(variable.Spec.Data))
+	for key := range variable.Spec.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	response := extractVariableResponse(variable)
+	return common.ScopedConfig{
+		ID:         response.ID,
+		Name:       response.Name,
+		Scope:      response.Scope,
+		Env:        response.Env,
+		Repository: response.Repository,
+		Keys:       keys,
+	}
+}
@@ -0,0 +1,103 @@
+package variable_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/variable"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/k8s"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+var _ = Describe("GetVariableScopeInfo", func() {
+	var handler *variable.Handler
+
+	BeforeEach(func() {
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		nsManager := authz.NewNamespaceManager(cfg)
+
+		globalVar := &envv1alpha1.LisstoVariable{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "lissto-global"},
+			Spec: envv1alpha1.LisstoVariableSpec{
+				Scope: "global",
+				Data:  map[string]string{"DB_HOST": "db.shared", "DB_PORT": "5432"},
+			},
+		}
+		envVar := &envv1alpha1.LisstoVariable{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "dev-alice"},
+			Spec: envv1alpha1.LisstoVariableSpec{
+				Scope: "env",
+				Env:   "dev",
+				Data:  map[string]string{"DB_HOST": "localhost"},
+			},
+		}
+		adminEnvVar := envVar.DeepCopy()
+		adminEnvVar.Namespace = "dev-root"
+		otherVar := &envv1alpha1.LisstoVariable{
+			ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: "dev-alice"},
+			Spec:       envv1alpha1.LisstoVariableSpec{Scope: "env", Env: "dev", Data: map[string]string{"DB_HOST": "x"}},
+		}
+
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(globalVar, envVar, adminEnvVar, otherVar).Build()
+		handler = variable.NewHandler(k8s.NewClientFromClient(fakeClient, scheme), authz.NewAuthorizer(nsManager), nsManager, cfg, config.DefaultSettings())
+	})
+
+	getScopeInfo := func(target string, user *middleware.User) (*httptest.ResponseRecorder, common.ScopeInfoResponse) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("db")
+		c.Set("user", user)
+		Expect(handler.GetVariableScopeInfo(c)).To(Succeed())
+
+		var info common.ScopeInfoResponse
+		if rec.Code == 200 {
+			Expect(json.Unmarshal(rec.Body.Bytes(), &info)).To(Succeed())
+		}
+		return rec, info
+	}
+
+	It("should report that an env-scoped variable shadows the global one", func() {
+		rec, info := getScopeInfo("/variables/db/scope-info", &middleware.User{Name: "alice", Role: authz.User})
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+		Expect(info.Scope).To(Equal("env"))
+		Expect(info.AppliesTo).To(Equal("stacks in env dev"))
+		Expect(info.Shadowed).To(BeFalse())
+		Expect(info.Shadows).To(ConsistOf(common.ScopeOverlap{
+			ID:    "lissto-global/db",
+			Scope: "global",
+			Keys:  []string{"DB_HOST"},
+		}))
+	})
+
+	It("should report the global variable as shadowed", func() {
+		rec, info := getScopeInfo("/variables/db/scope-info?scope=global", &middleware.User{Name: "root", Role: authz.Admin})
+		Expect(rec.Code).To(Equal(200), rec.Body.String())
+		Expect(info.Scope).To(Equal("global"))
+		Expect(info.Shadowed).To(BeTrue())
+		Expect(info.ShadowedBy).To(ConsistOf(common.ScopeOverlap{
+			ID:    "dev-root/db",
+			Scope: "env",
+			Env:   "dev",
+			Keys:  []string{"DB_HOST"},
+		}))
+	})
+})
@@ -0,0 +1,92 @@
+package variable_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/variable"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/k8s"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+var _ = Describe("Variable secret references", func() {
+	var (
+		handler   *variable.Handler
+		k8sClient *k8s.Client
+	)
+
+	BeforeEach(func() {
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		nsManager := authz.NewNamespaceManager(cfg)
+
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		secret := &envv1alpha1.LisstoSecret{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "dev-alice"},
+			Spec:       envv1alpha1.LisstoSecretSpec{Scope: "env", Env: "dev", Keys: []string{"PASSWORD"}},
+		}
+		k8sClient = k8s.NewClientFromClient(fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build(), scheme)
+		handler = variable.NewHandler(k8sClient, authz.NewAuthorizer(nsManager), nsManager, cfg, config.DefaultSettings())
+	})
+
+	createVariable := func(body string) *httptest.ResponseRecorder {
+		e := echo.New()
+		e.Validator = &testValidator{validator: validator.New()}
+		req := httptest.NewRequest(http.MethodPost, "/variables", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user", &middleware.User{Name: "alice", Role: authz.User})
+		Expect(handler.CreateVariable(c)).To(Succeed())
+		return rec
+	}
+
+	It("should store references to existing secret keys as written", func() {
+		rec := createVariable(`{"name": "app", "env": "dev", "data": {"DB_PASSWORD": "secretref://db/PASSWORD"}}`)
+		Expect(rec.Code).To(Equal(201), rec.Body.String())
+
+		stored, err := k8sClient.GetLisstoVariable(context.Background(), "dev-alice", "app")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Spec.Data).To(Equal(map[string]string{"DB_PASSWORD": "secretref://db/PASSWORD"}))
+	})
+
+	It("should reject dangling and malformed references", func() {
+		rec := createVariable(`{"name": "app", "env": "dev", "data": {
+			"A": "secretref://missing/KEY", "B": "secretref://db/USER", "C": "secretref://db", "D": "plain"
+		}}`)
+		Expect(rec.Code).To(Equal(400))
+
+		var resp common.ValueValidationResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.Violations).To(HaveLen(3))
+		for i, key := range []string{"A", "B", "C"} {
+			Expect(resp.Violations[i]).To(HaveField("Key", key))
+			Expect(resp.Violations[i]).To(HaveField("Rule", "secret_ref"))
+		}
+
+		_, err := k8sClient.GetLisstoVariable(context.Background(), "dev-alice", "app")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject references to another env's secrets", func() {
+		rec := createVariable(`{"name": "app", "env": "staging", "data": {"DB_PASSWORD": "secretref://db/PASSWORD"}}`)
+		Expect(rec.Code).To(Equal(400))
+	})
+})
@@ -0,0 +1,94 @@
+package variable_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/internal/api/variable"
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/k8s"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+type testValidator struct {
+	validator *validator.Validate
+}
+
+func (v *testValidator) Validate(i interface{}) error {
+	return v.validator.Struct(i)
+}
+
+var _ = Describe("Variable value validation", func() {
+	var (
+		handler   *variable.Handler
+		k8sClient *k8s.Client
+	)
+
+	BeforeEach(func() {
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		nsManager := authz.NewNamespaceManager(cfg)
+
+		settings := config.DefaultSettings()
+		settings.Values.MaxLength = 32
+
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		k8sClient = k8s.NewClientFromClient(fake.NewClientBuilder().WithScheme(scheme).Build(), scheme)
+		handler = variable.NewHandler(k8sClient, authz.NewAuthorizer(nsManager), nsManager, cfg, settings)
+	})
+
+	createVariable := func(body string) *httptest.ResponseRecorder {
+		e := echo.New()
+		e.Validator = &testValidator{validator: validator.New()}
+		req := httptest.NewRequest(http.MethodPost, "/variables", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user", &middleware.User{Name: "alice", Role: authz.User})
+		Expect(handler.CreateVariable(c)).To(Succeed())
+		return rec
+	}
+
+	It("should reject violations with the offending keys", func() {
+		rec := createVariable(`{
+			"name": "app", "env": "dev",
+			"data": {"PORT": "80a", "BLOB": "0123456789012345678901234567890123456789", "HOST": "db"},
+			"validations": {"PORT": {"pattern": "[0-9]+"}}
+		}`)
+		Expect(rec.Code).To(Equal(400))
+
+		var resp common.ValueValidationResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.Violations).To(HaveLen(2))
+		Expect(resp.Violations[0]).To(HaveField("Key", "BLOB"))
+		Expect(resp.Violations[0]).To(HaveField("Rule", "max_length"))
+		Expect(resp.Violations[1]).To(HaveField("Key", "PORT"))
+		Expect(resp.Violations[1]).To(HaveField("Rule", "pattern"))
+
+		_, err := k8sClient.GetLisstoVariable(context.Background(), "dev-alice", "app")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should create variables whose values pass validation", func() {
+		rec := createVariable(`{
+			"name": "app", "env": "dev",
+			"data": {"PORT": "8080", "CONFIG": "{\"debug\":true}"},
+			"validations": {"PORT": {"pattern": "[0-9]+"}, "CONFIG": {"format": "json"}}
+		}`)
+		Expect(rec.Code).To(Equal(201), rec.Body.String())
+	})
+})
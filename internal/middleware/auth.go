@@ -1,6 +1,9 @@
 package middleware
 
 import (
+	"strings"
+	"time"
+
 	"github.com/labstack/echo/v4"
 	"github.com/lissto-dev/api/pkg/authz"
 	"github.com/lissto-dev/api/pkg/config"
@@ -25,6 +28,115 @@ type User struct {
 	Role        authz.Role `json:"role"`
 	Email       string     `json:"email"`
 	SlackUserID string     `json:"slack_user_id,omitempty"`
+	Scopes      []string   `json:"scopes,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// HasScope reports whether the user's key may access the resource type
+// Keys without scopes may access every resource type
+func (u *User) HasScope(resourceType authz.ResourceType) bool {
+	if len(u.Scopes) == 0 {
+		return true
+	}
+	for _, scope := range u.Scopes {
+		if scope == string(resourceType) {
+			return true
+		}
+	}
+	return false
+}
+
+// routeScopes maps each route under /api/v1 (method and route pattern) to the resource types a scoped key needs
+// Routes with no entry (admin, internal) are closed to scoped keys; routes needing none are open to every key
+var routeScopes = map[string][]authz.ResourceType{
+	"GET /user":           nil,
+	"GET /user/me":        nil,
+	"GET /auth/whoami":    nil,
+	"POST /prepare":       {authz.ResourceStack},
+	"POST /prepare/plan":  {authz.ResourceStack},
+	"POST /prepare/batch": {authz.ResourceStack},
+	"GET /prepare/diff":   {authz.ResourceStack},
+
+	"GET /stacks":                                 {authz.ResourceStack},
+	"GET /stacks/:id":                             {authz.ResourceStack},
+	"GET /stacks/:id/conditions":                  {authz.ResourceStack},
+	"GET /stacks/:id/gitops-bundle":               {authz.ResourceStack},
+	"GET /stacks/:id/watch":                       {authz.ResourceStack},
+	"POST /stacks":                                {authz.ResourceStack},
+	"PUT /stacks/:id":                             {authz.ResourceStack},
+	"DELETE /stacks/:id":                          {authz.ResourceStack},
+	"PUT /stacks/:id/protection":                  {authz.ResourceStack},
+	"POST /stacks/:id/exec":                       {authz.ResourceStack},
+	"POST /stacks/:id/services/:service/recreate": {authz.ResourceStack},
+	"POST /stacks/:id/repair":                     {authz.ResourceStack},
+	"POST /stacks/:id/clone":                      {authz.ResourceStack},
+	"POST /stacks/:id/prepull":                    {authz.ResourceStack},
+	"GET /stacks/:id/prepull":                     {authz.ResourceStack},
+
+	"GET /blueprints":                                  {authz.ResourceBlueprint},
+	"GET /blueprints/:id":                              {authz.ResourceBlueprint},
+	"GET /blueprints/:id/registries":                   {authz.ResourceBlueprint},
+	"GET /blueprints/:id/services/:service/resolve":    {authz.ResourceBlueprint},
+	"GET /blueprints/:id/services/:service/image-info": {authz.ResourceBlueprint},
+	"POST /blueprints":                                 {authz.ResourceBlueprint},
+	"DELETE /blueprints/:id":                           {authz.ResourceBlueprint},
+
+	"POST /envs":               {authz.ResourceEnv},
+	"GET /envs":                {authz.ResourceEnv},
+	"GET /envs/:id":            {authz.ResourceEnv},
+	"GET /envs/:id/dependents": {authz.ResourceEnv},
+	"DELETE /envs/:id":         {authz.ResourceEnv},
+	// Refreshing an env's images rewrites its stacks
+	"POST /envs/:id/refresh-images": {authz.ResourceStack},
+
+	// Lifecycles run tasks of stacks
+	"GET /lifecycles/:id":         {authz.ResourceStack},
+	"POST /lifecycles/:id/pause":  {authz.ResourceStack},
+	"POST /lifecycles/:id/resume": {authz.ResourceStack},
+
+	"POST /variables":               {authz.ResourceVariable},
+	"GET /variables":                {authz.ResourceVariable},
+	"GET /variables/:id":            {authz.ResourceVariable},
+	"GET /variables/:id/scope-info": {authz.ResourceVariable},
+	"PUT /variables/:id":            {authz.ResourceVariable},
+	"DELETE /variables/:id":         {authz.ResourceVariable},
+
+	"POST /secrets":               {authz.ResourceSecret},
+	"GET /secrets":                {authz.ResourceSecret},
+	"GET /secrets/:id":            {authz.ResourceSecret},
+	"GET /secrets/:id/scope-info": {authz.ResourceSecret},
+	"PUT /secrets/:id":            {authz.ResourceSecret},
+	"DELETE /secrets/:id":         {authz.ResourceSecret},
+}
+
+// cascadeScopes are needed by DELETE /envs/:id?cascade=true, which also deletes the env's stacks, secrets and variables
+var cascadeScopes = []authz.ResourceType{authz.ResourceEnv, authz.ResourceStack, authz.ResourceSecret, authz.ResourceVariable}
+
+// requiredScopes returns the resource types a scoped key needs for the matched route, false if it may not call it
+func requiredScopes(c echo.Context) ([]authz.ResourceType, bool) {
+	route := c.Request().Method + " " + strings.TrimPrefix(c.Path(), "/api/v1")
+	if route == "DELETE /envs/:id" && c.QueryParam("cascade") == "true" {
+		return cascadeScopes, true
+	}
+	scopes, ok := routeScopes[route]
+	return scopes, ok
+}
+
+// scopeAllows reports whether a scoped user may call the matched route
+func scopeAllows(user *User, c echo.Context) bool {
+	if len(user.Scopes) == 0 {
+		return true
+	}
+	scopes, ok := requiredScopes(c)
+	if !ok {
+		return false
+	}
+	for _, resourceType := range scopes {
+		if !user.HasScope(resourceType) {
+			return false
+		}
+	}
+	return true
 }
 
 // APIKeyMiddleware validates API keys and creates user context
@@ -47,6 +159,12 @@ func APIKeyMiddleware(apiKeys []config.APIKey, authorizer *authz.Authorizer) ech
 				return response.Unauthorized(c, "Invalid API key")
 			}
 
+			if keyData.Expired(time.Now()) {
+				endpoint := c.Request().Method + " " + c.Request().URL.Path
+				logging.LogDeniedWithIP("expired_api_key", keyData.Name, endpoint, c.RealIP())
+				return response.Unauthorized(c, "API key expired")
+			}
+
 			// Set user in context
 			user := &User{
 				ID:          keyData.Name,
@@ -54,7 +172,16 @@ func APIKeyMiddleware(apiKeys []config.APIKey, authorizer *authz.Authorizer) ech
 				Role:        authz.ParseRole(keyData.Role),
 				Email:       keyData.Name + "@lissto.dev",
 				SlackUserID: keyData.SlackUserID,
+				Scopes:      keyData.Scopes,
+				ExpiresAt:   keyData.ExpiresAt,
+			}
+
+			if !scopeAllows(user, c) {
+				endpoint := c.Request().Method + " " + c.Request().URL.Path
+				logging.LogDeniedWithIP("out_of_scope", user.Name, endpoint, c.RealIP())
+				return response.Forbidden(c, "API key is not scoped for this resource")
 			}
+
 			c.Set("user", user)
 			c.Set("authorizer", authorizer)
 
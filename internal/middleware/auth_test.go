@@ -0,0 +1,87 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/config"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+var _ = Describe("APIKeyMiddleware", func() {
+	var e *echo.Echo
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	keys := []config.APIKey{
+		{Name: "alice", Role: "user", APIKey: "user-alice"},
+		{Name: "old", Role: "user", APIKey: "user-old", ExpiresAt: &past},
+		{Name: "ci", Role: "deploy", APIKey: "deploy-ci", Scopes: []string{"stack"}, ExpiresAt: &future},
+		{Name: "envs", Role: "deploy", APIKey: "deploy-envs", Scopes: []string{"env"}},
+		{Name: "ops", Role: "deploy", APIKey: "deploy-ops", Scopes: []string{"env", "stack", "secret", "variable"}},
+	}
+
+	BeforeEach(func() {
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+
+		e = echo.New()
+		api := e.Group("/api/v1")
+		api.Use(middleware.APIKeyMiddleware(keys, authz.NewAuthorizer(authz.NewNamespaceManager(cfg))))
+		ok := func(c echo.Context) error { return c.NoContent(204) }
+		api.GET("/stacks", ok)
+		api.GET("/secrets", ok)
+		api.GET("/auth/whoami", ok)
+		api.GET("/admin/info", ok)
+		api.GET("/envs/:id", ok)
+		api.DELETE("/envs/:id", ok)
+		api.POST("/envs/:id/refresh-images", ok)
+	})
+
+	send := func(method, path, key string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	request := func(path, key string) int {
+		return send(http.MethodGet, path, key)
+	}
+
+	It("should accept keys without expiry or scopes", func() {
+		Expect(request("/api/v1/stacks", "user-alice")).To(Equal(204))
+		Expect(request("/api/v1/secrets", "user-alice")).To(Equal(204))
+	})
+
+	It("should reject expired keys", func() {
+		Expect(request("/api/v1/auth/whoami", "user-old")).To(Equal(401))
+	})
+
+	It("should limit scoped keys to their resources", func() {
+		Expect(request("/api/v1/stacks", "deploy-ci")).To(Equal(204))
+		Expect(request("/api/v1/auth/whoami", "deploy-ci")).To(Equal(204))
+		Expect(request("/api/v1/secrets", "deploy-ci")).To(Equal(403))
+		Expect(request("/api/v1/admin/info", "deploy-ci")).To(Equal(403))
+	})
+
+	It("should require the stack scope to refresh an env's images", func() {
+		Expect(request("/api/v1/envs/dev", "deploy-envs")).To(Equal(204))
+		Expect(send(http.MethodPost, "/api/v1/envs/dev/refresh-images", "deploy-envs")).To(Equal(403))
+		Expect(send(http.MethodPost, "/api/v1/envs/dev/refresh-images", "deploy-ci")).To(Equal(204))
+	})
+
+	It("should require every affected scope to cascade delete an env", func() {
+		Expect(send(http.MethodDelete, "/api/v1/envs/dev", "deploy-envs")).To(Equal(204))
+		Expect(send(http.MethodDelete, "/api/v1/envs/dev?cascade=true", "deploy-envs")).To(Equal(403))
+		Expect(send(http.MethodDelete, "/api/v1/envs/dev?cascade=true", "deploy-ops")).To(Equal(204))
+	})
+})
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/lissto-dev/api/pkg/logging"
+	"github.com/lissto-dev/api/pkg/response"
+)
+
+// RequireClientCertificate rejects requests without a client certificate verified against the mutual TLS CA bundle
+// With mutual TLS the listener only verifies certificates clients present, so routes needing one use this
+func RequireClientCertificate() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			state := c.Request().TLS
+			if state == nil || len(state.VerifiedChains) == 0 {
+				endpoint := c.Request().Method + " " + c.Request().URL.Path
+				logging.LogDeniedWithIP("missing_client_certificate", "", endpoint, c.RealIP())
+				return response.Unauthorized(c, "Client certificate required")
+			}
+			return next(c)
+		}
+	}
+}
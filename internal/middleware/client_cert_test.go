@@ -0,0 +1,49 @@
+package middleware_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/internal/middleware"
+)
+
+var _ = Describe("RequireClientCertificate", func() {
+	var e *echo.Echo
+
+	BeforeEach(func() {
+		e = echo.New()
+		ok := func(c echo.Context) error { return c.NoContent(204) }
+		e.GET("/health", ok)
+		api := e.Group("/api/v1")
+		api.Use(middleware.RequireClientCertificate())
+		api.GET("/stacks", ok)
+	})
+
+	request := func(path string, state *tls.ConnectionState) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.TLS = state
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	It("should reject API requests without a verified client certificate", func() {
+		Expect(request("/api/v1/stacks", nil)).To(Equal(401))
+		Expect(request("/api/v1/stacks", &tls.ConnectionState{})).To(Equal(401))
+	})
+
+	It("should accept API requests with a verified client certificate", func() {
+		state := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}
+		Expect(request("/api/v1/stacks", state)).To(Equal(204))
+	})
+
+	It("should leave routes outside the API open", func() {
+		Expect(request("/health", &tls.ConnectionState{})).To(Equal(204))
+	})
+})
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/lissto-dev/api/pkg/metrics"
+)
+
+// unmatchedRoute labels requests that did not match a registered route
+// Raw paths are never used as labels, so cardinality stays bounded by the route table
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware records request counts and latency per route pattern
+func MetricsMiddleware(m *metrics.Metrics) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+
+			route := c.Path()
+			if route == "" || isNotFound(err) {
+				route = unmatchedRoute
+			}
+			method := c.Request().Method
+
+			m.RequestsTotal.WithLabelValues(route, method, strconv.Itoa(responseStatus(c, err))).Inc()
+			m.RequestDuration.WithLabelValues(route, method).Observe(time.Since(start).Seconds())
+			return err
+		}
+	}
+}
+
+// responseStatus returns the status code that is (or will be) sent for the request
+// Errors are written by echo's error handler after the middleware returns
+func responseStatus(c echo.Context, err error) int {
+	if err != nil {
+		var httpErr *echo.HTTPError
+		if errors.As(err, &httpErr) {
+			return httpErr.Code
+		}
+		return http.StatusInternalServerError
+	}
+	return c.Response().Status
+}
+
+// isNotFound reports whether the router found no route for the request
+func isNotFound(err error) bool {
+	var httpErr *echo.HTTPError
+	return errors.As(err, &httpErr) && (httpErr.Code == http.StatusNotFound || httpErr.Code == http.StatusMethodNotAllowed)
+}
@@ -0,0 +1,96 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/internal/middleware"
+	"github.com/lissto-dev/api/pkg/metrics"
+)
+
+// fakeCounter returns fixed resource counts
+type fakeCounter struct {
+	stacks     int
+	blueprints int
+	err        error
+}
+
+func (f *fakeCounter) CountStacks(context.Context) (int, error)     { return f.stacks, f.err }
+func (f *fakeCounter) CountBlueprints(context.Context) (int, error) { return f.blueprints, f.err }
+
+var _ = Describe("MetricsMiddleware", func() {
+	var (
+		e *echo.Echo
+		m *metrics.Metrics
+	)
+
+	BeforeEach(func() {
+		m = metrics.New()
+		e = echo.New()
+		e.Use(middleware.MetricsMiddleware(m))
+		e.GET("/stacks/:id", func(c echo.Context) error {
+			if c.Param("id") == "missing" {
+				return c.String(404, "Stack not found")
+			}
+			return c.String(200, "ok")
+		})
+		e.POST("/stacks", func(c echo.Context) error {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid")
+		})
+		e.GET("/metrics", echo.WrapHandler(m.Handler()))
+	})
+
+	request := func(method, target string) int {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec.Code
+	}
+
+	scrape := func() string {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		Expect(rec.Code).To(Equal(200))
+		return rec.Body.String()
+	}
+
+	It("should count requests by route pattern, method and status", func() {
+		Expect(request(http.MethodGet, "/stacks/alice-1")).To(Equal(200))
+		Expect(request(http.MethodGet, "/stacks/bob-2")).To(Equal(200))
+		Expect(request(http.MethodGet, "/stacks/missing")).To(Equal(404))
+		Expect(request(http.MethodPost, "/stacks")).To(Equal(400))
+
+		body := scrape()
+		Expect(body).To(ContainSubstring(`http_requests_total{method="GET",route="/stacks/:id",status="200"} 2`))
+		Expect(body).To(ContainSubstring(`http_requests_total{method="GET",route="/stacks/:id",status="404"} 1`))
+		Expect(body).To(ContainSubstring(`http_requests_total{method="POST",route="/stacks",status="400"} 1`))
+		Expect(body).To(ContainSubstring(`http_request_duration_seconds_count{method="GET",route="/stacks/:id"} 3`))
+		Expect(body).NotTo(ContainSubstring("alice-1"))
+	})
+
+	It("should label unknown paths as unmatched", func() {
+		Expect(request(http.MethodGet, "/does/not/exist/123")).To(Equal(404))
+		Expect(request(http.MethodGet, "/another/unknown")).To(Equal(404))
+
+		body := scrape()
+		Expect(body).To(ContainSubstring(`http_requests_total{method="GET",route="unmatched",status="404"} 2`))
+		Expect(body).NotTo(ContainSubstring("/does/not/exist"))
+	})
+
+	It("should report resource gauges", func() {
+		m.RefreshResourceGauges(context.Background(), &fakeCounter{stacks: 7, blueprints: 3})
+
+		body := scrape()
+		Expect(body).To(ContainSubstring("lissto_stacks 7"))
+		Expect(body).To(ContainSubstring("lissto_blueprints 3"))
+
+		// A failed refresh keeps the last known values
+		m.RefreshResourceGauges(context.Background(), &fakeCounter{err: errors.New("api unavailable")})
+		Expect(scrape()).To(ContainSubstring("lissto_stacks 7"))
+	})
+})
@@ -1,15 +1,21 @@
 package server
 
 import (
+	"context"
+	"net/http"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
 
+	"github.com/lissto-dev/api/internal/api/admin"
 	"github.com/lissto-dev/api/internal/api/apikey"
 	"github.com/lissto-dev/api/internal/api/blueprint"
+	"github.com/lissto-dev/api/internal/api/common"
 	"github.com/lissto-dev/api/internal/api/env"
+	"github.com/lissto-dev/api/internal/api/lifecycle"
 	"github.com/lissto-dev/api/internal/api/prepare"
 	"github.com/lissto-dev/api/internal/api/secret"
 	"github.com/lissto-dev/api/internal/api/stack"
@@ -19,8 +25,12 @@ import (
 	"github.com/lissto-dev/api/pkg/authz"
 	"github.com/lissto-dev/api/pkg/cache"
 	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/image"
 	"github.com/lissto-dev/api/pkg/k8s"
 	"github.com/lissto-dev/api/pkg/logging"
+	"github.com/lissto-dev/api/pkg/metrics"
+	"github.com/lissto-dev/api/pkg/notify"
+	pkgServer "github.com/lissto-dev/api/pkg/server"
 	controllerconfig "github.com/lissto-dev/controller/pkg/config"
 )
 
@@ -33,6 +43,8 @@ type Server struct {
 	k8sClient  *k8s.Client
 	instanceID string
 	publicURL  string
+	tls        config.TLSSettings
+	webhooks   *notify.WebhookNotifier // nil when no webhooks are configured
 }
 
 // GetAPIKeys returns a copy of the current API keys
@@ -58,6 +70,7 @@ func New(
 	e *echo.Echo,
 	apiKeys []config.APIKey,
 	cfg *controllerconfig.Config,
+	settings *config.Settings,
 	k8sClient *k8s.Client,
 	authorizer *authz.Authorizer,
 	nsManager *authz.NamespaceManager,
@@ -73,19 +86,60 @@ func New(
 		k8sClient:  k8sClient,
 		instanceID: instanceID,
 		publicURL:  publicURL,
+		tls:        settings.TLS,
 	}
 
+	// Record request metrics per route pattern and keep resource gauges fresh
+	serverMetrics := metrics.New()
+	e.Use(middleware.MetricsMiddleware(serverMetrics))
+	serverMetrics.StartResourceGauges(context.Background(), &resourceCounter{k8sClient: k8sClient}, resourceGaugeInterval)
+	image.DefaultRegistryBreaker.SetObserver(serverMetrics)
+
+	// Configure label/annotation keys hidden from detailed responses
+	common.SetStrippedMetadataPrefixes(settings.Detailed.StripPrefixes)
+
 	// Create image cache (file-based in dev via IMAGE_CACHE_FILE_PATH, memory-based otherwise)
-	imageCache := cache.NewImageCache()
+	imageCache := cache.NewImageCache(settings.Cache.EntryLimit(), settings.Cache.MemoryLimit())
+	if reporter, ok := imageCache.(metrics.CacheUsageReporter); ok {
+		serverMetrics.ObserveCache(reporter)
+	}
+
+	// Create exec executor only when the feature is enabled
+	var executor k8s.Executor
+	if settings.Exec.Enabled {
+		spdyExecutor, err := k8s.NewSPDYExecutor(k8sClient.RESTConfig())
+		if err != nil {
+			logging.Logger.Error("Failed to create exec executor, exec stays disabled", zap.Error(err))
+		} else {
+			executor = spdyExecutor
+			logging.Logger.Info("Stack exec enabled")
+		}
+	}
+
+	// Send stack lifecycle events to configured webhooks
+	var notifier notify.Notifier = notify.NopNotifier{}
+	if len(settings.Webhooks) > 0 {
+		srv.webhooks = notify.NewWebhookNotifier(settings.Webhooks)
+		notifier = srv.webhooks
+		logging.Logger.Info("Stack webhooks enabled", zap.Int("targets", len(settings.Webhooks)))
+	}
 
 	// Create handlers with dependencies
-	stackHandler := stack.NewHandler(k8sClient, authorizer, nsManager, cfg, imageCache)
-	blueprintHandler := blueprint.NewHandler(k8sClient, authorizer, nsManager, cfg)
-	envHandler := env.NewHandler(k8sClient, authorizer, nsManager, cfg)
-	userHandler := user.NewHandler()
-	prepareHandler := prepare.NewHandler(k8sClient, authorizer, nsManager, cfg, imageCache)
-	variableHandler := variable.NewHandler(k8sClient, authorizer, nsManager, cfg)
-	secretHandler := secret.NewHandler(k8sClient, authorizer, nsManager, cfg)
+	// Image refresh must see re-published tags, so its resolver skips the digest cache
+	refreshResolver := prepare.NewImageResolver(cfg, settings, nil)
+	stackHandler := stack.NewHandler(k8sClient, authorizer, nsManager, cfg, settings, imageCache, executor, notifier, refreshResolver, instanceID)
+	blueprintHandler := blueprint.NewHandler(k8sClient, authorizer, nsManager, cfg, prepare.NewImageResolver(cfg, settings, imageCache))
+	envHandler := env.NewHandler(k8sClient, authorizer, nsManager, cfg, notifier, stackHandler.Locker())
+	lifecycleHandler := lifecycle.NewHandler(k8sClient, authorizer, nsManager)
+	userHandler := user.NewHandler(authorizer)
+	prepareHandler := prepare.NewHandler(k8sClient, authorizer, nsManager, cfg, settings, imageCache)
+	variableHandler := variable.NewHandler(k8sClient, authorizer, nsManager, cfg, settings)
+	secretHandler := secret.NewHandler(k8sClient, authorizer, nsManager, cfg, settings)
+	adminHandler := admin.NewHandler(k8sClient, nsManager, cfg, settings, admin.RuntimeInfo{
+		InstanceID: instanceID,
+		PublicURL:  publicURL,
+		Cache:      imageCache,
+	})
 
 	// Create API key handler with updater function
 	// API keys are stored in the same namespace where API is running
@@ -97,6 +151,10 @@ func New(
 	// API routes with authentication
 	// Use function-based middleware to get current keys dynamically
 	api := e.Group("/api/v1")
+	if settings.TLS.ClientCAFile != "" {
+		// The listener accepts connections without a client certificate for /health and /metrics
+		api.Use(middleware.RequireClientCertificate())
+	}
 	api.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			// Get current API keys on each request
@@ -110,21 +168,54 @@ func New(
 	stack.RegisterRoutes(api.Group("/stacks"), stackHandler)
 	blueprint.RegisterRoutes(api.Group("/blueprints"), blueprintHandler)
 	env.RegisterRoutes(api.Group("/envs"), envHandler)
+	stack.RegisterEnvRoutes(api.Group("/envs"), stackHandler)
+	lifecycle.RegisterRoutes(api.Group("/lifecycles"), lifecycleHandler)
 	user.RegisterRoutes(api.Group("/user"), userHandler)
+	user.RegisterAuthRoutes(api.Group("/auth"), userHandler)
 	prepare.RegisterRoutes(api.Group(""), prepareHandler)
 	variable.RegisterRoutes(api.Group("/variables"), variableHandler)
 	secret.RegisterRoutes(api.Group("/secrets"), secretHandler)
 
 	// Register internal admin routes (apikey routes register themselves)
 	apikey.RegisterRoutes(api, apiKeyHandler)
+	admin.RegisterRoutes(api.Group("/admin"), adminHandler)
 
 	// Health check (no auth required)
 	// Supports ?info=true to return API information (public URL and API ID)
 	e.GET("/health", srv.handleHealth)
 
+	// Prometheus metrics (no auth required)
+	e.GET("/metrics", echo.WrapHandler(serverMetrics.Handler()))
+
 	return srv
 }
 
+// resourceGaugeInterval is how often the stack and blueprint gauges are refreshed
+const resourceGaugeInterval = time.Minute
+
+// resourceCounter counts resources for the metrics gauges
+type resourceCounter struct {
+	k8sClient *k8s.Client
+}
+
+// CountStacks counts stacks across all namespaces
+func (r *resourceCounter) CountStacks(ctx context.Context) (int, error) {
+	stacks, err := r.k8sClient.ListStacks(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	return len(stacks.Items), nil
+}
+
+// CountBlueprints counts blueprints across all namespaces
+func (r *resourceCounter) CountBlueprints(ctx context.Context) (int, error) {
+	blueprints, err := r.k8sClient.ListBlueprints(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	return len(blueprints.Items), nil
+}
+
 // handleHealth handles the health check endpoint
 // Returns 200 OK for normal health checks
 // Returns JSON with API info when ?info=true is specified
@@ -149,6 +240,40 @@ func (s *Server) Start() error {
 		port = "8080"
 	}
 	port = ":" + port
-	logging.Logger.Info("Starting server", zap.String("port", port))
-	return s.echo.Start(port)
+
+	// Serve TLS natively when a certificate is configured, otherwise plain HTTP
+	tlsConfig, err := pkgServer.TLSConfig(s.tls)
+	if err != nil {
+		return err
+	}
+	if tlsConfig == nil {
+		logging.Logger.Info("Starting server", zap.String("port", port))
+		return s.echo.Start(port)
+	}
+
+	logging.Logger.Info("Starting server with TLS",
+		zap.String("port", port),
+		zap.Bool("mutual_tls", tlsConfig.ClientCAs != nil))
+	return s.echo.StartServer(&http.Server{Addr: port, TLSConfig: tlsConfig})
 }
+
+// Shutdown stops accepting requests, waits for in-flight ones and then for pending webhook deliveries,
+// all within the context's deadline
+func (s *Server) Shutdown(ctx context.Context) error {
+	err := s.echo.Shutdown(ctx)
+	if s.webhooks == nil {
+		return err
+	}
+
+	timeout := webhookDrainTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	if !s.webhooks.WaitTimeout(timeout) {
+		logging.Logger.Error("Webhook deliveries still pending at shutdown were dropped", zap.Duration("waited", timeout))
+	}
+	return err
+}
+
+// webhookDrainTimeout bounds the wait for webhook deliveries when shutting down without a deadline
+const webhookDrainTimeout = 30 * time.Second
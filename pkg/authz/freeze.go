@@ -0,0 +1,47 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// FrozenAnnotation marks a frozen namespace; the value is the time it was frozen (RFC 3339)
+	FrozenAnnotation = "lissto.dev/frozen"
+	// FrozenByAnnotation holds the admin who froze the namespace
+	FrozenByAnnotation = "lissto.dev/frozen-by"
+	// FrozenReasonAnnotation holds the optional reason given when freezing
+	FrozenReasonAnnotation = "lissto.dev/frozen-reason"
+)
+
+// ErrNamespaceFrozen is returned for mutations in a frozen namespace
+var ErrNamespaceFrozen = errors.New("namespace is frozen")
+
+// NamespaceGetter reads namespaces (implemented by k8s.Client)
+type NamespaceGetter interface {
+	GetNamespace(ctx context.Context, name string) (*corev1.Namespace, error)
+}
+
+// CheckNotFrozen returns ErrNamespaceFrozen if mutations in ns are blocked by an admin freeze
+// A namespace that does not exist yet is not frozen
+func (nm *NamespaceManager) CheckNotFrozen(ctx context.Context, getter NamespaceGetter, ns string) error {
+	namespace, err := getter.GetNamespace(ctx, ns)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get namespace %s: %w", ns, err)
+	}
+
+	if _, frozen := namespace.Annotations[FrozenAnnotation]; !frozen {
+		return nil
+	}
+	if reason := namespace.Annotations[FrozenReasonAnnotation]; reason != "" {
+		return fmt.Errorf("%w: %s", ErrNamespaceFrozen, reason)
+	}
+	return ErrNamespaceFrozen
+}
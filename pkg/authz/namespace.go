@@ -39,3 +39,44 @@ func (nm *NamespaceManager) GetOwnerFromNamespace(ns string) (string, error) {
 func (nm *NamespaceManager) IsGlobalBranch(repository, branch string) bool {
 	return nm.config.IsGlobalBranch(repository, branch)
 }
+
+const (
+	// NamespaceOwnerLabel holds the developer owning a namespace
+	NamespaceOwnerLabel = "lissto.dev/owner"
+	// NamespaceScopeLabel holds the namespace scope ("global" or "developer")
+	NamespaceScopeLabel = "lissto.dev/scope"
+)
+
+// NamespaceLabels returns the standard lissto labels describing a namespace
+func (nm *NamespaceManager) NamespaceLabels(ns string) map[string]string {
+	labels := map[string]string{}
+	if nm.IsGlobalNamespace(ns) {
+		labels[NamespaceScopeLabel] = "global"
+		return labels
+	}
+	if owner, err := nm.Manager.GetOwnerFromNamespace(ns); err == nil {
+		labels[NamespaceScopeLabel] = "developer"
+		labels[NamespaceOwnerLabel] = owner
+	}
+	return labels
+}
+
+// NamespaceMetadata returns the labels and annotations EnsureNamespace applies to a namespace:
+// configured defaults plus the standard lissto labels, which take precedence
+func (nm *NamespaceManager) NamespaceMetadata(defaultLabels, defaultAnnotations map[string]string) func(ns string) (map[string]string, map[string]string) {
+	return func(ns string) (map[string]string, map[string]string) {
+		labels := make(map[string]string, len(defaultLabels))
+		for k, v := range defaultLabels {
+			labels[k] = v
+		}
+		for k, v := range nm.NamespaceLabels(ns) {
+			labels[k] = v
+		}
+
+		annotations := make(map[string]string, len(defaultAnnotations))
+		for k, v := range defaultAnnotations {
+			annotations[k] = v
+		}
+		return labels, annotations
+	}
+}
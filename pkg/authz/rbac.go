@@ -26,6 +26,26 @@ const (
 	ResourceSecret    ResourceType = "secret"
 )
 
+// Actions returns all actions in a stable order
+func Actions() []Action {
+	return []Action{ActionList, ActionRead, ActionCreate, ActionUpdate, ActionDelete}
+}
+
+// ResourceTypes returns all resource types in a stable order
+func ResourceTypes() []ResourceType {
+	return []ResourceType{ResourceStack, ResourceBlueprint, ResourceEnv, ResourceVariable, ResourceSecret}
+}
+
+// IsValidResourceType reports whether s names a known resource type
+func IsValidResourceType(s string) bool {
+	for _, resourceType := range ResourceTypes() {
+		if string(resourceType) == s {
+			return true
+		}
+	}
+	return false
+}
+
 // Permission represents a permission check result
 type Permission struct {
 	Allowed bool
@@ -34,7 +54,8 @@ type Permission struct {
 
 // Authorizer handles authorization decisions
 type Authorizer struct {
-	nsManager *NamespaceManager
+	nsManager  *NamespaceManager
+	visibility map[Role]RoleVisibility // Shared namespaces per role, DefaultRoleVisibility if unset
 }
 
 // NewAuthorizer creates a new authorizer
@@ -119,8 +140,8 @@ func (a *Authorizer) CanAccess(role Role, action Action, resourceType ResourceTy
 			}
 		}
 
-		// Users can read/list global blueprints only
-		if a.nsManager.IsGlobalNamespace(namespace) && resourceType == ResourceBlueprint {
+		// Users can read/list global blueprints only, unless global read is disabled for the role
+		if a.nsManager.IsGlobalNamespace(namespace) && resourceType == ResourceBlueprint && a.CanReadGlobal(role) {
 			if action == ActionRead || action == ActionList {
 				return Permission{
 					Allowed: true,
@@ -129,6 +150,15 @@ func (a *Authorizer) CanAccess(role Role, action Action, resourceType ResourceTy
 			}
 		}
 
+		// Users can read/list anything in extra namespaces configured for the role
+		if (action == ActionRead || action == ActionList) &&
+			!a.nsManager.IsGlobalNamespace(namespace) && a.CanReadShared(role, namespace) {
+			return Permission{
+				Allowed: true,
+				Reason:  "role can read this namespace",
+			}
+		}
+
 		return Permission{
 			Allowed: false,
 			Reason:  "insufficient permissions",
@@ -176,9 +206,9 @@ func (a *Authorizer) GetAllowedNamespaces(role Role, action Action, resourceType
 
 	// User role
 	if role == User {
-		// Can read from global
+		// Can read from global (unless disabled for the role) and extra read namespaces
 		if action == ActionRead || action == ActionList {
-			namespaces = append(namespaces, a.nsManager.GetGlobalNamespace())
+			namespaces = append(namespaces, a.SharedReadNamespaces(role)...)
 		}
 
 		// Can do everything in own namespace
@@ -0,0 +1,58 @@
+package authz
+
+// RoleVisibility controls which shared namespaces a role reads besides its own
+type RoleVisibility struct {
+	GlobalRead     bool     // Read and list global resources
+	ReadNamespaces []string // Extra namespaces the role may read and list
+}
+
+// DefaultRoleVisibility is used for roles without configured visibility: global is readable
+var DefaultRoleVisibility = RoleVisibility{GlobalRead: true}
+
+// SetRoleVisibility configures the shared namespaces a role reads
+func (a *Authorizer) SetRoleVisibility(role Role, visibility RoleVisibility) {
+	if a.visibility == nil {
+		a.visibility = make(map[Role]RoleVisibility)
+	}
+	a.visibility[role] = visibility
+}
+
+// roleVisibility returns the configured visibility of a role, DefaultRoleVisibility if unset
+func (a *Authorizer) roleVisibility(role Role) RoleVisibility {
+	if visibility, ok := a.visibility[role]; ok {
+		return visibility
+	}
+	return DefaultRoleVisibility
+}
+
+// CanReadGlobal reports whether a role sees global resources
+func (a *Authorizer) CanReadGlobal(role Role) bool {
+	return a.roleVisibility(role).GlobalRead
+}
+
+// SharedReadNamespaces returns the namespaces other than its own that a role reads:
+// the global namespace (unless disabled) followed by the configured extra namespaces
+func (a *Authorizer) SharedReadNamespaces(role Role) []string {
+	visibility := a.roleVisibility(role)
+
+	var namespaces []string
+	if visibility.GlobalRead {
+		namespaces = append(namespaces, a.nsManager.GetGlobalNamespace())
+	}
+	for _, ns := range visibility.ReadNamespaces {
+		if !a.nsManager.IsGlobalNamespace(ns) {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
+// CanReadShared reports whether namespace is one of the role's shared read namespaces
+func (a *Authorizer) CanReadShared(role Role, namespace string) bool {
+	for _, ns := range a.SharedReadNamespaces(role) {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
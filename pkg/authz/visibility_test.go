@@ -0,0 +1,67 @@
+package authz_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/authz"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+var _ = Describe("Role visibility", func() {
+	var authorizer *authz.Authorizer
+
+	BeforeEach(func() {
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		authorizer = authz.NewAuthorizer(authz.NewNamespaceManager(cfg))
+	})
+
+	It("should let roles read global by default", func() {
+		Expect(authorizer.CanReadGlobal(authz.User)).To(BeTrue())
+		Expect(authorizer.GetAllowedNamespaces(authz.User, authz.ActionList, authz.ResourceBlueprint, "alice")).
+			To(Equal([]string{"lissto-global", "dev-alice"}))
+		Expect(authorizer.CanAccess(authz.User, authz.ActionRead, authz.ResourceBlueprint, "lissto-global", "alice").Allowed).To(BeTrue())
+	})
+
+	Context("with global read disabled", func() {
+		BeforeEach(func() {
+			authorizer.SetRoleVisibility(authz.User, authz.RoleVisibility{GlobalRead: false})
+		})
+
+		It("should not list the global namespace", func() {
+			Expect(authorizer.SharedReadNamespaces(authz.User)).To(BeEmpty())
+			Expect(authorizer.GetAllowedNamespaces(authz.User, authz.ActionList, authz.ResourceBlueprint, "alice")).
+				To(Equal([]string{"dev-alice"}))
+		})
+
+		It("should deny reading global blueprints", func() {
+			Expect(authorizer.CanAccess(authz.User, authz.ActionRead, authz.ResourceBlueprint, "lissto-global", "alice").Allowed).To(BeFalse())
+			Expect(authorizer.CanReadShared(authz.User, "lissto-global")).To(BeFalse())
+		})
+
+		It("should leave other roles unchanged", func() {
+			Expect(authorizer.CanReadGlobal(authz.Deploy)).To(BeTrue())
+		})
+	})
+
+	Context("with extra read namespaces", func() {
+		BeforeEach(func() {
+			authorizer.SetRoleVisibility(authz.User, authz.RoleVisibility{
+				GlobalRead:     true,
+				ReadNamespaces: []string{"team-shared"},
+			})
+		})
+
+		It("should list and read them after global", func() {
+			Expect(authorizer.GetAllowedNamespaces(authz.User, authz.ActionList, authz.ResourceBlueprint, "alice")).
+				To(Equal([]string{"lissto-global", "team-shared", "dev-alice"}))
+			Expect(authorizer.CanAccess(authz.User, authz.ActionRead, authz.ResourceBlueprint, "team-shared", "alice").Allowed).To(BeTrue())
+		})
+
+		It("should not allow writing them", func() {
+			Expect(authorizer.CanAccess(authz.User, authz.ActionCreate, authz.ResourceBlueprint, "team-shared", "alice").Allowed).To(BeFalse())
+		})
+	})
+})
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
@@ -9,4 +10,41 @@ import (
 type Cache interface {
 	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
 	Get(ctx context.Context, key string, dest interface{}) error
+	Delete(ctx context.Context, key string) error
+}
+
+// Backend returns a short name for the cache implementation ("memory", "file" or "custom")
+func Backend(c Cache) string {
+	switch c.(type) {
+	case *MemoryCache:
+		return "memory"
+	case *FileCache:
+		return "file"
+	default:
+		return "custom"
+	}
+}
+
+// IsMiss reports whether err means the key is absent or expired,
+// as opposed to the cache backend failing
+func IsMiss(err error) bool {
+	return errors.Is(err, ErrCacheNotFound) || errors.Is(err, ErrCacheExpired)
+}
+
+// GetWithRetry calls Get up to attempts times, waiting backoff between tries
+// Misses are returned immediately; only backend errors are retried
+func GetWithRetry(ctx context.Context, c Cache, key string, dest interface{}, attempts int, backoff time.Duration) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = c.Get(ctx, key, dest)
+		if err == nil || IsMiss(err) || attempt >= attempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+	}
 }
@@ -9,8 +9,8 @@ import (
 
 // NewImageCache creates the appropriate cache for image digests
 // If IMAGE_CACHE_FILE_PATH env var is set, uses file-based cache (for dev)
-// Otherwise uses in-memory cache (for production)
-func NewImageCache() Cache {
+// Otherwise uses in-memory cache (for production) bounded by the entry size and memory limits
+func NewImageCache(maxEntryBytes, maxMemoryBytes int64) Cache {
 	cacheFilePath := os.Getenv("IMAGE_CACHE_FILE_PATH")
 	if cacheFilePath != "" {
 		fileCache, err := NewFileCache(cacheFilePath)
@@ -18,13 +18,15 @@ func NewImageCache() Cache {
 			logging.Logger.Warn("Failed to create file-based image cache, falling back to memory cache",
 				zap.String("path", cacheFilePath),
 				zap.Error(err))
-			return NewMemoryCache()
+			return NewMemoryCacheWithLimits(maxEntryBytes, maxMemoryBytes)
 		}
 		logging.Logger.Info("Initialized file-based image cache for development",
 			zap.String("path", cacheFilePath))
 		return fileCache
 	}
 
-	logging.Logger.Info("Initialized in-memory image cache")
-	return NewMemoryCache()
+	logging.Logger.Info("Initialized in-memory image cache",
+		zap.Int64("max_entry_bytes", maxEntryBytes),
+		zap.Int64("max_memory_bytes", maxMemoryBytes))
+	return NewMemoryCacheWithLimits(maxEntryBytes, maxMemoryBytes)
 }
@@ -103,6 +103,15 @@ func (fc *FileCache) Get(ctx context.Context, key string, dest interface{}) erro
 	return json.Unmarshal(entry.value, dest)
 }
 
+// Delete removes a key from the cache; deleting a missing key is not an error
+func (fc *FileCache) Delete(ctx context.Context, key string) error {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	delete(fc.data, key)
+	return nil
+}
+
 // cleanup runs periodically to remove expired entries
 func (fc *FileCache) cleanup() {
 	ticker := time.NewTicker(5 * time.Minute)
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
 	"sync"
 	"time"
 )
@@ -11,24 +13,55 @@ import (
 var (
 	ErrCacheNotFound = errors.New("cache entry not found")
 	ErrCacheExpired  = errors.New("cache entry expired")
+	ErrEntryTooLarge = errors.New("cache entry too large")
 )
 
 // cacheEntry represents a single cache entry with expiration
 type cacheEntry struct {
 	value     []byte // JSON-encoded value
 	expiresAt time.Time
+	seq       uint64 // Write order; the oldest entries are evicted first when over the memory budget
+}
+
+// size is the memory accounted to an entry stored under key
+func (e *cacheEntry) size(key string) int64 {
+	return int64(len(key) + len(e.value))
+}
+
+// Usage reports the current memory cache usage
+type Usage struct {
+	Entries   int
+	Bytes     int64
+	Evictions uint64 // Entries evicted to stay within the memory budget
+	Rejected  uint64 // Entries not stored because they exceed the per-entry limit
 }
 
 // MemoryCache is an in-memory implementation of the Cache interface
 type MemoryCache struct {
 	data map[string]*cacheEntry
 	mu   sync.RWMutex
+
+	maxEntryBytes int64 // 0 means unlimited
+	maxBytes      int64 // 0 means unlimited
+	bytes         int64
+	seq           uint64
+	evictions     uint64
+	rejected      uint64
 }
 
-// NewMemoryCache creates a new in-memory cache with background cleanup
+// NewMemoryCache creates a new in-memory cache with background cleanup and no size limits
 func NewMemoryCache() *MemoryCache {
+	return NewMemoryCacheWithLimits(0, 0)
+}
+
+// NewMemoryCacheWithLimits creates a new in-memory cache with background cleanup
+// Values larger than maxEntryBytes are rejected with ErrEntryTooLarge, and the oldest entries
+// are evicted once the cache holds more than maxBytes; zero disables either limit
+func NewMemoryCacheWithLimits(maxEntryBytes, maxBytes int64) *MemoryCache {
 	cache := &MemoryCache{
-		data: make(map[string]*cacheEntry),
+		data:          make(map[string]*cacheEntry),
+		maxEntryBytes: maxEntryBytes,
+		maxBytes:      maxBytes,
 	}
 
 	// Start background cleanup goroutine
@@ -47,10 +80,22 @@ func (m *MemoryCache) Set(ctx context.Context, key string, value interface{}, tt
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.data[key] = &cacheEntry{
+	now := time.Now()
+	m.seq++
+	entry := &cacheEntry{
 		value:     data,
-		expiresAt: time.Now().Add(ttl),
+		expiresAt: now.Add(ttl),
+		seq:       m.seq,
 	}
+	if m.maxEntryBytes > 0 && entry.size(key) > m.maxEntryBytes {
+		m.rejected++
+		return fmt.Errorf("%w: %d bytes exceeds the %d byte limit", ErrEntryTooLarge, entry.size(key), m.maxEntryBytes)
+	}
+
+	m.remove(key)
+	m.data[key] = entry
+	m.bytes += entry.size(key)
+	m.enforceBudget(key, now)
 
 	return nil
 }
@@ -67,9 +112,11 @@ func (m *MemoryCache) Get(ctx context.Context, key string, dest interface{}) err
 
 	// Check if expired
 	if time.Now().After(entry.expiresAt) {
-		// Clean up expired entry
+		// Clean up expired entry, unless it was replaced meanwhile
 		m.mu.Lock()
-		delete(m.data, key)
+		if m.data[key] == entry {
+			m.remove(key)
+		}
 		m.mu.Unlock()
 		return ErrCacheExpired
 	}
@@ -78,6 +125,67 @@ func (m *MemoryCache) Get(ctx context.Context, key string, dest interface{}) err
 	return json.Unmarshal(entry.value, dest)
 }
 
+// Delete removes a key from the cache; deleting a missing key is not an error
+func (m *MemoryCache) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(key)
+	return nil
+}
+
+// Usage returns the current entry count, memory use and eviction counters
+func (m *MemoryCache) Usage() Usage {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return Usage{
+		Entries:   len(m.data),
+		Bytes:     m.bytes,
+		Evictions: m.evictions,
+		Rejected:  m.rejected,
+	}
+}
+
+// remove deletes an entry and releases its memory; the caller holds the write lock
+func (m *MemoryCache) remove(key string) {
+	if entry, exists := m.data[key]; exists {
+		m.bytes -= entry.size(key)
+		delete(m.data, key)
+	}
+}
+
+// enforceBudget drops expired entries, then the oldest ones, until the cache fits in maxBytes
+// The entry just stored under keep is never evicted; the caller holds the write lock
+func (m *MemoryCache) enforceBudget(keep string, now time.Time) {
+	if m.maxBytes <= 0 || m.bytes <= m.maxBytes {
+		return
+	}
+
+	keys := make([]string, 0, len(m.data))
+	for key, entry := range m.data {
+		if key == keep {
+			continue
+		}
+		if now.After(entry.expiresAt) {
+			m.remove(key)
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return m.data[keys[i]].seq < m.data[keys[j]].seq
+	})
+	for _, key := range keys {
+		if m.bytes <= m.maxBytes {
+			return
+		}
+		m.remove(key)
+		m.evictions++
+	}
+}
+
 // cleanup runs periodically to remove expired entries
 func (m *MemoryCache) cleanup() {
 	ticker := time.NewTicker(5 * time.Minute)
@@ -89,7 +197,7 @@ func (m *MemoryCache) cleanup() {
 
 		for key, entry := range m.data {
 			if now.After(entry.expiresAt) {
-				delete(m.data, key)
+				m.remove(key)
 			}
 		}
 
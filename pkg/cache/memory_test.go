@@ -0,0 +1,91 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/cache"
+)
+
+var _ = Describe("MemoryCache limits", func() {
+	ctx := context.Background()
+
+	// entry is a prepare result whose JSON encoding is roughly size bytes
+	entry := func(size int) *cache.PrepareResultCache {
+		return &cache.PrepareResultCache{Namespace: "dev-alice", Compose: strings.Repeat("x", size)}
+	}
+
+	It("should not cache an entry over the per-entry limit", func() {
+		memCache := cache.NewMemoryCacheWithLimits(512, 0)
+
+		err := memCache.Set(ctx, "big", entry(1024), time.Minute)
+		Expect(errors.Is(err, cache.ErrEntryTooLarge)).To(BeTrue())
+
+		var result cache.PrepareResultCache
+		Expect(memCache.Get(ctx, "big", &result)).To(MatchError(cache.ErrCacheNotFound))
+		Expect(memCache.Usage()).To(Equal(cache.Usage{Rejected: 1}))
+
+		Expect(memCache.Set(ctx, "small", entry(100), time.Minute)).To(Succeed())
+		Expect(memCache.Get(ctx, "small", &result)).To(Succeed())
+	})
+
+	It("should release the memory of deleted entries", func() {
+		memCache := cache.NewMemoryCache()
+
+		Expect(memCache.Set(ctx, "result", entry(100), time.Minute)).To(Succeed())
+		Expect(memCache.Delete(ctx, "result")).To(Succeed())
+		Expect(memCache.Delete(ctx, "missing")).To(Succeed())
+
+		var result cache.PrepareResultCache
+		Expect(memCache.Get(ctx, "result", &result)).To(MatchError(cache.ErrCacheNotFound))
+		Expect(memCache.Usage()).To(Equal(cache.Usage{}))
+	})
+
+	It("should evict the oldest entries once over the memory budget", func() {
+		memCache := cache.NewMemoryCacheWithLimits(0, 2500)
+
+		Expect(memCache.Set(ctx, "first", entry(1000), time.Minute)).To(Succeed())
+		Expect(memCache.Set(ctx, "second", entry(1000), time.Minute)).To(Succeed())
+		Expect(memCache.Usage().Evictions).To(BeZero())
+
+		Expect(memCache.Set(ctx, "third", entry(1000), time.Minute)).To(Succeed())
+
+		var result cache.PrepareResultCache
+		Expect(memCache.Get(ctx, "first", &result)).To(MatchError(cache.ErrCacheNotFound))
+		Expect(memCache.Get(ctx, "second", &result)).To(Succeed())
+		Expect(memCache.Get(ctx, "third", &result)).To(Succeed())
+
+		usage := memCache.Usage()
+		Expect(usage.Entries).To(Equal(2))
+		Expect(usage.Evictions).To(Equal(uint64(1)))
+		Expect(usage.Bytes).To(BeNumerically("<=", 2500))
+	})
+
+	It("should evict expired entries before live ones", func() {
+		memCache := cache.NewMemoryCacheWithLimits(0, 2500)
+
+		Expect(memCache.Set(ctx, "live", entry(1000), time.Minute)).To(Succeed())
+		Expect(memCache.Set(ctx, "expired", entry(1000), -time.Second)).To(Succeed())
+		Expect(memCache.Set(ctx, "new", entry(1000), time.Minute)).To(Succeed())
+
+		var result cache.PrepareResultCache
+		Expect(memCache.Get(ctx, "live", &result)).To(Succeed())
+		Expect(memCache.Get(ctx, "new", &result)).To(Succeed())
+		Expect(memCache.Usage().Evictions).To(BeZero())
+	})
+
+	It("should account replaced entries once", func() {
+		memCache := cache.NewMemoryCacheWithLimits(0, 0)
+
+		Expect(memCache.Set(ctx, "web", entry(1000), time.Minute)).To(Succeed())
+		before := memCache.Usage().Bytes
+		Expect(memCache.Set(ctx, "web", entry(1000), time.Minute)).To(Succeed())
+
+		Expect(memCache.Usage()).To(Equal(cache.Usage{Entries: 1, Bytes: before}))
+	})
+})
@@ -4,8 +4,12 @@ import "time"
 
 // PrepareResultCache stores the result of a prepare operation
 type PrepareResultCache struct {
-	Namespace string                    `json:"namespace"` // For ownership verification
-	Images    map[string]ImageInfoCache `json:"images"`
+	Namespace  string                    `json:"namespace"` // For ownership verification
+	Images     map[string]ImageInfoCache `json:"images"`
+	Compose    string                    `json:"compose,omitempty"`     // Inline compose content, empty when prepared from a blueprint
+	HostSuffix string                    `json:"host_suffix,omitempty"` // Validated host_override_suffix, empty for the configured suffixes
+	// Blueprints are the references merged into Compose when several blueprints were prepared together
+	Blueprints []string `json:"blueprints,omitempty"`
 }
 
 // ImageInfoCache contains the cached information about a resolved image
@@ -13,13 +17,22 @@ type ImageInfoCache struct {
 	Digest string `json:"digest"`
 	Image  string `json:"image"`
 	URL    string `json:"url,omitempty"`
+	// Pending is set for build-only services awaiting their first build;
+	// Digest is empty and Image holds the expected placeholder
+	Pending bool `json:"pending,omitempty"`
+	// Unpinned is set when the image tag is deployed without a digest (unpinned fallback)
+	Unpinned bool `json:"unpinned,omitempty"`
+	// Verified is set when the image's cosign signature verified at prepare; Signer names who signed it
+	Verified bool   `json:"verified,omitempty"`
+	Signer   string `json:"signer,omitempty"`
 }
 
 // ImageDigestCache stores the digest for a specific image+tag+platform combination
 type ImageDigestCache struct {
-	ImageURL  string    `json:"image_url"`  // Original image:tag (e.g., postgres:15.2)
-	Digest    string    `json:"digest"`     // Full digest (e.g., sha256:abc123...)
-	Platform  string    `json:"platform"`   // Platform (e.g., linux/amd64)
-	ImageType string    `json:"image_type"` // "infra" or "service"
-	CachedAt  time.Time `json:"cached_at"`  // When this was cached (for debugging)
+	ImageURL  string    `json:"image_url"`            // Original image:tag (e.g., postgres:15.2)
+	Digest    string    `json:"digest"`               // Full digest (e.g., sha256:abc123...)
+	Platform  string    `json:"platform"`             // Platform (e.g., linux/amd64)
+	MultiArch bool      `json:"multi_arch,omitempty"` // Digest was selected from a manifest list
+	ImageType string    `json:"image_type"`           // "infra" or "service"
+	CachedAt  time.Time `json:"cached_at"`            // When this was cached (for debugging)
 }
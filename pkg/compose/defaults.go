@@ -0,0 +1,233 @@
+package compose
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// DefaultsKey is the x-lissto key holding defaults applied to every service
+// A service's own labels and settings always win over the defaults, which in turn
+// win over the server configuration:
+//
+//	x-lissto:
+//	  defaults:
+//	    platform: linux/arm64
+//	    pullSecret: regcred
+//	    strategy: start-first
+//	    resources:
+//	      limits: {cpus: "0.5", memory: 512M}
+//	    labels:
+//	      lissto.dev/resource-class: small
+const DefaultsKey = "defaults"
+
+// Labels set from ServiceDefaults fields
+const (
+	PlatformOSLabel   = "lissto.dev/platform-os"
+	PlatformArchLabel = "lissto.dev/platform-arch"
+	PullSecretLabel   = "kompose.image-pull-secret"
+)
+
+// Rollout orders accepted by ServiceDefaults.Strategy (compose deploy.update_config.order)
+const (
+	StrategyStartFirst = "start-first"
+	StrategyStopFirst  = "stop-first"
+)
+
+// ServiceDefaults are the x-lissto.defaults applied to services that don't set their own
+type ServiceDefaults struct {
+	Platform   string            // os/arch images are resolved for (lissto.dev/platform-os/-arch)
+	PullSecret string            // Image pull secret (kompose.image-pull-secret)
+	Strategy   string            // Rollout order: start-first or stop-first (deploy.update_config.order)
+	Resources  *types.Resources  // deploy.resources limits and reservations
+	Labels     map[string]string // Any other service label
+}
+
+// ExtractServiceDefaults parses x-lissto.defaults, nil when the block is absent
+func ExtractServiceDefaults(project *types.Project) (*ServiceDefaults, error) {
+	extMap, ok := project.Extensions["x-lissto"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	raw, ok := extMap[DefaultsKey]
+	if !ok {
+		return nil, nil
+	}
+	block, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("x-lissto.%s must be a mapping", DefaultsKey)
+	}
+
+	defaults := &ServiceDefaults{}
+	for key, value := range block {
+		var err error
+		switch key {
+		case "platform":
+			defaults.Platform, err = defaultString(key, value)
+			if err == nil {
+				if os, arch, found := strings.Cut(defaults.Platform, "/"); !found || os == "" || arch == "" {
+					err = fmt.Errorf("platform must be os/arch (e.g. linux/arm64), got %q", defaults.Platform)
+				}
+			}
+		case "pullSecret":
+			defaults.PullSecret, err = defaultString(key, value)
+		case "strategy":
+			defaults.Strategy, err = defaultString(key, value)
+			if err == nil && defaults.Strategy != StrategyStartFirst && defaults.Strategy != StrategyStopFirst {
+				err = fmt.Errorf("strategy must be %s or %s, got %q", StrategyStartFirst, StrategyStopFirst, defaults.Strategy)
+			}
+		case "resources":
+			defaults.Resources, err = parseDefaultResources(value)
+		case "labels":
+			defaults.Labels, err = parseDefaultLabels(value)
+		default:
+			err = fmt.Errorf("unknown key %q (valid: platform, pullSecret, strategy, resources, labels)", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid x-lissto.%s: %w", DefaultsKey, err)
+		}
+	}
+	return defaults, nil
+}
+
+// ApplyServiceDefaults merges x-lissto.defaults into every service that doesn't set the same
+// label or setting itself. It runs right after loading, before any preprocessor or postprocessor.
+func ApplyServiceDefaults(project *types.Project) error {
+	defaults, err := ExtractServiceDefaults(project)
+	if err != nil || defaults == nil {
+		return err
+	}
+
+	labels := make(map[string]string, len(defaults.Labels)+3)
+	for key, value := range defaults.Labels {
+		labels[key] = value
+	}
+	if defaults.Platform != "" {
+		os, arch, _ := strings.Cut(defaults.Platform, "/")
+		setIfAbsent(labels, PlatformOSLabel, os)
+		setIfAbsent(labels, PlatformArchLabel, arch)
+	}
+	if defaults.PullSecret != "" {
+		setIfAbsent(labels, PullSecretLabel, defaults.PullSecret)
+	}
+
+	for name, service := range project.Services {
+		for key, value := range labels {
+			if _, exists := service.Labels[key]; !exists {
+				service.Labels = service.Labels.Add(key, value)
+			}
+		}
+
+		if defaults.Strategy != "" || defaults.Resources != nil {
+			if service.Deploy == nil {
+				service.Deploy = &types.DeployConfig{}
+			}
+		}
+		if defaults.Strategy != "" {
+			if service.Deploy.UpdateConfig == nil {
+				service.Deploy.UpdateConfig = &types.UpdateConfig{}
+			}
+			if service.Deploy.UpdateConfig.Order == "" {
+				service.Deploy.UpdateConfig.Order = defaults.Strategy
+			}
+		}
+		if defaults.Resources != nil {
+			if service.Deploy.Resources.Limits == nil && defaults.Resources.Limits != nil {
+				limits := *defaults.Resources.Limits
+				service.Deploy.Resources.Limits = &limits
+			}
+			if service.Deploy.Resources.Reservations == nil && defaults.Resources.Reservations != nil {
+				reservations := *defaults.Resources.Reservations
+				service.Deploy.Resources.Reservations = &reservations
+			}
+		}
+
+		project.Services[name] = service
+	}
+	return nil
+}
+
+// setIfAbsent sets key unless the defaults' labels already set it
+func setIfAbsent(labels map[string]string, key, value string) {
+	if _, exists := labels[key]; !exists {
+		labels[key] = value
+	}
+}
+
+// defaultString reads a non-empty string default
+func defaultString(key string, value interface{}) (string, error) {
+	str, ok := value.(string)
+	if !ok || str == "" {
+		return "", fmt.Errorf("%s must be a non-empty string", key)
+	}
+	return str, nil
+}
+
+// parseDefaultLabels reads the labels mapping; values must be scalars
+func parseDefaultLabels(value interface{}) (map[string]string, error) {
+	block, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("labels must be a mapping")
+	}
+	labels := make(map[string]string, len(block))
+	for key, v := range block {
+		switch v.(type) {
+		case string, int, int64, float64, bool:
+			labels[key] = fmt.Sprint(v)
+		default:
+			return nil, fmt.Errorf("label %q must be a scalar", key)
+		}
+	}
+	return labels, nil
+}
+
+// parseDefaultResources reads limits and reservations with cpus and memory, in compose syntax
+func parseDefaultResources(value interface{}) (*types.Resources, error) {
+	block, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("resources must be a mapping")
+	}
+
+	resources := &types.Resources{}
+	for key, v := range block {
+		resource, err := parseDefaultResource(key, v)
+		if err != nil {
+			return nil, err
+		}
+		switch key {
+		case "limits":
+			resources.Limits = resource
+		case "reservations":
+			resources.Reservations = resource
+		default:
+			return nil, fmt.Errorf("unknown resources key %q (valid: limits, reservations)", key)
+		}
+	}
+	return resources, nil
+}
+
+// parseDefaultResource reads the cpus and memory of one resources entry
+func parseDefaultResource(name string, value interface{}) (*types.Resource, error) {
+	block, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("resources.%s must be a mapping", name)
+	}
+
+	resource := &types.Resource{}
+	for key, v := range block {
+		switch key {
+		case "cpus":
+			if err := resource.NanoCPUs.DecodeMapstructure(v); err != nil {
+				return nil, fmt.Errorf("invalid resources.%s.cpus: %w", name, err)
+			}
+		case "memory":
+			if err := resource.MemoryBytes.DecodeMapstructure(v); err != nil {
+				return nil, fmt.Errorf("invalid resources.%s.memory: %w", name, err)
+			}
+		default:
+			return nil, fmt.Errorf("unknown resources.%s key %q (valid: cpus, memory)", name, key)
+		}
+	}
+	return resource, nil
+}
@@ -0,0 +1,84 @@
+package compose_test
+
+import (
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/compose"
+)
+
+var _ = Describe("ApplyServiceDefaults", func() {
+	It("should apply defaults to services without their own and keep service overrides", func() {
+		project := loadProject(`
+x-lissto:
+  defaults:
+    platform: linux/arm64
+    pullSecret: regcred
+    strategy: start-first
+    resources:
+      limits:
+        cpus: "0.5"
+        memory: 512M
+    labels:
+      lissto.dev/resource-class: small
+services:
+  api:
+    image: api
+  worker:
+    image: worker
+    labels:
+      lissto.dev/platform-arch: amd64
+      lissto.dev/resource-class: large
+    deploy:
+      update_config:
+        order: stop-first
+      resources:
+        limits:
+          cpus: "2"
+`)
+		Expect(compose.ApplyServiceDefaults(project)).To(Succeed())
+
+		api := project.Services["api"]
+		Expect(api.Labels).To(Equal(types.Labels{
+			compose.PlatformOSLabel:     "linux",
+			compose.PlatformArchLabel:   "arm64",
+			compose.PullSecretLabel:     "regcred",
+			"lissto.dev/resource-class": "small",
+		}))
+		Expect(api.Deploy.UpdateConfig.Order).To(Equal(compose.StrategyStartFirst))
+		Expect(api.Deploy.Resources.Limits.NanoCPUs.Value()).To(BeNumerically("==", 0.5))
+		Expect(api.Deploy.Resources.Limits.MemoryBytes).To(Equal(types.UnitBytes(512 * 1024 * 1024)))
+
+		worker := project.Services["worker"]
+		Expect(worker.Labels).To(HaveKeyWithValue(compose.PlatformArchLabel, "amd64"))
+		Expect(worker.Labels).To(HaveKeyWithValue(compose.PlatformOSLabel, "linux"))
+		Expect(worker.Labels).To(HaveKeyWithValue("lissto.dev/resource-class", "large"))
+		Expect(worker.Deploy.UpdateConfig.Order).To(Equal(compose.StrategyStopFirst))
+		Expect(worker.Deploy.Resources.Limits.NanoCPUs.Value()).To(BeNumerically("==", 2))
+		Expect(worker.Deploy.Resources.Limits.MemoryBytes).To(BeZero())
+	})
+
+	It("should leave projects without defaults unchanged", func() {
+		project := loadProject(`
+services:
+  api:
+    image: api
+`)
+		Expect(compose.ApplyServiceDefaults(project)).To(Succeed())
+		Expect(project.Services["api"].Labels).To(BeEmpty())
+		Expect(project.Services["api"].Deploy).To(BeNil())
+	})
+
+	It("should reject invalid defaults", func() {
+		for _, block := range []string{
+			"platform: arm64",
+			"strategy: sideways",
+			"resources: {limits: {gpus: 1}}",
+			"replicas: 3",
+		} {
+			project := loadProject("x-lissto:\n  defaults:\n    " + block + "\nservices:\n  api:\n    image: api\n")
+			Expect(compose.ApplyServiceDefaults(project)).To(MatchError(ContainSubstring("x-lissto.defaults")), block)
+		}
+	})
+})
@@ -0,0 +1,109 @@
+package compose
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/dotenv"
+	"github.com/compose-spec/compose-go/v2/loader"
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// EnvFilesKey is the x-lissto key bundling env_file contents with the compose file
+// The API has no access to the repository, so blueprints carry env files inline:
+//
+//	x-lissto:
+//	  envFiles:
+//	    ./api.env: |
+//	      LOG_LEVEL=debug
+const EnvFilesKey = "envFiles"
+
+// WithDeferredEnvFiles is a loader option that keeps env_file references unresolved
+// so ResolveEnvFiles can read them from x-lissto.envFiles instead of the filesystem
+func WithDeferredEnvFiles(o *loader.Options) {
+	o.SkipResolveEnvironment = true
+}
+
+// ExtractEnvFiles returns the bundled env file contents keyed by normalized path
+func ExtractEnvFiles(project *types.Project) map[string]string {
+	files := make(map[string]string)
+
+	extMap, ok := project.Extensions["x-lissto"].(map[string]interface{})
+	if !ok {
+		return files
+	}
+	bundled, ok := extMap[EnvFilesKey].(map[string]interface{})
+	if !ok {
+		return files
+	}
+
+	for name, content := range bundled {
+		if contentStr, ok := content.(string); ok {
+			files[normalizeEnvFilePath(name)] = contentStr
+		}
+	}
+	return files
+}
+
+// ResolveEnvFiles merges bundled env_file values into each service's environment
+// Files are applied in order, later files overriding earlier ones; the service's own
+// environment always takes precedence. A required env file that is not bundled is an error,
+// optional ones are skipped. Resolved services have their env_file list cleared.
+func ResolveEnvFiles(project *types.Project) error {
+	bundled := ExtractEnvFiles(project)
+
+	for name, service := range project.Services {
+		if len(service.EnvFiles) == 0 {
+			continue
+		}
+
+		merged := types.MappingWithEquals{}
+		for _, envFile := range service.EnvFiles {
+			key := envFileKey(project.WorkingDir, envFile.Path)
+			content, ok := bundled[key]
+			if !ok {
+				if envFile.Required {
+					return fmt.Errorf("service %s: env file %q is not bundled in x-lissto.%s", name, key, EnvFilesKey)
+				}
+				continue
+			}
+
+			values, err := dotenv.UnmarshalWithLookup(content, nil)
+			if err != nil {
+				return fmt.Errorf("service %s: failed to parse env file %q: %w", name, key, err)
+			}
+			for k, v := range values {
+				value := v
+				merged[k] = &value
+			}
+		}
+
+		for k, v := range service.Environment {
+			merged[k] = v
+		}
+
+		service.Environment = merged
+		service.EnvFiles = nil
+		project.Services[name] = service
+	}
+
+	return nil
+}
+
+// envFileKey maps a loaded env_file path back to the path written in the compose file
+// The loader resolves relative paths against the project working directory
+func envFileKey(workingDir, envFilePath string) string {
+	if workingDir != "" && filepath.IsAbs(envFilePath) {
+		if rel, err := filepath.Rel(workingDir, envFilePath); err == nil && !strings.HasPrefix(rel, "..") {
+			envFilePath = rel
+		}
+	}
+	return normalizeEnvFilePath(envFilePath)
+}
+
+// normalizeEnvFilePath cleans a path so "./api.env" and "api.env" match
+func normalizeEnvFilePath(p string) string {
+	return path.Clean(filepath.ToSlash(p))
+}
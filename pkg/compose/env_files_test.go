@@ -0,0 +1,93 @@
+package compose_test
+
+import (
+	"context"
+
+	"github.com/compose-spec/compose-go/v2/loader"
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/compose"
+)
+
+// loadProjectWithEnvFiles loads compose content leaving env_file references for ResolveEnvFiles
+func loadProjectWithEnvFiles(content string) *types.Project {
+	project, err := loader.LoadWithContext(
+		context.Background(),
+		types.ConfigDetails{
+			ConfigFiles: []types.ConfigFile{{Filename: "docker-compose.yml", Content: []byte(content)}},
+			WorkingDir:  "/tmp",
+		},
+		loader.WithSkipValidation,
+		compose.WithDeferredEnvFiles,
+		func(o *loader.Options) { o.SetProjectName("test", true) },
+	)
+	Expect(err).NotTo(HaveOccurred())
+	return project
+}
+
+var _ = Describe("ResolveEnvFiles", func() {
+	It("should merge bundled env files with inline environment taking precedence", func() {
+		project := loadProjectWithEnvFiles(`
+x-lissto:
+  envFiles:
+    ./common.env: |
+      LOG_LEVEL=info
+      REGION=eu-west-1
+    api.env: |
+      LOG_LEVEL=debug
+      DB_HOST=db
+services:
+  api:
+    image: api
+    env_file:
+      - common.env
+      - ./api.env
+    environment:
+      DB_HOST: postgres
+  db:
+    image: postgres:15
+`)
+
+		Expect(compose.ResolveEnvFiles(project)).To(Succeed())
+
+		api := project.Services["api"]
+		Expect(api.EnvFiles).To(BeEmpty())
+		Expect(api.Environment).To(HaveLen(3))
+		Expect(*api.Environment["LOG_LEVEL"]).To(Equal("debug"))
+		Expect(*api.Environment["REGION"]).To(Equal("eu-west-1"))
+		Expect(*api.Environment["DB_HOST"]).To(Equal("postgres"))
+		Expect(project.Services["db"].Environment).To(BeEmpty())
+	})
+
+	It("should fail when a required env file is not bundled", func() {
+		project := loadProjectWithEnvFiles(`
+services:
+  api:
+    image: api
+    env_file: secrets.env
+`)
+
+		err := compose.ResolveEnvFiles(project)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(`env file "secrets.env" is not bundled`))
+	})
+
+	It("should skip optional env files that are not bundled", func() {
+		project := loadProjectWithEnvFiles(`
+services:
+  api:
+    image: api
+    env_file:
+      - path: local.env
+        required: false
+    environment:
+      PORT: "8080"
+`)
+
+		Expect(compose.ResolveEnvFiles(project)).To(Succeed())
+		Expect(project.Services["api"].Environment).To(HaveKey("PORT"))
+		Expect(project.Services["api"].EnvFiles).To(BeEmpty())
+	})
+})
@@ -0,0 +1,49 @@
+package compose
+
+import "github.com/compose-spec/compose-go/v2/types"
+
+// EnvReferencesKey is the x-lissto key listing the variables and secrets a blueprint expects in its env
+//
+//	x-lissto:
+//	  env:
+//	    variables: [app-config]
+//	    secrets: [db-creds]
+const EnvReferencesKey = "env"
+
+// EnvReferences names the LisstoVariables and LisstoSecrets a blueprint references
+type EnvReferences struct {
+	Variables []string
+	Secrets   []string
+}
+
+// ExtractEnvReferences returns the references declared in x-lissto.env; values that aren't names are ignored
+func ExtractEnvReferences(project *types.Project) EnvReferences {
+	var refs EnvReferences
+
+	extMap, ok := project.Extensions["x-lissto"].(map[string]interface{})
+	if !ok {
+		return refs
+	}
+	envMap, ok := extMap[EnvReferencesKey].(map[string]interface{})
+	if !ok {
+		return refs
+	}
+	refs.Variables = stringList(envMap["variables"])
+	refs.Secrets = stringList(envMap["secrets"])
+	return refs
+}
+
+// stringList returns the non-empty strings of a YAML sequence
+func stringList(value interface{}) []string {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	var values []string
+	for _, item := range items {
+		if s, ok := item.(string); ok && s != "" {
+			values = append(values, s)
+		}
+	}
+	return values
+}
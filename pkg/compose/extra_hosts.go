@@ -0,0 +1,25 @@
+package compose
+
+import (
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// ExtractServiceExtraHosts extracts each service's `extra_hosts` as hostname to IPs
+// Kompose drops extra_hosts, so they are translated to hostAliases after conversion.
+// Only services with at least one entry are returned.
+func ExtractServiceExtraHosts(project *types.Project) map[string]map[string][]string {
+	serviceExtraHosts := make(map[string]map[string][]string)
+
+	for name, service := range project.Services {
+		if len(service.ExtraHosts) == 0 {
+			continue
+		}
+		hosts := make(map[string][]string, len(service.ExtraHosts))
+		for hostname, ips := range service.ExtraHosts {
+			hosts[hostname] = append([]string(nil), ips...)
+		}
+		serviceExtraHosts[name] = hosts
+	}
+
+	return serviceExtraHosts
+}
@@ -0,0 +1,27 @@
+package compose_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/compose"
+)
+
+var _ = Describe("ExtractServiceExtraHosts", func() {
+	It("should extract extra_hosts of services that set them", func() {
+		project := loadProject(`
+services:
+  api:
+    image: nginx
+    extra_hosts:
+      - "legacy-db=10.1.2.3"
+      - "ldap:10.1.2.4"
+  db:
+    image: postgres:16
+`)
+
+		Expect(compose.ExtractServiceExtraHosts(project)).To(Equal(map[string]map[string][]string{
+			"api": {"legacy-db": {"10.1.2.3"}, "ldap": {"10.1.2.4"}},
+		}))
+	})
+})
@@ -0,0 +1,105 @@
+package compose
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// TmpfsMount is an in-memory mount declared by a service
+type TmpfsMount struct {
+	Path      string             // Mount path inside the container
+	SizeLimit *resource.Quantity // Size limit, nil if not specified
+}
+
+// ServiceFilesystem contains the filesystem options of a service that Kompose drops or mistranslates
+type ServiceFilesystem struct {
+	Tmpfs    []TmpfsMount // From `tmpfs` and `volumes` entries of type tmpfs, in declaration order
+	ReadOnly bool         // From `read_only`
+}
+
+// ExtractServiceFilesystems extracts tmpfs mounts and read_only from each service.
+// Only services declaring at least one of them are returned, keyed by service name.
+func ExtractServiceFilesystems(project *types.Project) (map[string]ServiceFilesystem, error) {
+	filesystems := make(map[string]ServiceFilesystem)
+
+	for name, service := range project.Services {
+		fs := ServiceFilesystem{ReadOnly: service.ReadOnly}
+
+		// Short syntax: "/path" or "/path:size=64m,mode=1777"
+		for _, entry := range service.Tmpfs {
+			mount, err := parseTmpfsEntry(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid tmpfs %q for service %s: %w", entry, name, err)
+			}
+			fs.Tmpfs = append(fs.Tmpfs, mount)
+		}
+
+		// Long syntax: volumes entries with type: tmpfs
+		for _, volume := range service.Volumes {
+			if volume.Type != types.VolumeTypeTmpfs || volume.Target == "" {
+				continue
+			}
+			mount := TmpfsMount{Path: volume.Target}
+			if volume.Tmpfs != nil && volume.Tmpfs.Size > 0 {
+				mount.SizeLimit = resource.NewQuantity(int64(volume.Tmpfs.Size), resource.BinarySI)
+			}
+			fs.Tmpfs = append(fs.Tmpfs, mount)
+		}
+
+		if fs.ReadOnly || len(fs.Tmpfs) > 0 {
+			filesystems[name] = fs
+		}
+	}
+
+	return filesystems, nil
+}
+
+// parseTmpfsEntry parses a short syntax tmpfs entry, keeping only the size option
+func parseTmpfsEntry(entry string) (TmpfsMount, error) {
+	path, options, _ := strings.Cut(entry, ":")
+	if path == "" {
+		return TmpfsMount{}, fmt.Errorf("path must not be empty")
+	}
+
+	mount := TmpfsMount{Path: path}
+	for _, option := range strings.Split(options, ",") {
+		key, value, _ := strings.Cut(option, "=")
+		if key != "size" {
+			continue
+		}
+		size, err := parseTmpfsSize(value)
+		if err != nil {
+			return TmpfsMount{}, err
+		}
+		mount.SizeLimit = size
+	}
+	return mount, nil
+}
+
+// parseTmpfsSize converts a Docker tmpfs size (bytes with optional k/m/g suffix, binary units)
+// to a Kubernetes quantity; "64m" means 64 MiB here, not 64 milli
+func parseTmpfsSize(value string) (*resource.Quantity, error) {
+	multiplier := int64(1)
+	number := strings.ToLower(value)
+	switch {
+	case strings.HasSuffix(number, "k"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(number, "m"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(number, "g"):
+		multiplier = 1 << 30
+	}
+	if multiplier > 1 {
+		number = number[:len(number)-1]
+	}
+
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("invalid size %q", value)
+	}
+	return resource.NewQuantity(n*multiplier, resource.BinarySI), nil
+}
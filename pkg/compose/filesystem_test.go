@@ -0,0 +1,57 @@
+package compose_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/lissto-dev/api/pkg/compose"
+)
+
+var _ = Describe("ExtractServiceFilesystems", func() {
+	It("should extract short and long syntax tmpfs mounts with sizes", func() {
+		project := loadProject(`
+services:
+  api:
+    image: nginx
+    read_only: true
+    tmpfs:
+      - /tmp
+      - /run:size=64m,mode=1777
+    volumes:
+      - type: tmpfs
+        target: /cache
+        tmpfs:
+          size: 1048576
+  db:
+    image: postgres:15
+`)
+
+		filesystems, err := compose.ExtractServiceFilesystems(project)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filesystems).To(HaveLen(1))
+
+		api := filesystems["api"]
+		Expect(api.ReadOnly).To(BeTrue())
+		Expect(api.Tmpfs).To(HaveLen(3))
+		Expect(api.Tmpfs[0].Path).To(Equal("/tmp"))
+		Expect(api.Tmpfs[0].SizeLimit).To(BeNil())
+		Expect(api.Tmpfs[1].Path).To(Equal("/run"))
+		Expect(api.Tmpfs[1].SizeLimit.Cmp(resource.MustParse("64Mi"))).To(Equal(0))
+		Expect(api.Tmpfs[2].Path).To(Equal("/cache"))
+		Expect(api.Tmpfs[2].SizeLimit.Cmp(resource.MustParse("1Mi"))).To(Equal(0))
+	})
+
+	It("should reject an invalid size", func() {
+		project := loadProject(`
+services:
+  api:
+    image: nginx
+    tmpfs:
+      - /tmp:size=lots
+`)
+
+		_, err := compose.ExtractServiceFilesystems(project)
+		Expect(err).To(MatchError(ContainSubstring("invalid size")))
+	})
+})
@@ -0,0 +1,64 @@
+package compose
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// GPUCapability is the device capability requesting GPUs
+const GPUCapability = "gpu"
+
+// MaxServiceGPUs bounds the GPUs a single service may reserve
+const MaxServiceGPUs = 16
+
+// ExtractServiceGPUs extracts the GPU count each service reserves in deploy.resources.reservations.devices.
+// Kompose drops device reservations, so they become nvidia.com/gpu resources after conversion.
+// Reservations without a count use their device_ids count; `count: all`, which is also the default
+// when neither is set, is rejected as Kubernetes has no "all GPUs" request.
+// Only services reserving at least one GPU are returned.
+func ExtractServiceGPUs(project *types.Project) (map[string]int64, error) {
+	serviceGPUs := make(map[string]int64)
+
+	for name, service := range project.Services {
+		if service.Deploy == nil || service.Deploy.Resources.Reservations == nil {
+			continue
+		}
+		var total int64
+		for _, device := range service.Deploy.Resources.Reservations.Devices {
+			if !slices.Contains(device.Capabilities, GPUCapability) {
+				continue
+			}
+			count, err := gpuCount(device)
+			if err != nil {
+				return nil, fmt.Errorf("invalid GPU reservation for service %s: %w", name, err)
+			}
+			total += count
+		}
+		if total > MaxServiceGPUs {
+			return nil, fmt.Errorf("invalid GPU reservation for service %s: %d GPUs exceed the maximum of %d", name, total, MaxServiceGPUs)
+		}
+		if total > 0 {
+			serviceGPUs[name] = total
+		}
+	}
+
+	return serviceGPUs, nil
+}
+
+// gpuCount returns the number of GPUs a device reservation asks for
+func gpuCount(device types.DeviceRequest) (int64, error) {
+	if device.Driver != "" && device.Driver != "nvidia" {
+		return 0, fmt.Errorf("driver %q is not supported, only nvidia", device.Driver)
+	}
+	switch {
+	case device.Count == -1:
+		return 0, fmt.Errorf("count 'all' is not supported, set a count or device_ids")
+	case device.Count < 0:
+		return 0, fmt.Errorf("count %d must be positive", device.Count)
+	case device.Count == 0:
+		return int64(len(device.IDs)), nil
+	}
+	return int64(device.Count), nil
+}
@@ -0,0 +1,63 @@
+package compose_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/compose"
+)
+
+var _ = Describe("ExtractServiceGPUs", func() {
+	It("should extract GPU reservations of services that set them", func() {
+		project := loadProject(`
+services:
+  trainer:
+    image: pytorch/pytorch
+    deploy:
+      resources:
+        reservations:
+          devices:
+            - driver: nvidia
+              count: 2
+              capabilities: [gpu]
+  inference:
+    image: vllm/vllm-openai
+    deploy:
+      resources:
+        reservations:
+          devices:
+            - device_ids: ["0"]
+              capabilities: [gpu, utility]
+  web:
+    image: nginx
+    deploy:
+      resources:
+        reservations:
+          memory: 128M
+`)
+
+		Expect(compose.ExtractServiceGPUs(project)).To(Equal(map[string]int64{"trainer": 2, "inference": 1}))
+	})
+
+	DescribeTable("should reject invalid GPU counts",
+		func(device, message string) {
+			project := loadProject(`
+services:
+  trainer:
+    image: pytorch/pytorch
+    deploy:
+      resources:
+        reservations:
+          devices:
+            - ` + device + `
+`)
+
+			_, err := compose.ExtractServiceGPUs(project)
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("all GPUs", `{count: all, capabilities: [gpu]}`, "count 'all' is not supported"),
+		Entry("no count", `{capabilities: [gpu]}`, "count 'all' is not supported"),
+		Entry("too many", `{count: 64, capabilities: [gpu]}`, "exceed the maximum"),
+		Entry("other driver", `{driver: amd, count: 1, capabilities: [gpu]}`, "only nvidia"),
+	)
+})
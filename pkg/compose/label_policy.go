@@ -0,0 +1,77 @@
+package compose
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// LabelPolicyPrefix scopes label policies to lissto's own labels, other labels are never restricted
+const LabelPolicyPrefix = "lissto.dev/"
+
+// LabelViolation is a lissto label a service may not use
+type LabelViolation struct {
+	Service string `json:"service"`
+	Label   string `json:"label"`
+}
+
+// ValidateLabelPatterns checks that every pattern is a valid path.Match pattern, e.g. lissto.dev/expose*
+func ValidateLabelPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if pattern == "" {
+			return fmt.Errorf("empty label pattern")
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid label pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// CheckLabelPolicy returns every lissto label a service may not use, sorted by service then label
+// A label is disallowed when it matches a denied pattern, or when allowed is set and it matches none of them.
+func CheckLabelPolicy(project *types.Project, allowed, denied []string) []LabelViolation {
+	if len(allowed) == 0 && len(denied) == 0 {
+		return nil
+	}
+	var violations []LabelViolation
+	for name, service := range project.Services {
+		for label := range service.Labels {
+			if !strings.HasPrefix(label, LabelPolicyPrefix) {
+				continue
+			}
+			if matchesLabel(denied, label) || (len(allowed) > 0 && !matchesLabel(allowed, label)) {
+				violations = append(violations, LabelViolation{Service: name, Label: label})
+			}
+		}
+	}
+	sort.Slice(violations, func(i, j int) bool {
+		if violations[i].Service != violations[j].Service {
+			return violations[i].Service < violations[j].Service
+		}
+		return violations[i].Label < violations[j].Label
+	})
+	return violations
+}
+
+// StripLabels removes the violating labels from the project's services
+func StripLabels(project *types.Project, violations []LabelViolation) {
+	for _, violation := range violations {
+		if service, ok := project.Services[violation.Service]; ok {
+			delete(service.Labels, violation.Label)
+		}
+	}
+}
+
+// matchesLabel reports whether the label matches any pattern; invalid patterns match nothing
+func matchesLabel(patterns []string, label string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, label); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package compose_test
+
+import (
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/compose"
+)
+
+var _ = Describe("CheckLabelPolicy", func() {
+	const content = `
+services:
+  web:
+    image: nginx
+    labels:
+      lissto.dev/image: registry.acme.io/web:1.0
+      lissto.dev/expose: internet
+      team: payments
+  worker:
+    image: busybox
+    labels:
+      lissto.dev/tag: stable
+`
+
+	It("should report denied lissto labels", func() {
+		project := loadProject(content)
+
+		Expect(compose.CheckLabelPolicy(project, nil, []string{"lissto.dev/image"})).To(Equal([]compose.LabelViolation{
+			{Service: "web", Label: "lissto.dev/image"},
+		}))
+	})
+
+	It("should report lissto labels outside the allowlist and ignore other labels", func() {
+		project := loadProject(content)
+
+		Expect(compose.CheckLabelPolicy(project, []string{"lissto.dev/expose*"}, nil)).To(Equal([]compose.LabelViolation{
+			{Service: "web", Label: "lissto.dev/image"},
+			{Service: "worker", Label: "lissto.dev/tag"},
+		}))
+	})
+
+	It("should deny labels matching both lists", func() {
+		project := loadProject(content)
+
+		Expect(compose.CheckLabelPolicy(project, []string{"lissto.dev/*"}, []string{"lissto.dev/tag"})).To(Equal([]compose.LabelViolation{
+			{Service: "worker", Label: "lissto.dev/tag"},
+		}))
+	})
+
+	It("should strip the violating labels", func() {
+		project := loadProject(content)
+		compose.StripLabels(project, compose.CheckLabelPolicy(project, nil, []string{"lissto.dev/image"}))
+
+		Expect(project.Services["web"].Labels).To(Equal(types.Labels{
+			"lissto.dev/expose": "internet",
+			"team":              "payments",
+		}))
+	})
+
+	It("should reject malformed patterns", func() {
+		Expect(compose.ValidateLabelPatterns([]string{"lissto.dev/["})).To(MatchError(ContainSubstring("invalid label pattern")))
+		Expect(compose.ValidateLabelPatterns([]string{"lissto.dev/*"})).To(Succeed())
+	})
+})
@@ -0,0 +1,37 @@
+package compose
+
+import (
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// LoggingLabelPrefix sets a logging option through a label, e.g. lissto.dev/logging.parser: json
+// Labels override the same option from the service's `logging.options`
+const LoggingLabelPrefix = "lissto.dev/logging."
+
+// ExtractServiceLogging extracts the logging options of each service from `logging.options`
+// and lissto.dev/logging.* labels. Only services with at least one option are returned.
+func ExtractServiceLogging(project *types.Project) map[string]map[string]string {
+	serviceLogging := make(map[string]map[string]string)
+
+	for name, service := range project.Services {
+		options := make(map[string]string)
+		if service.Logging != nil {
+			for key, value := range service.Logging.Options {
+				options[key] = value
+			}
+		}
+		for key, value := range service.Labels {
+			if option, ok := strings.CutPrefix(key, LoggingLabelPrefix); ok && option != "" {
+				options[option] = value
+			}
+		}
+
+		if len(options) > 0 {
+			serviceLogging[name] = options
+		}
+	}
+
+	return serviceLogging
+}
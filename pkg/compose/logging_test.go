@@ -0,0 +1,33 @@
+package compose_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/compose"
+)
+
+var _ = Describe("ExtractServiceLogging", func() {
+	It("should merge logging options with lissto.dev/logging labels", func() {
+		project := loadProject(`
+services:
+  api:
+    image: nginx
+    logging:
+      driver: json-file
+      options:
+        parser: json
+        max-size: 10m
+    labels:
+      lissto.dev/logging.parser: nginx
+      lissto.dev/logging.exclude: "true"
+      lissto.dev/other: ignored
+  db:
+    image: postgres:16
+`)
+
+		Expect(compose.ExtractServiceLogging(project)).To(Equal(map[string]map[string]string{
+			"api": {"parser": "nginx", "max-size": "10m", "exclude": "true"},
+		}))
+	})
+})
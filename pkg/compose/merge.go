@@ -0,0 +1,128 @@
+package compose
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ComposeFile is one compose document taking part in a merge, named for error messages
+// (e.g. the blueprint reference it came from)
+type ComposeFile struct {
+	Name    string
+	Content string
+}
+
+// mergedResourceKeys are the top-level sections whose entries may be shared between files,
+// as long as every file defines them identically
+var mergedResourceKeys = map[string]string{
+	"volumes":  "volume",
+	"networks": "network",
+	"configs":  "config",
+	"secrets":  "secret",
+}
+
+// MergeComposeFiles merges several compose documents into one
+// Services are combined and a service defined by two files is rejected; volumes, networks,
+// configs and secrets may repeat only with identical definitions. Extensions (x-*, including
+// x-lissto) are merged key by key and other top-level keys are kept from the first file that sets them.
+func MergeComposeFiles(files []ComposeFile) (string, error) {
+	if len(files) == 0 {
+		return "", fmt.Errorf("no compose files to merge")
+	}
+
+	merged := map[string]interface{}{}
+	serviceOwner := map[string]string{}
+	resourceOwner := map[string]string{}
+	for _, file := range files {
+		var doc map[string]interface{}
+		if err := yaml.Unmarshal([]byte(file.Content), &doc); err != nil {
+			return "", fmt.Errorf("failed to parse compose of %s: %w", file.Name, err)
+		}
+
+		for key, value := range doc {
+			switch {
+			case key == "services":
+				services, ok := value.(map[string]interface{})
+				if !ok {
+					if value == nil {
+						continue
+					}
+					return "", fmt.Errorf("services of %s must be a mapping", file.Name)
+				}
+				target := sectionOf(merged, key)
+				for name, service := range services {
+					if owner, exists := serviceOwner[name]; exists {
+						return "", fmt.Errorf("service %q is defined in both %s and %s", name, owner, file.Name)
+					}
+					serviceOwner[name] = file.Name
+					target[name] = service
+				}
+			case mergedResourceKeys[key] != "":
+				resources, ok := value.(map[string]interface{})
+				if !ok {
+					if value == nil {
+						continue
+					}
+					return "", fmt.Errorf("%s of %s must be a mapping", key, file.Name)
+				}
+				target := sectionOf(merged, key)
+				for name, resource := range resources {
+					if existing, exists := target[name]; exists {
+						if !reflect.DeepEqual(existing, resource) {
+							return "", fmt.Errorf("%s %q is defined differently in %s and %s",
+								mergedResourceKeys[key], name, resourceOwner[key+"/"+name], file.Name)
+						}
+						continue
+					}
+					resourceOwner[key+"/"+name] = file.Name
+					target[name] = resource
+				}
+			case strings.HasPrefix(key, "x-"):
+				merged[key] = mergeExtension(merged[key], value)
+			default:
+				if _, exists := merged[key]; !exists {
+					merged[key] = value
+				}
+			}
+		}
+	}
+
+	out, err := yaml.Marshal(merged)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize merged compose: %w", err)
+	}
+	return string(out), nil
+}
+
+// sectionOf returns the top-level mapping key of doc, creating it when missing
+func sectionOf(doc map[string]interface{}, key string) map[string]interface{} {
+	section, ok := doc[key].(map[string]interface{})
+	if !ok {
+		section = map[string]interface{}{}
+		doc[key] = section
+	}
+	return section
+}
+
+// mergeExtension merges an extension value into the one merged so far; mappings are merged
+// recursively and for anything else the earlier value wins
+func mergeExtension(existing, value interface{}) interface{} {
+	if existing == nil {
+		return value
+	}
+	existingMap, ok := existing.(map[string]interface{})
+	if !ok {
+		return existing
+	}
+	valueMap, ok := value.(map[string]interface{})
+	if !ok {
+		return existing
+	}
+	for key, v := range valueMap {
+		existingMap[key] = mergeExtension(existingMap[key], v)
+	}
+	return existingMap
+}
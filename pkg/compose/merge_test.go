@@ -0,0 +1,73 @@
+package compose_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/compose"
+)
+
+var _ = Describe("MergeComposeFiles", func() {
+	const infra = `
+x-lissto:
+  title: infra
+services:
+  postgres:
+    image: postgres:16
+    volumes:
+      - pg_data:/var/lib/postgresql/data
+volumes:
+  pg_data: {}
+`
+	const app = `
+x-lissto:
+  title: app
+  repository: ghcr.io/acme/app
+services:
+  web:
+    image: ghcr.io/acme/app:main
+    depends_on:
+      - postgres
+    environment:
+      DATABASE_URL: postgres://postgres@postgres:5432/app
+`
+
+	It("should merge an infra and an app blueprint into one project", func() {
+		merged, err := compose.MergeComposeFiles([]compose.ComposeFile{
+			{Name: "global/postgres", Content: infra},
+			{Name: "alice/app", Content: app},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		project := loadProject(merged)
+		Expect(project.ServiceNames()).To(ConsistOf("postgres", "web"))
+		Expect(project.Services["web"].DependsOn).To(HaveKey("postgres"))
+		Expect(project.Volumes).To(HaveKey("pg_data"))
+
+		lissto := project.Extensions["x-lissto"].(map[string]interface{})
+		Expect(lissto).To(HaveKeyWithValue("title", "infra"))
+		Expect(lissto).To(HaveKeyWithValue("repository", "ghcr.io/acme/app"))
+	})
+
+	It("should reject a service defined by two blueprints", func() {
+		_, err := compose.MergeComposeFiles([]compose.ComposeFile{
+			{Name: "global/postgres", Content: infra},
+			{Name: "alice/app", Content: app + "  postgres:\n    image: postgres:15\n"},
+		})
+		Expect(err).To(MatchError(`service "postgres" is defined in both global/postgres and alice/app`))
+	})
+
+	It("should only share volumes defined identically", func() {
+		_, err := compose.MergeComposeFiles([]compose.ComposeFile{
+			{Name: "global/postgres", Content: infra},
+			{Name: "alice/app", Content: app + "volumes:\n  pg_data:\n    driver: local\n"},
+		})
+		Expect(err).To(MatchError(ContainSubstring(`volume "pg_data" is defined differently`)))
+
+		_, err = compose.MergeComposeFiles([]compose.ComposeFile{
+			{Name: "global/postgres", Content: infra},
+			{Name: "alice/app", Content: app + "volumes:\n  pg_data: {}\n"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
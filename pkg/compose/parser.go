@@ -29,6 +29,14 @@ type LisstoConfig struct {
 	Registry         string `json:"registry,omitempty"`
 	Repository       string `json:"repository,omitempty"`       // Single repository for all services
 	RepositoryPrefix string `json:"repositoryPrefix,omitempty"` // Prefix + service name
+	DefaultTag       string `json:"defaultTag,omitempty"`       // Floating tag tried last instead of the configured one
+	// TagPrefixPerService prefixes commit/branch/floating tags with "<service>-" (e.g. repo:api-abc123)
+	TagPrefixPerService bool `json:"tagPrefixPerService,omitempty"`
+	// ExposeDefault is the visibility (internal, internet) of services exposed with lissto.dev/expose: "true"
+	ExposeDefault string `json:"exposeDefault,omitempty"`
+	// AllowedDigests lists the approved digests (sha256:...) per image repository; when set, every resolved
+	// image must be one of them
+	AllowedDigests map[string][]string `json:"allowedDigests,omitempty"`
 }
 
 // ParseBlueprintMetadata parses docker-compose content and extracts:
@@ -48,11 +56,17 @@ func ParseBlueprintMetadata(composeContent string, repoConfig controllerconfig.R
 			WorkingDir: "/tmp",
 		},
 		loader.WithSkipValidation,
+		WithDeferredEnvFiles,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse Docker Compose: %w", err)
 	}
 
+	// Merge env_file values bundled in x-lissto.envFiles
+	if err := ResolveEnvFiles(project); err != nil {
+		return nil, fmt.Errorf("failed to parse Docker Compose: %w", err)
+	}
+
 	// Extract title with priority: x-lissto.title → repo.Name → repo.URL
 	title := extractTitle(project, repoConfig)
 
@@ -138,6 +152,42 @@ func ExtractLisstoConfig(project *types.Project) *LisstoConfig {
 		}
 	}
 
+	// Extract defaultTag (floating tag, e.g. "stable")
+	if tagVal, ok := extMap["defaultTag"]; ok {
+		if tagStr, ok := tagVal.(string); ok && tagStr != "" {
+			config.DefaultTag = tagStr
+		}
+	}
+
+	// Extract tagPrefixPerService (per-service tags of a single monorepo repository)
+	if perServiceVal, ok := extMap["tagPrefixPerService"]; ok {
+		if perService, ok := perServiceVal.(bool); ok {
+			config.TagPrefixPerService = perService
+		}
+	}
+
+	// Extract exposeDefault (visibility of services exposed with "true")
+	if exposeVal, ok := extMap["exposeDefault"]; ok {
+		if exposeStr, ok := exposeVal.(string); ok && exposeStr != "" {
+			config.ExposeDefault = exposeStr
+		}
+	}
+
+	// Extract allowedDigests (approved digests per image repository)
+	if allowedVal, ok := extMap["allowedDigests"]; ok {
+		if allowedMap, ok := allowedVal.(map[string]interface{}); ok {
+			config.AllowedDigests = make(map[string][]string, len(allowedMap))
+			for repository, digestsVal := range allowedMap {
+				digests, _ := digestsVal.([]interface{})
+				for _, digestVal := range digests {
+					if digest, ok := digestVal.(string); ok && digest != "" {
+						config.AllowedDigests[repository] = append(config.AllowedDigests[repository], digest)
+					}
+				}
+			}
+		}
+	}
+
 	return config
 }
 
@@ -145,37 +195,45 @@ func ExtractLisstoConfig(project *types.Project) *LisstoConfig {
 // Respects lissto.dev/group label override
 func categorizeServices(services types.Services) (servicesList []string, infraList []string) {
 	for name, service := range services {
-		// Check for explicit group label override
-		group := getGroupFromLabels(service.Labels)
-
-		if group != "" {
-			// Label override - map to appropriate category
-			groupLower := strings.ToLower(group)
-			switch groupLower {
-			case "service", "services":
-				servicesList = append(servicesList, name)
-			case "data", "infra", "infrastructure", "cache":
-				infraList = append(infraList, name)
-			default:
-				// Unknown group, default to services category
-				servicesList = append(servicesList, name)
-			}
-			continue
-		}
-
-		// No label override - check for build phase
-		if service.Build != nil {
-			// Has build phase - categorize as service
-			servicesList = append(servicesList, name)
-		} else {
-			// No build phase - categorize as infra
+		if IsInfraService(service) {
 			infraList = append(infraList, name)
+		} else {
+			servicesList = append(servicesList, name)
 		}
 	}
 
 	return servicesList, infraList
 }
 
+// IsInfraService reports whether a service is infrastructure (databases, caches) rather than an app
+// The lissto.dev/group label takes precedence; otherwise services without a build phase are infra
+func IsInfraService(service types.ServiceConfig) bool {
+	// Check for explicit group label override
+	if group := getGroupFromLabels(service.Labels); group != "" {
+		switch strings.ToLower(group) {
+		case "data", "infra", "infrastructure", "cache":
+			return true
+		default:
+			// Unknown group, default to services category
+			return false
+		}
+	}
+
+	// No label override - services with a build phase are apps
+	return service.Build == nil
+}
+
+// InfraServices returns the names of the project's infra services, see IsInfraService
+func InfraServices(project *types.Project) map[string]bool {
+	infra := make(map[string]bool)
+	for name, service := range project.Services {
+		if IsInfraService(service) {
+			infra[name] = true
+		}
+	}
+	return infra
+}
+
 // getGroupFromLabels extracts lissto.dev/group label value
 func getGroupFromLabels(labels types.Labels) string {
 	if labels == nil {
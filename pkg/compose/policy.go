@@ -0,0 +1,123 @@
+package compose
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// Compose features a policy can deny
+const (
+	FeaturePrivileged      = "privileged"        // privileged: true
+	FeatureHostNetwork     = "host_network"      // network_mode: host
+	FeatureHostPID         = "host_pid"          // pid: host
+	FeatureHostIPC         = "host_ipc"          // ipc: host
+	FeatureHostPathVolumes = "host_path_volumes" // bind mounts of host paths
+	FeatureCapAdd          = "cap_add"           // added Linux capabilities
+	FeatureDevices         = "devices"           // host devices
+)
+
+// policyFeatures lists every known feature with the check finding it in a service
+var policyFeatures = map[string]func(service types.ServiceConfig) string{
+	FeaturePrivileged: func(service types.ServiceConfig) string {
+		if service.Privileged {
+			return "runs privileged"
+		}
+		return ""
+	},
+	FeatureHostNetwork: func(service types.ServiceConfig) string {
+		if service.NetworkMode == "host" {
+			return "uses the host network (network_mode: host)"
+		}
+		return ""
+	},
+	FeatureHostPID: func(service types.ServiceConfig) string {
+		if service.Pid == "host" {
+			return "shares the host PID namespace (pid: host)"
+		}
+		return ""
+	},
+	FeatureHostIPC: func(service types.ServiceConfig) string {
+		if service.Ipc == "host" {
+			return "shares the host IPC namespace (ipc: host)"
+		}
+		return ""
+	},
+	FeatureHostPathVolumes: func(service types.ServiceConfig) string {
+		var sources []string
+		for _, volume := range service.Volumes {
+			if volume.Type == types.VolumeTypeBind {
+				sources = append(sources, volume.Source)
+			}
+		}
+		if len(sources) == 0 {
+			return ""
+		}
+		return fmt.Sprintf("mounts host paths: %s", strings.Join(sources, ", "))
+	},
+	FeatureCapAdd: func(service types.ServiceConfig) string {
+		if len(service.CapAdd) == 0 {
+			return ""
+		}
+		return fmt.Sprintf("adds capabilities: %s", strings.Join(service.CapAdd, ", "))
+	},
+	FeatureDevices: func(service types.ServiceConfig) string {
+		if len(service.Devices) == 0 {
+			return ""
+		}
+		return fmt.Sprintf("maps %d host device(s)", len(service.Devices))
+	},
+}
+
+// PolicyFeatures returns the feature keys a policy can deny, sorted
+func PolicyFeatures() []string {
+	features := make([]string, 0, len(policyFeatures))
+	for feature := range policyFeatures {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+	return features
+}
+
+// ValidatePolicyFeatures checks that every denied feature is known
+func ValidatePolicyFeatures(denied []string) error {
+	for _, feature := range denied {
+		if _, ok := policyFeatures[feature]; !ok {
+			return fmt.Errorf("unknown feature %q (valid: %s)", feature, strings.Join(PolicyFeatures(), ", "))
+		}
+	}
+	return nil
+}
+
+// PolicyViolation is a denied feature used by a service
+type PolicyViolation struct {
+	Service string `json:"service"`
+	Feature string `json:"feature"`
+	Message string `json:"message"`
+}
+
+// CheckPolicy returns every use of a denied feature in the project, sorted by service then feature
+// Unknown features are ignored, see ValidatePolicyFeatures
+func CheckPolicy(project *types.Project, denied []string) []PolicyViolation {
+	var violations []PolicyViolation
+	for name, service := range project.Services {
+		for _, feature := range denied {
+			check, ok := policyFeatures[feature]
+			if !ok {
+				continue
+			}
+			if message := check(service); message != "" {
+				violations = append(violations, PolicyViolation{Service: name, Feature: feature, Message: message})
+			}
+		}
+	}
+	sort.Slice(violations, func(i, j int) bool {
+		if violations[i].Service != violations[j].Service {
+			return violations[i].Service < violations[j].Service
+		}
+		return violations[i].Feature < violations[j].Feature
+	})
+	return violations
+}
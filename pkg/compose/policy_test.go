@@ -0,0 +1,73 @@
+package compose_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/compose"
+)
+
+var _ = Describe("CheckPolicy", func() {
+	denied := []string{compose.FeaturePrivileged, compose.FeatureHostNetwork, compose.FeatureHostPathVolumes}
+
+	It("should report privileged and host network services", func() {
+		project := loadProject(`
+services:
+  agent:
+    image: datadog/agent
+    privileged: true
+    network_mode: host
+  web:
+    image: nginx
+    volumes:
+      - /var/run/docker.sock:/var/run/docker.sock
+      - data:/data
+volumes:
+  data:
+`)
+
+		Expect(compose.CheckPolicy(project, denied)).To(Equal([]compose.PolicyViolation{
+			{Service: "agent", Feature: compose.FeatureHostNetwork, Message: "uses the host network (network_mode: host)"},
+			{Service: "agent", Feature: compose.FeaturePrivileged, Message: "runs privileged"},
+			{Service: "web", Feature: compose.FeatureHostPathVolumes, Message: "mounts host paths: /var/run/docker.sock"},
+		}))
+	})
+
+	It("should pass a clean compose", func() {
+		project := loadProject(`
+services:
+  web:
+    image: nginx
+    volumes:
+      - data:/data
+volumes:
+  data:
+`)
+
+		Expect(compose.CheckPolicy(project, denied)).To(BeEmpty())
+	})
+
+	It("should only report denied features", func() {
+		project := loadProject(`
+services:
+  agent:
+    image: datadog/agent
+    privileged: true
+    pid: host
+`)
+
+		Expect(compose.CheckPolicy(project, []string{compose.FeatureHostPID})).To(Equal([]compose.PolicyViolation{
+			{Service: "agent", Feature: compose.FeatureHostPID, Message: "shares the host PID namespace (pid: host)"},
+		}))
+	})
+})
+
+var _ = Describe("ValidatePolicyFeatures", func() {
+	It("should accept known features", func() {
+		Expect(compose.ValidatePolicyFeatures(compose.PolicyFeatures())).To(Succeed())
+	})
+
+	It("should reject unknown features", func() {
+		Expect(compose.ValidatePolicyFeatures([]string{"privileged", "sudo"})).To(MatchError(ContainSubstring(`unknown feature "sudo"`)))
+	})
+})
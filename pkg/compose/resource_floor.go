@@ -0,0 +1,189 @@
+package compose
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strconv"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Resource labels override requests and limits per service (Kubernetes quantities, e.g. 250m, 512Mi)
+const (
+	CPURequestLabel    = "lissto.dev/cpu-request"
+	MemoryRequestLabel = "lissto.dev/memory-request"
+	CPULimitLabel      = "lissto.dev/cpu-limit"
+	MemoryLimitLabel   = "lissto.dev/memory-limit"
+)
+
+// Fields a resource floor raises
+const (
+	FloorFieldReplicas      = "replicas"
+	FloorFieldMemoryRequest = "memory_request"
+	FloorFieldMemoryLimit   = "memory_limit"
+)
+
+// ResourceFloor is the minimum every service of an env gets, unset values enforce nothing
+type ResourceFloor struct {
+	MinReplicas      int    `yaml:"minReplicas"`
+	MinMemoryRequest string `yaml:"minMemoryRequest"` // Kubernetes quantity, e.g. 256Mi
+}
+
+// FloorAdjustment is a service value raised to the floor
+type FloorAdjustment struct {
+	Service string `json:"service"`
+	Field   string `json:"field"` // One of the FloorField* constants
+	From    string `json:"from"`  // Value before the adjustment, empty if unset
+	To      string `json:"to"`
+}
+
+// IsZero reports whether the floor enforces nothing
+func (f ResourceFloor) IsZero() bool {
+	return f.MinReplicas == 0 && f.MinMemoryRequest == ""
+}
+
+// Validate checks the replica count and memory quantity
+func (f ResourceFloor) Validate() error {
+	if f.MinReplicas < 0 {
+		return fmt.Errorf("minReplicas must not be negative, got %d", f.MinReplicas)
+	}
+	if f.MinMemoryRequest != "" {
+		if _, err := resource.ParseQuantity(f.MinMemoryRequest); err != nil {
+			return fmt.Errorf("invalid minMemoryRequest %q: %w", f.MinMemoryRequest, err)
+		}
+	}
+	return nil
+}
+
+// ApplyResourceFloor raises the services below the floor to it and returns the adjustments, sorted by service.
+// Values at or above the floor are untouched. Replicas only count for services running as Deployments
+// (restart always or unless-stopped, the default). The memory request is the lissto.dev/memory-request label,
+// else deploy.resources.reservations.memory, else the configured default (appMemory, or infraMemory for infra
+// services); a raised request is written to the label, which takes precedence over the others, and memory
+// limits below it are raised with it so the pod stays schedulable.
+func ApplyResourceFloor(project *types.Project, floor ResourceFloor, appMemory, infraMemory string) []FloorAdjustment {
+	if floor.IsZero() {
+		return nil
+	}
+	var minMemory *resource.Quantity
+	if floor.MinMemoryRequest != "" {
+		quantity, err := resource.ParseQuantity(floor.MinMemoryRequest)
+		if err != nil {
+			return nil // Floors are validated with the settings
+		}
+		minMemory = &quantity
+	}
+	policies := ExtractServiceRestartPolicies(project)
+
+	var adjustments []FloorAdjustment
+	for name, service := range project.Services {
+		policy, ok := policies[name]
+		if floor.MinReplicas > 0 && (!ok || policy.Policy == RestartAlways || policy.Policy == RestartUnlessStopped) {
+			if replicas := service.GetScale(); replicas < floor.MinReplicas {
+				deploy := types.DeployConfig{}
+				if service.Deploy != nil {
+					deploy = *service.Deploy
+				}
+				service.Deploy = &deploy
+				service.SetScale(floor.MinReplicas)
+				adjustments = append(adjustments, FloorAdjustment{
+					Service: name,
+					Field:   FloorFieldReplicas,
+					From:    strconv.Itoa(replicas),
+					To:      strconv.Itoa(floor.MinReplicas),
+				})
+			}
+		}
+
+		if minMemory != nil {
+			defaultMemory := appMemory
+			if IsInfraService(service) {
+				defaultMemory = infraMemory
+			}
+			adjustments = append(adjustments, raiseMemory(&service, name, *minMemory, defaultMemory)...)
+		}
+
+		project.Services[name] = service
+	}
+
+	sort.SliceStable(adjustments, func(i, j int) bool {
+		return adjustments[i].Service < adjustments[j].Service
+	})
+	return adjustments
+}
+
+// raiseMemory raises the memory request, and a limit below it, of a service to the floor via its labels
+func raiseMemory(service *types.ServiceConfig, name string, floor resource.Quantity, defaultMemory string) []FloorAdjustment {
+	var adjustments []FloorAdjustment
+
+	request := labelQuantity(service.Labels, MemoryRequestLabel)
+	if request == nil && service.Deploy != nil && service.Deploy.Resources.Reservations != nil {
+		request = bytesQuantity(service.Deploy.Resources.Reservations.MemoryBytes)
+	}
+	if request == nil && defaultMemory != "" {
+		if quantity, err := resource.ParseQuantity(defaultMemory); err == nil {
+			request = &quantity
+		}
+	}
+	if request != nil && request.Cmp(floor) >= 0 {
+		return nil
+	}
+
+	service.Labels = maps.Clone(service.Labels)
+	if service.Labels == nil {
+		service.Labels = types.Labels{}
+	}
+	service.Labels[MemoryRequestLabel] = floor.String()
+	adjustments = append(adjustments, FloorAdjustment{
+		Service: name,
+		Field:   FloorFieldMemoryRequest,
+		From:    quantityString(request),
+		To:      floor.String(),
+	})
+
+	limit := labelQuantity(service.Labels, MemoryLimitLabel)
+	if limit == nil && service.Deploy != nil && service.Deploy.Resources.Limits != nil {
+		limit = bytesQuantity(service.Deploy.Resources.Limits.MemoryBytes)
+	}
+	if limit != nil && limit.Cmp(floor) < 0 {
+		service.Labels[MemoryLimitLabel] = floor.String()
+		adjustments = append(adjustments, FloorAdjustment{
+			Service: name,
+			Field:   FloorFieldMemoryLimit,
+			From:    limit.String(),
+			To:      floor.String(),
+		})
+	}
+	return adjustments
+}
+
+// labelQuantity parses a resource label, nil if unset or invalid (the postprocessor ignores invalid ones too)
+func labelQuantity(labels types.Labels, label string) *resource.Quantity {
+	value := labels[label]
+	if value == "" {
+		return nil
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return nil
+	}
+	return &quantity
+}
+
+// bytesQuantity converts a compose memory size, nil if unset
+func bytesQuantity(bytes types.UnitBytes) *resource.Quantity {
+	if bytes <= 0 {
+		return nil
+	}
+	return resource.NewQuantity(int64(bytes), resource.BinarySI)
+}
+
+// quantityString formats a quantity, empty if unset
+func quantityString(quantity *resource.Quantity) string {
+	if quantity == nil {
+		return ""
+	}
+	return quantity.String()
+}
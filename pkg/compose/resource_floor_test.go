@@ -0,0 +1,99 @@
+package compose_test
+
+import (
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/compose"
+)
+
+var _ = Describe("ApplyResourceFloor", func() {
+	It("should raise a service requesting 1 replica to the floor and report it", func() {
+		project := loadProject(`
+services:
+  web:
+    image: nginx
+    deploy:
+      replicas: 1
+  api:
+    image: acme/api
+    deploy:
+      replicas: 5
+`)
+
+		adjustments := compose.ApplyResourceFloor(project, compose.ResourceFloor{MinReplicas: 3}, "", "")
+
+		Expect(adjustments).To(Equal([]compose.FloorAdjustment{
+			{Service: "web", Field: compose.FloorFieldReplicas, From: "1", To: "3"},
+		}))
+		web := project.Services["web"]
+		Expect(web.GetScale()).To(Equal(3))
+		Expect(*web.Deploy.Replicas).To(Equal(3))
+		api := project.Services["api"]
+		Expect(api.GetScale()).To(Equal(5))
+	})
+
+	It("should not raise the replicas of services that run once", func() {
+		project := loadProject(`
+services:
+  migrate:
+    image: acme/migrate
+    restart: "no"
+`)
+
+		Expect(compose.ApplyResourceFloor(project, compose.ResourceFloor{MinReplicas: 2}, "", "")).To(BeEmpty())
+	})
+
+	It("should raise memory requests below the floor through the label, with limits below it", func() {
+		project := loadProject(`
+services:
+  web:
+    image: nginx
+    deploy:
+      resources:
+        reservations:
+          memory: 128M
+        limits:
+          memory: 192M
+  api:
+    image: acme/api
+    labels:
+      lissto.dev/memory-request: 1Gi
+  worker:
+    image: acme/worker
+`)
+
+		adjustments := compose.ApplyResourceFloor(project, compose.ResourceFloor{MinMemoryRequest: "256Mi"}, "", "")
+
+		Expect(adjustments).To(Equal([]compose.FloorAdjustment{
+			{Service: "web", Field: compose.FloorFieldMemoryRequest, From: "128Mi", To: "256Mi"},
+			{Service: "web", Field: compose.FloorFieldMemoryLimit, From: "192Mi", To: "256Mi"},
+			{Service: "worker", Field: compose.FloorFieldMemoryRequest, From: "", To: "256Mi"},
+		}))
+		Expect(project.Services["web"].Labels).To(Equal(types.Labels{
+			compose.MemoryRequestLabel: "256Mi",
+			compose.MemoryLimitLabel:   "256Mi",
+		}))
+		Expect(project.Services["api"].Labels).To(Equal(types.Labels{compose.MemoryRequestLabel: "1Gi"}))
+	})
+
+	It("should count the configured default memory request", func() {
+		project := loadProject(`
+services:
+  web:
+    image: nginx
+`)
+
+		Expect(compose.ApplyResourceFloor(project, compose.ResourceFloor{MinMemoryRequest: "256Mi"}, "", "512Mi")).To(BeEmpty())
+		Expect(compose.ApplyResourceFloor(project, compose.ResourceFloor{MinMemoryRequest: "256Mi"}, "", "64Mi")).To(Equal([]compose.FloorAdjustment{
+			{Service: "web", Field: compose.FloorFieldMemoryRequest, From: "64Mi", To: "256Mi"},
+		}))
+	})
+
+	It("should reject invalid floors", func() {
+		Expect(compose.ResourceFloor{MinReplicas: -1}.Validate()).To(MatchError(ContainSubstring("minReplicas")))
+		Expect(compose.ResourceFloor{MinMemoryRequest: "lots"}.Validate()).To(MatchError(ContainSubstring("minMemoryRequest")))
+		Expect(compose.ResourceFloor{MinReplicas: 2, MinMemoryRequest: "256Mi"}.Validate()).To(Succeed())
+	})
+})
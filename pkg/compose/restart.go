@@ -0,0 +1,92 @@
+package compose
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// Compose restart policies
+const (
+	RestartAlways        = "always"
+	RestartUnlessStopped = "unless-stopped"
+	RestartOnFailure     = "on-failure"
+	RestartNo            = "no"
+)
+
+// RestartPolicy is the normalized restart behaviour of a service
+type RestartPolicy struct {
+	Policy      string // One of the Restart* constants
+	MaxAttempts *int32 // Restart attempts for on-failure, nil if not specified
+}
+
+// ExtractServiceRestartPolicies extracts each service's restart policy.
+// deploy.restart_policy replaces `restart` (as in Kompose), its conditions any/none map
+// to always/no; `on-failure:N` and max_attempts carry the attempt count.
+// Services with no or an unrecognized policy are not returned (Kompose defaults them to always).
+func ExtractServiceRestartPolicies(project *types.Project) map[string]RestartPolicy {
+	policies := make(map[string]RestartPolicy)
+
+	for name, service := range project.Services {
+		policy, ok := parseRestart(service.Restart)
+		if service.Deploy != nil && service.Deploy.RestartPolicy != nil {
+			restartPolicy := service.Deploy.RestartPolicy
+			var condition string
+			condition, ok = parseRestartCondition(restartPolicy.Condition)
+			policy = RestartPolicy{Policy: condition}
+			if ok && condition == RestartOnFailure && restartPolicy.MaxAttempts != nil {
+				attempts := int32(min(*restartPolicy.MaxAttempts, uint64(1<<31-1)))
+				policy.MaxAttempts = &attempts
+			}
+		}
+		if ok {
+			policies[name] = policy
+		}
+	}
+
+	return policies
+}
+
+// NormalizeRestartPolicies rewrites `on-failure:N` to `on-failure`, which Kompose rejects.
+// Call ExtractServiceRestartPolicies first, it keeps the attempt count.
+func NormalizeRestartPolicies(project *types.Project) {
+	for name, service := range project.Services {
+		if strings.HasPrefix(service.Restart, RestartOnFailure+":") {
+			service.Restart = RestartOnFailure
+			project.Services[name] = service
+		}
+	}
+}
+
+// parseRestart parses the `restart` option
+func parseRestart(value string) (RestartPolicy, bool) {
+	switch value {
+	case RestartAlways, RestartUnlessStopped, RestartOnFailure, RestartNo:
+		return RestartPolicy{Policy: value}, true
+	}
+
+	// on-failure[:max-retries]
+	if attemptsStr, found := strings.CutPrefix(value, RestartOnFailure+":"); found {
+		attempts, err := strconv.ParseInt(attemptsStr, 10, 32)
+		if err != nil || attempts < 0 {
+			return RestartPolicy{}, false
+		}
+		maxAttempts := int32(attempts)
+		return RestartPolicy{Policy: RestartOnFailure, MaxAttempts: &maxAttempts}, true
+	}
+	return RestartPolicy{}, false
+}
+
+// parseRestartCondition maps a deploy.restart_policy condition to a restart policy
+func parseRestartCondition(condition string) (string, bool) {
+	switch condition {
+	case "any":
+		return RestartAlways, true
+	case "none":
+		return RestartNo, true
+	case RestartOnFailure:
+		return RestartOnFailure, true
+	}
+	return "", false
+}
@@ -0,0 +1,61 @@
+package compose_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/compose"
+)
+
+var _ = Describe("ExtractServiceRestartPolicies", func() {
+	attempts := func(n int32) *int32 { return &n }
+
+	It("should extract restart and deploy.restart_policy", func() {
+		project := loadProject(`
+services:
+  web:
+    image: nginx
+    restart: unless-stopped
+  worker:
+    image: worker
+    restart: "on-failure:5"
+  migrate:
+    image: migrate
+    restart: "no"
+  seed:
+    image: seed
+    restart: always
+    deploy:
+      restart_policy:
+        condition: on-failure
+        max_attempts: 3
+  cron:
+    image: cron
+    deploy:
+      restart_policy:
+        condition: none
+  db:
+    image: postgres:16
+`)
+
+		Expect(compose.ExtractServiceRestartPolicies(project)).To(Equal(map[string]compose.RestartPolicy{
+			"web":     {Policy: compose.RestartUnlessStopped},
+			"worker":  {Policy: compose.RestartOnFailure, MaxAttempts: attempts(5)},
+			"migrate": {Policy: compose.RestartNo},
+			"seed":    {Policy: compose.RestartOnFailure, MaxAttempts: attempts(3)},
+			"cron":    {Policy: compose.RestartNo},
+		}))
+	})
+
+	It("should strip the attempt count Kompose rejects", func() {
+		project := loadProject(`
+services:
+  worker:
+    image: worker
+    restart: "on-failure:5"
+`)
+
+		compose.NormalizeRestartPolicies(project)
+		Expect(project.Services["worker"].Restart).To(Equal(compose.RestartOnFailure))
+	})
+})
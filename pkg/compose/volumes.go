@@ -0,0 +1,67 @@
+package compose
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// VolumeSizeLabel sets the requested PVC capacity for a top-level volume
+	VolumeSizeLabel = "lissto.dev/volume-size"
+	// StorageClassLabel sets the PVC storage class for a top-level volume
+	StorageClassLabel = "lissto.dev/storage-class"
+)
+
+// VolumeStorage contains storage hints parsed from a top-level compose volume
+type VolumeStorage struct {
+	Size         string // Requested capacity (e.g., "10Gi"), empty if not specified
+	StorageClass string // Storage class name, empty if not specified
+}
+
+// ExtractVolumeStorage extracts size and storage class hints from the top-level volumes section.
+// Sources (label wins over driver option):
+// - size: lissto.dev/volume-size label → driver_opts.size
+// - class: lissto.dev/storage-class label → driver_opts.storage-class
+// Results are keyed by the PVC name Kompose generates for the volume.
+func ExtractVolumeStorage(project *types.Project) (map[string]VolumeStorage, error) {
+	storage := make(map[string]VolumeStorage)
+
+	for name, volume := range project.Volumes {
+		size := firstNonEmpty(volume.Labels[VolumeSizeLabel], volume.DriverOpts["size"])
+		class := firstNonEmpty(volume.Labels[StorageClassLabel], volume.DriverOpts["storage-class"])
+		if size == "" && class == "" {
+			continue
+		}
+
+		if size != "" {
+			if _, err := resource.ParseQuantity(size); err != nil {
+				return nil, fmt.Errorf("invalid size %q for volume %s: %w", size, name, err)
+			}
+		}
+
+		storage[PVCName(name)] = VolumeStorage{
+			Size:         size,
+			StorageClass: class,
+		}
+	}
+
+	return storage, nil
+}
+
+// PVCName returns the PVC name Kompose generates for a named volume
+func PVCName(volumeName string) string {
+	return strings.ToLower(strings.ReplaceAll(volumeName, "_", "-"))
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+package compose_test
+
+import (
+	"context"
+
+	"github.com/compose-spec/compose-go/v2/loader"
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/compose"
+)
+
+func loadProject(content string) *types.Project {
+	project, err := loader.LoadWithContext(
+		context.Background(),
+		types.ConfigDetails{
+			ConfigFiles: []types.ConfigFile{{Filename: "docker-compose.yml", Content: []byte(content)}},
+			WorkingDir:  "/tmp",
+		},
+		loader.WithSkipValidation,
+		func(o *loader.Options) { o.SetProjectName("test", true) },
+	)
+	Expect(err).NotTo(HaveOccurred())
+	return project
+}
+
+var _ = Describe("ExtractVolumeStorage", func() {
+	It("should extract size from driver_opts and class from labels", func() {
+		project := loadProject(`
+services:
+  db:
+    image: postgres:15
+    volumes:
+      - pg_data:/var/lib/postgresql/data
+volumes:
+  pg_data:
+    driver_opts:
+      size: 20Gi
+    labels:
+      lissto.dev/storage-class: fast-ssd
+`)
+
+		storage, err := compose.ExtractVolumeStorage(project)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(storage).To(HaveKeyWithValue("pg-data", compose.VolumeStorage{Size: "20Gi", StorageClass: "fast-ssd"}))
+	})
+
+	It("should prefer the size label over driver_opts", func() {
+		project := loadProject(`
+services:
+  db:
+    image: postgres:15
+volumes:
+  data:
+    driver_opts:
+      size: 5Gi
+    labels:
+      lissto.dev/volume-size: 50Gi
+`)
+
+		storage, err := compose.ExtractVolumeStorage(project)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(storage["data"].Size).To(Equal("50Gi"))
+	})
+
+	It("should skip volumes without storage hints", func() {
+		project := loadProject(`
+services:
+  db:
+    image: postgres:15
+volumes:
+  data: {}
+`)
+
+		storage, err := compose.ExtractVolumeStorage(project)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(storage).To(BeEmpty())
+	})
+
+	It("should reject invalid sizes", func() {
+		project := loadProject(`
+services:
+  db:
+    image: postgres:15
+volumes:
+  data:
+    driver_opts:
+      size: lots
+`)
+
+		_, err := compose.ExtractVolumeStorage(project)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("data"))
+	})
+})
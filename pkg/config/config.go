@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/lissto-dev/api/pkg/k8s"
 	"github.com/lissto-dev/api/pkg/logging"
@@ -27,6 +28,15 @@ type APIKey struct {
 	APIKey      string `yaml:"api_key"`
 	Name        string `yaml:"name,omitempty"`
 	SlackUserID string `yaml:"slack_user_id,omitempty"`
+	// Scopes restricts the key to these resource types (stack, blueprint, env, variable, secret), empty means all
+	Scopes []string `yaml:"scopes,omitempty"`
+	// ExpiresAt is when the key stops being accepted, nil means never
+	ExpiresAt *time.Time `yaml:"expires_at,omitempty"`
+}
+
+// Expired reports whether the key has an expiry at or before now
+func (k APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
 }
 
 // APIKeysConfig represents the configuration file structure
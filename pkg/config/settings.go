@@ -0,0 +1,598 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gopkg.in/yaml.v3"
+
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/image"
+	"github.com/lissto-dev/api/pkg/notify"
+	"github.com/lissto-dev/api/pkg/postprocessor"
+	"github.com/lissto-dev/api/pkg/preprocessor"
+)
+
+// Settings holds API-only options that are not part of the shared operator config.
+// They live in the same config file, under the `api` section next to `server`:
+//
+//	api:
+//	  server: ...
+//	  exec:
+//	    enabled: true
+type Settings struct {
+	Exec       ExecSettings      `yaml:"exec"`
+	Detailed   DetailedSettings  `yaml:"detailed"`
+	Namespaces NamespaceSettings `yaml:"namespaces"`
+	Images     ImageSettings     `yaml:"images"`
+	TLS        TLSSettings       `yaml:"tls"`
+	Values     ValueSettings     `yaml:"values"`
+	Manifests  ManifestSettings  `yaml:"manifests"`
+	Resources  ResourceSettings  `yaml:"resources"`
+	Ingress    IngressSettings   `yaml:"ingress"`
+	Compose    ComposeSettings   `yaml:"compose"`
+	Prepull    PrepullSettings   `yaml:"prepull"`
+	Cache      CacheSettings     `yaml:"cache"`
+	Prepare    PrepareSettings   `yaml:"prepare"`
+	Signatures SignatureSettings `yaml:"signatures"`
+	// Roles controls which shared namespaces each role (admin, deploy, user) reads
+	Roles map[string]RoleSettings `yaml:"roles"`
+	// Webhooks receive stack created/updated/deleted events
+	Webhooks []notify.WebhookTarget `yaml:"webhooks"`
+}
+
+// ExecSettings controls the stack exec proxy
+type ExecSettings struct {
+	// Enabled turns on POST /stacks/:id/exec (disabled by default, it grants shell-level access)
+	Enabled bool `yaml:"enabled"`
+}
+
+// DetailedSettings controls ?format=detailed responses
+type DetailedSettings struct {
+	// StripPrefixes lists label/annotation key prefixes hidden from detailed responses
+	// Unset keeps the built-in defaults, an empty list disables stripping; lissto.dev/ keys are always kept
+	StripPrefixes []string `yaml:"stripPrefixes"`
+}
+
+// NamespaceSettings controls metadata applied to namespaces created by the API
+type NamespaceSettings struct {
+	// Labels are added to every managed namespace (e.g. cost-center); lissto's own labels take precedence
+	Labels map[string]string `yaml:"labels"`
+	// Annotations are added to every managed namespace
+	Annotations map[string]string `yaml:"annotations"`
+}
+
+// ImageSettings controls image resolution
+type ImageSettings struct {
+	// TagSources is the ordered list of tag candidates tried for services without an explicit image
+	// Valid sources: original, label, commit, branch, latest; omit latest to never fall back to it
+	TagSources []string `yaml:"tagSources"`
+	// Rewrites transform resolved image references before they are checked, first match wins
+	Rewrites []image.RewriteRule `yaml:"rewrites"`
+	// DefaultTag is the floating tag tried by the latest source (default "latest"), e.g. "stable"
+	// Blueprints can override it with x-lissto.defaultTag
+	DefaultTag string `yaml:"defaultTag"`
+	// RewriteExplicit also applies Rewrites to lissto.dev/image overrides and explicit image fields
+	RewriteExplicit bool `yaml:"rewriteExplicit"`
+	// UnpinnedFallback deploys a compose image tag without a digest when it cannot be pinned,
+	// instead of failing prepare. Off by default: unpinned tags may change under a running stack.
+	UnpinnedFallback bool `yaml:"unpinnedFallback"`
+}
+
+// TLSSettings enables native TLS for deployments not behind a TLS-terminating ingress
+// The server falls back to plain HTTP when no certificate is configured
+type TLSSettings struct {
+	// CertFile and KeyFile are the PEM server certificate and key; both or neither must be set
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+	// ClientCAFile turns on mutual TLS: /api/v1 clients must present a certificate signed by this CA bundle,
+	// /health and /metrics stay reachable without one. API keys are still required on top of the client certificate
+	ClientCAFile string `yaml:"clientCAFile"`
+}
+
+// Enabled reports whether a server certificate is configured
+func (t TLSSettings) Enabled() bool {
+	return t.CertFile != "" || t.KeyFile != ""
+}
+
+// Validate checks that the TLS options are consistent
+func (t TLSSettings) Validate() error {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return fmt.Errorf("certFile and keyFile must be set together")
+	}
+	if t.ClientCAFile != "" && !t.Enabled() {
+		return fmt.Errorf("clientCAFile requires certFile and keyFile")
+	}
+	return nil
+}
+
+// ValueSettings controls validation of variable and secret values
+type ValueSettings struct {
+	// MaxLength rejects values longer than this many bytes (0 disables)
+	// Requests can override it per key with validations.<key>.max_length
+	MaxLength int `yaml:"maxLength"`
+}
+
+// Default limits on the number of Kubernetes objects generated for one stack
+const (
+	DefaultWarnObjects = 250
+	DefaultMaxObjects  = 500
+)
+
+// ManifestSettings limits the Kubernetes objects generated for a stack
+type ManifestSettings struct {
+	// WarnObjects logs and reports a warning above this many objects (default 250)
+	WarnObjects int `yaml:"warnObjects"`
+	// MaxObjects rejects stacks generating more objects than this (default 500)
+	MaxObjects int `yaml:"maxObjects"`
+}
+
+// ObjectLimits returns the warning threshold and the cap, with defaults for unset values
+func (m ManifestSettings) ObjectLimits() (warn, max int) {
+	warn, max = m.WarnObjects, m.MaxObjects
+	if max == 0 {
+		max = DefaultMaxObjects
+	}
+	if warn == 0 {
+		warn = min(DefaultWarnObjects, max)
+	}
+	return warn, max
+}
+
+// Validate checks that the limits are positive and the warning comes before the cap
+func (m ManifestSettings) Validate() error {
+	if m.WarnObjects < 0 || m.MaxObjects < 0 {
+		return fmt.Errorf("warnObjects and maxObjects must not be negative")
+	}
+	if warn, max := m.ObjectLimits(); warn > max {
+		return fmt.Errorf("warnObjects (%d) must not exceed maxObjects (%d)", warn, max)
+	}
+	return nil
+}
+
+// ResourceSettings are the CPU/memory requests and limits given to containers that set none
+// (via lissto.dev resource labels or compose deploy.resources). Infra services (no build, or
+// lissto.dev/group data/infra/cache) use Infra, everything else App; unset values add nothing.
+type ResourceSettings struct {
+	App   postprocessor.ResourceDefaults `yaml:"app"`
+	Infra postprocessor.ResourceDefaults `yaml:"infra"`
+	// Floors are minimum replicas and memory requests enforced regardless of the blueprint
+	Floors FloorSettings `yaml:"floors"`
+}
+
+// FloorSettings are the resource floors of envs, services below them are raised when manifests are generated
+type FloorSettings struct {
+	// Default applies to envs without their own floor
+	Default compose.ResourceFloor `yaml:"default"`
+	// Envs maps an env name (e.g. production) to its floor, replacing the default
+	Envs map[string]compose.ResourceFloor `yaml:"envs"`
+}
+
+// For returns the floor of an env
+func (f FloorSettings) For(env string) compose.ResourceFloor {
+	if floor, ok := f.Envs[env]; ok {
+		return floor
+	}
+	return f.Default
+}
+
+// Validate checks both sets of defaults and the floors
+func (r ResourceSettings) Validate() error {
+	if err := r.App.Validate(); err != nil {
+		return fmt.Errorf("app: %w", err)
+	}
+	if err := r.Infra.Validate(); err != nil {
+		return fmt.Errorf("infra: %w", err)
+	}
+	if err := r.Floors.Default.Validate(); err != nil {
+		return fmt.Errorf("floors.default: %w", err)
+	}
+	for env, floor := range r.Floors.Envs {
+		if err := floor.Validate(); err != nil {
+			return fmt.Errorf("floors.envs.%s: %w", env, err)
+		}
+	}
+	return nil
+}
+
+// ApplyFloor raises the project's services below the env's floor to it, see compose.ApplyResourceFloor
+func (r ResourceSettings) ApplyFloor(project *types.Project, env string) []compose.FloorAdjustment {
+	return compose.ApplyResourceFloor(project, r.Floors.For(env), r.App.Requests.Memory, r.Infra.Requests.Memory)
+}
+
+// IngressSettings controls how the ingresses of exposed services get their TLS certificates
+type IngressSettings struct {
+	// TLSMode is secret (reference stacks.ingress.<visibility>.tlsSecret, the default) or cert-manager
+	// (cert-manager issues a certificate per service). Services can pick one with lissto.dev/tls-mode.
+	TLSMode string `yaml:"tlsMode"`
+	// ClusterIssuers maps a visibility (internal, internet) to the cert-manager ClusterIssuer for it
+	ClusterIssuers map[string]string `yaml:"clusterIssuers"`
+	// PreviewDomains are the parent domains hostnames may be put under with host_override_suffix
+	// (e.g. preview.example.com allows pr-123.preview.example.com); empty disables it
+	PreviewDomains []string `yaml:"previewDomains"`
+}
+
+// ClusterIssuer returns the cert-manager ClusterIssuer configured for a visibility, empty if none
+func (i IngressSettings) ClusterIssuer(visibility string) string {
+	return i.ClusterIssuers[visibility]
+}
+
+// Validate checks the TLS mode, the issuer visibilities and the preview domains
+func (i IngressSettings) Validate() error {
+	switch i.TLSMode {
+	case "", preprocessor.TLSModeSecret:
+	case preprocessor.TLSModeCertManager:
+		if len(i.ClusterIssuers) == 0 {
+			return fmt.Errorf("tlsMode %s requires clusterIssuers", preprocessor.TLSModeCertManager)
+		}
+	default:
+		return fmt.Errorf("unknown tlsMode %q (valid: %s, %s)", i.TLSMode, preprocessor.TLSModeSecret, preprocessor.TLSModeCertManager)
+	}
+	for visibility, issuer := range i.ClusterIssuers {
+		switch preprocessor.VisibilityType(visibility) {
+		case preprocessor.VisibilityInternal, preprocessor.VisibilityInternet:
+		default:
+			return fmt.Errorf("unknown clusterIssuers visibility %q (valid: internal, internet)", visibility)
+		}
+		if issuer == "" {
+			return fmt.Errorf("clusterIssuers.%s must not be empty", visibility)
+		}
+	}
+	for _, domain := range i.PreviewDomains {
+		if _, err := preprocessor.NormalizeHostSuffix(domain, []string{domain}); err != nil {
+			return fmt.Errorf("previewDomains: %w", err)
+		}
+	}
+	return nil
+}
+
+// DefaultPrepullTTLSeconds is how long finished prepull Jobs are kept when unset
+const DefaultPrepullTTLSeconds = 600
+
+// PrepullSettings controls POST /stacks/:id/prepull
+type PrepullSettings struct {
+	// Enabled turns on image pre-pulling Jobs (disabled by default)
+	Enabled bool `yaml:"enabled"`
+	// TTLSeconds deletes finished prepull Jobs after this many seconds (default 600)
+	TTLSeconds int32 `yaml:"ttlSeconds"`
+}
+
+// TTL returns the finished Job lifetime with the default applied
+func (p PrepullSettings) TTL() int32 {
+	if p.TTLSeconds == 0 {
+		return DefaultPrepullTTLSeconds
+	}
+	return p.TTLSeconds
+}
+
+// Validate checks that the TTL is not negative
+func (p PrepullSettings) Validate() error {
+	if p.TTLSeconds < 0 {
+		return fmt.Errorf("ttlSeconds must not be negative")
+	}
+	return nil
+}
+
+// Default in-memory cache limits, applied when unset
+const (
+	DefaultCacheMaxEntryBytes  = 1 << 20   // 1 MiB
+	DefaultCacheMaxMemoryBytes = 256 << 20 // 256 MiB
+)
+
+// CacheSettings bounds the in-memory cache holding prepare results and image digests
+type CacheSettings struct {
+	// MaxEntryBytes is the largest entry stored (default 1 MiB); larger prepare results are returned uncached
+	MaxEntryBytes int64 `yaml:"maxEntryBytes"`
+	// MaxMemoryBytes is the total cache budget (default 256 MiB); the oldest entries are evicted beyond it
+	MaxMemoryBytes int64 `yaml:"maxMemoryBytes"`
+}
+
+// EntryLimit returns the per-entry limit with the default applied
+func (c CacheSettings) EntryLimit() int64 {
+	if c.MaxEntryBytes == 0 {
+		return DefaultCacheMaxEntryBytes
+	}
+	return c.MaxEntryBytes
+}
+
+// MemoryLimit returns the total cache budget with the default applied
+func (c CacheSettings) MemoryLimit() int64 {
+	if c.MaxMemoryBytes == 0 {
+		return DefaultCacheMaxMemoryBytes
+	}
+	return c.MaxMemoryBytes
+}
+
+// Validate checks that the limits are not negative and that an entry fits in the budget
+func (c CacheSettings) Validate() error {
+	if c.MaxEntryBytes < 0 {
+		return fmt.Errorf("maxEntryBytes must not be negative")
+	}
+	if c.MaxMemoryBytes < 0 {
+		return fmt.Errorf("maxMemoryBytes must not be negative")
+	}
+	if c.EntryLimit() > c.MemoryLimit() {
+		return fmt.Errorf("maxEntryBytes (%d) must not exceed maxMemoryBytes (%d)", c.EntryLimit(), c.MemoryLimit())
+	}
+	return nil
+}
+
+// Default per-user prepare result quota, applied when unset
+const DefaultPrepareMaxResultsPerUser = 50
+
+// What PrepareStack does when a user holds as many prepare results as allowed
+const (
+	PrepareQuotaEvict  = "evict"  // Drop the user's oldest prepare result (default)
+	PrepareQuotaReject = "reject" // Reject the prepare with 429
+)
+
+// PrepareSettings bounds the unexpired prepare results a single user holds in the cache
+type PrepareSettings struct {
+	// MaxResultsPerUser is the per-user cap (default 50)
+	MaxResultsPerUser int `yaml:"maxResultsPerUser"`
+	// AdminMaxResults is the cap for admins; unset exempts them
+	AdminMaxResults int `yaml:"adminMaxResults"`
+	// OnLimit is evict (default) or reject
+	OnLimit string `yaml:"onLimit"`
+	// SharedResultTTLSeconds reuses the resolved images of an identical prepare (same compose, commit,
+	// branch and env) for this many seconds, across users; unset disables sharing
+	SharedResultTTLSeconds int `yaml:"sharedResultTTLSeconds"`
+}
+
+// SharedResultTTL returns how long resolved images are shared, 0 when sharing is disabled
+func (p PrepareSettings) SharedResultTTL() time.Duration {
+	return time.Duration(p.SharedResultTTLSeconds) * time.Second
+}
+
+// ResultLimit returns the cap for a role with the default applied, 0 meaning unlimited
+func (p PrepareSettings) ResultLimit(role string) int {
+	if role == "admin" {
+		return p.AdminMaxResults
+	}
+	if p.MaxResultsPerUser == 0 {
+		return DefaultPrepareMaxResultsPerUser
+	}
+	return p.MaxResultsPerUser
+}
+
+// RejectsOnLimit reports whether prepares beyond the cap are rejected instead of evicting
+func (p PrepareSettings) RejectsOnLimit() bool {
+	return p.OnLimit == PrepareQuotaReject
+}
+
+// Validate checks that the caps and the sharing TTL are not negative and the limit action is known
+func (p PrepareSettings) Validate() error {
+	if p.MaxResultsPerUser < 0 {
+		return fmt.Errorf("maxResultsPerUser must not be negative")
+	}
+	if p.AdminMaxResults < 0 {
+		return fmt.Errorf("adminMaxResults must not be negative")
+	}
+	if p.SharedResultTTLSeconds < 0 {
+		return fmt.Errorf("sharedResultTTLSeconds must not be negative")
+	}
+	switch p.OnLimit {
+	case "", PrepareQuotaEvict, PrepareQuotaReject:
+	default:
+		return fmt.Errorf("unknown onLimit %q (valid: %s, %s)", p.OnLimit, PrepareQuotaEvict, PrepareQuotaReject)
+	}
+	return nil
+}
+
+// SignatureSettings controls cosign signature verification of resolved images
+type SignatureSettings struct {
+	// Keys are PEM files of trusted cosign public keys
+	Keys []string `yaml:"keys"`
+	// Roots is a PEM file of CA certificates keyless signing certificates must chain to (e.g. Fulcio's)
+	Roots string `yaml:"roots"`
+	// Issuers are the OIDC issuers trusted for keyless signatures and who may sign with each, required with roots
+	Issuers []SignatureIssuer `yaml:"issuers"`
+	// RekorKeys are PEM files of transparency log public keys, required with roots. Keyless signatures are
+	// only trusted with a log entry signed by one of them, which proves their certificate was valid at signing
+	RekorKeys []string `yaml:"rekorKeys"`
+	// Required rejects every unverified image at stack creation, not only lissto.dev/require-signature services
+	Required bool `yaml:"required"`
+}
+
+// SignatureIssuer is an OIDC issuer trusted for keyless signatures
+type SignatureIssuer struct {
+	Issuer string `yaml:"issuer"` // e.g. https://token.actions.githubusercontent.com
+	// Identities are the certificate emails or URIs allowed to sign, path.Match patterns where * does not match /
+	// (e.g. https://github.com/acme/web/.github/workflows/release.yml@refs/tags/*)
+	Identities []string `yaml:"identities"`
+}
+
+// KeylessIdentities maps each trusted issuer to its allowed signer identities
+func (s SignatureSettings) KeylessIdentities() map[string][]string {
+	identities := make(map[string][]string, len(s.Issuers))
+	for _, issuer := range s.Issuers {
+		identities[issuer.Issuer] = append(identities[issuer.Issuer], issuer.Identities...)
+	}
+	return identities
+}
+
+// Enabled reports whether prepare verifies image signatures
+func (s SignatureSettings) Enabled() bool {
+	return len(s.Keys) > 0 || s.Roots != ""
+}
+
+// Validate checks that keyless verification has roots and issuers and that required has something to verify with
+func (s SignatureSettings) Validate() error {
+	if (s.Roots == "") != (len(s.Issuers) == 0) {
+		return fmt.Errorf("roots and issuers must be set together")
+	}
+	if s.Roots != "" && len(s.RekorKeys) == 0 {
+		return fmt.Errorf("roots require rekorKeys to check when keyless signatures were made")
+	}
+	for _, issuer := range s.Issuers {
+		if issuer.Issuer == "" {
+			return fmt.Errorf("issuers need an issuer URL")
+		}
+		if len(issuer.Identities) == 0 {
+			return fmt.Errorf("issuer %s needs the identities allowed to sign", issuer.Issuer)
+		}
+		for _, identity := range issuer.Identities {
+			if _, err := path.Match(identity, ""); err != nil {
+				return fmt.Errorf("invalid identity pattern %q of issuer %s: %w", identity, issuer.Issuer, err)
+			}
+		}
+	}
+	if s.Required && !s.Enabled() {
+		return fmt.Errorf("required needs keys or roots")
+	}
+	return nil
+}
+
+// ComposeSettings controls which compose features stacks may use
+type ComposeSettings struct {
+	// DeniedFeatures lists compose features rejected at prepare and deploy time
+	// (privileged, host_network, host_pid, host_ipc, host_path_volumes, cap_add, devices)
+	DeniedFeatures []string `yaml:"deniedFeatures"`
+}
+
+// Validate checks that every denied feature is known
+func (c ComposeSettings) Validate() error {
+	if err := compose.ValidatePolicyFeatures(c.DeniedFeatures); err != nil {
+		return fmt.Errorf("deniedFeatures: %w", err)
+	}
+	return nil
+}
+
+// RoleSettings controls the shared namespaces a role sees besides its own
+type RoleSettings struct {
+	// GlobalRead lists global variables, secrets and blueprints for the role (default true)
+	GlobalRead *bool `yaml:"globalRead"`
+	// ReadNamespaces are extra namespaces the role may read and list
+	ReadNamespaces []string `yaml:"readNamespaces"`
+	// HostOverride lets the role set host_override_suffix within ingress.previewDomains (admins always may)
+	HostOverride bool `yaml:"hostOverride"`
+	// AllowedLabels restricts the lissto.dev/ service labels the role may use to these patterns
+	// (path.Match syntax, e.g. lissto.dev/expose*); unset allows every label not denied. Admins are never restricted.
+	AllowedLabels []string `yaml:"allowedLabels"`
+	// DeniedLabels lists lissto.dev/ label patterns the role may not use, even when allowed
+	DeniedLabels []string `yaml:"deniedLabels"`
+	// OnDisallowedLabel is reject (default) or strip, which removes the labels with a prepare warning
+	OnDisallowedLabel string `yaml:"onDisallowedLabel"`
+}
+
+// Actions on service labels disallowed by a role's label policy
+const (
+	LabelPolicyReject = "reject"
+	LabelPolicyStrip  = "strip"
+)
+
+// StripsDisallowedLabels reports whether disallowed labels are removed instead of rejected
+func (r RoleSettings) StripsDisallowedLabels() bool {
+	return r.OnDisallowedLabel == LabelPolicyStrip
+}
+
+// GlobalReadEnabled reports whether the role reads the global namespace, true when unset
+func (r RoleSettings) GlobalReadEnabled() bool {
+	return r.GlobalRead == nil || *r.GlobalRead
+}
+
+// validateRoles checks role names and their extra read namespaces
+func validateRoles(roles map[string]RoleSettings) error {
+	for name, role := range roles {
+		switch name {
+		case "admin", "deploy", "user":
+		default:
+			return fmt.Errorf("unknown role %q (valid: admin, deploy, user)", name)
+		}
+		for _, ns := range role.ReadNamespaces {
+			if ns == "" {
+				return fmt.Errorf("role %q: readNamespaces must not contain empty names", name)
+			}
+		}
+		if err := compose.ValidateLabelPatterns(role.AllowedLabels); err != nil {
+			return fmt.Errorf("role %q: allowedLabels: %w", name, err)
+		}
+		if err := compose.ValidateLabelPatterns(role.DeniedLabels); err != nil {
+			return fmt.Errorf("role %q: deniedLabels: %w", name, err)
+		}
+		switch role.OnDisallowedLabel {
+		case "", LabelPolicyReject, LabelPolicyStrip:
+		default:
+			return fmt.Errorf("role %q: unknown onDisallowedLabel %q (valid: %s, %s)", name, role.OnDisallowedLabel, LabelPolicyReject, LabelPolicyStrip)
+		}
+	}
+	return nil
+}
+
+// settingsFile mirrors the config file layout down to the API section
+type settingsFile struct {
+	API Settings `yaml:"api"`
+}
+
+// DefaultSettings returns settings with every optional feature disabled
+func DefaultSettings() *Settings {
+	return &Settings{}
+}
+
+// LoadSettings loads API settings from the shared config file
+func LoadSettings(path string) (*Settings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	file := settingsFile{API: *DefaultSettings()}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse API settings: %w", err)
+	}
+
+	if len(file.API.Images.TagSources) > 0 {
+		if err := image.ValidateTagSources(file.API.Images.TagSources); err != nil {
+			return nil, fmt.Errorf("invalid api.images.tagSources: %w", err)
+		}
+	}
+	if file.API.Images.DefaultTag != "" {
+		if err := image.ValidateTag(file.API.Images.DefaultTag); err != nil {
+			return nil, fmt.Errorf("invalid api.images.defaultTag: %w", err)
+		}
+	}
+	if err := image.ValidateRewriteRules(file.API.Images.Rewrites); err != nil {
+		return nil, fmt.Errorf("invalid api.images.rewrites: %w", err)
+	}
+	if err := notify.ValidateWebhookTargets(file.API.Webhooks); err != nil {
+		return nil, fmt.Errorf("invalid api.webhooks: %w", err)
+	}
+	if err := file.API.TLS.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid api.tls: %w", err)
+	}
+	if file.API.Values.MaxLength < 0 {
+		return nil, fmt.Errorf("invalid api.values.maxLength: must not be negative")
+	}
+	if err := file.API.Manifests.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid api.manifests: %w", err)
+	}
+	if err := file.API.Resources.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid api.resources: %w", err)
+	}
+	if err := file.API.Ingress.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid api.ingress: %w", err)
+	}
+	if err := file.API.Compose.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid api.compose: %w", err)
+	}
+	if err := file.API.Prepull.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid api.prepull: %w", err)
+	}
+	if err := file.API.Cache.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid api.cache: %w", err)
+	}
+	if err := file.API.Prepare.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid api.prepare: %w", err)
+	}
+	if err := file.API.Signatures.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid api.signatures: %w", err)
+	}
+	if err := validateRoles(file.API.Roles); err != nil {
+		return nil, fmt.Errorf("invalid api.roles: %w", err)
+	}
+
+	return &file.API, nil
+}
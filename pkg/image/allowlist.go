@@ -0,0 +1,37 @@
+package image
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// ErrDigestNotAllowed is returned for a resolved image missing from a digest allowlist
+var ErrDigestNotAllowed = errors.New("digest not in allowlist")
+
+// CheckAllowedDigest checks a resolved image against an allowlist of digests per image repository
+// Repositories are compared normalized (postgres and docker.io/library/postgres are the same). With an
+// allowlist set, images that are not pinned to a digest or whose repository is not listed are rejected too.
+func CheckAllowedDigest(imageRef string, allowed map[string][]string) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+	digest, err := name.NewDigest(imageRef)
+	if err != nil {
+		return fmt.Errorf("%w: %s is not pinned to a digest", ErrDigestNotAllowed, imageRef)
+	}
+	repository := digest.Context().Name()
+	for listed, digests := range allowed {
+		listedRepository, err := name.NewRepository(listed)
+		if err != nil || listedRepository.Name() != repository {
+			continue
+		}
+		if slices.Contains(digests, digest.DigestStr()) {
+			return nil
+		}
+		return fmt.Errorf("%w: %s is not an approved digest of %s", ErrDigestNotAllowed, digest.DigestStr(), listed)
+	}
+	return fmt.Errorf("%w: repository of %s has no approved digests", ErrDigestNotAllowed, imageRef)
+}
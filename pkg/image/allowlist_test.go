@@ -0,0 +1,42 @@
+package image_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+var _ = Describe("CheckAllowedDigest", func() {
+	const (
+		approved = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		repushed = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+	allowed := map[string][]string{
+		"registry.acme.io/web": {approved},
+		"postgres":             {approved},
+	}
+
+	It("should accept approved digests", func() {
+		Expect(image.CheckAllowedDigest("registry.acme.io/web@"+approved, allowed)).To(Succeed())
+	})
+
+	It("should compare normalized repositories", func() {
+		Expect(image.CheckAllowedDigest("docker.io/library/postgres@"+approved, allowed)).To(Succeed())
+	})
+
+	It("should reject other digests of a listed repository", func() {
+		err := image.CheckAllowedDigest("registry.acme.io/web@"+repushed, allowed)
+		Expect(err).To(MatchError(image.ErrDigestNotAllowed))
+		Expect(err).To(MatchError(ContainSubstring(repushed)))
+	})
+
+	It("should reject unlisted repositories and unpinned images", func() {
+		Expect(image.CheckAllowedDigest("registry.acme.io/api@"+approved, allowed)).To(MatchError(image.ErrDigestNotAllowed))
+		Expect(image.CheckAllowedDigest("registry.acme.io/web:1.0", allowed)).To(MatchError(image.ErrDigestNotAllowed))
+	})
+
+	It("should accept anything without an allowlist", func() {
+		Expect(image.CheckAllowedDigest("registry.acme.io/web:1.0", nil)).To(Succeed())
+	})
+})
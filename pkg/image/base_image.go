@@ -0,0 +1,15 @@
+package image
+
+import "github.com/compose-spec/compose-go/v2/types"
+
+// BaseImageLabel names the base image (FROM) of a build service, e.g. "node:20-alpine"
+// The API never sees the Dockerfile, so provenance of the base layer has to be declared
+const BaseImageLabel = "lissto.dev/base-image"
+
+// BaseImage returns the declared base image of a build service, or "" if there is none
+func BaseImage(service types.ServiceConfig) string {
+	if service.Build == nil || service.Labels == nil {
+		return ""
+	}
+	return service.Labels[BaseImageLabel]
+}
@@ -0,0 +1,350 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// Registry circuit breaker defaults
+const (
+	DefaultBreakerThreshold = 5                // Consecutive registry failures that open the breaker
+	DefaultBreakerCooldown  = 30 * time.Second // Time an open breaker fails fast before probing again
+	DefaultRetryAttempts    = 2                // Attempts per registry call, including the first
+	DefaultRetryBackoff     = 200 * time.Millisecond
+)
+
+// ErrRegistryUnavailable matches RegistryUnavailableError with errors.Is
+var ErrRegistryUnavailable = errors.New("registry unavailable")
+
+// RegistryUnavailableError is returned without contacting a registry whose breaker is open
+type RegistryUnavailableError struct {
+	Registry   string
+	RetryAfter time.Duration // Remaining cooldown, zero while a recovery probe is in flight
+}
+
+func (e *RegistryUnavailableError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("registry unavailable: %s is failing, retrying in %s", e.Registry, e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("registry unavailable: %s is failing, recovery check in progress", e.Registry)
+}
+
+// Is makes errors.Is(err, ErrRegistryUnavailable) match
+func (e *RegistryUnavailableError) Is(target error) bool {
+	return target == ErrRegistryUnavailable
+}
+
+// BreakerState is the state of a registry's circuit breaker
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // Calls go through
+	BreakerHalfOpen                     // One probe call tests whether the registry recovered
+	BreakerOpen                         // Calls fail fast until the cooldown ends
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerHalfOpen:
+		return "half-open"
+	case BreakerOpen:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+// BreakerObserver is notified of breaker state changes and fail-fast rejections, e.g. to export metrics
+type BreakerObserver interface {
+	BreakerStateChanged(registry string, state BreakerState)
+	BreakerRejected(registry string)
+}
+
+// registryCircuit is the breaker state of one registry host
+type registryCircuit struct {
+	state    BreakerState
+	failures int
+	openedAt time.Time
+}
+
+// RegistryBreaker is a circuit breaker keyed per registry host
+// After threshold consecutive failures a host's breaker opens and calls fail fast for the cooldown,
+// then a single probe call is let through: success closes the breaker, failure opens it again
+type RegistryBreaker struct {
+	mu        sync.Mutex
+	circuits  map[string]*registryCircuit
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	observer  BreakerObserver
+}
+
+// DefaultRegistryBreaker is shared by the image resolvers so every handler sees the same registry health
+var DefaultRegistryBreaker = NewRegistryBreaker(DefaultBreakerThreshold, DefaultBreakerCooldown)
+
+// NewRegistryBreaker creates a breaker that opens after threshold consecutive failures for cooldown
+func NewRegistryBreaker(threshold int, cooldown time.Duration) *RegistryBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &RegistryBreaker{
+		circuits:  make(map[string]*registryCircuit),
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// SetClock replaces the time source (tests)
+func (b *RegistryBreaker) SetClock(now func() time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.now = now
+}
+
+// SetObserver registers the observer notified of state changes and rejections
+func (b *RegistryBreaker) SetObserver(observer BreakerObserver) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.observer = observer
+}
+
+// State returns the breaker state of a registry host
+func (b *RegistryBreaker) State(registry string) BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if circuit, ok := b.circuits[registry]; ok {
+		return circuit.state
+	}
+	return BreakerClosed
+}
+
+// Allow reports whether a call to registry may proceed, returning a RegistryUnavailableError if not
+// An open breaker whose cooldown has ended lets exactly one probe call through
+func (b *RegistryBreaker) Allow(registry string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	circuit, ok := b.circuits[registry]
+	if !ok {
+		return nil
+	}
+
+	switch circuit.state {
+	case BreakerOpen:
+		remaining := b.cooldown - b.now().Sub(circuit.openedAt)
+		if remaining > 0 {
+			b.reject(registry)
+			return &RegistryUnavailableError{Registry: registry, RetryAfter: remaining}
+		}
+		b.setState(registry, circuit, BreakerHalfOpen)
+		return nil
+	case BreakerHalfOpen:
+		b.reject(registry)
+		return &RegistryUnavailableError{Registry: registry}
+	default:
+		return nil
+	}
+}
+
+// RecordSuccess closes the breaker of a registry that answered
+func (b *RegistryBreaker) RecordSuccess(registry string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	circuit, ok := b.circuits[registry]
+	if !ok {
+		return
+	}
+	circuit.failures = 0
+	b.setState(registry, circuit, BreakerClosed)
+}
+
+// RecordFailure counts a registry failure, opening the breaker at the threshold or after a failed probe
+func (b *RegistryBreaker) RecordFailure(registry string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	circuit, ok := b.circuits[registry]
+	if !ok {
+		circuit = &registryCircuit{}
+		b.circuits[registry] = circuit
+	}
+	circuit.failures++
+
+	if circuit.state == BreakerHalfOpen || circuit.failures >= b.threshold {
+		circuit.openedAt = b.now()
+		if circuit.state != BreakerOpen {
+			logging.Logger.Warn("Registry circuit breaker opened",
+				zap.String("registry", registry),
+				zap.Int("consecutive_failures", circuit.failures),
+				zap.Duration("cooldown", b.cooldown))
+		}
+		b.setState(registry, circuit, BreakerOpen)
+	}
+}
+
+// setState updates a circuit and notifies the observer; callers hold the lock
+func (b *RegistryBreaker) setState(registry string, circuit *registryCircuit, state BreakerState) {
+	if circuit.state == state {
+		return
+	}
+	if state == BreakerClosed {
+		logging.Logger.Info("Registry circuit breaker closed", zap.String("registry", registry))
+	}
+	circuit.state = state
+	if b.observer != nil {
+		b.observer.BreakerStateChanged(registry, state)
+	}
+}
+
+// reject notifies the observer of a fail-fast call; callers hold the lock
+func (b *RegistryBreaker) reject(registry string) {
+	if b.observer != nil {
+		b.observer.BreakerRejected(registry)
+	}
+}
+
+// RegistryHost returns the registry host of an image reference (docker.io for Docker Hub)
+func RegistryHost(imageURL string) string {
+	ref, err := name.ParseReference(imageURL)
+	if err != nil {
+		return ""
+	}
+	return ref.Context().RegistryStr()
+}
+
+// unexpectedStatusPattern matches the server errors containers/image reports as text
+var unexpectedStatusPattern = regexp.MustCompile(`(?i)(unexpected http status|status ?code):? (5\d\d|429)`)
+
+// IsRegistryFailure reports whether err means the registry is unreachable or failing,
+// as opposed to answering that an image doesn't exist or access is denied
+func IsRegistryFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var transportErr *transport.Error
+	if errors.As(err, &transportErr) {
+		return transportErr.StatusCode >= http.StatusInternalServerError ||
+			transportErr.StatusCode == http.StatusTooManyRequests
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return unexpectedStatusPattern.MatchString(err.Error())
+}
+
+// BreakerChecker guards an image checker with retries and a per-registry circuit breaker
+type BreakerChecker struct {
+	checker        ImageChecker
+	breaker        *RegistryBreaker
+	maxAttempts    int
+	initialBackoff time.Duration
+}
+
+// NewBreakerChecker wraps checker; registry failures are retried with doubling backoff
+// before counting against the registry's breaker
+func NewBreakerChecker(checker ImageChecker, breaker *RegistryBreaker) *BreakerChecker {
+	return &BreakerChecker{
+		checker:        checker,
+		breaker:        breaker,
+		maxAttempts:    DefaultRetryAttempts,
+		initialBackoff: DefaultRetryBackoff,
+	}
+}
+
+// SetRetry configures the number of attempts per call and the delay before the first retry
+// The delay doubles after every failed attempt
+func (bc *BreakerChecker) SetRetry(maxAttempts int, initialBackoff time.Duration) {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	bc.maxAttempts = maxAttempts
+	bc.initialBackoff = initialBackoff
+}
+
+// CheckImageExists implements ImageChecker
+func (bc *BreakerChecker) CheckImageExists(imageURL string) (*ImageMetadata, error) {
+	return bc.guard(imageURL, func() (*ImageMetadata, error) {
+		return bc.checker.CheckImageExists(imageURL)
+	})
+}
+
+// CheckImageExistsForPlatform implements ImageChecker
+func (bc *BreakerChecker) CheckImageExistsForPlatform(imageURL, os, arch string) (*ImageMetadata, error) {
+	return bc.guard(imageURL, func() (*ImageMetadata, error) {
+		return bc.checker.CheckImageExistsForPlatform(imageURL, os, arch)
+	})
+}
+
+// WithCredential returns a copy checking with cred that shares the breaker
+// Fails when the wrapped checker doesn't support credentials, like ImageResolver.WithCredential
+func (bc *BreakerChecker) WithCredential(cred RegistryCredential) ImageChecker {
+	credentialChecker, ok := bc.checker.(CredentialChecker)
+	if !ok {
+		return &unsupportedCredentialChecker{}
+	}
+	checker := *bc
+	checker.checker = credentialChecker.WithCredential(cred)
+	return &checker
+}
+
+// guard runs check unless the registry's breaker is open, retrying and recording registry failures
+func (bc *BreakerChecker) guard(imageURL string, check func() (*ImageMetadata, error)) (*ImageMetadata, error) {
+	registry := RegistryHost(imageURL)
+	if registry == "" {
+		return check()
+	}
+	if err := bc.breaker.Allow(registry); err != nil {
+		return &ImageMetadata{Exists: false}, err
+	}
+
+	backoff := bc.initialBackoff
+	for attempt := 1; ; attempt++ {
+		metadata, err := check()
+		if !IsRegistryFailure(err) {
+			bc.breaker.RecordSuccess(registry)
+			return metadata, err
+		}
+		if attempt >= bc.maxAttempts {
+			bc.breaker.RecordFailure(registry)
+			return metadata, err
+		}
+
+		logging.Logger.Debug("Registry call failed, retrying",
+			zap.String("registry", registry),
+			zap.String("image", imageURL),
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", backoff),
+			zap.Error(err))
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// unsupportedCredentialChecker fails every check of a credential the wrapped checker can't use
+type unsupportedCredentialChecker struct{}
+
+func (unsupportedCredentialChecker) CheckImageExists(string) (*ImageMetadata, error) {
+	return &ImageMetadata{Exists: false}, fmt.Errorf("image checker does not support registry credentials")
+}
+
+func (unsupportedCredentialChecker) CheckImageExistsForPlatform(string, string, string) (*ImageMetadata, error) {
+	return &ImageMetadata{Exists: false}, fmt.Errorf("image checker does not support registry credentials")
+}
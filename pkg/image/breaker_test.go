@@ -0,0 +1,216 @@
+package image_test
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+// flakyChecker fails with a registry error while down, otherwise answers from the mock
+type flakyChecker struct {
+	*MockImageChecker
+	down  bool
+	calls int
+}
+
+func (f *flakyChecker) CheckImageExists(imageURL string) (*image.ImageMetadata, error) {
+	return f.CheckImageExistsForPlatform(imageURL, "linux", "amd64")
+}
+
+func (f *flakyChecker) CheckImageExistsForPlatform(imageURL, os, arch string) (*image.ImageMetadata, error) {
+	f.calls++
+	if f.down {
+		return &image.ImageMetadata{Exists: false}, &transport.Error{StatusCode: 503}
+	}
+	return f.MockImageChecker.CheckImageExistsForPlatform(imageURL, os, arch)
+}
+
+// breakerObserver records the observed states and rejections
+type breakerObserver struct {
+	states     []image.BreakerState
+	rejections int
+}
+
+func (o *breakerObserver) BreakerStateChanged(_ string, state image.BreakerState) {
+	o.states = append(o.states, state)
+}
+
+func (o *breakerObserver) BreakerRejected(string) {
+	o.rejections++
+}
+
+var _ = Describe("RegistryBreaker", func() {
+	var (
+		flaky    *flakyChecker
+		breaker  *image.RegistryBreaker
+		checker  *image.BreakerChecker
+		observer *breakerObserver
+		now      time.Time
+	)
+
+	BeforeEach(func() {
+		mock := NewMockImageChecker()
+		mock.AddResponse("ghcr.io/acme/api:v1", "linux", "amd64", "sha256:abc")
+		flaky = &flakyChecker{MockImageChecker: mock}
+
+		now = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		observer = &breakerObserver{}
+		breaker = image.NewRegistryBreaker(3, time.Minute)
+		breaker.SetClock(func() time.Time { return now })
+		breaker.SetObserver(observer)
+
+		checker = image.NewBreakerChecker(flaky, breaker)
+		checker.SetRetry(1, 0)
+	})
+
+	It("opens after consecutive failures and fails fast", func() {
+		flaky.down = true
+		for i := 0; i < 3; i++ {
+			_, err := checker.CheckImageExists("ghcr.io/acme/api:v1")
+			Expect(err).To(HaveOccurred())
+			Expect(errors.Is(err, image.ErrRegistryUnavailable)).To(BeFalse())
+		}
+		Expect(breaker.State("ghcr.io")).To(Equal(image.BreakerOpen))
+
+		_, err := checker.CheckImageExists("ghcr.io/acme/api:v1")
+		Expect(errors.Is(err, image.ErrRegistryUnavailable)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("registry unavailable: ghcr.io"))
+		Expect(flaky.calls).To(Equal(3))
+		Expect(observer.states).To(Equal([]image.BreakerState{image.BreakerOpen}))
+		Expect(observer.rejections).To(Equal(1))
+	})
+
+	It("keeps other registries closed", func() {
+		flaky.down = true
+		for i := 0; i < 3; i++ {
+			_, _ = checker.CheckImageExists("ghcr.io/acme/api:v1")
+		}
+
+		_, err := checker.CheckImageExists("quay.io/acme/api:v1")
+		Expect(errors.Is(err, image.ErrRegistryUnavailable)).To(BeFalse())
+		Expect(breaker.State("quay.io")).To(Equal(image.BreakerClosed))
+	})
+
+	It("does not count missing images as failures", func() {
+		for i := 0; i < 5; i++ {
+			metadata, err := checker.CheckImageExists("ghcr.io/acme/api:missing")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(metadata.Exists).To(BeFalse())
+		}
+		Expect(breaker.State("ghcr.io")).To(Equal(image.BreakerClosed))
+	})
+
+	It("resets the failure count after a success", func() {
+		flaky.down = true
+		_, _ = checker.CheckImageExists("ghcr.io/acme/api:v1")
+		_, _ = checker.CheckImageExists("ghcr.io/acme/api:v1")
+		flaky.down = false
+		_, _ = checker.CheckImageExists("ghcr.io/acme/api:v1")
+		flaky.down = true
+		_, _ = checker.CheckImageExists("ghcr.io/acme/api:v1")
+		_, _ = checker.CheckImageExists("ghcr.io/acme/api:v1")
+
+		Expect(breaker.State("ghcr.io")).To(Equal(image.BreakerClosed))
+	})
+
+	It("closes after a successful probe once the cooldown ends", func() {
+		flaky.down = true
+		for i := 0; i < 3; i++ {
+			_, _ = checker.CheckImageExists("ghcr.io/acme/api:v1")
+		}
+		flaky.down = false
+
+		now = now.Add(30 * time.Second)
+		_, err := checker.CheckImageExists("ghcr.io/acme/api:v1")
+		Expect(errors.Is(err, image.ErrRegistryUnavailable)).To(BeTrue())
+
+		now = now.Add(31 * time.Second)
+		metadata, err := checker.CheckImageExists("ghcr.io/acme/api:v1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metadata.Exists).To(BeTrue())
+		Expect(breaker.State("ghcr.io")).To(Equal(image.BreakerClosed))
+		Expect(observer.states).To(Equal([]image.BreakerState{
+			image.BreakerOpen, image.BreakerHalfOpen, image.BreakerClosed,
+		}))
+	})
+
+	It("reopens when the probe fails", func() {
+		flaky.down = true
+		for i := 0; i < 3; i++ {
+			_, _ = checker.CheckImageExists("ghcr.io/acme/api:v1")
+		}
+
+		now = now.Add(time.Minute)
+		_, err := checker.CheckImageExists("ghcr.io/acme/api:v1")
+		Expect(errors.Is(err, image.ErrRegistryUnavailable)).To(BeFalse())
+		Expect(breaker.State("ghcr.io")).To(Equal(image.BreakerOpen))
+
+		_, err = checker.CheckImageExists("ghcr.io/acme/api:v1")
+		Expect(errors.Is(err, image.ErrRegistryUnavailable)).To(BeTrue())
+	})
+
+	It("lets a single probe through while half-open", func() {
+		flaky.down = true
+		for i := 0; i < 3; i++ {
+			_, _ = checker.CheckImageExists("ghcr.io/acme/api:v1")
+		}
+
+		now = now.Add(time.Minute)
+		Expect(breaker.Allow("ghcr.io")).To(Succeed())
+		Expect(breaker.State("ghcr.io")).To(Equal(image.BreakerHalfOpen))
+		Expect(errors.Is(breaker.Allow("ghcr.io"), image.ErrRegistryUnavailable)).To(BeTrue())
+	})
+
+	It("retries registry failures before counting one", func() {
+		flaky.down = true
+		checker.SetRetry(3, time.Millisecond)
+
+		_, err := checker.CheckImageExists("ghcr.io/acme/api:v1")
+		Expect(err).To(HaveOccurred())
+		Expect(flaky.calls).To(Equal(3))
+		Expect(breaker.State("ghcr.io")).To(Equal(image.BreakerClosed))
+	})
+
+	It("surfaces an open breaker from image resolution", func() {
+		flaky.down = true
+		for i := 0; i < 3; i++ {
+			_, _ = checker.CheckImageExists("ghcr.io/acme/api:v1")
+		}
+
+		resolver := image.NewImageResolver("", "", checker)
+		_, err := resolver.ResolveImageWithCandidates(types.ServiceConfig{
+			Name:  "api",
+			Build: &types.BuildConfig{},
+			Labels: types.Labels{
+				"lissto.dev/registry":   "ghcr.io",
+				"lissto.dev/repository": "acme/api",
+			},
+		}, image.ResolutionConfig{})
+		Expect(errors.Is(err, image.ErrRegistryUnavailable)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("no existing image found for service api"))
+	})
+})
+
+var _ = Describe("IsRegistryFailure", func() {
+	DescribeTable("classifies registry errors",
+		func(err error, expected bool) {
+			Expect(image.IsRegistryFailure(err)).To(Equal(expected))
+		},
+		Entry("nil", nil, false),
+		Entry("server error", &transport.Error{StatusCode: 502}, true),
+		Entry("rate limited", &transport.Error{StatusCode: 429}, true),
+		Entry("not found", &transport.Error{StatusCode: 404}, false),
+		Entry("unauthorized", &transport.Error{StatusCode: 401}, false),
+		Entry("connection refused", fmt.Errorf("get: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}), true),
+		Entry("containers/image status", errors.New("pinging container registry ghcr.io: received unexpected HTTP status: 503 Service Unavailable"), true),
+		Entry("manifest unknown", errors.New("reading manifest v1 in ghcr.io/acme/api: manifest unknown"), false),
+	)
+})
@@ -0,0 +1,68 @@
+package image_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+// ttlRecordingCache is a memory cache remembering the TTL of every entry set
+type ttlRecordingCache struct {
+	*cache.MemoryCache
+	ttls map[string]time.Duration
+}
+
+func (c *ttlRecordingCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	c.ttls[key] = ttl
+	return c.MemoryCache.Set(ctx, key, value, ttl)
+}
+
+var _ = Describe("Cache overrides", func() {
+	var (
+		resolver *image.ImageResolver
+		checker  *MockImageChecker
+		recorder *ttlRecordingCache
+		service  = types.ServiceConfig{Image: "myapp:v1.2.3", Build: &types.BuildConfig{Context: "."}}
+		cacheKey = image.GetCacheKey("myapp:v1.2.3", "linux", "amd64")
+	)
+
+	BeforeEach(func() {
+		checker = NewMockImageChecker()
+		checker.AddResponse("myapp:v1.2.3", "linux", "amd64", "sha256:xyz789myapp")
+		recorder = &ttlRecordingCache{MemoryCache: cache.NewMemoryCache(), ttls: map[string]time.Duration{}}
+		resolver = image.NewImageResolverWithCache("", "", checker, recorder)
+	})
+
+	resolve := func(r *image.ImageResolver) {
+		_, err := r.GetImageDigestWithCacheContext("myapp:v1.2.3", "linux", "amd64", service)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	It("should ask the registry despite a cached digest when skipping reads", func() {
+		resolve(resolver)
+		resolve(resolver)
+		Expect(checker.GetCallCount("myapp:v1.2.3", "linux", "amd64")).To(Equal(1))
+
+		resolve(resolver.WithCacheOverride(image.CacheOverride{SkipRead: true}))
+		Expect(checker.GetCallCount("myapp:v1.2.3", "linux", "amd64")).To(Equal(2))
+	})
+
+	It("should cache digests for a shorter override TTL", func() {
+		resolve(resolver.WithCacheOverride(image.CacheOverride{TTL: 5 * time.Minute}))
+
+		Expect(recorder.ttls).To(HaveKeyWithValue(cacheKey, 5*time.Minute))
+	})
+
+	It("should not cache digests longer than the default TTL", func() {
+		resolve(resolver.WithCacheOverride(image.CacheOverride{TTL: 720 * time.Hour}))
+
+		Expect(recorder.ttls).To(HaveKeyWithValue(cacheKey, image.GetTTL(false, "myapp:v1.2.3")))
+		Expect(recorder.ttls[cacheKey]).To(Equal(time.Hour))
+	})
+})
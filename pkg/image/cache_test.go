@@ -16,12 +16,16 @@ import (
 type MockImageChecker struct {
 	// Map of imageURL@platform -> digest for testing
 	responses map[string]string
-	callCount map[string]int // Track how many times each image was checked
+	multiArch map[string]bool   // Responses served from a manifest list
+	configs   map[string][]byte // Config blob per imageURL, empty if unset
+	callCount map[string]int    // Track how many times each image was checked
 }
 
 func NewMockImageChecker() *MockImageChecker {
 	return &MockImageChecker{
 		responses: make(map[string]string),
+		multiArch: make(map[string]bool),
+		configs:   make(map[string][]byte),
 		callCount: make(map[string]int),
 	}
 }
@@ -44,10 +48,10 @@ func (m *MockImageChecker) CheckImageExistsForPlatform(imageURL, os, arch string
 		Exists:          true,
 		Digest:          digest,
 		Manifest:        []byte{},
-		Config:          []byte{},
+		Config:          m.configs[imageURL],
 		Architectures:   []string{arch},
 		PlatformDigests: map[string]string{os + "/" + arch: digest},
-		IsMultiArch:     false,
+		IsMultiArch:     m.multiArch[key],
 		ManifestType:    "application/vnd.docker.distribution.manifest.v2+json",
 	}, nil
 }
@@ -62,6 +66,15 @@ func (m *MockImageChecker) AddResponse(imageURL, os, arch, digest string) {
 	m.responses[key] = digest
 }
 
+func (m *MockImageChecker) AddConfig(imageURL string, config []byte) {
+	m.configs[imageURL] = config
+}
+
+func (m *MockImageChecker) AddMultiArchResponse(imageURL, os, arch, digest string) {
+	m.AddResponse(imageURL, os, arch, digest)
+	m.multiArch[imageURL+"@"+os+"/"+arch] = true
+}
+
 var _ = Describe("Image Cache", func() {
 	Describe("IsInfraImage", func() {
 		Context("when service has image and no build", func() {
@@ -130,6 +130,10 @@ func (iec *ImageExistenceChecker) checkImageWithContainersImage(ctx context.Cont
 	// Create a source for the image
 	source, err := ref.NewImageSource(ctx, systemContext)
 	if err != nil {
+		// An unreachable or failing registry is reported so callers can tell it from a missing image
+		if IsRegistryFailure(err) {
+			return &ImageMetadata{Exists: false}, fmt.Errorf("registry request failed: %w", err)
+		}
 		logging.Logger.Debug("Image source creation failed (image likely doesn't exist)",
 			zap.String("image", imageURL),
 			zap.Error(err))
@@ -140,6 +144,9 @@ func (iec *ImageExistenceChecker) checkImageWithContainersImage(ctx context.Cont
 	// Get the image manifest
 	manifestBytes, manifestType, err := source.GetManifest(ctx, nil)
 	if err != nil {
+		if IsRegistryFailure(err) {
+			return &ImageMetadata{Exists: false}, fmt.Errorf("registry request failed: %w", err)
+		}
 		logging.Logger.Debug("Failed to get manifest (image likely doesn't exist)",
 			zap.String("image", imageURL),
 			zap.Error(err))
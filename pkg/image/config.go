@@ -0,0 +1,57 @@
+package image
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"github.com/lissto-dev/api/internal/api/common"
+)
+
+// BaseNameLabel is the OCI label naming the image an image was built from
+const BaseNameLabel = "org.opencontainers.image.base.name"
+
+// ParseImageConfig extracts the runtime defaults of an image from its config blob (ImageMetadata.Config)
+func ParseImageConfig(blob []byte) (*common.ImageConfigInfo, error) {
+	if len(blob) == 0 {
+		return nil, errors.New("image config is empty")
+	}
+	configFile, err := v1.ParseConfigFile(bytes.NewReader(blob))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse image config: %w", err)
+	}
+
+	info := &common.ImageConfigInfo{
+		OS:           configFile.OS,
+		OSVersion:    configFile.OSVersion,
+		Architecture: configFile.Architecture,
+		Entrypoint:   configFile.Config.Entrypoint,
+		Cmd:          configFile.Config.Cmd,
+		User:         configFile.Config.User,
+		WorkingDir:   configFile.Config.WorkingDir,
+		BaseImage:    configFile.Config.Labels[BaseNameLabel],
+		ExposedPorts: []string{},
+	}
+	for port := range configFile.Config.ExposedPorts {
+		info.ExposedPorts = append(info.ExposedPorts, port)
+	}
+	sort.Strings(info.ExposedPorts)
+	return info, nil
+}
+
+// InspectImage fetches the config of an image for the service's platform and extracts its runtime defaults
+func (ir *ImageResolver) InspectImage(imageRef string, service types.ServiceConfig) (*common.ImageConfigInfo, error) {
+	os, arch := ir.getPlatformFromService(service)
+	metadata, err := ir.imageChecker.CheckImageExistsForPlatform(imageRef, os, arch)
+	if errors.Is(err, ErrRegistryUnavailable) {
+		return nil, err
+	}
+	if err != nil || !metadata.Exists {
+		return nil, fmt.Errorf("image not found: %s", imageRef)
+	}
+	return ParseImageConfig(metadata.Config)
+}
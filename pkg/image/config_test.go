@@ -0,0 +1,64 @@
+package image_test
+
+import (
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+// configBlob is a trimmed config of a node image built on Debian
+const configBlob = `{
+  "architecture": "amd64",
+  "os": "linux",
+  "config": {
+    "User": "node",
+    "ExposedPorts": {"9090/tcp": {}, "3000/tcp": {}},
+    "Env": ["PATH=/usr/local/bin:/usr/bin", "NODE_VERSION=20.11.0"],
+    "Entrypoint": ["docker-entrypoint.sh"],
+    "Cmd": ["node", "server.js"],
+    "WorkingDir": "/app",
+    "Labels": {"org.opencontainers.image.base.name": "docker.io/library/debian:bookworm-slim"}
+  },
+  "rootfs": {"type": "layers", "diff_ids": []}
+}`
+
+var _ = Describe("Image config", func() {
+	It("should extract the entrypoint, user and exposed ports", func() {
+		info, err := image.ParseImageConfig([]byte(configBlob))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(info.OS).To(Equal("linux"))
+		Expect(info.Architecture).To(Equal("amd64"))
+		Expect(info.Entrypoint).To(Equal([]string{"docker-entrypoint.sh"}))
+		Expect(info.Cmd).To(Equal([]string{"node", "server.js"}))
+		Expect(info.User).To(Equal("node"))
+		Expect(info.WorkingDir).To(Equal("/app"))
+		Expect(info.ExposedPorts).To(Equal([]string{"3000/tcp", "9090/tcp"}))
+		Expect(info.BaseImage).To(Equal("docker.io/library/debian:bookworm-slim"))
+	})
+
+	It("should reject an empty or malformed config", func() {
+		_, err := image.ParseImageConfig(nil)
+		Expect(err).To(MatchError(ContainSubstring("empty")))
+
+		_, err = image.ParseImageConfig([]byte("{not json"))
+		Expect(err).To(MatchError(ContainSubstring("failed to parse image config")))
+	})
+
+	It("should inspect the image for the service platform", func() {
+		checker := NewMockImageChecker()
+		checker.AddResponse("acme/web@sha256:abc", "linux", "arm64", "sha256:abc")
+		checker.AddConfig("acme/web@sha256:abc", []byte(configBlob))
+		resolver := image.NewImageResolver("", "", checker)
+		service := types.ServiceConfig{Name: "web", Labels: types.Labels{"lissto.dev/platform-arch": "arm64"}}
+
+		info, err := resolver.InspectImage("acme/web@sha256:abc", service)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.User).To(Equal("node"))
+
+		_, err = resolver.InspectImage("acme/missing:1.0", service)
+		Expect(err).To(MatchError(ContainSubstring("image not found")))
+	})
+})
@@ -0,0 +1,108 @@
+package image
+
+import (
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// RegistryCredential is a registry login supplied with a single request
+// It only lives for that request: never log it, cache it or store it in a resource
+type RegistryCredential struct {
+	Registry string // Registry host the credential applies to (e.g., ghcr.io)
+	Token    string // Bearer token, alternative to Username/Password
+	Username string
+	Password string
+}
+
+// CredentialChecker is implemented by image checkers that can authenticate with a request credential
+type CredentialChecker interface {
+	WithCredential(cred RegistryCredential) ImageChecker
+}
+
+// Validate checks that the credential names a registry and carries exactly one kind of secret
+func (c RegistryCredential) Validate() error {
+	if c.Registry == "" {
+		return fmt.Errorf("registry is required")
+	}
+	if _, err := name.NewRegistry(c.Registry); err != nil {
+		return fmt.Errorf("invalid registry %q: %w", c.Registry, err)
+	}
+	hasBasic := c.Username != "" || c.Password != ""
+	switch {
+	case c.Token != "" && hasBasic:
+		return fmt.Errorf("set either token or username/password, not both")
+	case c.Token == "" && !hasBasic:
+		return fmt.Errorf("token or username/password is required")
+	case hasBasic && (c.Username == "" || c.Password == ""):
+		return fmt.Errorf("username and password must be set together")
+	}
+	return nil
+}
+
+// Authenticator returns the go-containerregistry authenticator for the credential
+func (c RegistryCredential) Authenticator() authn.Authenticator {
+	if c.Token != "" {
+		return &authn.Bearer{Token: c.Token}
+	}
+	return &authn.Basic{Username: c.Username, Password: c.Password}
+}
+
+// Keychain returns a keychain using the credential for its registry and base (nil = anonymous) elsewhere
+func (c RegistryCredential) Keychain(base authn.Keychain) authn.Keychain {
+	keychain := authn.Keychain(&credentialKeychain{credential: c})
+	if base != nil {
+		keychain = authn.NewMultiKeychain(keychain, base)
+	}
+	return keychain
+}
+
+// String hides the secret so the credential can't leak through logs
+func (c RegistryCredential) String() string {
+	return fmt.Sprintf("RegistryCredential{Registry: %s}", c.Registry)
+}
+
+// GoString hides the secret from %#v as well
+func (c RegistryCredential) GoString() string {
+	return c.String()
+}
+
+// credentialKeychain serves a request credential for its registry only
+type credentialKeychain struct {
+	credential RegistryCredential
+}
+
+// Resolve implements authn.Keychain; other registries get anonymous so a base keychain can answer
+func (k *credentialKeychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	registry, err := name.NewRegistry(k.credential.Registry)
+	if err != nil || registry.RegistryStr() != target.RegistryStr() {
+		return authn.Anonymous, nil
+	}
+	return k.credential.Authenticator(), nil
+}
+
+// WithCredential returns a copy of the checker that authenticates with cred for its registry
+// The K8s keychain, if any, still serves every other registry
+func (iec *ImageExistenceChecker) WithCredential(cred RegistryCredential) ImageChecker {
+	checker := *iec
+	checker.keychain = cred.Keychain(iec.keychain)
+	return &checker
+}
+
+// WithCredential returns a copy of the resolver whose lookups authenticate with cred
+// The copy has no digest cache, so nothing resolved with the credential outlives the request
+func (ir *ImageResolver) WithCredential(cred RegistryCredential) (*ImageResolver, error) {
+	if err := cred.Validate(); err != nil {
+		return nil, err
+	}
+	checker, ok := ir.imageChecker.(CredentialChecker)
+	if !ok {
+		return nil, fmt.Errorf("image checker does not support registry credentials")
+	}
+
+	resolver := *ir
+	resolver.imageChecker = checker.WithCredential(cred)
+	resolver.cache = nil
+	return &resolver, nil
+}
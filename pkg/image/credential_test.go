@@ -0,0 +1,126 @@
+package image_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+// credentialMockChecker records request credentials and answers with a checker that can see private images
+type credentialMockChecker struct {
+	*MockImageChecker
+	authenticated *MockImageChecker
+	credentials   []image.RegistryCredential
+}
+
+func (m *credentialMockChecker) WithCredential(cred image.RegistryCredential) image.ImageChecker {
+	m.credentials = append(m.credentials, cred)
+	return m.authenticated
+}
+
+// staticKeychain stands in for the K8s keychain
+type staticKeychain struct {
+	authenticator authn.Authenticator
+}
+
+func (k staticKeychain) Resolve(authn.Resource) (authn.Authenticator, error) {
+	return k.authenticator, nil
+}
+
+var _ = Describe("RegistryCredential", func() {
+	authorization := func(keychain authn.Keychain, registry string) *authn.AuthConfig {
+		reg, err := name.NewRegistry(registry)
+		Expect(err).NotTo(HaveOccurred())
+		authenticator, err := keychain.Resolve(reg)
+		Expect(err).NotTo(HaveOccurred())
+		config, err := authenticator.Authorization()
+		Expect(err).NotTo(HaveOccurred())
+		return config
+	}
+
+	It("should validate the credential", func() {
+		Expect(image.RegistryCredential{Registry: "ghcr.io", Token: "t"}.Validate()).To(Succeed())
+		Expect(image.RegistryCredential{Registry: "ghcr.io", Username: "u", Password: "p"}.Validate()).To(Succeed())
+		Expect(image.RegistryCredential{Token: "t"}.Validate()).To(MatchError(ContainSubstring("registry is required")))
+		Expect(image.RegistryCredential{Registry: "ghcr.io"}.Validate()).To(HaveOccurred())
+		Expect(image.RegistryCredential{Registry: "ghcr.io", Token: "t", Username: "u", Password: "p"}.Validate()).To(HaveOccurred())
+		Expect(image.RegistryCredential{Registry: "ghcr.io", Username: "u"}.Validate()).To(HaveOccurred())
+	})
+
+	It("should use the credential for its registry and the base keychain elsewhere", func() {
+		base := staticKeychain{authenticator: &authn.Basic{Username: "node", Password: "node-secret"}}
+		keychain := image.RegistryCredential{Registry: "ghcr.io", Token: "short-lived"}.Keychain(base)
+
+		Expect(authorization(keychain, "ghcr.io").RegistryToken).To(Equal("short-lived"))
+		Expect(authorization(keychain, "registry.example.com").Username).To(Equal("node"))
+	})
+
+	It("should fall back to anonymous without a base keychain", func() {
+		keychain := image.RegistryCredential{Registry: "ghcr.io", Username: "ci", Password: "pw"}.Keychain(nil)
+
+		Expect(authorization(keychain, "ghcr.io").Username).To(Equal("ci"))
+		Expect(*authorization(keychain, "docker.io")).To(Equal(authn.AuthConfig{}))
+	})
+
+	It("should not print the secret", func() {
+		cred := image.RegistryCredential{Registry: "ghcr.io", Token: "short-lived", Password: "pw"}
+		Expect(fmt.Sprintf("%v %+v %#v %s", cred, cred, cred, cred)).NotTo(Or(ContainSubstring("short-lived"), ContainSubstring("pw")))
+	})
+
+	Describe("ImageResolver.WithCredential", func() {
+		var (
+			checker  *credentialMockChecker
+			memCache *cache.MemoryCache
+			resolver *image.ImageResolver
+			service  types.ServiceConfig
+		)
+
+		BeforeEach(func() {
+			checker = &credentialMockChecker{MockImageChecker: NewMockImageChecker(), authenticated: NewMockImageChecker()}
+			checker.authenticated.AddResponse("ghcr.io/acme/private:1.0", "linux", "amd64", "sha256:private")
+			memCache = cache.NewMemoryCache()
+			resolver = image.NewImageResolverWithCache("", "", checker, memCache)
+			service = types.ServiceConfig{Image: "ghcr.io/acme/private:1.0"}
+		})
+
+		It("should resolve with the provided credential without persisting anything", func() {
+			cred := image.RegistryCredential{Registry: "ghcr.io", Token: "short-lived"}
+			withCredential, err := resolver.WithCredential(cred)
+			Expect(err).NotTo(HaveOccurred())
+
+			digest, err := withCredential.GetImageDigestWithServicePlatform("ghcr.io/acme/private:1.0", service)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(digest).To(ContainSubstring("sha256:private"))
+			Expect(checker.credentials).To(Equal([]image.RegistryCredential{cred}))
+
+			// Nothing resolved with the credential is cached for other requests
+			var entry cache.ImageDigestCache
+			Expect(memCache.Get(context.Background(), image.GetCacheKey("ghcr.io/acme/private:1.0", "linux", "amd64"), &entry)).NotTo(Succeed())
+
+			// The shared resolver keeps its own checker
+			_, err = resolver.GetImageDigestWithServicePlatform("ghcr.io/acme/private:1.0", service)
+			Expect(err).To(HaveOccurred())
+			Expect(checker.GetCallCount("ghcr.io/acme/private:1.0", "linux", "amd64")).To(Equal(1))
+		})
+
+		It("should reject an invalid credential", func() {
+			_, err := resolver.WithCredential(image.RegistryCredential{Registry: "ghcr.io"})
+			Expect(err).To(HaveOccurred())
+			Expect(checker.credentials).To(BeEmpty())
+		})
+
+		It("should fail when the checker cannot authenticate", func() {
+			plain := image.NewImageResolver("", "", NewMockImageChecker())
+			_, err := plain.WithCredential(image.RegistryCredential{Registry: "ghcr.io", Token: "t"})
+			Expect(err).To(MatchError(ContainSubstring("does not support registry credentials")))
+		})
+	})
+})
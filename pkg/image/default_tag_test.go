@@ -0,0 +1,55 @@
+package image_test
+
+import (
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+var _ = Describe("Default floating tag", func() {
+	var (
+		resolver *image.ImageResolver
+		service  types.ServiceConfig
+	)
+
+	BeforeEach(func() {
+		resolver = image.NewImageResolver("registry.io", "team/", &mockImageChecker{existingImages: make(map[string]bool)})
+		service = types.ServiceConfig{Name: "api", Build: &types.BuildConfig{Context: "."}}
+	})
+
+	lastCandidate := func(config image.ResolutionConfig) string {
+		result, err := resolver.ResolveImageDetailed(service, config)
+		Expect(err).To(HaveOccurred())
+		Expect(result.Candidates).NotTo(BeEmpty())
+		last := result.Candidates[len(result.Candidates)-1]
+		Expect(last.Source).To(Equal(image.TagSourceLatest))
+		return last.ImageURL
+	}
+
+	It("should try latest when no default tag is configured", func() {
+		Expect(resolver.DefaultTag()).To(Equal("latest"))
+		Expect(lastCandidate(image.ResolutionConfig{Commit: "abc123"})).To(Equal("registry.io/team/api:latest"))
+	})
+
+	It("should use the configured default tag as the final candidate", func() {
+		Expect(resolver.SetDefaultTag("stable")).To(Succeed())
+		Expect(lastCandidate(image.ResolutionConfig{Commit: "abc123"})).To(Equal("registry.io/team/api:stable"))
+	})
+
+	It("should let the blueprint override take precedence", func() {
+		Expect(resolver.SetDefaultTag("stable")).To(Succeed())
+		Expect(lastCandidate(image.ResolutionConfig{Commit: "abc123", DefaultTag: "main"})).To(Equal("registry.io/team/api:main"))
+	})
+
+	It("should ignore an invalid blueprint override", func() {
+		Expect(resolver.SetDefaultTag("stable")).To(Succeed())
+		Expect(lastCandidate(image.ResolutionConfig{DefaultTag: "not a tag"})).To(Equal("registry.io/team/api:stable"))
+	})
+
+	It("should reject an invalid configured tag", func() {
+		Expect(resolver.SetDefaultTag(":bad")).NotTo(Succeed())
+		Expect(resolver.DefaultTag()).To(Equal("latest"))
+	})
+})
@@ -0,0 +1,34 @@
+package image
+
+import "github.com/compose-spec/compose-go/v2/types"
+
+const (
+	// BuildPendingLabel opts a build-only service into pending resolution
+	// Value must be "allow"; the image is expected to be published by a build that hasn't finished yet
+	BuildPendingLabel = "lissto.dev/build-pending"
+
+	// MethodPending is the resolution method reported for services awaiting their first build
+	MethodPending = "pending"
+)
+
+// AllowsBuildPending reports whether a failed resolution for the service may be
+// turned into a pending placeholder instead of an error.
+// Only services that are built (have a build section and no explicit image) qualify.
+func AllowsBuildPending(service types.ServiceConfig, requested bool) bool {
+	if service.Build == nil || service.Image != "" {
+		return false
+	}
+	if requested {
+		return true
+	}
+	return service.Labels != nil && service.Labels[BuildPendingLabel] == "allow"
+}
+
+// PendingPlaceholder returns the image a pending service is expected to be published as.
+// This is the highest priority candidate, i.e. the tag the build pipeline should produce.
+func PendingPlaceholder(result *DetailedImageResolutionResult) string {
+	if result == nil || len(result.Candidates) == 0 {
+		return ""
+	}
+	return result.Candidates[0].ImageURL
+}
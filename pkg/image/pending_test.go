@@ -0,0 +1,63 @@
+package image_test
+
+import (
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+var _ = Describe("Build pending resolution", func() {
+	Describe("AllowsBuildPending", func() {
+		DescribeTable("should only allow build-only services that opted in",
+			func(service types.ServiceConfig, requested, expected bool) {
+				Expect(image.AllowsBuildPending(service, requested)).To(Equal(expected))
+			},
+			Entry("build service with allow label",
+				types.ServiceConfig{
+					Build:  &types.BuildConfig{Context: "."},
+					Labels: map[string]string{image.BuildPendingLabel: "allow"},
+				}, false, true),
+			Entry("build service with request flag",
+				types.ServiceConfig{Build: &types.BuildConfig{Context: "."}}, true, true),
+			Entry("build service without label or flag",
+				types.ServiceConfig{Build: &types.BuildConfig{Context: "."}}, false, false),
+			Entry("build service with other label value",
+				types.ServiceConfig{
+					Build:  &types.BuildConfig{Context: "."},
+					Labels: map[string]string{image.BuildPendingLabel: "deny"},
+				}, false, false),
+			Entry("service with explicit image",
+				types.ServiceConfig{
+					Image:  "postgres:15",
+					Build:  &types.BuildConfig{Context: "."},
+					Labels: map[string]string{image.BuildPendingLabel: "allow"},
+				}, true, false),
+			Entry("service without build",
+				types.ServiceConfig{Labels: map[string]string{image.BuildPendingLabel: "allow"}}, true, false),
+		)
+	})
+
+	Describe("PendingPlaceholder", func() {
+		It("should use the highest priority candidate when nothing is published", func() {
+			resolver := image.NewImageResolver("registry.io", "team/", NewMockImageChecker())
+			service := types.ServiceConfig{
+				Name:   "api",
+				Build:  &types.BuildConfig{Context: "."},
+				Labels: map[string]string{image.BuildPendingLabel: "allow"},
+			}
+
+			result, err := resolver.ResolveImageDetailed(service, image.ResolutionConfig{Commit: "abc123", Branch: "feature"})
+
+			Expect(err).To(HaveOccurred())
+			Expect(result.Candidates).NotTo(BeEmpty())
+			Expect(image.PendingPlaceholder(result)).To(Equal("registry.io/team/api:abc123"))
+		})
+
+		It("should return empty without candidates", func() {
+			Expect(image.PendingPlaceholder(nil)).To(BeEmpty())
+			Expect(image.PendingPlaceholder(&image.DetailedImageResolutionResult{})).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,72 @@
+package image
+
+import (
+	"fmt"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/google/go-containerregistry/pkg/name"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// MethodPinned is reported for compose images already pinned to a digest, which are deployed as written
+const MethodPinned = "pinned"
+
+// IsDigestPinned reports whether an image reference names a digest (e.g. nginx@sha256:...)
+func IsDigestPinned(imageRef string) bool {
+	_, err := name.NewDigest(imageRef)
+	return err == nil
+}
+
+// VerifyPinnedImage checks that a digest-pinned image exists for the service's platform
+// The reference is returned unchanged: resolving it like a tag would swap a pinned manifest
+// list for the platform digest, and no tag candidates are generated
+func (ir *ImageResolver) VerifyPinnedImage(imageRef string, service types.ServiceConfig) (*PlatformDigest, error) {
+	resolved, err := ir.ResolvePlatformDigest(imageRef, service)
+	if err != nil {
+		return nil, err
+	}
+	resolved.Image = imageRef
+	return resolved, nil
+}
+
+// PinnedCandidate returns the candidate recorded for a verified digest-pinned image
+func PinnedCandidate(imageRef string) common.ImageCandidate {
+	return common.ImageCandidate{
+		ImageURL: imageRef,
+		Tag:      MethodPinned,
+		Source:   MethodPinned,
+		Success:  true,
+		Digest:   imageRef,
+	}
+}
+
+// resolvePinnedDetailed verifies the service's digest-pinned compose image, see ResolveImageDetailed
+func (ir *ImageResolver) resolvePinnedDetailed(service types.ServiceConfig) (*DetailedImageResolutionResult, error) {
+	result := &DetailedImageResolutionResult{Platform: ir.ServicePlatform(service)}
+
+	resolved, err := ir.VerifyPinnedImage(service.Image, service)
+	if err != nil {
+		result.Candidates = []common.ImageCandidate{{
+			ImageURL: service.Image,
+			Tag:      MethodPinned,
+			Source:   MethodPinned,
+			Error:    err.Error(),
+		}}
+		return result, fmt.Errorf("pinned image '%s' for service %s not found: %w", service.Image, service.Name, err)
+	}
+
+	logging.Logger.Info("Using digest-pinned compose image",
+		zap.String("service", service.Name),
+		zap.String("image", resolved.Image))
+
+	result.FinalImage = resolved.Image
+	result.Method = MethodPinned
+	result.Selected = service.Image
+	result.Candidates = []common.ImageCandidate{PinnedCandidate(service.Image)}
+	result.MultiArch = resolved.MultiArch
+	result.Platforms = resolved.Platforms
+	return result, nil
+}
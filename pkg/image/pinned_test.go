@@ -0,0 +1,72 @@
+package image_test
+
+import (
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/internal/api/common"
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+var _ = Describe("Digest-pinned images", func() {
+	const (
+		pinned         = "nginx@sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		platformDigest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+
+	var (
+		checker  *MockImageChecker
+		resolver *image.ImageResolver
+		service  types.ServiceConfig
+	)
+
+	BeforeEach(func() {
+		checker = NewMockImageChecker()
+		resolver = image.NewImageResolver("registry.io", "team/", checker)
+		service = types.ServiceConfig{Name: "web", Image: pinned}
+	})
+
+	It("should recognize digest references", func() {
+		Expect(image.IsDigestPinned(pinned)).To(BeTrue())
+		Expect(image.IsDigestPinned("registry.io:5000/team/api@sha256:1111111111111111111111111111111111111111111111111111111111111111")).To(BeTrue())
+		Expect(image.IsDigestPinned("nginx:alpine")).To(BeFalse())
+		Expect(image.IsDigestPinned("nginx@sha256:short")).To(BeFalse())
+		Expect(image.IsDigestPinned("")).To(BeFalse())
+	})
+
+	It("should verify the pinned image and return it unchanged", func() {
+		// A pinned manifest list resolves to the platform digest, which must not replace the pin
+		checker.AddMultiArchResponse(pinned, "linux", "amd64", platformDigest)
+
+		result, err := resolver.ResolveImageDetailed(service, image.ResolutionConfig{Commit: "abc123", Branch: "main"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.FinalImage).To(Equal(pinned))
+		Expect(result.Method).To(Equal(image.MethodPinned))
+		Expect(result.MultiArch).To(BeTrue())
+		Expect(result.Candidates).To(Equal([]common.ImageCandidate{image.PinnedCandidate(pinned)}))
+		Expect(checker.GetCallCount(pinned, "linux", "amd64")).To(Equal(1))
+	})
+
+	It("should not try tag candidates", func() {
+		checker.AddResponse(pinned, "linux", "amd64", platformDigest)
+		checker.AddResponse("registry.io/team/web:abc123", "linux", "amd64", platformDigest)
+
+		result, err := resolver.ResolveImageWithCandidates(service, image.ResolutionConfig{Commit: "abc123"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.FinalImage).To(Equal(pinned))
+		Expect(result.Method).To(Equal(image.MethodPinned))
+		Expect(checker.GetCallCount("registry.io/team/web:abc123", "linux", "amd64")).To(BeZero())
+	})
+
+	It("should fail when the pinned image does not exist", func() {
+		result, err := resolver.ResolveImageDetailed(service, image.ResolutionConfig{UnpinnedFallback: true})
+
+		Expect(err).To(MatchError(ContainSubstring("pinned image")))
+		Expect(result.FinalImage).To(BeEmpty())
+		Expect(result.Candidates).To(HaveLen(1))
+		Expect(result.Candidates[0].Success).To(BeFalse())
+	})
+})
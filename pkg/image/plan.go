@@ -0,0 +1,42 @@
+package image
+
+import (
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// PlannedCandidate is an image URL the resolver would try
+type PlannedCandidate struct {
+	ImageURL string
+	Tag      string
+	Source   string // "original", "label", "commit", "branch", "latest"
+}
+
+// ResolutionPlan describes how a service image would be resolved
+type ResolutionPlan struct {
+	Registry   string             // Registry used
+	ImageName  string             // Image name resolved
+	Candidates []PlannedCandidate // Candidates in the order they would be tried
+}
+
+// PlanCandidates returns the candidates ResolveImageDetailed would try, without contacting any registry
+// ResolvedBefore is not applied since it needs tag metadata from the registry
+func (ir *ImageResolver) PlanCandidates(service types.ServiceConfig, config ResolutionConfig) *ResolutionPlan {
+	registry := ir.ResolveRegistryWithCompose(service, config.ComposeRegistry)
+	imageName := ir.ResolveImageNameWithCompose(service, config.ComposeRepository, config.ComposePrefix)
+
+	tagCandidates := ir.resolveTag(service, config)
+	candidates := make([]PlannedCandidate, 0, len(tagCandidates))
+	for _, candidate := range tagCandidates {
+		candidates = append(candidates, PlannedCandidate{
+			ImageURL: ir.candidateURL(registry, imageName, candidate.Tag),
+			Tag:      candidate.Tag,
+			Source:   candidate.Source,
+		})
+	}
+
+	return &ResolutionPlan{
+		Registry:   registry,
+		ImageName:  imageName,
+		Candidates: candidates,
+	}
+}
@@ -0,0 +1,118 @@
+package image_test
+
+import (
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+var _ = Describe("ResolvePlatformDigest", func() {
+	var checker *MockImageChecker
+
+	BeforeEach(func() {
+		checker = NewMockImageChecker()
+		checker.AddResponse("nginx:1.27", "linux", "amd64", "sha256:amd")
+		checker.AddMultiArchResponse("nginx:1.27", "linux", "arm64", "sha256:arm")
+	})
+
+	It("should report the default platform", func() {
+		resolver := image.NewImageResolver("", "", checker)
+		resolved, err := resolver.ResolvePlatformDigest("nginx:1.27", types.ServiceConfig{Name: "web"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*resolved).To(Equal(image.PlatformDigest{Image: "nginx@sha256:amd", Platform: "linux/amd64"}))
+	})
+
+	It("should report a lissto.dev/platform-arch override and the manifest list", func() {
+		resolver := image.NewImageResolver("", "", checker)
+		service := types.ServiceConfig{Name: "web", Labels: types.Labels{"lissto.dev/platform-arch": "arm64"}}
+		resolved, err := resolver.ResolvePlatformDigest("nginx:1.27", service)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*resolved).To(Equal(image.PlatformDigest{Image: "nginx@sha256:arm", Platform: "linux/arm64", MultiArch: true}))
+		Expect(resolver.ServicePlatform(service)).To(Equal("linux/arm64"))
+	})
+
+	It("should keep the manifest list flag on cache hits", func() {
+		resolver := image.NewImageResolverWithCache("", "", checker, cache.NewMemoryCache())
+		service := types.ServiceConfig{Name: "web", Image: "nginx:1.27", Labels: types.Labels{"lissto.dev/platform-arch": "arm64"}}
+
+		_, err := resolver.ResolvePlatformDigest("nginx:1.27", service)
+		Expect(err).NotTo(HaveOccurred())
+		resolved, err := resolver.ResolvePlatformDigest("nginx:1.27", service)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(checker.GetCallCount("nginx:1.27", "linux", "arm64")).To(Equal(1))
+		Expect(resolved.MultiArch).To(BeTrue())
+		Expect(resolved.Platform).To(Equal("linux/arm64"))
+	})
+})
+
+var _ = Describe("lissto.dev/require-platforms", func() {
+	var checker *MockImageChecker
+	var resolver *image.ImageResolver
+
+	BeforeEach(func() {
+		checker = NewMockImageChecker()
+		checker.AddResponse("nginx:1.27", "linux", "amd64", "sha256:amd")
+		checker.AddMultiArchResponse("redis:7", "linux", "amd64", "sha256:redis-amd")
+		checker.AddMultiArchResponse("redis:7", "linux", "arm64", "sha256:redis-arm")
+		resolver = image.NewImageResolver("", "", checker)
+	})
+
+	requiring := func(platforms string) types.ServiceConfig {
+		return types.ServiceConfig{Name: "web", Labels: types.Labels{image.RequirePlatformsLabel: platforms}}
+	}
+
+	It("should reject an image missing a required arm64 platform", func() {
+		_, err := resolver.ResolvePlatformDigest("nginx:1.27", requiring("linux/amd64,linux/arm64"))
+
+		Expect(err).To(MatchError(image.ErrMissingPlatforms))
+		Expect(err.Error()).To(ContainSubstring("nginx:1.27 has no linux/arm64 image"))
+		Expect(checker.GetCallCount("nginx:1.27", "linux", "arm64")).To(Equal(1))
+	})
+
+	It("should report the verified platforms of an image supporting all of them", func() {
+		resolved, err := resolver.ResolvePlatformDigest("redis:7", requiring("linux/arm64, linux/amd64"))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved.Image).To(Equal("redis@sha256:redis-amd"))
+		Expect(resolved.Platforms).To(Equal([]string{"linux/amd64", "linux/arm64"}))
+		Expect(checker.GetCallCount("redis:7", "linux", "amd64")).To(Equal(1))
+	})
+
+	It("should leave the platforms unset without the label", func() {
+		resolved, err := resolver.ResolvePlatformDigest("redis:7", types.ServiceConfig{Name: "web"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved.Platforms).To(BeNil())
+	})
+
+	It("should stop trying candidates at an image missing a required platform", func() {
+		checker.AddResponse("ghcr.io/acme/web:abc123", "linux", "amd64", "sha256:commit")
+		checker.AddMultiArchResponse("ghcr.io/acme/web:main", "linux", "amd64", "sha256:main-amd")
+		checker.AddMultiArchResponse("ghcr.io/acme/web:main", "linux", "arm64", "sha256:main-arm")
+		service := requiring("linux/arm64")
+		service.Build = &types.BuildConfig{Context: "."}
+
+		result, err := resolver.ResolveImageDetailed(service, image.ResolutionConfig{
+			Commit:            "abc123",
+			Branch:            "main",
+			ComposeRegistry:   "ghcr.io",
+			ComposeRepository: "acme/web",
+		})
+
+		Expect(err).To(MatchError(image.ErrMissingPlatforms))
+		Expect(err.Error()).To(ContainSubstring("ghcr.io/acme/web:abc123 has no linux/arm64 image"))
+		Expect(result.FinalImage).To(BeEmpty())
+		Expect(result.Candidates).To(HaveLen(1))
+		Expect(checker.GetCallCount("ghcr.io/acme/web:main", "linux", "amd64")).To(BeZero())
+	})
+
+	It("should reject a malformed platform", func() {
+		_, err := image.RequiredPlatforms(requiring("linux/amd64,arm64"))
+
+		Expect(err).To(MatchError(ContainSubstring(`invalid lissto.dev/require-platforms entry "arm64"`)))
+	})
+})
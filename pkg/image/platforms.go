@@ -0,0 +1,85 @@
+package image
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// RequirePlatformsLabel lists the os/arch platforms a service image must support (e.g. linux/amd64,linux/arm64)
+const RequirePlatformsLabel = "lissto.dev/require-platforms"
+
+// ErrMissingPlatforms is returned when an image lacks a platform required by RequirePlatformsLabel
+var ErrMissingPlatforms = errors.New("image is missing required platforms")
+
+// RequiredPlatforms parses the service's RequirePlatformsLabel into sorted, de-duplicated os/arch pairs
+func RequiredPlatforms(service types.ServiceConfig) ([]string, error) {
+	value := strings.TrimSpace(service.Labels[RequirePlatformsLabel])
+	if value == "" {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool)
+	var platforms []string
+	for _, entry := range strings.Split(value, ",") {
+		platform := strings.TrimSpace(entry)
+		if platform == "" {
+			continue
+		}
+		parts := strings.Split(platform, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid %s entry %q for service %s, expected os/arch", RequirePlatformsLabel, platform, service.Name)
+		}
+		if !seen[platform] {
+			seen[platform] = true
+			platforms = append(platforms, platform)
+		}
+	}
+	sort.Strings(platforms)
+	return platforms, nil
+}
+
+// verifyRequiredPlatforms checks that imageURL exists for every platform required by the service
+// It returns the verified platforms (nil without the label); resolved is the platform already checked
+func (ir *ImageResolver) verifyRequiredPlatforms(imageURL string, service types.ServiceConfig, resolved string) ([]string, error) {
+	platforms, err := RequiredPlatforms(service)
+	if err != nil || len(platforms) == 0 {
+		return nil, err
+	}
+
+	var missing []string
+	for _, platform := range platforms {
+		if platform == resolved {
+			continue
+		}
+		os, arch, _ := strings.Cut(platform, "/")
+		metadata, err := ir.imageChecker.CheckImageExistsForPlatform(imageURL, os, arch)
+		if errors.Is(err, ErrRegistryUnavailable) {
+			return nil, err
+		}
+		if err != nil || !metadata.Exists {
+			missing = append(missing, platform)
+		}
+	}
+	if len(missing) > 0 {
+		logging.Logger.Info("Image is missing required platforms",
+			zap.String("service", service.Name),
+			zap.String("image", imageURL),
+			zap.Strings("missing", missing))
+		return nil, fmt.Errorf("%w: %s has no %s image", ErrMissingPlatforms, imageURL, strings.Join(missing, ", "))
+	}
+
+	verified := platforms
+	if !slices.Contains(platforms, resolved) {
+		verified = append(append([]string{}, platforms...), resolved)
+		sort.Strings(verified)
+	}
+	return verified, nil
+}
@@ -0,0 +1,90 @@
+package image
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// TagInfo describes a tag in a repository
+type TagInfo struct {
+	Tag      string
+	PushedAt time.Time // Zero if the registry does not report it
+}
+
+// TagLister is implemented by image checkers that can list repository tags with push times
+// It enables point-in-time resolution (ResolutionConfig.ResolvedBefore)
+type TagLister interface {
+	ListTags(repository string) ([]TagInfo, error)
+}
+
+// tagCandidates returns the tag candidates for a service in the order they should be tried
+// With ResolvedBefore set, candidates pushed after that time are dropped and the rest are
+// ordered newest first; without tag metadata the normal order is kept
+func (ir *ImageResolver) tagCandidates(service types.ServiceConfig, config ResolutionConfig, registry, imageName string) []TagCandidate {
+	candidates := ir.resolveTag(service, config)
+	if config.ResolvedBefore.IsZero() {
+		return candidates
+	}
+
+	lister, ok := ir.imageChecker.(TagLister)
+	if !ok {
+		logging.Logger.Debug("Image checker cannot list tags, ignoring resolved-before",
+			zap.String("service", service.Name))
+		return candidates
+	}
+
+	repository := ir.repositoryURL(registry, imageName)
+	tags, err := lister.ListTags(repository)
+	if err != nil {
+		logging.Logger.Warn("Failed to list tags, ignoring resolved-before",
+			zap.String("service", service.Name),
+			zap.String("repository", repository),
+			zap.Error(err))
+		return candidates
+	}
+
+	pushedAt := make(map[string]time.Time, len(tags))
+	for _, tag := range tags {
+		if !tag.PushedAt.IsZero() {
+			pushedAt[tag.Tag] = tag.PushedAt
+		}
+	}
+	if len(pushedAt) == 0 {
+		logging.Logger.Debug("No tag push times available, ignoring resolved-before",
+			zap.String("service", service.Name),
+			zap.String("repository", repository))
+		return candidates
+	}
+
+	return filterPushedBefore(candidates, pushedAt, config.ResolvedBefore)
+}
+
+// filterPushedBefore keeps candidates pushed before the cutoff, newest first
+// Candidates pushed at the same time keep their original relative order
+func filterPushedBefore(candidates []TagCandidate, pushedAt map[string]time.Time, before time.Time) []TagCandidate {
+	filtered := make([]TagCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		pushed, known := pushedAt[candidate.Tag]
+		if known && pushed.Before(before) {
+			filtered = append(filtered, candidate)
+		}
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return pushedAt[filtered[i].Tag].After(pushedAt[filtered[j].Tag])
+	})
+	return filtered
+}
+
+// repositoryURL returns the repository a candidate tag belongs to, with rewrite rules applied
+func (ir *ImageResolver) repositoryURL(registry, imageName string) string {
+	// Build a candidate URL and strip the tag, so rewrites match exactly as for candidates
+	const placeholderTag = "lissto-tag-placeholder"
+	return strings.TrimSuffix(ir.candidateURL(registry, imageName, placeholderTag), ":"+placeholderTag)
+}
@@ -0,0 +1,133 @@
+package image_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+// listingImageChecker is a mock checker that also lists tags with push times
+type listingImageChecker struct {
+	mockImageChecker
+	tags       map[string][]image.TagInfo
+	listErr    error
+	listedRepo string
+}
+
+func (l *listingImageChecker) ListTags(repository string) ([]image.TagInfo, error) {
+	l.listedRepo = repository
+	if l.listErr != nil {
+		return nil, l.listErr
+	}
+	return l.tags[repository], nil
+}
+
+var _ = Describe("Point-in-time resolution", func() {
+	var (
+		checker  *listingImageChecker
+		resolver *image.ImageResolver
+		service  types.ServiceConfig
+		noon     time.Time
+	)
+
+	BeforeEach(func() {
+		checker = &listingImageChecker{
+			mockImageChecker: mockImageChecker{existingImages: map[string]bool{
+				"registry.io/team/api:abc123":  true,
+				"registry.io/team/api:feature": true,
+				"registry.io/team/api:latest":  true,
+			}},
+		}
+		resolver = image.NewImageResolver("registry.io", "team/", checker)
+		service = types.ServiceConfig{Name: "api", Build: &types.BuildConfig{Context: "."}}
+		noon = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+		checker.tags = map[string][]image.TagInfo{
+			"registry.io/team/api": {
+				{Tag: "abc123", PushedAt: noon.Add(2 * time.Hour)},
+				{Tag: "feature", PushedAt: noon.Add(-1 * time.Hour)},
+				{Tag: "latest", PushedAt: noon.Add(-3 * time.Hour)},
+			},
+		}
+	})
+
+	It("should pick the newest candidate pushed before the cutoff", func() {
+		result, err := resolver.ResolveImageDetailed(service, image.ResolutionConfig{
+			Commit: "abc123", Branch: "feature", ResolvedBefore: noon,
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(checker.listedRepo).To(Equal("registry.io/team/api"))
+		Expect(result.Method).To(Equal("branch"))
+		Expect(result.Selected).To(Equal("registry.io/team/api:feature"))
+	})
+
+	It("should fall through to older candidates when the newest is missing", func() {
+		checker.existingImages["registry.io/team/api:abc123"] = false
+
+		result, err := resolver.ResolveImageDetailed(service, image.ResolutionConfig{
+			Commit: "abc123", Branch: "feature", ResolvedBefore: noon.Add(3 * time.Hour),
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		sources := []string{}
+		for _, candidate := range result.Candidates {
+			sources = append(sources, candidate.Source)
+		}
+		Expect(sources).To(Equal([]string{"commit", "branch"}))
+		Expect(result.Selected).To(Equal("registry.io/team/api:feature"))
+	})
+
+	It("should fail when no candidate was pushed before the cutoff", func() {
+		_, err := resolver.ResolveImageDetailed(service, image.ResolutionConfig{
+			Commit: "abc123", Branch: "feature", ResolvedBefore: noon.Add(-4 * time.Hour),
+		})
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should use the normal order when the cutoff is unset", func() {
+		result, err := resolver.ResolveImageDetailed(service, image.ResolutionConfig{Commit: "abc123", Branch: "feature"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(checker.listedRepo).To(BeEmpty())
+		Expect(result.Method).To(Equal("commit"))
+	})
+
+	It("should fall back to the normal order when listing fails", func() {
+		checker.listErr = errors.New("registry unavailable")
+
+		result, err := resolver.ResolveImageDetailed(service, image.ResolutionConfig{
+			Commit: "abc123", Branch: "feature", ResolvedBefore: noon,
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Method).To(Equal("commit"))
+	})
+
+	It("should fall back to the normal order without push times", func() {
+		checker.tags["registry.io/team/api"] = []image.TagInfo{{Tag: "abc123"}, {Tag: "feature"}}
+
+		result, err := resolver.ResolveImageDetailed(service, image.ResolutionConfig{
+			Commit: "abc123", Branch: "feature", ResolvedBefore: noon,
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Method).To(Equal("commit"))
+	})
+
+	It("should ignore the cutoff for checkers that cannot list tags", func() {
+		plain := image.NewImageResolver("registry.io", "team/", &checker.mockImageChecker)
+
+		result, err := plain.ResolveImageDetailed(service, image.ResolutionConfig{
+			Commit: "abc123", Branch: "feature", ResolvedBefore: noon,
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Method).To(Equal("commit"))
+	})
+})
@@ -2,6 +2,7 @@ package image
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -27,6 +28,16 @@ type ResolutionConfig struct {
 	ComposeRegistry   string // Registry from x-lissto.registry
 	ComposeRepository string // Single repository from x-lissto.repository (for monorepo)
 	ComposePrefix     string // Repository prefix from x-lissto.repositoryPrefix
+	// ResolvedBefore selects the newest candidate tag pushed before this time (zero disables)
+	// Requires an image checker implementing TagLister; otherwise the normal order is used
+	ResolvedBefore time.Time
+	// UnpinnedFallback deploys the compose image tag without a digest when no candidate
+	// can be pinned, instead of failing. Off by default (pinned-only).
+	UnpinnedFallback bool
+	// DefaultTag overrides the floating tag of the latest source (from x-lissto.defaultTag)
+	DefaultTag string
+	// TagPrefixPerService prefixes generated tags with "<service>-" (from x-lissto.tagPrefixPerService)
+	TagPrefixPerService bool
 }
 
 // ImageResolver handles image resolution with registry/repository/tag priority
@@ -37,6 +48,27 @@ type ImageResolver struct {
 	defaultOS      string
 	defaultArch    string
 	cache          pkgcache.Cache // Optional cache for image digest lookups
+	tagSources     []string       // Tag candidate order, DefaultTagSources if empty
+	defaultTag     string         // Floating tag of the latest source, DefaultFloatingTag if empty
+
+	rewriteRules    []RewriteRule // Registry rewrite rules applied before existence checks
+	rewriteExplicit bool          // Also rewrite override labels and explicit image fields
+
+	cacheOverride CacheOverride // Per-request digest cache behavior, see WithCacheOverride
+}
+
+// CacheOverride changes how a resolver uses the image digest cache, e.g. to force fresh resolution
+// after a registry served a bad digest
+type CacheOverride struct {
+	SkipRead bool          // Always ask the registry; fresh digests are still cached
+	TTL      time.Duration // Max age of cached digests used, and of digests cached when below the default (0 keeps the defaults)
+}
+
+// WithCacheOverride returns a copy of the resolver using the digest cache as overridden
+func (ir *ImageResolver) WithCacheOverride(override CacheOverride) *ImageResolver {
+	resolver := *ir
+	resolver.cacheOverride = override
+	return &resolver
 }
 
 // NewImageResolver creates a new image resolver
@@ -80,6 +112,7 @@ func NewImageResolverWithCache(globalRegistry, globalPrefix string, imageChecker
 func (ir *ImageResolver) ResolveImage(service types.ServiceConfig, config ResolutionConfig) (string, error) {
 	// Step 0: Check for complete image override label (highest priority)
 	if imageOverride := ir.getLabelValue(service.Labels, "lissto.dev/image", ""); imageOverride != "" {
+		imageOverride = ir.RewriteExplicitImage(imageOverride)
 		logging.Logger.Info("Using image override from label",
 			zap.String("service", service.Name),
 			zap.String("override_image", imageOverride))
@@ -107,19 +140,18 @@ func (ir *ImageResolver) ResolveImage(service types.ServiceConfig, config Resolu
 	imageName := ir.ResolveImageNameWithCompose(service, config.ComposeRepository, config.ComposePrefix)
 
 	// Step 3: Resolve tag candidates
-	tagCandidates := ir.resolveTag(service, config.Commit, config.Branch)
+	tagCandidates := ir.tagCandidates(service, config, registry, imageName)
 
 	// Step 4: Check existence for each candidate
+	var unavailable error
 	for _, candidate := range tagCandidates {
-		var imageURL string
-		if registry != "" {
-			imageURL = fmt.Sprintf("%s/%s:%s", registry, imageName, candidate.Tag)
-		} else {
-			imageURL = fmt.Sprintf("%s:%s", imageName, candidate.Tag)
-		}
+		imageURL := ir.candidateURL(registry, imageName, candidate.Tag)
 
 		// Check if image exists
 		metadata, err := ir.imageChecker.CheckImageExists(imageURL)
+		if errors.Is(err, ErrRegistryUnavailable) {
+			unavailable = err
+		}
 		if err == nil && metadata.Exists {
 			logging.Logger.Info("Found existing image",
 				zap.String("image", imageURL),
@@ -134,7 +166,7 @@ func (ir *ImageResolver) ResolveImage(service types.ServiceConfig, config Resolu
 			zap.String("service", service.Name))
 	}
 
-	return "", fmt.Errorf("no existing image found for service %s", service.Name)
+	return "", noImageFoundError(service.Name, unavailable)
 }
 
 // ResolveRegistryWithCompose determines the registry for a service with compose-level config
@@ -157,6 +189,7 @@ func (ir *ImageResolver) ResolveRegistryWithCompose(service types.ServiceConfig,
 
 // ResolveImageNameWithCompose determines the image name for a service with compose-level config
 // Priority: Service label → Compose repository (x-lissto.repository) → Compose prefix (x-lissto.repositoryPrefix) + service name → Global prefix + service name → Service name
+// Per-service tags of a single repository are handled by the tag prefix (see serviceTagPrefix), not here
 func (ir *ImageResolver) ResolveImageNameWithCompose(service types.ServiceConfig, composeRepository, composePrefix string) string {
 	// Service-specific label always takes precedence
 	if repo := ir.getLabelValue(service.Labels, "lissto.dev/repository", ""); repo != "" {
@@ -179,35 +212,66 @@ func (ir *ImageResolver) ResolveImageNameWithCompose(service types.ServiceConfig
 }
 
 // resolveTag determines tag candidates in priority order
-// Priority: Original → Labels → commit → branch → latest
-func (ir *ImageResolver) resolveTag(service types.ServiceConfig, commit, branch string) []TagCandidate {
+// Default priority: Original → Labels → commit → branch → latest (see SetTagSources)
+// The latest source tries the floating tag: config.DefaultTag if set, else the configured default
+// Generated tags (commit, branch, latest) carry the per-service tag prefix, see serviceTagPrefix
+func (ir *ImageResolver) resolveTag(service types.ServiceConfig, config ResolutionConfig) []TagCandidate {
 	candidates := make([]TagCandidate, 0)
+	prefix := ir.serviceTagPrefix(service, config.TagPrefixPerService)
+
+	for _, source := range ir.TagSources() {
+		var tag string
+		switch source {
+		case TagSourceOriginal:
+			// Extract tag from service.Image (e.g., "nginx:alpine" -> "alpine")
+			tag = ir.extractOriginalTag(service.Image)
+		case TagSourceLabel:
+			// Custom tag from label
+			tag = ir.getLabelValue(service.Labels, "lissto.dev/tag", "")
+		case TagSourceCommit:
+			tag = prefixTag(prefix, config.Commit)
+		case TagSourceBranch:
+			tag = prefixTag(prefix, config.Branch)
+		case TagSourceLatest:
+			tag = prefixTag(prefix, ir.floatingTag(config.DefaultTag))
+		}
 
-	// Priority 0: Original tag from docker-compose image field
-	// Extract tag from service.Image (e.g., "nginx:alpine" -> "alpine")
-	if originalTag := ir.extractOriginalTag(service.Image); originalTag != "" {
-		candidates = append(candidates, TagCandidate{Tag: originalTag, Source: "original"})
+		if tag != "" {
+			candidates = append(candidates, TagCandidate{Tag: tag, Source: source})
+		}
 	}
 
-	// Priority 1: Custom tag from label
-	if tag := ir.getLabelValue(service.Labels, "lissto.dev/tag", ""); tag != "" {
-		candidates = append(candidates, TagCandidate{Tag: tag, Source: "label"})
-	}
+	return candidates
+}
 
-	// Priority 2: Commit-based tag
-	if commit != "" {
-		candidates = append(candidates, TagCandidate{Tag: commit, Source: "commit"})
+// serviceTagPrefix returns the prefix of generated tags for monorepos publishing per-service tags
+// of a single repository (e.g. repo:api-abc123)
+// Priority: lissto.dev/tag-service-prefix label → "<service>-" with x-lissto.tagPrefixPerService → none
+// It only changes tags, the image name is resolved as usual (typically from x-lissto.repository).
+// The original and lissto.dev/tag tags are used as written.
+func (ir *ImageResolver) serviceTagPrefix(service types.ServiceConfig, perService bool) string {
+	if prefix := ir.getLabelValue(service.Labels, TagServicePrefixLabel, ""); prefix != "" {
+		if err := ValidateTag(prefix); err != nil {
+			logging.Logger.Warn("Ignoring invalid tag prefix label",
+				zap.String("service", service.Name),
+				zap.String("label_value", prefix),
+				zap.Error(err))
+		} else {
+			return prefix
+		}
 	}
-
-	// Priority 3: Branch-based tag
-	if branch != "" {
-		candidates = append(candidates, TagCandidate{Tag: branch, Source: "branch"})
+	if perService {
+		return service.Name + "-"
 	}
+	return ""
+}
 
-	// Priority 4: Latest
-	candidates = append(candidates, TagCandidate{Tag: "latest", Source: "latest"})
-
-	return candidates
+// prefixTag prepends prefix to a non-empty tag
+func prefixTag(prefix, tag string) string {
+	if tag == "" {
+		return ""
+	}
+	return prefix + tag
 }
 
 // extractOriginalTag extracts the tag from the original docker-compose image field
@@ -248,6 +312,17 @@ func (ir *ImageResolver) extractOriginalTag(image string) string {
 	return tag
 }
 
+// candidateURL builds the image URL for a tag candidate, applying rewrite rules
+func (ir *ImageResolver) candidateURL(registry, imageName, tag string) string {
+	var imageURL string
+	if registry != "" {
+		imageURL = fmt.Sprintf("%s/%s:%s", registry, imageName, tag)
+	} else {
+		imageURL = fmt.Sprintf("%s:%s", imageName, tag)
+	}
+	return ir.rewriteImage(imageURL)
+}
+
 // getLabelValue safely extracts a label value from service labels
 func (ir *ImageResolver) getLabelValue(labels map[string]string, key, defaultValue string) string {
 	if labels == nil {
@@ -274,6 +349,17 @@ type DetailedImageResolutionResult struct {
 	Registry   string                  // Registry used
 	ImageName  string                  // Image name resolved
 	Candidates []common.ImageCandidate // All candidates that were tried
+	Platform   string                  // Platform resolved for (e.g., linux/arm64)
+	MultiArch  bool                    // Digest was selected from a manifest list
+	Platforms  []string                // Platforms verified for lissto.dev/require-platforms
+}
+
+// PlatformDigest is an image resolved to its digest for one platform
+type PlatformDigest struct {
+	Image     string   // Image with digest (e.g., nginx@sha256:...)
+	Platform  string   // Platform resolved for (e.g., linux/arm64)
+	MultiArch bool     // Digest was selected from a manifest list
+	Platforms []string // Platforms verified for lissto.dev/require-platforms, nil without the label
 }
 
 // ResolveImageWithCandidates tries multiple candidates, returns which worked
@@ -284,6 +370,7 @@ func (ir *ImageResolver) ResolveImageWithCandidates(
 ) (*ImageResolutionResult, error) {
 	// Step 0: Check for complete image override label (highest priority)
 	if imageOverride := ir.getLabelValue(service.Labels, "lissto.dev/image", ""); imageOverride != "" {
+		imageOverride = ir.RewriteExplicitImage(imageOverride)
 		logging.Logger.Info("Using image override from label",
 			zap.String("service", service.Name),
 			zap.String("override_image", imageOverride))
@@ -309,6 +396,19 @@ func (ir *ImageResolver) ResolveImageWithCandidates(
 		return nil, fmt.Errorf("image override '%s' for service %s not found: %w", imageOverride, service.Name, err)
 	}
 
+	// Step 0.5: A compose image pinned to a digest is deployed as written, no candidates are tried
+	if IsDigestPinned(service.Image) {
+		resolved, err := ir.VerifyPinnedImage(service.Image, service)
+		if err != nil {
+			return nil, fmt.Errorf("pinned image '%s' for service %s not found: %w", service.Image, service.Name, err)
+		}
+		return &ImageResolutionResult{
+			FinalImage: resolved.Image,
+			Method:     MethodPinned,
+			Selected:   service.Image,
+		}, nil
+	}
+
 	// Step 1: Resolve registry
 	registry := ir.ResolveRegistryWithCompose(service, config.ComposeRegistry)
 
@@ -316,7 +416,7 @@ func (ir *ImageResolver) ResolveImageWithCandidates(
 	imageName := ir.ResolveImageNameWithCompose(service, config.ComposeRepository, config.ComposePrefix)
 
 	// Step 3: Resolve tag candidates
-	tagCandidates := ir.resolveTag(service, config.Commit, config.Branch)
+	tagCandidates := ir.tagCandidates(service, config, registry, imageName)
 
 	logging.Logger.Info("Resolving image with candidates",
 		zap.String("service", service.Name),
@@ -328,12 +428,7 @@ func (ir *ImageResolver) ResolveImageWithCandidates(
 
 	// Log all candidates that will be tried
 	for i, candidate := range tagCandidates {
-		var imageURL string
-		if registry != "" {
-			imageURL = fmt.Sprintf("%s/%s:%s", registry, imageName, candidate.Tag)
-		} else {
-			imageURL = fmt.Sprintf("%s:%s", imageName, candidate.Tag)
-		}
+		imageURL := ir.candidateURL(registry, imageName, candidate.Tag)
 		logging.Logger.Info("Image candidate",
 			zap.String("service", service.Name),
 			zap.Int("candidate_index", i),
@@ -343,13 +438,9 @@ func (ir *ImageResolver) ResolveImageWithCandidates(
 	}
 
 	// Step 4: Check existence for each candidate
+	var unavailable error
 	for _, candidate := range tagCandidates {
-		var imageURL string
-		if registry != "" {
-			imageURL = fmt.Sprintf("%s/%s:%s", registry, imageName, candidate.Tag)
-		} else {
-			imageURL = fmt.Sprintf("%s:%s", imageName, candidate.Tag)
-		}
+		imageURL := ir.candidateURL(registry, imageName, candidate.Tag)
 
 		// Try to get image with digest using service-specific platform
 		logging.Logger.Info("Trying image candidate",
@@ -371,6 +462,13 @@ func (ir *ImageResolver) ResolveImageWithCandidates(
 			}, nil
 		}
 
+		if errors.Is(err, ErrMissingPlatforms) {
+			// The candidate exists but cannot run everywhere; older candidates are not a substitute
+			return nil, fmt.Errorf("image for service %s: %w", service.Name, err)
+		}
+		if errors.Is(err, ErrRegistryUnavailable) {
+			unavailable = err
+		}
 		logging.Logger.Info("Image not found, trying next candidate",
 			zap.String("image", imageURL),
 			zap.String("tag_source", candidate.Source),
@@ -378,7 +476,7 @@ func (ir *ImageResolver) ResolveImageWithCandidates(
 			zap.Error(err))
 	}
 
-	return nil, fmt.Errorf("no existing image found for service %s", service.Name)
+	return nil, noImageFoundError(service.Name, unavailable)
 }
 
 // ResolveImageDetailed tries multiple candidates and returns detailed info about all attempts
@@ -386,6 +484,11 @@ func (ir *ImageResolver) ResolveImageDetailed(
 	service types.ServiceConfig,
 	config ResolutionConfig,
 ) (*DetailedImageResolutionResult, error) {
+	// A compose image pinned to a digest is deployed as written, no candidates are tried
+	if IsDigestPinned(service.Image) {
+		return ir.resolvePinnedDetailed(service)
+	}
+
 	// Step 1: Resolve registry
 	registry := ir.ResolveRegistryWithCompose(service, config.ComposeRegistry)
 
@@ -393,7 +496,7 @@ func (ir *ImageResolver) ResolveImageDetailed(
 	imageName := ir.ResolveImageNameWithCompose(service, config.ComposeRepository, config.ComposePrefix)
 
 	// Step 3: Resolve tag candidates
-	tagCandidates := ir.resolveTag(service, config.Commit, config.Branch)
+	tagCandidates := ir.tagCandidates(service, config, registry, imageName)
 
 	logging.Logger.Info("Resolving image with detailed candidates",
 		zap.String("service", service.Name),
@@ -406,15 +509,13 @@ func (ir *ImageResolver) ResolveImageDetailed(
 	// Track all candidates
 	candidates := make([]common.ImageCandidate, 0, len(tagCandidates))
 	var finalImage, method, selected string
+	var multiArch bool
+	var platforms []string
+	var unavailable, missingPlatforms error
 
 	// Step 4: Check existence for each candidate
 	for _, candidate := range tagCandidates {
-		var imageURL string
-		if registry != "" {
-			imageURL = fmt.Sprintf("%s/%s:%s", registry, imageName, candidate.Tag)
-		} else {
-			imageURL = fmt.Sprintf("%s:%s", imageName, candidate.Tag)
-		}
+		imageURL := ir.candidateURL(registry, imageName, candidate.Tag)
 
 		logging.Logger.Info("Trying image candidate",
 			zap.String("service", service.Name),
@@ -422,7 +523,7 @@ func (ir *ImageResolver) ResolveImageDetailed(
 			zap.String("tag_source", candidate.Source))
 
 		// Try to get image with digest using service-specific platform
-		imageWithDigest, err := ir.GetImageDigestWithServicePlatform(imageURL, service)
+		resolved, err := ir.ResolvePlatformDigest(imageURL, service)
 
 		candidateResult := common.ImageCandidate{
 			ImageURL: imageURL,
@@ -432,17 +533,25 @@ func (ir *ImageResolver) ResolveImageDetailed(
 		}
 
 		if err == nil {
-			candidateResult.Digest = imageWithDigest
-			finalImage = imageWithDigest
+			candidateResult.Digest = resolved.Image
+			finalImage = resolved.Image
 			method = candidate.Source
 			selected = imageURL
+			multiArch = resolved.MultiArch
+			platforms = resolved.Platforms
 
 			logging.Logger.Info("Found existing image",
-				zap.String("image", imageWithDigest),
+				zap.String("image", resolved.Image),
 				zap.String("tag_source", candidate.Source),
 				zap.String("service", service.Name))
 		} else {
 			candidateResult.Error = err.Error()
+			if errors.Is(err, ErrMissingPlatforms) {
+				missingPlatforms = err
+			}
+			if errors.Is(err, ErrRegistryUnavailable) {
+				unavailable = err
+			}
 			logging.Logger.Info("Image not found, trying next candidate",
 				zap.String("image", imageURL),
 				zap.String("tag_source", candidate.Source),
@@ -452,12 +561,32 @@ func (ir *ImageResolver) ResolveImageDetailed(
 
 		candidates = append(candidates, candidateResult)
 
-		// If we found a working image, we can stop here
-		if err == nil {
+		// If we found a working image, or one missing a required platform, we can stop here
+		if err == nil || missingPlatforms != nil {
 			break
 		}
 	}
 
+	if missingPlatforms != nil {
+		return &DetailedImageResolutionResult{
+			Registry:   registry,
+			ImageName:  imageName,
+			Candidates: candidates,
+			Platform:   ir.ServicePlatform(service),
+		}, fmt.Errorf("image for service %s: %w", service.Name, missingPlatforms)
+	}
+
+	if finalImage == "" && config.UnpinnedFallback && service.Image != "" {
+		finalImage = ir.RewriteExplicitImage(service.Image)
+		method = MethodUnpinnedFallback
+		selected = finalImage
+		candidates = append(candidates, UnpinnedFallbackCandidate(finalImage))
+
+		logging.Logger.Warn("No pinnable image found, deploying compose image tag UNPINNED",
+			zap.String("service", service.Name),
+			zap.String("image", finalImage))
+	}
+
 	if finalImage == "" {
 		return &DetailedImageResolutionResult{
 			FinalImage: "",
@@ -466,7 +595,8 @@ func (ir *ImageResolver) ResolveImageDetailed(
 			Registry:   registry,
 			ImageName:  imageName,
 			Candidates: candidates,
-		}, fmt.Errorf("no existing image found for service %s", service.Name)
+			Platform:   ir.ServicePlatform(service),
+		}, noImageFoundError(service.Name, unavailable)
 	}
 
 	return &DetailedImageResolutionResult{
@@ -476,9 +606,21 @@ func (ir *ImageResolver) ResolveImageDetailed(
 		Registry:   registry,
 		ImageName:  imageName,
 		Candidates: candidates,
+		Platform:   ir.ServicePlatform(service),
+		MultiArch:  multiArch,
+		Platforms:  platforms,
 	}, nil
 }
 
+// noImageFoundError reports that no candidate resolved, wrapping the registry-unavailable error
+// (if any) so callers can tell an unreachable registry from a missing image
+func noImageFoundError(serviceName string, unavailable error) error {
+	if unavailable != nil {
+		return fmt.Errorf("no existing image found for service %s: %w", serviceName, unavailable)
+	}
+	return fmt.Errorf("no existing image found for service %s", serviceName)
+}
+
 // GetImageDigest resolves an image URL to its digest
 func (ir *ImageResolver) GetImageDigest(imageURL string) (string, error) {
 	// Use default platform for backward compatibility
@@ -487,9 +629,28 @@ func (ir *ImageResolver) GetImageDigest(imageURL string) (string, error) {
 
 // GetImageDigestForPlatform resolves an image URL to its digest for a specific platform
 func (ir *ImageResolver) GetImageDigestForPlatform(imageURL, os, arch string) (string, error) {
+	resolved, err := ir.resolvePlatformDigest(imageURL, os, arch)
+	if err != nil {
+		return "", err
+	}
+	return resolved.Image, nil
+}
+
+// resolvePlatformDigest resolves an image URL to its digest for a specific platform,
+// recording whether the digest was picked from a manifest list
+func (ir *ImageResolver) resolvePlatformDigest(imageURL, os, arch string) (*PlatformDigest, error) {
 	metadata, err := ir.imageChecker.CheckImageExistsForPlatform(imageURL, os, arch)
+	if errors.Is(err, ErrRegistryUnavailable) {
+		return nil, err
+	}
 	if err != nil || !metadata.Exists {
-		return "", fmt.Errorf("image not found: %s", imageURL)
+		return nil, fmt.Errorf("image not found: %s", imageURL)
+	}
+
+	resolved := &PlatformDigest{
+		Image:     imageURL,
+		Platform:  os + "/" + arch,
+		MultiArch: metadata.IsMultiArch,
 	}
 
 	// Check if we have a digest
@@ -498,19 +659,32 @@ func (ir *ImageResolver) GetImageDigestForPlatform(imageURL, os, arch string) (s
 			zap.String("image", imageURL),
 			zap.String("platform", os+"/"+arch))
 		// Return the image without digest - this is acceptable for some use cases
-		return imageURL, nil
+		return resolved, nil
 	}
 
 	// Return image with digest-only format (strip tag)
-	return ir.formatImageWithDigest(imageURL, metadata.Digest), nil
+	resolved.Image = ir.formatImageWithDigest(imageURL, metadata.Digest)
+	return resolved, nil
 }
 
 // GetImageDigestWithCacheContext resolves an image URL to its digest with caching support
 // Uses service context to determine if it's an infra or service image for cache TTL decisions
 func (ir *ImageResolver) GetImageDigestWithCacheContext(imageURL, os, arch string, service types.ServiceConfig) (string, error) {
+	resolved, err := ir.resolvePlatformDigestWithCache(imageURL, os, arch, service)
+	if err != nil {
+		return "", err
+	}
+	return resolved.Image, nil
+}
+
+// errCacheReadSkipped stands in for a cache miss when a CacheOverride bypasses or outdates the entry
+var errCacheReadSkipped = errors.New("cache read skipped")
+
+// resolvePlatformDigestWithCache is resolvePlatformDigest backed by the digest cache
+func (ir *ImageResolver) resolvePlatformDigestWithCache(imageURL, os, arch string, service types.ServiceConfig) (*PlatformDigest, error) {
 	// If no cache is configured, fall back to non-cached behavior
 	if ir.cache == nil {
-		return ir.GetImageDigestForPlatform(imageURL, os, arch)
+		return ir.resolvePlatformDigest(imageURL, os, arch)
 	}
 
 	ctx := context.Background()
@@ -523,14 +697,20 @@ func (ir *ImageResolver) GetImageDigestWithCacheContext(imageURL, os, arch strin
 			zap.String("image", imageURL),
 			zap.String("image_type", imageType),
 			zap.String("platform", os+"/"+arch))
-		return ir.GetImageDigestForPlatform(imageURL, os, arch)
+		return ir.resolvePlatformDigest(imageURL, os, arch)
 	}
 
 	// Check cache first
 	cacheKey := GetCacheKey(imageURL, os, arch)
 	var cachedEntry pkgcache.ImageDigestCache
 
-	err := ir.cache.Get(ctx, cacheKey, &cachedEntry)
+	err := errCacheReadSkipped
+	if !ir.cacheOverride.SkipRead {
+		err = ir.cache.Get(ctx, cacheKey, &cachedEntry)
+	}
+	if err == nil && ir.cacheOverride.TTL > 0 && time.Since(cachedEntry.CachedAt) > ir.cacheOverride.TTL {
+		err = errCacheReadSkipped
+	}
 	if err == nil {
 		// Cache hit!
 		logging.Logger.Info("Image digest cache HIT",
@@ -539,7 +719,11 @@ func (ir *ImageResolver) GetImageDigestWithCacheContext(imageURL, os, arch strin
 			zap.String("platform", os+"/"+arch),
 			zap.String("digest", cachedEntry.Digest),
 			zap.Time("cached_at", cachedEntry.CachedAt))
-		return cachedEntry.Digest, nil
+		return &PlatformDigest{
+			Image:     cachedEntry.Digest,
+			Platform:  os + "/" + arch,
+			MultiArch: cachedEntry.MultiArch,
+		}, nil
 	}
 
 	// Cache miss - log it
@@ -549,18 +733,23 @@ func (ir *ImageResolver) GetImageDigestWithCacheContext(imageURL, os, arch strin
 		zap.String("platform", os+"/"+arch))
 
 	// Fetch from registry
-	digest, err := ir.GetImageDigestForPlatform(imageURL, os, arch)
+	resolved, err := ir.resolvePlatformDigest(imageURL, os, arch)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Store in cache with appropriate TTL
 	ttl := GetTTL(isInfra, imageURL)
+	if ttl > 0 && ir.cacheOverride.TTL > 0 {
+		// An override only shortens the TTL, it must not keep digests longer for every later caller
+		ttl = min(ttl, ir.cacheOverride.TTL)
+	}
 	if ttl > 0 {
 		cacheEntry := pkgcache.ImageDigestCache{
 			ImageURL:  imageURL,
-			Digest:    digest,
-			Platform:  fmt.Sprintf("%s/%s", os, arch),
+			Digest:    resolved.Image,
+			Platform:  resolved.Platform,
+			MultiArch: resolved.MultiArch,
 			ImageType: imageType,
 			CachedAt:  time.Now(),
 		}
@@ -579,20 +768,50 @@ func (ir *ImageResolver) GetImageDigestWithCacheContext(imageURL, os, arch strin
 		}
 	}
 
-	return digest, nil
+	return resolved, nil
 }
 
 // GetImageDigestWithServicePlatform resolves an image URL to its digest using service-specific platform configuration
 func (ir *ImageResolver) GetImageDigestWithServicePlatform(imageURL string, service types.ServiceConfig) (string, error) {
+	resolved, err := ir.ResolvePlatformDigest(imageURL, service)
+	if err != nil {
+		return "", err
+	}
+	return resolved.Image, nil
+}
+
+// ResolvePlatformDigest resolves an image URL like GetImageDigestWithServicePlatform,
+// also reporting the platform used and whether the digest came from a manifest list
+// Images of services with lissto.dev/require-platforms must also exist for every listed platform
+func (ir *ImageResolver) ResolvePlatformDigest(imageURL string, service types.ServiceConfig) (*PlatformDigest, error) {
 	os, arch := ir.getPlatformFromService(service)
 
-	// If cache is available, use the cache-aware method
+	var resolved *PlatformDigest
+	var err error
 	if ir.cache != nil {
-		return ir.GetImageDigestWithCacheContext(imageURL, os, arch, service)
+		// If cache is available, use the cache-aware method
+		resolved, err = ir.resolvePlatformDigestWithCache(imageURL, os, arch, service)
+	} else {
+		// Otherwise use the standard method
+		resolved, err = ir.resolvePlatformDigest(imageURL, os, arch)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	// Otherwise use the standard method
-	return ir.GetImageDigestForPlatform(imageURL, os, arch)
+	platforms, err := ir.verifyRequiredPlatforms(imageURL, service, os+"/"+arch)
+	if err != nil {
+		return nil, err
+	}
+	resolved.Platforms = platforms
+	return resolved, nil
+}
+
+// ServicePlatform returns the os/arch images of the service are resolved for
+// (lissto.dev/platform-os and lissto.dev/platform-arch labels, or the resolver defaults)
+func (ir *ImageResolver) ServicePlatform(service types.ServiceConfig) string {
+	os, arch := ir.getPlatformFromService(service)
+	return os + "/" + arch
 }
 
 // getPlatformFromService extracts platform configuration from service labels or uses defaults
@@ -0,0 +1,123 @@
+package image
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lissto-dev/api/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// RewriteRule rewrites image references, e.g. to pull Docker Hub images through a mirror
+// From is a reference prefix; a trailing "*" captures the rest of the reference,
+// which replaces the "*" in To (or is appended if To has none):
+//
+//	from: docker.io/library/*
+//	to:   mirror.internal/docker-hub/*
+type RewriteRule struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// ValidateRewriteRules checks configured rewrite rules
+func ValidateRewriteRules(rules []RewriteRule) error {
+	for i, rule := range rules {
+		from := strings.TrimSuffix(rule.From, "*")
+		if from == "" {
+			return fmt.Errorf("rule %d: from must not be empty", i)
+		}
+		if strings.Contains(from, "*") {
+			return fmt.Errorf("rule %d: '*' is only allowed at the end of from", i)
+		}
+		if strings.Count(rule.To, "*") > 1 {
+			return fmt.Errorf("rule %d: to may contain at most one '*'", i)
+		}
+		if strings.TrimSuffix(strings.ReplaceAll(rule.To, "*", ""), "/") == "" {
+			return fmt.Errorf("rule %d: to must not be empty", i)
+		}
+	}
+	return nil
+}
+
+// SetRewriteRules sets the rewrite rules applied to candidate images before existence checks
+// rewriteExplicit also applies them to lissto.dev/image overrides and explicit image fields
+func (ir *ImageResolver) SetRewriteRules(rules []RewriteRule, rewriteExplicit bool) error {
+	if err := ValidateRewriteRules(rules); err != nil {
+		return err
+	}
+	ir.rewriteRules = append([]RewriteRule(nil), rules...)
+	ir.rewriteExplicit = rewriteExplicit
+	return nil
+}
+
+// RewriteExplicitImage rewrites an image given by the user (override label or image field)
+// It is a no-op unless explicit rewriting is enabled
+func (ir *ImageResolver) RewriteExplicitImage(imageRef string) string {
+	if !ir.rewriteExplicit {
+		return imageRef
+	}
+	return ir.rewriteImage(imageRef)
+}
+
+// rewriteImage applies the first matching rewrite rule to an image reference
+func (ir *ImageResolver) rewriteImage(imageRef string) string {
+	if len(ir.rewriteRules) == 0 {
+		return imageRef
+	}
+
+	normalized := normalizeImageRef(imageRef)
+	for _, rule := range ir.rewriteRules {
+		rewritten, ok := applyRewriteRule(rule, imageRef, normalized)
+		if !ok {
+			continue
+		}
+		logging.Logger.Debug("Rewrote image reference",
+			zap.String("image", imageRef),
+			zap.String("rewritten", rewritten),
+			zap.String("rule", rule.From))
+		return rewritten
+	}
+	return imageRef
+}
+
+// applyRewriteRule rewrites the reference if it matches the rule, trying the reference as written first
+func applyRewriteRule(rule RewriteRule, refs ...string) (string, bool) {
+	prefix := strings.TrimSuffix(rule.From, "*")
+	for _, ref := range refs {
+		if !strings.HasPrefix(ref, prefix) {
+			continue
+		}
+		captured := strings.TrimPrefix(ref, prefix)
+		if strings.Contains(rule.To, "*") {
+			return strings.Replace(rule.To, "*", captured, 1), true
+		}
+		return rule.To + captured, true
+	}
+	return "", false
+}
+
+// normalizeImageRef expands Docker Hub shorthands so rules can match on the registry
+// Examples:
+//   - "nginx:alpine" -> "docker.io/library/nginx:alpine"
+//   - "bitnami/redis:7" -> "docker.io/bitnami/redis:7"
+//   - "index.docker.io/library/nginx" -> "docker.io/library/nginx"
+//   - "ghcr.io/org/app:v1" -> unchanged
+func normalizeImageRef(imageRef string) string {
+	first, rest, hasSlash := strings.Cut(imageRef, "/")
+	if !hasSlash {
+		return "docker.io/library/" + imageRef
+	}
+	if first == "index.docker.io" {
+		first = "docker.io"
+	}
+	if first == "docker.io" {
+		if !strings.Contains(rest, "/") {
+			return "docker.io/library/" + rest
+		}
+		return "docker.io/" + rest
+	}
+	if !strings.ContainsAny(first, ".:") && first != "localhost" {
+		return "docker.io/" + imageRef
+	}
+	return imageRef
+}
@@ -0,0 +1,88 @@
+package image_test
+
+import (
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+var _ = Describe("Registry rewrite rules", func() {
+	var (
+		mockChecker *mockImageChecker
+		resolver    *image.ImageResolver
+	)
+
+	BeforeEach(func() {
+		mockChecker = &mockImageChecker{existingImages: make(map[string]bool)}
+		resolver = image.NewImageResolver("", "", mockChecker)
+		Expect(resolver.SetRewriteRules([]image.RewriteRule{
+			{From: "docker.io/library/*", To: "mirror.internal/docker-hub/*"},
+			{From: "ghcr.io/", To: "mirror.internal/ghcr/"},
+		}, false)).To(Succeed())
+	})
+
+	It("should rewrite a Docker Hub image to the mirror before checking it", func() {
+		mockChecker.existingImages["mirror.internal/docker-hub/nginx:alpine"] = true
+		service := types.ServiceConfig{Name: "nginx", Labels: map[string]string{"lissto.dev/tag": "alpine"}}
+
+		result, err := resolver.ResolveImageDetailed(service, image.ResolutionConfig{})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Selected).To(Equal("mirror.internal/docker-hub/nginx:alpine"))
+		Expect(result.FinalImage).To(Equal("mirror.internal/docker-hub/nginx@sha256:mockdigest"))
+	})
+
+	It("should rewrite with a plain prefix rule", func() {
+		mockChecker.existingImages["mirror.internal/ghcr/acme/api:main"] = true
+		service := types.ServiceConfig{Name: "api", Labels: map[string]string{"lissto.dev/registry": "ghcr.io", "lissto.dev/repository": "acme/api"}}
+
+		result, err := resolver.ResolveImageDetailed(service, image.ResolutionConfig{Branch: "main"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Selected).To(Equal("mirror.internal/ghcr/acme/api:main"))
+	})
+
+	It("should pass non-matching images through unchanged", func() {
+		mockChecker.existingImages["quay.io/acme/worker:main"] = true
+		service := types.ServiceConfig{Name: "worker", Labels: map[string]string{"lissto.dev/registry": "quay.io", "lissto.dev/repository": "acme/worker"}}
+
+		result, err := resolver.ResolveImageDetailed(service, image.ResolutionConfig{Branch: "main"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Selected).To(Equal("quay.io/acme/worker:main"))
+	})
+
+	Describe("RewriteExplicitImage", func() {
+		It("should leave explicit images alone by default", func() {
+			Expect(resolver.RewriteExplicitImage("postgres:15")).To(Equal("postgres:15"))
+		})
+
+		It("should rewrite explicit images when enabled", func() {
+			Expect(resolver.SetRewriteRules([]image.RewriteRule{
+				{From: "docker.io/library/*", To: "mirror.internal/docker-hub/*"},
+			}, true)).To(Succeed())
+
+			Expect(resolver.RewriteExplicitImage("postgres:15")).To(Equal("mirror.internal/docker-hub/postgres:15"))
+			Expect(resolver.RewriteExplicitImage("index.docker.io/library/redis:7")).To(Equal("mirror.internal/docker-hub/redis:7"))
+			Expect(resolver.RewriteExplicitImage("bitnami/redis:7")).To(Equal("bitnami/redis:7"))
+		})
+	})
+
+	DescribeTable("ValidateRewriteRules",
+		func(rule image.RewriteRule, valid bool) {
+			err := image.ValidateRewriteRules([]image.RewriteRule{rule})
+			if valid {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("capture rule", image.RewriteRule{From: "docker.io/library/*", To: "mirror.internal/docker-hub/*"}, true),
+		Entry("prefix rule", image.RewriteRule{From: "ghcr.io/", To: "mirror.internal/ghcr/"}, true),
+		Entry("empty from", image.RewriteRule{From: "*", To: "mirror.internal/"}, false),
+		Entry("wildcard in the middle", image.RewriteRule{From: "docker.io/*/app", To: "mirror.internal/"}, false),
+		Entry("empty to", image.RewriteRule{From: "ghcr.io/", To: ""}, false),
+	)
+})
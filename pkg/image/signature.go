@@ -0,0 +1,484 @@
+package image
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// RequireSignatureLabel makes stack creation reject the service unless its image has a verified signature
+const RequireSignatureLabel = "lissto.dev/require-signature"
+
+// Cosign signature manifest annotations
+const (
+	CosignSignatureAnnotation   = "dev.cosignproject.cosign/signature"
+	CosignCertificateAnnotation = "dev.sigstore.cosign/certificate"
+	CosignChainAnnotation       = "dev.sigstore.cosign/chain"
+	// CosignBundleAnnotation holds the transparency log (Rekor) entry of a keyless signature and its signed timestamp
+	CosignBundleAnnotation = "dev.sigstore.cosign/bundle"
+)
+
+// Fulcio certificate extensions holding the OIDC issuer of a keyless signature
+var (
+	fulcioIssuerV1OID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	fulcioIssuerV2OID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+)
+
+// Signature results are cached per digest; unverified ones briefly, as a signature may be pushed after the image
+const (
+	SignatureCacheTTL           = time.Hour
+	UnverifiedSignatureCacheTTL = time.Minute
+)
+
+// maxSignaturePayload bounds the signed payload read from the registry
+const maxSignaturePayload = 1 << 20
+
+// RequiresSignature reports whether the service sets RequireSignatureLabel to true
+func RequiresSignature(service types.ServiceConfig) bool {
+	required, _ := strconv.ParseBool(service.Labels[RequireSignatureLabel])
+	return required
+}
+
+// SignatureResult is the cosign signature status of an image
+type SignatureResult struct {
+	Signed   bool   `json:"signed"`           // At least one cosign signature exists
+	Verified bool   `json:"verified"`         // A signature verified with a trusted key or keyless identity
+	Signer   string `json:"signer,omitempty"` // Trusted key name or certificate identity that verified it
+	Error    string `json:"error,omitempty"`  // Why no signature verified
+}
+
+// SignatureVerifier verifies the signature of a digest-pinned image
+// Unsigned and unverifiable images are reported in the result; errors are registry failures only
+type SignatureVerifier interface {
+	VerifySignature(ctx context.Context, imageRef string) (*SignatureResult, error)
+}
+
+// TrustedKey is a public key cosign signatures are verified with
+type TrustedKey struct {
+	Name string // Reported as the signer, e.g. the key file name
+	Key  crypto.PublicKey
+}
+
+// ParsePublicKey parses a PEM encoded cosign public key (ECDSA, RSA or Ed25519)
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T", key)
+}
+
+// KeylessPolicy is what keyless signatures (short-lived Fulcio certificates) are trusted with
+type KeylessPolicy struct {
+	// Roots are the CAs signing certificates must chain to
+	Roots *x509.CertPool
+	// Identities maps each trusted OIDC issuer to the signer identities allowed from it,
+	// path.Match patterns matched against the certificate's emails and URIs
+	Identities map[string][]string
+	// RekorKeys are the transparency log keys; a signature needs a log entry signed by one of them,
+	// whose time proves the certificate was valid when the image was signed
+	RekorKeys []crypto.PublicKey
+}
+
+// CosignVerifier verifies cosign signatures stored next to images (<repo>:sha256-<hex>.sig)
+// A signature is trusted when it verifies with one of the keys, or when its keyless certificate chains
+// to the policy's roots, names an allowed identity of an allowed issuer and was valid when the signature
+// was entered in the transparency log. Keyless signatures without a verified log entry are not trusted.
+type CosignVerifier struct {
+	keys     []TrustedKey
+	keyless  *KeylessPolicy
+	keychain authn.Keychain
+}
+
+// NewCosignVerifier creates a verifier trusting keys and, with a keyless policy, keyless signatures
+func NewCosignVerifier(keys []TrustedKey, keyless *KeylessPolicy, keychain authn.Keychain) *CosignVerifier {
+	if keychain == nil {
+		keychain = authn.DefaultKeychain
+	}
+	return &CosignVerifier{keys: keys, keyless: keyless, keychain: keychain}
+}
+
+// VerifySignature looks up the cosign signatures of imageRef and verifies them
+func (v *CosignVerifier) VerifySignature(ctx context.Context, imageRef string) (*SignatureResult, error) {
+	ref, err := name.NewDigest(imageRef)
+	if err != nil {
+		return &SignatureResult{Error: "image is not pinned to a digest"}, nil
+	}
+	digest := ref.DigestStr()
+	sigTag := ref.Context().Tag(strings.Replace(digest, ":", "-", 1) + ".sig")
+
+	sigImage, err := remote.Image(sigTag, remote.WithAuthFromKeychain(v.keychain), remote.WithContext(ctx))
+	if err != nil {
+		var terr *transport.Error
+		if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+			return &SignatureResult{Error: "no cosign signature found"}, nil
+		}
+		return nil, fmt.Errorf("failed to fetch signatures of %s: %w", imageRef, err)
+	}
+	manifest, err := sigImage.Manifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signatures of %s: %w", imageRef, err)
+	}
+
+	result := &SignatureResult{}
+	var failures []string
+	for _, desc := range manifest.Layers {
+		signature := desc.Annotations[CosignSignatureAnnotation]
+		if signature == "" {
+			continue
+		}
+		result.Signed = true
+
+		layer, err := sigImage.LayerByDigest(desc.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signature payload of %s: %w", imageRef, err)
+		}
+		payload, err := readPayload(layer.Compressed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signature payload of %s: %w", imageRef, err)
+		}
+
+		signer, err := v.verifySignature(digest, payload, signature, desc.Annotations)
+		if err == nil {
+			result.Verified = true
+			result.Signer = signer
+			result.Error = ""
+			return result, nil
+		}
+		failures = append(failures, err.Error())
+	}
+
+	if !result.Signed {
+		result.Error = "no cosign signature found"
+	} else {
+		result.Error = strings.Join(failures, "; ")
+	}
+	logging.Logger.Info("Image signature not verified",
+		zap.String("image", imageRef),
+		zap.Bool("signed", result.Signed),
+		zap.String("reason", result.Error))
+	return result, nil
+}
+
+// verifySignature checks one signature over payload and returns who signed it
+func (v *CosignVerifier) verifySignature(digest string, payload []byte, signature string, annotations map[string]string) (string, error) {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return "", errors.New("signature is not base64 encoded")
+	}
+	if err := checkPayloadDigest(payload, digest); err != nil {
+		return "", err
+	}
+
+	for _, key := range v.keys {
+		if verifyWithKey(key.Key, payload, sig) == nil {
+			return key.Name, nil
+		}
+	}
+
+	certPEM := annotations[CosignCertificateAnnotation]
+	if certPEM == "" || v.keyless == nil {
+		return "", errors.New("signature does not verify with a trusted key")
+	}
+	cert, err := parseCertificate(certPEM)
+	if err != nil {
+		return "", err
+	}
+	if err := verifyWithKey(cert.PublicKey, payload, sig); err != nil {
+		return "", fmt.Errorf("signature does not match its certificate: %w", err)
+	}
+	signedAt, err := v.verifyBundle(annotations[CosignBundleAnnotation], payload, sig, cert)
+	if err != nil {
+		return "", err
+	}
+	return v.verifyCertificate(cert, annotations[CosignChainAnnotation], signedAt)
+}
+
+// parseCertificate parses a PEM encoded signing certificate
+func parseCertificate(certPEM string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil {
+		return nil, errors.New("invalid signing certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing certificate: %w", err)
+	}
+	return cert, nil
+}
+
+// verifyCertificate checks that a keyless signing certificate chained to the roots when the image was signed
+// and names an allowed identity of an allowed issuer, which it returns
+func (v *CosignVerifier) verifyCertificate(cert *x509.Certificate, chainPEM string, signedAt time.Time) (string, error) {
+	intermediates := x509.NewCertPool()
+	intermediates.AppendCertsFromPEM([]byte(chainPEM))
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:         v.keyless.Roots,
+		Intermediates: intermediates,
+		CurrentTime:   signedAt,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		return "", fmt.Errorf("signing certificate is not trusted: %w", err)
+	}
+
+	issuer := certificateIssuer(cert)
+	patterns, ok := v.keyless.Identities[issuer]
+	if !ok {
+		return "", fmt.Errorf("signing certificate issuer %q is not trusted", issuer)
+	}
+	for _, identity := range certificateIdentities(cert) {
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(pattern, identity); matched {
+				return identity, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("signer %q is not an allowed identity of issuer %q", certificateIdentity(cert), issuer)
+}
+
+// rekorBundle is the transparency log entry cosign attaches to keyless signatures
+type rekorBundle struct {
+	SignedEntryTimestamp []byte `json:"SignedEntryTimestamp"`
+	Payload              struct {
+		Body           string `json:"body"`
+		IntegratedTime int64  `json:"integratedTime"`
+		LogIndex       int64  `json:"logIndex"`
+		LogID          string `json:"logID"`
+	} `json:"Payload"`
+}
+
+// hashedRekord is the log entry body of a signature: the payload hash, the signature and its certificate
+type hashedRekord struct {
+	Kind string `json:"kind"`
+	Spec struct {
+		Data struct {
+			Hash struct {
+				Algorithm string `json:"algorithm"`
+				Value     string `json:"value"`
+			} `json:"hash"`
+		} `json:"data"`
+		Signature struct {
+			Content   []byte `json:"content"`
+			PublicKey struct {
+				Content []byte `json:"content"`
+			} `json:"publicKey"`
+		} `json:"signature"`
+	} `json:"spec"`
+}
+
+// verifyBundle checks that the transparency log signed an entry for this payload, signature and certificate
+// and returns when the entry was made
+func (v *CosignVerifier) verifyBundle(bundleJSON string, payload, sig []byte, cert *x509.Certificate) (time.Time, error) {
+	if bundleJSON == "" {
+		return time.Time{}, errors.New("keyless signature has no transparency log entry")
+	}
+	var bundle rekorBundle
+	if err := json.Unmarshal([]byte(bundleJSON), &bundle); err != nil {
+		return time.Time{}, errors.New("invalid transparency log bundle")
+	}
+
+	// The signed entry timestamp covers the canonical JSON of the payload (keys sorted, as encoding/json does for maps)
+	var canonical bytes.Buffer
+	encoder := json.NewEncoder(&canonical)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(map[string]any{
+		"body":           bundle.Payload.Body,
+		"integratedTime": bundle.Payload.IntegratedTime,
+		"logIndex":       bundle.Payload.LogIndex,
+		"logID":          bundle.Payload.LogID,
+	}); err != nil {
+		return time.Time{}, err
+	}
+	signed := bytes.TrimSuffix(canonical.Bytes(), []byte("\n"))
+	if !slices.ContainsFunc(v.keyless.RekorKeys, func(key crypto.PublicKey) bool {
+		return verifyWithKey(key, signed, bundle.SignedEntryTimestamp) == nil
+	}) {
+		return time.Time{}, errors.New("transparency log entry is not signed by a trusted log")
+	}
+
+	body, err := base64.StdEncoding.DecodeString(bundle.Payload.Body)
+	if err != nil {
+		return time.Time{}, errors.New("invalid transparency log entry")
+	}
+	var entry hashedRekord
+	if err := json.Unmarshal(body, &entry); err != nil || entry.Kind != "hashedrekord" {
+		return time.Time{}, errors.New("transparency log entry is not a hashedrekord")
+	}
+	hash := sha256.Sum256(payload)
+	if entry.Spec.Data.Hash.Algorithm != "sha256" || entry.Spec.Data.Hash.Value != hex.EncodeToString(hash[:]) {
+		return time.Time{}, errors.New("transparency log entry is for another payload")
+	}
+	if !bytes.Equal(entry.Spec.Signature.Content, sig) {
+		return time.Time{}, errors.New("transparency log entry is for another signature")
+	}
+	logged, err := parseCertificate(string(entry.Spec.Signature.PublicKey.Content))
+	if err != nil || !logged.Equal(cert) {
+		return time.Time{}, errors.New("transparency log entry is for another certificate")
+	}
+	return time.Unix(bundle.Payload.IntegratedTime, 0), nil
+}
+
+// certificateIssuer returns the OIDC issuer recorded in a Fulcio certificate
+func certificateIssuer(cert *x509.Certificate) string {
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(fulcioIssuerV2OID):
+			var issuer string
+			if _, err := asn1.Unmarshal(ext.Value, &issuer); err == nil {
+				return issuer
+			}
+		case ext.Id.Equal(fulcioIssuerV1OID):
+			return string(ext.Value)
+		}
+	}
+	return ""
+}
+
+// certificateIdentities returns the signer identities of a keyless certificate (emails and URIs)
+func certificateIdentities(cert *x509.Certificate) []string {
+	identities := slices.Clone(cert.EmailAddresses)
+	for _, uri := range cert.URIs {
+		identities = append(identities, uri.String())
+	}
+	return identities
+}
+
+// certificateIdentity returns the signer identity of a keyless certificate for messages
+func certificateIdentity(cert *x509.Certificate) string {
+	if identities := certificateIdentities(cert); len(identities) > 0 {
+		return identities[0]
+	}
+	return cert.Subject.String()
+}
+
+// checkPayloadDigest checks that a simple signing payload is about digest
+func checkPayloadDigest(payload []byte, digest string) error {
+	var simpleSigning struct {
+		Critical struct {
+			Image struct {
+				DockerManifestDigest string `json:"docker-manifest-digest"`
+			} `json:"image"`
+		} `json:"critical"`
+	}
+	if err := json.Unmarshal(payload, &simpleSigning); err != nil {
+		return errors.New("signature payload is not a simple signing document")
+	}
+	if simpleSigning.Critical.Image.DockerManifestDigest != digest {
+		return fmt.Errorf("signature is for %s, not %s", simpleSigning.Critical.Image.DockerManifestDigest, digest)
+	}
+	return nil
+}
+
+// verifyWithKey verifies sig over payload the way cosign signs (SHA-256, except Ed25519)
+func verifyWithKey(key crypto.PublicKey, payload, sig []byte) error {
+	hash := sha256.Sum256(payload)
+	switch pub := key.(type) {
+	case *ecdsa.PublicKey:
+		if ecdsa.VerifyASN1(pub, hash[:], sig) {
+			return nil
+		}
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, hash[:], sig)
+	case ed25519.PublicKey:
+		if ed25519.Verify(pub, payload, sig) {
+			return nil
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+	return errors.New("invalid signature")
+}
+
+// readPayload reads a signature layer, refusing payloads over maxSignaturePayload
+func readPayload(open func() (io.ReadCloser, error)) ([]byte, error) {
+	rc, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, io.LimitReader(rc, maxSignaturePayload+1)); err != nil {
+		return nil, err
+	}
+	if buf.Len() > maxSignaturePayload {
+		return nil, errors.New("signature payload too large")
+	}
+	return buf.Bytes(), nil
+}
+
+// CachedSignatureVerifier caches signature results by image digest
+type CachedSignatureVerifier struct {
+	verifier SignatureVerifier
+	cache    cache.Cache
+}
+
+// NewCachedSignatureVerifier wraps verifier with a cache; a nil cache disables caching
+func NewCachedSignatureVerifier(verifier SignatureVerifier, c cache.Cache) *CachedSignatureVerifier {
+	return &CachedSignatureVerifier{verifier: verifier, cache: c}
+}
+
+// VerifySignature returns the cached result for imageRef or verifies and caches it
+func (cv *CachedSignatureVerifier) VerifySignature(ctx context.Context, imageRef string) (*SignatureResult, error) {
+	key := "signature:" + imageRef
+	if cv.cache != nil {
+		var cached SignatureResult
+		if err := cv.cache.Get(ctx, key, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	result, err := cv.verifier.VerifySignature(ctx, imageRef)
+	if err != nil {
+		return nil, err
+	}
+	if cv.cache != nil {
+		ttl := UnverifiedSignatureCacheTTL
+		if result.Verified {
+			ttl = SignatureCacheTTL
+		}
+		if err := cv.cache.Set(ctx, key, result, ttl); err != nil {
+			logging.Logger.Warn("Failed to cache signature result",
+				zap.String("image", imageRef),
+				zap.Error(err))
+		}
+	}
+	return result, nil
+}
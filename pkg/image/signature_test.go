@@ -0,0 +1,296 @@
+package image_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	ggcrtypes "github.com/google/go-containerregistry/pkg/v1/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+// countingVerifier returns a fixed result and counts lookups
+type countingVerifier struct {
+	result *image.SignatureResult
+	calls  int
+}
+
+func (v *countingVerifier) VerifySignature(context.Context, string) (*image.SignatureResult, error) {
+	v.calls++
+	return v.result, nil
+}
+
+var _ = Describe("Image signatures", func() {
+	Describe("CosignVerifier", func() {
+		var (
+			server   *httptest.Server
+			imageRef string
+			key      *ecdsa.PrivateKey
+		)
+
+		BeforeEach(func() {
+			server = httptest.NewServer(registry.New())
+			DeferCleanup(server.Close)
+
+			img, err := random.Image(256, 1)
+			Expect(err).NotTo(HaveOccurred())
+			ref, err := name.ParseReference(strings.TrimPrefix(server.URL, "http://") + "/acme/web:1.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(remote.Write(ref, img)).To(Succeed())
+			digest, err := img.Digest()
+			Expect(err).NotTo(HaveOccurred())
+			imageRef = ref.Context().Digest(digest.String()).String()
+
+			key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		// payloadFor is the simple signing payload of imageRef
+		payloadFor := func() []byte {
+			ref, err := name.NewDigest(imageRef)
+			Expect(err).NotTo(HaveOccurred())
+			return []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":%q},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`,
+				ref.Context().String(), ref.DigestStr()))
+		}
+
+		// push stores a cosign signature layer of imageRef with its annotations
+		push := func(payload []byte, annotations map[string]string) {
+			ref, err := name.NewDigest(imageRef)
+			Expect(err).NotTo(HaveOccurred())
+			sigImage, err := mutate.Append(empty.Image, mutate.Addendum{
+				Layer:       static.NewLayer(payload, ggcrtypes.MediaType("application/vnd.dev.cosign.simplesigning.v1+json")),
+				Annotations: annotations,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			sigTag := ref.Context().Tag(strings.Replace(ref.DigestStr(), ":", "-", 1) + ".sig")
+			Expect(remote.Write(sigTag, sigImage)).To(Succeed())
+		}
+
+		// signPayload signs payload the way cosign does
+		signPayload := func(signer *ecdsa.PrivateKey, payload []byte) []byte {
+			hash := sha256.Sum256(payload)
+			sig, err := ecdsa.SignASN1(rand.Reader, signer, hash[:])
+			Expect(err).NotTo(HaveOccurred())
+			return sig
+		}
+
+		// sign pushes a cosign signature of imageRef made with signer
+		sign := func(signer *ecdsa.PrivateKey) {
+			payload := payloadFor()
+			push(payload, map[string]string{image.CosignSignatureAnnotation: base64.StdEncoding.EncodeToString(signPayload(signer, payload))})
+		}
+
+		verifier := func() *image.CosignVerifier {
+			der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+			Expect(err).NotTo(HaveOccurred())
+			pub, err := image.ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+			Expect(err).NotTo(HaveOccurred())
+			return image.NewCosignVerifier([]image.TrustedKey{{Name: "release", Key: pub}}, nil, authn.NewMultiKeychain())
+		}
+
+		It("should verify an image signed with a trusted key", func() {
+			sign(key)
+
+			result, err := verifier().VerifySignature(context.Background(), imageRef)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(&image.SignatureResult{Signed: true, Verified: true, Signer: "release"}))
+		})
+
+		It("should report an unsigned image", func() {
+			result, err := verifier().VerifySignature(context.Background(), imageRef)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Signed).To(BeFalse())
+			Expect(result.Verified).To(BeFalse())
+		})
+
+		It("should not verify a signature made with an untrusted key", func() {
+			other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+			sign(other)
+
+			result, err := verifier().VerifySignature(context.Background(), imageRef)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Signed).To(BeTrue())
+			Expect(result.Verified).To(BeFalse())
+			Expect(result.Error).To(ContainSubstring("trusted key"))
+		})
+
+		Describe("keyless", func() {
+			var (
+				root      *x509.Certificate
+				rootKey   *ecdsa.PrivateKey
+				rekorKey  *ecdsa.PrivateKey
+				notBefore time.Time
+			)
+
+			BeforeEach(func() {
+				var err error
+				rootKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+				template := &x509.Certificate{
+					SerialNumber:          big.NewInt(1),
+					Subject:               pkix.Name{CommonName: "test fulcio"},
+					NotBefore:             time.Now().Add(-time.Hour),
+					NotAfter:              time.Now().Add(time.Hour),
+					KeyUsage:              x509.KeyUsageCertSign,
+					BasicConstraintsValid: true,
+					IsCA:                  true,
+				}
+				der, err := x509.CreateCertificate(rand.Reader, template, template, &rootKey.PublicKey, rootKey)
+				Expect(err).NotTo(HaveOccurred())
+				root, err = x509.ParseCertificate(der)
+				Expect(err).NotTo(HaveOccurred())
+
+				rekorKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+				notBefore = time.Now().Add(-time.Minute)
+			})
+
+			// signKeyless pushes a keyless signature by email from issuer, logged at integratedTime by log (nil: no bundle)
+			signKeyless := func(email, issuer string, log *ecdsa.PrivateKey, integratedTime time.Time) {
+				leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+				der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+					SerialNumber:    big.NewInt(2),
+					NotBefore:       notBefore,
+					NotAfter:        notBefore.Add(10 * time.Minute),
+					KeyUsage:        x509.KeyUsageDigitalSignature,
+					ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+					EmailAddresses:  []string{email},
+					ExtraExtensions: []pkix.Extension{{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}, Value: []byte(issuer)}},
+				}, root, &leafKey.PublicKey, rootKey)
+				Expect(err).NotTo(HaveOccurred())
+				certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+				payload := payloadFor()
+				sig := signPayload(leafKey, payload)
+				annotations := map[string]string{
+					image.CosignSignatureAnnotation:   base64.StdEncoding.EncodeToString(sig),
+					image.CosignCertificateAnnotation: string(certPEM),
+				}
+				if log != nil {
+					hash := sha256.Sum256(payload)
+					body, err := json.Marshal(map[string]any{
+						"apiVersion": "0.0.1",
+						"kind":       "hashedrekord",
+						"spec": map[string]any{
+							"data":      map[string]any{"hash": map[string]string{"algorithm": "sha256", "value": hex.EncodeToString(hash[:])}},
+							"signature": map[string]any{"content": sig, "publicKey": map[string]any{"content": certPEM}},
+						},
+					})
+					Expect(err).NotTo(HaveOccurred())
+					entry := fmt.Sprintf(`{"body":%q,"integratedTime":%d,"logID":"c0ffee","logIndex":42}`,
+						base64.StdEncoding.EncodeToString(body), integratedTime.Unix())
+					annotations[image.CosignBundleAnnotation] = fmt.Sprintf(`{"SignedEntryTimestamp":%q,"Payload":%s}`,
+						base64.StdEncoding.EncodeToString(signPayload(log, []byte(entry))), entry)
+				}
+				push(payload, annotations)
+			}
+
+			keylessVerifier := func() *image.CosignVerifier {
+				roots := x509.NewCertPool()
+				roots.AddCert(root)
+				return image.NewCosignVerifier(nil, &image.KeylessPolicy{
+					Roots:      roots,
+					Identities: map[string][]string{"https://token.example.com": {"*@acme.dev"}},
+					RekorKeys:  []crypto.PublicKey{&rekorKey.PublicKey},
+				}, authn.NewMultiKeychain())
+			}
+
+			verify := func() *image.SignatureResult {
+				result, err := keylessVerifier().VerifySignature(context.Background(), imageRef)
+				Expect(err).NotTo(HaveOccurred())
+				return result
+			}
+
+			It("should verify an allowed identity logged while its certificate was valid", func() {
+				signKeyless("release@acme.dev", "https://token.example.com", rekorKey, notBefore.Add(time.Minute))
+
+				Expect(verify()).To(Equal(&image.SignatureResult{Signed: true, Verified: true, Signer: "release@acme.dev"}))
+			})
+
+			It("should not verify other identities of a trusted issuer", func() {
+				signKeyless("mallory@evil.dev", "https://token.example.com", rekorKey, notBefore.Add(time.Minute))
+
+				result := verify()
+				Expect(result.Verified).To(BeFalse())
+				Expect(result.Error).To(ContainSubstring("not an allowed identity"))
+			})
+
+			It("should not verify untrusted issuers", func() {
+				signKeyless("release@acme.dev", "https://other.example.com", rekorKey, notBefore.Add(time.Minute))
+
+				Expect(verify().Error).To(ContainSubstring("issuer"))
+			})
+
+			It("should not treat keyless signatures without a log entry as signed by the certificate", func() {
+				signKeyless("release@acme.dev", "https://token.example.com", nil, time.Time{})
+
+				result := verify()
+				Expect(result.Verified).To(BeFalse())
+				Expect(result.Error).To(ContainSubstring("no transparency log entry"))
+			})
+
+			It("should not verify entries signed by an untrusted log", func() {
+				other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+				signKeyless("release@acme.dev", "https://token.example.com", other, notBefore.Add(time.Minute))
+
+				Expect(verify().Error).To(ContainSubstring("not signed by a trusted log"))
+			})
+
+			It("should not verify signatures logged after the certificate expired", func() {
+				signKeyless("release@acme.dev", "https://token.example.com", rekorKey, notBefore.Add(time.Hour))
+
+				result := verify()
+				Expect(result.Verified).To(BeFalse())
+				Expect(result.Error).To(ContainSubstring("not trusted"))
+			})
+		})
+	})
+
+	It("should cache verified results", func() {
+		mock := &countingVerifier{result: &image.SignatureResult{Signed: true, Verified: true, Signer: "release"}}
+		cached := image.NewCachedSignatureVerifier(mock, cache.NewMemoryCache())
+
+		for i := 0; i < 2; i++ {
+			result, err := cached.VerifySignature(context.Background(), "registry.example.com/web@sha256:abc")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Signer).To(Equal("release"))
+		}
+		Expect(mock.calls).To(Equal(1))
+	})
+
+	It("should read lissto.dev/require-signature", func() {
+		Expect(image.RequiresSignature(types.ServiceConfig{Labels: types.Labels{image.RequireSignatureLabel: "true"}})).To(BeTrue())
+		Expect(image.RequiresSignature(types.ServiceConfig{})).To(BeFalse())
+	})
+})
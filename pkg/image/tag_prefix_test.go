@@ -0,0 +1,100 @@
+package image_test
+
+import (
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+var _ = Describe("Per-service tag prefix", func() {
+	var (
+		checker  *mockImageChecker
+		resolver *image.ImageResolver
+		config   image.ResolutionConfig
+	)
+
+	BeforeEach(func() {
+		checker = &mockImageChecker{existingImages: map[string]bool{
+			"ghcr.io/acme/monorepo:api-abc123":    true,
+			"ghcr.io/acme/monorepo:worker-abc123": true,
+			"ghcr.io/acme/monorepo:web-main":      true,
+		}}
+		resolver = image.NewImageResolver("", "", checker)
+		config = image.ResolutionConfig{
+			Commit:              "abc123",
+			Branch:              "main",
+			ComposeRegistry:     "ghcr.io",
+			ComposeRepository:   "acme/monorepo",
+			TagPrefixPerService: true,
+		}
+	})
+
+	service := func(name string, labels types.Labels) types.ServiceConfig {
+		return types.ServiceConfig{Name: name, Build: &types.BuildConfig{Context: "./" + name}, Labels: labels}
+	}
+
+	It("should resolve each service to its own tag of the shared repository", func() {
+		for _, name := range []string{"api", "worker"} {
+			result, err := resolver.ResolveImageDetailed(service(name, nil), config)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Selected).To(Equal("ghcr.io/acme/monorepo:" + name + "-abc123"))
+			Expect(result.Method).To(Equal(image.TagSourceCommit))
+		}
+	})
+
+	It("should prefix branch and floating tags as well", func() {
+		result, err := resolver.ResolveImageDetailed(service("web", nil), config)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Selected).To(Equal("ghcr.io/acme/monorepo:web-main"))
+
+		var urls []string
+		for _, candidate := range result.Candidates {
+			urls = append(urls, candidate.ImageURL)
+		}
+		Expect(urls).To(Equal([]string{"ghcr.io/acme/monorepo:web-abc123", "ghcr.io/acme/monorepo:web-main"}))
+
+		plan := resolver.PlanCandidates(service("web", nil), config)
+		Expect(plan.Candidates[len(plan.Candidates)-1].ImageURL).To(Equal("ghcr.io/acme/monorepo:web-latest"))
+	})
+
+	It("should let the service label override the prefix", func() {
+		checker.existingImages["ghcr.io/acme/monorepo:service-api-abc123"] = true
+		result, err := resolver.ResolveImageDetailed(service("api", types.Labels{
+			image.TagServicePrefixLabel: "service-api-",
+		}), config)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Selected).To(Equal("ghcr.io/acme/monorepo:service-api-abc123"))
+	})
+
+	It("should apply the label without the x-lissto setting", func() {
+		config.TagPrefixPerService = false
+		result, err := resolver.ResolveImageDetailed(service("worker", types.Labels{
+			image.TagServicePrefixLabel: "worker-",
+		}), config)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Selected).To(Equal("ghcr.io/acme/monorepo:worker-abc123"))
+	})
+
+	It("should keep explicit tags as written", func() {
+		checker.existingImages["ghcr.io/acme/monorepo:pinned"] = true
+		result, err := resolver.ResolveImageDetailed(service("api", types.Labels{"lissto.dev/tag": "pinned"}), config)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Selected).To(Equal("ghcr.io/acme/monorepo:pinned"))
+	})
+
+	It("should not prefix tags when disabled", func() {
+		config.TagPrefixPerService = false
+		plan := resolver.PlanCandidates(service("api", nil), config)
+		Expect(plan.Candidates[0].ImageURL).To(Equal("ghcr.io/acme/monorepo:abc123"))
+	})
+
+	It("should read tagPrefixPerService from x-lissto", func() {
+		project := &types.Project{Extensions: types.Extensions{
+			"x-lissto": map[string]interface{}{"repository": "acme/monorepo", "tagPrefixPerService": true},
+		}}
+		Expect(compose.ExtractLisstoConfig(project).TagPrefixPerService).To(BeTrue())
+	})
+})
@@ -0,0 +1,134 @@
+package image
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// Tag sources used to build image tag candidates
+const (
+	TagSourceOriginal = "original" // Tag from the docker-compose image field
+	TagSourceLabel    = "label"    // lissto.dev/tag service label
+	TagSourceCommit   = "commit"   // Git commit of the request
+	TagSourceBranch   = "branch"   // Git branch of the request
+	TagSourceLatest   = "latest"   // The floating default tag ("latest" unless configured)
+)
+
+// TagServicePrefixLabel sets the prefix of a service's commit, branch and floating tags (e.g. "api-")
+const TagServicePrefixLabel = "lissto.dev/tag-service-prefix"
+
+// DefaultFloatingTag is the tag tried by the latest source when none is configured
+const DefaultFloatingTag = "latest"
+
+// tagPattern is the Docker reference grammar for tags
+var tagPattern = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+
+// DefaultTagSources is the candidate order used when none is configured
+var DefaultTagSources = []string{
+	TagSourceOriginal,
+	TagSourceLabel,
+	TagSourceCommit,
+	TagSourceBranch,
+	TagSourceLatest,
+}
+
+// ValidateTagSources checks a configured tag source list
+// Sources must be known and unique; the list may omit any source (e.g. latest) but not be empty
+func ValidateTagSources(sources []string) error {
+	if len(sources) == 0 {
+		return fmt.Errorf("tag sources must not be empty")
+	}
+
+	seen := make(map[string]bool, len(sources))
+	for _, source := range sources {
+		if !isKnownTagSource(source) {
+			return fmt.Errorf("unknown tag source %q (valid: %s)", source, strings.Join(DefaultTagSources, ", "))
+		}
+		if seen[source] {
+			return fmt.Errorf("duplicate tag source %q", source)
+		}
+		seen[source] = true
+	}
+	return nil
+}
+
+// SetTagSources sets the order in which tag candidates are tried
+// An empty list restores DefaultTagSources
+func (ir *ImageResolver) SetTagSources(sources []string) error {
+	if len(sources) == 0 {
+		ir.tagSources = nil
+		return nil
+	}
+	if err := ValidateTagSources(sources); err != nil {
+		return err
+	}
+	ir.tagSources = append([]string(nil), sources...)
+	return nil
+}
+
+// TagSources returns the tag source order in effect
+func (ir *ImageResolver) TagSources() []string {
+	if len(ir.tagSources) == 0 {
+		return DefaultTagSources
+	}
+	return ir.tagSources
+}
+
+func isKnownTagSource(source string) bool {
+	for _, known := range DefaultTagSources {
+		if source == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTag checks that tag is a valid image tag
+func ValidateTag(tag string) error {
+	if !tagPattern.MatchString(tag) {
+		return fmt.Errorf("invalid image tag %q", tag)
+	}
+	return nil
+}
+
+// SetDefaultTag sets the floating tag tried by the latest source (e.g. "stable" or "main")
+// An empty tag restores DefaultFloatingTag
+func (ir *ImageResolver) SetDefaultTag(tag string) error {
+	if tag == "" {
+		ir.defaultTag = ""
+		return nil
+	}
+	if err := ValidateTag(tag); err != nil {
+		return err
+	}
+	ir.defaultTag = tag
+	return nil
+}
+
+// DefaultTag returns the floating tag in effect
+func (ir *ImageResolver) DefaultTag() string {
+	if ir.defaultTag == "" {
+		return DefaultFloatingTag
+	}
+	return ir.defaultTag
+}
+
+// floatingTag returns the tag for the latest source: a valid per-blueprint override
+// (x-lissto.defaultTag) takes precedence over the configured default
+func (ir *ImageResolver) floatingTag(override string) string {
+	if override == "" {
+		return ir.DefaultTag()
+	}
+	if err := ValidateTag(override); err != nil {
+		logging.Logger.Warn("Ignoring invalid x-lissto.defaultTag",
+			zap.String("default_tag", override),
+			zap.Error(err))
+		return ir.DefaultTag()
+	}
+	return override
+}
@@ -0,0 +1,89 @@
+package image_test
+
+import (
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+var _ = Describe("Tag sources", func() {
+	var (
+		mockChecker *mockImageChecker
+		resolver    *image.ImageResolver
+		service     types.ServiceConfig
+		config      image.ResolutionConfig
+	)
+
+	BeforeEach(func() {
+		mockChecker = &mockImageChecker{existingImages: make(map[string]bool)}
+		resolver = image.NewImageResolver("registry.io", "team/", mockChecker)
+		service = types.ServiceConfig{Name: "api", Build: &types.BuildConfig{Context: "."}}
+		config = image.ResolutionConfig{Commit: "abc123", Branch: "feature"}
+	})
+
+	candidateSources := func(result *image.DetailedImageResolutionResult) []string {
+		sources := make([]string, 0, len(result.Candidates))
+		for _, candidate := range result.Candidates {
+			sources = append(sources, candidate.Source)
+		}
+		return sources
+	}
+
+	It("should use the default order when none is configured", func() {
+		result, err := resolver.ResolveImageDetailed(service, config)
+
+		Expect(err).To(HaveOccurred())
+		Expect(candidateSources(result)).To(Equal([]string{"commit", "branch", "latest"}))
+	})
+
+	It("should try branch before commit when reordered", func() {
+		Expect(resolver.SetTagSources([]string{"branch", "commit", "latest"})).To(Succeed())
+		mockChecker.existingImages["registry.io/team/api:abc123"] = true
+		mockChecker.existingImages["registry.io/team/api:feature"] = true
+
+		result, err := resolver.ResolveImageDetailed(service, config)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Method).To(Equal("branch"))
+		Expect(result.Selected).To(Equal("registry.io/team/api:feature"))
+	})
+
+	It("should fail when only latest would match and latest is disabled", func() {
+		Expect(resolver.SetTagSources([]string{"original", "label", "commit", "branch"})).To(Succeed())
+		mockChecker.existingImages["registry.io/team/api:latest"] = true
+
+		result, err := resolver.ResolveImageDetailed(service, config)
+
+		Expect(err).To(HaveOccurred())
+		Expect(candidateSources(result)).To(Equal([]string{"commit", "branch"}))
+	})
+
+	It("should restore the defaults for an empty list", func() {
+		Expect(resolver.SetTagSources([]string{"latest"})).To(Succeed())
+		Expect(resolver.SetTagSources(nil)).To(Succeed())
+		Expect(resolver.TagSources()).To(Equal(image.DefaultTagSources))
+	})
+
+	DescribeTable("ValidateTagSources",
+		func(sources []string, valid bool) {
+			err := image.ValidateTagSources(sources)
+			if valid {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("default order", image.DefaultTagSources, true),
+		Entry("without latest", []string{"commit", "branch"}, true),
+		Entry("unknown source", []string{"commit", "sha"}, false),
+		Entry("duplicate source", []string{"commit", "commit"}, false),
+		Entry("empty list", []string{}, false),
+	)
+
+	It("should reject invalid sources without changing the order", func() {
+		Expect(resolver.SetTagSources([]string{"tip"})).To(MatchError(ContainSubstring(`unknown tag source "tip"`)))
+		Expect(resolver.TagSources()).To(Equal(image.DefaultTagSources))
+	})
+})
@@ -0,0 +1,19 @@
+package image
+
+import (
+	"github.com/lissto-dev/api/internal/api/common"
+)
+
+// MethodUnpinnedFallback is reported when no candidate could be pinned to a digest and the
+// compose image tag is deployed as-is (only with ResolutionConfig.UnpinnedFallback)
+const MethodUnpinnedFallback = "unpinned-fallback"
+
+// UnpinnedFallbackCandidate returns the candidate recorded for an unpinned fallback image
+func UnpinnedFallbackCandidate(imageRef string) common.ImageCandidate {
+	return common.ImageCandidate{
+		ImageURL: imageRef,
+		Tag:      extractTag(imageRef),
+		Source:   MethodUnpinnedFallback,
+		Success:  true,
+	}
+}
@@ -0,0 +1,58 @@
+package image_test
+
+import (
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/image"
+)
+
+var _ = Describe("Unpinned fallback", func() {
+	var (
+		resolver *image.ImageResolver
+		service  types.ServiceConfig
+	)
+
+	BeforeEach(func() {
+		// The checker knows no images, so nothing can be pinned
+		resolver = image.NewImageResolver("registry.io", "team/", NewMockImageChecker())
+		service = types.ServiceConfig{
+			Name:  "api",
+			Image: "registry.io/team/api:v1.2.3",
+			Build: &types.BuildConfig{Context: "."},
+		}
+	})
+
+	It("should fail by default when no candidate can be pinned", func() {
+		result, err := resolver.ResolveImageDetailed(service, image.ResolutionConfig{})
+
+		Expect(err).To(HaveOccurred())
+		Expect(result.FinalImage).To(BeEmpty())
+		for _, candidate := range result.Candidates {
+			Expect(candidate.Source).NotTo(Equal(image.MethodUnpinnedFallback))
+		}
+	})
+
+	It("should fall back to the compose image tag when enabled", func() {
+		result, err := resolver.ResolveImageDetailed(service, image.ResolutionConfig{UnpinnedFallback: true})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.FinalImage).To(Equal("registry.io/team/api:v1.2.3"))
+		Expect(result.Method).To(Equal(image.MethodUnpinnedFallback))
+
+		last := result.Candidates[len(result.Candidates)-1]
+		Expect(last.Source).To(Equal(image.MethodUnpinnedFallback))
+		Expect(last.Tag).To(Equal("v1.2.3"))
+		Expect(last.Success).To(BeTrue())
+		Expect(last.Digest).To(BeEmpty())
+	})
+
+	It("should still fail for build-only services without an image tag", func() {
+		service.Image = ""
+
+		_, err := resolver.ResolveImageDetailed(service, image.ResolutionConfig{UnpinnedFallback: true})
+
+		Expect(err).To(HaveOccurred())
+	})
+})
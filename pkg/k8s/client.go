@@ -21,7 +21,10 @@ import (
 // Client wraps controller-runtime client for managing CRDs
 type Client struct {
 	client.Client
-	scheme *runtime.Scheme
+	scheme     *runtime.Scheme
+	restConfig *rest.Config
+
+	namespaceMetadata NamespaceMetadataFunc
 }
 
 // Scheme returns the runtime scheme for owner references
@@ -29,6 +32,31 @@ func (c *Client) Scheme() *runtime.Scheme {
 	return c.scheme
 }
 
+// RESTConfig returns the REST config used to build the client (nil for injected clients)
+func (c *Client) RESTConfig() *rest.Config {
+	return c.restConfig
+}
+
+// NewClientFromClient wraps an existing controller-runtime client (e.g. a fake client in tests)
+func NewClientFromClient(k8sClient client.Client, scheme *runtime.Scheme) *Client {
+	return &Client{
+		Client: k8sClient,
+		scheme: scheme,
+	}
+}
+
+// NewScheme creates a scheme with client-go types and the operator CRDs registered
+func NewScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, fmt.Errorf("failed to add client-go scheme: %w", err)
+	}
+	if err := envv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, fmt.Errorf("failed to add operator scheme: %w", err)
+	}
+	return scheme, nil
+}
+
 // NewClient creates a new Kubernetes client
 // If inCluster is true, uses in-cluster config. Otherwise, uses kubeconfig.
 func NewClient(inCluster bool, kubeconfigPath string) (*Client, error) {
@@ -55,26 +83,23 @@ func NewClient(inCluster bool, kubeconfigPath string) (*Client, error) {
 	}
 
 	// Create scheme and register our CRDs
-	scheme := runtime.NewScheme()
-	if err := clientgoscheme.AddToScheme(scheme); err != nil {
-		logging.Logger.Error("Failed to add client-go scheme", zap.Error(err))
-		return nil, fmt.Errorf("failed to add client-go scheme: %w", err)
-	}
-	if err := envv1alpha1.AddToScheme(scheme); err != nil {
-		logging.Logger.Error("Failed to add operator scheme", zap.Error(err))
-		return nil, fmt.Errorf("failed to add operator scheme: %w", err)
+	scheme, err := NewScheme()
+	if err != nil {
+		logging.Logger.Error("Failed to create scheme", zap.Error(err))
+		return nil, err
 	}
 
-	// Create controller-runtime client
-	k8sClient, err := client.New(config, client.Options{Scheme: scheme})
+	// Create controller-runtime client, with watch support for streaming endpoints
+	k8sClient, err := client.NewWithWatch(config, client.Options{Scheme: scheme})
 	if err != nil {
 		logging.Logger.Error("Failed to create client", zap.Error(err))
 		return nil, fmt.Errorf("failed to create client: %w", err)
 	}
 
 	return &Client{
-		Client: k8sClient,
-		scheme: scheme,
+		Client:     k8sClient,
+		scheme:     scheme,
+		restConfig: config,
 	}, nil
 }
 
@@ -118,6 +143,20 @@ func (c *Client) DeleteStack(ctx context.Context, namespace, name string) error
 	return c.Delete(ctx, stack)
 }
 
+// GetStackLifecycle retrieves a StackLifecycle resource
+func (c *Client) GetStackLifecycle(ctx context.Context, namespace, name string) (*envv1alpha1.StackLifecycle, error) {
+	lifecycle := &envv1alpha1.StackLifecycle{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, lifecycle); err != nil {
+		return nil, err
+	}
+	return lifecycle, nil
+}
+
+// UpdateStackLifecycle updates a StackLifecycle resource
+func (c *Client) UpdateStackLifecycle(ctx context.Context, lifecycle *envv1alpha1.StackLifecycle) error {
+	return c.Update(ctx, lifecycle)
+}
+
 // CreateBlueprint creates a Blueprint resource in the given namespace
 func (c *Client) CreateBlueprint(ctx context.Context, blueprint *envv1alpha1.Blueprint) error {
 	return c.Create(ctx, blueprint)
@@ -244,6 +283,22 @@ func (c *Client) UpdateSecret(ctx context.Context, secret *corev1.Secret) error
 	return c.Update(ctx, secret)
 }
 
+// ListSecretsWithLabels lists Secret resources with specific labels, in all namespaces when namespace is empty
+func (c *Client) ListSecretsWithLabels(ctx context.Context, namespace string, labels map[string]string) (*corev1.SecretList, error) {
+	secretList := &corev1.SecretList{}
+	opts := []client.ListOption{}
+	if namespace != "" {
+		opts = append(opts, client.InNamespace(namespace))
+	}
+	if len(labels) > 0 {
+		opts = append(opts, client.MatchingLabels(labels))
+	}
+	if err := c.List(ctx, secretList, opts...); err != nil {
+		return nil, err
+	}
+	return secretList, nil
+}
+
 // DeleteSecret deletes a Secret resource
 func (c *Client) DeleteSecret(ctx context.Context, namespace, name string) error {
 	secret := &corev1.Secret{}
@@ -0,0 +1,74 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// ExecRequest describes a command to run in a pod container
+type ExecRequest struct {
+	Namespace string
+	Pod       string
+	Container string
+	Command   []string
+}
+
+// Executor runs commands in pod containers, streaming output to the given writers
+type Executor interface {
+	Exec(ctx context.Context, req ExecRequest, stdout, stderr io.Writer) error
+}
+
+// SPDYExecutor runs commands through the pods/exec subresource
+type SPDYExecutor struct {
+	config    *rest.Config
+	clientset kubernetes.Interface
+}
+
+// NewSPDYExecutor creates an executor from a REST config
+func NewSPDYExecutor(config *rest.Config) (*SPDYExecutor, error) {
+	if config == nil {
+		return nil, fmt.Errorf("rest config is required for exec")
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	return &SPDYExecutor{
+		config:    config,
+		clientset: clientset,
+	}, nil
+}
+
+// Exec runs the command and streams stdout/stderr until it exits or ctx is cancelled
+func (e *SPDYExecutor) Exec(ctx context.Context, req ExecRequest, stdout, stderr io.Writer) error {
+	execReq := e.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(req.Namespace).
+		Name(req.Pod).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: req.Container,
+			Command:   req.Command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(e.config, "POST", execReq.URL())
+	if err != nil {
+		return fmt.Errorf("failed to create SPDY executor: %w", err)
+	}
+
+	return executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+}
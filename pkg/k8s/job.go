@@ -0,0 +1,29 @@
+package k8s
+
+import (
+	"context"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CreateJob creates a Job
+func (c *Client) CreateJob(ctx context.Context, job *batchv1.Job) error {
+	return c.Create(ctx, job)
+}
+
+// GetJob gets a Job by name
+func (c *Client) GetJob(ctx context.Context, namespace, name string) (*batchv1.Job, error) {
+	job := &batchv1.Job{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// DeleteJob deletes a Job and its pods; a Job that is already gone is not an error
+func (c *Client) DeleteJob(ctx context.Context, namespace, name string) error {
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	return client.IgnoreNotFound(c.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)))
+}
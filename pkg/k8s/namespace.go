@@ -6,13 +6,53 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// EnsureNamespace creates namespace if it doesn't exist
+const (
+	// ManagedByLabel marks namespaces managed by lissto
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	// ManagedByValue is the ManagedByLabel value for lissto namespaces
+	ManagedByValue = "lissto"
+)
+
+// NamespaceMetadataFunc returns the labels and annotations a managed namespace should carry
+type NamespaceMetadataFunc func(name string) (labels, annotations map[string]string)
+
+// SetNamespaceMetadata configures the metadata EnsureNamespace applies to namespaces
+func (c *Client) SetNamespaceMetadata(fn NamespaceMetadataFunc) {
+	c.namespaceMetadata = fn
+}
+
+// desiredNamespaceMetadata returns the labels and annotations for a namespace,
+// always including the managed-by label
+func (c *Client) desiredNamespaceMetadata(name string) (map[string]string, map[string]string) {
+	labels := map[string]string{}
+	annotations := map[string]string{}
+	if c.namespaceMetadata != nil {
+		extraLabels, extraAnnotations := c.namespaceMetadata(name)
+		for k, v := range extraLabels {
+			labels[k] = v
+		}
+		for k, v := range extraAnnotations {
+			annotations[k] = v
+		}
+	}
+	labels[ManagedByLabel] = ManagedByValue
+	return labels, annotations
+}
+
+// EnsureNamespace creates namespace if it doesn't exist and reconciles its lissto labels/annotations
+// Keys not managed by lissto are left untouched, so calling it repeatedly is safe
 func (c *Client) EnsureNamespace(ctx context.Context, name string) error {
+	labels, annotations := c.desiredNamespaceMetadata(name)
+
 	ns := &corev1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: name,
+			Name:        name,
+			Labels:      labels,
+			Annotations: annotations,
 		},
 	}
 
@@ -21,9 +61,69 @@ func (c *Client) EnsureNamespace(ctx context.Context, name string) error {
 		return nil // Successfully created
 	}
 
-	if errors.IsAlreadyExists(err) {
-		return nil // Already exists, OK
+	if !errors.IsAlreadyExists(err) {
+		return err // Real error
 	}
 
-	return err // Real error
+	// Already exists: bring labels/annotations back in line if they drifted
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing := &corev1.Namespace{}
+		if err := c.Get(ctx, client.ObjectKey{Name: name}, existing); err != nil {
+			return err
+		}
+
+		labelsChanged := mergeMetadata(&existing.Labels, labels)
+		annotationsChanged := mergeMetadata(&existing.Annotations, annotations)
+		if !labelsChanged && !annotationsChanged {
+			return nil
+		}
+		return c.Update(ctx, existing)
+	})
+}
+
+// mergeMetadata sets desired keys on target and reports whether anything changed
+func mergeMetadata(target *map[string]string, desired map[string]string) bool {
+	changed := false
+	for k, v := range desired {
+		if current, ok := (*target)[k]; ok && current == v {
+			continue
+		}
+		if *target == nil {
+			*target = make(map[string]string)
+		}
+		(*target)[k] = v
+		changed = true
+	}
+	return changed
+}
+
+// GetNamespace retrieves a namespace by name
+func (c *Client) GetNamespace(ctx context.Context, name string) (*corev1.Namespace, error) {
+	ns := &corev1.Namespace{}
+	if err := c.Get(ctx, client.ObjectKey{Name: name}, ns); err != nil {
+		return nil, err
+	}
+	return ns, nil
+}
+
+// UpdateNamespaceAnnotations sets and removes annotations on an existing namespace
+func (c *Client) UpdateNamespaceAnnotations(ctx context.Context, name string, set map[string]string, remove []string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := c.GetNamespace(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		changed := mergeMetadata(&existing.Annotations, set)
+		for _, key := range remove {
+			if _, ok := existing.Annotations[key]; ok {
+				delete(existing.Annotations, key)
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+		return c.Update(ctx, existing)
+	})
 }
@@ -0,0 +1,101 @@
+package k8s_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lissto-dev/api/pkg/authz"
+	"github.com/lissto-dev/api/pkg/k8s"
+	controllerconfig "github.com/lissto-dev/controller/pkg/config"
+)
+
+var _ = Describe("EnsureNamespace", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	newClient := func(objects ...client.Object) *k8s.Client {
+		scheme, err := k8s.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+
+		cfg := &controllerconfig.Config{}
+		cfg.Namespaces.Global = "lissto-global"
+		cfg.Namespaces.DeveloperPrefix = "dev-"
+		nsManager := authz.NewNamespaceManager(cfg)
+
+		c := k8s.NewClientFromClient(fakeClient, scheme)
+		c.SetNamespaceMetadata(nsManager.NamespaceMetadata(
+			map[string]string{"cost-center": "platform"},
+			map[string]string{"contact": "team@example.com"},
+		))
+		return c
+	}
+
+	getNamespace := func(c *k8s.Client, name string) *corev1.Namespace {
+		ns := &corev1.Namespace{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: name}, ns)).To(Succeed())
+		return ns
+	}
+
+	It("should set standard and default labels on create", func() {
+		c := newClient()
+		Expect(c.EnsureNamespace(ctx, "dev-alice")).To(Succeed())
+
+		ns := getNamespace(c, "dev-alice")
+		Expect(ns.Labels).To(Equal(map[string]string{
+			"app.kubernetes.io/managed-by": "lissto",
+			"lissto.dev/owner":             "alice",
+			"lissto.dev/scope":             "developer",
+			"cost-center":                  "platform",
+		}))
+		Expect(ns.Annotations).To(HaveKeyWithValue("contact", "team@example.com"))
+	})
+
+	It("should mark the global namespace with the global scope", func() {
+		c := newClient()
+		Expect(c.EnsureNamespace(ctx, "lissto-global")).To(Succeed())
+
+		ns := getNamespace(c, "lissto-global")
+		Expect(ns.Labels).To(HaveKeyWithValue("lissto.dev/scope", "global"))
+		Expect(ns.Labels).NotTo(HaveKey("lissto.dev/owner"))
+	})
+
+	It("should reconcile labels on an existing namespace and keep foreign keys", func() {
+		existing := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "dev-alice",
+				Labels: map[string]string{
+					"lissto.dev/owner": "mallory",
+					"team":             "payments",
+				},
+			},
+		}
+		c := newClient(existing)
+		Expect(c.EnsureNamespace(ctx, "dev-alice")).To(Succeed())
+
+		ns := getNamespace(c, "dev-alice")
+		Expect(ns.Labels).To(HaveKeyWithValue("app.kubernetes.io/managed-by", "lissto"))
+		Expect(ns.Labels).To(HaveKeyWithValue("lissto.dev/owner", "alice"))
+		Expect(ns.Labels).To(HaveKeyWithValue("lissto.dev/scope", "developer"))
+		Expect(ns.Labels).To(HaveKeyWithValue("team", "payments"))
+		Expect(ns.Annotations).To(HaveKeyWithValue("contact", "team@example.com"))
+	})
+
+	It("should be idempotent", func() {
+		c := newClient()
+		Expect(c.EnsureNamespace(ctx, "dev-alice")).To(Succeed())
+		version := getNamespace(c, "dev-alice").ResourceVersion
+
+		Expect(c.EnsureNamespace(ctx, "dev-alice")).To(Succeed())
+		Expect(getNamespace(c, "dev-alice").ResourceVersion).To(Equal(version))
+	})
+})
@@ -0,0 +1,44 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ListPodsWithLabels lists pods in a namespace matching all given labels
+func (c *Client) ListPodsWithLabels(ctx context.Context, namespace string, labels map[string]string) (*corev1.PodList, error) {
+	podList := &corev1.PodList{}
+	opts := []client.ListOption{client.InNamespace(namespace)}
+	if len(labels) > 0 {
+		opts = append(opts, client.MatchingLabels(labels))
+	}
+	if err := c.List(ctx, podList, opts...); err != nil {
+		return nil, err
+	}
+	return podList, nil
+}
+
+// DeletePod deletes a pod; a pod that is already gone is not an error
+func (c *Client) DeletePod(ctx context.Context, namespace, name string) error {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	return client.IgnoreNotFound(c.Delete(ctx, pod))
+}
+
+// WatchPodsWithLabels watches pods in a namespace matching all given labels
+// The caller stops the returned watch; clients without watch support return an error
+func (c *Client) WatchPodsWithLabels(ctx context.Context, namespace string, labels map[string]string) (watch.Interface, error) {
+	watcher, ok := c.Client.(client.WithWatch)
+	if !ok {
+		return nil, fmt.Errorf("kubernetes client does not support watches")
+	}
+	opts := []client.ListOption{client.InNamespace(namespace)}
+	if len(labels) > 0 {
+		opts = append(opts, client.MatchingLabels(labels))
+	}
+	return watcher.Watch(ctx, &corev1.PodList{}, opts...)
+}
@@ -0,0 +1,65 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RestartedAtAnnotation is the pod template annotation kubectl rollout restart sets
+const RestartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// ListWorkloadsWithPodLabels lists the Deployments and StatefulSets in a namespace whose
+// pod template carries all given labels (workload metadata labels are not stack-specific)
+func (c *Client) ListWorkloadsWithPodLabels(ctx context.Context, namespace string, podLabels map[string]string) ([]client.Object, error) {
+	selector := labels.SelectorFromSet(podLabels)
+	var workloads []client.Object
+
+	deployments := &appsv1.DeploymentList{}
+	if err := c.List(ctx, deployments, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for i := range deployments.Items {
+		if selector.Matches(labels.Set(deployments.Items[i].Spec.Template.Labels)) {
+			workloads = append(workloads, &deployments.Items[i])
+		}
+	}
+
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := c.List(ctx, statefulSets, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for i := range statefulSets.Items {
+		if selector.Matches(labels.Set(statefulSets.Items[i].Spec.Template.Labels)) {
+			workloads = append(workloads, &statefulSets.Items[i])
+		}
+	}
+
+	return workloads, nil
+}
+
+// RestartWorkload triggers a rolling restart of a Deployment or StatefulSet,
+// like kubectl rollout restart, by stamping its pod template
+func (c *Client) RestartWorkload(ctx context.Context, workload client.Object, at time.Time) error {
+	var template *corev1.PodTemplateSpec
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		template = &w.Spec.Template
+	case *appsv1.StatefulSet:
+		template = &w.Spec.Template
+	default:
+		return fmt.Errorf("cannot restart %T", workload)
+	}
+
+	patch := client.MergeFrom(workload.DeepCopyObject().(client.Object))
+	if template.Annotations == nil {
+		template.Annotations = make(map[string]string)
+	}
+	template.Annotations[RestartedAtAnnotation] = at.UTC().Format(time.RFC3339)
+	return c.Patch(ctx, workload, patch)
+}
@@ -0,0 +1,154 @@
+// Package metrics exposes Prometheus metrics for the API server
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/pkg/cache"
+	"github.com/lissto-dev/api/pkg/image"
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// Metrics holds the collectors served on /metrics
+type Metrics struct {
+	registry *prometheus.Registry
+
+	RequestsTotal    *prometheus.CounterVec
+	RequestDuration  *prometheus.HistogramVec
+	ActiveStacks     prometheus.Gauge
+	ActiveBlueprints prometheus.Gauge
+
+	RegistryBreakerState     *prometheus.GaugeVec
+	RegistryBreakerRejection *prometheus.CounterVec
+}
+
+// New creates the metrics with their own registry, including Go and process collectors
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		RequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests by route pattern, method and status code.",
+		}, []string{"route", "method", "status"}),
+		RequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by route pattern and method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		ActiveStacks: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "lissto_stacks",
+			Help: "Number of stacks across all namespaces.",
+		}),
+		ActiveBlueprints: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "lissto_blueprints",
+			Help: "Number of blueprints across all namespaces.",
+		}),
+		RegistryBreakerState: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "lissto_registry_breaker_state",
+			Help: "Registry circuit breaker state by registry host (0 closed, 1 half-open, 2 open).",
+		}, []string{"registry"}),
+		RegistryBreakerRejection: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "lissto_registry_breaker_rejections_total",
+			Help: "Registry calls failed fast by an open circuit breaker, by registry host.",
+		}, []string{"registry"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.RequestsTotal,
+		m.RequestDuration,
+		m.ActiveStacks,
+		m.ActiveBlueprints,
+		m.RegistryBreakerState,
+		m.RegistryBreakerRejection,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// BreakerStateChanged implements image.BreakerObserver
+func (m *Metrics) BreakerStateChanged(registry string, state image.BreakerState) {
+	m.RegistryBreakerState.WithLabelValues(registry).Set(float64(state))
+}
+
+// BreakerRejected implements image.BreakerObserver
+func (m *Metrics) BreakerRejected(registry string) {
+	m.RegistryBreakerRejection.WithLabelValues(registry).Inc()
+}
+
+// CacheUsageReporter reports the usage of a size-bounded cache
+type CacheUsageReporter interface {
+	Usage() cache.Usage
+}
+
+// ObserveCache exposes the cache's entry count, memory use, evictions and rejected entries
+func (m *Metrics) ObserveCache(reporter CacheUsageReporter) {
+	m.registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "lissto_cache_entries",
+			Help: "Entries held in the in-memory cache.",
+		}, func() float64 { return float64(reporter.Usage().Entries) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "lissto_cache_bytes",
+			Help: "Bytes held in the in-memory cache.",
+		}, func() float64 { return float64(reporter.Usage().Bytes) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "lissto_cache_evictions_total",
+			Help: "Cache entries evicted to stay within the memory budget.",
+		}, func() float64 { return float64(reporter.Usage().Evictions) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "lissto_cache_rejected_total",
+			Help: "Cache entries not stored because they exceed the per-entry size limit.",
+		}, func() float64 { return float64(reporter.Usage().Rejected) }),
+	)
+}
+
+// ResourceCounter counts Lissto resources across all namespaces
+type ResourceCounter interface {
+	CountStacks(ctx context.Context) (int, error)
+	CountBlueprints(ctx context.Context) (int, error)
+}
+
+// RefreshResourceGauges updates the stack and blueprint gauges; failed counts keep the previous value
+func (m *Metrics) RefreshResourceGauges(ctx context.Context, counter ResourceCounter) {
+	if stacks, err := counter.CountStacks(ctx); err != nil {
+		logging.Logger.Warn("Failed to count stacks for metrics", zap.Error(err))
+	} else {
+		m.ActiveStacks.Set(float64(stacks))
+	}
+
+	if blueprints, err := counter.CountBlueprints(ctx); err != nil {
+		logging.Logger.Warn("Failed to count blueprints for metrics", zap.Error(err))
+	} else {
+		m.ActiveBlueprints.Set(float64(blueprints))
+	}
+}
+
+// StartResourceGauges refreshes the resource gauges immediately and then every interval until ctx is done
+func (m *Metrics) StartResourceGauges(ctx context.Context, counter ResourceCounter, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			m.RefreshResourceGauges(ctx, counter)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,57 @@
+// Package notify delivers stack lifecycle events to external systems
+package notify
+
+import (
+	"sort"
+	"time"
+
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+// Event types dispatched after successful stack operations
+const (
+	EventStackCreated = "stack.created"
+	EventStackUpdated = "stack.updated"
+	EventStackDeleted = "stack.deleted"
+)
+
+// Event is the payload sent to webhook targets
+type Event struct {
+	Type      string    `json:"type"`           // One of the Event* constants
+	StackID   string    `json:"stack_id"`       // Scoped identifier (e.g., "alice/stack-20240101-120000")
+	Namespace string    `json:"namespace"`      // Namespace the stack lives in
+	User      string    `json:"user"`           // User who performed the operation
+	Timestamp time.Time `json:"timestamp"`      // When the operation completed (UTC)
+	URLs      []string  `json:"urls,omitempty"` // Exposed service URLs, sorted
+}
+
+// Notifier dispatches events; implementations must not block the caller
+type Notifier interface {
+	Notify(event Event)
+}
+
+// NopNotifier discards all events
+type NopNotifier struct{}
+
+// Notify implements Notifier
+func (NopNotifier) Notify(Event) {}
+
+// NewStackEvent builds an event for a stack, collecting the exposed URLs from its images
+func NewStackEvent(eventType, stackID string, stack *envv1alpha1.Stack, user string) Event {
+	var urls []string
+	for _, info := range stack.Spec.Images {
+		if info.URL != "" {
+			urls = append(urls, info.URL)
+		}
+	}
+	sort.Strings(urls)
+
+	return Event{
+		Type:      eventType,
+		StackID:   stackID,
+		Namespace: stack.Namespace,
+		User:      user,
+		Timestamp: time.Now().UTC(),
+		URLs:      urls,
+	}
+}
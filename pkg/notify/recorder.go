@@ -0,0 +1,28 @@
+package notify
+
+import "sync"
+
+// RecordingNotifier keeps dispatched events in memory, for use in tests
+type RecordingNotifier struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+// NewRecordingNotifier creates an empty recording notifier
+func NewRecordingNotifier() *RecordingNotifier {
+	return &RecordingNotifier{}
+}
+
+// Notify implements Notifier
+func (r *RecordingNotifier) Notify(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// Events returns a copy of the recorded events in dispatch order
+func (r *RecordingNotifier) Events() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event(nil), r.events...)
+}
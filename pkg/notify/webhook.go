@@ -0,0 +1,213 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lissto-dev/api/pkg/logging"
+)
+
+// Webhook request headers
+const (
+	SignatureHeader = "X-Lissto-Signature" // "sha256=<hex HMAC of the body>", only set when the target has a secret
+	EventHeader     = "X-Lissto-Event"
+)
+
+// Delivery defaults
+const (
+	DefaultMaxAttempts    = 5
+	DefaultInitialBackoff = time.Second
+	DefaultRequestTimeout = 10 * time.Second
+)
+
+// WebhookTarget is an endpoint that receives events
+type WebhookTarget struct {
+	URL string `yaml:"url"`
+	// Secret signs the payload with HMAC-SHA256; empty sends unsigned requests
+	Secret string `yaml:"secret"`
+	// Events limits the event types sent to this target; empty sends all events
+	Events []string `yaml:"events"`
+}
+
+// ValidateWebhookTargets checks configured webhook targets
+func ValidateWebhookTargets(targets []WebhookTarget) error {
+	for i, target := range targets {
+		if target.URL == "" {
+			return fmt.Errorf("webhook %d: url must not be empty", i)
+		}
+		for _, eventType := range target.Events {
+			if eventType != EventStackCreated && eventType != EventStackUpdated && eventType != EventStackDeleted {
+				return fmt.Errorf("webhook %d: unknown event %q", i, eventType)
+			}
+		}
+	}
+	return nil
+}
+
+// WebhookNotifier posts events to webhook targets in the background
+// Failed deliveries are retried with exponential backoff; after the last attempt the
+// payload is written to the error log as a dead letter
+type WebhookNotifier struct {
+	targets        []WebhookTarget
+	client         *http.Client
+	maxAttempts    int
+	initialBackoff time.Duration
+	wg             sync.WaitGroup
+}
+
+// NewWebhookNotifier creates a notifier for the given targets
+func NewWebhookNotifier(targets []WebhookTarget) *WebhookNotifier {
+	return &WebhookNotifier{
+		targets:        append([]WebhookTarget(nil), targets...),
+		client:         &http.Client{Timeout: DefaultRequestTimeout},
+		maxAttempts:    DefaultMaxAttempts,
+		initialBackoff: DefaultInitialBackoff,
+	}
+}
+
+// SetRetry configures the number of delivery attempts and the delay before the first retry
+// The delay doubles after every failed attempt
+func (n *WebhookNotifier) SetRetry(maxAttempts int, initialBackoff time.Duration) {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	n.maxAttempts = maxAttempts
+	n.initialBackoff = initialBackoff
+}
+
+// Notify implements Notifier; delivery happens asynchronously
+func (n *WebhookNotifier) Notify(event Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logging.Logger.Error("Failed to encode webhook event",
+			zap.String("event", event.Type),
+			zap.String("stack", event.StackID),
+			zap.Error(err))
+		return
+	}
+
+	for _, target := range n.targets {
+		if len(target.Events) > 0 && !slices.Contains(target.Events, event.Type) {
+			continue
+		}
+		n.wg.Add(1)
+		go func(target WebhookTarget) {
+			defer n.wg.Done()
+			n.deliver(target, event, payload)
+		}(target)
+	}
+}
+
+// Wait blocks until all in-flight deliveries have finished
+func (n *WebhookNotifier) Wait() {
+	n.wg.Wait()
+}
+
+// WaitTimeout waits like Wait for at most timeout and reports whether all deliveries finished
+// Deliveries still running afterwards, including retries waiting out their backoff, are abandoned
+func (n *WebhookNotifier) WaitTimeout(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// deliver sends the payload to a target, retrying transient failures
+func (n *WebhookNotifier) deliver(target WebhookTarget, event Event, payload []byte) {
+	backoff := n.initialBackoff
+	var lastErr error
+
+	for attempt := 1; attempt <= n.maxAttempts; attempt++ {
+		retryable, err := n.send(target, event.Type, payload)
+		if err == nil {
+			logging.Logger.Debug("Webhook delivered",
+				zap.String("url", logURL(target.URL)),
+				zap.String("event", event.Type),
+				zap.String("stack", event.StackID),
+				zap.Int("attempt", attempt))
+			return
+		}
+		lastErr = err
+		if !retryable || attempt == n.maxAttempts {
+			break
+		}
+
+		logging.Logger.Warn("Webhook delivery failed, retrying",
+			zap.String("url", logURL(target.URL)),
+			zap.String("event", event.Type),
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", backoff),
+			zap.Error(err))
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+
+	// Dead letter: keep the full payload so the event can be replayed by hand
+	logging.Logger.Error("Webhook delivery failed permanently",
+		zap.String("url", logURL(target.URL)),
+		zap.String("event", event.Type),
+		zap.String("stack", event.StackID),
+		zap.ByteString("payload", payload),
+		zap.Error(lastErr))
+}
+
+// send performs a single delivery attempt and reports whether a failure is worth retrying
+func (n *WebhookNotifier) send(target WebhookTarget, eventType string, payload []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, target.URL, bytes.NewReader(payload))
+	if err != nil {
+		return false, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, eventType)
+	if target.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(target.Secret, payload))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return true, nil
+	}
+	// Client errors will not succeed on retry, except rate limiting
+	retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retryable, fmt.Errorf("unexpected status %d", resp.StatusCode)
+}
+
+// logURL keeps only the scheme and host of a target URL, since webhook paths often embed tokens
+func logURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "[redacted]"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// Sign returns the signature header value for a payload
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
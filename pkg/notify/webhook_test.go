@@ -0,0 +1,190 @@
+package notify_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/lissto-dev/api/pkg/logging"
+	"github.com/lissto-dev/api/pkg/notify"
+)
+
+// webhookRequest is a request captured by the test server
+type webhookRequest struct {
+	body      []byte
+	signature string
+	event     string
+}
+
+var _ = Describe("WebhookNotifier", func() {
+	var (
+		mu       sync.Mutex
+		requests []webhookRequest
+		failures int32
+		status   int
+		server   *httptest.Server
+		event    notify.Event
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		failures = 0
+		status = http.StatusInternalServerError
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			requests = append(requests, webhookRequest{
+				body:      body,
+				signature: r.Header.Get(notify.SignatureHeader),
+				event:     r.Header.Get(notify.EventHeader),
+			})
+			mu.Unlock()
+			if atomic.AddInt32(&failures, -1) >= 0 {
+				w.WriteHeader(status)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		event = notify.Event{
+			Type:      notify.EventStackCreated,
+			StackID:   "alice/my-stack",
+			Namespace: "dev-alice",
+			User:      "alice",
+			Timestamp: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			URLs:      []string{"api-alice.dev.lissto.dev"},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	captured := func() []webhookRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]webhookRequest(nil), requests...)
+	}
+
+	It("should post a signed JSON payload", func() {
+		notifier := notify.NewWebhookNotifier([]notify.WebhookTarget{{URL: server.URL, Secret: "s3cret"}})
+		notifier.Notify(event)
+		notifier.Wait()
+
+		reqs := captured()
+		Expect(reqs).To(HaveLen(1))
+		Expect(reqs[0].event).To(Equal(notify.EventStackCreated))
+		Expect(reqs[0].signature).To(Equal(notify.Sign("s3cret", reqs[0].body)))
+
+		var received notify.Event
+		Expect(json.Unmarshal(reqs[0].body, &received)).To(Succeed())
+		Expect(received).To(Equal(event))
+	})
+
+	It("should retry server errors until delivery succeeds", func() {
+		failures = 2
+		notifier := notify.NewWebhookNotifier([]notify.WebhookTarget{{URL: server.URL}})
+		notifier.SetRetry(5, time.Millisecond)
+
+		notifier.Notify(event)
+		notifier.Wait()
+
+		reqs := captured()
+		Expect(reqs).To(HaveLen(3))
+		Expect(reqs[0].signature).To(BeEmpty())
+	})
+
+	It("should give up after the configured attempts", func() {
+		failures = 10
+		notifier := notify.NewWebhookNotifier([]notify.WebhookTarget{{URL: server.URL}})
+		notifier.SetRetry(3, time.Millisecond)
+
+		notifier.Notify(event)
+		notifier.Wait()
+
+		Expect(captured()).To(HaveLen(3))
+	})
+
+	It("should not retry client errors", func() {
+		failures = 10
+		status = http.StatusBadRequest
+		notifier := notify.NewWebhookNotifier([]notify.WebhookTarget{{URL: server.URL}})
+		notifier.SetRetry(3, time.Millisecond)
+
+		notifier.Notify(event)
+		notifier.Wait()
+
+		Expect(captured()).To(HaveLen(1))
+	})
+
+	It("should only send subscribed events", func() {
+		notifier := notify.NewWebhookNotifier([]notify.WebhookTarget{{URL: server.URL, Events: []string{notify.EventStackDeleted}}})
+
+		notifier.Notify(event)
+		notifier.Wait()
+
+		Expect(captured()).To(BeEmpty())
+	})
+
+	It("should stop waiting for deliveries after the timeout", func() {
+		failures = 10
+		notifier := notify.NewWebhookNotifier([]notify.WebhookTarget{{URL: server.URL}})
+		notifier.SetRetry(2, time.Hour)
+
+		notifier.Notify(event)
+		Expect(notifier.WaitTimeout(50 * time.Millisecond)).To(BeFalse())
+		Expect(captured()).To(HaveLen(1))
+	})
+
+	It("should report finished deliveries within the timeout", func() {
+		notifier := notify.NewWebhookNotifier([]notify.WebhookTarget{{URL: server.URL}})
+
+		notifier.Notify(event)
+		Expect(notifier.WaitTimeout(5 * time.Second)).To(BeTrue())
+		Expect(captured()).To(HaveLen(1))
+	})
+
+	It("should log only the scheme and host of failing targets", func() {
+		core, logs := observer.New(zapcore.WarnLevel)
+		previous := logging.Logger
+		logging.Logger = zap.New(core)
+		DeferCleanup(func() { logging.Logger = previous })
+
+		failures = 10
+		notifier := notify.NewWebhookNotifier([]notify.WebhookTarget{{URL: server.URL + "/hooks/t0ken?key=s3cret"}})
+		notifier.SetRetry(2, time.Millisecond)
+
+		notifier.Notify(event)
+		notifier.Wait()
+
+		entries := logs.All()
+		Expect(entries).To(HaveLen(2))
+		for _, entry := range entries {
+			Expect(entry.ContextMap()).To(HaveKeyWithValue("url", server.URL))
+		}
+	})
+
+	DescribeTable("ValidateWebhookTargets",
+		func(target notify.WebhookTarget, valid bool) {
+			err := notify.ValidateWebhookTargets([]notify.WebhookTarget{target})
+			if valid {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("all events", notify.WebhookTarget{URL: "https://hooks.example.com"}, true),
+		Entry("filtered events", notify.WebhookTarget{URL: "https://hooks.example.com", Events: []string{"stack.deleted"}}, true),
+		Entry("missing url", notify.WebhookTarget{}, false),
+		Entry("unknown event", notify.WebhookTarget{URL: "https://hooks.example.com", Events: []string{"stack.exploded"}}, false),
+	)
+})
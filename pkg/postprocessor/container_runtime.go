@@ -0,0 +1,126 @@
+package postprocessor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/logging"
+	"go.uber.org/zap"
+)
+
+const (
+	// WorkingDirLabel overrides the container working directory
+	WorkingDirLabel = "lissto.dev/working-dir"
+	// UserLabel overrides the user the container runs as ("uid" or "uid:gid")
+	UserLabel = "lissto.dev/user"
+)
+
+// ContainerRuntimeOverrider sets working directory and run-as user from lissto.dev labels
+// Kubernetes only accepts numeric IDs, so user names are skipped with a warning
+type ContainerRuntimeOverrider struct{}
+
+// NewContainerRuntimeOverrider creates a new container runtime overrider
+func NewContainerRuntimeOverrider() *ContainerRuntimeOverrider {
+	return &ContainerRuntimeOverrider{}
+}
+
+// OverrideRuntime applies working-dir and user overrides from service labels to Kubernetes objects
+// serviceLabelMap maps service name to its labels from docker-compose
+func (o *ContainerRuntimeOverrider) OverrideRuntime(objects []runtime.Object, serviceLabelMap map[string]map[string]string) []runtime.Object {
+	if len(serviceLabelMap) == 0 {
+		return objects
+	}
+
+	for _, obj := range objects {
+		switch resource := obj.(type) {
+		case *appsv1.Deployment:
+			// Match by deployment name (equals service name in Kompose)
+			if labels, exists := serviceLabelMap[resource.Name]; exists {
+				o.overrideContainers(resource.Spec.Template.Spec.Containers, labels, resource.Name)
+			}
+
+		case *appsv1.StatefulSet:
+			// Match by statefulset name (equals service name in Kompose)
+			if labels, exists := serviceLabelMap[resource.Name]; exists {
+				o.overrideContainers(resource.Spec.Template.Spec.Containers, labels, resource.Name)
+			}
+
+		case *corev1.Pod:
+			// Match by pod name or io.kompose.service label
+			serviceName := resource.Name
+			if komposeService, ok := resource.Labels["io.kompose.service"]; ok {
+				serviceName = komposeService
+			}
+			if labels, exists := serviceLabelMap[serviceName]; exists {
+				o.overrideContainers(resource.Spec.Containers, labels, serviceName)
+			}
+		}
+	}
+
+	return objects
+}
+
+// overrideContainers applies the label overrides to all containers of a pod
+func (o *ContainerRuntimeOverrider) overrideContainers(containers []corev1.Container, labels map[string]string, serviceName string) {
+	if workingDir := labels[WorkingDirLabel]; workingDir != "" {
+		for i := range containers {
+			containers[i].WorkingDir = workingDir
+		}
+		logging.Logger.Info("Overriding container working directory",
+			zap.String("service", serviceName),
+			zap.String("working_dir", workingDir))
+	}
+
+	userLabel := labels[UserLabel]
+	if userLabel == "" {
+		return
+	}
+
+	uid, gid, err := parseRunAsUser(userLabel)
+	if err != nil {
+		logging.Logger.Warn("Skipping lissto.dev/user label",
+			zap.String("service", serviceName),
+			zap.String("label_value", userLabel),
+			zap.Error(err))
+		return
+	}
+
+	for i := range containers {
+		if containers[i].SecurityContext == nil {
+			containers[i].SecurityContext = &corev1.SecurityContext{}
+		}
+		runAsUser := uid
+		containers[i].SecurityContext.RunAsUser = &runAsUser
+		if gid != nil {
+			runAsGroup := *gid
+			containers[i].SecurityContext.RunAsGroup = &runAsGroup
+		}
+	}
+	logging.Logger.Info("Overriding container user",
+		zap.String("service", serviceName),
+		zap.String("user", userLabel))
+}
+
+// parseRunAsUser parses "uid" or "uid:gid"; both must be numeric
+func parseRunAsUser(value string) (int64, *int64, error) {
+	userPart, groupPart, hasGroup := strings.Cut(value, ":")
+
+	uid, err := strconv.ParseInt(userPart, 10, 64)
+	if err != nil || uid < 0 {
+		return 0, nil, fmt.Errorf("user must be a numeric UID, got %q", userPart)
+	}
+	if !hasGroup {
+		return uid, nil, nil
+	}
+
+	gid, err := strconv.ParseInt(groupPart, 10, 64)
+	if err != nil || gid < 0 {
+		return 0, nil, fmt.Errorf("group must be a numeric GID, got %q", groupPart)
+	}
+	return uid, &gid, nil
+}
@@ -0,0 +1,93 @@
+package postprocessor_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/postprocessor"
+)
+
+var _ = Describe("ContainerRuntimeOverrider", func() {
+	var overrider *postprocessor.ContainerRuntimeOverrider
+
+	newDeployment := func(name string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: name, Image: name}},
+					},
+				},
+			},
+		}
+	}
+
+	override := func(labels map[string]string) corev1.Container {
+		result := overrider.OverrideRuntime([]runtime.Object{newDeployment("api")}, map[string]map[string]string{"api": labels})
+		return result[0].(*appsv1.Deployment).Spec.Template.Spec.Containers[0]
+	}
+
+	BeforeEach(func() {
+		overrider = postprocessor.NewContainerRuntimeOverrider()
+	})
+
+	It("should override the working directory", func() {
+		container := override(map[string]string{postprocessor.WorkingDirLabel: "/srv/app"})
+
+		Expect(container.WorkingDir).To(Equal("/srv/app"))
+		Expect(container.SecurityContext).To(BeNil())
+	})
+
+	It("should set runAsUser for a numeric user", func() {
+		container := override(map[string]string{postprocessor.UserLabel: "1000"})
+
+		Expect(container.SecurityContext).NotTo(BeNil())
+		Expect(container.SecurityContext.RunAsUser).To(HaveValue(Equal(int64(1000))))
+		Expect(container.SecurityContext.RunAsGroup).To(BeNil())
+	})
+
+	It("should set runAsGroup for uid:gid", func() {
+		container := override(map[string]string{postprocessor.UserLabel: "1000:2000"})
+
+		Expect(container.SecurityContext.RunAsUser).To(HaveValue(Equal(int64(1000))))
+		Expect(container.SecurityContext.RunAsGroup).To(HaveValue(Equal(int64(2000))))
+	})
+
+	It("should skip a non-numeric user and still apply the working directory", func() {
+		container := override(map[string]string{
+			postprocessor.UserLabel:       "www-data",
+			postprocessor.WorkingDirLabel: "/var/www",
+		})
+
+		Expect(container.SecurityContext).To(BeNil())
+		Expect(container.WorkingDir).To(Equal("/var/www"))
+	})
+
+	It("should keep an existing security context", func() {
+		deployment := newDeployment("api")
+		privileged := false
+		deployment.Spec.Template.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{Privileged: &privileged}
+
+		result := overrider.OverrideRuntime([]runtime.Object{deployment}, map[string]map[string]string{
+			"api": {postprocessor.UserLabel: "1000"},
+		})
+
+		securityContext := result[0].(*appsv1.Deployment).Spec.Template.Spec.Containers[0].SecurityContext
+		Expect(securityContext.Privileged).To(HaveValue(BeFalse()))
+		Expect(securityContext.RunAsUser).To(HaveValue(Equal(int64(1000))))
+	})
+
+	It("should leave services without labels untouched", func() {
+		result := overrider.OverrideRuntime([]runtime.Object{newDeployment("worker")}, map[string]map[string]string{
+			"api": {postprocessor.WorkingDirLabel: "/srv/app"},
+		})
+
+		container := result[0].(*appsv1.Deployment).Spec.Template.Spec.Containers[0]
+		Expect(container.WorkingDir).To(BeEmpty())
+	})
+})
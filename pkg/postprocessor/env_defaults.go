@@ -0,0 +1,74 @@
+package postprocessor
+
+import (
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// EnvDefaultsLabel opts a service out of its env's default variables and secrets when "false"
+const EnvDefaultsLabel = "lissto.dev/env-defaults"
+
+// EnvDefaultsInjector adds the env vars every stack of an env inherits to workload containers
+// Variables the container already sets (compose environment) are kept.
+type EnvDefaultsInjector struct{}
+
+// NewEnvDefaultsInjector creates a new env defaults injector
+func NewEnvDefaultsInjector() *EnvDefaultsInjector {
+	return &EnvDefaultsInjector{}
+}
+
+// Inject appends envVars to the containers of services that don't opt out
+// serviceLabelMap maps service name to its labels from docker-compose
+func (e *EnvDefaultsInjector) Inject(objects []runtime.Object, envVars []corev1.EnvVar, serviceLabelMap map[string]map[string]string) []runtime.Object {
+	if len(envVars) == 0 {
+		return objects
+	}
+
+	for _, obj := range objects {
+		switch resource := obj.(type) {
+		case *appsv1.Deployment:
+			e.injectPodSpec(&resource.Spec.Template.Spec, envVars, serviceLabelMap[resource.Name], resource.Name)
+		case *appsv1.StatefulSet:
+			e.injectPodSpec(&resource.Spec.Template.Spec, envVars, serviceLabelMap[resource.Name], resource.Name)
+		case *corev1.Pod:
+			serviceName := serviceNameOf(resource.Name, resource.Labels)
+			e.injectPodSpec(&resource.Spec, envVars, serviceLabelMap[serviceName], serviceName)
+		}
+	}
+
+	return objects
+}
+
+// injectPodSpec adds the env vars missing from each container unless the service opted out
+func (e *EnvDefaultsInjector) injectPodSpec(spec *corev1.PodSpec, envVars []corev1.EnvVar, labels map[string]string, serviceName string) {
+	if value := labels[EnvDefaultsLabel]; value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			logging.Logger.Warn("Ignoring invalid lissto.dev/env-defaults label",
+				zap.String("service", serviceName),
+				zap.String("label_value", value))
+		} else if !enabled {
+			logging.Logger.Info("Service opted out of env defaults", zap.String("service", serviceName))
+			return
+		}
+	}
+
+	for i := range spec.Containers {
+		container := &spec.Containers[i]
+		existing := make(map[string]bool, len(container.Env))
+		for _, envVar := range container.Env {
+			existing[envVar.Name] = true
+		}
+		for _, envVar := range envVars {
+			if !existing[envVar.Name] {
+				container.Env = append(container.Env, envVar)
+			}
+		}
+	}
+}
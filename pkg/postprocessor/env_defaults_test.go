@@ -0,0 +1,39 @@
+package postprocessor_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/postprocessor"
+)
+
+var _ = Describe("EnvDefaultsInjector", func() {
+	defaults := []corev1.EnvVar{{Name: "REGION", Value: "eu-west-1"}, {Name: "LOG_LEVEL", Value: "debug"}}
+
+	inject := func(labels map[string]string) []corev1.EnvVar {
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api"},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "api", Env: []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "info"}}}},
+					},
+				},
+			},
+		}
+		result := postprocessor.NewEnvDefaultsInjector().Inject([]runtime.Object{deployment}, defaults, map[string]map[string]string{"api": labels})
+		return result[0].(*appsv1.Deployment).Spec.Template.Spec.Containers[0].Env
+	}
+
+	It("should add defaults the container doesn't set", func() {
+		Expect(inject(nil)).To(Equal([]corev1.EnvVar{{Name: "LOG_LEVEL", Value: "info"}, {Name: "REGION", Value: "eu-west-1"}}))
+	})
+
+	It("should skip services that opt out", func() {
+		Expect(inject(map[string]string{postprocessor.EnvDefaultsLabel: "false"})).To(Equal([]corev1.EnvVar{{Name: "LOG_LEVEL", Value: "info"}}))
+	})
+})
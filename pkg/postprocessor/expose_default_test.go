@@ -0,0 +1,90 @@
+package postprocessor_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/preprocessor"
+)
+
+var _ = Describe("Blueprint expose default", func() {
+	internal := &preprocessor.IngressConfig{IngressClass: "nginx-internal", HostSuffix: ".dev.example.com", TLSSecret: "internal-tls"}
+	internet := &preprocessor.IngressConfig{IngressClass: "nginx-public", HostSuffix: ".example.com", TLSSecret: "public-tls"}
+
+	// render exposes the services with the blueprint's default and returns the ingresses by name
+	render := func(content string, internalConfig, internetConfig *preprocessor.IngressConfig) (map[string]*networkingv1.Ingress, error) {
+		project, err := loadProject(content)
+		Expect(err).NotTo(HaveOccurred())
+
+		exposePreprocessor, err := preprocessor.NewExposePreprocessor(internalConfig, internetConfig).
+			WithDefaultVisibility(compose.ExtractLisstoConfig(project).ExposeDefault)
+		if err != nil {
+			return nil, err
+		}
+		services, err := exposePreprocessor.ProcessServices(project.Services, "dev", "my-stack")
+		Expect(err).NotTo(HaveOccurred())
+		project.Services = services
+		objects, err := convertProject(project)
+		Expect(err).NotTo(HaveOccurred())
+
+		ingresses := map[string]*networkingv1.Ingress{}
+		for _, obj := range objects {
+			if ingress, ok := obj.(*networkingv1.Ingress); ok {
+				ingresses[ingress.Name] = ingress
+			}
+		}
+		return ingresses, nil
+	}
+
+	const demo = `
+x-lissto:
+  exposeDefault: internet
+services:
+  web:
+    image: nginx
+    ports:
+      - "8080:80"
+    labels:
+      lissto.dev/expose: "true"
+  admin:
+    image: nginx
+    ports:
+      - "8081:80"
+    labels:
+      lissto.dev/expose: internal
+`
+
+	It("should expose services with expose: true on the internet ingress", func() {
+		ingresses, err := render(demo, internal, internet)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(ingresses).To(HaveKey("web"))
+		Expect(*ingresses["web"].Spec.IngressClassName).To(Equal("nginx-public"))
+		Expect(ingresses["web"].Spec.Rules[0].Host).To(Equal("web-dev.example.com"))
+	})
+
+	It("should let the service label override the blueprint default", func() {
+		ingresses, err := render(demo, internal, internet)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(*ingresses["admin"].Spec.IngressClassName).To(Equal("nginx-internal"))
+	})
+
+	It("should reject a default visibility that is not configured", func() {
+		_, err := render(demo, internal, nil)
+		Expect(err).To(MatchError(ContainSubstring("'internet' visibility is not configured")))
+	})
+
+	It("should reject an unknown default visibility", func() {
+		_, err := render(`
+x-lissto:
+  exposeDefault: public
+services:
+  web:
+    image: nginx
+`, internal, internet)
+		Expect(err).To(MatchError(ContainSubstring("invalid x-lissto.exposeDefault")))
+	})
+})
@@ -0,0 +1,76 @@
+package postprocessor_test
+
+import (
+	"github.com/compose-spec/compose-go/v2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"github.com/lissto-dev/api/pkg/preprocessor"
+)
+
+var _ = Describe("Expose host suffix override", func() {
+	internal := &preprocessor.IngressConfig{IngressClass: "nginx-internal", HostSuffix: ".dev.example.com", TLSSecret: "internal-tls"}
+	web := types.ServiceConfig{Name: "web", Labels: types.Labels{"lissto.dev/expose": "true"}}
+
+	It("should generate preview hostnames with the override", func() {
+		configured := preprocessor.NewExposePreprocessor(internal, nil)
+		preview := configured.WithHostSuffix(".pr-123.preview.example.com")
+
+		Expect(preview.GetExposedServiceURL(web, "web", "dev")).To(Equal("web-dev.pr-123.preview.example.com"))
+		Expect(configured.GetExposedServiceURL(web, "web", "dev")).To(Equal("web-dev.dev.example.com"))
+		Expect(internal.HostSuffix).To(Equal(".dev.example.com"))
+
+		project, err := loadProject(`
+services:
+  web:
+    image: nginx
+    ports:
+      - "8080:80"
+    labels:
+      lissto.dev/expose: "true"
+`)
+		Expect(err).NotTo(HaveOccurred())
+		services, err := preview.ProcessServices(project.Services, "dev", "my-stack")
+		Expect(err).NotTo(HaveOccurred())
+		project.Services = services
+		objects, err := convertProject(project)
+		Expect(err).NotTo(HaveOccurred())
+
+		var hosts []string
+		for _, obj := range objects {
+			if ingress, ok := obj.(*networkingv1.Ingress); ok {
+				hosts = append(hosts, ingress.Spec.Rules[0].Host)
+			}
+		}
+		Expect(hosts).To(Equal([]string{"web-dev.pr-123.preview.example.com"}))
+	})
+
+	It("should keep the configured suffix without an override", func() {
+		configured := preprocessor.NewExposePreprocessor(internal, nil)
+		Expect(configured.WithHostSuffix("")).To(BeIdenticalTo(configured))
+	})
+
+	Describe("NormalizeHostSuffix", func() {
+		allowed := []string{"preview.example.com"}
+
+		It("should accept subdomains of an allowed domain with or without the leading dot", func() {
+			Expect(preprocessor.NormalizeHostSuffix("pr-123.preview.example.com", allowed)).To(Equal(".pr-123.preview.example.com"))
+			Expect(preprocessor.NormalizeHostSuffix(".PR-123.preview.example.com", allowed)).To(Equal(".pr-123.preview.example.com"))
+			Expect(preprocessor.NormalizeHostSuffix("preview.example.com", allowed)).To(Equal(".preview.example.com"))
+		})
+
+		It("should reject a suffix outside the allowed domains", func() {
+			_, err := preprocessor.NormalizeHostSuffix("pr-123.attacker.com", allowed)
+			Expect(err).To(MatchError(ContainSubstring("not within an allowed preview domain")))
+
+			_, err = preprocessor.NormalizeHostSuffix("pr-123.evilpreview.example.com", allowed)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should reject suffixes that are not DNS names", func() {
+			_, err := preprocessor.NormalizeHostSuffix("pr_123.preview.example.com", allowed)
+			Expect(err).To(MatchError(ContainSubstring("invalid host suffix")))
+		})
+	})
+})
@@ -0,0 +1,118 @@
+package postprocessor
+
+import (
+	"net"
+	"slices"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// ExtraHostsTranslator turns compose `extra_hosts` into pod hostAliases
+// Aliases are merged with those already on the pod (e.g. from lissto.dev/host-aliases),
+// so an IP appears once with every hostname. Entries without a valid IP, such as
+// Docker's host-gateway, are skipped with a warning.
+type ExtraHostsTranslator struct{}
+
+// NewExtraHostsTranslator creates a new extra_hosts translator
+func NewExtraHostsTranslator() *ExtraHostsTranslator {
+	return &ExtraHostsTranslator{}
+}
+
+// Translate adds each service's extra_hosts to the hostAliases of its pod spec
+// serviceExtraHosts maps service name to hostname to IPs from docker-compose
+func (t *ExtraHostsTranslator) Translate(objects []runtime.Object, serviceExtraHosts map[string]map[string][]string) []runtime.Object {
+	if len(serviceExtraHosts) == 0 {
+		return objects
+	}
+
+	for _, obj := range objects {
+		switch resource := obj.(type) {
+		case *appsv1.Deployment:
+			// Match by deployment name (equals service name in Kompose)
+			if hosts, exists := serviceExtraHosts[resource.Name]; exists {
+				t.translatePodSpec(&resource.Spec.Template.Spec, hosts, resource.Name)
+			}
+
+		case *appsv1.StatefulSet:
+			// Match by statefulset name (equals service name in Kompose)
+			if hosts, exists := serviceExtraHosts[resource.Name]; exists {
+				t.translatePodSpec(&resource.Spec.Template.Spec, hosts, resource.Name)
+			}
+
+		case *corev1.Pod:
+			// Match by pod name or io.kompose.service label
+			serviceName := resource.Name
+			if komposeService, ok := resource.Labels["io.kompose.service"]; ok {
+				serviceName = komposeService
+			}
+			if hosts, exists := serviceExtraHosts[serviceName]; exists {
+				t.translatePodSpec(&resource.Spec, hosts, serviceName)
+			}
+		}
+	}
+
+	return objects
+}
+
+// translatePodSpec merges the service's extra_hosts into the pod spec's hostAliases
+func (t *ExtraHostsTranslator) translatePodSpec(spec *corev1.PodSpec, hosts map[string][]string, serviceName string) {
+	aliases := extraHostsAliases(hosts, serviceName)
+	if len(aliases) == 0 {
+		return
+	}
+	spec.HostAliases = MergeHostAliases(spec.HostAliases, aliases)
+	logging.Logger.Info("Adding host aliases from extra_hosts",
+		zap.String("service", serviceName),
+		zap.Int("count", len(aliases)))
+}
+
+// extraHostsAliases groups hostnames by IP, in hostname order, skipping invalid IPs
+func extraHostsAliases(hosts map[string][]string, serviceName string) []corev1.HostAlias {
+	hostnames := make([]string, 0, len(hosts))
+	for hostname := range hosts {
+		hostnames = append(hostnames, hostname)
+	}
+	sort.Strings(hostnames)
+
+	var aliases []corev1.HostAlias
+	for _, hostname := range hostnames {
+		for _, ip := range hosts[hostname] {
+			if net.ParseIP(ip) == nil {
+				logging.Logger.Warn("Skipping extra_hosts entry with invalid IP",
+					zap.String("service", serviceName),
+					zap.String("hostname", hostname),
+					zap.String("ip", ip))
+				continue
+			}
+			aliases = MergeHostAliases(aliases, []corev1.HostAlias{{IP: ip, Hostnames: []string{hostname}}})
+		}
+	}
+	return aliases
+}
+
+// MergeHostAliases appends added to existing, folding aliases with the same IP into one entry
+// and dropping repeated hostnames; existing entries keep their position
+func MergeHostAliases(existing, added []corev1.HostAlias) []corev1.HostAlias {
+	merged := make([]corev1.HostAlias, 0, len(existing)+len(added))
+	byIP := make(map[string]int)
+	for _, alias := range append(append([]corev1.HostAlias(nil), existing...), added...) {
+		i, exists := byIP[alias.IP]
+		if !exists {
+			i = len(merged)
+			byIP[alias.IP] = i
+			merged = append(merged, corev1.HostAlias{IP: alias.IP})
+		}
+		for _, hostname := range alias.Hostnames {
+			if !slices.Contains(merged[i].Hostnames, hostname) {
+				merged[i].Hostnames = append(merged[i].Hostnames, hostname)
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,67 @@
+package postprocessor_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/postprocessor"
+)
+
+var _ = Describe("ExtraHostsTranslator", func() {
+	translate := func(labels map[string]string, hosts map[string][]string) corev1.PodSpec {
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api"},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "api", Image: "api"}}},
+				},
+			},
+		}
+		objects := []runtime.Object{deployment}
+		objects = postprocessor.NewPodNetworkConfigurator().Configure(objects, map[string]map[string]string{"api": labels})
+		objects = postprocessor.NewExtraHostsTranslator().Translate(objects, map[string]map[string][]string{"api": hosts})
+		return objects[0].(*appsv1.Deployment).Spec.Template.Spec
+	}
+
+	It("should turn extra_hosts into host aliases grouped by IP", func() {
+		spec := translate(nil, map[string][]string{
+			"legacy-db":      {"10.1.2.3"},
+			"legacy-db.corp": {"10.1.2.3"},
+			"ldap":           {"fd00::1"},
+		})
+
+		Expect(spec.HostAliases).To(Equal([]corev1.HostAlias{
+			{IP: "fd00::1", Hostnames: []string{"ldap"}},
+			{IP: "10.1.2.3", Hostnames: []string{"legacy-db", "legacy-db.corp"}},
+		}))
+	})
+
+	It("should merge with label-provided aliases without duplicates", func() {
+		spec := translate(
+			map[string]string{postprocessor.HostAliasesLabel: `[{"ip":"10.1.2.3","hostnames":["legacy-db"]}]`},
+			map[string][]string{
+				"legacy-db":      {"10.1.2.3"},
+				"legacy-db.corp": {"10.1.2.3"},
+				"cache":          {"10.1.2.9"},
+			},
+		)
+
+		Expect(spec.HostAliases).To(Equal([]corev1.HostAlias{
+			{IP: "10.1.2.3", Hostnames: []string{"legacy-db", "legacy-db.corp"}},
+			{IP: "10.1.2.9", Hostnames: []string{"cache"}},
+		}))
+	})
+
+	It("should skip entries without a valid IP", func() {
+		spec := translate(nil, map[string][]string{
+			"host.docker.internal": {"host-gateway"},
+			"ok":                   {"10.1.2.3"},
+		})
+
+		Expect(spec.HostAliases).To(Equal([]corev1.HostAlias{{IP: "10.1.2.3", Hostnames: []string{"ok"}}}))
+	})
+})
@@ -0,0 +1,153 @@
+package postprocessor
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// FilesystemTranslator applies compose tmpfs mounts and read_only to workloads
+// Kompose drops tmpfs size limits and turns long syntax tmpfs volumes into PVCs,
+// so every tmpfs path is (re)mounted as a memory-backed emptyDir here
+type FilesystemTranslator struct{}
+
+// NewFilesystemTranslator creates a new filesystem translator
+func NewFilesystemTranslator() *FilesystemTranslator {
+	return &FilesystemTranslator{}
+}
+
+// Translate applies the filesystem options of each service to its workload
+// filesystems maps service name to its options from docker-compose
+func (f *FilesystemTranslator) Translate(objects []runtime.Object, filesystems map[string]compose.ServiceFilesystem) []runtime.Object {
+	if len(filesystems) == 0 {
+		return objects
+	}
+
+	// PVCs Kompose generated for tmpfs volumes, removed once the mount is replaced
+	replacedClaims := make(map[string]bool)
+
+	for _, obj := range objects {
+		switch resource := obj.(type) {
+		case *appsv1.Deployment:
+			// Match by deployment name (equals service name in Kompose)
+			if fs, exists := filesystems[resource.Name]; exists {
+				f.applyToPodSpec(&resource.Spec.Template.Spec, fs, resource.Name, replacedClaims)
+			}
+
+		case *appsv1.StatefulSet:
+			// Match by statefulset name (equals service name in Kompose)
+			if fs, exists := filesystems[resource.Name]; exists {
+				f.applyToPodSpec(&resource.Spec.Template.Spec, fs, resource.Name, replacedClaims)
+			}
+
+		case *corev1.Pod:
+			// Match by pod name or io.kompose.service label
+			serviceName := resource.Name
+			if komposeService, ok := resource.Labels["io.kompose.service"]; ok {
+				serviceName = komposeService
+			}
+			if fs, exists := filesystems[serviceName]; exists {
+				f.applyToPodSpec(&resource.Spec, fs, serviceName, replacedClaims)
+			}
+		}
+	}
+
+	if len(replacedClaims) == 0 {
+		return objects
+	}
+
+	result := make([]runtime.Object, 0, len(objects))
+	for _, obj := range objects {
+		if pvc, ok := obj.(*corev1.PersistentVolumeClaim); ok && replacedClaims[pvc.Name] {
+			logging.Logger.Info("Dropping PVC replaced by tmpfs mount", zap.String("pvc", pvc.Name))
+			continue
+		}
+		result = append(result, obj)
+	}
+	return result
+}
+
+// applyToPodSpec mounts the tmpfs paths and sets the read-only root filesystem on every container
+func (f *FilesystemTranslator) applyToPodSpec(spec *corev1.PodSpec, fs compose.ServiceFilesystem, serviceName string, replacedClaims map[string]bool) {
+	for i, tmpfs := range fs.Tmpfs {
+		source := corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{
+				Medium:    corev1.StorageMediumMemory,
+				SizeLimit: tmpfs.SizeLimit,
+			},
+		}
+
+		volumeName := findMountedVolume(spec.Containers, tmpfs.Path)
+		if volumeName == "" {
+			volumeName = uniqueVolumeName(spec.Volumes, fmt.Sprintf("%s-tmpfs%d", serviceName, i))
+			spec.Volumes = append(spec.Volumes, corev1.Volume{Name: volumeName, VolumeSource: source})
+			for c := range spec.Containers {
+				spec.Containers[c].VolumeMounts = append(spec.Containers[c].VolumeMounts, corev1.VolumeMount{
+					Name:      volumeName,
+					MountPath: tmpfs.Path,
+				})
+			}
+		} else {
+			for v := range spec.Volumes {
+				if spec.Volumes[v].Name != volumeName {
+					continue
+				}
+				if claim := spec.Volumes[v].PersistentVolumeClaim; claim != nil {
+					replacedClaims[claim.ClaimName] = true
+				}
+				spec.Volumes[v].VolumeSource = source
+			}
+		}
+
+		logging.Logger.Info("Mounted tmpfs as memory emptyDir",
+			zap.String("service", serviceName),
+			zap.String("path", tmpfs.Path),
+			zap.String("volume", volumeName))
+	}
+
+	if fs.ReadOnly {
+		for c := range spec.Containers {
+			if spec.Containers[c].SecurityContext == nil {
+				spec.Containers[c].SecurityContext = &corev1.SecurityContext{}
+			}
+			readOnly := true
+			spec.Containers[c].SecurityContext.ReadOnlyRootFilesystem = &readOnly
+		}
+	}
+}
+
+// findMountedVolume returns the name of the volume mounted at path, if any
+func findMountedVolume(containers []corev1.Container, path string) string {
+	for _, container := range containers {
+		for _, mount := range container.VolumeMounts {
+			if mount.MountPath == path {
+				return mount.Name
+			}
+		}
+	}
+	return ""
+}
+
+// uniqueVolumeName returns name, suffixed if a volume with that name already exists
+func uniqueVolumeName(volumes []corev1.Volume, name string) string {
+	candidate := name
+	for n := 1; ; n++ {
+		taken := false
+		for _, volume := range volumes {
+			if volume.Name == candidate {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d", name, n)
+	}
+}
@@ -0,0 +1,125 @@
+package postprocessor_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/postprocessor"
+)
+
+var _ = Describe("FilesystemTranslator", func() {
+	translate := func(composeContent string) []runtime.Object {
+		project, err := loadProject(composeContent)
+		Expect(err).NotTo(HaveOccurred())
+
+		filesystems, err := compose.ExtractServiceFilesystems(project)
+		Expect(err).NotTo(HaveOccurred())
+
+		objects, err := convertProject(project)
+		Expect(err).NotTo(HaveOccurred())
+
+		return postprocessor.NewFilesystemTranslator().Translate(objects, filesystems)
+	}
+
+	findDeployment := func(objects []runtime.Object, name string) *appsv1.Deployment {
+		for _, obj := range objects {
+			if deployment, ok := obj.(*appsv1.Deployment); ok && deployment.Name == name {
+				return deployment
+			}
+		}
+		Fail("deployment " + name + " not found")
+		return nil
+	}
+
+	volumeAt := func(spec corev1.PodSpec, path string) corev1.Volume {
+		for _, mount := range spec.Containers[0].VolumeMounts {
+			if mount.MountPath != path {
+				continue
+			}
+			for _, volume := range spec.Volumes {
+				if volume.Name == mount.Name {
+					return volume
+				}
+			}
+		}
+		Fail("no volume mounted at " + path)
+		return corev1.Volume{}
+	}
+
+	It("should mount a tmpfs path as a memory emptyDir with its size limit", func() {
+		result := translate(`
+services:
+  api:
+    image: nginx
+    tmpfs:
+      - /run:size=64m
+`)
+
+		spec := findDeployment(result, "api").Spec.Template.Spec
+		volume := volumeAt(spec, "/run")
+		Expect(volume.EmptyDir).NotTo(BeNil())
+		Expect(volume.EmptyDir.Medium).To(Equal(corev1.StorageMediumMemory))
+		Expect(volume.EmptyDir.SizeLimit.Cmp(resource.MustParse("64Mi"))).To(Equal(0))
+		Expect(spec.Containers[0].VolumeMounts).To(HaveLen(1))
+	})
+
+	It("should replace the PVC Kompose creates for a long syntax tmpfs volume", func() {
+		result := translate(`
+services:
+  api:
+    image: nginx
+    volumes:
+      - type: tmpfs
+        target: /cache
+`)
+
+		volume := volumeAt(findDeployment(result, "api").Spec.Template.Spec, "/cache")
+		Expect(volume.PersistentVolumeClaim).To(BeNil())
+		Expect(volume.EmptyDir).NotTo(BeNil())
+		Expect(volume.EmptyDir.Medium).To(Equal(corev1.StorageMediumMemory))
+		for _, obj := range result {
+			Expect(obj).NotTo(BeAssignableToTypeOf(&corev1.PersistentVolumeClaim{}))
+		}
+	})
+
+	It("should set a read-only root filesystem for read_only services", func() {
+		result := translate(`
+services:
+  api:
+    image: nginx
+    read_only: true
+  worker:
+    image: worker
+    read_only: false
+`)
+
+		api := findDeployment(result, "api").Spec.Template.Spec.Containers[0]
+		Expect(api.SecurityContext).NotTo(BeNil())
+		Expect(api.SecurityContext.ReadOnlyRootFilesystem).To(HaveValue(BeTrue()))
+
+		worker := findDeployment(result, "worker").Spec.Template.Spec.Containers[0]
+		if worker.SecurityContext != nil {
+			Expect(worker.SecurityContext.ReadOnlyRootFilesystem).NotTo(HaveValue(BeTrue()))
+		}
+	})
+
+	It("should add a mount when none exists for the path", func() {
+		deployment := &appsv1.Deployment{}
+		deployment.Name = "api"
+		deployment.Spec.Template.Spec.Containers = []corev1.Container{{Name: "api"}}
+
+		result := postprocessor.NewFilesystemTranslator().Translate([]runtime.Object{deployment}, map[string]compose.ServiceFilesystem{
+			"api": {Tmpfs: []compose.TmpfsMount{{Path: "/tmp"}}},
+		})
+
+		spec := result[0].(*appsv1.Deployment).Spec.Template.Spec
+		Expect(spec.Volumes).To(HaveLen(1))
+		Expect(spec.Volumes[0].Name).To(Equal("api-tmpfs0"))
+		Expect(spec.Containers[0].VolumeMounts).To(ConsistOf(corev1.VolumeMount{Name: "api-tmpfs0", MountPath: "/tmp"}))
+	})
+})
@@ -0,0 +1,92 @@
+package postprocessor
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// GPUResourceName is the extended resource exposed by the NVIDIA device plugin
+const GPUResourceName corev1.ResourceName = "nvidia.com/gpu"
+
+// GPURuntimeClassLabel sets the runtimeClassName of a service reserving GPUs (e.g. nvidia)
+const GPURuntimeClassLabel = "lissto.dev/gpu-runtime-class"
+
+// GPUResourceInjector turns compose GPU reservations into nvidia.com/gpu resources
+// Extended resources cannot be overcommitted, so the request and limit are set to the same count.
+type GPUResourceInjector struct{}
+
+// NewGPUResourceInjector creates a new GPU resource injector
+func NewGPUResourceInjector() *GPUResourceInjector {
+	return &GPUResourceInjector{}
+}
+
+// Inject sets the GPU count on the service's container and the runtime class from its labels
+// serviceGPUs maps service name to its reserved GPU count, serviceLabelMap to its labels from docker-compose
+func (g *GPUResourceInjector) Inject(objects []runtime.Object, serviceGPUs map[string]int64, serviceLabelMap map[string]map[string]string) []runtime.Object {
+	if len(serviceGPUs) == 0 {
+		return objects
+	}
+
+	for _, obj := range objects {
+		switch workload := obj.(type) {
+		case *appsv1.Deployment:
+			serviceName := serviceNameOf(workload.Name, workload.Labels)
+			if count, exists := serviceGPUs[serviceName]; exists {
+				g.injectPodSpec(&workload.Spec.Template.Spec, count, serviceLabelMap[serviceName], serviceName)
+			}
+
+		case *appsv1.StatefulSet:
+			serviceName := serviceNameOf(workload.Name, workload.Labels)
+			if count, exists := serviceGPUs[serviceName]; exists {
+				g.injectPodSpec(&workload.Spec.Template.Spec, count, serviceLabelMap[serviceName], serviceName)
+			}
+
+		case *corev1.Pod:
+			serviceName := serviceNameOf(workload.Name, workload.Labels)
+			if count, exists := serviceGPUs[serviceName]; exists {
+				g.injectPodSpec(&workload.Spec, count, serviceLabelMap[serviceName], serviceName)
+			}
+		}
+	}
+
+	return objects
+}
+
+// injectPodSpec sets the GPU request and limit on the service's container
+// Kompose emits one container per service; if there are several, the one named after the service gets the GPUs.
+func (g *GPUResourceInjector) injectPodSpec(spec *corev1.PodSpec, count int64, labels map[string]string, serviceName string) {
+	if len(spec.Containers) == 0 {
+		return
+	}
+	container := &spec.Containers[0]
+	for i := range spec.Containers {
+		if spec.Containers[i].Name == serviceName {
+			container = &spec.Containers[i]
+			break
+		}
+	}
+
+	quantity := *resource.NewQuantity(count, resource.DecimalSI)
+	if container.Resources.Requests == nil {
+		container.Resources.Requests = corev1.ResourceList{}
+	}
+	if container.Resources.Limits == nil {
+		container.Resources.Limits = corev1.ResourceList{}
+	}
+	container.Resources.Requests[GPUResourceName] = quantity
+	container.Resources.Limits[GPUResourceName] = quantity.DeepCopy()
+
+	if runtimeClass := labels[GPURuntimeClassLabel]; runtimeClass != "" {
+		spec.RuntimeClassName = &runtimeClass
+	}
+
+	logging.Logger.Info("Adding GPU resources from device reservations",
+		zap.String("service", serviceName),
+		zap.String("container", container.Name),
+		zap.Int64("gpus", count))
+}
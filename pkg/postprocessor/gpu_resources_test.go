@@ -0,0 +1,70 @@
+package postprocessor_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/postprocessor"
+)
+
+var _ = Describe("GPUResourceInjector", func() {
+	deployments := func(content string) map[string]*appsv1.Deployment {
+		project, err := loadProject(content)
+		Expect(err).NotTo(HaveOccurred())
+		serviceGPUs, err := compose.ExtractServiceGPUs(project)
+		Expect(err).NotTo(HaveOccurred())
+		serviceLabelMap := make(map[string]map[string]string)
+		for name, service := range project.Services {
+			serviceLabelMap[name] = service.Labels
+		}
+
+		objects, err := convertProject(project)
+		Expect(err).NotTo(HaveOccurred())
+		objects = postprocessor.NewGPUResourceInjector().Inject(objects, serviceGPUs, serviceLabelMap)
+
+		result := make(map[string]*appsv1.Deployment)
+		for _, obj := range objects {
+			if deployment, ok := obj.(*appsv1.Deployment); ok {
+				result[deployment.Name] = deployment
+			}
+		}
+		return result
+	}
+
+	It("should turn a GPU reservation into an nvidia.com/gpu resource and leave other services alone", func() {
+		result := deployments(`
+services:
+  trainer:
+    image: pytorch/pytorch
+    labels:
+      lissto.dev/gpu-runtime-class: nvidia
+    deploy:
+      resources:
+        reservations:
+          devices:
+            - driver: nvidia
+              count: 2
+              capabilities: [gpu]
+  web:
+    image: nginx
+    labels:
+      lissto.dev/gpu-runtime-class: nvidia
+`)
+
+		trainer := result["trainer"].Spec.Template.Spec
+		requests, limits := trainer.Containers[0].Resources.Requests, trainer.Containers[0].Resources.Limits
+		Expect(requests).To(HaveKey(postprocessor.GPUResourceName))
+		Expect(limits).To(HaveKey(postprocessor.GPUResourceName))
+		Expect(requests.Name(postprocessor.GPUResourceName, resource.DecimalSI).Value()).To(Equal(int64(2)))
+		Expect(limits.Name(postprocessor.GPUResourceName, resource.DecimalSI).Value()).To(Equal(int64(2)))
+		Expect(trainer.RuntimeClassName).To(HaveValue(Equal("nvidia")))
+
+		web := result["web"].Spec.Template.Spec
+		Expect(web.Containers[0].Resources.Requests).NotTo(HaveKey(postprocessor.GPUResourceName))
+		Expect(web.Containers[0].Resources.Limits).NotTo(HaveKey(postprocessor.GPUResourceName))
+		Expect(web.RuntimeClassName).To(BeNil())
+	})
+})
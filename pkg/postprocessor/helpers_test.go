@@ -0,0 +1,34 @@
+package postprocessor_test
+
+import (
+	"context"
+
+	"github.com/compose-spec/compose-go/v2/loader"
+	"github.com/compose-spec/compose-go/v2/types"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/kompose"
+	"github.com/lissto-dev/api/pkg/serializer"
+)
+
+// loadProject parses compose content the same way the stack handler does
+func loadProject(content string) (*types.Project, error) {
+	return loader.LoadWithContext(
+		context.Background(),
+		types.ConfigDetails{
+			ConfigFiles: []types.ConfigFile{{Filename: "docker-compose.yml", Content: []byte(content)}},
+			WorkingDir:  "/tmp",
+		},
+		loader.WithSkipValidation,
+		func(o *loader.Options) { o.SetProjectName("stack", true) },
+	)
+}
+
+// convertProject serializes the project and converts it with Kompose
+func convertProject(project *types.Project) ([]runtime.Object, error) {
+	composeYAML, err := serializer.NewComposeSerializer().Serialize(project)
+	if err != nil {
+		return nil, err
+	}
+	return kompose.NewConverter("test").ConvertToObjects(composeYAML)
+}
@@ -0,0 +1,52 @@
+package postprocessor
+
+import (
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/logging"
+	"github.com/lissto-dev/api/pkg/preprocessor"
+	"go.uber.org/zap"
+)
+
+// ClusterIssuerAnnotation asks cert-manager to issue the certificate of an ingress's TLS secret
+const ClusterIssuerAnnotation = "cert-manager.io/cluster-issuer"
+
+// IngressTLSAnnotator adds the cert-manager issuer annotation to the ingresses of services
+// exposed in cert-manager TLS mode. The expose preprocessor picks the issuer and the
+// auto-named TLS secret, Kompose already references the secret in the ingress tls block.
+type IngressTLSAnnotator struct{}
+
+// NewIngressTLSAnnotator creates a new ingress TLS annotator
+func NewIngressTLSAnnotator() *IngressTLSAnnotator {
+	return &IngressTLSAnnotator{}
+}
+
+// Annotate sets the cluster issuer annotation on ingresses whose service carries preprocessor.TLSIssuerLabel
+// serviceLabelMap maps service name to its labels after the expose preprocessor
+func (a *IngressTLSAnnotator) Annotate(objects []runtime.Object, serviceLabelMap map[string]map[string]string) []runtime.Object {
+	for _, obj := range objects {
+		ingress, ok := obj.(*networkingv1.Ingress)
+		if !ok {
+			continue
+		}
+
+		serviceName := serviceNameOf(ingress.Name, ingress.Labels)
+		issuer := serviceLabelMap[serviceName][preprocessor.TLSIssuerLabel]
+		if issuer == "" {
+			continue
+		}
+
+		if ingress.Annotations == nil {
+			ingress.Annotations = make(map[string]string)
+		}
+		// The marker label is copied to the ingress by Kompose, the issuer annotation replaces it
+		delete(ingress.Annotations, preprocessor.TLSIssuerLabel)
+		ingress.Annotations[ClusterIssuerAnnotation] = issuer
+
+		logging.Logger.Info("Requesting cert-manager certificate for ingress",
+			zap.String("service", serviceName),
+			zap.String("cluster_issuer", issuer))
+	}
+	return objects
+}
@@ -0,0 +1,124 @@
+package postprocessor_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/postprocessor"
+	"github.com/lissto-dev/api/pkg/preprocessor"
+)
+
+var _ = Describe("IngressTLSAnnotator", func() {
+	const content = `
+services:
+  web:
+    image: nginx
+    ports:
+      - "8080:80"
+    labels:
+      lissto.dev/expose: "true"
+  admin:
+    image: nginx
+    ports:
+      - "8081:80"
+    labels:
+      lissto.dev/expose: "true"
+      lissto.dev/tls-mode: secret
+`
+
+	// render runs the expose preprocessor, Kompose and the annotator like the stack handler
+	render := func(config preprocessor.IngressConfig) (map[string]*networkingv1.Ingress, error) {
+		project, err := loadProject(content)
+		Expect(err).NotTo(HaveOccurred())
+
+		services, err := preprocessor.NewExposePreprocessor(&config, nil).ProcessServices(project.Services, "dev", "my-stack")
+		if err != nil {
+			return nil, err
+		}
+		project.Services = services
+		objects, err := convertProject(project)
+		Expect(err).NotTo(HaveOccurred())
+
+		labels := map[string]map[string]string{}
+		for name, service := range project.Services {
+			labels[name] = service.Labels
+		}
+		objects = postprocessor.NewIngressTLSAnnotator().Annotate(objects, labels)
+
+		ingresses := map[string]*networkingv1.Ingress{}
+		for _, obj := range objects {
+			if ingress, ok := obj.(*networkingv1.Ingress); ok {
+				ingresses[ingress.Name] = ingress
+			}
+		}
+		return ingresses, nil
+	}
+
+	It("should add the issuer annotation and an auto-named TLS secret in cert-manager mode", func() {
+		ingresses, err := render(preprocessor.IngressConfig{
+			IngressClass:  "nginx",
+			HostSuffix:    ".dev.example.com",
+			TLSSecret:     "wildcard-tls",
+			TLSMode:       preprocessor.TLSModeCertManager,
+			ClusterIssuer: "letsencrypt",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(ingresses).To(HaveKey("web"))
+		web := ingresses["web"]
+		Expect(web.Annotations).To(HaveKeyWithValue(postprocessor.ClusterIssuerAnnotation, "letsencrypt"))
+		Expect(web.Annotations).NotTo(HaveKey(preprocessor.TLSIssuerLabel))
+		Expect(web.Spec.TLS).To(HaveLen(1))
+		Expect(web.Spec.TLS[0].SecretName).To(Equal("web-dev-tls"))
+		Expect(web.Spec.TLS[0].Hosts).To(ConsistOf("web-dev.dev.example.com"))
+	})
+
+	It("should let the tls-mode label keep the static secret", func() {
+		ingresses, err := render(preprocessor.IngressConfig{
+			IngressClass:  "nginx",
+			HostSuffix:    ".dev.example.com",
+			TLSSecret:     "wildcard-tls",
+			TLSMode:       preprocessor.TLSModeCertManager,
+			ClusterIssuer: "letsencrypt",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		admin := ingresses["admin"]
+		Expect(admin.Annotations).NotTo(HaveKey(postprocessor.ClusterIssuerAnnotation))
+		Expect(admin.Spec.TLS[0].SecretName).To(Equal("wildcard-tls"))
+	})
+
+	It("should reference the static secret by default", func() {
+		ingresses, err := render(preprocessor.IngressConfig{
+			IngressClass: "nginx",
+			HostSuffix:   ".dev.example.com",
+			TLSSecret:    "wildcard-tls",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, ingress := range ingresses {
+			Expect(ingress.Annotations).NotTo(HaveKey(postprocessor.ClusterIssuerAnnotation))
+			Expect(ingress.Spec.TLS[0].SecretName).To(Equal("wildcard-tls"))
+		}
+	})
+
+	It("should reject cert-manager mode without a cluster issuer", func() {
+		_, err := render(preprocessor.IngressConfig{
+			IngressClass: "nginx",
+			HostSuffix:   ".dev.example.com",
+			TLSSecret:    "wildcard-tls",
+			TLSMode:      preprocessor.TLSModeCertManager,
+		})
+		Expect(err).To(MatchError(ContainSubstring("no cluster issuer")))
+	})
+
+	It("should leave ingresses of services without an issuer alone", func() {
+		ingress := &networkingv1.Ingress{}
+		ingress.Name = "web"
+		postprocessor.NewIngressTLSAnnotator().Annotate([]runtime.Object{ingress}, nil)
+
+		Expect(ingress.Annotations).NotTo(HaveKey(postprocessor.ClusterIssuerAnnotation))
+	})
+})
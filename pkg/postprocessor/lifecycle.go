@@ -0,0 +1,116 @@
+package postprocessor
+
+import (
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/logging"
+	"go.uber.org/zap"
+)
+
+const (
+	// TerminationGracePeriodLabel sets the pod termination grace period as a duration, e.g. "90s" or "2m"
+	TerminationGracePeriodLabel = "lissto.dev/termination-grace-period"
+	// PreStopLabel sets a preStop exec hook, as a JSON array or space-separated like lissto.dev/command
+	PreStopLabel = "lissto.dev/prestop"
+
+	// MaxTerminationGracePeriod bounds the grace period so a stuck pod can't block deletes for long
+	MaxTerminationGracePeriod = time.Hour
+)
+
+// LifecycleConfigurator sets the termination grace period and preStop hooks of workloads from lissto.dev labels
+// Stateful services need time and a hook to shut down cleanly. Invalid label values are ignored with a warning.
+type LifecycleConfigurator struct {
+	commands *CommandOverrider
+}
+
+// NewLifecycleConfigurator creates a new lifecycle configurator
+func NewLifecycleConfigurator() *LifecycleConfigurator {
+	return &LifecycleConfigurator{commands: NewCommandOverrider()}
+}
+
+// Configure applies the grace period and preStop labels to Kubernetes objects
+// serviceLabelMap maps service name to its labels from docker-compose
+func (l *LifecycleConfigurator) Configure(objects []runtime.Object, serviceLabelMap map[string]map[string]string) []runtime.Object {
+	if len(serviceLabelMap) == 0 {
+		return objects
+	}
+
+	for _, obj := range objects {
+		switch resource := obj.(type) {
+		case *appsv1.Deployment:
+			if labels, exists := serviceLabelMap[resource.Name]; exists {
+				l.configurePodSpec(&resource.Spec.Template.Spec, labels, resource.Name)
+			}
+
+		case *appsv1.StatefulSet:
+			if labels, exists := serviceLabelMap[resource.Name]; exists {
+				l.configurePodSpec(&resource.Spec.Template.Spec, labels, resource.Name)
+			}
+
+		case *corev1.Pod:
+			serviceName := serviceNameOf(resource.Name, resource.Labels)
+			if labels, exists := serviceLabelMap[serviceName]; exists {
+				l.configurePodSpec(&resource.Spec, labels, serviceName)
+			}
+		}
+	}
+
+	return objects
+}
+
+// configurePodSpec applies both labels to a pod spec; the preStop hook is set on all containers
+func (l *LifecycleConfigurator) configurePodSpec(spec *corev1.PodSpec, labels map[string]string, serviceName string) {
+	if value := labels[TerminationGracePeriodLabel]; value != "" {
+		seconds, err := parseGracePeriod(value)
+		if err != nil {
+			logging.Logger.Warn("Ignoring invalid lissto.dev/termination-grace-period label",
+				zap.String("service", serviceName),
+				zap.String("label_value", value),
+				zap.Error(err))
+		} else {
+			spec.TerminationGracePeriodSeconds = &seconds
+			logging.Logger.Info("Setting termination grace period",
+				zap.String("service", serviceName),
+				zap.Int64("seconds", seconds))
+		}
+	}
+
+	if value := labels[PreStopLabel]; value != "" {
+		command, err := l.commands.parseCommandLabel(value)
+		if err != nil || len(command) == 0 {
+			logging.Logger.Warn("Ignoring invalid lissto.dev/prestop label",
+				zap.String("service", serviceName),
+				zap.String("label_value", value),
+				zap.Error(err))
+			return
+		}
+		for i := range spec.Containers {
+			if spec.Containers[i].Lifecycle == nil {
+				spec.Containers[i].Lifecycle = &corev1.Lifecycle{}
+			}
+			spec.Containers[i].Lifecycle.PreStop = &corev1.LifecycleHandler{
+				Exec: &corev1.ExecAction{Command: command},
+			}
+		}
+		logging.Logger.Info("Setting preStop hook",
+			zap.String("service", serviceName),
+			zap.Strings("command", command))
+	}
+}
+
+// parseGracePeriod parses a duration into whole seconds, rounding up
+func parseGracePeriod(value string) (int64, error) {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if duration < 0 || duration > MaxTerminationGracePeriod {
+		return 0, fmt.Errorf("grace period must be between 0 and %s", MaxTerminationGracePeriod)
+	}
+	return int64((duration + time.Second - 1) / time.Second), nil
+}
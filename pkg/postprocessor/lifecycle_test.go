@@ -0,0 +1,65 @@
+package postprocessor_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/postprocessor"
+)
+
+var _ = Describe("LifecycleConfigurator", func() {
+	configure := func(labels map[string]string) corev1.PodSpec {
+		statefulSet := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db"},
+			Spec: appsv1.StatefulSetSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "db", Image: "postgres"}},
+					},
+				},
+			},
+		}
+		result := postprocessor.NewLifecycleConfigurator().Configure([]runtime.Object{statefulSet}, map[string]map[string]string{"db": labels})
+		return result[0].(*appsv1.StatefulSet).Spec.Template.Spec
+	}
+
+	It("should set the termination grace period from a duration", func() {
+		spec := configure(map[string]string{postprocessor.TerminationGracePeriodLabel: "2m"})
+
+		Expect(spec.TerminationGracePeriodSeconds).To(HaveValue(Equal(int64(120))))
+	})
+
+	DescribeTable("should ignore invalid grace periods",
+		func(value string) {
+			spec := configure(map[string]string{postprocessor.TerminationGracePeriodLabel: value})
+			Expect(spec.TerminationGracePeriodSeconds).To(BeNil())
+		},
+		Entry("not a duration", "90"),
+		Entry("negative", "-5s"),
+		Entry("above the maximum", "2h"),
+	)
+
+	It("should set a preStop exec hook from a JSON array", func() {
+		spec := configure(map[string]string{postprocessor.PreStopLabel: `["sh", "-c", "pg_ctl stop -m fast"]`})
+
+		Expect(spec.Containers[0].Lifecycle).NotTo(BeNil())
+		Expect(spec.Containers[0].Lifecycle.PreStop.Exec.Command).To(Equal([]string{"sh", "-c", "pg_ctl stop -m fast"}))
+	})
+
+	It("should split a space-separated preStop command", func() {
+		spec := configure(map[string]string{postprocessor.PreStopLabel: "sleep 10"})
+
+		Expect(spec.Containers[0].Lifecycle.PreStop.Exec.Command).To(Equal([]string{"sleep", "10"}))
+	})
+
+	It("should leave unlabelled services alone", func() {
+		spec := configure(map[string]string{})
+
+		Expect(spec.TerminationGracePeriodSeconds).To(BeNil())
+		Expect(spec.Containers[0].Lifecycle).To(BeNil())
+	})
+})
@@ -0,0 +1,143 @@
+package postprocessor
+
+import (
+	"sort"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/lissto-dev/api/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// Logging options with a standard log shipper annotation
+const (
+	LoggingOptionExclude = "exclude" // "true" stops log collection for the pod
+	LoggingOptionInclude = "include" // "false" is the same as exclude: "true"
+	LoggingOptionParser  = "parser"  // Parser applied by Fluent Bit (e.g., json, nginx)
+)
+
+// Log shipper annotations set from known logging options
+const (
+	FluentBitExcludeAnnotation = "fluentbit.io/exclude"
+	FluentBitParserAnnotation  = "fluentbit.io/parser"
+	VectorExcludeAnnotation    = "vector.dev/exclude"
+)
+
+// LoggingAnnotationPrefix namespaces options without a standard annotation,
+// e.g. max-size becomes logging.lissto.dev/max-size
+const LoggingAnnotationPrefix = "logging.lissto.dev/"
+
+// LoggingAnnotator turns compose logging options into pod template annotations read by log shippers
+type LoggingAnnotator struct{}
+
+// NewLoggingAnnotator creates a new logging annotator
+func NewLoggingAnnotator() *LoggingAnnotator {
+	return &LoggingAnnotator{}
+}
+
+// Annotate adds the logging annotations of each service to its pod template
+// serviceLogging maps service name to its logging options from docker-compose
+func (l *LoggingAnnotator) Annotate(objects []runtime.Object, serviceLogging map[string]map[string]string) []runtime.Object {
+	if len(serviceLogging) == 0 {
+		return objects
+	}
+
+	for _, obj := range objects {
+		switch resource := obj.(type) {
+		case *appsv1.Deployment:
+			// Match by deployment name (equals service name in Kompose)
+			if options, exists := serviceLogging[resource.Name]; exists {
+				resource.Spec.Template.Annotations = l.annotate(resource.Spec.Template.Annotations, options, resource.Name)
+			}
+
+		case *appsv1.StatefulSet:
+			// Match by statefulset name (equals service name in Kompose)
+			if options, exists := serviceLogging[resource.Name]; exists {
+				resource.Spec.Template.Annotations = l.annotate(resource.Spec.Template.Annotations, options, resource.Name)
+			}
+
+		case *corev1.Pod:
+			// Match by pod name or io.kompose.service label
+			serviceName := resource.Name
+			if komposeService, ok := resource.Labels["io.kompose.service"]; ok {
+				serviceName = komposeService
+			}
+			if options, exists := serviceLogging[serviceName]; exists {
+				resource.Annotations = l.annotate(resource.Annotations, options, serviceName)
+			}
+		}
+	}
+
+	return objects
+}
+
+// annotate merges the annotations for options into existing pod annotations
+func (l *LoggingAnnotator) annotate(annotations map[string]string, options map[string]string, serviceName string) map[string]string {
+	added := LoggingAnnotations(options, serviceName)
+	if len(added) == 0 {
+		return annotations
+	}
+	if annotations == nil {
+		annotations = make(map[string]string, len(added))
+	}
+	for key, value := range added {
+		annotations[key] = value
+	}
+
+	keys := make([]string, 0, len(added))
+	for key := range added {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	logging.Logger.Info("Adding logging annotations",
+		zap.String("service", serviceName),
+		zap.Strings("annotations", keys))
+	return annotations
+}
+
+// LoggingAnnotations maps logging options to annotations
+// Known options become log shipper annotations, others are kept under LoggingAnnotationPrefix.
+// Options that are not valid annotation names are skipped with a warning.
+func LoggingAnnotations(options map[string]string, serviceName string) map[string]string {
+	annotations := make(map[string]string, len(options))
+
+	for option, value := range options {
+		switch option {
+		case LoggingOptionExclude, LoggingOptionInclude:
+			if _, both := options[LoggingOptionExclude]; both && option == LoggingOptionInclude {
+				continue // exclude wins over include
+			}
+			flag, err := strconv.ParseBool(value)
+			if err != nil {
+				logging.Logger.Warn("Ignoring logging option, expected true or false",
+					zap.String("service", serviceName),
+					zap.String("option", option),
+					zap.String("value", value))
+				continue
+			}
+			exclude := strconv.FormatBool(flag == (option == LoggingOptionExclude))
+			annotations[FluentBitExcludeAnnotation] = exclude
+			annotations[VectorExcludeAnnotation] = exclude
+
+		case LoggingOptionParser:
+			annotations[FluentBitParserAnnotation] = value
+
+		default:
+			key := LoggingAnnotationPrefix + option
+			if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+				logging.Logger.Warn("Ignoring logging option that is not a valid annotation name",
+					zap.String("service", serviceName),
+					zap.String("option", option),
+					zap.Strings("errors", errs))
+				continue
+			}
+			annotations[key] = value
+		}
+	}
+
+	return annotations
+}
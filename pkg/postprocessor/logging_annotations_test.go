@@ -0,0 +1,83 @@
+package postprocessor_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/postprocessor"
+)
+
+var _ = Describe("LoggingAnnotator", func() {
+	annotate := func(composeContent string) []runtime.Object {
+		project, err := loadProject(composeContent)
+		Expect(err).NotTo(HaveOccurred())
+
+		objects, err := convertProject(project)
+		Expect(err).NotTo(HaveOccurred())
+
+		return postprocessor.NewLoggingAnnotator().Annotate(objects, compose.ExtractServiceLogging(project))
+	}
+
+	templateAnnotations := func(objects []runtime.Object, name string) map[string]string {
+		for _, obj := range objects {
+			if deployment, ok := obj.(*appsv1.Deployment); ok && deployment.Name == name {
+				return deployment.Spec.Template.Annotations
+			}
+		}
+		Fail("deployment " + name + " not found")
+		return nil
+	}
+
+	It("should turn a service's logging options into pod annotations", func() {
+		result := annotate(`
+services:
+  api:
+    image: nginx
+    logging:
+      driver: json-file
+      options:
+        parser: nginx
+        max-size: 10m
+    labels:
+      lissto.dev/logging.team: payments
+  db:
+    image: postgres:16
+`)
+
+		annotations := templateAnnotations(result, "api")
+		Expect(annotations).To(HaveKeyWithValue(postprocessor.FluentBitParserAnnotation, "nginx"))
+		Expect(annotations).To(HaveKeyWithValue("logging.lissto.dev/max-size", "10m"))
+		Expect(annotations).To(HaveKeyWithValue("logging.lissto.dev/team", "payments"))
+		Expect(templateAnnotations(result, "db")).NotTo(HaveKey(HavePrefix("logging.lissto.dev/")))
+	})
+
+	It("should let labels override logging options", func() {
+		result := annotate(`
+services:
+  worker:
+    image: busybox
+    logging:
+      options:
+        exclude: "false"
+    labels:
+      lissto.dev/logging.exclude: "true"
+`)
+
+		annotations := templateAnnotations(result, "worker")
+		Expect(annotations).To(HaveKeyWithValue(postprocessor.FluentBitExcludeAnnotation, "true"))
+		Expect(annotations).To(HaveKeyWithValue(postprocessor.VectorExcludeAnnotation, "true"))
+	})
+
+	It("should map include, prefer exclude and skip invalid options", func() {
+		Expect(postprocessor.LoggingAnnotations(map[string]string{"include": "false"}, "api")).To(Equal(map[string]string{
+			postprocessor.FluentBitExcludeAnnotation: "true",
+			postprocessor.VectorExcludeAnnotation:    "true",
+		}))
+		Expect(postprocessor.LoggingAnnotations(map[string]string{"include": "false", "exclude": "false"}, "api")).To(
+			HaveKeyWithValue(postprocessor.FluentBitExcludeAnnotation, "false"))
+		Expect(postprocessor.LoggingAnnotations(map[string]string{"exclude": "maybe", "bad key!": "x"}, "api")).To(BeEmpty())
+	})
+})
@@ -0,0 +1,143 @@
+package postprocessor
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/logging"
+	"go.uber.org/zap"
+)
+
+const (
+	// HostAliasesLabel adds /etc/hosts entries, as a JSON array of {"ip": "...", "hostnames": [...]}
+	HostAliasesLabel = "lissto.dev/host-aliases"
+	// DNSServersLabel adds nameservers to the pod DNS config (comma-separated IPs)
+	DNSServersLabel = "lissto.dev/dns-servers"
+)
+
+// PodNetworkConfigurator sets hostAliases and dnsConfig from lissto.dev labels
+// Nameservers are added to the pod's DNS config; the DNS policy is left as is,
+// so cluster DNS keeps working. Entries with invalid IPs are skipped with a warning.
+type PodNetworkConfigurator struct{}
+
+// NewPodNetworkConfigurator creates a new pod network configurator
+func NewPodNetworkConfigurator() *PodNetworkConfigurator {
+	return &PodNetworkConfigurator{}
+}
+
+// Configure applies host-aliases and dns-servers labels to the pod specs of Kubernetes objects
+// serviceLabelMap maps service name to its labels from docker-compose
+func (p *PodNetworkConfigurator) Configure(objects []runtime.Object, serviceLabelMap map[string]map[string]string) []runtime.Object {
+	if len(serviceLabelMap) == 0 {
+		return objects
+	}
+
+	for _, obj := range objects {
+		switch resource := obj.(type) {
+		case *appsv1.Deployment:
+			// Match by deployment name (equals service name in Kompose)
+			if labels, exists := serviceLabelMap[resource.Name]; exists {
+				p.configurePodSpec(&resource.Spec.Template.Spec, labels, resource.Name)
+			}
+
+		case *appsv1.StatefulSet:
+			// Match by statefulset name (equals service name in Kompose)
+			if labels, exists := serviceLabelMap[resource.Name]; exists {
+				p.configurePodSpec(&resource.Spec.Template.Spec, labels, resource.Name)
+			}
+
+		case *corev1.Pod:
+			// Match by pod name or io.kompose.service label
+			serviceName := resource.Name
+			if komposeService, ok := resource.Labels["io.kompose.service"]; ok {
+				serviceName = komposeService
+			}
+			if labels, exists := serviceLabelMap[serviceName]; exists {
+				p.configurePodSpec(&resource.Spec, labels, serviceName)
+			}
+		}
+	}
+
+	return objects
+}
+
+// configurePodSpec applies both labels to a pod spec
+func (p *PodNetworkConfigurator) configurePodSpec(spec *corev1.PodSpec, labels map[string]string, serviceName string) {
+	if value := labels[HostAliasesLabel]; value != "" {
+		if aliases := parseHostAliases(value, serviceName); len(aliases) > 0 {
+			spec.HostAliases = append(spec.HostAliases, aliases...)
+			logging.Logger.Info("Adding host aliases",
+				zap.String("service", serviceName),
+				zap.Int("count", len(aliases)))
+		}
+	}
+
+	if value := labels[DNSServersLabel]; value != "" {
+		if servers := parseDNSServers(value, serviceName); len(servers) > 0 {
+			if spec.DNSConfig == nil {
+				spec.DNSConfig = &corev1.PodDNSConfig{}
+			}
+			spec.DNSConfig.Nameservers = append(spec.DNSConfig.Nameservers, servers...)
+			logging.Logger.Info("Adding DNS servers",
+				zap.String("service", serviceName),
+				zap.Strings("nameservers", servers))
+		}
+	}
+}
+
+// parseHostAliases parses the host-aliases label, skipping entries without a valid IP or hostnames
+func parseHostAliases(value, serviceName string) []corev1.HostAlias {
+	var entries []struct {
+		IP        string   `json:"ip"`
+		Hostnames []string `json:"hostnames"`
+	}
+	if err := json.Unmarshal([]byte(value), &entries); err != nil {
+		logging.Logger.Warn("Skipping invalid lissto.dev/host-aliases label",
+			zap.String("service", serviceName),
+			zap.String("label_value", value),
+			zap.Error(err))
+		return nil
+	}
+
+	var aliases []corev1.HostAlias
+	for _, entry := range entries {
+		if net.ParseIP(entry.IP) == nil {
+			logging.Logger.Warn("Skipping host alias with invalid IP",
+				zap.String("service", serviceName),
+				zap.String("ip", entry.IP))
+			continue
+		}
+		if len(entry.Hostnames) == 0 {
+			logging.Logger.Warn("Skipping host alias without hostnames",
+				zap.String("service", serviceName),
+				zap.String("ip", entry.IP))
+			continue
+		}
+		aliases = append(aliases, corev1.HostAlias{IP: entry.IP, Hostnames: entry.Hostnames})
+	}
+	return aliases
+}
+
+// parseDNSServers parses the comma-separated dns-servers label, skipping invalid IPs
+func parseDNSServers(value, serviceName string) []string {
+	var servers []string
+	for _, server := range strings.Split(value, ",") {
+		server = strings.TrimSpace(server)
+		if server == "" {
+			continue
+		}
+		if net.ParseIP(server) == nil {
+			logging.Logger.Warn("Skipping DNS server with invalid IP",
+				zap.String("service", serviceName),
+				zap.String("ip", server))
+			continue
+		}
+		servers = append(servers, server)
+	}
+	return servers
+}
@@ -0,0 +1,69 @@
+package postprocessor_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/postprocessor"
+)
+
+var _ = Describe("PodNetworkConfigurator", func() {
+	var configurator *postprocessor.PodNetworkConfigurator
+
+	configure := func(labels map[string]string) corev1.PodSpec {
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api"},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "api", Image: "api"}}},
+				},
+			},
+		}
+		result := configurator.Configure([]runtime.Object{deployment}, map[string]map[string]string{"api": labels})
+		return result[0].(*appsv1.Deployment).Spec.Template.Spec
+	}
+
+	BeforeEach(func() {
+		configurator = postprocessor.NewPodNetworkConfigurator()
+	})
+
+	It("should add host aliases", func() {
+		spec := configure(map[string]string{
+			postprocessor.HostAliasesLabel: `[{"ip":"10.1.2.3","hostnames":["legacy-db","legacy-db.corp"]},{"ip":"fd00::1","hostnames":["ldap"]}]`,
+		})
+
+		Expect(spec.HostAliases).To(Equal([]corev1.HostAlias{
+			{IP: "10.1.2.3", Hostnames: []string{"legacy-db", "legacy-db.corp"}},
+			{IP: "fd00::1", Hostnames: []string{"ldap"}},
+		}))
+		Expect(spec.DNSConfig).To(BeNil())
+	})
+
+	It("should add custom DNS servers without changing the DNS policy", func() {
+		spec := configure(map[string]string{postprocessor.DNSServersLabel: "10.0.0.53, 10.0.1.53"})
+
+		Expect(spec.DNSConfig).NotTo(BeNil())
+		Expect(spec.DNSConfig.Nameservers).To(Equal([]string{"10.0.0.53", "10.0.1.53"}))
+		Expect(spec.DNSPolicy).To(BeEmpty())
+	})
+
+	It("should skip entries with invalid IPs", func() {
+		spec := configure(map[string]string{
+			postprocessor.HostAliasesLabel: `[{"ip":"legacy-host","hostnames":["legacy"]},{"ip":"10.1.2.3","hostnames":["ok"]}]`,
+			postprocessor.DNSServersLabel:  "dns.corp,10.0.0.53",
+		})
+
+		Expect(spec.HostAliases).To(Equal([]corev1.HostAlias{{IP: "10.1.2.3", Hostnames: []string{"ok"}}}))
+		Expect(spec.DNSConfig.Nameservers).To(Equal([]string{"10.0.0.53"}))
+	})
+
+	It("should ignore malformed host aliases JSON", func() {
+		spec := configure(map[string]string{postprocessor.HostAliasesLabel: "10.1.2.3 legacy"})
+
+		Expect(spec.HostAliases).To(BeEmpty())
+	})
+})
@@ -0,0 +1,61 @@
+package postprocessor
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// PVCStorageInjector sets PVC capacity and storage class from compose volume hints
+// Kompose drops driver options of top-level volumes, so PVCs would otherwise get the default size
+type PVCStorageInjector struct{}
+
+func NewPVCStorageInjector() *PVCStorageInjector {
+	return &PVCStorageInjector{}
+}
+
+// InjectStorage applies size and storage class to PVCs matched by name
+func (p *PVCStorageInjector) InjectStorage(objects []runtime.Object, volumes map[string]compose.VolumeStorage) []runtime.Object {
+	if len(volumes) == 0 {
+		return objects
+	}
+
+	for i, obj := range objects {
+		pvc, ok := obj.(*corev1.PersistentVolumeClaim)
+		if !ok {
+			continue
+		}
+
+		storage, ok := volumes[pvc.Name]
+		if !ok {
+			continue
+		}
+
+		if storage.Size != "" {
+			quantity, err := resource.ParseQuantity(storage.Size)
+			if err != nil {
+				logging.Logger.Warn("Skipping invalid volume size",
+					zap.String("pvc", pvc.Name),
+					zap.String("size", storage.Size),
+					zap.Error(err))
+			} else {
+				if pvc.Spec.Resources.Requests == nil {
+					pvc.Spec.Resources.Requests = corev1.ResourceList{}
+				}
+				pvc.Spec.Resources.Requests[corev1.ResourceStorage] = quantity
+			}
+		}
+
+		if storage.StorageClass != "" {
+			storageClass := storage.StorageClass
+			pvc.Spec.StorageClassName = &storageClass
+		}
+
+		objects[i] = pvc
+	}
+	return objects
+}
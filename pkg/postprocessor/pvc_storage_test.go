@@ -0,0 +1,102 @@
+package postprocessor_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/postprocessor"
+)
+
+var _ = Describe("PVCStorageInjector", func() {
+	var injector *postprocessor.PVCStorageInjector
+
+	newPVC := func(name string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceStorage: resource.MustParse("100Mi"),
+					},
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		injector = postprocessor.NewPVCStorageInjector()
+	})
+
+	It("should set the requested capacity and storage class", func() {
+		objects := []runtime.Object{newPVC("pg-data")}
+		result := injector.InjectStorage(objects, map[string]compose.VolumeStorage{
+			"pg-data": {Size: "20Gi", StorageClass: "fast-ssd"},
+		})
+
+		pvc := result[0].(*corev1.PersistentVolumeClaim)
+		Expect(pvc.Spec.Resources.Requests[corev1.ResourceStorage]).To(Equal(resource.MustParse("20Gi")))
+		Expect(pvc.Spec.StorageClassName).NotTo(BeNil())
+		Expect(*pvc.Spec.StorageClassName).To(Equal("fast-ssd"))
+	})
+
+	It("should only set the class when no size is given", func() {
+		objects := []runtime.Object{newPVC("data")}
+		result := injector.InjectStorage(objects, map[string]compose.VolumeStorage{
+			"data": {StorageClass: "standard"},
+		})
+
+		pvc := result[0].(*corev1.PersistentVolumeClaim)
+		Expect(pvc.Spec.Resources.Requests[corev1.ResourceStorage]).To(Equal(resource.MustParse("100Mi")))
+		Expect(*pvc.Spec.StorageClassName).To(Equal("standard"))
+	})
+
+	It("should leave unmatched PVCs untouched", func() {
+		objects := []runtime.Object{newPVC("other")}
+		result := injector.InjectStorage(objects, map[string]compose.VolumeStorage{
+			"data": {Size: "20Gi"},
+		})
+
+		pvc := result[0].(*corev1.PersistentVolumeClaim)
+		Expect(pvc.Spec.Resources.Requests[corev1.ResourceStorage]).To(Equal(resource.MustParse("100Mi")))
+		Expect(pvc.Spec.StorageClassName).To(BeNil())
+	})
+
+	It("should size PVCs generated by Kompose from a compose volume", func() {
+		composeContent := `
+services:
+  db:
+    image: postgres:15
+    volumes:
+      - pg_data:/var/lib/postgresql/data
+volumes:
+  pg_data:
+    driver_opts:
+      size: 20Gi
+`
+		project, err := loadProject(composeContent)
+		Expect(err).NotTo(HaveOccurred())
+
+		storage, err := compose.ExtractVolumeStorage(project)
+		Expect(err).NotTo(HaveOccurred())
+
+		objects, err := convertProject(project)
+		Expect(err).NotTo(HaveOccurred())
+
+		result := injector.InjectStorage(objects, storage)
+
+		var pvcs []*corev1.PersistentVolumeClaim
+		for _, obj := range result {
+			if pvc, ok := obj.(*corev1.PersistentVolumeClaim); ok {
+				pvcs = append(pvcs, pvc)
+			}
+		}
+		Expect(pvcs).To(HaveLen(1))
+		Expect(pvcs[0].Name).To(Equal("pg-data"))
+		Expect(pvcs[0].Spec.Resources.Requests[corev1.ResourceStorage]).To(Equal(resource.MustParse("20Gi")))
+	})
+})
@@ -0,0 +1,93 @@
+package postprocessor
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/logging"
+	"go.uber.org/zap"
+)
+
+const (
+	// ResourceClassAnnotation marks generated objects as state or workload
+	ResourceClassAnnotation = "lissto.dev/resource-class"
+	// ResourceClassLabel is the compose service label overriding the kind-based class
+	ResourceClassLabel = "lissto.dev/class"
+
+	// ResourceClassState is used for objects holding data (PVCs, StatefulSets)
+	ResourceClassState = "state"
+	// ResourceClassWorkload is used for everything that can be recreated freely
+	ResourceClassWorkload = "workload"
+)
+
+// ResourceClassifier annotates objects with their resource class
+// The default comes from the Kubernetes kind; a lissto.dev/class service label overrides it
+// for every object generated for that service
+type ResourceClassifier struct{}
+
+// NewResourceClassifier creates a new resource classifier
+func NewResourceClassifier() *ResourceClassifier {
+	return &ResourceClassifier{}
+}
+
+// Classify sets ResourceClassAnnotation on all objects
+// serviceLabelMap maps service name to its labels from docker-compose
+func (r *ResourceClassifier) Classify(objects []runtime.Object, serviceLabelMap map[string]map[string]string) []runtime.Object {
+	for i, obj := range objects {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			continue
+		}
+
+		class := r.classFromKind(obj)
+		serviceName := serviceNameOf(accessor.GetName(), accessor.GetLabels())
+		if override := r.classFromLabels(serviceLabelMap[serviceName], serviceName); override != "" {
+			class = override
+		}
+
+		annotations := accessor.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[ResourceClassAnnotation] = class
+		accessor.SetAnnotations(annotations)
+		objects[i] = obj
+	}
+	return objects
+}
+
+// classFromKind returns the kind-based default class
+func (r *ResourceClassifier) classFromKind(obj runtime.Object) string {
+	switch obj.(type) {
+	case *corev1.PersistentVolumeClaim, *appsv1.StatefulSet:
+		return ResourceClassState
+	default:
+		return ResourceClassWorkload
+	}
+}
+
+// classFromLabels returns the class override from service labels, empty if absent or invalid
+func (r *ResourceClassifier) classFromLabels(labels map[string]string, serviceName string) string {
+	value, ok := labels[ResourceClassLabel]
+	if !ok || value == "" {
+		return ""
+	}
+	if value != ResourceClassState && value != ResourceClassWorkload {
+		logging.Logger.Warn("Ignoring invalid lissto.dev/class label",
+			zap.String("service", serviceName),
+			zap.String("label_value", value))
+		return ""
+	}
+	return value
+}
+
+// serviceNameOf returns the compose service an object was generated for
+// Kompose sets io.kompose.service on generated objects; workloads are also named after the service
+func serviceNameOf(name string, labels map[string]string) string {
+	if service, ok := labels["io.kompose.service"]; ok && service != "" {
+		return service
+	}
+	return name
+}
@@ -0,0 +1,110 @@
+package postprocessor_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/postprocessor"
+)
+
+var _ = Describe("ResourceClassifier", func() {
+	var classifier *postprocessor.ResourceClassifier
+
+	BeforeEach(func() {
+		classifier = postprocessor.NewResourceClassifier()
+	})
+
+	Context("without class labels", func() {
+		It("should classify by kind", func() {
+			objects := []runtime.Object{
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web"}},
+				&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db"}},
+				&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "db-data"}},
+				&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web"}},
+			}
+
+			result := classifier.Classify(objects, nil)
+
+			Expect(result[0].(*appsv1.Deployment).Annotations).To(HaveKeyWithValue(postprocessor.ResourceClassAnnotation, "workload"))
+			Expect(result[1].(*appsv1.StatefulSet).Annotations).To(HaveKeyWithValue(postprocessor.ResourceClassAnnotation, "state"))
+			Expect(result[2].(*corev1.PersistentVolumeClaim).Annotations).To(HaveKeyWithValue(postprocessor.ResourceClassAnnotation, "state"))
+			Expect(result[3].(*corev1.Service).Annotations).To(HaveKeyWithValue(postprocessor.ResourceClassAnnotation, "workload"))
+		})
+	})
+
+	Context("with lissto.dev/class label", func() {
+		It("should override the default for a Deployment labeled as state", func() {
+			objects := []runtime.Object{
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+					Name:   "cache",
+					Labels: map[string]string{"io.kompose.service": "cache"},
+				}},
+			}
+
+			result := classifier.Classify(objects, map[string]map[string]string{
+				"cache": {"lissto.dev/class": "state"},
+			})
+
+			Expect(result[0].(*appsv1.Deployment).Annotations).To(HaveKeyWithValue(postprocessor.ResourceClassAnnotation, "state"))
+		})
+
+		It("should propagate the class to all objects of the service", func() {
+			objects := []runtime.Object{
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+					Name:   "cache",
+					Labels: map[string]string{"io.kompose.service": "cache"},
+				}},
+				&corev1.Service{ObjectMeta: metav1.ObjectMeta{
+					Name:   "cache",
+					Labels: map[string]string{"io.kompose.service": "cache"},
+				}},
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+					Name:   "cache-warmup",
+					Labels: map[string]string{"io.kompose.service": "cache"},
+				}},
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+					Name:   "web",
+					Labels: map[string]string{"io.kompose.service": "web"},
+				}},
+			}
+
+			result := classifier.Classify(objects, map[string]map[string]string{
+				"cache": {"lissto.dev/class": "state"},
+			})
+
+			Expect(result[0].(*appsv1.Deployment).Annotations).To(HaveKeyWithValue(postprocessor.ResourceClassAnnotation, "state"))
+			Expect(result[1].(*corev1.Service).Annotations).To(HaveKeyWithValue(postprocessor.ResourceClassAnnotation, "state"))
+			Expect(result[2].(*corev1.Pod).Annotations).To(HaveKeyWithValue(postprocessor.ResourceClassAnnotation, "state"))
+			Expect(result[3].(*appsv1.Deployment).Annotations).To(HaveKeyWithValue(postprocessor.ResourceClassAnnotation, "workload"))
+		})
+
+		It("should ignore invalid class values", func() {
+			objects := []runtime.Object{
+				&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db"}},
+			}
+
+			result := classifier.Classify(objects, map[string]map[string]string{
+				"db": {"lissto.dev/class": "ephemeral"},
+			})
+
+			Expect(result[0].(*appsv1.StatefulSet).Annotations).To(HaveKeyWithValue(postprocessor.ResourceClassAnnotation, "state"))
+		})
+
+		It("should keep existing annotations", func() {
+			objects := []runtime.Object{
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+					Name:        "web",
+					Annotations: map[string]string{"team": "platform"},
+				}},
+			}
+
+			result := classifier.Classify(objects, nil)
+
+			Expect(result[0].(*appsv1.Deployment).Annotations).To(HaveKeyWithValue("team", "platform"))
+		})
+	})
+})
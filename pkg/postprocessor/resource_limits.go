@@ -0,0 +1,187 @@
+package postprocessor
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// Resource labels override requests and limits per service (Kubernetes quantities, e.g. 250m, 512Mi)
+const (
+	CPURequestLabel    = compose.CPURequestLabel
+	MemoryRequestLabel = compose.MemoryRequestLabel
+	CPULimitLabel      = compose.CPULimitLabel
+	MemoryLimitLabel   = compose.MemoryLimitLabel
+)
+
+// ResourceValues is a CPU and memory pair of Kubernetes quantities, empty values are not set
+type ResourceValues struct {
+	CPU    string `yaml:"cpu"`
+	Memory string `yaml:"memory"`
+}
+
+// ResourceDefaults are the requests and limits given to containers that specify none
+type ResourceDefaults struct {
+	Requests ResourceValues `yaml:"requests"`
+	Limits   ResourceValues `yaml:"limits"`
+}
+
+// Validate checks that all set values are valid quantities and requests don't exceed limits
+func (d ResourceDefaults) Validate() error {
+	for _, field := range []struct{ name, value string }{
+		{"requests.cpu", d.Requests.CPU},
+		{"requests.memory", d.Requests.Memory},
+		{"limits.cpu", d.Limits.CPU},
+		{"limits.memory", d.Limits.Memory},
+	} {
+		if field.value == "" {
+			continue
+		}
+		if _, err := resource.ParseQuantity(field.value); err != nil {
+			return fmt.Errorf("invalid %s %q: %w", field.name, field.value, err)
+		}
+	}
+	for _, pair := range []struct{ name, request, limit string }{
+		{"cpu", d.Requests.CPU, d.Limits.CPU},
+		{"memory", d.Requests.Memory, d.Limits.Memory},
+	} {
+		if pair.request == "" || pair.limit == "" {
+			continue
+		}
+		request, limit := resource.MustParse(pair.request), resource.MustParse(pair.limit)
+		if request.Cmp(limit) > 0 {
+			return fmt.Errorf("%s request %s exceeds limit %s", pair.name, pair.request, pair.limit)
+		}
+	}
+	return nil
+}
+
+// ResourceLimitInjector gives every container CPU/memory requests and limits
+// Priority per value: lissto.dev resource label → compose deploy.resources → configured default,
+// where infra services (see compose.IsInfraService) get their own defaults.
+// A default request above the container's limit is lowered to the limit, and a default limit
+// below the container's request is skipped, so the result is always schedulable.
+type ResourceLimitInjector struct {
+	app   ResourceDefaults
+	infra ResourceDefaults
+}
+
+// NewResourceLimitInjector creates an injector with the app and infra defaults
+func NewResourceLimitInjector(app, infra ResourceDefaults) *ResourceLimitInjector {
+	return &ResourceLimitInjector{app: app, infra: infra}
+}
+
+// Inject applies resource labels and defaults to the containers of workload objects
+// serviceLabelMap maps service name to its labels from docker-compose, infraServices names the infra services
+func (r *ResourceLimitInjector) Inject(objects []runtime.Object, serviceLabelMap map[string]map[string]string, infraServices map[string]bool) []runtime.Object {
+	for _, obj := range objects {
+		switch workload := obj.(type) {
+		case *appsv1.Deployment:
+			serviceName := serviceNameOf(workload.Name, workload.Labels)
+			r.injectPodSpec(&workload.Spec.Template.Spec, serviceLabelMap[serviceName], infraServices[serviceName], serviceName)
+
+		case *appsv1.StatefulSet:
+			serviceName := serviceNameOf(workload.Name, workload.Labels)
+			r.injectPodSpec(&workload.Spec.Template.Spec, serviceLabelMap[serviceName], infraServices[serviceName], serviceName)
+
+		case *corev1.Pod:
+			serviceName := serviceNameOf(workload.Name, workload.Labels)
+			r.injectPodSpec(&workload.Spec, serviceLabelMap[serviceName], infraServices[serviceName], serviceName)
+		}
+	}
+	return objects
+}
+
+// injectPodSpec applies labels, then defaults, to every container of a pod spec
+func (r *ResourceLimitInjector) injectPodSpec(spec *corev1.PodSpec, labels map[string]string, infra bool, serviceName string) {
+	defaults := r.app
+	if infra {
+		defaults = r.infra
+	}
+
+	for i := range spec.Containers {
+		resources := &spec.Containers[i].Resources
+
+		// Labels override whatever compose set
+		r.applyLabel(&resources.Requests, corev1.ResourceCPU, labels, CPURequestLabel, serviceName)
+		r.applyLabel(&resources.Requests, corev1.ResourceMemory, labels, MemoryRequestLabel, serviceName)
+		r.applyLabel(&resources.Limits, corev1.ResourceCPU, labels, CPULimitLabel, serviceName)
+		r.applyLabel(&resources.Limits, corev1.ResourceMemory, labels, MemoryLimitLabel, serviceName)
+
+		// Defaults only fill values still missing
+		r.applyDefaultRequest(resources, corev1.ResourceCPU, defaults.Requests.CPU)
+		r.applyDefaultRequest(resources, corev1.ResourceMemory, defaults.Requests.Memory)
+		r.applyDefaultLimit(resources, corev1.ResourceCPU, defaults.Limits.CPU)
+		r.applyDefaultLimit(resources, corev1.ResourceMemory, defaults.Limits.Memory)
+	}
+}
+
+// applyLabel sets a request or limit from a resource label, ignoring invalid quantities
+func (r *ResourceLimitInjector) applyLabel(list *corev1.ResourceList, name corev1.ResourceName, labels map[string]string, label, serviceName string) {
+	value := labels[label]
+	if value == "" {
+		return
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		logging.Logger.Warn("Ignoring invalid resource label",
+			zap.String("service", serviceName),
+			zap.String("label", label),
+			zap.String("label_value", value))
+		return
+	}
+	if *list == nil {
+		*list = corev1.ResourceList{}
+	}
+	(*list)[name] = quantity
+}
+
+// applyDefaultRequest sets a missing request, capped at the container's limit
+// Defaults are validated with the settings, so unparsable values are simply skipped
+func (r *ResourceLimitInjector) applyDefaultRequest(resources *corev1.ResourceRequirements, name corev1.ResourceName, value string) {
+	if value == "" {
+		return
+	}
+	if _, ok := resources.Requests[name]; ok {
+		return
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return
+	}
+	if limit, ok := resources.Limits[name]; ok && quantity.Cmp(limit) > 0 {
+		quantity = limit.DeepCopy()
+	}
+	if resources.Requests == nil {
+		resources.Requests = corev1.ResourceList{}
+	}
+	resources.Requests[name] = quantity
+}
+
+// applyDefaultLimit sets a missing limit unless it is below the container's request
+func (r *ResourceLimitInjector) applyDefaultLimit(resources *corev1.ResourceRequirements, name corev1.ResourceName, value string) {
+	if value == "" {
+		return
+	}
+	if _, ok := resources.Limits[name]; ok {
+		return
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return
+	}
+	if request, ok := resources.Requests[name]; ok && quantity.Cmp(request) < 0 {
+		return
+	}
+	if resources.Limits == nil {
+		resources.Limits = corev1.ResourceList{}
+	}
+	resources.Limits[name] = quantity
+}
@@ -0,0 +1,189 @@
+package postprocessor_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/postprocessor"
+)
+
+var _ = Describe("ResourceLimitInjector", func() {
+	var injector *postprocessor.ResourceLimitInjector
+
+	app := postprocessor.ResourceDefaults{
+		Requests: postprocessor.ResourceValues{CPU: "100m", Memory: "128Mi"},
+		Limits:   postprocessor.ResourceValues{Memory: "512Mi"},
+	}
+	infra := postprocessor.ResourceDefaults{
+		Requests: postprocessor.ResourceValues{CPU: "250m", Memory: "1Gi"},
+		Limits:   postprocessor.ResourceValues{Memory: "2Gi"},
+	}
+
+	newDeployment := func(name string, resources corev1.ResourceRequirements) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: name, Image: name, Resources: resources}}},
+				},
+			},
+		}
+	}
+
+	resourcesOf := func(deployment *appsv1.Deployment) corev1.ResourceRequirements {
+		return deployment.Spec.Template.Spec.Containers[0].Resources
+	}
+
+	BeforeEach(func() {
+		injector = postprocessor.NewResourceLimitInjector(app, infra)
+	})
+
+	It("should apply the app defaults when a container sets none", func() {
+		api := newDeployment("api", corev1.ResourceRequirements{})
+		injector.Inject([]runtime.Object{api}, nil, nil)
+
+		Expect(resourcesOf(api).Requests).To(Equal(corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+			corev1.ResourceMemory: resource.MustParse("128Mi"),
+		}))
+		Expect(resourcesOf(api).Limits).To(Equal(corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("512Mi"),
+		}))
+	})
+
+	It("should apply the infra defaults to infra services", func() {
+		api := newDeployment("api", corev1.ResourceRequirements{})
+		db := newDeployment("db", corev1.ResourceRequirements{})
+		injector.Inject([]runtime.Object{api, db}, nil, map[string]bool{"db": true})
+
+		Expect(resourcesOf(db).Requests[corev1.ResourceMemory]).To(Equal(resource.MustParse("1Gi")))
+		Expect(resourcesOf(db).Limits[corev1.ResourceMemory]).To(Equal(resource.MustParse("2Gi")))
+		Expect(resourcesOf(api).Requests[corev1.ResourceMemory]).To(Equal(resource.MustParse("128Mi")))
+	})
+
+	It("should let labels override compose values and defaults", func() {
+		api := newDeployment("api", corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+		})
+		injector.Inject([]runtime.Object{api}, map[string]map[string]string{
+			"api": {
+				postprocessor.CPULimitLabel:      "2",
+				postprocessor.MemoryRequestLabel: "256Mi",
+			},
+		}, nil)
+
+		Expect(resourcesOf(api).Limits[corev1.ResourceCPU]).To(Equal(resource.MustParse("2")))
+		Expect(resourcesOf(api).Requests[corev1.ResourceMemory]).To(Equal(resource.MustParse("256Mi")))
+		Expect(resourcesOf(api).Requests[corev1.ResourceCPU]).To(Equal(resource.MustParse("100m")))
+	})
+
+	It("should keep values set in compose", func() {
+		api := newDeployment("api", corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")},
+			Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("96Mi")},
+		})
+		injector.Inject([]runtime.Object{api}, nil, nil)
+
+		Expect(resourcesOf(api).Requests[corev1.ResourceMemory]).To(Equal(resource.MustParse("64Mi")))
+		Expect(resourcesOf(api).Limits[corev1.ResourceMemory]).To(Equal(resource.MustParse("96Mi")))
+	})
+
+	It("should keep default requests within the container's limits", func() {
+		api := newDeployment("api", corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")},
+		})
+		worker := newDeployment("worker", corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+		})
+		injector.Inject([]runtime.Object{api, worker}, nil, nil)
+
+		Expect(resourcesOf(api).Requests[corev1.ResourceMemory]).To(Equal(resource.MustParse("64Mi")))
+		Expect(resourcesOf(worker).Limits).NotTo(HaveKey(corev1.ResourceMemory))
+	})
+
+	It("should ignore invalid label values", func() {
+		api := newDeployment("api", corev1.ResourceRequirements{})
+		injector.Inject([]runtime.Object{api}, map[string]map[string]string{
+			"api": {postprocessor.CPURequestLabel: "lots"},
+		}, nil)
+
+		Expect(resourcesOf(api).Requests[corev1.ResourceCPU]).To(Equal(resource.MustParse("100m")))
+	})
+
+	It("should add nothing without defaults or labels", func() {
+		api := newDeployment("api", corev1.ResourceRequirements{})
+		postprocessor.NewResourceLimitInjector(postprocessor.ResourceDefaults{}, postprocessor.ResourceDefaults{}).
+			Inject([]runtime.Object{api}, nil, nil)
+
+		Expect(resourcesOf(api)).To(Equal(corev1.ResourceRequirements{}))
+	})
+
+	It("should classify services converted by Kompose", func() {
+		project, err := loadProject(`
+services:
+  api:
+    build: .
+    image: api
+    deploy:
+      resources:
+        limits:
+          memory: 256M
+  db:
+    image: postgres:16
+  cache:
+    build: ./cache
+    labels:
+      lissto.dev/group: cache
+`)
+		Expect(err).NotTo(HaveOccurred())
+		objects, err := convertProject(project)
+		Expect(err).NotTo(HaveOccurred())
+
+		labels := map[string]map[string]string{}
+		for name, service := range project.Services {
+			labels[name] = service.Labels
+		}
+		injector.Inject(objects, labels, compose.InfraServices(project))
+
+		deployments := map[string]*appsv1.Deployment{}
+		for _, obj := range objects {
+			if deployment, ok := obj.(*appsv1.Deployment); ok {
+				deployments[deployment.Name] = deployment
+			}
+		}
+		Expect(deployments).To(HaveKey("api"))
+		Expect(resourcesOf(deployments["api"]).Requests[corev1.ResourceMemory]).To(Equal(resource.MustParse("128Mi")))
+		composeLimit := resourcesOf(deployments["api"]).Limits[corev1.ResourceMemory]
+		Expect(composeLimit.Value()).To(BeNumerically("==", 256*1024*1024))
+		Expect(resourcesOf(deployments["db"]).Requests[corev1.ResourceMemory]).To(Equal(resource.MustParse("1Gi")))
+		Expect(resourcesOf(deployments["cache"]).Requests[corev1.ResourceMemory]).To(Equal(resource.MustParse("1Gi")))
+	})
+})
+
+var _ = Describe("ResourceDefaults", func() {
+	It("should accept valid quantities", func() {
+		Expect(postprocessor.ResourceDefaults{
+			Requests: postprocessor.ResourceValues{CPU: "100m", Memory: "128Mi"},
+			Limits:   postprocessor.ResourceValues{CPU: "1", Memory: "1Gi"},
+		}.Validate()).To(Succeed())
+	})
+
+	It("should reject invalid quantities", func() {
+		Expect(postprocessor.ResourceDefaults{
+			Requests: postprocessor.ResourceValues{Memory: "lots"},
+		}.Validate()).To(MatchError(ContainSubstring("requests.memory")))
+	})
+
+	It("should reject requests above limits", func() {
+		Expect(postprocessor.ResourceDefaults{
+			Requests: postprocessor.ResourceValues{Memory: "2Gi"},
+			Limits:   postprocessor.ResourceValues{Memory: "1Gi"},
+		}.Validate()).To(MatchError(ContainSubstring("exceeds limit")))
+	})
+})
@@ -0,0 +1,53 @@
+package postprocessor
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Apply order ranks, lower ranks are applied first
+const (
+	rankNamespace = iota
+	rankDependency
+	rankState
+	rankWorkload
+)
+
+// ResourceOrderer sorts objects so that what workloads depend on is applied first:
+// namespaces, then PVCs, ConfigMaps, Secrets and Ingresses, then other state objects
+// (by ResourceClassAnnotation, so run it after the ResourceClassifier), then workloads.
+// The sort is stable, objects of the same rank keep their order.
+type ResourceOrderer struct{}
+
+// NewResourceOrderer creates a new resource orderer
+func NewResourceOrderer() *ResourceOrderer {
+	return &ResourceOrderer{}
+}
+
+// Order sorts objects into apply order
+func (r *ResourceOrderer) Order(objects []runtime.Object) []runtime.Object {
+	sort.SliceStable(objects, func(i, j int) bool {
+		return r.rank(objects[i]) < r.rank(objects[j])
+	})
+	return objects
+}
+
+// rank returns the apply order rank of an object
+func (r *ResourceOrderer) rank(obj runtime.Object) int {
+	switch obj.(type) {
+	case *corev1.Namespace:
+		return rankNamespace
+	case *corev1.PersistentVolumeClaim, *corev1.ConfigMap, *corev1.Secret, *networkingv1.Ingress:
+		return rankDependency
+	}
+
+	accessor, err := meta.Accessor(obj)
+	if err == nil && accessor.GetAnnotations()[ResourceClassAnnotation] == ResourceClassState {
+		return rankState
+	}
+	return rankWorkload
+}
@@ -0,0 +1,80 @@
+package postprocessor_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/kompose"
+	"github.com/lissto-dev/api/pkg/postprocessor"
+)
+
+var _ = Describe("ResourceOrderer", func() {
+	It("should serialize PVCs before the deployments mounting them", func() {
+		project, err := loadProject(`
+services:
+  api:
+    image: nginx
+    volumes:
+      - uploads:/data
+    ports:
+      - "80:80"
+volumes:
+  uploads: {}
+`)
+		Expect(err).NotTo(HaveOccurred())
+		objects, err := convertProject(project)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Put the PVC last so the test does not depend on Kompose's own order
+		for i, obj := range objects {
+			if _, ok := obj.(*corev1.PersistentVolumeClaim); ok {
+				objects = append(append(objects[:i:i], objects[i+1:]...), obj)
+				break
+			}
+		}
+
+		objects = postprocessor.NewResourceClassifier().Classify(objects, nil)
+		objects = postprocessor.NewResourceOrderer().Order(objects)
+		manifests, err := kompose.NewConverter("test").SerializeToYAML(objects)
+		Expect(err).NotTo(HaveOccurred())
+
+		pvc := strings.Index(manifests, "kind: PersistentVolumeClaim")
+		deployment := strings.Index(manifests, "kind: Deployment")
+		Expect(pvc).To(BeNumerically(">=", 0))
+		Expect(deployment).To(BeNumerically(">", pvc))
+	})
+
+	It("should order namespaces, dependencies, state and workloads", func() {
+		stateful := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db"}}
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api"}}
+		service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "api"}}
+		configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config"}}
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "creds"}}
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev-alice"}}
+
+		objects := []runtime.Object{deployment, stateful, service, configMap, namespace, secret}
+		objects = postprocessor.NewResourceClassifier().Classify(objects, nil)
+
+		Expect(postprocessor.NewResourceOrderer().Order(objects)).To(Equal([]runtime.Object{
+			namespace, configMap, secret, stateful, deployment, service,
+		}))
+	})
+
+	It("should use the class annotation for services overridden to state", func() {
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api"}}
+		cache := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "cache"}}
+
+		objects := []runtime.Object{deployment, cache}
+		objects = postprocessor.NewResourceClassifier().Classify(objects, map[string]map[string]string{
+			"cache": {postprocessor.ResourceClassLabel: postprocessor.ResourceClassState},
+		})
+
+		Expect(postprocessor.NewResourceOrderer().Order(objects)).To(Equal([]runtime.Object{cache, deployment}))
+	})
+})
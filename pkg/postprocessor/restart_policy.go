@@ -0,0 +1,131 @@
+package postprocessor
+
+import (
+	"maps"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// RestartPolicyMapper picks the workload kind and pod restartPolicy from the compose restart policy:
+//   - always, unless-stopped: Deployment (restartPolicy Always)
+//   - on-failure: Job (restartPolicy OnFailure, backoffLimit from the max attempts)
+//   - no: standalone Pod (restartPolicy Never), run once
+//
+// Kompose turns on-failure services into bare Pods that are never retried once the node goes away,
+// so they become Jobs. Only Deployments and Pods are converted; StatefulSets and other controllers
+// chosen explicitly are kept. Run it after the pod spec postprocessors, the new object keeps the spec.
+type RestartPolicyMapper struct{}
+
+// NewRestartPolicyMapper creates a new restart policy mapper
+func NewRestartPolicyMapper() *RestartPolicyMapper {
+	return &RestartPolicyMapper{}
+}
+
+// Map replaces workloads whose kind doesn't match their service's restart policy
+// policies maps service name to its restart policy, services without one are left as converted
+func (m *RestartPolicyMapper) Map(objects []runtime.Object, policies map[string]compose.RestartPolicy) []runtime.Object {
+	if len(policies) == 0 {
+		return objects
+	}
+
+	for i, obj := range objects {
+		switch workload := obj.(type) {
+		case *appsv1.Deployment:
+			serviceName := serviceNameOf(workload.Name, workload.Labels)
+			policy, ok := policies[serviceName]
+			if !ok {
+				continue
+			}
+			if runsContinuously(policy) {
+				workload.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyAlways
+				continue
+			}
+			objects[i] = m.workloadFor(policy, workload.ObjectMeta, workload.Spec.Template, serviceName)
+
+		case *corev1.Pod:
+			serviceName := serviceNameOf(workload.Name, workload.Labels)
+			policy, ok := policies[serviceName]
+			if !ok {
+				continue
+			}
+			if policy.Policy == compose.RestartNo {
+				workload.Spec.RestartPolicy = corev1.RestartPolicyNever
+				continue
+			}
+			template := corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: workload.Labels, Annotations: workload.Annotations},
+				Spec:       workload.Spec,
+			}
+			meta := metav1.ObjectMeta{Name: workload.Name, Namespace: workload.Namespace, Labels: maps.Clone(workload.Labels)}
+			objects[i] = m.workloadFor(policy, meta, template, serviceName)
+		}
+	}
+
+	return objects
+}
+
+// runsContinuously reports whether the policy keeps the service running (a Deployment)
+func runsContinuously(policy compose.RestartPolicy) bool {
+	return policy.Policy == compose.RestartAlways || policy.Policy == compose.RestartUnlessStopped
+}
+
+// workloadFor builds the workload for a restart policy from object metadata and a pod template
+func (m *RestartPolicyMapper) workloadFor(policy compose.RestartPolicy, meta metav1.ObjectMeta, template corev1.PodTemplateSpec, serviceName string) runtime.Object {
+	switch policy.Policy {
+	case compose.RestartOnFailure:
+		template.Spec.RestartPolicy = corev1.RestartPolicyOnFailure
+		logging.Logger.Info("Running service as a Job for restart policy on-failure",
+			zap.String("service", serviceName))
+		return &batchv1.Job{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"},
+			ObjectMeta: meta,
+			Spec: batchv1.JobSpec{
+				BackoffLimit: policy.MaxAttempts,
+				Template:     template,
+			},
+		}
+
+	case compose.RestartNo:
+		template.Spec.RestartPolicy = corev1.RestartPolicyNever
+		logging.Logger.Info("Running service as a standalone Pod for restart policy no",
+			zap.String("service", serviceName))
+		return &corev1.Pod{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        meta.Name,
+				Namespace:   meta.Namespace,
+				Labels:      template.Labels,
+				Annotations: template.Annotations,
+			},
+			Spec: template.Spec,
+		}
+
+	default:
+		template.Spec.RestartPolicy = corev1.RestartPolicyAlways
+		if template.Labels == nil {
+			template.Labels = map[string]string{}
+		}
+		template.Labels["io.kompose.service"] = serviceName
+		replicas := int32(1)
+		logging.Logger.Info("Running service as a Deployment",
+			zap.String("service", serviceName),
+			zap.String("restart", policy.Policy))
+		return &appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: meta,
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"io.kompose.service": serviceName}},
+				Template: template,
+			},
+		}
+	}
+}
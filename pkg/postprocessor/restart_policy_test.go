@@ -0,0 +1,140 @@
+package postprocessor_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/compose"
+	"github.com/lissto-dev/api/pkg/postprocessor"
+)
+
+var _ = Describe("RestartPolicyMapper", func() {
+	var mapper *postprocessor.RestartPolicyMapper
+
+	BeforeEach(func() {
+		mapper = postprocessor.NewRestartPolicyMapper()
+	})
+
+	// convert runs the compose content through Kompose and the mapper, keyed by object name
+	convert := func(content string) map[string]runtime.Object {
+		project, err := loadProject(content)
+		Expect(err).NotTo(HaveOccurred())
+		policies := compose.ExtractServiceRestartPolicies(project)
+		compose.NormalizeRestartPolicies(project)
+
+		objects, err := convertProject(project)
+		Expect(err).NotTo(HaveOccurred())
+		objects = mapper.Map(objects, policies)
+
+		byName := map[string]runtime.Object{}
+		for _, obj := range objects {
+			switch workload := obj.(type) {
+			case *appsv1.Deployment, *appsv1.StatefulSet, *batchv1.Job, *corev1.Pod:
+				byName[workload.(metav1.Object).GetName()] = obj
+			}
+		}
+		return byName
+	}
+
+	It("should map each restart policy to its workload kind", func() {
+		workloads := convert(`
+services:
+  web:
+    image: nginx
+    restart: always
+  api:
+    image: api
+    restart: unless-stopped
+  worker:
+    image: worker
+    restart: "on-failure:4"
+  migrate:
+    image: migrate
+    restart: "no"
+  default:
+    image: busybox
+`)
+
+		Expect(workloads["web"]).To(BeAssignableToTypeOf(&appsv1.Deployment{}))
+		Expect(workloads["web"].(*appsv1.Deployment).Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyAlways))
+		Expect(workloads["api"]).To(BeAssignableToTypeOf(&appsv1.Deployment{}))
+		Expect(workloads["api"].(*appsv1.Deployment).Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyAlways))
+		Expect(workloads["default"]).To(BeAssignableToTypeOf(&appsv1.Deployment{}))
+
+		Expect(workloads["worker"]).To(BeAssignableToTypeOf(&batchv1.Job{}))
+		job := workloads["worker"].(*batchv1.Job)
+		Expect(job.Kind).To(Equal("Job"))
+		Expect(job.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyOnFailure))
+		Expect(job.Spec.BackoffLimit).To(HaveValue(Equal(int32(4))))
+		Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal("worker"))
+		Expect(job.Spec.Template.Labels).To(HaveKeyWithValue("io.kompose.service", "worker"))
+
+		Expect(workloads["migrate"]).To(BeAssignableToTypeOf(&corev1.Pod{}))
+		Expect(workloads["migrate"].(*corev1.Pod).Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+	})
+
+	It("should take the policy and attempts from deploy.restart_policy", func() {
+		workloads := convert(`
+services:
+  seed:
+    image: seed
+    deploy:
+      restart_policy:
+        condition: on-failure
+        max_attempts: 2
+  oneshot:
+    image: oneshot
+    deploy:
+      restart_policy:
+        condition: none
+`)
+
+		Expect(workloads["seed"]).To(BeAssignableToTypeOf(&batchv1.Job{}))
+		Expect(workloads["seed"].(*batchv1.Job).Spec.BackoffLimit).To(HaveValue(Equal(int32(2))))
+		Expect(workloads["oneshot"]).To(BeAssignableToTypeOf(&corev1.Pod{}))
+		Expect(workloads["oneshot"].(*corev1.Pod).Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+	})
+
+	It("should convert Deployments and Pods whose kind doesn't match the policy", func() {
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "task", Labels: map[string]string{"io.kompose.service": "task"}},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"io.kompose.service": "task"}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "task", Image: "task"}}},
+			}},
+		}
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "server", Labels: map[string]string{"io.kompose.service": "server"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "server", Image: "server"}}},
+		}
+
+		objects := mapper.Map([]runtime.Object{deployment, pod}, map[string]compose.RestartPolicy{
+			"task":   {Policy: compose.RestartNo},
+			"server": {Policy: compose.RestartAlways},
+		})
+
+		Expect(objects[0]).To(BeAssignableToTypeOf(&corev1.Pod{}))
+		Expect(objects[0].(*corev1.Pod).Name).To(Equal("task"))
+		Expect(objects[0].(*corev1.Pod).Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+
+		Expect(objects[1]).To(BeAssignableToTypeOf(&appsv1.Deployment{}))
+		server := objects[1].(*appsv1.Deployment)
+		Expect(server.Spec.Selector.MatchLabels).To(Equal(map[string]string{"io.kompose.service": "server"}))
+		Expect(server.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyAlways))
+		Expect(server.Spec.Template.Spec.Containers[0].Image).To(Equal("server"))
+	})
+
+	It("should keep StatefulSets", func() {
+		statefulSet := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db"}}
+		objects := mapper.Map([]runtime.Object{statefulSet}, map[string]compose.RestartPolicy{
+			"db": {Policy: compose.RestartOnFailure},
+		})
+
+		Expect(objects[0]).To(BeIdenticalTo(statefulSet))
+	})
+})
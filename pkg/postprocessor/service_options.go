@@ -0,0 +1,85 @@
+package postprocessor
+
+import (
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/logging"
+	"go.uber.org/zap"
+)
+
+const (
+	// HeadlessLabel makes the service's Service headless (clusterIP: None) when true
+	HeadlessLabel = "lissto.dev/headless"
+	// SessionAffinityLabel sets the Service session affinity (ClientIP or None)
+	SessionAffinityLabel = "lissto.dev/session-affinity"
+)
+
+// ServiceOptionsConfigurator sets headless and session affinity options on generated Services
+// Kompose configures neither; stateful services (databases, clusters) often need them.
+// Invalid label values are ignored with a warning.
+type ServiceOptionsConfigurator struct{}
+
+// NewServiceOptionsConfigurator creates a new Service options configurator
+func NewServiceOptionsConfigurator() *ServiceOptionsConfigurator {
+	return &ServiceOptionsConfigurator{}
+}
+
+// Configure applies headless and session-affinity labels to the Services of Kubernetes objects
+// serviceLabelMap maps service name to its labels from docker-compose
+func (s *ServiceOptionsConfigurator) Configure(objects []runtime.Object, serviceLabelMap map[string]map[string]string) []runtime.Object {
+	if len(serviceLabelMap) == 0 {
+		return objects
+	}
+
+	for _, obj := range objects {
+		service, ok := obj.(*corev1.Service)
+		if !ok {
+			continue
+		}
+		serviceName := serviceNameOf(service.Name, service.Labels)
+		if labels, exists := serviceLabelMap[serviceName]; exists {
+			s.configureService(service, labels, serviceName)
+		}
+	}
+
+	return objects
+}
+
+// configureService applies both labels to a Service
+func (s *ServiceOptionsConfigurator) configureService(service *corev1.Service, labels map[string]string, serviceName string) {
+	if value := labels[HeadlessLabel]; value != "" {
+		headless, err := strconv.ParseBool(value)
+		switch {
+		case err != nil:
+			logging.Logger.Warn("Ignoring invalid lissto.dev/headless label",
+				zap.String("service", serviceName),
+				zap.String("label_value", value))
+		case headless && service.Spec.Type != "" && service.Spec.Type != corev1.ServiceTypeClusterIP:
+			// Only ClusterIP Services can be headless
+			logging.Logger.Warn("Ignoring lissto.dev/headless label on non-ClusterIP Service",
+				zap.String("service", serviceName),
+				zap.String("type", string(service.Spec.Type)))
+		case headless:
+			service.Spec.ClusterIP = corev1.ClusterIPNone
+			logging.Logger.Info("Making Service headless", zap.String("service", serviceName))
+		}
+	}
+
+	if value := labels[SessionAffinityLabel]; value != "" {
+		affinity := corev1.ServiceAffinity(value)
+		if affinity != corev1.ServiceAffinityClientIP && affinity != corev1.ServiceAffinityNone {
+			logging.Logger.Warn("Ignoring invalid lissto.dev/session-affinity label",
+				zap.String("service", serviceName),
+				zap.String("label_value", value),
+				zap.Strings("allowed", []string{string(corev1.ServiceAffinityClientIP), string(corev1.ServiceAffinityNone)}))
+			return
+		}
+		service.Spec.SessionAffinity = affinity
+		logging.Logger.Info("Setting Service session affinity",
+			zap.String("service", serviceName),
+			zap.String("session_affinity", value))
+	}
+}
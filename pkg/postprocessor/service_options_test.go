@@ -0,0 +1,118 @@
+package postprocessor_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/lissto-dev/api/pkg/postprocessor"
+)
+
+var _ = Describe("ServiceOptionsConfigurator", func() {
+	var configurator *postprocessor.ServiceOptionsConfigurator
+
+	newService := func(name string, serviceType corev1.ServiceType) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"io.kompose.service": name}},
+			Spec: corev1.ServiceSpec{
+				Type:  serviceType,
+				Ports: []corev1.ServicePort{{Name: "5432", Port: 5432}},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		configurator = postprocessor.NewServiceOptionsConfigurator()
+	})
+
+	It("should make the Service headless", func() {
+		db := newService("db", "")
+		configurator.Configure([]runtime.Object{db}, map[string]map[string]string{
+			"db": {postprocessor.HeadlessLabel: "true"},
+		})
+
+		Expect(db.Spec.ClusterIP).To(Equal(corev1.ClusterIPNone))
+		Expect(db.Spec.SessionAffinity).To(BeEmpty())
+	})
+
+	It("should set ClientIP session affinity", func() {
+		web := newService("web", corev1.ServiceTypeClusterIP)
+		configurator.Configure([]runtime.Object{web}, map[string]map[string]string{
+			"web": {postprocessor.SessionAffinityLabel: "ClientIP"},
+		})
+
+		Expect(web.Spec.SessionAffinity).To(Equal(corev1.ServiceAffinityClientIP))
+		Expect(web.Spec.ClusterIP).To(BeEmpty())
+	})
+
+	It("should leave non-matching Services untouched", func() {
+		db := newService("db", "")
+		cache := newService("cache", "")
+		configurator.Configure([]runtime.Object{db, cache}, map[string]map[string]string{
+			"db":    {postprocessor.HeadlessLabel: "true", postprocessor.SessionAffinityLabel: "ClientIP"},
+			"cache": {"lissto.dev/other": "x"},
+		})
+
+		Expect(cache.Spec.ClusterIP).To(BeEmpty())
+		Expect(cache.Spec.SessionAffinity).To(BeEmpty())
+		Expect(db.Spec.ClusterIP).To(Equal(corev1.ClusterIPNone))
+	})
+
+	It("should ignore invalid values", func() {
+		db := newService("db", "")
+		configurator.Configure([]runtime.Object{db}, map[string]map[string]string{
+			"db": {postprocessor.HeadlessLabel: "yes please", postprocessor.SessionAffinityLabel: "Cookie"},
+		})
+
+		Expect(db.Spec.ClusterIP).To(BeEmpty())
+		Expect(db.Spec.SessionAffinity).To(BeEmpty())
+	})
+
+	It("should not make LoadBalancer Services headless", func() {
+		web := newService("web", corev1.ServiceTypeLoadBalancer)
+		configurator.Configure([]runtime.Object{web}, map[string]map[string]string{
+			"web": {postprocessor.HeadlessLabel: "true"},
+		})
+
+		Expect(web.Spec.ClusterIP).To(BeEmpty())
+	})
+
+	It("should configure Services generated by Kompose", func() {
+		project, err := loadProject(`
+services:
+  db:
+    image: postgres:16
+    ports: ["5432:5432"]
+    labels:
+      lissto.dev/headless: "true"
+  web:
+    image: nginx
+    ports: ["80:80"]
+    labels:
+      lissto.dev/session-affinity: ClientIP
+`)
+		Expect(err).NotTo(HaveOccurred())
+		objects, err := convertProject(project)
+		Expect(err).NotTo(HaveOccurred())
+
+		labels := map[string]map[string]string{}
+		for name, service := range project.Services {
+			labels[name] = service.Labels
+		}
+		configurator.Configure(objects, labels)
+
+		services := map[string]*corev1.Service{}
+		for _, obj := range objects {
+			if service, ok := obj.(*corev1.Service); ok {
+				services[service.Name] = service
+			}
+		}
+		Expect(services).To(HaveKey("db"))
+		Expect(services).To(HaveKey("web"))
+		Expect(services["db"].Spec.ClusterIP).To(Equal(corev1.ClusterIPNone))
+		Expect(services["web"].Spec.SessionAffinity).To(Equal(corev1.ServiceAffinityClientIP))
+		Expect(services["web"].Spec.ClusterIP).To(BeEmpty())
+	})
+})
@@ -3,6 +3,7 @@ package postprocessor
 import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -52,3 +53,47 @@ func (s *StackLabelInjector) injectToPod(pod *corev1.Pod, stackName string) {
 	}
 	pod.Labels["lissto.dev/stack"] = stackName
 }
+
+// InjectMetadata adds labels and annotations to every object and to the pod templates of workloads
+// Keys the generated objects already set are kept
+func (s *StackLabelInjector) InjectMetadata(objects []runtime.Object, labels, annotations map[string]string) []runtime.Object {
+	if len(labels) == 0 && len(annotations) == 0 {
+		return objects
+	}
+
+	for _, obj := range objects {
+		if accessor, err := meta.Accessor(obj); err == nil {
+			accessor.SetLabels(mergeMissing(accessor.GetLabels(), labels))
+			accessor.SetAnnotations(mergeMissing(accessor.GetAnnotations(), annotations))
+		}
+
+		var template *corev1.PodTemplateSpec
+		switch resource := obj.(type) {
+		case *appsv1.Deployment:
+			template = &resource.Spec.Template
+		case *appsv1.StatefulSet:
+			template = &resource.Spec.Template
+		}
+		if template != nil {
+			template.Labels = mergeMissing(template.Labels, labels)
+			template.Annotations = mergeMissing(template.Annotations, annotations)
+		}
+	}
+	return objects
+}
+
+// mergeMissing copies the entries of values missing from target, creating target when needed
+func mergeMissing(target, values map[string]string) map[string]string {
+	if len(values) == 0 {
+		return target
+	}
+	if target == nil {
+		target = make(map[string]string, len(values))
+	}
+	for key, value := range values {
+		if _, exists := target[key]; !exists {
+			target[key] = value
+		}
+	}
+	return target
+}
@@ -294,4 +294,23 @@ var _ = Describe("StackLabelInjector", func() {
 			})
 		})
 	})
+
+	Describe("InjectMetadata", func() {
+		It("should add labels and annotations to objects and pod templates without overriding", func() {
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{"team": "web"}},
+			}
+			service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web"}}
+
+			injector.InjectMetadata([]runtime.Object{deployment, service},
+				map[string]string{"cost-center": "cc-4711", "team": "payments"},
+				map[string]string{"owner": "payments@example.com"})
+
+			Expect(deployment.Labels).To(Equal(map[string]string{"cost-center": "cc-4711", "team": "web"}))
+			Expect(deployment.Annotations).To(HaveKeyWithValue("owner", "payments@example.com"))
+			Expect(deployment.Spec.Template.Labels).To(HaveKeyWithValue("cost-center", "cc-4711"))
+			Expect(deployment.Spec.Template.Annotations).To(HaveKeyWithValue("owner", "payments@example.com"))
+			Expect(service.Labels).To(HaveKeyWithValue("cost-center", "cc-4711"))
+		})
+	})
 })
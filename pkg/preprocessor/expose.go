@@ -7,6 +7,7 @@ import (
 	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/lissto-dev/api/pkg/logging"
 	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // VisibilityType represents the ingress visibility level
@@ -17,11 +18,26 @@ const (
 	VisibilityInternet VisibilityType = "internet"
 )
 
+// TLS modes for the ingress of an exposed service
+const (
+	TLSModeSecret      = "secret"       // Reference the pre-existing TLSSecret of the visibility
+	TLSModeCertManager = "cert-manager" // Let cert-manager issue a certificate into an auto-named secret
+)
+
+// TLSModeLabel lets a service pick its TLS mode, overriding the configured one
+const TLSModeLabel = "lissto.dev/tls-mode"
+
+// TLSIssuerLabel carries the cert-manager ClusterIssuer of an exposed service to the postprocessors
+// It is set by the preprocessor only; values from docker-compose are dropped
+const TLSIssuerLabel = "lissto.dev/tls-issuer"
+
 // IngressConfig holds configuration for a specific ingress visibility type
 type IngressConfig struct {
-	IngressClass string
-	HostSuffix   string
-	TLSSecret    string
+	IngressClass  string
+	HostSuffix    string
+	TLSSecret     string
+	TLSMode       string // TLSModeSecret (default) or TLSModeCertManager
+	ClusterIssuer string // cert-manager ClusterIssuer, required for TLSModeCertManager
 }
 
 // ExposePreprocessor handles conversion of lissto.dev/expose labels to Kompose labels
@@ -47,6 +63,67 @@ func NewExposePreprocessor(internalConfig, internetConfig *IngressConfig) *Expos
 	}
 }
 
+// WithDefaultVisibility returns a copy of the preprocessor using visibility for services exposed
+// with "true", as requested by a blueprint's x-lissto.exposeDefault. Empty keeps the configured default.
+// The visibility must be configured; lissto.dev/expose values naming a visibility still take precedence.
+func (ep *ExposePreprocessor) WithDefaultVisibility(visibility string) (*ExposePreprocessor, error) {
+	if visibility == "" {
+		return ep, nil
+	}
+
+	visType := VisibilityType(visibility)
+	switch visType {
+	case VisibilityInternal, VisibilityInternet:
+	default:
+		return nil, fmt.Errorf("invalid x-lissto.exposeDefault '%s' (valid: internal, internet)", visibility)
+	}
+	if !ep.isVisibilityConfigured(visType) {
+		return nil, fmt.Errorf("x-lissto.exposeDefault is '%s' but '%s' visibility is not configured", visType, visType)
+	}
+
+	withDefault := *ep
+	withDefault.defaultType = visType
+	return &withDefault, nil
+}
+
+// WithHostSuffix returns a copy of the preprocessor generating hostnames with suffix instead of the
+// configured HostSuffix of every visibility, e.g. a per-PR preview domain. Empty keeps the configured
+// suffixes; the suffix is validated by the caller, see NormalizeHostSuffix.
+func (ep *ExposePreprocessor) WithHostSuffix(suffix string) *ExposePreprocessor {
+	if suffix == "" {
+		return ep
+	}
+
+	withSuffix := *ep
+	if ep.internalConfig != nil {
+		internal := *ep.internalConfig
+		internal.HostSuffix = suffix
+		withSuffix.internalConfig = &internal
+	}
+	if ep.internetConfig != nil {
+		internet := *ep.internetConfig
+		internet.HostSuffix = suffix
+		withSuffix.internetConfig = &internet
+	}
+	return &withSuffix
+}
+
+// NormalizeHostSuffix checks that suffix is a DNS name within one of the allowed parent domains
+// and returns it with the leading dot hostnames are built with (e.g. .pr-123.preview.example.com)
+func NormalizeHostSuffix(suffix string, allowedDomains []string) (string, error) {
+	name := strings.ToLower(strings.TrimPrefix(suffix, "."))
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return "", fmt.Errorf("invalid host suffix '%s': %s", suffix, strings.Join(errs, "; "))
+	}
+	for _, domain := range allowedDomains {
+		domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+		if name == domain || strings.HasSuffix(name, "."+domain) {
+			return "." + name, nil
+		}
+	}
+	return "", fmt.Errorf("host suffix '%s' is not within an allowed preview domain", suffix)
+}
+
 // ExposureError represents an error during service exposure processing
 type ExposureError struct {
 	ServiceName   string
@@ -127,8 +204,12 @@ func (ep *ExposePreprocessor) ProcessServices(services types.Services, envName,
 			}
 
 			config := ep.getConfigForVisibility(visType)
+			tlsSecret, issuer, err := ep.resolveTLS(name, service, envName, visType, *config)
+			if err != nil {
+				return nil, err
+			}
 			hostname := ep.generateHostnameWithConfig(name, envName, *config)
-			komposeLabels := ep.convertToKomposeLabels(baseLabels, hostname, *config)
+			komposeLabels := ep.convertToKomposeLabels(baseLabels, hostname, *config, tlsSecret, issuer)
 			newService.Labels = komposeLabels
 
 			logging.Logger.Info("Service marked for exposure",
@@ -136,7 +217,8 @@ func (ep *ExposePreprocessor) ProcessServices(services types.Services, envName,
 				zap.String("hostname", hostname),
 				zap.String("visibility", string(visType)),
 				zap.String("ingress-class", config.IngressClass),
-				zap.String("tls-secret", config.TLSSecret),
+				zap.String("tls-secret", tlsSecret),
+				zap.String("cluster-issuer", issuer),
 				zap.String("stack", stackName))
 
 			processed[name] = newService
@@ -183,8 +265,65 @@ func (ep *ExposePreprocessor) GetExposedServiceURL(service types.ServiceConfig,
 	return ep.generateHostnameWithConfig(serviceName, envName, *config)
 }
 
+// GetTLSSecret returns the pre-existing TLS secret the ingress of an exposed service will reference
+// Returns empty string if service is not exposed, visibility type is not configured, no secret is set
+// or the certificate is issued by cert-manager (the secret only exists once it is issued)
+func (ep *ExposePreprocessor) GetTLSSecret(service types.ServiceConfig) string {
+	if !ep.shouldExposeService(service) {
+		return ""
+	}
+	config := ep.getConfigForVisibility(ep.getVisibilityType(service))
+	if config == nil {
+		return ""
+	}
+	if ep.getTLSMode(service, *config) != TLSModeSecret {
+		return ""
+	}
+	return config.TLSSecret
+}
+
+// CertManagerSecretName returns the secret cert-manager issues the certificate of an exposed service into
+func CertManagerSecretName(serviceName, envName string) string {
+	return strings.ToLower(fmt.Sprintf("%s-%s-tls", serviceName, envName))
+}
+
+// getTLSMode returns the TLS mode of a service: its lissto.dev/tls-mode label, else the configured mode
+func (ep *ExposePreprocessor) getTLSMode(service types.ServiceConfig, config IngressConfig) string {
+	if mode := service.Labels[TLSModeLabel]; mode != "" {
+		return mode
+	}
+	if config.TLSMode != "" {
+		return config.TLSMode
+	}
+	return TLSModeSecret
+}
+
+// resolveTLS returns the TLS secret and, in cert-manager mode, the ClusterIssuer for an exposed service
+func (ep *ExposePreprocessor) resolveTLS(serviceName string, service types.ServiceConfig, envName string, visType VisibilityType, config IngressConfig) (string, string, error) {
+	switch mode := ep.getTLSMode(service, config); mode {
+	case TLSModeSecret:
+		return config.TLSSecret, "", nil
+	case TLSModeCertManager:
+		if config.ClusterIssuer == "" {
+			return "", "", &ExposureError{
+				ServiceName:   serviceName,
+				RequestedType: visType,
+				Message:       fmt.Sprintf("requested cert-manager TLS but no cluster issuer is configured for '%s' visibility", visType),
+			}
+		}
+		return CertManagerSecretName(serviceName, envName), config.ClusterIssuer, nil
+	default:
+		return "", "", &ExposureError{
+			ServiceName:   serviceName,
+			RequestedType: visType,
+			Message: fmt.Sprintf("unknown %s '%s' (valid: %s, %s)",
+				TLSModeLabel, mode, TLSModeSecret, TLSModeCertManager),
+		}
+	}
+}
+
 // convertToKomposeLabels converts lissto.dev/expose labels to Kompose-compatible labels
-func (ep *ExposePreprocessor) convertToKomposeLabels(labels map[string]string, hostname string, config IngressConfig) map[string]string {
+func (ep *ExposePreprocessor) convertToKomposeLabels(labels map[string]string, hostname string, config IngressConfig, tlsSecret, issuer string) map[string]string {
 	komposeLabels := make(map[string]string)
 
 	// Copy non-expose labels
@@ -203,13 +342,19 @@ func (ep *ExposePreprocessor) convertToKomposeLabels(labels map[string]string, h
 	// Set ingress class
 	komposeLabels["kompose.service.expose.ingress-class-name"] = config.IngressClass
 
-	// Set TLS secret (always present due to validation)
-	komposeLabels["kompose.service.expose.tls-secret"] = config.TLSSecret
+	// Set TLS secret (always present due to validation, auto-named in cert-manager mode)
+	komposeLabels["kompose.service.expose.tls-secret"] = tlsSecret
+
+	// Hand the issuer to the ingress TLS postprocessor
+	if issuer != "" {
+		komposeLabels[TLSIssuerLabel] = issuer
+	}
 
 	return komposeLabels
 }
 
 // removeKomposeExposeLabels returns a copy of labels without kompose service expose labels
+// The issuer label is dropped too, only the configured issuers may be used
 func (ep *ExposePreprocessor) removeKomposeExposeLabels(labels map[string]string) map[string]string {
 	cleaned := make(map[string]string)
 	if labels == nil {
@@ -218,7 +363,8 @@ func (ep *ExposePreprocessor) removeKomposeExposeLabels(labels map[string]string
 	for key, value := range labels {
 		if key == "kompose.service.expose" ||
 			key == "kompose.service.expose.ingress-class-name" ||
-			key == "kompose.service.expose.tls-secret" {
+			key == "kompose.service.expose.tls-secret" ||
+			key == TLSIssuerLabel {
 			continue
 		}
 		cleaned[key] = value
@@ -0,0 +1,91 @@
+// Package secretref handles variable values that point at a secret key instead of holding a value.
+//
+// A value of the form secretref://<secret>/<key> names a key of a LisstoSecret, so the secret value
+// never appears in the variable data. The API validates references when variables are saved; it does
+// not inject variables into workloads. The controller injects them and must turn a reference into an
+// env var with valueFrom.secretKeyRef, which EnvVar builds. Until the pinned controller does this, a
+// variable it injects carries the reference as a literal value. The one place the API injects
+// variables itself, an env's default variables (lissto.dev/default-variables), resolves them with EnvVar.
+package secretref
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+// Prefix marks a variable value as a secret reference
+const Prefix = "secretref://"
+
+// ErrDangling is returned when a reference names a secret or key that doesn't exist
+var ErrDangling = errors.New("dangling secret reference")
+
+// Ref is a parsed secret reference
+type Ref struct {
+	Secret string // LisstoSecret name
+	Key    string // Key within the secret
+}
+
+// String returns the reference in secretref:// form
+func (r Ref) String() string {
+	return Prefix + r.Secret + "/" + r.Key
+}
+
+// Parse parses a variable value; ok is false for plain values
+// A value with the prefix but without both a secret name and a key is an error.
+func Parse(value string) (ref Ref, ok bool, err error) {
+	rest, found := strings.CutPrefix(value, Prefix)
+	if !found {
+		return Ref{}, false, nil
+	}
+	secret, key, found := strings.Cut(rest, "/")
+	if !found || secret == "" || key == "" || strings.Contains(key, "/") {
+		return Ref{}, true, fmt.Errorf("invalid secret reference %q, expected %s<secret>/<key>", value, Prefix)
+	}
+	return Ref{Secret: secret, Key: key}, true, nil
+}
+
+// Resolve finds the referenced key among the secrets of an env and returns its K8s selector
+// Env-scoped secrets are only visible to variables of the same env (pass env "" for repo/global variables).
+func (r Ref) Resolve(secrets []envv1alpha1.LisstoSecret, env string) (*corev1.SecretKeySelector, error) {
+	for i := range secrets {
+		secret := &secrets[i]
+		if secret.Name != r.Secret {
+			continue
+		}
+		if secret.GetScope() == "env" && secret.Spec.Env != env {
+			break
+		}
+		for _, key := range secret.Spec.Keys {
+			if key == r.Key {
+				return &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secret.GetSecretRef()},
+					Key:                  r.Key,
+				}, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: secret %q has no key %q", ErrDangling, r.Secret, r.Key)
+	}
+	return nil, fmt.Errorf("%w: secret %q not found", ErrDangling, r.Secret)
+}
+
+// EnvVar builds the env var for a variable key: a literal value, or valueFrom.secretKeyRef for references
+// It is the resolution the controller's variable injection is expected to apply
+func EnvVar(name, value string, secrets []envv1alpha1.LisstoSecret, env string) (corev1.EnvVar, error) {
+	ref, ok, err := Parse(value)
+	if err != nil {
+		return corev1.EnvVar{}, err
+	}
+	if !ok {
+		return corev1.EnvVar{Name: name, Value: value}, nil
+	}
+	selector, err := ref.Resolve(secrets, env)
+	if err != nil {
+		return corev1.EnvVar{}, err
+	}
+	return corev1.EnvVar{Name: name, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: selector}}, nil
+}
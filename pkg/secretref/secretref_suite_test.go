@@ -0,0 +1,13 @@
+package secretref_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSecretRef(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SecretRef Suite")
+}
@@ -0,0 +1,84 @@
+package secretref_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lissto-dev/api/pkg/secretref"
+	envv1alpha1 "github.com/lissto-dev/controller/api/v1alpha1"
+)
+
+var _ = Describe("Secret references", func() {
+	secrets := []envv1alpha1.LisstoSecret{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "db"},
+			Spec:       envv1alpha1.LisstoSecretSpec{Scope: "env", Env: "dev", Keys: []string{"PASSWORD"}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared"},
+			Spec:       envv1alpha1.LisstoSecretSpec{Scope: "repo", Keys: []string{"TOKEN"}, SecretRef: "shared-values"},
+		},
+	}
+
+	Describe("Parse", func() {
+		It("should parse references", func() {
+			ref, ok, err := secretref.Parse("secretref://db/PASSWORD")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(ref).To(Equal(secretref.Ref{Secret: "db", Key: "PASSWORD"}))
+			Expect(ref.String()).To(Equal("secretref://db/PASSWORD"))
+		})
+
+		It("should leave plain values alone", func() {
+			_, ok, err := secretref.Parse("postgres://db:5432")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should reject malformed references", func() {
+			for _, value := range []string{"secretref://db", "secretref:///KEY", "secretref://db/", "secretref://db/a/b"} {
+				_, ok, err := secretref.Parse(value)
+				Expect(ok).To(BeTrue(), value)
+				Expect(err).To(HaveOccurred(), value)
+			}
+		})
+	})
+
+	Describe("EnvVar", func() {
+		It("should resolve references to secretKeyRef", func() {
+			envVar, err := secretref.EnvVar("DB_PASSWORD", "secretref://db/PASSWORD", secrets, "dev")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envVar).To(Equal(corev1.EnvVar{
+				Name: "DB_PASSWORD",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "db-data"},
+					Key:                  "PASSWORD",
+				}},
+			}))
+
+			envVar, err = secretref.EnvVar("TOKEN", "secretref://shared/TOKEN", secrets, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envVar.ValueFrom.SecretKeyRef.Name).To(Equal("shared-values"))
+		})
+
+		It("should keep literal values", func() {
+			envVar, err := secretref.EnvVar("HOST", "db", secrets, "dev")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envVar).To(Equal(corev1.EnvVar{Name: "HOST", Value: "db"}))
+		})
+
+		It("should reject dangling references", func() {
+			for _, value := range []string{"secretref://missing/KEY", "secretref://db/USER"} {
+				_, err := secretref.EnvVar("X", value, secrets, "dev")
+				Expect(err).To(MatchError(secretref.ErrDangling), value)
+			}
+		})
+
+		It("should not resolve another env's secrets", func() {
+			_, err := secretref.EnvVar("DB_PASSWORD", "secretref://db/PASSWORD", secrets, "staging")
+			Expect(err).To(MatchError(secretref.ErrDangling))
+		})
+	})
+})
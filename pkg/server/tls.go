@@ -0,0 +1,49 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/lissto-dev/api/pkg/config"
+)
+
+// TLSConfig builds the server TLS configuration from settings
+// It returns nil when no certificate is configured, meaning the server runs plain HTTP
+func TLSConfig(settings config.TLSSettings) (*tls.Config, error) {
+	if !settings.Enabled() {
+		return nil, nil
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	cert, err := tls.LoadX509KeyPair(settings.CertFile, settings.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	// Mutual TLS: certificates clients present must be signed by the CA bundle. The listener does not require one,
+	// so kubelet probes and metrics scrapes reach /health and /metrics; /api/v1 requires a verified certificate
+	// (middleware.RequireClientCertificate)
+	if settings.ClientCAFile != "" {
+		caPEM, err := os.ReadFile(settings.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in client CA bundle %s", settings.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,89 @@
+package server_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lissto-dev/api/pkg/config"
+	"github.com/lissto-dev/api/pkg/server"
+)
+
+// writeSelfSigned writes a self-signed certificate and key to dir and returns their paths
+func writeSelfSigned(dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "lissto-api"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+
+	certPath := filepath.Join(dir, "tls.crt")
+	keyPath := filepath.Join(dir, "tls.key")
+	Expect(os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)).To(Succeed())
+	Expect(os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)).To(Succeed())
+	return certPath, keyPath
+}
+
+var _ = Describe("TLSConfig", func() {
+	var certPath, keyPath string
+
+	BeforeEach(func() {
+		certPath, keyPath = writeSelfSigned(GinkgoT().TempDir())
+	})
+
+	It("should select plain HTTP when no certificate is configured", func() {
+		tlsConfig, err := server.TLSConfig(config.TLSSettings{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tlsConfig).To(BeNil())
+	})
+
+	It("should select TLS when a certificate is configured", func() {
+		tlsConfig, err := server.TLSConfig(config.TLSSettings{CertFile: certPath, KeyFile: keyPath})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tlsConfig).NotTo(BeNil())
+		Expect(tlsConfig.Certificates).To(HaveLen(1))
+		Expect(tlsConfig.ClientAuth).To(Equal(tls.NoClientCert))
+	})
+
+	It("should verify client certificates when a client CA is configured", func() {
+		tlsConfig, err := server.TLSConfig(config.TLSSettings{CertFile: certPath, KeyFile: keyPath, ClientCAFile: certPath})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tlsConfig.ClientAuth).To(Equal(tls.VerifyClientCertIfGiven))
+		Expect(tlsConfig.ClientCAs).NotTo(BeNil())
+	})
+
+	It("should reject a certificate without its key", func() {
+		_, err := server.TLSConfig(config.TLSSettings{CertFile: certPath})
+		Expect(err).To(MatchError(ContainSubstring("must be set together")))
+	})
+
+	It("should reject an empty client CA bundle", func() {
+		emptyCA := filepath.Join(GinkgoT().TempDir(), "ca.crt")
+		Expect(os.WriteFile(emptyCA, []byte("not a certificate"), 0o600)).To(Succeed())
+
+		_, err := server.TLSConfig(config.TLSSettings{CertFile: certPath, KeyFile: keyPath, ClientCAFile: emptyCA})
+		Expect(err).To(MatchError(ContainSubstring("no certificates found")))
+	})
+})
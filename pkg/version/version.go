@@ -0,0 +1,8 @@
+// Package version holds the build version of the API, set at link time
+package version
+
+// Version is the API version, set with -ldflags "-X github.com/lissto-dev/api/pkg/version.Version=..."
+var Version = "dev"
+
+// BuildTime is the UTC build timestamp, set like Version
+var BuildTime = ""